@@ -0,0 +1,285 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 存款结果
+type DepositResult struct {
+	AccountID  string  `json:"accountId"`
+	Amount     float64 `json:"amount"`
+	OldBalance float64 `json:"oldBalance"`
+	NewBalance float64 `json:"newBalance"`
+	Version    int64   `json:"version"`
+	Time       string  `json:"time"`
+}
+
+// 转账结果（跨币种转账时包含兑换信息）
+type TransferResult struct {
+	FromAccount  string  `json:"fromAccount"`
+	ToAccount    string  `json:"toAccount"`
+	Amount       float64 `json:"amount"`
+	NewBalance   float64 `json:"newBalance"`
+	Time         string  `json:"time"`
+	FromCurrency string  `json:"fromCurrency,omitempty"`
+	ToCurrency   string  `json:"toCurrency,omitempty"`
+	FXRate       float64 `json:"fxRate,omitempty"`
+	CreditAmount float64 `json:"creditAmount,omitempty"`
+	Fee          float64 `json:"fee"`
+	TotalDebit   float64 `json:"totalDebit"`
+	QuoteID      string  `json:"quoteId,omitempty"`
+
+	model.Remittance
+}
+
+// 交易流水分页
+type TransactionPage struct {
+	AccountID string              `json:"accountId"`
+	Page      int                 `json:"page"`
+	PageSize  int                 `json:"pageSize"`
+	Total     int                 `json:"total"`
+	Items     []model.Transaction `json:"items"`
+}
+
+// 时点余额
+type PointBalance struct {
+	AccountID string  `json:"accountId"`
+	Currency  string  `json:"currency"`
+	At        string  `json:"at"`
+	Balance   float64 `json:"balance"`
+}
+
+// 期间余额
+type PeriodBalance struct {
+	AccountID      string  `json:"accountId"`
+	Currency       string  `json:"currency"`
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	OpeningBalance float64 `json:"openingBalance"`
+	ClosingBalance float64 `json:"closingBalance"`
+	CreditTotal    float64 `json:"creditTotal"`
+	DebitTotal     float64 `json:"debitTotal"`
+	Count          int     `json:"count"`
+}
+
+// 获取当前登录账户信息
+func (c *Client) Account() (model.Account, error) {
+	var account model.Account
+	err := c.do(request{method: http.MethodGet, path: "/account"}, &account)
+	return account, err
+}
+
+// 批量查询账户摘要（最多 50 个），单个账户不存在或无权查看时在对应条目的 code/error 中返回
+func (c *Client) BatchGetAccounts(accountIDs ...string) ([]service.AccountBatchItem, error) {
+	var items []service.AccountBatchItem
+	err := c.do(request{method: http.MethodPost, path: "/accounts/batch-get", body: service.AccountBatchRequest{AccountIDs: accountIDs}}, &items)
+	return items, err
+}
+
+// 按 ETag 条件查询账户信息（适合轮询余额）：etag 为上次返回的 ETag，账户未变化时 changed 为 false 且不返回账户数据
+func (c *Client) AccountIfChanged(etag string) (account model.Account, newETag string, changed bool, err error) {
+	newETag, changed, err = c.doConditional(request{method: http.MethodGet, path: "/account", ifNoneMatch: etag}, &account)
+	return account, newETag, changed, err
+}
+
+// 存款，expectedVersion 非 0 时按 If-Match 条件更新
+func (c *Client) Deposit(req service.DepositRequest, expectedVersion int64) (DepositResult, error) {
+	var result DepositResult
+	err := c.do(request{method: http.MethodPost, path: "/deposit", body: req, ifMatch: expectedVersion}, &result)
+	return result, err
+}
+
+// 行内转账，req.FromVersion 非 0 时按 If-Match 校验转出账户版本
+func (c *Client) Transfer(req service.TransferRequest) (TransferResult, error) {
+	var result TransferResult
+	err := c.do(request{method: http.MethodPost, path: "/transfer", body: req, ifMatch: req.FromVersion}, &result)
+	return result, err
+}
+
+// 转账前核对收款人户名，返回 match/partial_match/no_match（开启 payee_check 开关时首次收款人转账须先核对）
+func (c *Client) VerifyPayee(req service.PayeeCheckRequest) (service.PayeeCheck, error) {
+	var check service.PayeeCheck
+	err := c.do(request{method: http.MethodPost, path: "/transfer/verify-payee", body: req}, &check)
+	return check, err
+}
+
+// 分页查询交易流水（按时间倒序），accountID 为空时查询默认账户
+func (c *Client) Transactions(accountID string, page, pageSize int) (TransactionPage, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(pageSize))
+	}
+
+	var result TransactionPage
+	err := c.do(request{method: http.MethodGet, path: "/transactions", query: query}, &result)
+	return result, err
+}
+
+// 按条件（参考号、用途代码、金额与时间区间、交易类型、对方账户等）查询交易流水（偏移分页，默认按时间倒序）
+func (c *Client) SearchTransactions(accountID string, filter service.TransactionFilter, page, pageSize int) (TransactionPage, error) {
+	query := url.Values{}
+	setTransactionFilter(query, accountID, filter)
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(pageSize))
+	}
+
+	var result TransactionPage
+	err := c.do(request{method: http.MethodGet, path: "/transactions", query: query}, &result)
+	return result, err
+}
+
+// 按游标分页查询交易流水（默认按时间倒序），cursor 为上一页的 NextCursor，为空表示第一页；limit 为 0 时使用默认条数
+func (c *Client) TransactionsByCursor(accountID string, filter service.TransactionFilter, cursor string, limit int) (service.TransactionPage, error) {
+	query := cursorQuery(cursor, limit)
+	setTransactionFilter(query, accountID, filter)
+
+	var page service.TransactionPage
+	err := c.do(request{method: http.MethodGet, path: "/transactions", query: query}, &page)
+	return page, err
+}
+
+// 按 ETag 条件游标分页查询交易流水：etag 为相同查询条件上次返回的 ETag，账户没有新流水时 changed 为 false 且不返回数据
+func (c *Client) TransactionsByCursorIfChanged(accountID string, filter service.TransactionFilter, cursor string, limit int,
+	etag string) (page service.TransactionPage, newETag string, changed bool, err error) {
+	query := cursorQuery(cursor, limit)
+	setTransactionFilter(query, accountID, filter)
+
+	newETag, changed, err = c.doConditional(request{method: http.MethodGet, path: "/transactions", query: query, ifNoneMatch: etag}, &page)
+	return page, newETag, changed, err
+}
+
+// 全文检索交易流水（关键词匹配摘要、收付款方、参考号与附言，结果带高亮），filter 可进一步限定条件；limit 为 0 时使用默认条数
+func (c *Client) SearchTransactionText(accountID, text string, filter service.TransactionFilter, limit int) (service.TransactionSearchResult, error) {
+	query := url.Values{}
+	setTransactionFilter(query, accountID, filter)
+	query.Set("q", text)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var result service.TransactionSearchResult
+	err := c.do(request{method: http.MethodGet, path: "/transactions/search", query: query}, &result)
+	return result, err
+}
+
+// 交易流水过滤与排序参数
+func setTransactionFilter(query url.Values, accountID string, filter service.TransactionFilter) {
+	for key, value := range map[string]string{
+		"accountId":           accountID,
+		"reference":           filter.Reference,
+		"purposeCode":         filter.PurposeCode,
+		"type":                strings.Join(filter.Types, ","),
+		"counterpartyAccount": filter.Counterparty,
+		"direction":           filter.Direction,
+		"status":              filter.Status,
+		"sort":                filter.SortBy,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if filter.MinAmount != nil {
+		query.Set("minAmount", strconv.FormatFloat(*filter.MinAmount, 'f', -1, 64))
+	}
+	if filter.MaxAmount != nil {
+		query.Set("maxAmount", strconv.FormatFloat(*filter.MaxAmount, 'f', -1, 64))
+	}
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.Format(time.RFC3339))
+	}
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.Format(time.RFC3339))
+	}
+	if filter.Ascending {
+		query.Set("order", "asc")
+	}
+}
+
+// 游标分页参数（始终带 cursor 参数，服务端据此按游标分页）
+func cursorQuery(cursor string, limit int) url.Values {
+	query := url.Values{}
+	query.Set("cursor", cursor)
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
+
+// 获取转账报价（手续费、汇率与扣款总额），返回的报价 ID 可填入 TransferRequest.QuoteID 以锁定价格
+func (c *Client) TransferQuote(req service.QuoteRequest) (service.TransferQuote, error) {
+	var quote service.TransferQuote
+	err := c.do(request{method: http.MethodPost, path: "/transfer/quote", body: req}, &quote)
+	return quote, err
+}
+
+// 激活休眠账户
+func (c *Client) ReactivateAccount(accountID string) (model.Account, error) {
+	var account model.Account
+	err := c.do(request{method: http.MethodPost, path: "/account/reactivate",
+		body: service.ReactivateRequest{AccountID: accountID}}, &account)
+	return account, err
+}
+
+// 转账用途代码列表
+func (c *Client) PurposeCodes() ([]service.PurposeCode, error) {
+	var codes []service.PurposeCode
+	err := c.do(request{method: http.MethodGet, path: "/transfer/purpose-codes"}, &codes)
+	return codes, err
+}
+
+// 查询时点余额，at 为零值时按当前模拟时间
+func (c *Client) BalanceAt(accountID string, at time.Time) (PointBalance, error) {
+	query := url.Values{"accountId": {accountID}}
+	if !at.IsZero() {
+		query.Set("at", at.Format(time.RFC3339))
+	}
+
+	var result PointBalance
+	err := c.do(request{method: http.MethodGet, path: "/account/balance", query: query}, &result)
+	return result, err
+}
+
+// 游标分页查询账户动态（交易、登录、安全事件、状态变更与通知按时间倒序合并），types 为空表示全部类型（见 service.TIMELINE_*），
+// accountID 为空时查询当前登录账户
+func (c *Client) Timeline(accountID string, types []string, cursor string, limit int) (service.TimelinePage, error) {
+	query := cursorQuery(cursor, limit)
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if len(types) > 0 {
+		query.Set("types", strings.Join(types, ","))
+	}
+
+	var page service.TimelinePage
+	err := c.do(request{method: http.MethodGet, path: "/account/timeline", query: query}, &page)
+	return page, err
+}
+
+// 查询期间期初/期末余额及借贷发生额
+func (c *Client) PeriodBalance(accountID string, from, to time.Time) (PeriodBalance, error) {
+	query := url.Values{
+		"accountId": {accountID},
+		"from":      {from.Format(time.RFC3339)},
+		"to":        {to.Format(time.RFC3339)},
+	}
+
+	var result PeriodBalance
+	err := c.do(request{method: http.MethodGet, path: "/account/balance", query: query}, &result)
+	return result, err
+}
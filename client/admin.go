@@ -0,0 +1,498 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/handler"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 试算平衡表及最近一次对账结果
+type TrialBalanceReport struct {
+	TrialBalance       service.TrialBalance          `json:"trialBalance"`
+	LastReconciliation *service.ReconciliationResult `json:"lastReconciliation"`
+}
+
+// 总账科目表及科目余额
+type GLBalanceReport struct {
+	ChartOfAccounts []service.GLAccount `json:"chartOfAccounts"`
+	Balances        []service.GLBalance `json:"balances"`
+}
+
+// 运营统计
+type AdminStats struct {
+	GeneratedAt   string                    `json:"generatedAt"`
+	Accounts      service.AccountTotals     `json:"accounts"`
+	WSConnections int64                     `json:"wsConnections"`
+	WSAccounts    map[string]int            `json:"wsAccounts"` // 各账户当前 WebSocket 连接数
+	Today         service.PeriodStats       `json:"today"`
+	ThisWeek      service.PeriodStats       `json:"thisWeek"`
+	TopAccounts   []service.AccountActivity `json:"topAccounts"`
+	ErrorCodes    []handler.CodeCount       `json:"errorCodes"`
+}
+
+// WebSocket 在线连接及断开统计
+type WSConnectionList struct {
+	Total           int                 `json:"total"`
+	Connections     []ws.ConnectionInfo `json:"connections"`
+	MessagesDropped int64               `json:"messagesDropped"`
+	Disconnects     map[string]int64    `json:"disconnects"`
+}
+
+// 模拟时钟状态
+type SimClock struct {
+	SimTime  string  `json:"simTime"`
+	RealTime string  `json:"realTime,omitempty"`
+	Speed    float64 `json:"speed,omitempty"`
+}
+
+// 查询实时试算平衡表（管理员）
+func (c *Client) TrialBalance() (TrialBalanceReport, error) {
+	var report TrialBalanceReport
+	err := c.do(request{method: http.MethodGet, path: "/admin/trial-balance"}, &report)
+	return report, err
+}
+
+// 资金守恒检查（管理员）
+func (c *Client) CheckInvariants() (service.InvariantReport, error) {
+	var report service.InvariantReport
+	err := c.do(request{method: http.MethodGet, path: "/admin/invariants"}, &report)
+	return report, err
+}
+
+// 选主状态（管理员）
+func (c *Client) LeaderStatus() (service.LeaderStatus, error) {
+	var status service.LeaderStatus
+	err := c.do(request{method: http.MethodGet, path: "/admin/leader"}, &status)
+	return status, err
+}
+
+// 模拟外部系统熔断器状态（管理员）
+func (c *Client) Breakers() ([]service.BreakerStatus, error) {
+	var statuses []service.BreakerStatus
+	err := c.do(request{method: http.MethodGet, path: "/admin/breakers"}, &statuses)
+	return statuses, err
+}
+
+// 强制熔断（trip）或恢复（reset）熔断器（管理员）
+func (c *Client) SetBreaker(name, action string) (service.BreakerStatus, error) {
+	var status service.BreakerStatus
+	err := c.do(request{method: http.MethodPost, path: "/admin/breakers/" + url.PathEscape(name),
+		body: service.BreakerActionRequest{Action: action}}, &status)
+	return status, err
+}
+
+// 查询已结账期间（管理员，最近的在前）
+func (c *Client) Periods() ([]service.PeriodClose, error) {
+	var periods []service.PeriodClose
+	err := c.do(request{method: http.MethodGet, path: "/admin/periods"}, &periods)
+	return periods, err
+}
+
+// 手动结账（管理员，period 为 YYYY-MM）
+func (c *Client) ClosePeriod(period string) (service.PeriodClose, error) {
+	var result service.PeriodClose
+	err := c.do(request{method: http.MethodPost, path: "/admin/periods", body: handler.PeriodCloseRequest{Period: period}}, &result)
+	return result, err
+}
+
+// 查询期间结账报告（管理员）
+func (c *Client) PeriodClose(period string) (service.PeriodClose, error) {
+	var result service.PeriodClose
+	err := c.do(request{method: http.MethodGet, path: "/admin/periods/" + url.PathEscape(period)}, &result)
+	return result, err
+}
+
+// 查询总账科目余额（管理员）
+func (c *Client) GLBalances() (GLBalanceReport, error) {
+	var report GLBalanceReport
+	err := c.do(request{method: http.MethodGet, path: "/admin/gl/balances"}, &report)
+	return report, err
+}
+
+// 查询 WebSocket 推送统计（管理员）
+func (c *Client) WSMetrics() (ws.Metrics, error) {
+	var metrics ws.Metrics
+	err := c.do(request{method: http.MethodGet, path: "/admin/ws/metrics"}, &metrics)
+	return metrics, err
+}
+
+// 查询 WebSocket 在线连接（管理员），accountID 为空时返回全部
+func (c *Client) WSConnections(accountID string) (WSConnectionList, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var list WSConnectionList
+	err := c.do(request{method: http.MethodGet, path: "/admin/ws/connections", query: query}, &list)
+	return list, err
+}
+
+// 查询运营统计（管理员）
+func (c *Client) AdminStats() (AdminStats, error) {
+	var stats AdminStats
+	err := c.do(request{method: http.MethodGet, path: "/admin/stats"}, &stats)
+	return stats, err
+}
+
+// 按条件查询账户列表（管理员）
+func (c *Client) SearchAccounts(filter service.AccountFilter) (service.AccountPage, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"status":      filter.Status,
+		"name":        filter.NamePrefix,
+		"createdFrom": filter.CreatedFrom,
+		"createdTo":   filter.CreatedTo,
+		"sort":        filter.Sort,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if filter.MinBalance != nil {
+		query.Set("minBalance", strconv.FormatFloat(*filter.MinBalance, 'f', -1, 64))
+	}
+	if filter.MaxBalance != nil {
+		query.Set("maxBalance", strconv.FormatFloat(*filter.MaxBalance, 'f', -1, 64))
+	}
+	if filter.Desc {
+		query.Set("order", "desc")
+	}
+	if filter.Page > 0 {
+		query.Set("page", strconv.Itoa(filter.Page))
+	}
+	if filter.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(filter.PageSize))
+	}
+
+	var page service.AccountPage
+	err := c.do(request{method: http.MethodGet, path: "/admin/accounts", query: query}, &page)
+	return page, err
+}
+
+// 查询模拟时钟
+func (c *Client) SimClock() (SimClock, error) {
+	var clock SimClock
+	err := c.do(request{method: http.MethodGet, path: "/sim/clock"}, &clock)
+	return clock, err
+}
+
+// 快进模拟时钟（跨越日界时由日终调度补跑日终）
+func (c *Client) AdvanceSimClock(hours float64) (SimClock, error) {
+	var clock SimClock
+	err := c.do(request{method: http.MethodPost, path: "/sim/clock/advance",
+		body: handler.ClockAdvanceRequest{Hours: hours}}, &clock)
+	return clock, err
+}
+
+// 批量生成模拟账户（零值字段使用默认值）
+func (c *Client) Seed(req service.SeedRequest) (service.SeedResult, error) {
+	var result service.SeedResult
+	err := c.do(request{method: http.MethodPost, path: "/sim/seed", body: req}, &result)
+	return result, err
+}
+
+// 人工调账（管理员），amount 为正调增、为负调减
+func (c *Client) AdjustBalance(accountID string, amount float64, reason string) (DepositResult, error) {
+	var result DepositResult
+	err := c.do(request{method: http.MethodPost, path: "/admin/accounts/adjust",
+		body: service.BalanceAdjustRequest{AccountID: accountID, Amount: amount, Reason: reason}}, &result)
+	return result, err
+}
+
+// 按 CSV 批量导入账户（管理员），dryRun 为 true 时只校验不写入
+func (c *Client) ImportAccounts(csv []byte, dryRun bool) (service.AccountImportReport, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dryRun", "true")
+	}
+	var report service.AccountImportReport
+	err := c.do(request{method: http.MethodPost, path: "/admin/accounts/import", query: query,
+		raw: csv, rawType: "text/csv"}, &report)
+	return report, err
+}
+
+// 冻结/解冻账户（管理员），status 为 normal 或 frozen
+func (c *Client) SetAccountStatus(accountID, status, reason string) (model.Account, error) {
+	var account model.Account
+	err := c.do(request{method: http.MethodPost, path: "/admin/accounts/status",
+		body: service.AccountStatusRequest{AccountID: accountID, Status: status, Reason: reason}}, &account)
+	return account, err
+}
+
+// 查询模拟邮件发件箱（按时间倒序），accountID/template 为空表示不限
+func (c *Client) Outbox(accountID, template string) ([]service.Email, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if template != "" {
+		query.Set("template", template)
+	}
+
+	var emails []service.Email
+	err := c.do(request{method: http.MethodGet, path: "/sim/outbox", query: query}, &emails)
+	return emails, err
+}
+
+// 清空模拟邮件发件箱，返回清除的邮件数
+func (c *Client) ClearOutbox() (int, error) {
+	var result struct {
+		Cleared int `json:"cleared"`
+	}
+	err := c.do(request{method: http.MethodDelete, path: "/sim/outbox"}, &result)
+	return result.Cleared, err
+}
+
+// 短信网关配置
+type SMSGatewayConfig struct {
+	FailureRate   float64 `json:"failureRate"`
+	MaxAttempts   int     `json:"maxAttempts"`
+	RetryInterval string  `json:"retryInterval"`
+}
+
+// 查询模拟短信发件箱（按时间倒序），accountID/smsType/status 为空表示不限
+func (c *Client) SMSOutbox(accountID, smsType, status string) ([]service.SMS, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{"accountId": accountID, "type": smsType, "status": status} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	var messages []service.SMS
+	err := c.do(request{method: http.MethodGet, path: "/sim/sms", query: query}, &messages)
+	return messages, err
+}
+
+// 清空模拟短信发件箱，返回清除的短信数
+func (c *Client) ClearSMSOutbox() (int, error) {
+	var result struct {
+		Cleared int `json:"cleared"`
+	}
+	err := c.do(request{method: http.MethodDelete, path: "/sim/sms"}, &result)
+	return result.Cleared, err
+}
+
+// 调整短信网关失败率与最多下发次数（字段为空表示不变）
+func (c *Client) SetSMSConfig(req service.SMSConfigRequest) (SMSGatewayConfig, error) {
+	var cfg SMSGatewayConfig
+	err := c.do(request{method: http.MethodPost, path: "/sim/sms/config", body: req}, &cfg)
+	return cfg, err
+}
+
+// 故障注入配置与累计统计（延迟以毫秒计）
+type ChaosStatus struct {
+	Enabled          bool                                   `json:"enabled"`
+	Latency          service.ChaosLatencyRequest            `json:"latency"`
+	Endpoints        map[string]service.ChaosLatencyRequest `json:"endpoints"`
+	ErrorRate        float64                                `json:"errorRate"`
+	WriteFailureRate float64                                `json:"writeFailureRate"`
+	StormIntervalSec int                                    `json:"stormIntervalSec"`
+	StormRatio       float64                                `json:"stormRatio"`
+	Stats            service.ChaosStats                     `json:"stats"`
+}
+
+// 查询故障注入配置与统计
+func (c *Client) Chaos() (ChaosStatus, error) {
+	var status ChaosStatus
+	err := c.do(request{method: http.MethodGet, path: "/sim/chaos"}, &status)
+	return status, err
+}
+
+// 调整故障注入配置（字段为空表示不变）
+func (c *Client) SetChaos(req service.ChaosConfigRequest) (ChaosStatus, error) {
+	var status ChaosStatus
+	err := c.do(request{method: http.MethodPost, path: "/sim/chaos", body: req}, &status)
+	return status, err
+}
+
+// 立即触发一次 WebSocket 断线风暴，返回断开的连接数
+func (c *Client) ChaosStorm(ratio float64) (int, error) {
+	var result struct {
+		Disconnected int `json:"disconnected"`
+	}
+	err := c.do(request{method: http.MethodPost, path: "/sim/chaos/storm", body: handler.ChaosStormRequest{Ratio: ratio}}, &result)
+	return result.Disconnected, err
+}
+
+// 查询消息模板，event/locale/channel 为空表示不限
+func (c *Client) Templates(event, locale, channel string) ([]service.MessageTemplate, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{"event": event, "locale": locale, "channel": channel} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	var templates []service.MessageTemplate
+	err := c.do(request{method: http.MethodGet, path: "/admin/templates", query: query}, &templates)
+	return templates, err
+}
+
+// 新增或覆盖消息模板（仅在内存中生效）
+func (c *Client) SetTemplate(tmpl service.MessageTemplate) (service.MessageTemplate, error) {
+	var result service.MessageTemplate
+	err := c.do(request{method: http.MethodPost, path: "/admin/templates", body: tmpl}, &result)
+	return result, err
+}
+
+// 重新加载消息模板文件，返回生效的模板数
+func (c *Client) ReloadTemplates() (int, error) {
+	var result struct {
+		Templates int `json:"templates"`
+	}
+	err := c.do(request{method: http.MethodPost, path: "/admin/templates/reload"}, &result)
+	return result.Templates, err
+}
+
+// 已生成的日终清算文件列表（不含明细）
+func (c *Client) SettlementFiles() ([]service.SettlementFile, error) {
+	var files []service.SettlementFile
+	err := c.do(request{method: http.MethodGet, path: "/admin/settlement-files"}, &files)
+	return files, err
+}
+
+// 查询指定日期的清算文件（含明细与对手方净头寸）
+func (c *Client) SettlementFile(date string) (service.SettlementFile, error) {
+	var file service.SettlementFile
+	err := c.do(request{method: http.MethodGet, path: "/admin/settlement-files/" + url.PathEscape(date),
+		query: url.Values{"format": {"json"}}}, &file)
+	return file, err
+}
+
+// 下载清算文件原文，format 为 csv 或 fixed（定长格式）
+func (c *Client) DownloadSettlementFile(date, format string) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodGet, path: "/admin/settlement-files/" + url.PathEscape(date),
+		query: url.Values{"format": {format}}})
+}
+
+// 生成监管报表（report 为 large-cash 或 daily-cash），from/to 为空时取当日
+func (c *Client) RegulatoryReport(report, from, to string) (service.RegulatoryReport, error) {
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+
+	var result service.RegulatoryReport
+	err := c.do(request{method: http.MethodGet, path: "/admin/reports/" + url.PathEscape(report), query: query}, &result)
+	return result, err
+}
+
+// 导出监管报表 CSV
+func (c *Client) DownloadRegulatoryReport(report, from, to string) ([]byte, error) {
+	query := url.Values{"format": {"csv"}}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	return c.doRaw(request{method: http.MethodGet, path: "/admin/reports/" + url.PathEscape(report), query: query})
+}
+
+// 查询资产负债表（管理员），asOf 为空表示今日
+func (c *Client) BalanceSheet(asOf string) (service.BalanceSheet, error) {
+	query := url.Values{}
+	if asOf != "" {
+		query.Set("to", asOf)
+	}
+	var sheet service.BalanceSheet
+	err := c.do(request{method: http.MethodGet, path: "/admin/financials/balance-sheet", query: query}, &sheet)
+	return sheet, err
+}
+
+// 查询损益表（管理员），period 为 YYYY-MM，为空表示本月初至今日
+func (c *Client) ProfitAndLoss(period string) (service.ProfitLoss, error) {
+	query := url.Values{}
+	if period != "" {
+		query.Set("period", period)
+	}
+	var report service.ProfitLoss
+	err := c.do(request{method: http.MethodGet, path: "/admin/financials/profit-loss", query: query}, &report)
+	return report, err
+}
+
+// 导出财务报表 CSV（statement 为 balance-sheet 或 profit-loss）
+func (c *Client) DownloadFinancialStatement(statement, from, to string) ([]byte, error) {
+	query := url.Values{"format": {"csv"}}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	return c.doRaw(request{method: http.MethodGet, path: "/admin/financials/" + url.PathEscape(statement), query: query})
+}
+
+// 资金头寸看板（管理员）
+func (c *Client) Treasury() (service.TreasuryDashboard, error) {
+	var dashboard service.TreasuryDashboard
+	err := c.do(request{method: http.MethodGet, path: "/admin/treasury"}, &dashboard)
+	return dashboard, err
+}
+
+// 调整准备金率与大额清算门槛（管理员）
+func (c *Client) SetTreasuryConfig(req service.TreasuryConfigRequest) (service.TreasuryDashboard, error) {
+	var dashboard service.TreasuryDashboard
+	err := c.do(request{method: http.MethodPost, path: "/admin/treasury", body: req}, &dashboard)
+	return dashboard, err
+}
+
+// 查询利息代扣税汇总，from/to 为空表示不限
+func (c *Client) WithholdingTax(from, to string) (service.WithholdingTaxReport, error) {
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+
+	var report service.WithholdingTaxReport
+	err := c.do(request{method: http.MethodGet, path: "/admin/tax/withholding", query: query}, &report)
+	return report, err
+}
+
+// 查询营业日历，from/to 为空时自今日起 30 天
+func (c *Client) Calendar(from, to string) (service.CalendarView, error) {
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+
+	var view service.CalendarView
+	err := c.do(request{method: http.MethodGet, path: "/calendar", query: query}, &view)
+	return view, err
+}
+
+// 节假日列表（管理员）
+func (c *Client) Holidays() ([]service.Holiday, error) {
+	var holidays []service.Holiday
+	err := c.do(request{method: http.MethodGet, path: "/admin/calendar/holidays"}, &holidays)
+	return holidays, err
+}
+
+// 新增或修改节假日（管理员）
+func (c *Client) AddHoliday(date, name string) (service.Holiday, error) {
+	var holiday service.Holiday
+	err := c.do(request{method: http.MethodPost, path: "/admin/calendar/holidays",
+		body: service.Holiday{Date: date, Name: name}}, &holiday)
+	return holiday, err
+}
+
+// 删除节假日（管理员）
+func (c *Client) RemoveHoliday(date string) error {
+	return c.do(request{method: http.MethodDelete, path: "/admin/calendar/holidays",
+		query: url.Values{"date": {date}}}, nil)
+}
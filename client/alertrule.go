@@ -0,0 +1,40 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户的余额提醒规则
+func (c *Client) AlertRules(accountID string) ([]service.AlertRule, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var rules []service.AlertRule
+	err := c.do(request{method: http.MethodGet, path: "/alerts/rules", query: query}, &rules)
+	return rules, err
+}
+
+// 创建余额提醒规则（如余额低于 500、单笔支出超过 1000），触发时推送 balanceAlert 消息
+func (c *Client) CreateAlertRule(req service.AlertRuleRequest) (service.AlertRule, error) {
+	var rule service.AlertRule
+	err := c.do(request{method: http.MethodPost, path: "/alerts/rules", body: req}, &rule)
+	return rule, err
+}
+
+// 修改提醒规则的阈值或启用状态
+func (c *Client) UpdateAlertRule(ruleID string, req service.AlertRuleUpdateRequest) (service.AlertRule, error) {
+	var rule service.AlertRule
+	err := c.do(request{method: http.MethodPut, path: "/alerts/rules/" + url.PathEscape(ruleID), body: req}, &rule)
+	return rule, err
+}
+
+// 删除提醒规则
+func (c *Client) DeleteAlertRule(ruleID string) (service.AlertRule, error) {
+	var rule service.AlertRule
+	err := c.do(request{method: http.MethodDelete, path: "/alerts/rules/" + url.PathEscape(ruleID)}, &rule)
+	return rule, err
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户绑定的收款别名，accountID 为空时查询默认账户
+func (c *Client) Aliases(accountID string) ([]service.Alias, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var aliases []service.Alias
+	err := c.do(request{method: http.MethodGet, path: "/aliases", query: query}, &aliases)
+	return aliases, err
+}
+
+// 申请绑定收款别名（手机号或邮箱），验证码发往该别名，须调用 ConfirmAlias 确认
+func (c *Client) ClaimAlias(accountID, alias string) (service.AliasClaim, error) {
+	var claim service.AliasClaim
+	err := c.do(request{method: http.MethodPost, path: "/aliases", body: service.AliasRequest{AccountID: accountID, Alias: alias}}, &claim)
+	return claim, err
+}
+
+// 确认收款别名绑定
+func (c *Client) ConfirmAlias(claimID, code string) (service.Alias, error) {
+	var alias service.Alias
+	err := c.do(request{method: http.MethodPost, path: "/aliases/confirm", body: service.AliasConfirmRequest{ClaimID: claimID, Code: code}}, &alias)
+	return alias, err
+}
+
+// 解绑收款别名
+func (c *Client) RemoveAlias(accountID, alias string) (service.Alias, error) {
+	query := url.Values{"alias": {alias}}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var removed service.Alias
+	err := c.do(request{method: http.MethodDelete, path: "/aliases", query: query}, &removed)
+	return removed, err
+}
+
+// 转账前按别名核对收款人（户名与账号脱敏）
+func (c *Client) LookupAlias(alias string) (service.AliasLookup, error) {
+	var lookup service.AliasLookup
+	err := c.do(request{method: http.MethodGet, path: "/aliases/lookup", query: url.Values{"alias": {alias}}}, &lookup)
+	return lookup, err
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户月度现金流汇总：from/to 为 YYYY-MM（为空表示不限），groupBy 为空或 category/counterparty；
+// accountID 为空时查询默认账户
+func (c *Client) Cashflow(accountID, from, to, groupBy string) (service.CashflowReport, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{"accountId": accountID, "from": from, "to": to, "groupBy": groupBy} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	var report service.CashflowReport
+	err := c.do(request{method: http.MethodGet, path: "/analytics/cashflow", query: query}, &report)
+	return report, err
+}
+
+// 识别账户的周期性支出（accountID 为空时查询默认账户）
+func (c *Client) RecurringPayments(accountID string) ([]service.RecurringPayment, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var payments []service.RecurringPayment
+	err := c.do(request{method: http.MethodGet, path: "/analytics/recurring", query: query}, &payments)
+	return payments, err
+}
+
+// 查询账户某月的收付款日历：month 为 YYYY-MM（为空表示本月），accountID 为空时查询默认账户
+func (c *Client) PaymentCalendar(accountID, month string) (service.PaymentCalendar, error) {
+	query := url.Values{}
+	query.Set("month", month)
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var calendar service.PaymentCalendar
+	err := c.do(request{method: http.MethodGet, path: "/calendar", query: query}, &calendar)
+	return calendar, err
+}
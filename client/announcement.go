@@ -0,0 +1,33 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询广播公告（管理员），status 为空时返回全部
+func (c *Client) Announcements(status string) ([]service.Announcement, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var announcements []service.Announcement
+	err := c.do(request{method: http.MethodGet, path: "/admin/broadcast", query: query}, &announcements)
+	return announcements, err
+}
+
+// 发布广播公告（管理员）：推送给全部在线客户端并存入通知中心，可定时发布、设置过期时间
+func (c *Client) Broadcast(req service.AnnouncementRequest) (service.Announcement, error) {
+	var result service.Announcement
+	err := c.do(request{method: http.MethodPost, path: "/admin/broadcast", body: req}, &result)
+	return result, err
+}
+
+// 取消待发布的广播公告（管理员）
+func (c *Client) CancelBroadcast(announcementID string) (service.Announcement, error) {
+	var result service.Announcement
+	err := c.do(request{method: http.MethodDelete, path: "/admin/broadcast/" + url.PathEscape(announcementID)}, &result)
+	return result, err
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户的月度预算及使用进度，month 为 YYYY-MM（为空表示当月）
+func (c *Client) Budgets(accountID, month string) ([]service.BudgetProgress, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if month != "" {
+		query.Set("month", month)
+	}
+	var budgets []service.BudgetProgress
+	err := c.do(request{method: http.MethodGet, path: "/budgets", query: query}, &budgets)
+	return budgets, err
+}
+
+// 创建月度预算（如 card 类每月 3000），当月支出达到 80%、100% 时推送 budgetAlert 消息
+func (c *Client) CreateBudget(req service.BudgetRequest) (service.BudgetProgress, error) {
+	var budget service.BudgetProgress
+	err := c.do(request{method: http.MethodPost, path: "/budgets", body: req}, &budget)
+	return budget, err
+}
+
+// 修改预算金额或启用状态
+func (c *Client) UpdateBudget(budgetID string, req service.BudgetUpdateRequest) (service.BudgetProgress, error) {
+	var budget service.BudgetProgress
+	err := c.do(request{method: http.MethodPut, path: "/budgets/" + url.PathEscape(budgetID), body: req}, &budget)
+	return budget, err
+}
+
+// 删除预算
+func (c *Client) DeleteBudget(budgetID string) (service.BudgetProgress, error) {
+	var budget service.BudgetProgress
+	err := c.do(request{method: http.MethodDelete, path: "/budgets/" + url.PathEscape(budgetID)}, &budget)
+	return budget, err
+}
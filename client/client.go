@@ -0,0 +1,199 @@
+// Package client 是数字银行核心业务模拟系统的 Go 客户端 SDK，封装全部 REST 接口与 WebSocket 事件流。
+//
+// 业务失败（响应 code 非 200）统一以 *model.BizError 返回，可通过 model.ErrorCode 取得业务码。
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 接口路径
+const apiBasePath = "/api"
+
+// 客户端
+type Client struct {
+	baseURL string
+	token   string // 访问令牌（开放银行 AIS/PIS 接口使用），以 Bearer 方式携带
+	session string // 登录令牌，以 X-Session-Token 请求头携带
+	device  string // 设备标识，以 X-Device-Id 请求头携带
+
+	HTTPClient *http.Client
+}
+
+// 创建客户端，baseURL 形如 http://localhost:8080，token 可为空
+func New(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// 返回使用新访问令牌的客户端副本（如开放银行授权成功后）
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.token = token
+	return &clone
+}
+
+// 返回携带登录令牌的客户端副本（登录成功后使用，会话失效后请求将被拒绝）
+func (c *Client) WithSession(token string) *Client {
+	clone := *c
+	clone.session = token
+	return &clone
+}
+
+// 返回携带设备标识的客户端副本（登录与转账按设备识别，未信任设备转账须短信验证码）
+func (c *Client) WithDevice(deviceID string) *Client {
+	clone := *c
+	clone.device = deviceID
+	return &clone
+}
+
+// 统一响应格式（data 延迟解析）
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// 单次请求参数
+type request struct {
+	method  string
+	path    string
+	query   url.Values
+	body    interface{} // JSON 请求体
+	raw     []byte      // 原始请求体（优先于 body）
+	rawType string      // 原始请求体的 Content-Type
+	ifMatch int64       // 期望账户版本，0 表示不携带 If-Match
+
+	ifNoneMatch string // 上次响应的 ETag，为空表示不携带 If-None-Match
+}
+
+// 发送请求并返回 HTTP 响应（调用方负责关闭 Body）
+func (c *Client) send(req request) (*http.Response, error) {
+	target := c.baseURL + apiBasePath + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := ""
+	switch {
+	case req.raw != nil:
+		body, contentType = bytes.NewReader(req.raw), req.rawType
+	case req.body != nil:
+		data, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("请求参数序列化失败: %v", err)
+		}
+		body, contentType = bytes.NewReader(data), "application/json"
+	}
+
+	httpReq, err := http.NewRequest(req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.session != "" {
+		httpReq.Header.Set("X-Session-Token", c.session)
+	}
+	if c.device != "" {
+		httpReq.Header.Set("X-Device-Id", c.device)
+	}
+	if req.ifMatch > 0 {
+		httpReq.Header.Set("If-Match", strconv.Quote(strconv.FormatInt(req.ifMatch, 10)))
+	}
+	if req.ifNoneMatch != "" {
+		httpReq.Header.Set("If-None-Match", req.ifNoneMatch)
+	}
+	return c.HTTPClient.Do(httpReq)
+}
+
+// 发送请求并将 data 解析到 out，业务失败时返回 *model.BizError
+func (c *Client) do(req request, out interface{}) error {
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeEnvelope(resp, out)
+}
+
+// 发送条件 GET 请求并返回响应的 ETag；服务端返回 304（数据未变化）时 changed 为 false，不解析 out
+func (c *Client) doConditional(req request, out interface{}) (etag string, changed bool, err error) {
+	resp, err := c.send(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	etag = resp.Header.Get("ETag")
+	if resp.StatusCode == http.StatusNotModified {
+		return etag, false, nil
+	}
+	return etag, true, decodeEnvelope(resp, out)
+}
+
+// 发送请求并返回原始报文（XML/文本导出），业务失败时返回 *model.BizError
+func (c *Client) doRaw(req request) ([]byte, error) {
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 失败时服务端仍返回统一 JSON 格式
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return nil, decodeEnvelope(resp, nil)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// 解析统一响应格式
+func decodeEnvelope(resp *http.Response, out interface{}) error {
+	// 服务端开启严格 HTTP 状态码时，业务错误也以统一格式返回，按业务码处理
+	if resp.StatusCode != http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return fmt.Errorf("服务端返回 HTTP %d", resp.StatusCode)
+	}
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("响应解析失败: %v", err)
+	}
+	// 部分接口失败时也携带数据（如 PIS 支付记录），一并解析
+	if out != nil && len(env.Data) > 0 && string(env.Data) != "null" {
+		if err := json.Unmarshal(env.Data, out); err != nil && env.Code == model.CODE_SUCCESS {
+			return fmt.Errorf("响应数据解析失败: %v", err)
+		}
+	}
+	if env.Code != model.CODE_SUCCESS {
+		// 参数校验失败时返回逐字段错误
+		var fields []model.FieldError
+		if env.Code == model.CODE_PARAM_ERROR && json.Unmarshal(env.Data, &fields) == nil && len(fields) > 0 && fields[0].Field != "" {
+			return &model.ValidationError{Fields: fields}
+		}
+		return model.NewError(env.Code, env.Message)
+	}
+	return nil
+}
+
+// 日期格式（YYYY-MM-DD）
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
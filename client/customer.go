@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询客户资料（customerID 为空时按 accountID 所属客户，均为空时使用默认账户）
+func (c *Client) CustomerProfile(customerID, accountID string) (service.CustomerProfile, error) {
+	var profile service.CustomerProfile
+	err := c.do(request{method: http.MethodGet, path: "/customer/profile", query: customerQuery(customerID, accountID)}, &profile)
+	return profile, err
+}
+
+// 修改客户资料（手机号、邮箱变更返回待验证申请，须调用 ConfirmContactChange 确认）
+func (c *Client) UpdateCustomerProfile(customerID, accountID string, req service.CustomerUpdateRequest) (service.CustomerProfile, error) {
+	var profile service.CustomerProfile
+	err := c.do(request{method: http.MethodPut, path: "/customer/profile", query: customerQuery(customerID, accountID), body: req}, &profile)
+	return profile, err
+}
+
+// 查询联系方式变更记录
+func (c *Client) ContactChanges(customerID, accountID string) ([]service.ContactChange, error) {
+	var changes []service.ContactChange
+	err := c.do(request{method: http.MethodGet, path: "/customer/contact/changes", query: customerQuery(customerID, accountID)}, &changes)
+	return changes, err
+}
+
+// 确认联系方式变更（验证码发往新的手机号或邮箱）
+func (c *Client) ConfirmContactChange(changeID, code string) (service.CustomerProfile, error) {
+	var profile service.CustomerProfile
+	err := c.do(request{method: http.MethodPost, path: "/customer/contact/confirm", body: service.ContactConfirmRequest{ChangeID: changeID, Code: code}}, &profile)
+	return profile, err
+}
+
+// 查询审计日志（管理员），条件为空表示不限
+func (c *Client) AuditLog(target, action string) ([]service.AuditEntry, error) {
+	query := url.Values{}
+	if target != "" {
+		query.Set("target", target)
+	}
+	if action != "" {
+		query.Set("action", action)
+	}
+	var entries []service.AuditEntry
+	err := c.do(request{method: http.MethodGet, path: "/admin/audit", query: query}, &entries)
+	return entries, err
+}
+
+// 按游标分页查询审计日志（管理员），cursor 为上一页的 NextCursor，为空表示第一页
+func (c *Client) AuditLogByCursor(target, action, cursor string, limit int) (service.AuditPage, error) {
+	query := cursorQuery(cursor, limit)
+	if target != "" {
+		query.Set("target", target)
+	}
+	if action != "" {
+		query.Set("action", action)
+	}
+	var page service.AuditPage
+	err := c.do(request{method: http.MethodGet, path: "/admin/audit", query: query}, &page)
+	return page, err
+}
+
+// 客户定位参数
+func customerQuery(customerID, accountID string) url.Values {
+	query := url.Values{}
+	if customerID != "" {
+		query.Set("customerId", customerID)
+	}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	return query
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 路径
+const wsPath = "/ws"
+
+// WebSocket 事件流（余额变动、交易提醒及已订阅主题的推送）
+type EventStream struct {
+	conn    *websocket.Conn
+	events  chan ws.Message
+	writeMu sync.Mutex // 订阅指令写入互斥（gorilla 连接不支持并发写）
+
+	errMu sync.Mutex
+	err   error
+}
+
+// 建立 WebSocket 连接并订阅指定主题（如 rates、alerts）
+func (c *Client) Events(topics ...string) (*EventStream, error) {
+	return c.dialEvents(url.Values{}, topics)
+}
+
+// 长轮询结果（v1 扁平消息格式）
+type EventPoll struct {
+	Events         []ws.Message `json:"events"`
+	LatestEventID  uint64       `json:"latestEventId"` // 作为下一次长轮询的 since
+	OldestEventID  uint64       `json:"oldestEventId,omitempty"`
+	ResyncRequired bool         `json:"resyncRequired,omitempty"` // 所需事件已超出服务端缓冲区，应全量同步
+}
+
+// 长轮询当前登录账户的事件（无法建立 WebSocket 时使用）：返回序号 since 之后的事件，since 为 0 时从当前最新事件开始等待；
+// 暂无新事件时服务端最多等待 wait（不超过 30 秒，0 表示服务端默认值）后返回空列表
+func (c *Client) PollEvents(since uint64, wait time.Duration) (EventPoll, error) {
+	query := url.Values{"version": {strconv.Itoa(ws.SCHEMA_V1)}}
+	if since > 0 {
+		query.Set("since", strconv.FormatUint(since, 10))
+	}
+	if wait > 0 {
+		query.Set("wait", strconv.Itoa(int(wait/time.Second)))
+	}
+
+	// 请求超时须长于服务端等待时间
+	clone := *c
+	if c.HTTPClient.Timeout > 0 {
+		httpClient := *c.HTTPClient
+		httpClient.Timeout += 30 * time.Second
+		clone.HTTPClient = &httpClient
+	}
+	var result EventPoll
+	err := clone.do(request{method: http.MethodGet, path: "/events/poll", query: query}, &result)
+	return result, err
+}
+
+// 建立账户 WebSocket 连接：只接收本账户消息、全员广播及已订阅主题消息。
+// lastEventID 为断线前收到的最后事件序号（ws.Message.Seq），大于 0 时服务端先补发之后的事件
+func (c *Client) AccountEvents(accountID string, lastEventID uint64, topics ...string) (*EventStream, error) {
+	query := url.Values{"accountId": {accountID}}
+	if lastEventID > 0 {
+		query.Set("lastEventId", strconv.FormatUint(lastEventID, 10))
+	}
+	return c.dialEvents(query, topics)
+}
+
+// 建立管理员 WebSocket 连接（adminKey 为服务端 WS_ADMIN_KEY），可订阅 admin. 前缀主题，
+// 如 ws.TOPIC_ADMIN_TRANSACTIONS（全行交易流水，可配合 SubscribeFiltered 按账户或金额过滤）
+func (c *Client) AdminEvents(adminKey string, topics ...string) (*EventStream, error) {
+	return c.dialEvents(url.Values{"adminKey": {adminKey}}, topics)
+}
+
+func (c *Client) dialEvents(query url.Values, topics []string) (*EventStream, error) {
+	// 已登录时连接绑定到会话，会话被终止（退出登录、强制下线、账户冻结）时服务端断开连接
+	if c.session != "" && query.Get("adminKey") == "" {
+		query.Set("token", c.session)
+	}
+	// 固定使用 v1 扁平消息格式，服务端默认版本变化时不影响 SDK 解析
+	query.Set("version", strconv.Itoa(ws.SCHEMA_V1))
+	target := "ws" + strings.TrimPrefix(c.baseURL, "http") + wsPath + "?" + query.Encode()
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	// 提出 permessage-deflate 扩展，服务端启用 WS_COMPRESSION 时消息压缩传输
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = true
+	conn, _, err := dialer.Dial(target, header)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &EventStream{conn: conn, events: make(chan ws.Message, 64)}
+	for _, topic := range topics {
+		if err := stream.Subscribe(topic); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	go stream.readLoop()
+	return stream, nil
+}
+
+// 事件通道（连接断开后关闭，原因见 Err）
+func (s *EventStream) Events() <-chan ws.Message {
+	return s.events
+}
+
+// 订阅主题
+func (s *EventStream) Subscribe(topic string) error {
+	return s.command(ws.ClientMessage{Action: "subscribe", Topic: topic})
+}
+
+// 订阅主题并设置过滤条件（仅对支持过滤的主题生效，如 ws.TOPIC_ADMIN_TRANSACTIONS），重复订阅时替换原条件
+func (s *EventStream) SubscribeFiltered(topic string, filter ws.Filter) error {
+	return s.command(ws.ClientMessage{Action: "subscribe", Topic: topic, Filter: &filter})
+}
+
+// 退订主题
+func (s *EventStream) Unsubscribe(topic string) error {
+	return s.command(ws.ClientMessage{Action: "unsubscribe", Topic: topic})
+}
+
+// 发送在线客服消息（账户连接），客服回复以 supportChat 类型推送
+func (s *EventStream) Chat(text string) error {
+	return s.command(ws.ClientMessage{Action: "chat", Text: text})
+}
+
+// 回复客服会话（管理员连接），会话动态见 ws.TOPIC_ADMIN_SUPPORT 主题
+func (s *EventStream) ReplyChat(conversationID, agent, text string) error {
+	return s.command(ws.ClientMessage{Action: "chat", ConversationID: conversationID, Agent: agent, Text: text})
+}
+
+// 关闭连接
+func (s *EventStream) Close() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return s.conn.Close()
+}
+
+// 连接异常断开的原因（正常关闭时为 nil）
+func (s *EventStream) Err() error {
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	return s.err
+}
+
+// 发送订阅/退订/客服消息指令
+func (s *EventStream) command(cmd ws.ClientMessage) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteJSON(cmd)
+}
+
+// 读取推送消息并转发到事件通道
+func (s *EventStream) readLoop() {
+	defer close(s.events)
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) && !errors.Is(err, net.ErrClosed) {
+				s.errMu.Lock()
+				s.err = err
+				s.errMu.Unlock()
+			}
+			return
+		}
+
+		var msg ws.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		s.events <- msg
+	}
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询全部功能开关（管理员）
+func (c *Client) FeatureFlags() ([]service.FeatureFlag, error) {
+	var flags []service.FeatureFlag
+	err := c.do(request{method: http.MethodGet, path: "/admin/flags"}, &flags)
+	return flags, err
+}
+
+// 设置功能开关（管理员，即时生效）
+func (c *Client) SetFeatureFlag(name string, enabled bool) (service.FeatureFlag, error) {
+	var flag service.FeatureFlag
+	body := service.FeatureFlagRequest{Enabled: &enabled}
+	err := c.do(request{method: http.MethodPut, path: "/admin/flags/" + url.PathEscape(name), body: body}, &flag)
+	return flag, err
+}
+
+// 撤销功能开关的运行时设置，恢复为配置文件或默认值（管理员）
+func (c *Client) ResetFeatureFlag(name string) (service.FeatureFlag, error) {
+	var flag service.FeatureFlag
+	err := c.do(request{method: http.MethodDelete, path: "/admin/flags/" + url.PathEscape(name)}, &flag)
+	return flag, err
+}
+
+// 重新读取服务端功能开关配置文件（管理员）
+func (c *Client) ReloadFeatureFlags() ([]service.FeatureFlag, error) {
+	var flags []service.FeatureFlag
+	err := c.do(request{method: http.MethodPost, path: "/admin/flags/reload"}, &flags)
+	return flags, err
+}
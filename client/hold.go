@@ -0,0 +1,80 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户冻结汇总（账面/冻结/可用余额）及冻结记录，status 为空表示不限
+func (c *Client) Holds(accountID, status string) (service.HoldSummary, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var summary service.HoldSummary
+	err := c.do(request{method: http.MethodGet, path: "/holds", query: query}, &summary)
+	return summary, err
+}
+
+// 设置资金冻结
+func (c *Client) PlaceHold(req service.HoldRequest) (service.Hold, error) {
+	var hold service.Hold
+	err := c.do(request{method: http.MethodPost, path: "/holds", body: req}, &hold)
+	return hold, err
+}
+
+// 查询单笔冻结
+func (c *Client) Hold(holdID string) (service.Hold, error) {
+	var hold service.Hold
+	err := c.do(request{method: http.MethodGet, path: "/holds/" + url.PathEscape(holdID)}, &hold)
+	return hold, err
+}
+
+// 修改冻结的金额、原因或到期时间
+func (c *Client) ModifyHold(holdID string, req service.HoldUpdateRequest) (service.Hold, error) {
+	var hold service.Hold
+	err := c.do(request{method: http.MethodPut, path: "/holds/" + url.PathEscape(holdID), body: req}, &hold)
+	return hold, err
+}
+
+// 解除冻结
+func (c *Client) ReleaseHold(holdID string) (service.Hold, error) {
+	var hold service.Hold
+	err := c.do(request{method: http.MethodDelete, path: "/holds/" + url.PathEscape(holdID)}, &hold)
+	return hold, err
+}
+
+// 查询司法冻结（管理员），accountID、status 为空表示不限
+func (c *Client) LegalHolds(accountID, status string) ([]service.Hold, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var holds []service.Hold
+	err := c.do(request{method: http.MethodGet, path: "/admin/legal-holds", query: query}, &holds)
+	return holds, err
+}
+
+// 设置司法冻结（管理员）
+func (c *Client) PlaceLegalHold(req service.LegalHoldRequest) (service.Hold, error) {
+	var hold service.Hold
+	err := c.do(request{method: http.MethodPost, path: "/admin/legal-holds", body: req}, &hold)
+	return hold, err
+}
+
+// 部分或全部解除司法冻结（管理员）
+func (c *Client) ReleaseLegalHold(holdID string, req service.LegalHoldReleaseRequest) (service.Hold, error) {
+	var hold service.Hold
+	err := c.do(request{method: http.MethodPost, path: "/admin/legal-holds/" + url.PathEscape(holdID) + "/release", body: req}, &hold)
+	return hold, err
+}
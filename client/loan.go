@@ -0,0 +1,74 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 信用评分及贷款审批规则
+type CreditScoreResult struct {
+	Score     service.CreditScore `json:"score"`
+	LoanRules []service.LoanRule  `json:"loanRules"`
+}
+
+// 查询账户信用评分，accountID 为空时查询默认账户
+func (c *Client) CreditScore(accountID string) (CreditScoreResult, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var result CreditScoreResult
+	err := c.do(request{method: http.MethodGet, path: "/credit/score", query: query}, &result)
+	return result, err
+}
+
+// 申请贷款（按信用评分自动审批，通过后立即放款）
+func (c *Client) ApplyLoan(req service.LoanApplication) (service.Loan, error) {
+	var loan service.Loan
+	err := c.do(request{method: http.MethodPost, path: "/loans", body: req}, &loan)
+	return loan, err
+}
+
+// 查询账户贷款列表，accountID 为空时查询默认账户
+func (c *Client) Loans(accountID string) ([]service.Loan, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var loans []service.Loan
+	err := c.do(request{method: http.MethodGet, path: "/loans", query: query}, &loans)
+	return loans, err
+}
+
+// 查询贷款及还款计划
+func (c *Client) Loan(loanID string) (service.Loan, error) {
+	var loan service.Loan
+	err := c.do(request{method: http.MethodGet, path: "/loans/" + url.PathEscape(loanID)}, &loan)
+	return loan, err
+}
+
+// 提前还款试算（剩余本金、应计利息与违约金）
+func (c *Client) LoanPayoffQuote(loanID string) (service.PayoffQuote, error) {
+	var quote service.PayoffQuote
+	err := c.do(request{method: http.MethodGet, path: "/loans/" + url.PathEscape(loanID) + "/payoff-quote"}, &quote)
+	return quote, err
+}
+
+// 提前还款，full 为 true 时全额结清，否则按 amount 部分还款并重算还款计划
+func (c *Client) RepayLoan(loanID string, amount float64, full bool) (service.LoanRepayResult, error) {
+	var result service.LoanRepayResult
+	err := c.do(request{method: http.MethodPost, path: "/loans/" + url.PathEscape(loanID) + "/repay",
+		body: service.LoanRepayRequest{Amount: amount, Full: full}}, &result)
+	return result, err
+}
+
+// 贷款催收看板（逾期贷款、各逾期档位笔数与最近的催收事件）
+func (c *Client) Collections() (service.CollectionsReport, error) {
+	var report service.CollectionsReport
+	err := c.do(request{method: http.MethodGet, path: "/admin/collections"}, &report)
+	return report, err
+}
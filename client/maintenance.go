@@ -0,0 +1,40 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询维护状态（是否维护中及待开始的维护窗口）
+func (c *Client) MaintenanceStatus() (service.MaintenanceStatus, error) {
+	var status service.MaintenanceStatus
+	err := c.do(request{method: http.MethodGet, path: "/maintenance"}, &status)
+	return status, err
+}
+
+// 查询维护窗口（管理员），status 为空时返回全部
+func (c *Client) MaintenanceWindows(status string) ([]service.MaintenanceWindow, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var windows []service.MaintenanceWindow
+	err := c.do(request{method: http.MethodGet, path: "/admin/maintenance", query: query}, &windows)
+	return windows, err
+}
+
+// 立即开启（未指定开始时间）或预约维护窗口（管理员）
+func (c *Client) ScheduleMaintenance(req service.MaintenanceRequest) (service.MaintenanceWindow, error) {
+	var window service.MaintenanceWindow
+	err := c.do(request{method: http.MethodPost, path: "/admin/maintenance", body: req}, &window)
+	return window, err
+}
+
+// 结束维护中的窗口或取消待开始的窗口（管理员）
+func (c *Client) EndMaintenance(windowID string) (service.MaintenanceWindow, error) {
+	var window service.MaintenanceWindow
+	err := c.do(request{method: http.MethodDelete, path: "/admin/maintenance/" + url.PathEscape(windowID)}, &window)
+	return window, err
+}
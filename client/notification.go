@@ -0,0 +1,64 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户通知及未读数，unreadOnly 为 true 时只返回未读
+func (c *Client) Notifications(accountID string, unreadOnly bool) (service.NotificationList, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if unreadOnly {
+		query.Set("unreadOnly", "true")
+	}
+
+	var list service.NotificationList
+	err := c.do(request{method: http.MethodGet, path: "/notifications", query: query}, &list)
+	return list, err
+}
+
+// 按游标分页查询账户通知（按时间倒序），cursor 为上一页的 NextCursor，为空表示第一页
+func (c *Client) NotificationsByCursor(accountID string, unreadOnly bool, cursor string, limit int) (service.NotificationPage, error) {
+	query := cursorQuery(cursor, limit)
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if unreadOnly {
+		query.Set("unreadOnly", "true")
+	}
+
+	var page service.NotificationPage
+	err := c.do(request{method: http.MethodGet, path: "/notifications", query: query}, &page)
+	return page, err
+}
+
+// 将通知标记为已读
+func (c *Client) MarkNotificationRead(accountID, notificationID string) (service.Notification, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var notification service.Notification
+	err := c.do(request{method: http.MethodPost, path: "/notifications/" + url.PathEscape(notificationID) + "/read", query: query}, &notification)
+	return notification, err
+}
+
+// 发送短信验证码（验证码本身仅通过短信下发）
+func (c *Client) SendOTP(accountID, purpose string) (service.OTPResult, error) {
+	var result service.OTPResult
+	err := c.do(request{method: http.MethodPost, path: "/otp/send",
+		body: service.OTPRequest{AccountID: accountID, Purpose: purpose}}, &result)
+	return result, err
+}
+
+// 校验短信验证码
+func (c *Client) VerifyOTP(accountID, purpose, code string) error {
+	return c.do(request{method: http.MethodPost, path: "/otp/verify",
+		body: service.OTPVerifyRequest{AccountID: accountID, Purpose: purpose, Code: code}}, nil)
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 同意书授权结果
+type ConsentAuthorization struct {
+	Consent service.Consent     `json:"consent"`
+	Token   service.AccessToken `json:"token"`
+}
+
+// AIS 账户基本信息
+type AISAccount struct {
+	AccountID string `json:"accountId"`
+	Name      string `json:"name"`
+	Currency  string `json:"currency"`
+	Status    string `json:"status"`
+}
+
+// AIS 余额
+type AISBalance struct {
+	AccountID string  `json:"accountId"`
+	Balance   float64 `json:"balance"`
+	Currency  string  `json:"currency"`
+	AsOf      string  `json:"asOf"`
+}
+
+// -------------------------- 同意书管理 --------------------------
+
+// 第三方创建同意书（待客户授权）
+func (c *Client) CreateConsent(req service.ConsentRequest) (service.Consent, error) {
+	var consent service.Consent
+	err := c.do(request{method: http.MethodPost, path: "/openbanking/consents", body: req}, &consent)
+	return consent, err
+}
+
+// 客户授权同意书，返回同意书与访问令牌（可用 WithToken 构造第三方客户端）
+func (c *Client) AuthorizeConsent(consentID string) (ConsentAuthorization, error) {
+	var result ConsentAuthorization
+	err := c.do(request{method: http.MethodPost, path: "/openbanking/consents/authorize",
+		body: service.ConsentAuthorizeRequest{ConsentID: consentID, Approve: true}}, &result)
+	return result, err
+}
+
+// 客户拒绝同意书
+func (c *Client) RejectConsent(consentID string) (service.Consent, error) {
+	var consent service.Consent
+	err := c.do(request{method: http.MethodPost, path: "/openbanking/consents/authorize",
+		body: service.ConsentAuthorizeRequest{ConsentID: consentID}}, &consent)
+	return consent, err
+}
+
+// 查询同意书状态
+func (c *Client) Consent(consentID string) (service.Consent, error) {
+	var consent service.Consent
+	err := c.do(request{method: http.MethodGet, path: "/openbanking/consent",
+		query: url.Values{"consentId": {consentID}}}, &consent)
+	return consent, err
+}
+
+// 撤销同意书
+func (c *Client) RevokeConsent(consentID string) (service.Consent, error) {
+	var consent service.Consent
+	err := c.do(request{method: http.MethodPost, path: "/openbanking/consents/revoke",
+		body: service.ConsentAuthorizeRequest{ConsentID: consentID}}, &consent)
+	return consent, err
+}
+
+// -------------------------- AIS/PIS（需访问令牌） --------------------------
+
+// AIS：查询授权账户基本信息
+func (c *Client) AISAccounts() ([]AISAccount, error) {
+	var accounts []AISAccount
+	err := c.do(request{method: http.MethodGet, path: "/openbanking/aisp/accounts"}, &accounts)
+	return accounts, err
+}
+
+// AIS：查询授权账户余额
+func (c *Client) AISBalances() (AISBalance, error) {
+	var balance AISBalance
+	err := c.do(request{method: http.MethodGet, path: "/openbanking/aisp/balances"}, &balance)
+	return balance, err
+}
+
+// AIS：查询授权账户交易流水
+func (c *Client) AISTransactions() ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := c.do(request{method: http.MethodGet, path: "/openbanking/aisp/transactions"}, &transactions)
+	return transactions, err
+}
+
+// PIS：按同意书执行支付；转账失败时同时返回支付记录与业务错误
+func (c *Client) PISPayment() (service.OpenBankingPayment, error) {
+	var payment service.OpenBankingPayment
+	err := c.do(request{method: http.MethodPost, path: "/openbanking/pisp/payments"}, &payment)
+	return payment, err
+}
+
+// PIS：查询支付状态
+func (c *Client) PISPaymentStatus(paymentID string) (service.OpenBankingPayment, error) {
+	var payment service.OpenBankingPayment
+	err := c.do(request{method: http.MethodGet, path: "/openbanking/pisp/payment",
+		query: url.Values{"paymentId": {paymentID}}}, &payment)
+	return payment, err
+}
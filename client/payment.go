@@ -0,0 +1,219 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 汇率牌价
+type FXRates struct {
+	BaseCurrency string           `json:"baseCurrency"`
+	Spread       float64          `json:"spread"`
+	Rates        []service.FXRate `json:"rates"`
+}
+
+// 汇率调整结果
+type FXRateUpdate struct {
+	Currency string  `json:"currency"`
+	MidRate  float64 `json:"midRate"`
+	Spread   float64 `json:"spread"`
+}
+
+// -------------------------- 跨行转账 --------------------------
+
+// 查询可汇入的他行列表
+func (c *Client) ExternalBanks() ([]service.ExternalBank, error) {
+	var banks []service.ExternalBank
+	err := c.do(request{method: http.MethodGet, path: "/interbank/banks"}, &banks)
+	return banks, err
+}
+
+// 跨行转账（立即扣款，进入清算队列）
+func (c *Client) InterbankTransfer(req service.InterbankTransferRequest) (service.InterbankPayment, error) {
+	var payment service.InterbankPayment
+	err := c.do(request{method: http.MethodPost, path: "/interbank/transfer", body: req}, &payment)
+	return payment, err
+}
+
+// 按流水号查询跨行支付状态
+func (c *Client) InterbankPayment(reference string) (service.InterbankPayment, error) {
+	var payment service.InterbankPayment
+	err := c.do(request{method: http.MethodGet, path: "/interbank/payment",
+		query: url.Values{"reference": {reference}}}, &payment)
+	return payment, err
+}
+
+// -------------------------- 直接借记 --------------------------
+
+// 查询账户的直接借记授权，accountID 为空时查询默认账户
+func (c *Client) Mandates(accountID string) ([]service.Mandate, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var mandates []service.Mandate
+	err := c.do(request{method: http.MethodGet, path: "/direct-debits/mandates", query: query}, &mandates)
+	return mandates, err
+}
+
+// 登记直接借记授权（商户侧）
+func (c *Client) RegisterMandate(req service.MandateRequest) (service.Mandate, error) {
+	var mandate service.Mandate
+	err := c.do(request{method: http.MethodPost, path: "/direct-debits/mandates", body: req}, &mandate)
+	return mandate, err
+}
+
+// 撤销直接借记授权（客户侧），accountID 为空时按默认账户
+func (c *Client) CancelMandate(mandateID, accountID string) (service.Mandate, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var mandate service.Mandate
+	err := c.do(request{method: http.MethodDelete, path: "/direct-debits/mandates/" + url.PathEscape(mandateID), query: query}, &mandate)
+	return mandate, err
+}
+
+// 按授权扣款（商户侧）
+func (c *Client) CollectDirectDebit(req service.DirectDebitRequest) (service.DirectDebit, error) {
+	var collection service.DirectDebit
+	err := c.do(request{method: http.MethodPost, path: "/direct-debits/collect", body: req}, &collection)
+	return collection, err
+}
+
+// 查询直接借记扣款记录，mandateID 不为空时只查询该授权下的扣款
+func (c *Client) DirectDebits(accountID, mandateID string) ([]service.DirectDebit, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	if mandateID != "" {
+		query.Set("mandateId", mandateID)
+	}
+
+	var collections []service.DirectDebit
+	err := c.do(request{method: http.MethodGet, path: "/direct-debits/collections", query: query}, &collections)
+	return collections, err
+}
+
+// 在退回期内退回直接借记扣款（客户侧）
+func (c *Client) ReturnDirectDebit(collectionID string, req service.DirectDebitReturnRequest) (service.DirectDebit, error) {
+	var collection service.DirectDebit
+	err := c.do(request{method: http.MethodPost, path: "/direct-debits/collections/" + url.PathEscape(collectionID) + "/return", body: req}, &collection)
+	return collection, err
+}
+
+// -------------------------- 代发工资 --------------------------
+
+// 提交代发批次（付款账户一次扣款，逐笔入账），返回批次结果报告
+func (c *Client) SubmitPayroll(req service.PayrollRequest) (service.PayrollBatch, error) {
+	var batch service.PayrollBatch
+	err := c.do(request{method: http.MethodPost, path: "/payroll/batches", body: req}, &batch)
+	return batch, err
+}
+
+// 查询付款账户的代发批次，accountID 为空时查询默认账户
+func (c *Client) PayrollBatches(accountID string) ([]service.PayrollBatch, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var batches []service.PayrollBatch
+	err := c.do(request{method: http.MethodGet, path: "/payroll/batches", query: query}, &batches)
+	return batches, err
+}
+
+// 查询代发批次结果报告
+func (c *Client) PayrollBatch(batchID string) (service.PayrollBatch, error) {
+	var batch service.PayrollBatch
+	err := c.do(request{method: http.MethodGet, path: "/payroll/batches/" + url.PathEscape(batchID)}, &batch)
+	return batch, err
+}
+
+// -------------------------- 商户收单 --------------------------
+
+// 登记收单商户（管理接口）
+func (c *Client) RegisterMerchant(req service.MerchantRequest) (service.Merchant, error) {
+	var merchant service.Merchant
+	err := c.do(request{method: http.MethodPost, path: "/admin/merchants", body: req}, &merchant)
+	return merchant, err
+}
+
+// 收单商户列表（管理接口）
+func (c *Client) Merchants() ([]service.Merchant, error) {
+	var merchants []service.Merchant
+	err := c.do(request{method: http.MethodGet, path: "/admin/merchants"}, &merchants)
+	return merchants, err
+}
+
+// POS 消费（按卡号或账户ID扣款）
+func (c *Client) POSPurchase(req service.POSPurchaseRequest) (service.POSTransaction, error) {
+	var tx service.POSTransaction
+	err := c.do(request{method: http.MethodPost, path: "/pos/purchase", body: req}, &tx)
+	return tx, err
+}
+
+// POS 退货，amount 为 0 时退还剩余全部金额
+func (c *Client) POSRefund(merchantID, posTxID string, amount float64) (service.POSTransaction, error) {
+	var tx service.POSTransaction
+	err := c.do(request{method: http.MethodPost, path: "/pos/refund",
+		body: service.POSRefundRequest{MerchantID: merchantID, PosTxID: posTxID, Amount: amount}}, &tx)
+	return tx, err
+}
+
+// 查询 POS 交易
+func (c *Client) POSTransaction(posTxID string) (service.POSTransaction, error) {
+	var tx service.POSTransaction
+	err := c.do(request{method: http.MethodGet, path: "/pos/transaction",
+		query: url.Values{"posTxId": {posTxID}}}, &tx)
+	return tx, err
+}
+
+// 商户日结算汇总，date 为空时返回全部日期
+func (c *Client) MerchantSettlements(merchantID, date string) ([]service.MerchantSettlement, error) {
+	query := url.Values{"merchantId": {merchantID}}
+	if date != "" {
+		query.Set("date", date)
+	}
+
+	var settlements []service.MerchantSettlement
+	err := c.do(request{method: http.MethodGet, path: "/merchants/settlement", query: query}, &settlements)
+	return settlements, err
+}
+
+// -------------------------- ISO 20022 批量支付 --------------------------
+
+// 提交 pain.001 批量支付报文，返回 pain.002 状态报告
+func (c *Client) ImportPain001(document []byte) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodPost, path: "/payments/pain001",
+		raw: document, rawType: "application/xml"})
+}
+
+// 按原 pain.001 MsgId 查询 pain.002 状态报告
+func (c *Client) Pain002(msgID string) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodGet, path: "/payments/pain002",
+		query: url.Values{"msgId": {msgID}}})
+}
+
+// -------------------------- 外汇 --------------------------
+
+// 查询汇率牌价
+func (c *Client) FXRates() (FXRates, error) {
+	var rates FXRates
+	err := c.do(request{method: http.MethodGet, path: "/fx/rates"}, &rates)
+	return rates, err
+}
+
+// 设置汇率中间价（管理员）
+func (c *Client) SetFXRate(currency string, rate float64, spread *float64) (FXRateUpdate, error) {
+	var result FXRateUpdate
+	err := c.do(request{method: http.MethodPost, path: "/admin/fx/rates",
+		body: service.FXRateRequest{Currency: strings.ToUpper(currency), Rate: rate, Spread: spread}}, &result)
+	return result, err
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询产品列表（含当前生效参数与待生效参数）
+func (c *Client) Products() ([]service.ProductSchedule, error) {
+	var products []service.ProductSchedule
+	err := c.do(request{method: http.MethodGet, path: "/admin/products"}, &products)
+	return products, err
+}
+
+// 新增产品或调整产品参数，EffectiveDate 为空时自下一次计息起生效
+func (c *Client) DefineProduct(product service.Product) (service.Product, error) {
+	var result service.Product
+	err := c.do(request{method: http.MethodPost, path: "/admin/products", body: product}, &result)
+	return result, err
+}
+
+// 账户绑定产品
+func (c *Client) BindProduct(accountID, productCode string) (service.AccountProduct, error) {
+	var result service.AccountProduct
+	err := c.do(request{method: http.MethodPost, path: "/admin/accounts/product",
+		body: service.ProductBindRequest{AccountID: accountID, ProductCode: productCode}}, &result)
+	return result, err
+}
+
+// 查询账户产品、已计提利息与到期日，accountID 为空时查询默认账户
+func (c *Client) AccountProduct(accountID string) (service.AccountProduct, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var result service.AccountProduct
+	err := c.do(request{method: http.MethodGet, path: "/account/product", query: query}, &result)
+	return result, err
+}
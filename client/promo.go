@@ -0,0 +1,108 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 开户，ReferralCode 填写推荐人账户ID时发放推荐奖励
+func (c *Client) OpenAccount(req service.OpenAccountRequest) (service.OpenAccountResult, error) {
+	var result service.OpenAccountResult
+	err := c.do(request{method: http.MethodPost, path: "/accounts/open", body: req}, &result)
+	return result, err
+}
+
+// 查询营销活动列表
+func (c *Client) Promos() ([]service.Campaign, error) {
+	var campaigns []service.Campaign
+	err := c.do(request{method: http.MethodGet, path: "/admin/promos"}, &campaigns)
+	return campaigns, err
+}
+
+// 创建营销活动
+func (c *Client) CreatePromo(campaign service.Campaign) (service.Campaign, error) {
+	var result service.Campaign
+	err := c.do(request{method: http.MethodPost, path: "/admin/promos", body: campaign}, &result)
+	return result, err
+}
+
+// 修改营销活动（暂停/恢复、调整预算与单户上限）
+func (c *Client) UpdatePromo(campaignID string, req service.CampaignUpdateRequest) (service.Campaign, error) {
+	var result service.Campaign
+	err := c.do(request{method: http.MethodPut, path: "/admin/promos/" + url.PathEscape(campaignID), body: req}, &result)
+	return result, err
+}
+
+// 查询账户获得的营销奖励，accountID 为空时查询默认账户
+func (c *Client) PromoRewards(accountID string) ([]service.PromoReward, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var rewards []service.PromoReward
+	err := c.do(request{method: http.MethodGet, path: "/promos/rewards", query: query}, &rewards)
+	return rewards, err
+}
+
+// 查询账户消费返现汇总（待入账、本月累计与入账记录），accountID 为空时查询默认账户
+func (c *Client) Cashback(accountID string) (service.CashbackSummary, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var summary service.CashbackSummary
+	err := c.do(request{method: http.MethodGet, path: "/cashback", query: query}, &summary)
+	return summary, err
+}
+
+// 查询消费返现配置
+func (c *Client) CashbackConfig() (service.CashbackConfig, error) {
+	var cfg service.CashbackConfig
+	err := c.do(request{method: http.MethodGet, path: "/admin/cashback/config"}, &cfg)
+	return cfg, err
+}
+
+// 调整消费返现配置
+func (c *Client) SetCashbackConfig(req service.CashbackConfigRequest) (service.CashbackConfig, error) {
+	var cfg service.CashbackConfig
+	err := c.do(request{method: http.MethodPost, path: "/admin/cashback/config", body: req}, &cfg)
+	return cfg, err
+}
+
+// 查询账户积分账本（积分余额与积分流水），accountID 为空时查询默认账户
+func (c *Client) Loyalty(accountID string) (service.LoyaltyAccount, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+
+	var result service.LoyaltyAccount
+	err := c.do(request{method: http.MethodGet, path: "/loyalty", query: query}, &result)
+	return result, err
+}
+
+// 积分兑换为存款
+func (c *Client) RedeemPoints(accountID string, points int64) (service.RedeemResult, error) {
+	var result service.RedeemResult
+	err := c.do(request{method: http.MethodPost, path: "/loyalty/redeem",
+		body: service.RedeemRequest{AccountID: accountID, Points: points}}, &result)
+	return result, err
+}
+
+// 查询积分配置
+func (c *Client) LoyaltyConfig() (service.LoyaltyConfig, error) {
+	var cfg service.LoyaltyConfig
+	err := c.do(request{method: http.MethodGet, path: "/admin/loyalty/config"}, &cfg)
+	return cfg, err
+}
+
+// 调整积分配置
+func (c *Client) SetLoyaltyConfig(req service.LoyaltyConfigRequest) (service.LoyaltyConfig, error) {
+	var cfg service.LoyaltyConfig
+	err := c.do(request{method: http.MethodPost, path: "/admin/loyalty/config", body: req}, &cfg)
+	return cfg, err
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户历史交易地点（含可信地点）
+func (c *Client) RiskLocations(accountID string) ([]service.KnownLocation, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var locations []service.KnownLocation
+	err := c.do(request{method: http.MethodGet, path: "/risk/locations", query: query}, &locations)
+	return locations, err
+}
+
+// 标记可信地点（按 IP 或 国家/地区/城市），之后来自该地点的转账不再要求验证
+func (c *Client) TrustLocation(req service.TrustLocationRequest) (service.KnownLocation, error) {
+	var location service.KnownLocation
+	err := c.do(request{method: http.MethodPost, path: "/risk/locations/trust", body: req}, &location)
+	return location, err
+}
+
+// 查询风控事件（管理员），accountID 为空时返回全部
+func (c *Client) RiskEvents(accountID string) ([]service.RiskEvent, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var events []service.RiskEvent
+	err := c.do(request{method: http.MethodGet, path: "/admin/risk/events", query: query}, &events)
+	return events, err
+}
+
+// 查询账户设备列表（信任、未识别与已移除设备）
+func (c *Client) Devices(accountID string) ([]service.Device, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var devices []service.Device
+	err := c.do(request{method: http.MethodGet, path: "/devices", query: query}, &devices)
+	return devices, err
+}
+
+// 移除设备：设备不再信任，其上的登录会话同时失效
+func (c *Client) RevokeDevice(accountID, deviceID string) (service.Device, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var result struct {
+		Device service.Device `json:"device"`
+	}
+	err := c.do(request{method: http.MethodDelete, path: "/devices/" + url.PathEscape(deviceID), query: query}, &result)
+	return result.Device, err
+}
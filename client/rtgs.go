@@ -0,0 +1,76 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 央行 RTGS 清算账户（管理员）
+func (c *Client) RTGSAccounts() ([]service.SettlementAccount, error) {
+	var accounts []service.SettlementAccount
+	err := c.do(request{method: http.MethodGet, path: "/admin/rtgs/accounts"}, &accounts)
+	return accounts, err
+}
+
+// 调整参与行日间透支限额（管理员）
+func (c *Client) SetRTGSLimit(bic string, limit float64) (service.SettlementAccount, error) {
+	var account service.SettlementAccount
+	err := c.do(request{method: http.MethodPost, path: "/admin/rtgs/accounts/" + url.PathEscape(bic),
+		body: service.RTGSLimitRequest{IntradayLimit: limit}}, &account)
+	return account, err
+}
+
+// RTGS 排队中的结算指令（管理员）
+func (c *Client) RTGSQueue() ([]service.RTGSEntry, error) {
+	var entries []service.RTGSEntry
+	err := c.do(request{method: http.MethodGet, path: "/admin/rtgs/queue"}, &entries)
+	return entries, err
+}
+
+// RTGS 已结算分录（管理员），date 为空表示全部
+func (c *Client) RTGSEntries(date string) ([]service.RTGSEntry, error) {
+	query := url.Values{}
+	if date != "" {
+		query.Set("date", date)
+	}
+	var entries []service.RTGSEntry
+	err := c.do(request{method: http.MethodGet, path: "/admin/rtgs/entries", query: query}, &entries)
+	return entries, err
+}
+
+// 模拟他行之间经 RTGS 的支付
+func (c *Client) RTGSTransfer(req service.RTGSTransferRequest) (service.RTGSEntry, error) {
+	var entry service.RTGSEntry
+	err := c.do(request{method: http.MethodPost, path: "/sim/rtgs/transfer", body: req}, &entry)
+	return entry, err
+}
+
+// 清算所轧差场次列表（管理员）
+func (c *Client) ClearingCycles() ([]service.ClearingCycle, error) {
+	var cycles []service.ClearingCycle
+	err := c.do(request{method: http.MethodGet, path: "/admin/netting/cycles"}, &cycles)
+	return cycles, err
+}
+
+// 轧差场次报告（管理员）
+func (c *Client) ClearingCycle(cycleID string) (service.ClearingCycle, error) {
+	var cycle service.ClearingCycle
+	err := c.do(request{method: http.MethodGet, path: "/admin/netting/cycles/" + url.PathEscape(cycleID)}, &cycle)
+	return cycle, err
+}
+
+// 立即截止清算所当前场次（管理员）
+func (c *Client) CloseClearingCycle() (service.ClearingCycle, error) {
+	var cycle service.ClearingCycle
+	err := c.do(request{method: http.MethodPost, path: "/admin/netting/close"}, &cycle)
+	return cycle, err
+}
+
+// 模拟他行之间经清算所的低额支付
+func (c *Client) ClearingHouseTransfer(req service.ClearingHouseTransferRequest) (service.ClearingHouseReceipt, error) {
+	var receipt service.ClearingHouseReceipt
+	err := c.do(request{method: http.MethodPost, path: "/sim/netting/transfer", body: req}, &receipt)
+	return receipt, err
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询事务编排记录（管理员），status 为空时返回全部
+func (c *Client) Sagas(status string) ([]service.Saga, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var sagas []service.Saga
+	err := c.do(request{method: http.MethodGet, path: "/admin/sagas", query: query}, &sagas)
+	return sagas, err
+}
+
+// 查询单条事务编排记录（管理员）
+func (c *Client) Saga(sagaID string) (service.Saga, error) {
+	var saga service.Saga
+	err := c.do(request{method: http.MethodGet, path: "/admin/sagas/" + url.PathEscape(sagaID)}, &saga)
+	return saga, err
+}
+
+// 重试补偿失败的事务编排（管理员）
+func (c *Client) RetrySaga(sagaID string) (service.Saga, error) {
+	var saga service.Saga
+	err := c.do(request{method: http.MethodPost, path: "/admin/sagas/" + url.PathEscape(sagaID) + "/retry"}, &saga)
+	return saga, err
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户预约的定时转账，accountID 为空时查询默认账户
+func (c *Client) ScheduledTransfers(accountID string) ([]service.ScheduledTransfer, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var transfers []service.ScheduledTransfer
+	err := c.do(request{method: http.MethodGet, path: "/transfer/scheduled", query: query}, &transfers)
+	return transfers, err
+}
+
+// 预约定时转账（req.Frequency 为空表示单次）
+func (c *Client) CreateScheduledTransfer(req service.ScheduledTransferRequest) (service.ScheduledTransfer, error) {
+	var transfer service.ScheduledTransfer
+	err := c.do(request{method: http.MethodPost, path: "/transfer/scheduled", body: req}, &transfer)
+	return transfer, err
+}
+
+// 修改定时转账的金额或下次约定日期（正在执行时返回错误）
+func (c *Client) AmendScheduledTransfer(transferID string, req service.ScheduledTransferAmendRequest) (service.ScheduledTransfer, error) {
+	var transfer service.ScheduledTransfer
+	err := c.do(request{method: http.MethodPut, path: "/transfer/scheduled/" + url.PathEscape(transferID), body: req}, &transfer)
+	return transfer, err
+}
+
+// 取消定时转账，accountID 为转出账户
+func (c *Client) CancelScheduledTransfer(transferID, accountID string) (service.ScheduledTransfer, error) {
+	return c.scheduledTransferAction(http.MethodDelete, transferID, "", accountID)
+}
+
+// 暂停定时转账
+func (c *Client) PauseScheduledTransfer(transferID, accountID string) (service.ScheduledTransfer, error) {
+	return c.scheduledTransferAction(http.MethodPost, transferID, "/pause", accountID)
+}
+
+// 恢复已暂停的定时转账
+func (c *Client) ResumeScheduledTransfer(transferID, accountID string) (service.ScheduledTransfer, error) {
+	return c.scheduledTransferAction(http.MethodPost, transferID, "/resume", accountID)
+}
+
+func (c *Client) scheduledTransferAction(method, transferID, action, accountID string) (service.ScheduledTransfer, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var transfer service.ScheduledTransfer
+	err := c.do(request{method: method, path: "/transfer/scheduled/" + url.PathEscape(transferID) + action, query: query}, &transfer)
+	return transfer, err
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 登录，成功后可通过 WithSession(session.Token) 取得携带登录令牌的客户端（失败次数过多时返回锁定错误）
+func (c *Client) Login(accountID, password string) (service.Session, error) {
+	var session service.Session
+	err := c.do(request{method: http.MethodPost, path: "/auth/login", body: service.LoginRequest{AccountID: accountID, Password: password}}, &session)
+	return session, err
+}
+
+// 忘记密码：经短信或邮件下发重置令牌（channel 为 sms/email，为空时优先短信）
+func (c *Client) ForgotPassword(accountID, channel string) (service.PasswordForgotResult, error) {
+	var result service.PasswordForgotResult
+	err := c.do(request{method: http.MethodPost, path: "/auth/password/forgot", body: service.PasswordForgotRequest{AccountID: accountID, Channel: channel}}, &result)
+	return result, err
+}
+
+// 使用重置令牌设置新密码，成功后账户的全部登录会话失效
+func (c *Client) ResetPassword(req service.PasswordResetRequest) error {
+	return c.do(request{method: http.MethodPost, path: "/auth/password/reset", body: req}, nil)
+}
+
+// 查询当前会话（须携带登录令牌）
+func (c *Client) Session() (service.Session, error) {
+	var session service.Session
+	err := c.do(request{method: http.MethodGet, path: "/auth/session"}, &session)
+	return session, err
+}
+
+// 退出登录（须携带登录令牌）
+func (c *Client) Logout() (service.Session, error) {
+	var session service.Session
+	err := c.do(request{method: http.MethodDelete, path: "/auth/session"}, &session)
+	return session, err
+}
+
+// 查询当前账户的在线会话及其 WebSocket 连接
+func (c *Client) SecuritySessions() (service.AccountSessions, error) {
+	var sessions service.AccountSessions
+	err := c.do(request{method: http.MethodGet, path: "/security/sessions"}, &sessions)
+	return sessions, err
+}
+
+// 远程下线当前账户的指定会话：令牌失效并断开其 WebSocket 连接
+func (c *Client) RevokeSession(sessionID string) (service.SessionRevokeResult, error) {
+	var result service.SessionRevokeResult
+	err := c.do(request{method: http.MethodDelete, path: "/security/sessions/" + url.PathEscape(sessionID)}, &result)
+	return result, err
+}
+
+// 查询登录会话（管理员），accountID 为空时返回全部
+func (c *Client) Sessions(accountID string) ([]service.Session, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var sessions []service.Session
+	err := c.do(request{method: http.MethodGet, path: "/admin/sessions", query: query}, &sessions)
+	return sessions, err
+}
+
+// 查询当前账户的登录记录（成功与失败），result 为 success/failure，为空时返回全部
+func (c *Client) LoginHistory(result string) ([]service.LoginAttempt, error) {
+	query := url.Values{}
+	if result != "" {
+		query.Set("result", result)
+	}
+	var attempts []service.LoginAttempt
+	err := c.do(request{method: http.MethodGet, path: "/security/logins", query: query}, &attempts)
+	return attempts, err
+}
+
+// 查询登录记录（管理员），条件为空表示不限
+func (c *Client) LoginAttempts(q service.LoginAttemptQuery) ([]service.LoginAttempt, error) {
+	query := url.Values{}
+	if q.AccountID != "" {
+		query.Set("accountId", q.AccountID)
+	}
+	if q.Result != "" {
+		query.Set("result", q.Result)
+	}
+	if q.IP != "" {
+		query.Set("ip", q.IP)
+	}
+	var attempts []service.LoginAttempt
+	err := c.do(request{method: http.MethodGet, path: "/admin/security/logins", query: query}, &attempts)
+	return attempts, err
+}
+
+// 会话控制（管理员）：强制下线、要求重新认证或冻结账户
+func (c *Client) SessionControl(req service.SessionControlRequest) (service.SessionControlResult, error) {
+	var result service.SessionControlResult
+	err := c.do(request{method: http.MethodPost, path: "/admin/sessions/control", body: req}, &result)
+	return result, err
+}
+
+// 查询登录失败计数与锁定（管理员），lockedOnly 为真时仅返回锁定中的记录
+func (c *Client) LoginLocks(lockedOnly bool) ([]service.LoginLock, error) {
+	query := url.Values{}
+	if lockedOnly {
+		query.Set("locked", "true")
+	}
+	var locks []service.LoginLock
+	err := c.do(request{method: http.MethodGet, path: "/admin/login/locks", query: query}, &locks)
+	return locks, err
+}
+
+// 解除登录锁定（管理员）
+func (c *Client) UnlockLogin(req service.LoginUnlockRequest) ([]service.LoginLock, error) {
+	var locks []service.LoginLock
+	err := c.do(request{method: http.MethodPost, path: "/admin/login/unlock", body: req}, &locks)
+	return locks, err
+}
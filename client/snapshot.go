@@ -0,0 +1,48 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询状态快照列表（管理员）
+func (c *Client) Snapshots() ([]service.SnapshotInfo, error) {
+	var list []service.SnapshotInfo
+	err := c.do(request{method: http.MethodGet, path: "/admin/snapshots"}, &list)
+	return list, err
+}
+
+// 创建状态快照（管理员），name 为空时按模拟时间命名，同名快照将被覆盖
+func (c *Client) CreateSnapshot(name string) (service.SnapshotInfo, error) {
+	var info service.SnapshotInfo
+	err := c.do(request{method: http.MethodPost, path: "/admin/snapshots",
+		body: service.SnapshotRequest{Name: name}}, &info)
+	return info, err
+}
+
+// 回滚到指定状态快照（管理员）
+func (c *Client) RestoreSnapshot(name string) (service.SnapshotInfo, error) {
+	var info service.SnapshotInfo
+	err := c.do(request{method: http.MethodPost, path: "/admin/snapshots/" + url.PathEscape(name) + "/restore"}, &info)
+	return info, err
+}
+
+// 下载状态快照文件（管理员）
+func (c *Client) DownloadSnapshot(name string) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodGet, path: "/admin/snapshots/" + url.PathEscape(name)})
+}
+
+// 导入状态快照文件并以 name 保存（管理员）
+func (c *Client) UploadSnapshot(name string, data []byte) (service.SnapshotInfo, error) {
+	var info service.SnapshotInfo
+	err := c.do(request{method: http.MethodPut, path: "/admin/snapshots/" + url.PathEscape(name),
+		raw: data, rawType: "application/json"}, &info)
+	return info, err
+}
+
+// 删除状态快照（管理员）
+func (c *Client) DeleteSnapshot(name string) error {
+	return c.do(request{method: http.MethodDelete, path: "/admin/snapshots/" + url.PathEscape(name)}, nil)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/handler"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 手动日终结果
+type EODResult struct {
+	Date string `json:"date"`
+}
+
+// 查询账户对账单列表
+func (c *Client) Statements(accountID string) ([]service.Statement, error) {
+	var statements []service.Statement
+	err := c.do(request{method: http.MethodGet, path: "/statements",
+		query: url.Values{"accountId": {accountID}}}, &statements)
+	return statements, err
+}
+
+// 查询账户的月度对账单存档
+func (c *Client) MonthlyStatements(accountID string) ([]service.Statement, error) {
+	var statements []service.Statement
+	err := c.do(request{method: http.MethodGet, path: "/statements",
+		query: url.Values{"accountId": {accountID}, "period": {"monthly"}}}, &statements)
+	return statements, err
+}
+
+// 查询月度对账单订阅
+func (c *Client) StatementSubscription(accountID string) (service.StatementSubscription, error) {
+	var sub service.StatementSubscription
+	err := c.do(request{method: http.MethodGet, path: "/statements/subscription",
+		query: url.Values{"accountId": {accountID}}}, &sub)
+	return sub, err
+}
+
+// 开通或关闭月度对账单（开通须已登记邮箱）
+func (c *Client) SetStatementSubscription(accountID string, enabled bool) (service.StatementSubscription, error) {
+	var sub service.StatementSubscription
+	err := c.do(request{method: http.MethodPut, path: "/statements/subscription",
+		body: service.StatementSubscriptionRequest{AccountID: accountID, Enabled: enabled}}, &sub)
+	return sub, err
+}
+
+// 导出日终对账单（camt.053 XML）
+func (c *Client) Camt053(accountID string, date time.Time) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodGet, path: "/statements/camt053",
+		query: url.Values{"accountId": {accountID}, "date": {formatDate(date)}}})
+}
+
+// 导出日期区间的 MT940 对账单
+func (c *Client) MT940(accountID string, from, to time.Time) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodGet, path: "/statements/mt940",
+		query: url.Values{"accountId": {accountID}, "from": {formatDate(from)}, "to": {formatDate(to)}}})
+}
+
+// 导出日期区间的 OFX 交易（供个人理财软件导入）
+func (c *Client) OFX(accountID string, from, to time.Time) ([]byte, error) {
+	return c.doRaw(request{method: http.MethodGet, path: "/statements/ofx",
+		query: url.Values{"accountId": {accountID}, "from": {formatDate(from)}, "to": {formatDate(to)}}})
+}
+
+// 手动触发日终（管理员），day 为零值时处理模拟时钟的前一日
+func (c *Client) RunEOD(day time.Time) (EODResult, error) {
+	var req handler.EODRequest
+	if !day.IsZero() {
+		req.Date = formatDate(day)
+	}
+
+	var result EODResult
+	err := c.do(request{method: http.MethodPost, path: "/admin/eod", body: req}, &result)
+	return result, err
+}
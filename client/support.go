@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询账户的在线客服会话及消息（客服回复随之视为已读）
+func (c *Client) SupportConversations(accountID string) ([]service.Conversation, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var conversations []service.Conversation
+	err := c.do(request{method: http.MethodGet, path: "/support/chat", query: query}, &conversations)
+	return conversations, err
+}
+
+// 向在线客服发送消息（没有进行中的会话时自动开启）
+func (c *Client) SendSupportMessage(accountID, text string) (service.ChatMessage, error) {
+	var message service.ChatMessage
+	err := c.do(request{method: http.MethodPost, path: "/support/chat",
+		body: service.SupportChatRequest{AccountID: accountID, Text: text}}, &message)
+	return message, err
+}
+
+// 查询客服会话队列（管理员），status 为空时返回全部
+func (c *Client) SupportQueue(status string) ([]service.Conversation, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var conversations []service.Conversation
+	err := c.do(request{method: http.MethodGet, path: "/admin/support/conversations", query: query}, &conversations)
+	return conversations, err
+}
+
+// 查询客服会话及消息（管理员，客户消息随之视为已读）
+func (c *Client) SupportConversation(conversationID string) (service.Conversation, error) {
+	var conversation service.Conversation
+	err := c.do(request{method: http.MethodGet, path: "/admin/support/conversations/" + url.PathEscape(conversationID)}, &conversation)
+	return conversation, err
+}
+
+// 回复客服会话（管理员）：回复推送给客户并存入通知中心
+func (c *Client) ReplySupport(conversationID string, req service.SupportReplyRequest) (service.ChatMessage, error) {
+	var message service.ChatMessage
+	err := c.do(request{method: http.MethodPost, path: "/admin/support/conversations/" + url.PathEscape(conversationID) + "/reply", body: req}, &message)
+	return message, err
+}
+
+// 结束客服会话（管理员）
+func (c *Client) CloseSupportConversation(conversationID, agent string) (service.Conversation, error) {
+	var conversation service.Conversation
+	err := c.do(request{method: http.MethodPost, path: "/admin/support/conversations/" + url.PathEscape(conversationID) + "/close",
+		body: service.SupportCloseRequest{Agent: agent}}, &conversation)
+	return conversation, err
+}
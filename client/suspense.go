@@ -0,0 +1,40 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 模拟他行汇入（收款账户无法入账时转入待处理挂账）
+func (c *Client) IncomingCredit(req service.IncomingCreditRequest) (service.IncomingCredit, error) {
+	var result service.IncomingCredit
+	err := c.do(request{method: http.MethodPost, path: "/sim/interbank/incoming", body: req}, &result)
+	return result, err
+}
+
+// 查询挂账工作队列（管理员），status 为空表示全部
+func (c *Client) SuspenseItems(status string) ([]service.SuspenseItem, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var items []service.SuspenseItem
+	err := c.do(request{method: http.MethodGet, path: "/admin/suspense", query: query}, &items)
+	return items, err
+}
+
+// 查明收款人后将挂账款项入账（管理员）
+func (c *Client) RepairSuspense(itemID string, req service.SuspenseRepairRequest) (service.SuspenseItem, error) {
+	var item service.SuspenseItem
+	err := c.do(request{method: http.MethodPost, path: "/admin/suspense/" + url.PathEscape(itemID) + "/repair", body: req}, &item)
+	return item, err
+}
+
+// 将挂账款项退回汇出行（管理员）
+func (c *Client) ReturnSuspense(itemID string, req service.SuspenseReturnRequest) (service.SuspenseItem, error) {
+	var item service.SuspenseItem
+	err := c.do(request{method: http.MethodPost, path: "/admin/suspense/" + url.PathEscape(itemID) + "/return", body: req}, &item)
+	return item, err
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 查询网点及柜员列表
+func (c *Client) Branches() ([]service.BranchDetail, error) {
+	var branches []service.BranchDetail
+	err := c.do(request{method: http.MethodGet, path: "/teller/branches"}, &branches)
+	return branches, err
+}
+
+// 柜员开箱（开始班次）
+func (c *Client) OpenDrawer(req service.DrawerOpenRequest) (service.CashDrawer, error) {
+	var drawer service.CashDrawer
+	err := c.do(request{method: http.MethodPost, path: "/teller/drawer/open", body: req}, &drawer)
+	return drawer, err
+}
+
+// 柜员封箱（结束班次并生成轧账报告）
+func (c *Client) CloseDrawer(req service.DrawerCloseRequest) (service.ShiftReport, error) {
+	var report service.ShiftReport
+	err := c.do(request{method: http.MethodPost, path: "/teller/drawer/close", body: req}, &report)
+	return report, err
+}
+
+// 柜员代客现金存款
+func (c *Client) TellerDeposit(req service.TellerCashRequest) (service.DrawerOperation, error) {
+	var operation service.DrawerOperation
+	err := c.do(request{method: http.MethodPost, path: "/teller/deposit", body: req}, &operation)
+	return operation, err
+}
+
+// 柜员代客现金取款
+func (c *Client) TellerWithdraw(req service.TellerCashRequest) (service.DrawerOperation, error) {
+	var operation service.DrawerOperation
+	err := c.do(request{method: http.MethodPost, path: "/teller/withdraw", body: req}, &operation)
+	return operation, err
+}
+
+// 查询柜员班次轧账报告（未封箱时返回实时试算）
+func (c *Client) ShiftReport(tellerID string) (service.ShiftReport, error) {
+	var report service.ShiftReport
+	err := c.do(request{method: http.MethodGet, path: "/teller/report",
+		query: url.Values{"tellerId": {tellerID}}}, &report)
+	return report, err
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 按模板转账的结果（附执行后的模板使用次数）
+type TemplateTransferResult struct {
+	TransferResult
+	Template service.TransferTemplate `json:"template"`
+}
+
+// 查询账户的转账模板（按使用次数倒序），accountID 为空时查询默认账户
+func (c *Client) TransferTemplates(accountID string) ([]service.TransferTemplate, error) {
+	query := url.Values{}
+	if accountID != "" {
+		query.Set("accountId", accountID)
+	}
+	var templates []service.TransferTemplate
+	err := c.do(request{method: http.MethodGet, path: "/transfer/templates", query: query}, &templates)
+	return templates, err
+}
+
+// 保存转账模板（金额为 0 表示执行时指定）
+func (c *Client) CreateTransferTemplate(req service.TransferTemplateRequest) (service.TransferTemplate, error) {
+	var template service.TransferTemplate
+	err := c.do(request{method: http.MethodPost, path: "/transfer/templates", body: req}, &template)
+	return template, err
+}
+
+// 修改转账模板名称、金额或附言
+func (c *Client) UpdateTransferTemplate(templateID string, req service.TransferTemplateUpdateRequest) (service.TransferTemplate, error) {
+	var template service.TransferTemplate
+	err := c.do(request{method: http.MethodPut, path: "/transfer/templates/" + url.PathEscape(templateID), body: req}, &template)
+	return template, err
+}
+
+// 删除转账模板
+func (c *Client) DeleteTransferTemplate(templateID string) (service.TransferTemplate, error) {
+	var template service.TransferTemplate
+	err := c.do(request{method: http.MethodDelete, path: "/transfer/templates/" + url.PathEscape(templateID)}, &template)
+	return template, err
+}
+
+// 按模板转账，req.Amount 为 0 时使用模板金额
+func (c *Client) ExecuteTransferTemplate(templateID string, req service.TemplateExecuteRequest) (TemplateTransferResult, error) {
+	var result TemplateTransferResult
+	err := c.do(request{method: http.MethodPost, path: "/transfer/templates/" + url.PathEscape(templateID) + "/execute", body: req}, &result)
+	return result, err
+}
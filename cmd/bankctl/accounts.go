@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// accounts list：按条件查询账户
+func runAccountsList(app *app, args []string) error {
+	fs := newFlagSet("accounts list", "")
+	var filter service.AccountFilter
+	fs.StringVar(&filter.Status, "status", "", "账户状态 normal/frozen/dormant")
+	fs.StringVar(&filter.NamePrefix, "name", "", "户名前缀")
+	minBalance := fs.String("min", "", "余额下限")
+	maxBalance := fs.String("max", "", "余额上限")
+	fs.StringVar(&filter.Sort, "sort", "", "排序字段 accountId/userName/balance/createAt")
+	fs.BoolVar(&filter.Desc, "desc", false, "倒序")
+	fs.IntVar(&filter.Page, "page", 1, "页码")
+	fs.IntVar(&filter.PageSize, "size", 20, "每页条数")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl accounts list [参数]"); err != nil {
+		return err
+	}
+	if filter.MinBalance, err = optionalAmount(*minBalance); err != nil {
+		return err
+	}
+	if filter.MaxBalance, err = optionalAmount(*maxBalance); err != nil {
+		return err
+	}
+
+	page, err := app.client.SearchAccounts(filter)
+	if err != nil {
+		return err
+	}
+	return app.print(page, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "账户ID\t户名\t余额\t可用\t币种\t产品\t状态\t开户日期")
+		for _, account := range page.Items {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%s\t%s\t%s\t%s\n", account.AccountID, account.UserName,
+				account.Balance, account.Available(), account.Currency, account.ProductCode, account.Status, account.CreateAt)
+		}
+		fmt.Fprintf(w, "第 %d 页，共 %d 个账户\n", page.Page, page.Total)
+	})
+}
+
+// accounts create：开户
+func runAccountsCreate(app *app, args []string) error {
+	fs := newFlagSet("accounts create", "")
+	var req service.OpenAccountRequest
+	fs.StringVar(&req.UserName, "name", "", "户名")
+	fs.StringVar(&req.CustomerID, "customer", "", "已有客户号（填写时沿用客户资料）")
+	fs.StringVar(&req.Currency, "currency", "", "币种，默认人民币")
+	fs.StringVar(&req.ProductCode, "product", "", "存款产品代码，默认活期储蓄")
+	fs.StringVar(&req.Email, "email", "", "通知邮箱")
+	fs.StringVar(&req.Phone, "phone", "", "通知手机号")
+	fs.StringVar(&req.Locale, "locale", "", "消息语言，如 en-US")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl accounts create -name <户名> [参数]"); err != nil {
+		return err
+	}
+
+	result, err := app.client.OpenAccount(req)
+	if err != nil {
+		return err
+	}
+	return app.print(result, func(w *tabwriter.Writer) {
+		printAccount(w, result.Account)
+		for _, reward := range result.Rewards {
+			fmt.Fprintf(w, "开户奖励:\t%.2f（%s）\n", reward.Amount, reward.Campaign)
+		}
+	})
+}
+
+// accounts freeze：冻结账户
+func runAccountsFreeze(app *app, args []string) error {
+	fs := newFlagSet("accounts freeze", "<账户ID>")
+	reason := fs.String("reason", "", "冻结原因（必填）")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 1, "bankctl accounts freeze <账户ID> -reason <原因>"); err != nil {
+		return err
+	}
+	return setStatus(app, positional[0], "frozen", *reason)
+}
+
+// accounts unfreeze：解冻账户
+func runAccountsUnfreeze(app *app, args []string) error {
+	fs := newFlagSet("accounts unfreeze", "<账户ID>")
+	reason := fs.String("reason", "", "解冻原因")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 1, "bankctl accounts unfreeze <账户ID>"); err != nil {
+		return err
+	}
+	return setStatus(app, positional[0], "normal", *reason)
+}
+
+func setStatus(app *app, accountID, status, reason string) error {
+	account, err := app.client.SetAccountStatus(accountID, status, reason)
+	if err != nil {
+		return err
+	}
+	return app.print(account, func(w *tabwriter.Writer) { printAccount(w, account) })
+}
+
+// accounts adjust：人工调账
+func runAccountsAdjust(app *app, args []string) error {
+	fs := newFlagSet("accounts adjust", "<账户ID> <金额>")
+	reason := fs.String("reason", "", "调账原因（必填）")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 2, "bankctl accounts adjust <账户ID> <金额> -reason <原因>"); err != nil {
+		return err
+	}
+	amount, err := strconv.ParseFloat(positional[1], 64)
+	if err != nil {
+		return fmt.Errorf("金额格式错误: %s", positional[1])
+	}
+
+	result, err := app.client.AdjustBalance(positional[0], amount, *reason)
+	if err != nil {
+		return err
+	}
+	return app.print(result, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "账户ID:\t%s\n调整金额:\t%+.2f\n余额:\t%.2f → %.2f\n", result.AccountID, result.Amount, result.OldBalance, result.NewBalance)
+	})
+}
+
+// accounts import：按 CSV 批量导入账户，输出逐行校验结果
+func runAccountsImport(app *app, args []string) error {
+	fs := newFlagSet("accounts import", "<CSV文件>")
+	dryRun := fs.Bool("dry-run", false, "只校验不写入")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 1, "bankctl accounts import <CSV文件> [-dry-run]"); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := app.client.ImportAccounts(data, *dryRun)
+	if err != nil {
+		return err
+	}
+	if err := app.print(report, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "行号\t账户ID\t结果")
+		for _, row := range report.Rows {
+			result := "通过"
+			if !row.OK {
+				result = strings.Join(row.Errors, "；")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", row.Row, row.AccountID, result)
+		}
+		if report.DryRun {
+			fmt.Fprintf(w, "试运行：共 %d 行，通过 %d 行，失败 %d 行，余额合计 %.2f\n", report.Total, report.Valid, report.Failed, report.TotalBalance)
+		} else {
+			fmt.Fprintf(w, "共 %d 行，导入 %d 个账户，失败 %d 行，余额合计 %.2f\n", report.Total, report.Imported, report.Failed, report.TotalBalance)
+		}
+	}); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d 行校验失败", report.Failed)
+	}
+	return nil
+}
+
+// 打印单个账户
+func printAccount(w *tabwriter.Writer, account model.Account) {
+	fmt.Fprintf(w, "账户ID:\t%s\n", account.AccountID)
+	fmt.Fprintf(w, "户名:\t%s（客户号 %s）\n", account.UserName, account.CustomerID)
+	fmt.Fprintf(w, "余额:\t%.2f %s（可用 %.2f）\n", account.Balance, account.Currency, account.Available())
+	fmt.Fprintf(w, "产品:\t%s\n", account.ProductCode)
+	fmt.Fprintf(w, "状态:\t%s\n", account.Status)
+}
+
+// 解析可选金额参数，为空返回 nil
+func optionalAmount(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("金额格式错误: %s", value)
+	}
+	return &amount, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/client"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// chaos show：查询故障注入配置与统计
+func runChaosShow(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl chaos show"); err != nil {
+		return err
+	}
+	status, err := app.client.Chaos()
+	if err != nil {
+		return err
+	}
+	return app.print(status, func(w *tabwriter.Writer) { printChaos(w, status) })
+}
+
+// chaos set：调整故障注入配置（只修改指定的参数）
+func runChaosSet(app *app, args []string) error {
+	fs := newFlagSet("chaos set", "")
+	enabled := fs.Bool("enabled", false, "开启或关闭故障注入（-enabled=false 关闭）")
+	latency := fs.String("latency", "", "全部接口的附加延迟（毫秒），如 100-500 或 200")
+	endpoints := fs.String("endpoint", "", "按接口路径前缀覆盖附加延迟，如 /api/transfer=1000-3000,/api/deposit=500；传 none 清空")
+	errorRate := fs.Float64("error-rate", 0, "接口返回服务繁忙的概率（0~1）")
+	writeFailureRate := fs.Float64("write-failure-rate", 0, "存储写入失败的概率（0~1）")
+	stormInterval := fs.Int("storm-interval", 0, "断线风暴间隔（秒），0 表示不定时触发")
+	stormRatio := fs.Float64("storm-ratio", 0, "每次断线风暴断开的连接比例（0~1）")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl chaos set [参数]"); err != nil {
+		return err
+	}
+
+	var req service.ChaosConfigRequest
+	var parseErr error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "enabled":
+			req.Enabled = enabled
+		case "latency":
+			var l service.ChaosLatencyRequest
+			if l, parseErr = parseLatency(*latency); parseErr == nil {
+				req.Latency = &l
+			}
+		case "endpoint":
+			req.Endpoints, parseErr = parseEndpoints(*endpoints)
+		case "error-rate":
+			req.ErrorRate = errorRate
+		case "write-failure-rate":
+			req.WriteFailureRate = writeFailureRate
+		case "storm-interval":
+			req.StormIntervalSec = stormInterval
+		case "storm-ratio":
+			req.StormRatio = stormRatio
+		}
+	})
+	if parseErr != nil {
+		return parseErr
+	}
+
+	status, err := app.client.SetChaos(req)
+	if err != nil {
+		return err
+	}
+	return app.print(status, func(w *tabwriter.Writer) { printChaos(w, status) })
+}
+
+// chaos storm：立即触发一次 WebSocket 断线风暴
+func runChaosStorm(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl chaos storm <断开比例 0~1>"); err != nil {
+		return err
+	}
+	ratio, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("断开比例格式错误: %s", args[0])
+	}
+	dropped, err := app.client.ChaosStorm(ratio)
+	if err != nil {
+		return err
+	}
+	return app.print(map[string]interface{}{"ratio": ratio, "disconnected": dropped}, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "已断开连接:\t%d 个\n", dropped)
+	})
+}
+
+// 打印故障注入配置与统计
+func printChaos(w *tabwriter.Writer, status client.ChaosStatus) {
+	state := "关闭"
+	if status.Enabled {
+		state = "开启"
+	}
+	fmt.Fprintf(w, "故障注入:\t%s\n", state)
+	fmt.Fprintf(w, "附加延迟:\t%d~%d 毫秒\n", status.Latency.MinMs, status.Latency.MaxMs)
+	paths := make([]string, 0, len(status.Endpoints))
+	for path := range status.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(w, "  %s:\t%d~%d 毫秒\n", path, status.Endpoints[path].MinMs, status.Endpoints[path].MaxMs)
+	}
+	fmt.Fprintf(w, "繁忙概率:\t%.2f\n", status.ErrorRate)
+	fmt.Fprintf(w, "写入失败概率:\t%.2f\n", status.WriteFailureRate)
+	fmt.Fprintf(w, "断线风暴:\t每 %d 秒断开 %.0f%% 连接\n", status.StormIntervalSec, status.StormRatio*100)
+	stats := status.Stats
+	fmt.Fprintf(w, "累计:\t延迟 %d 次（共 %d 毫秒），繁忙 %d 次，写入失败 %d 次，断线风暴 %d 次（断开 %d 个连接）\n",
+		stats.DelayedRequests, stats.TotalDelayMs, stats.BusyErrors, stats.WriteFailures, stats.Storms, stats.Disconnects)
+}
+
+// 解析延迟范围：min-max 或单个值（毫秒）
+func parseLatency(value string) (service.ChaosLatencyRequest, error) {
+	minText, maxText, found := strings.Cut(value, "-")
+	if !found {
+		maxText = minText
+	}
+	min, err1 := strconv.Atoi(strings.TrimSpace(minText))
+	max, err2 := strconv.Atoi(strings.TrimSpace(maxText))
+	if err1 != nil || err2 != nil {
+		return service.ChaosLatencyRequest{}, fmt.Errorf("延迟格式错误: %s（应为 min-max 毫秒）", value)
+	}
+	return service.ChaosLatencyRequest{MinMs: min, MaxMs: max}, nil
+}
+
+// 解析按接口覆盖的延迟：路径=延迟，逗号分隔；none 表示清空
+func parseEndpoints(value string) (map[string]service.ChaosLatencyRequest, error) {
+	endpoints := make(map[string]service.ChaosLatencyRequest)
+	if value == "none" {
+		return endpoints, nil
+	}
+	for _, item := range strings.Split(value, ",") {
+		path, latency, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			return nil, fmt.Errorf("接口延迟格式错误: %s（应为 路径=min-max）", item)
+		}
+		l, err := parseLatency(latency)
+		if err != nil {
+			return nil, err
+		}
+		endpoints[path] = l
+	}
+	return endpoints, nil
+}
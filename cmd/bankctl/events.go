@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/client"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 管理员事件流默认订阅的主题
+var defaultAdminTopics = []string{ws.TOPIC_ADMIN_TRANSACTIONS, ws.TOPIC_ADMIN_SUPPORT, "alerts"}
+
+// events tail：持续输出实时事件流，直至连接断开或 Ctrl+C
+func runEventsTail(app *app, args []string) error {
+	fs := newFlagSet("events tail", "")
+	accountID := fs.String("account", "", "以账户身份连接，只接收该账户的消息（默认以管理员身份连接）")
+	topics := fs.String("topics", "", "订阅的主题，逗号分隔（管理员默认 "+strings.Join(defaultAdminTopics, ",")+"）")
+	filterAccount := fs.String("filter-account", "", "管理员交易流水只显示该账户")
+	minAmount := fs.Float64("min", 0, "管理员交易流水只显示不低于该金额的交易")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl events tail [参数]"); err != nil {
+		return err
+	}
+
+	var subscribe []string
+	if *topics != "" {
+		for _, topic := range strings.Split(*topics, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				subscribe = append(subscribe, topic)
+			}
+		}
+	}
+
+	var stream *client.EventStream
+	if *accountID != "" {
+		stream, err = app.client.AccountEvents(*accountID, 0, subscribe...)
+	} else {
+		if subscribe == nil {
+			subscribe = defaultAdminTopics
+		}
+		stream, err = app.client.AdminEvents(app.adminKey)
+		if err == nil {
+			err = subscribeAll(stream, subscribe, ws.Filter{AccountID: *filterAccount, MinAmount: *minAmount})
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		stream.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "已连接，订阅主题: %s（Ctrl+C 退出）\n", strings.Join(subscribe, ","))
+	for msg := range stream.Events() {
+		printEvent(app, msg)
+	}
+	return stream.Err()
+}
+
+// 订阅主题，管理员交易流水主题附带过滤条件
+func subscribeAll(stream *client.EventStream, topics []string, filter ws.Filter) error {
+	for _, topic := range topics {
+		var err error
+		if topic == ws.TOPIC_ADMIN_TRANSACTIONS && filter != (ws.Filter{}) {
+			err = stream.SubscribeFiltered(topic, filter)
+		} else {
+			err = stream.Subscribe(topic)
+		}
+		if err != nil {
+			stream.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// 输出一条事件：-json 时每行一条 JSON，否则输出时间、类型、主题、账户与摘要
+func printEvent(app *app, msg ws.Message) {
+	if app.jsonOut {
+		data, _ := json.Marshal(msg)
+		fmt.Fprintln(app.out, string(data))
+		return
+	}
+
+	summary := msg.Message
+	if summary == "" && msg.Data != nil {
+		data, _ := json.Marshal(msg.Data)
+		summary = string(data)
+	}
+	if msg.Type == "balanceUpdate" {
+		summary = fmt.Sprintf("余额 %.2f", msg.NewBalance)
+	}
+	topic := msg.Topic
+	if topic == "" {
+		topic = "-"
+	}
+	account := msg.AccountID
+	if account == "" {
+		account = "-"
+	}
+	fmt.Fprintf(app.out, "%s  %-18s %-20s %-10s %s\n", time.Now().Format("15:04:05"), msg.Type, topic, account, summary)
+}
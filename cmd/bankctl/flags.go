@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// flags list：查询功能开关
+func runFlagsList(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl flags list"); err != nil {
+		return err
+	}
+	flags, err := app.client.FeatureFlags()
+	if err != nil {
+		return err
+	}
+	return app.print(flags, func(w *tabwriter.Writer) { printFeatureFlags(w, flags) })
+}
+
+// flags set：设置功能开关
+func runFlagsSet(app *app, args []string) error {
+	if err := expectArgs(args, 2, "bankctl flags set <开关> <true|false>"); err != nil {
+		return err
+	}
+	enabled, err := strconv.ParseBool(args[1])
+	if err != nil {
+		return fmt.Errorf("开关取值应为 true 或 false: %s", args[1])
+	}
+	flag, err := app.client.SetFeatureFlag(args[0], enabled)
+	if err != nil {
+		return err
+	}
+	return app.print(flag, func(w *tabwriter.Writer) { printFeatureFlags(w, []service.FeatureFlag{flag}) })
+}
+
+// flags reset：撤销运行时设置
+func runFlagsReset(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl flags reset <开关>"); err != nil {
+		return err
+	}
+	flag, err := app.client.ResetFeatureFlag(args[0])
+	if err != nil {
+		return err
+	}
+	return app.print(flag, func(w *tabwriter.Writer) { printFeatureFlags(w, []service.FeatureFlag{flag}) })
+}
+
+// flags reload：重新读取配置文件
+func runFlagsReload(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl flags reload"); err != nil {
+		return err
+	}
+	flags, err := app.client.ReloadFeatureFlags()
+	if err != nil {
+		return err
+	}
+	return app.print(flags, func(w *tabwriter.Writer) { printFeatureFlags(w, flags) })
+}
+
+// 打印功能开关
+func printFeatureFlags(w *tabwriter.Writer, flags []service.FeatureFlag) {
+	fmt.Fprintln(w, "开关\t状态\t默认\t来源\t说明")
+	for _, flag := range flags {
+		fmt.Fprintf(w, "%s\t%t\t%t\t%s\t%s\n", flag.Name, flag.Enabled, flag.Default, flag.Source, flag.Description)
+	}
+}
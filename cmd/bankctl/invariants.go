@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// invariants check：资金守恒检查
+func runInvariantsCheck(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl invariants check"); err != nil {
+		return err
+	}
+	report, err := app.client.CheckInvariants()
+	if err != nil {
+		return err
+	}
+	if err := app.print(report, func(w *tabwriter.Writer) { printInvariants(w, report) }); err != nil {
+		return err
+	}
+	if !report.Holds {
+		return fmt.Errorf("资金守恒检查未通过（%d 项）", len(report.Violations))
+	}
+	return nil
+}
+
+// 并发转账压测结果
+type stressResult struct {
+	Seed          int64                   `json:"seed"`
+	Accounts      []string                `json:"accounts"`
+	Transfers     int                     `json:"transfers"`
+	Succeeded     int                     `json:"succeeded"`
+	Rejected      int                     `json:"rejected"` // 余额不足、风控拦截等业务拒绝
+	Fees          float64                 `json:"fees"`     // 成功转账的手续费合计
+	OpeningTotal  float64                 `json:"openingTotal"`
+	ClosingTotal  float64                 `json:"closingTotal"`
+	ExpectedTotal float64                 `json:"expectedTotal"` // 期初合计 - 手续费
+	Violations    []string                `json:"violations"`
+	Invariants    service.InvariantReport `json:"invariants"`
+}
+
+// invariants stress：开立一组账户后随机并发互转，验证参与账户余额合计只因手续费减少、全行资金守恒。
+// 金额、收付款方与并发交错均随机生成，以 -seed 复现同一组转账
+func runInvariantsStress(app *app, args []string) error {
+	fs := newFlagSet("invariants stress", "")
+	accounts := fs.Int("accounts", 5, "参与互转的新开账户数")
+	deposit := fs.Float64("deposit", 1000, "每个账户的初始存款")
+	transfers := fs.Int("transfers", 200, "转账笔数")
+	workers := fs.Int("workers", 8, "并发数")
+	maxAmount := fs.Float64("max", 300, "单笔金额上限（超过余额的转账应被拒绝）")
+	seed := fs.Int64("seed", 0, "随机种子，0 表示取当前时间")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl invariants stress [参数]"); err != nil {
+		return err
+	}
+	if *accounts < 2 || *transfers < 1 || *workers < 1 || *maxAmount <= 0 {
+		return fmt.Errorf("参数错误：账户数至少 2 个，转账笔数、并发数与金额上限须大于 0")
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(*seed))
+
+	// 开户并存入初始资金（开户奖励等入账均计入期初合计）
+	prefix := fmt.Sprintf("压测%d-", *seed)
+	result := stressResult{Seed: *seed, Transfers: *transfers, Violations: []string{}}
+	for i := 0; i < *accounts; i++ {
+		opened, err := app.client.OpenAccount(service.OpenAccountRequest{UserName: fmt.Sprintf("%s%02d", prefix, i+1)})
+		if err != nil {
+			return err
+		}
+		result.Accounts = append(result.Accounts, opened.Account.AccountID)
+		if _, err := app.client.Deposit(service.DepositRequest{AccountID: opened.Account.AccountID, Amount: *deposit}, 0); err != nil {
+			return err
+		}
+	}
+	if result.OpeningTotal, err = stressTotal(app, prefix, *accounts); err != nil {
+		return err
+	}
+
+	// 预先生成全部转账，并发执行顺序由调度决定
+	jobs := make(chan service.TransferRequest, *transfers)
+	for i := 0; i < *transfers; i++ {
+		from := rng.Intn(*accounts)
+		to := (from + 1 + rng.Intn(*accounts-1)) % *accounts
+		jobs <- service.TransferRequest{
+			FromAccount: result.Accounts[from],
+			ToAccount:   result.Accounts[to],
+			Amount:      model.RoundAmount(0.01 + rng.Float64()*(*maxAmount)),
+		}
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var failure error
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range jobs {
+				transfer, err := app.client.Transfer(req)
+				mu.Lock()
+				switch {
+				case err == nil:
+					result.Succeeded++
+					result.Fees += transfer.Fee
+				case errors.As(err, new(*model.BizError)):
+					result.Rejected++
+				case failure == nil:
+					failure = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if failure != nil {
+		return failure
+	}
+
+	if result.ClosingTotal, err = stressTotal(app, prefix, *accounts); err != nil {
+		return err
+	}
+	result.Fees = model.RoundAmount(result.Fees)
+	result.ExpectedTotal = model.RoundAmount(result.OpeningTotal - result.Fees)
+	if diff := model.RoundAmount(result.ClosingTotal - result.ExpectedTotal); diff != 0 {
+		result.Violations = append(result.Violations,
+			fmt.Sprintf("参与账户余额合计 %.2f，应为 %.2f（期初 %.2f - 手续费 %.2f），差额 %.2f",
+				result.ClosingTotal, result.ExpectedTotal, result.OpeningTotal, result.Fees, diff))
+	}
+	if result.Invariants, err = app.client.CheckInvariants(); err != nil {
+		return err
+	}
+	result.Violations = append(result.Violations, result.Invariants.Violations...)
+
+	if err := app.print(result, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "随机种子:\t%d\n", result.Seed)
+		fmt.Fprintf(w, "转账:\t%d 笔（成功 %d，拒绝 %d），手续费 %.2f\n", result.Transfers, result.Succeeded, result.Rejected, result.Fees)
+		fmt.Fprintf(w, "参与账户合计:\t期初 %.2f，期末 %.2f，应为 %.2f\n", result.OpeningTotal, result.ClosingTotal, result.ExpectedTotal)
+		if result.ClosingTotal != result.ExpectedTotal {
+			fmt.Fprintln(w, "✘ 参与账户余额合计与预期不符")
+		}
+		printInvariants(w, result.Invariants)
+	}); err != nil {
+		return err
+	}
+	if len(result.Violations) > 0 {
+		return fmt.Errorf("压测发现资金不一致（随机种子 %d）", result.Seed)
+	}
+	return nil
+}
+
+// 按户名前缀汇总压测账户余额
+func stressTotal(app *app, prefix string, count int) (float64, error) {
+	page, err := app.client.SearchAccounts(service.AccountFilter{NamePrefix: prefix, Page: 1, PageSize: count})
+	if err != nil {
+		return 0, err
+	}
+	if len(page.Items) != count {
+		return 0, fmt.Errorf("压测账户数 %d 与预期 %d 不符", len(page.Items), count)
+	}
+	var total float64
+	for _, account := range page.Items {
+		total += account.Balance
+	}
+	return model.RoundAmount(total), nil
+}
+
+// 打印资金守恒检查结果
+func printInvariants(w *tabwriter.Writer, report service.InvariantReport) {
+	fmt.Fprintln(w, "币种\t客户余额\t内部科目\t行内合计\t净流入\t差额")
+	for _, c := range report.Currencies {
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n", c.Currency, c.CustomerBalances, c.InternalBalances, c.Total, c.NetInflow, c.Drift)
+	}
+	if report.Holds {
+		fmt.Fprintf(w, "✔ 资金守恒（%d 个账户，检查时间 %s）\n", report.Accounts, report.CheckedAt)
+		return
+	}
+	for _, v := range report.Violations {
+		fmt.Fprintf(w, "✘ %s\n", v)
+	}
+}
@@ -0,0 +1,281 @@
+// bankctl 是面向运维人员的命令行工具，通过管理接口完成日常操作，无需手写 curl 请求。
+//
+// 用法：
+//
+//	bankctl [全局参数] <命令> [子命令] [参数]
+//
+// 全局参数：
+//
+//	-server     服务地址，默认取环境变量 BANKCTL_SERVER，未设置时为 http://localhost:8080
+//	-admin-key  管理员密钥（订阅管理员事件流使用），默认取环境变量 BANKCTL_ADMIN_KEY，未设置时为 admin
+//	-json       以 JSON 格式输出结果
+//
+// 命令：
+//
+//	accounts list      按条件查询账户
+//	accounts create    开户
+//	accounts freeze    冻结账户
+//	accounts unfreeze  解冻账户
+//	accounts adjust    人工调账（正数调增、负数调减）
+//	accounts import    按 CSV 批量导入账户
+//	garnish list       查询司法冻结
+//	garnish place      设置司法冻结（指定金额或全部余额）
+//	garnish release    部分或全部解除司法冻结
+//	eod run            手动触发日终
+//	clock show         查询模拟时钟
+//	clock advance      快进模拟时钟
+//	seed               批量生成模拟账户
+//	chaos show         查询故障注入配置与统计
+//	chaos set          调整故障注入配置
+//	chaos storm        立即触发 WebSocket 断线风暴
+//	maintenance status 查询维护状态
+//	maintenance list   查询维护窗口
+//	maintenance start  立即开启或预约维护窗口
+//	maintenance end    结束维护或取消预约
+//	flags list         查询功能开关
+//	flags set          设置功能开关
+//	flags reset        撤销功能开关的运行时设置
+//	flags reload       重新读取功能开关配置文件
+//	sagas list         查询事务编排记录
+//	sagas show         查询单条事务编排记录
+//	sagas retry        重试补偿失败的事务编排
+//	scenario run       按场景文件依次执行操作（结束后检查资金守恒）
+//	invariants check   资金守恒检查
+//	invariants stress  随机并发转账压测并检查资金守恒
+//	snapshot list      查询状态快照列表
+//	snapshot create    创建状态快照
+//	snapshot restore   回滚到状态快照
+//	snapshot download  下载快照文件
+//	snapshot upload    导入快照文件
+//	snapshot delete    删除状态快照
+//	events tail        持续输出实时事件流
+//
+// 执行 bankctl <命令> -h 查看各命令的参数。
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/client"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 命令（含子命令时 run 为空）
+type command struct {
+	name  string
+	usage string // 位置参数说明
+	short string
+	run   func(app *app, args []string) error
+	subs  []*command
+}
+
+// 命令执行环境
+type app struct {
+	client   *client.Client
+	adminKey string
+	jsonOut  bool
+	out      io.Writer
+}
+
+// 命令树
+var commands = []*command{
+	{name: "accounts", short: "账户管理", subs: []*command{
+		{name: "list", short: "按条件查询账户", run: runAccountsList},
+		{name: "create", short: "开户", run: runAccountsCreate},
+		{name: "freeze", usage: "<账户ID>", short: "冻结账户", run: runAccountsFreeze},
+		{name: "unfreeze", usage: "<账户ID>", short: "解冻账户", run: runAccountsUnfreeze},
+		{name: "adjust", usage: "<账户ID> <金额>", short: "人工调账（正数调增、负数调减）", run: runAccountsAdjust},
+		{name: "import", usage: "<CSV文件>", short: "按 CSV 批量导入账户", run: runAccountsImport},
+	}},
+	{name: "garnish", short: "司法冻结", subs: []*command{
+		{name: "list", short: "查询司法冻结", run: runGarnishList},
+		{name: "place", usage: "<账户ID>", short: "设置司法冻结（指定金额或全部余额）", run: runGarnishPlace},
+		{name: "release", usage: "<冻结编号>", short: "部分或全部解除司法冻结", run: runGarnishRelease},
+	}},
+	{name: "eod", short: "日终", subs: []*command{
+		{name: "run", short: "手动触发日终", run: runEOD},
+	}},
+	{name: "clock", short: "模拟时钟", subs: []*command{
+		{name: "show", short: "查询模拟时钟", run: runClockShow},
+		{name: "advance", usage: "<小时数>", short: "快进模拟时钟", run: runClockAdvance},
+	}},
+	{name: "seed", short: "批量生成模拟账户", run: runSeed},
+	{name: "chaos", short: "故障注入", subs: []*command{
+		{name: "show", short: "查询故障注入配置与统计", run: runChaosShow},
+		{name: "set", short: "调整故障注入配置", run: runChaosSet},
+		{name: "storm", usage: "<断开比例>", short: "立即触发 WebSocket 断线风暴", run: runChaosStorm},
+	}},
+	{name: "maintenance", short: "维护模式", subs: []*command{
+		{name: "status", short: "查询维护状态", run: runMaintenanceStatus},
+		{name: "list", short: "查询维护窗口", run: runMaintenanceList},
+		{name: "start", short: "立即开启或预约维护窗口", run: runMaintenanceStart},
+		{name: "end", usage: "<窗口编号>", short: "结束维护或取消预约", run: runMaintenanceEnd},
+	}},
+	{name: "flags", short: "功能开关", subs: []*command{
+		{name: "list", short: "查询功能开关", run: runFlagsList},
+		{name: "set", usage: "<开关> <true|false>", short: "设置功能开关", run: runFlagsSet},
+		{name: "reset", usage: "<开关>", short: "撤销功能开关的运行时设置", run: runFlagsReset},
+		{name: "reload", short: "重新读取功能开关配置文件", run: runFlagsReload},
+	}},
+	{name: "sagas", short: "事务编排", subs: []*command{
+		{name: "list", short: "查询事务编排记录", run: runSagasList},
+		{name: "show", usage: "<编排编号>", short: "查询单条事务编排记录", run: runSagasShow},
+		{name: "retry", usage: "<编排编号>", short: "重试补偿失败的事务编排", run: runSagasRetry},
+	}},
+	{name: "scenario", short: "场景", subs: []*command{
+		{name: "run", usage: "<场景文件|->", short: "按场景文件依次执行操作", run: runScenario},
+	}},
+	{name: "invariants", short: "资金守恒", subs: []*command{
+		{name: "check", short: "资金守恒检查", run: runInvariantsCheck},
+		{name: "stress", short: "随机并发转账压测并检查资金守恒", run: runInvariantsStress},
+	}},
+	{name: "snapshot", short: "状态快照", subs: []*command{
+		{name: "list", short: "查询快照列表", run: runSnapshotList},
+		{name: "create", usage: "[名称]", short: "创建快照", run: runSnapshotCreate},
+		{name: "restore", usage: "<名称>", short: "回滚到快照", run: runSnapshotRestore},
+		{name: "download", usage: "<名称>", short: "下载快照文件", run: runSnapshotDownload},
+		{name: "upload", usage: "<名称> <文件>", short: "导入快照文件", run: runSnapshotUpload},
+		{name: "delete", usage: "<名称>", short: "删除快照", run: runSnapshotDelete},
+	}},
+	{name: "events", short: "事件流", subs: []*command{
+		{name: "tail", short: "持续输出实时事件流（Ctrl+C 退出）", run: runEventsTail},
+	}},
+}
+
+func main() {
+	global := flag.NewFlagSet("bankctl", flag.ContinueOnError)
+	server := global.String("server", envOr("BANKCTL_SERVER", "http://localhost:8080"), "服务地址")
+	adminKey := global.String("admin-key", envOr("BANKCTL_ADMIN_KEY", "admin"), "管理员密钥")
+	jsonOut := global.Bool("json", false, "以 JSON 格式输出结果")
+	global.Usage = func() { printUsage(global.Output(), "bankctl", commands) }
+	if err := global.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+
+	cmd, path, args := resolve(global.Args())
+	if cmd == nil || cmd.run == nil {
+		subs := commands
+		if cmd != nil {
+			subs = cmd.subs
+		}
+		printUsage(os.Stderr, "bankctl"+path, subs)
+		os.Exit(2)
+	}
+
+	app := &app{client: client.New(*server, ""), adminKey: *adminKey, jsonOut: *jsonOut, out: os.Stdout}
+	if err := cmd.run(app, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "错误:", describeError(err))
+		os.Exit(1)
+	}
+}
+
+// 按参数逐级查找命令，返回命令、命令路径与剩余参数
+func resolve(args []string) (*command, string, []string) {
+	var found *command
+	path := ""
+	subs := commands
+	for len(args) > 0 {
+		var next *command
+		for _, cmd := range subs {
+			if cmd.name == args[0] {
+				next = cmd
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		found, path, subs, args = next, path+" "+next.name, next.subs, args[1:]
+		if found.run != nil {
+			break
+		}
+	}
+	return found, path, args
+}
+
+// 打印命令列表
+func printUsage(w io.Writer, prefix string, subs []*command) {
+	fmt.Fprintf(w, "用法: %s <命令> [参数]\n\n命令:\n", prefix)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range subs {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", cmd.name, cmd.usage, cmd.short)
+	}
+	tw.Flush()
+}
+
+// 解析命令参数，允许参数与位置参数交替出现，返回位置参数（负数视为位置参数，如调减金额 -100）
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if _, err := strconv.ParseFloat(args[0], 64); err == nil {
+			positional, args = append(positional, args[0]), args[1:]
+			continue
+		}
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional, args = append(positional, fs.Arg(0)), fs.Args()[1:]
+	}
+	return positional, nil
+}
+
+// 创建命令参数集，usage 为位置参数说明
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: bankctl %s [参数] %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// 校验位置参数个数
+func expectArgs(args []string, n int, usage string) error {
+	if len(args) != n {
+		return fmt.Errorf("参数错误，用法: %s", usage)
+	}
+	return nil
+}
+
+// 按输出格式打印结果：-json 时输出 JSON，否则调用 table 打印表格
+func (a *app) print(v interface{}, table func(w *tabwriter.Writer)) error {
+	if a.jsonOut {
+		encoder := json.NewEncoder(a.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	tw := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// 错误描述（业务错误附带业务码）
+func describeError(err error) string {
+	var bizErr *model.BizError
+	if errors.As(err, &bizErr) {
+		return fmt.Sprintf("[%d] %s", bizErr.Code, bizErr.Message)
+	}
+	return err.Error()
+}
+
+// 读取环境变量，未设置时使用默认值
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
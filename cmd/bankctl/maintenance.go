@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// maintenance status：查询维护状态
+func runMaintenanceStatus(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl maintenance status"); err != nil {
+		return err
+	}
+	status, err := app.client.MaintenanceStatus()
+	if err != nil {
+		return err
+	}
+	return app.print(status, func(w *tabwriter.Writer) {
+		if status.Current != nil {
+			fmt.Fprintf(w, "维护中:\t%s（%s ~ %s）\n", status.Current.WindowID, status.Current.StartAt, endText(status.Current.EndAt))
+		} else {
+			fmt.Fprintln(w, "维护中:\t否")
+		}
+		for _, window := range status.Upcoming {
+			fmt.Fprintf(w, "待开始:\t%s（%s ~ %s）\n", window.WindowID, window.StartAt, endText(window.EndAt))
+		}
+	})
+}
+
+// maintenance list：查询维护窗口
+func runMaintenanceList(app *app, args []string) error {
+	fs := newFlagSet("maintenance list", "")
+	status := fs.String("status", "", "按状态过滤 scheduled/active/completed/cancelled")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl maintenance list [-status 状态]"); err != nil {
+		return err
+	}
+	windows, err := app.client.MaintenanceWindows(*status)
+	if err != nil {
+		return err
+	}
+	return app.print(windows, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "窗口编号\t状态\t开始时间\t结束时间\t说明")
+		for _, window := range windows {
+			end := endText(window.EndAt)
+			if window.EndedAt != "" {
+				end = window.EndedAt
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", window.WindowID, window.Status, window.StartAt, end, window.Message)
+		}
+	})
+}
+
+// maintenance start：立即开启或预约维护窗口
+func runMaintenanceStart(app *app, args []string) error {
+	fs := newFlagSet("maintenance start", "")
+	var req service.MaintenanceRequest
+	fs.StringVar(&req.Message, "message", "", "维护说明")
+	fs.StringVar(&req.StartAt, "start", "", "开始时间（模拟时间 \"2006-01-02 15:04:05\"），默认立即开始")
+	fs.StringVar(&req.EndAt, "end", "", "结束时间，默认直至 maintenance end")
+	notice := fs.Int("notice", service.MAINTENANCE_DEFAULT_NOTICE, "提前通知时长（分钟）")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl maintenance start [参数]"); err != nil {
+		return err
+	}
+	req.NoticeMinutes = notice
+
+	window, err := app.client.ScheduleMaintenance(req)
+	if err != nil {
+		return err
+	}
+	return app.print(window, func(w *tabwriter.Writer) { printMaintenanceWindow(w, window) })
+}
+
+// maintenance end：结束维护或取消预约
+func runMaintenanceEnd(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl maintenance end <窗口编号>"); err != nil {
+		return err
+	}
+	window, err := app.client.EndMaintenance(args[0])
+	if err != nil {
+		return err
+	}
+	return app.print(window, func(w *tabwriter.Writer) { printMaintenanceWindow(w, window) })
+}
+
+// 打印维护窗口
+func printMaintenanceWindow(w *tabwriter.Writer, window service.MaintenanceWindow) {
+	fmt.Fprintf(w, "窗口编号:\t%s\n", window.WindowID)
+	fmt.Fprintf(w, "状态:\t%s\n", window.Status)
+	fmt.Fprintf(w, "维护时间:\t%s ~ %s\n", window.StartAt, endText(window.EndAt))
+	if window.EndedAt != "" {
+		fmt.Fprintf(w, "实际结束:\t%s\n", window.EndedAt)
+	}
+	fmt.Fprintf(w, "说明:\t%s\n", window.Message)
+}
+
+// 结束时间的展示文字
+func endText(endAt string) string {
+	if endAt == "" {
+		return "另行通知"
+	}
+	return endAt
+}
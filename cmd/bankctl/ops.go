@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// eod run：手动触发日终
+func runEOD(app *app, args []string) error {
+	fs := newFlagSet("eod run", "")
+	date := fs.String("date", "", "营业日期 YYYY-MM-DD，默认模拟时钟的前一日")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl eod run [-date YYYY-MM-DD]"); err != nil {
+		return err
+	}
+	var day time.Time
+	if *date != "" {
+		if day, err = time.ParseInLocation("2006-01-02", *date, time.Local); err != nil {
+			return fmt.Errorf("日期格式错误: %s", *date)
+		}
+	}
+
+	result, err := app.client.RunEOD(day)
+	if err != nil {
+		return err
+	}
+	return app.print(result, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "日终已执行:\t%s\n", result.Date)
+	})
+}
+
+// clock show：查询模拟时钟
+func runClockShow(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl clock show"); err != nil {
+		return err
+	}
+	clock, err := app.client.SimClock()
+	if err != nil {
+		return err
+	}
+	return app.print(clock, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "模拟时间:\t%s\n真实时间:\t%s\n倍速:\t%g\n", clock.SimTime, clock.RealTime, clock.Speed)
+	})
+}
+
+// clock advance：快进模拟时钟
+func runClockAdvance(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl clock advance <小时数>"); err != nil {
+		return err
+	}
+	hours, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return fmt.Errorf("小时数格式错误: %s", args[0])
+	}
+	clock, err := app.client.AdvanceSimClock(hours)
+	if err != nil {
+		return err
+	}
+	return app.print(clock, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "模拟时间:\t%s\n", clock.SimTime)
+	})
+}
+
+// seed：批量生成模拟账户
+func runSeed(app *app, args []string) error {
+	fs := newFlagSet("seed", "")
+	var req service.SeedRequest
+	fs.IntVar(&req.Count, "count", 10, "账户数")
+	fs.StringVar(&req.Currency, "currency", "", "账户币种，默认人民币")
+	fs.StringVar(&req.Distribution, "distribution", "", "余额分布 lognormal/uniform")
+	fs.Float64Var(&req.Median, "median", 0, "对数正态分布的中位数")
+	fs.Float64Var(&req.Sigma, "sigma", 0, "对数正态分布的离散度")
+	fs.Float64Var(&req.Min, "min", 0, "均匀分布下限")
+	fs.Float64Var(&req.Max, "max", 0, "余额上限")
+	fs.IntVar(&req.Transactions, "transactions", 0, "每个账户的历史交易笔数")
+	fs.IntVar(&req.HistoryDays, "days", 0, "历史交易分布的天数")
+	fs.Int64Var(&req.RandSeed, "rand-seed", 0, "随机种子")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl seed [参数]"); err != nil {
+		return err
+	}
+
+	result, err := app.client.Seed(req)
+	if err != nil {
+		return err
+	}
+	return app.print(result, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "账户ID\t户名\t余额\t币种\t产品\t流水笔数")
+		for _, account := range result.Accounts {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%s\t%d\n", account.AccountID, account.UserName,
+				account.Balance, account.Currency, account.ProductCode, account.Transactions)
+		}
+		fmt.Fprintf(w, "共 %d 个账户，余额合计 %.2f，历史流水 %d 笔，随机种子 %d\n",
+			result.Count, result.TotalBalance, result.Transactions, result.RandSeed)
+	})
+}
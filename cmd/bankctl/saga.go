@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// sagas list：查询事务编排记录
+func runSagasList(app *app, args []string) error {
+	fs := newFlagSet("sagas list", "")
+	status := fs.String("status", "", "按状态过滤 completed/compensated/stuck")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 0, "bankctl sagas list [-status 状态]"); err != nil {
+		return err
+	}
+	sagas, err := app.client.Sagas(*status)
+	if err != nil {
+		return err
+	}
+	return app.print(sagas, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "编排编号\t类型\t业务标识\t状态\t开始时间\t失败原因")
+		for _, saga := range sagas {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", saga.SagaID, saga.Type, saga.Reference, saga.Status, saga.StartedAt, saga.Error)
+		}
+	})
+}
+
+// sagas show：查询单条事务编排记录
+func runSagasShow(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl sagas show <编排编号>"); err != nil {
+		return err
+	}
+	saga, err := app.client.Saga(args[0])
+	if err != nil {
+		return err
+	}
+	return app.print(saga, func(w *tabwriter.Writer) { printSaga(w, saga) })
+}
+
+// sagas retry：重试补偿失败的事务编排
+func runSagasRetry(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl sagas retry <编排编号>"); err != nil {
+		return err
+	}
+	saga, err := app.client.RetrySaga(args[0])
+	if err != nil {
+		return err
+	}
+	return app.print(saga, func(w *tabwriter.Writer) { printSaga(w, saga) })
+}
+
+// 打印事务编排记录
+func printSaga(w *tabwriter.Writer, saga service.Saga) {
+	fmt.Fprintf(w, "编排编号:\t%s\n", saga.SagaID)
+	fmt.Fprintf(w, "类型:\t%s\n", saga.Type)
+	fmt.Fprintf(w, "业务标识:\t%s\n", saga.Reference)
+	fmt.Fprintf(w, "状态:\t%s\n", saga.Status)
+	if saga.Error != "" {
+		fmt.Fprintf(w, "失败原因:\t%s\n", saga.Error)
+	}
+	for _, step := range saga.Steps {
+		line := step.Status
+		if step.Error != "" {
+			line += "（" + step.Error + "）"
+		}
+		fmt.Fprintf(w, "步骤 %s:\t%s\n", step.Name, line)
+	}
+}
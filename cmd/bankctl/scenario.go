@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 场景文件：按顺序执行的操作步骤，例如
+//
+//	{
+//	  "name": "月末批量转账",
+//	  "steps": [
+//	    {"action": "open", "as": "alice", "userName": "王五"},
+//	    {"action": "deposit", "accountId": "@alice", "amount": 1000},
+//	    {"action": "transfer", "from": "@alice", "to": "8001234567", "amount": 300},
+//	    {"action": "transfer", "from": "@alice", "to": "8001234567", "amount": 5000, "expectError": true},
+//	    {"action": "advance", "hours": 24}
+//	  ]
+//	}
+type scenario struct {
+	Name  string         `json:"name"`
+	Steps []scenarioStep `json:"steps"`
+}
+
+// 场景步骤（按 action 使用对应字段）
+type scenarioStep struct {
+	Action string `json:"action"` // open/deposit/transfer/adjust/freeze/unfreeze/advance/eod/seed/sleep
+
+	As           string  `json:"as"`           // open：为新账户命名，后续步骤以 @名称 引用账户ID
+	AccountID    string  `json:"accountId"`    // deposit/adjust/freeze/unfreeze
+	From         string  `json:"from"`         // transfer
+	To           string  `json:"to"`           // transfer
+	Amount       float64 `json:"amount"`       // deposit/transfer/adjust
+	Reason       string  `json:"reason"`       // adjust/freeze/unfreeze
+	UserName     string  `json:"userName"`     // open
+	Currency     string  `json:"currency"`     // open/seed
+	ProductCode  string  `json:"productCode"`  // open
+	Hours        float64 `json:"hours"`        // advance
+	Date         string  `json:"date"`         // eod：营业日期，为空处理模拟时钟的前一日
+	Count        int     `json:"count"`        // seed：账户数
+	Transactions int     `json:"transactions"` // seed：每个账户的历史交易笔数
+	Duration     string  `json:"duration"`     // sleep：等待时长（如 2s），用于等待跨行清算等异步处理
+
+	ExpectError bool `json:"expectError"` // 预期失败（如余额不足），失败视为通过
+}
+
+// 步骤执行结果
+type stepResult struct {
+	Step   int    `json:"step"`
+	Action string `json:"action"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// scenario run：按场景文件依次执行操作，遇到不符合预期的步骤时停止
+func runScenario(app *app, args []string) error {
+	fs := newFlagSet("scenario run", "<场景文件|->")
+	keepGoing := fs.Bool("continue", false, "步骤不符合预期时继续执行后续步骤")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 1, "bankctl scenario run <场景文件|->"); err != nil {
+		return err
+	}
+	sc, err := loadScenario(positional[0])
+	if err != nil {
+		return err
+	}
+
+	runner := &scenarioRunner{app: app, names: map[string]string{}}
+	results := make([]stepResult, 0, len(sc.Steps))
+	failed := 0
+	for i, step := range sc.Steps {
+		detail, err := runner.run(step)
+		result := stepResult{Step: i + 1, Action: step.Action, Passed: (err == nil) != step.ExpectError, Detail: detail}
+		if err != nil {
+			result.Detail = describeError(err)
+		}
+		results = append(results, result)
+		if !app.jsonOut {
+			mark := "✔"
+			if !result.Passed {
+				mark = "✘"
+			}
+			fmt.Fprintf(app.out, "%s [%d/%d] %-9s %s\n", mark, result.Step, len(sc.Steps), result.Action, result.Detail)
+		}
+		if !result.Passed {
+			failed++
+			if !*keepGoing {
+				break
+			}
+		}
+	}
+
+	// 场景结束后检查资金守恒（无论步骤是否符合预期）
+	invariants, err := app.client.CheckInvariants()
+	if err != nil {
+		return err
+	}
+
+	summary := map[string]interface{}{"name": sc.Name, "steps": results, "failed": failed, "invariants": invariants}
+	if err := app.print(summary, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "场景 %s：执行 %d/%d 步，不符合预期 %d 步\n", sc.Name, len(results), len(sc.Steps), failed)
+		printInvariants(w, invariants)
+	}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("场景执行失败（%d 步不符合预期）", failed)
+	}
+	if !invariants.Holds {
+		return fmt.Errorf("场景执行后资金守恒检查未通过（%d 项）", len(invariants.Violations))
+	}
+	return nil
+}
+
+// 读取场景文件，"-" 表示从标准输入读取
+func loadScenario(path string) (scenario, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return scenario{}, err
+	}
+
+	var sc scenario
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return scenario{}, fmt.Errorf("场景文件格式错误: %v", err)
+	}
+	if len(sc.Steps) == 0 {
+		return scenario{}, fmt.Errorf("场景文件没有步骤")
+	}
+	if sc.Name == "" {
+		sc.Name = path
+	}
+	return sc, nil
+}
+
+// 场景执行器（记录 open 步骤命名的账户）
+type scenarioRunner struct {
+	app   *app
+	names map[string]string
+}
+
+// 解析账户引用：@名称 替换为 open 步骤开立的账户ID
+func (r *scenarioRunner) account(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "@") {
+		return ref, nil
+	}
+	id, ok := r.names[strings.TrimPrefix(ref, "@")]
+	if !ok {
+		return "", fmt.Errorf("未定义的账户引用: %s", ref)
+	}
+	return id, nil
+}
+
+// 执行单个步骤，返回结果摘要
+func (r *scenarioRunner) run(step scenarioStep) (string, error) {
+	c := r.app.client
+	switch step.Action {
+	case "open":
+		result, err := c.OpenAccount(service.OpenAccountRequest{UserName: step.UserName, Currency: step.Currency, ProductCode: step.ProductCode})
+		if err != nil {
+			return "", err
+		}
+		if step.As != "" {
+			r.names[step.As] = result.Account.AccountID
+		}
+		return fmt.Sprintf("开户 %s（%s）", result.Account.AccountID, result.Account.UserName), nil
+
+	case "deposit":
+		accountID, err := r.account(step.AccountID)
+		if err != nil {
+			return "", err
+		}
+		result, err := c.Deposit(service.DepositRequest{AccountID: accountID, Amount: step.Amount}, 0)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s 存款 %.2f，余额 %.2f", accountID, step.Amount, result.NewBalance), nil
+
+	case "transfer":
+		from, err := r.account(step.From)
+		if err != nil {
+			return "", err
+		}
+		to, err := r.account(step.To)
+		if err != nil {
+			return "", err
+		}
+		result, err := c.Transfer(service.TransferRequest{FromAccount: from, ToAccount: to, Amount: step.Amount})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s → %s 转账 %.2f，转出账户余额 %.2f", from, to, step.Amount, result.NewBalance), nil
+
+	case "adjust":
+		accountID, err := r.account(step.AccountID)
+		if err != nil {
+			return "", err
+		}
+		result, err := c.AdjustBalance(accountID, step.Amount, step.Reason)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s 调账 %+.2f，余额 %.2f", accountID, step.Amount, result.NewBalance), nil
+
+	case "freeze", "unfreeze":
+		accountID, err := r.account(step.AccountID)
+		if err != nil {
+			return "", err
+		}
+		status := "frozen"
+		if step.Action == "unfreeze" {
+			status = "normal"
+		}
+		account, err := c.SetAccountStatus(accountID, status, step.Reason)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s 状态 %s", accountID, account.Status), nil
+
+	case "advance":
+		clock, err := c.AdvanceSimClock(step.Hours)
+		if err != nil {
+			return "", err
+		}
+		return "模拟时间 " + clock.SimTime, nil
+
+	case "eod":
+		var day time.Time
+		if step.Date != "" {
+			var err error
+			if day, err = time.ParseInLocation("2006-01-02", step.Date, time.Local); err != nil {
+				return "", fmt.Errorf("日期格式错误: %s", step.Date)
+			}
+		}
+		result, err := c.RunEOD(day)
+		if err != nil {
+			return "", err
+		}
+		return "日终已执行 " + result.Date, nil
+
+	case "seed":
+		result, err := c.Seed(service.SeedRequest{Count: step.Count, Currency: step.Currency, Transactions: step.Transactions})
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("生成 %d 个账户，历史流水 %d 笔", result.Count, result.Transactions), nil
+
+	case "sleep":
+		d, err := time.ParseDuration(step.Duration)
+		if err != nil {
+			return "", fmt.Errorf("等待时长格式错误: %s", step.Duration)
+		}
+		time.Sleep(d)
+		return "等待 " + d.String(), nil
+
+	default:
+		return "", fmt.Errorf("不支持的操作: %s", step.Action)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// snapshot list：查询状态快照列表
+func runSnapshotList(app *app, args []string) error {
+	if err := expectArgs(args, 0, "bankctl snapshot list"); err != nil {
+		return err
+	}
+	list, err := app.client.Snapshots()
+	if err != nil {
+		return err
+	}
+	return app.print(list, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "名称\t创建时间\t模拟时间\t最近日终\t账户数\t流水数\t大小")
+		for _, info := range list {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\n", info.Name, info.CreatedAt, info.SimTime,
+				info.LastEODDate, info.Accounts, info.Transactions, info.Size)
+		}
+	})
+}
+
+// snapshot create：创建状态快照
+func runSnapshotCreate(app *app, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("参数错误，用法: bankctl snapshot create [名称]")
+	}
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+	info, err := app.client.CreateSnapshot(name)
+	if err != nil {
+		return err
+	}
+	return app.print(info, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "快照已创建:\t%s\n模拟时间:\t%s\n账户/流水:\t%d / %d\n", info.Name, info.SimTime, info.Accounts, info.Transactions)
+	})
+}
+
+// snapshot restore：回滚到状态快照
+func runSnapshotRestore(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl snapshot restore <名称>"); err != nil {
+		return err
+	}
+	info, err := app.client.RestoreSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	return app.print(info, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "已恢复到快照:\t%s\n模拟时间:\t%s\n账户/流水:\t%d / %d\n", info.Name, info.SimTime, info.Accounts, info.Transactions)
+	})
+}
+
+// snapshot download：下载快照文件
+func runSnapshotDownload(app *app, args []string) error {
+	fs := newFlagSet("snapshot download", "<名称>")
+	output := fs.String("o", "", "保存路径，默认 <名称>.json")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(positional, 1, "bankctl snapshot download <名称> [-o 文件]"); err != nil {
+		return err
+	}
+	data, err := app.client.DownloadSnapshot(positional[0])
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		*output = positional[0] + ".json"
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(app.out, "已保存到 %s（%d 字节）\n", *output, len(data))
+	return nil
+}
+
+// snapshot upload：导入快照文件
+func runSnapshotUpload(app *app, args []string) error {
+	if err := expectArgs(args, 2, "bankctl snapshot upload <名称> <文件>"); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		return err
+	}
+	info, err := app.client.UploadSnapshot(args[0], data)
+	if err != nil {
+		return err
+	}
+	return app.print(info, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "快照已导入:\t%s\n模拟时间:\t%s\n", info.Name, info.SimTime)
+	})
+}
+
+// snapshot delete：删除状态快照
+func runSnapshotDelete(app *app, args []string) error {
+	if err := expectArgs(args, 1, "bankctl snapshot delete <名称>"); err != nil {
+		return err
+	}
+	if err := app.client.DeleteSnapshot(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(app.out, "快照已删除: %s\n", args[0])
+	return nil
+}
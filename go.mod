@@ -0,0 +1,5 @@
+module github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem
+
+go 1.21
+
+require github.com/gorilla/websocket v1.5.3
@@ -0,0 +1,570 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 账户接口实现 --------------------------
+
+// 获取账户信息
+func (h *Handler) getAccountInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	// 模拟获取当前登录用户的账户（实际项目应从 Token/Session 中获取）
+	account, err := h.accounts.Get(defaultAccountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	// 终端提示：账户信息查询
+	log.Println("\n[📋 账户查询]")
+	log.Printf("查询时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("当前余额: %.2f 元", account.Balance)
+	log.Printf("账户状态: %s", account.Status)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	if notModified(w, r, accountETag(account, r)) {
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取账户信息成功", account)
+}
+
+// 批量查询账户摘要（最多 50 个），供需要同时展示多个账户的前端一次取回；
+// 单个账户不存在或无权查看时在对应条目中返回错误，不影响其他账户
+func (h *Handler) handleBatchGetAccounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.AccountBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	callerID, err := h.currentAccountID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	items, err := h.accounts.BatchGet(callerID, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "批量查询账户成功", items)
+}
+
+// 处理存款请求
+func (h *Handler) handleDeposit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	// 解析请求体
+	var req service.DepositRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	expectedVersion, err := ifMatchVersion(r)
+	if err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, err.Error(), nil)
+		return
+	}
+
+	result, err := h.accounts.Deposit(req, expectedVersion)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	result.Account.LoyaltyPoints = h.loyalty.Balance(result.Account.AccountID)
+	w.Header().Set("ETag", accountETag(result.Account, r))
+	h.sendResponse(w, model.CODE_SUCCESS, "存款成功", map[string]interface{}{
+		"accountId":  req.AccountID,
+		"amount":     req.Amount,
+		"oldBalance": result.OldBalance,
+		"newBalance": result.Account.Balance,
+		"version":    result.Account.Version,
+		"time":       time.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+// 处理转账请求
+func (h *Handler) handleTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	// 解析请求体
+	var req service.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, err.Error(), nil)
+		return
+	}
+	req.FromVersion = version
+
+	data, err := h.transfer(r, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "转账成功", data)
+}
+
+// 风控评估后执行行内转账（转账接口与转账模板共用），指定收款别名时先解析为收款账户
+func (h *Handler) transfer(r *http.Request, req service.TransferRequest) (map[string]interface{}, error) {
+	if req.ToAlias != "" {
+		if req.ToAccount != "" {
+			return nil, model.NewError(model.CODE_PARAM_ERROR, "收款账户与收款别名只能指定一个")
+		}
+		accountID, err := h.aliases.Resolve(req.ToAlias)
+		if err != nil {
+			return nil, err
+		}
+		req.ToAccount = accountID
+	}
+
+	device, err := deviceID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 风控：新地点交易按配置标记或要求短信验证码，未信任设备交易要求短信验证码
+	assessment, err := h.risk.AssessTransfer(service.RiskRequest{AccountID: req.FromAccount, ToAccount: req.ToAccount, IP: clientIP(r), DeviceID: device, Amount: req.Amount, OTPCode: req.OTPCode})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := h.accounts.Transfer(req)
+	if err != nil {
+		return nil, err
+	}
+	data["risk"] = assessment
+	if req.ToAlias != "" {
+		data["toAlias"] = req.ToAlias
+	}
+	return data, nil
+}
+
+// 转账报价：返回手续费、汇率与扣款总额，报价 ID 可在有效期内用于转账以锁定价格
+func (h *Handler) handleTransferQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	quote, err := h.pricing.Quote(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "报价成功", quote)
+}
+
+// 转账前核对收款人户名（Confirmation of Payee）：返回 match/partial_match/no_match，
+// 户名相近时返回登记户名。收款人可用账号或收款别名指定
+func (h *Handler) handleVerifyPayee(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.PayeeCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	if req.FromAccount == "" {
+		req.FromAccount = defaultAccountID
+	}
+	if req.ToAlias != "" {
+		if req.ToAccount != "" {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "收款账户与收款别名只能指定一个", nil)
+			return
+		}
+		accountID, err := h.aliases.Resolve(req.ToAlias)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		req.ToAccount = accountID
+	}
+
+	check, err := h.payees.Verify(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "收款人核对完成", check)
+}
+
+// 激活休眠账户
+func (h *Handler) handleReactivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.ReactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	account, err := h.dormancy.Reactivate(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "账户已激活", account)
+}
+
+// 转账用途代码列表
+func (h *Handler) getPurposeCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取用途代码成功", service.PurposeCodes())
+}
+
+// -------------------------- 交易流水接口实现 --------------------------
+
+// 查询账户交易流水：可按金额区间（minAmount/maxAmount）、时间区间（from/to，RFC3339 或 YYYY-MM-DD）、
+// 交易类型（type，逗号分隔）、对方账户（counterpartyAccount）、借贷方向（direction）与状态（status）过滤，
+// 按 sort（time/amount）与 order（asc/desc，默认 desc）排序。带 cursor 或 limit 参数时按游标分页
+// （cursor 为空表示第一页，响应中的 nextCursor 用于查询下一页），否则按 page/pageSize 偏移分页
+func (h *Handler) getTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	account, err := h.accounts.Get(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	filter, err := parseTransactionFilter(query)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if notModified(w, r, h.historyETag(account, r)) {
+		return
+	}
+	if query.Has("cursor") || query.Has("limit") {
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		page, err := h.ledger.TransactionPage(accountID, filter, query.Get("cursor"), limit)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取交易流水成功", page)
+		return
+	}
+
+	all, err := h.ledger.SearchTransactions(accountID, filter)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	total := len(all)
+	items := make([]model.Transaction, 0, pageSize)
+	for i := (page - 1) * pageSize; i < total && len(items) < pageSize; i++ {
+		items = append(items, all[i])
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取交易流水成功", map[string]interface{}{
+		"accountId": accountID,
+		"page":      page,
+		"pageSize":  pageSize,
+		"total":     total,
+		"items":     items,
+	})
+}
+
+// 全文检索账户交易流水（q 为关键词，多个关键词以空格分隔且须全部命中），命中词在 highlights 中以 <em></em> 标出；
+// 可叠加 getTransactions 的过滤与排序参数，limit 为返回条数
+func (h *Handler) searchTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	account, err := h.accounts.Get(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	filter, err := parseTransactionFilter(query)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if notModified(w, r, h.historyETag(account, r)) {
+		return
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	result, err := h.ledger.SearchText(accountID, query.Get("q"), filter, limit)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "检索交易流水成功", result)
+}
+
+// 查询账户时点余额（at）或期间期初/期末余额（from/to），时间为 RFC3339 或 YYYY-MM-DD
+func (h *Handler) getBalanceAt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	account, err := h.accounts.Get(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	// 期间查询：期初为 from 时刻余额，期末为 to 时刻余额
+	if query.Get("from") != "" || query.Get("to") != "" {
+		from, errFrom := parseQueryTime(query.Get("from"))
+		to, errTo := parseQueryTime(query.Get("to"))
+		if errFrom != nil || errTo != nil || to.Before(from) {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "期间格式错误，from/to 应为 RFC3339 或 YYYY-MM-DD 且 from 不晚于 to", nil)
+			return
+		}
+
+		period, _ := h.ledger.PeriodBalance(accountID, from, to)
+		h.sendResponse(w, model.CODE_SUCCESS, "获取期间余额成功", map[string]interface{}{
+			"accountId":      accountID,
+			"currency":       account.Currency,
+			"from":           from.Format(time.RFC3339),
+			"to":             to.Format(time.RFC3339),
+			"openingBalance": model.RoundAmount(period.OpeningBalance),
+			"closingBalance": model.RoundAmount(period.ClosingBalance),
+			"creditTotal":    model.RoundAmount(period.CreditTotal),
+			"debitTotal":     model.RoundAmount(period.DebitTotal),
+			"count":          period.Count,
+		})
+		return
+	}
+
+	at := h.clock.Now()
+	if v := query.Get("at"); v != "" {
+		parsed, err := parseQueryTime(v)
+		if err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "时间格式错误，应为 RFC3339 或 YYYY-MM-DD", nil)
+			return
+		}
+		at = parsed
+	}
+
+	balance, _ := h.ledger.BalanceAt(accountID, at)
+	h.sendResponse(w, model.CODE_SUCCESS, "获取时点余额成功", map[string]interface{}{
+		"accountId": accountID,
+		"currency":  account.Currency,
+		"at":        at.Format(time.RFC3339),
+		"balance":   model.RoundAmount(balance),
+	})
+}
+
+// 账户动态：交易流水、登录、风控安全事件、账户状态变更与通知按时间倒序合并，供“最近动态”页面展示。
+// ?types= 按类型过滤（逗号分隔，见 service.TIMELINE_*），cursor/limit 游标分页；未指定 accountId 时为当前登录账户
+func (h *Handler) getAccountTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		var err error
+		if accountID, err = h.currentAccountID(r); err != nil {
+			h.sendError(w, err)
+			return
+		}
+	}
+	if _, err := h.accounts.Get(accountID); err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	var types []string
+	if value := query.Get("types"); value != "" {
+		types = strings.Split(value, ",")
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	page, err := h.timeline.Page(accountID, types, query.Get("cursor"), limit)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取账户动态成功", page)
+}
+
+// 账户查询结果对应的弱 ETag：由账户版本、积分余额与协商的响应媒体类型组成。
+// 积分账本独立维护、变化时账户版本不变，同一版本的 JSON 与 XML 表示也不同（响应均带 Vary: Accept）
+func accountETag(account model.Account, r *http.Request) string {
+	media := strings.ReplaceAll(strings.TrimPrefix(negotiateMIME(r.Header.Get("Accept")), "application/"), "/", "-")
+	return fmt.Sprintf("W/\"%d-%d-%s\"", account.Version, account.LoyaltyPoints, media)
+}
+
+// 交易流水查询结果对应的弱 ETag：由账户版本、账户流水版本与查询参数摘要组成，
+// 账户有新交易或查询条件不同时变化
+func (h *Handler) historyETag(account model.Account, r *http.Request) string {
+	digest := fnv.New32a()
+	digest.Write([]byte(r.URL.RawQuery))
+	return fmt.Sprintf("W/\"%d-%s-%08x\"", account.Version, h.ledger.HistoryVersion(account.AccountID), digest.Sum32())
+}
+
+// 条件 GET：设置 ETag 响应头，If-None-Match 与之匹配（弱比较，支持 * 与逗号分隔的多个值）时
+// 返回 304 且不含响应体，调用方应直接返回；否则返回 false 由调用方正常输出
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	value := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if value == "" {
+		return false
+	}
+	matched := value == "*"
+	for _, candidate := range strings.Split(value, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// 解析 If-Match 请求头中的账户版本（未携带时返回 0）：可为账户版本号，或账户 ETag（取其中的版本部分）
+func ifMatchVersion(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	value, _, _ = strings.Cut(strings.Trim(strings.TrimPrefix(value, "W/"), "\""), "-")
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("If-Match 格式错误")
+	}
+	return version, nil
+}
+
+// 解析交易流水过滤与排序参数（金额区间、时间区间、类型、方向、状态与排序）
+func parseTransactionFilter(query url.Values) (service.TransactionFilter, error) {
+	filter := service.TransactionFilter{
+		Reference:    query.Get("reference"),
+		PurposeCode:  query.Get("purposeCode"),
+		Counterparty: query.Get("counterpartyAccount"),
+		Direction:    query.Get("direction"),
+		Status:       query.Get("status"),
+		SortBy:       query.Get("sort"),
+	}
+	for name, target := range map[string]**float64{"minAmount": &filter.MinAmount, "maxAmount": &filter.MaxAmount} {
+		if value := query.Get(name); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil || amount < 0 {
+				return filter, model.NewError(model.CODE_PARAM_ERROR, name+" 应为非负数")
+			}
+			*target = &amount
+		}
+	}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			t, err := parseQueryTime(value)
+			if err != nil {
+				return filter, model.NewError(model.CODE_PARAM_ERROR, name+" 应为 RFC3339 或 YYYY-MM-DD")
+			}
+			*target = t
+		}
+	}
+	if value := query.Get("type"); value != "" {
+		filter.Types = strings.Split(value, ",")
+	}
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return filter, model.NewError(model.CODE_PARAM_ERROR, "排序方向仅支持 asc/desc")
+	}
+	return filter, nil
+}
+
+// 解析查询参数中的时间（RFC3339 或本地日期 YYYY-MM-DD）
+func parseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
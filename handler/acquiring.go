@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 商户收单接口实现 --------------------------
+
+// 收单商户：GET 查询商户列表，POST 登记商户
+func (h *Handler) handleMerchants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取商户列表成功", h.acquiring.Merchants())
+
+	case http.MethodPost:
+		var req service.MerchantRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		merchant, err := h.acquiring.Register(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "商户已登记", merchant)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// POS 消费
+func (h *Handler) handlePOSPurchase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.POSPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	tx, err := h.acquiring.Purchase(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "消费成功", tx)
+}
+
+// POS 退货（金额为空退还剩余全部金额）
+func (h *Handler) handlePOSRefund(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.POSRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	tx, err := h.acquiring.Refund(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "退货成功", tx)
+}
+
+// 查询 POS 交易
+func (h *Handler) getPOSTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	tx, err := h.acquiring.Transaction(r.URL.Query().Get("posTxId"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取交易成功", tx)
+}
+
+// 商户日结算汇总（date 为空返回全部日期）
+func (h *Handler) getMerchantSettlements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	settlements, err := h.acquiring.Settlements(query.Get("merchantId"), query.Get("date"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取结算汇总成功", settlements)
+}
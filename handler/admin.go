@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 管理接口实现 --------------------------
+
+// 查询实时试算平衡表（管理员）
+func (h *Handler) getTrialBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取试算平衡表成功", map[string]interface{}{
+		"trialBalance":       h.ledger.TrialBalance(),
+		"lastReconciliation": h.ledger.LastReconciliation(),
+	})
+}
+
+// 资金守恒检查（管理员）：客户余额与内部科目合计须等于经现金、清算等边界科目的净流入
+func (h *Handler) getInvariants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	report := h.ledger.CheckInvariants()
+	message := "资金守恒检查通过"
+	if !report.Holds {
+		message = "资金守恒检查未通过"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, report)
+}
+
+// 选主状态（管理员）：本节点标识、当前主节点与租约
+func (h *Handler) getLeaderStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取选主状态成功", h.leader.Status())
+}
+
+// 模拟外部系统熔断器状态（管理员）
+func (h *Handler) getBreakers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取熔断器状态成功", h.breakers.Statuses())
+}
+
+// 强制熔断或恢复熔断器（管理员）：POST /admin/breakers/{name}，{"action":"trip|reset"}
+func (h *Handler) handleBreaker(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/breakers/")
+	if name == "" || strings.Contains(name, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.BreakerActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	status, err := h.breakers.Apply(name, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := "熔断器已恢复"
+	if req.Action == service.BREAKER_ACTION_TRIP {
+		message = "熔断器已强制熔断"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, status)
+}
+
+// 查询总账科目余额（管理员）
+func (h *Handler) getGLBalances(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取总账余额成功", map[string]interface{}{
+		"chartOfAccounts": h.ledger.Chart().Accounts(),
+		"balances":        h.ledger.GLBalances(),
+	})
+}
+
+// 查询 WebSocket 推送统计（管理员）
+func (h *Handler) getWsMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取 WebSocket 统计成功", h.hub.Metrics())
+}
+
+// 查询 WebSocket 在线连接（管理员）：?accountId= 按账户过滤，附丢弃消息数与断开原因统计
+func (h *Handler) getWsConnections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	connections := h.hub.Connections(r.URL.Query().Get("accountId"))
+	metrics := h.hub.Metrics()
+	h.sendResponse(w, model.CODE_SUCCESS, "获取 WebSocket 连接成功", map[string]interface{}{
+		"total":           len(connections),
+		"connections":     connections,
+		"messagesDropped": metrics.MessagesDropped,
+		"disconnects":     metrics.Disconnects,
+	})
+}
+
+// 查询运营统计（管理员）：账户总数、在线连接、今日/本周交易、活跃账户与错误码分布
+func (h *Handler) getAdminStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	stats := h.stats.Activity()
+	h.sendResponse(w, model.CODE_SUCCESS, "获取运营统计成功", map[string]interface{}{
+		"generatedAt":   stats.GeneratedAt,
+		"accounts":      stats.Accounts,
+		"wsConnections": h.hub.Count(),
+		"wsAccounts":    h.hub.AccountCounts(), // 各账户当前 WebSocket 连接数
+		"today":         stats.Today,
+		"thisWeek":      stats.ThisWeek,
+		"topAccounts":   stats.TopAccounts,
+		"errorCodes":    errorCodeCounts(),
+	})
+}
+
+// 按条件查询账户列表（管理员）：status、name（户名前缀）、minBalance/maxBalance、
+// createdFrom/createdTo（YYYY-MM-DD）、sort（accountId/userName/balance/createAt）、order（asc/desc）、page、pageSize
+func (h *Handler) getAdminAccounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := service.AccountFilter{
+		Status:      query.Get("status"),
+		NamePrefix:  query.Get("name"),
+		CreatedFrom: query.Get("createdFrom"),
+		CreatedTo:   query.Get("createdTo"),
+		Sort:        query.Get("sort"),
+		Desc:        query.Get("order") == "desc",
+	}
+	filter.Page, _ = strconv.Atoi(query.Get("page"))
+	filter.PageSize, _ = strconv.Atoi(query.Get("pageSize"))
+
+	for key, target := range map[string]**float64{"minBalance": &filter.MinBalance, "maxBalance": &filter.MaxBalance} {
+		if v := query.Get(key); v != "" {
+			amount, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				h.sendResponse(w, model.CODE_PARAM_ERROR, key+" 格式错误", nil)
+				return
+			}
+			*target = &amount
+		}
+	}
+	for key, v := range map[string]string{"createdFrom": filter.CreatedFrom, "createdTo": filter.CreatedTo} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, key+" 格式错误，应为 YYYY-MM-DD", nil)
+			return
+		}
+	}
+
+	page, err := h.accounts.Search(filter)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取账户列表成功", page)
+}
+
+// 冻结/解冻账户（管理员）
+func (h *Handler) handleAccountStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.AccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	account, err := h.accounts.SetStatus(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	// 冻结账户时终止其全部会话并断开 WebSocket 连接
+	if account.Status == "frozen" {
+		h.sessions.Terminate(account.AccountID, service.SESSION_CMD_ACCOUNT_FROZEN, req.Reason)
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "账户状态已更新", account)
+}
+
+// 人工调账（管理员）
+func (h *Handler) handleAdjustBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.BalanceAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	result, err := h.accounts.Adjust(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "调账成功", map[string]interface{}{
+		"accountId":  req.AccountID,
+		"amount":     model.RoundAmount(req.Amount),
+		"oldBalance": result.OldBalance,
+		"newBalance": result.Account.Balance,
+		"version":    result.Account.Version,
+		"time":       h.clock.Now().Format("2006-01-02 15:04:05"),
+	})
+}
+
+// 批量导入账户（管理员）：提交 CSV（multipart 文件字段 file 或直接作为请求体），
+// ?dryRun=true 时只校验不写入，返回逐行校验结果
+func (h *Handler) handleAccountImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "未找到上传文件（字段名 file）", nil)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	report, err := h.importer.Import(io.LimitReader(body, 10<<20), dryRun)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := fmt.Sprintf("导入完成：成功 %d 个，失败 %d 行", report.Imported, report.Failed)
+	if dryRun {
+		message = fmt.Sprintf("校验完成：通过 %d 行，失败 %d 行（试运行，未写入）", report.Valid, report.Failed)
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, report)
+}
+
+// 消息模板：GET 查询（可按 event/locale/channel 过滤），POST 新增或覆盖
+func (h *Handler) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		h.sendResponse(w, model.CODE_SUCCESS, "获取消息模板成功",
+			h.templates.List(query.Get("event"), query.Get("locale"), query.Get("channel")))
+	case http.MethodPost:
+		var req service.MessageTemplate
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		tmpl, err := h.templates.Set(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "消息模板已更新", tmpl)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 重新加载消息模板文件（文件有误时保留原模板）
+func (h *Handler) handleReloadTemplates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	count, err := h.templates.Reload()
+	if err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, err.Error(), nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "消息模板已重新加载", map[string]interface{}{
+		"templates": count,
+	})
+}
+
+// 利息代扣税汇总（from/to 为空表示不限）
+func (h *Handler) getWithholdingTax(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	var from, to time.Time
+	var errFrom, errTo error
+	if value := query.Get("from"); value != "" {
+		from, errFrom = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if value := query.Get("to"); value != "" {
+		to, errTo = time.ParseInLocation("2006-01-02", value, time.Local)
+		to = to.AddDate(0, 0, 1)
+	}
+	if errFrom != nil || errTo != nil || (!from.IsZero() && !to.IsZero() && !to.After(from)) {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "日期区间错误，from/to 应为 YYYY-MM-DD 且 from 不晚于 to", nil)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取代扣税汇总成功", h.tax.Report(from, to))
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 余额提醒规则：GET 查询账户规则（?accountId=），POST 创建规则
+func (h *Handler) handleAlertRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取提醒规则成功", h.alertRules.List(accountID))
+
+	case http.MethodPost:
+		var req service.AlertRuleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		rule, err := h.alertRules.Create(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "提醒规则已创建", rule)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单条提醒规则：GET /alerts/rules/{id} 查询，PUT 修改阈值/启用状态，DELETE 删除
+func (h *Handler) handleAlertRuleAction(w http.ResponseWriter, r *http.Request) {
+	ruleID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/alerts/rules/")
+	if ruleID == "" || strings.Contains(ruleID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	var (
+		rule    service.AlertRule
+		err     error
+		message string
+	)
+	switch r.Method {
+	case http.MethodGet:
+		rule, err = h.alertRules.Get(ruleID)
+		message = "获取提醒规则成功"
+	case http.MethodPut:
+		var req service.AlertRuleUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		rule, err = h.alertRules.Update(ruleID, req)
+		message = "提醒规则已修改"
+	case http.MethodDelete:
+		rule, err = h.alertRules.Delete(ruleID)
+		message = "提醒规则已删除"
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, rule)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 收款别名接口实现 --------------------------
+
+// 收款别名：GET 查询账户绑定的别名（?accountId=），POST 申请绑定（验证码发往该手机号或邮箱），DELETE ?accountId=&alias= 解绑
+func (h *Handler) handleAliases(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取收款别名成功", h.aliases.List(accountID))
+
+	case http.MethodPost:
+		var req service.AliasRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		claim, err := h.aliases.Claim(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "验证码已发送，请确认绑定", claim)
+
+	case http.MethodDelete:
+		alias, err := h.aliases.Remove(accountID, query.Get("alias"))
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "收款别名已解绑", alias)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 确认收款别名绑定
+func (h *Handler) handleConfirmAlias(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.AliasConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	alias, err := h.aliases.Confirm(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "收款别名已绑定", alias)
+}
+
+// 转账前核对收款人：?alias= 返回别名对应账户的脱敏户名与账号
+func (h *Handler) getAliasLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	lookup, err := h.aliases.Lookup(r.URL.Query().Get("alias"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "查询收款人成功", lookup)
+}
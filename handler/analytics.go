@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// -------------------------- 收支分析接口实现 --------------------------
+
+// 月度现金流汇总：from/to 为 YYYY-MM（含），groupBy 为 category（交易类别）或 counterparty（对方）时附带分组合计
+func (h *Handler) getCashflow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	report, err := h.cashflow.Report(accountID, query.Get("from"), query.Get("to"), query.Get("groupBy"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取现金流汇总成功", report)
+}
+
+// 周期性支出识别：同一收款方、金额相近、间隔规律的支出，附预计下次日期与金额
+func (h *Handler) getRecurring(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	payments, err := h.recurring.Detect(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取周期性支出成功", payments)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 广播公告管理：GET 查询公告列表（?status= 按状态过滤），POST 发布公告（可定时发布、设置过期时间）
+func (h *Handler) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取广播公告成功", h.announcements.List(r.URL.Query().Get("status")))
+	case http.MethodPost:
+		var req service.AnnouncementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		announcement, err := h.announcements.Create(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		message := "广播公告已发布"
+		if announcement.Status == service.ANNOUNCEMENT_SCHEDULED {
+			message = "广播公告已排期"
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, message, announcement)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 取消待发布的公告：DELETE /api/admin/broadcast/{announcementId}
+func (h *Handler) handleBroadcastAction(w http.ResponseWriter, r *http.Request) {
+	announcementID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/broadcast/")
+	if announcementID == "" || strings.Contains(announcementID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	announcement, err := h.announcements.Cancel(announcementID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "广播公告已取消", announcement)
+}
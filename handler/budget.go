@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 月度预算：GET 查询账户预算及使用进度（?accountId=&month=YYYY-MM，默认当月），POST 创建预算
+func (h *Handler) handleBudgets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		accountID := query.Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		budgets, err := h.budgets.List(accountID, query.Get("month"))
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取预算成功", budgets)
+
+	case http.MethodPost:
+		var req service.BudgetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		budget, err := h.budgets.Create(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "预算已创建", budget)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单个预算：GET /budgets/{id} 查询使用进度（?month=），PUT 修改金额/启用状态，DELETE 删除
+func (h *Handler) handleBudgetAction(w http.ResponseWriter, r *http.Request) {
+	budgetID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/budgets/")
+	if budgetID == "" || strings.Contains(budgetID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	var (
+		budget  service.BudgetProgress
+		err     error
+		message string
+	)
+	switch r.Method {
+	case http.MethodGet:
+		budget, err = h.budgets.Get(budgetID, r.URL.Query().Get("month"))
+		message = "获取预算成功"
+	case http.MethodPut:
+		var req service.BudgetUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		budget, err = h.budgets.Update(budgetID, req)
+		message = "预算已修改"
+	case http.MethodDelete:
+		budget, err = h.budgets.Delete(budgetID)
+		message = "预算已删除"
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, budget)
+}
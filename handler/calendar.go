@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// -------------------------- 营业日历接口实现 --------------------------
+
+// 营业日历查询：from/to 为 YYYY-MM-DD，默认自今日起 30 天；
+// 带 month（YYYY-MM）参数时查询账户当月的收付款日历
+func (h *Handler) getCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Has("month") {
+		accountID := query.Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		calendar, err := h.upcoming.Month(accountID, query.Get("month"))
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取收付款日历成功", calendar)
+		return
+	}
+
+	from := sim.StartOfDay(h.clock.Now())
+	var errFrom, errTo error
+	if value := query.Get("from"); value != "" {
+		from, errFrom = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	to := from.AddDate(0, 0, 29)
+	if value := query.Get("to"); value != "" {
+		to, errTo = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if errFrom != nil || errTo != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "日期格式错误，应为 YYYY-MM-DD", nil)
+		return
+	}
+
+	view, err := h.calendar.Days(from, to)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取营业日历成功", view)
+}
+
+// 节假日：GET 查询，POST 新增或修改，DELETE ?date= 删除
+func (h *Handler) handleHolidays(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取节假日成功", h.calendar.Holidays())
+
+	case http.MethodPost:
+		var req service.Holiday
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		holiday, err := h.calendar.AddHoliday(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "节假日已保存", holiday)
+
+	case http.MethodDelete:
+		if err := h.calendar.RemoveHoliday(r.URL.Query().Get("date")); err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "节假日已删除", nil)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 清算所轧差场次列表（管理员，最近的在前，含收集中的场次）
+func (h *Handler) getNettingCycles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取轧差场次成功", h.clearingHouse.Cycles())
+}
+
+// 轧差场次报告（管理员）：/admin/netting/cycles/{cycleId}
+func (h *Handler) getNettingCycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	cycleID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/netting/cycles/")
+	cycle, err := h.clearingHouse.Cycle(cycleID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取场次报告成功", cycle)
+}
+
+// 立即截止当前场次（管理员），净额在下一次清算处理时经 RTGS 结算
+func (h *Handler) closeNettingCycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	cycle, err := h.clearingHouse.Close()
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "场次已截止，待净额结算", cycle)
+}
+
+// 模拟他行之间的低额支付（进入清算所当前场次）
+func (h *Handler) handleNettingTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.ClearingHouseTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	receipt, err := h.clearingHouse.Transfer(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "支付已提交清算所", receipt)
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// 响应压缩配置
+type CompressionConfig struct {
+	Enabled      bool
+	MinSize      int      // 响应体达到该字节数才压缩，过小的响应压缩收益不抵开销
+	Level        int      // 压缩级别 1~9，0 或越界时使用默认级别
+	ContentTypes []string // 可压缩的 Content-Type 前缀（如 application/json、text/），图片等已压缩格式不在其列
+}
+
+// 响应压缩中间件：按 Accept-Encoding 以 gzip 或 deflate 压缩响应（交易明细、对账单导出等大响应收益明显），
+// 仅压缩 Content-Type 在配置范围内且不小于 MinSize 的响应；WebSocket 升级请求、分段响应与已编码的响应原样返回
+func WithCompression(cfg CompressionConfig) func(http.Handler) http.Handler {
+	if cfg.Level < flate.BestSpeed || cfg.Level > flate.BestCompression {
+		cfg.Level = flate.DefaultCompression
+	}
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Accept-Encoding")
+			cw := &compressWriter{ResponseWriter: w, cfg: cfg, encoding: encoding}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// 按 Accept-Encoding（含 q 权重）选择压缩算法，权重相同时优先 gzip，不接受压缩时返回空。
+// 明确列出的编码以其权重为准（q=0 表示拒绝），* 仅作用于未列出的 gzip/deflate
+func negotiateEncoding(accept string) string {
+	listed := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding == "*" {
+			wildcard = q
+		} else {
+			listed[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{"gzip", "deflate"} {
+		q, ok := listed[coding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// 压缩响应写入器：先缓冲响应体，确定 Content-Type 且达到大小阈值后开始压缩，
+// 响应结束时仍不足阈值则原样写出
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      CompressionConfig
+	encoding string
+
+	status     int
+	buf        []byte
+	decided    bool           // 已确定是否压缩
+	compressor io.WriteCloser // 为空表示不压缩、直接写出
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if !cw.decided {
+		if !cw.compressible() {
+			cw.start(false)
+		} else {
+			cw.buf = append(cw.buf, p...)
+			if len(cw.buf) < cw.cfg.MinSize {
+				return len(p), nil
+			}
+			cw.start(true)
+			return len(p), cw.flushBuffer()
+		}
+	}
+	if cw.compressor != nil {
+		return cw.compressor.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// 推送缓冲内容（SSE 等流式响应不在可压缩类型内，直接透传）
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		cw.start(cw.compressible() && len(cw.buf) > 0)
+		cw.flushBuffer()
+	}
+	if f, ok := cw.compressor.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// 支持连接劫持（WebSocket 升级请求已在中间件中跳过，此处仅为兼容）
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// 供 http.ResponseController 访问底层写入器（如长轮询延长写超时）
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// 结束响应：未达阈值的缓冲内容原样写出，压缩流写入结尾
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			return nil // 处理函数未写出任何内容
+		}
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		cw.start(false)
+		if err := cw.flushBuffer(); err != nil {
+			return err
+		}
+	}
+	if cw.compressor != nil {
+		return cw.compressor.Close()
+	}
+	return nil
+}
+
+// 响应是否可压缩：状态码与 Content-Type 符合且未经其他编码
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+	if cw.status < http.StatusOK || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified ||
+		cw.status == http.StatusPartialContent || header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range cw.cfg.ContentTypes {
+		if prefix != "" && strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// 确定是否压缩并写出响应头
+func (cw *compressWriter) start(compress bool) {
+	cw.decided = true
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		if cw.encoding == "gzip" {
+			cw.compressor, _ = gzip.NewWriterLevel(cw.ResponseWriter, cw.cfg.Level)
+		} else {
+			cw.compressor, _ = zlib.NewWriterLevel(cw.ResponseWriter, cw.cfg.Level) // HTTP deflate 编码为 zlib 格式
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// 写出已缓冲的响应体
+func (cw *compressWriter) flushBuffer() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.compressor != nil {
+		_, err = cw.compressor.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
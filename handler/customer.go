@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 请求对应的客户：?customerId= 指定客户，或 ?accountId= 按账户所属客户，均为空时使用默认账户
+func (h *Handler) customerID(r *http.Request) (string, error) {
+	if customerID := r.URL.Query().Get("customerId"); customerID != "" {
+		return customerID, nil
+	}
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	return h.customers.Owner(accountID)
+}
+
+// 客户资料：GET 查询，PUT 修改（手机号、邮箱变更须经新联系方式验证后生效）
+func (h *Handler) handleCustomerProfile(w http.ResponseWriter, r *http.Request) {
+	customerID, err := h.customerID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profile, err := h.customers.Get(customerID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取客户资料成功", profile)
+	case http.MethodPut:
+		var req service.CustomerUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		profile, err := h.customers.Update(customerID, req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		message := "客户资料已更新"
+		if len(profile.PendingChanges) > 0 {
+			message = "客户资料已更新，联系方式变更待验证（验证码已发送到新的联系方式）"
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, message, profile)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 联系方式变更记录（含待验证、已生效与已失效的申请）
+func (h *Handler) getContactChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	customerID, err := h.customerID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取变更记录成功", h.customers.Changes(customerID))
+}
+
+// 确认联系方式变更：填写发往新手机号或新邮箱的验证码
+func (h *Handler) handleConfirmContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.ContactConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	profile, err := h.customers.ConfirmChange(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "联系方式已变更", profile)
+}
+
+// 审计日志（管理员）：?target= 按操作对象、?action= 按操作类型过滤；带 cursor 或 limit 参数时按游标分页返回
+func (h *Handler) getAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	query := r.URL.Query()
+	auditQuery := service.AuditQuery{Target: query.Get("target"), Action: query.Get("action")}
+	if query.Has("cursor") || query.Has("limit") {
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		page, err := h.audit.Page(auditQuery, query.Get("cursor"), limit)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取审计日志成功", page)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取审计日志成功", h.audit.List(auditQuery))
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 设备标识请求头（客户端生成并持久化的设备唯一标识）
+const DEVICE_HEADER = "X-Device-Id"
+
+// 请求携带的设备标识（未携带时为空，不做设备检查）
+func deviceID(r *http.Request) (string, error) {
+	id := strings.TrimSpace(r.Header.Get(DEVICE_HEADER))
+	if id == "" {
+		return "", nil
+	}
+	return id, service.ValidateDeviceID(id)
+}
+
+// 账户设备列表（含信任、未识别与已移除设备）
+func (h *Handler) getDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取设备列表成功", h.risk.Devices(accountID))
+}
+
+// 移除设备：DELETE /api/devices/{deviceId}?accountId=，设备不再信任并终止其上的登录会话
+func (h *Handler) handleDevice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/devices/")
+	if id == "" || strings.Contains(id, "/") {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "设备标识不能为空", nil)
+		return
+	}
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	device, err := h.risk.RevokeDevice(accountID, id)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sessions := h.sessions.TerminateDevice(accountID, id)
+	h.sendResponse(w, model.CODE_SUCCESS, "设备已移除", map[string]interface{}{
+		"device":          device,
+		"revokedSessions": sessions,
+	})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 直接借记接口实现 --------------------------
+
+// 直接借记授权：GET 查询账户授权列表，POST 由商户登记授权
+func (h *Handler) handleMandates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取授权列表成功", h.directDebits.Mandates(accountID))
+
+	case http.MethodPost:
+		var req service.MandateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		mandate, err := h.directDebits.Register(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "授权已登记", mandate)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单笔授权：GET /direct-debits/mandates/{id} 查询，DELETE 由客户撤销授权
+func (h *Handler) handleMandateAction(w http.ResponseWriter, r *http.Request) {
+	mandateID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/direct-debits/mandates/")
+	if mandateID == "" || strings.Contains(mandateID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		mandate, err := h.directDebits.Mandate(mandateID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取授权成功", mandate)
+
+	case http.MethodDelete:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		mandate, err := h.directDebits.Cancel(mandateID, accountID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "授权已撤销", mandate)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 商户按授权扣款
+func (h *Handler) handleDirectDebitCollect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.DirectDebitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	collection, err := h.directDebits.Collect(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "扣款成功", collection)
+}
+
+// 查询账户的直接借记扣款记录（可按 mandateId 过滤）
+func (h *Handler) getDirectDebits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取扣款记录成功", h.directDebits.Collections(accountID, query.Get("mandateId")))
+}
+
+// 退回扣款：POST /direct-debits/collections/{id}/return
+func (h *Handler) handleDirectDebitReturn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/direct-debits/collections/")
+	collectionID, action, found := strings.Cut(path, "/")
+	if !found || collectionID == "" || action != "return" {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	var req service.DirectDebitReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	if req.AccountID == "" {
+		req.AccountID = defaultAccountID
+	}
+
+	collection, err := h.directDebits.Return(collectionID, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "扣款已退回", collection)
+}
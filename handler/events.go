@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 长轮询最长等待时间
+const POLL_MAX_WAIT = 30 * time.Second
+
+// 长轮询当前登录账户的事件（WebSocket 与 SSE 被网络环境拦截时的降级方案）：
+// ?since=N 返回序号 N 之后的事件，未携带时从当前最新事件开始等待；暂无新事件时最多等待 wait 秒（默认且最多 30 秒），
+// 事件格式与 WebSocket 推送一致（?version= 指定消息格式版本），响应中的 latestEventId 作为下一次请求的 since
+func (h *Handler) getEventsPoll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID, err := h.currentAccountID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	version := ws.SCHEMA_DEFAULT
+	if v := query.Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || !ws.SupportedVersion(n) {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的消息格式版本", nil)
+			return
+		}
+		version = n
+	}
+	wait := POLL_MAX_WAIT
+	if v := query.Get("wait"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > POLL_MAX_WAIT {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "wait 应为 0~30 的整数（秒）", nil)
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	since := h.hub.LatestEventID(accountID)
+	if v := query.Get("since"); v != "" {
+		if since, err = strconv.ParseUint(v, 10, 64); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "since 应为事件序号", nil)
+			return
+		}
+	}
+
+	// 等待时间可能超过服务端写超时，按本次等待时间延长
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + 10*time.Second))
+
+	result, err := h.hub.Poll(r.Context(), accountID, since, version, wait)
+	if errors.Is(err, ws.ErrPollUnavailable) {
+		h.sendResponse(w, model.CODE_SERVER_BUSY, err.Error(), nil)
+		return
+	}
+	if r.Context().Err() != nil {
+		return // 客户端已断开
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取事件成功", result)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 功能开关列表（含当前取值与来源）
+func (h *Handler) getFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取功能开关成功", h.flags.List())
+}
+
+// 单个功能开关：GET 查询，PUT/POST 设置（{"enabled": true}），DELETE 撤销设置恢复配置文件或默认值；
+// POST /api/admin/flags/reload 重新读取配置文件
+func (h *Handler) handleFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/flags/")
+	if name == "" || strings.Contains(name, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "功能开关不存在", nil)
+		return
+	}
+	if name == "reload" {
+		if r.Method != http.MethodPost {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		if err := h.flags.Reload(); err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "功能开关配置已重新读取", h.flags.List())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		flag, ok := h.flags.Get(name)
+		if !ok {
+			h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "功能开关不存在: "+name, nil)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取功能开关成功", flag)
+	case http.MethodPut, http.MethodPost:
+		var req service.FeatureFlagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.Enabled == nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "enabled 不能为空", nil)
+			return
+		}
+		flag, err := h.flags.Set(name, *req.Enabled)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "功能开关已设置", flag)
+	case http.MethodDelete:
+		flag, err := h.flags.Reset(name)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "功能开关已恢复", flag)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 财务报表（管理员）：/admin/financials/{balance-sheet|profit-loss}
+// ?period=YYYY-MM 按月出表，或 ?from=&to= 指定日期区间（资产负债表取 to 日日终），默认本月初至今日；
+// ?format=csv 导出 CSV
+func (h *Handler) getFinancialStatement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	statement := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/financials/")
+	if statement != "balance-sheet" && statement != "profit-loss" {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	today := sim.StartOfDay(h.clock.Now())
+	from, to := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location()), today
+	if period := query.Get("period"); period != "" {
+		month, err := time.ParseInLocation("2006-01", period, time.Local)
+		if err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "期间格式错误，应为 YYYY-MM", nil)
+			return
+		}
+		from, to = month, month.AddDate(0, 1, -1)
+	}
+	var errFrom, errTo error
+	if value := query.Get("from"); value != "" {
+		from, errFrom = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if value := query.Get("to"); value != "" {
+		to, errTo = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "日期区间错误，from/to 应为 YYYY-MM-DD 且 from 不晚于 to", nil)
+		return
+	}
+
+	var result interface {
+		CSV() ([]byte, error)
+	}
+	var message, filename string
+	if statement == "balance-sheet" {
+		result = h.ledger.BalanceSheet(to)
+		message, filename = "生成资产负债表成功", "balance_sheet_"+to.Format("20060102")
+	} else {
+		result = h.ledger.ProfitAndLoss(from, to)
+		message, filename = "生成损益表成功", "profit_loss_"+from.Format("20060102")+"_"+to.Format("20060102")
+	}
+
+	switch query.Get("format") {
+	case "", "json":
+		h.sendResponse(w, model.CODE_SUCCESS, message, result)
+
+	case "csv":
+		output, err := result.CSV()
+		if err != nil {
+			h.sendResponse(w, model.CODE_UNKNOWN_ERROR, "报表导出失败", nil)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+filename+".csv")
+		w.WriteHeader(http.StatusOK)
+		w.Write(output)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的文件格式，可选 json/csv", nil)
+	}
+}
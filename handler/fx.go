@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 汇率接口实现 --------------------------
+
+// 查询汇率牌价
+func (h *Handler) getFXRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	rates, spread := h.fx.Rates()
+	h.sendResponse(w, model.CODE_SUCCESS, "获取汇率成功", map[string]interface{}{
+		"baseCurrency": model.BASE_CURRENCY,
+		"spread":       spread,
+		"rates":        rates,
+	})
+}
+
+// 设置汇率中间价（管理员）
+func (h *Handler) handleSetFXRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.FXRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	spread, err := h.fx.SetRate(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "汇率已更新", map[string]interface{}{
+		"currency": strings.ToUpper(req.Currency),
+		"midRate":  req.Rate,
+		"spread":   spread,
+	})
+}
@@ -0,0 +1,554 @@
+// Package handler 负责 HTTP/TCP 接口层：解析请求、调用业务服务并返回统一格式响应。
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 接口路径
+const (
+	API_BASE_URL = "/api" // 接口基础路径
+	WS_PATH      = "/ws"  // WebSocket 路径
+)
+
+// 默认测试账户（实际项目应从 Token/Session 中获取当前登录用户）
+const defaultAccountID = "8001234567"
+
+// 响应结构体（统一返回格式）
+type Response struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// 业务码出现次数（供运营统计）
+var responseCodes = struct {
+	sync.Mutex
+	counts map[int]int64
+}{counts: make(map[int]int64)}
+
+// 业务码统计
+type CodeCount struct {
+	Code  int   `json:"code"`
+	Count int64 `json:"count"`
+}
+
+// 接口层依赖的业务服务
+type Deps struct {
+	Accounts      *service.AccountService
+	Ledger        *service.LedgerService
+	FX            *service.FXService
+	Interbank     *service.InterbankService
+	Teller        *service.TellerService
+	Statements    *service.StatementService
+	EOD           *service.EODService
+	Payments      *service.PaymentService
+	OpenBanking   *service.OpenBankingService
+	Cards         *service.CardService
+	Cashback      *service.CashbackService
+	Loyalty       *service.LoyaltyService
+	Credit        *service.CreditService
+	Loans         *service.LoanService
+	DirectDebits  *service.DirectDebitService
+	Payroll       *service.PayrollService
+	Acquiring     *service.AcquiringService
+	Settlements   *service.SettlementService
+	Regulatory    *service.RegulatoryService
+	Tax           *service.TaxService
+	Calendar      *service.CalendarService
+	Stats         *service.StatsService
+	Cashflow      *service.CashflowService
+	Recurring     *service.RecurringService
+	Upcoming      *service.PaymentCalendarService
+	Periods       *service.PeriodCloseService
+	Notifications *service.NotificationService
+	Emails        *service.EmailService
+	SMS           *service.SMSService
+	Templates     *service.TemplateRegistry
+	Holds         *service.HoldService
+	Suspense      *service.SuspenseService
+	Treasury      *service.TreasuryService
+	RTGS          *service.RTGSService
+	ClearingHouse *service.ClearingHouseService
+	Leader        *service.LeaderElector
+	Breakers      *service.BreakerRegistry
+	Pricing       *service.PricingService
+	Products      *service.ProductService
+	Dormancy      *service.DormancyService
+	Promos        *service.PromoService
+	Onboarding    *service.OnboardingService
+	Customers     *service.CustomerService
+	Audit         *service.AuditService
+	Sessions      *service.SessionService
+	LoginGuard    *service.LoginGuardService
+	Passwords     *service.PasswordService
+	Announcements *service.AnnouncementService
+	AlertRules    *service.AlertRuleService
+	Budgets       *service.BudgetService
+	Favorites     *service.TransferTemplateService
+	Scheduled     *service.ScheduledTransferService
+	Aliases       *service.AliasService
+	Payees        *service.PayeeService
+	Risk          *service.RiskService
+	Timeline      *service.TimelineService
+	Support       *service.SupportChatService
+	Seeder        *service.SeedService
+	Importer      *service.AccountImportService
+	Chaos         *service.ChaosService
+	Maintenance   *service.MaintenanceService
+	Flags         *service.FeatureFlagService
+	Sagas         *service.SagaService
+	Snapshots     *service.SnapshotService
+	Clock         *sim.Clock
+	Hub           *ws.Hub
+}
+
+// 接口处理器
+type Handler struct {
+	accounts      *service.AccountService
+	ledger        *service.LedgerService
+	fx            *service.FXService
+	interbank     *service.InterbankService
+	teller        *service.TellerService
+	statements    *service.StatementService
+	eod           *service.EODService
+	payments      *service.PaymentService
+	openBanking   *service.OpenBankingService
+	cards         *service.CardService
+	cashback      *service.CashbackService
+	loyalty       *service.LoyaltyService
+	credit        *service.CreditService
+	loans         *service.LoanService
+	directDebits  *service.DirectDebitService
+	payroll       *service.PayrollService
+	acquiring     *service.AcquiringService
+	settlements   *service.SettlementService
+	regulatory    *service.RegulatoryService
+	tax           *service.TaxService
+	calendar      *service.CalendarService
+	stats         *service.StatsService
+	cashflow      *service.CashflowService
+	recurring     *service.RecurringService
+	upcoming      *service.PaymentCalendarService
+	periods       *service.PeriodCloseService
+	notifications *service.NotificationService
+	emails        *service.EmailService
+	sms           *service.SMSService
+	templates     *service.TemplateRegistry
+	holds         *service.HoldService
+	suspense      *service.SuspenseService
+	treasury      *service.TreasuryService
+	rtgs          *service.RTGSService
+	clearingHouse *service.ClearingHouseService
+	leader        *service.LeaderElector
+	breakers      *service.BreakerRegistry
+	pricing       *service.PricingService
+	products      *service.ProductService
+	dormancy      *service.DormancyService
+	promos        *service.PromoService
+	onboarding    *service.OnboardingService
+	customers     *service.CustomerService
+	audit         *service.AuditService
+	sessions      *service.SessionService
+	loginGuard    *service.LoginGuardService
+	passwords     *service.PasswordService
+	announcements *service.AnnouncementService
+	alertRules    *service.AlertRuleService
+	budgets       *service.BudgetService
+	favorites     *service.TransferTemplateService
+	scheduled     *service.ScheduledTransferService
+	aliases       *service.AliasService
+	payees        *service.PayeeService
+	risk          *service.RiskService
+	timeline      *service.TimelineService
+	support       *service.SupportChatService
+	seeder        *service.SeedService
+	importer      *service.AccountImportService
+	chaos         *service.ChaosService
+	maintenance   *service.MaintenanceService
+	flags         *service.FeatureFlagService
+	sagas         *service.SagaService
+	snapshots     *service.SnapshotService
+	clock         *sim.Clock
+	hub           *ws.Hub
+}
+
+func New(deps Deps) *Handler {
+	return &Handler{
+		accounts:      deps.Accounts,
+		ledger:        deps.Ledger,
+		fx:            deps.FX,
+		interbank:     deps.Interbank,
+		teller:        deps.Teller,
+		statements:    deps.Statements,
+		eod:           deps.EOD,
+		payments:      deps.Payments,
+		openBanking:   deps.OpenBanking,
+		cards:         deps.Cards,
+		cashback:      deps.Cashback,
+		loyalty:       deps.Loyalty,
+		credit:        deps.Credit,
+		loans:         deps.Loans,
+		directDebits:  deps.DirectDebits,
+		payroll:       deps.Payroll,
+		acquiring:     deps.Acquiring,
+		settlements:   deps.Settlements,
+		regulatory:    deps.Regulatory,
+		tax:           deps.Tax,
+		calendar:      deps.Calendar,
+		stats:         deps.Stats,
+		cashflow:      deps.Cashflow,
+		recurring:     deps.Recurring,
+		upcoming:      deps.Upcoming,
+		periods:       deps.Periods,
+		notifications: deps.Notifications,
+		emails:        deps.Emails,
+		sms:           deps.SMS,
+		templates:     deps.Templates,
+		holds:         deps.Holds,
+		suspense:      deps.Suspense,
+		treasury:      deps.Treasury,
+		rtgs:          deps.RTGS,
+		clearingHouse: deps.ClearingHouse,
+		leader:        deps.Leader,
+		breakers:      deps.Breakers,
+		pricing:       deps.Pricing,
+		products:      deps.Products,
+		dormancy:      deps.Dormancy,
+		promos:        deps.Promos,
+		onboarding:    deps.Onboarding,
+		customers:     deps.Customers,
+		audit:         deps.Audit,
+		sessions:      deps.Sessions,
+		loginGuard:    deps.LoginGuard,
+		passwords:     deps.Passwords,
+		announcements: deps.Announcements,
+		alertRules:    deps.AlertRules,
+		budgets:       deps.Budgets,
+		favorites:     deps.Favorites,
+		scheduled:     deps.Scheduled,
+		aliases:       deps.Aliases,
+		payees:        deps.Payees,
+		risk:          deps.Risk,
+		timeline:      deps.Timeline,
+		support:       deps.Support,
+		seeder:        deps.Seeder,
+		importer:      deps.Importer,
+		chaos:         deps.Chaos,
+		maintenance:   deps.Maintenance,
+		flags:         deps.Flags,
+		sagas:         deps.Sagas,
+		snapshots:     deps.Snapshots,
+		clock:         deps.Clock,
+		hub:           deps.Hub,
+	}
+}
+
+// 注册 API 与 WebSocket 路由
+func (h *Handler) Register(mux *http.ServeMux) {
+	// API 接口路由
+	mux.HandleFunc(API_BASE_URL+"/auth/login", h.handleLogin)                        // 登录
+	mux.HandleFunc(API_BASE_URL+"/auth/session", h.handleSession)                    // 当前会话查询/退出登录
+	mux.HandleFunc(API_BASE_URL+"/auth/password/forgot", h.handleForgotPassword)     // 忘记密码（下发重置令牌）
+	mux.HandleFunc(API_BASE_URL+"/auth/password/reset", h.handleResetPassword)       // 重置密码
+	mux.HandleFunc(API_BASE_URL+"/account", h.getAccountInfo)                        // 获取账户信息
+	mux.HandleFunc(API_BASE_URL+"/deposit", h.handleDeposit)                         // 存款接口
+	mux.HandleFunc(API_BASE_URL+"/transfer", h.handleTransfer)                       // 转账接口
+	mux.HandleFunc(API_BASE_URL+"/transfer/purpose-codes", h.getPurposeCodes)        // 用途代码列表
+	mux.HandleFunc(API_BASE_URL+"/transfer/quote", h.handleTransferQuote)            // 转账报价（手续费与汇率）
+	mux.HandleFunc(API_BASE_URL+"/transfer/templates", h.handleTransferTemplates)    // 转账模板查询/创建
+	mux.HandleFunc(API_BASE_URL+"/transfer/templates/", h.handleTransferTemplate)    // 转账模板查询/修改/删除/执行
+	mux.HandleFunc(API_BASE_URL+"/transfer/scheduled", h.handleScheduledTransfers)   // 定时转账查询/预约
+	mux.HandleFunc(API_BASE_URL+"/transfer/scheduled/", h.handleScheduledTransfer)   // 定时转账查询/修改/取消/暂停/恢复
+	mux.HandleFunc(API_BASE_URL+"/transfer/verify-payee", h.handleVerifyPayee)       // 转账前核对收款人户名
+	mux.HandleFunc(API_BASE_URL+"/aliases", h.handleAliases)                         // 收款别名查询/绑定/解绑
+	mux.HandleFunc(API_BASE_URL+"/aliases/confirm", h.handleConfirmAlias)            // 确认收款别名绑定
+	mux.HandleFunc(API_BASE_URL+"/aliases/lookup", h.getAliasLookup)                 // 按别名核对收款人
+	mux.HandleFunc(API_BASE_URL+"/account/reactivate", h.handleReactivate)           // 激活休眠账户
+	mux.HandleFunc(API_BASE_URL+"/account/product", h.getAccountProduct)             // 账户产品与计提利息
+	mux.HandleFunc(API_BASE_URL+"/transactions", h.getTransactions)                  // 交易流水查询
+	mux.HandleFunc(API_BASE_URL+"/transactions/search", h.searchTransactions)        // 交易流水全文检索
+	mux.HandleFunc(API_BASE_URL+"/account/balance", h.getBalanceAt)                  // 时点/期间余额
+	mux.HandleFunc(API_BASE_URL+"/account/timeline", h.getAccountTimeline)           // 账户动态（交易、登录、安全事件、状态变更与通知）
+	mux.HandleFunc(API_BASE_URL+"/accounts/open", h.handleOpenAccount)               // 开户（可填写推荐码）
+	mux.HandleFunc(API_BASE_URL+"/accounts/batch-get", h.handleBatchGetAccounts)     // 批量查询账户摘要
+	mux.HandleFunc(API_BASE_URL+"/customer/profile", h.handleCustomerProfile)        // 客户资料查询/修改
+	mux.HandleFunc(API_BASE_URL+"/customer/contact/changes", h.getContactChanges)    // 联系方式变更记录
+	mux.HandleFunc(API_BASE_URL+"/customer/contact/confirm", h.handleConfirmContact) // 确认联系方式变更
+	mux.HandleFunc(API_BASE_URL+"/promos/rewards", h.getPromoRewards)                // 营销奖励记录
+	mux.HandleFunc(API_BASE_URL+"/cashback", h.getCashbackSummary)                   // 消费返现汇总
+	mux.HandleFunc(API_BASE_URL+"/loyalty", h.getLoyaltyAccount)                     // 积分账本
+	mux.HandleFunc(API_BASE_URL+"/loyalty/redeem", h.handleRedeemPoints)             // 积分兑换
+	mux.HandleFunc(API_BASE_URL+"/analytics/cashflow", h.getCashflow)                // 月度现金流汇总
+	mux.HandleFunc(API_BASE_URL+"/analytics/recurring", h.getRecurring)              // 周期性支出识别
+	mux.HandleFunc(API_BASE_URL+"/budgets", h.handleBudgets)                         // 月度预算查询/创建
+	mux.HandleFunc(API_BASE_URL+"/budgets/", h.handleBudgetAction)                   // 月度预算查询/修改/删除
+
+	// 信用评分与贷款路由
+	mux.HandleFunc(API_BASE_URL+"/credit/score", h.getCreditScore) // 信用评分
+	mux.HandleFunc(API_BASE_URL+"/loans", h.handleLoans)           // 贷款查询/申请
+	mux.HandleFunc(API_BASE_URL+"/loans/", h.handleLoanAction)     // 贷款详情
+
+	// 直接借记路由
+	mux.HandleFunc(API_BASE_URL+"/direct-debits/mandates", h.handleMandates)              // 授权查询/登记
+	mux.HandleFunc(API_BASE_URL+"/direct-debits/mandates/", h.handleMandateAction)        // 授权查询/撤销
+	mux.HandleFunc(API_BASE_URL+"/direct-debits/collect", h.handleDirectDebitCollect)     // 商户按授权扣款
+	mux.HandleFunc(API_BASE_URL+"/direct-debits/collections", h.getDirectDebits)          // 扣款记录
+	mux.HandleFunc(API_BASE_URL+"/direct-debits/collections/", h.handleDirectDebitReturn) // 扣款退回
+
+	// 代发工资路由
+	mux.HandleFunc(API_BASE_URL+"/payroll/batches", h.handlePayrollBatches) // 代发批次查询/提交
+	mux.HandleFunc(API_BASE_URL+"/payroll/batches/", h.getPayrollBatch)     // 代发批次结果报告
+
+	// 商户收单路由
+	mux.HandleFunc(API_BASE_URL+"/pos/purchase", h.handlePOSPurchase)              // POS 消费
+	mux.HandleFunc(API_BASE_URL+"/pos/refund", h.handlePOSRefund)                  // POS 退货
+	mux.HandleFunc(API_BASE_URL+"/pos/transaction", h.getPOSTransaction)           // POS 交易查询
+	mux.HandleFunc(API_BASE_URL+"/merchants/settlement", h.getMerchantSettlements) // 商户日结算汇总
+
+	// 资金冻结（止付）路由
+	mux.HandleFunc(API_BASE_URL+"/holds", h.handleHolds)       // 冻结查询/设置
+	mux.HandleFunc(API_BASE_URL+"/holds/", h.handleHoldAction) // 冻结查询/修改/解除
+
+	// 通知中心路由
+	mux.HandleFunc(API_BASE_URL+"/events/poll", h.getEventsPoll)               // 长轮询账户事件（WebSocket 降级方案）
+	mux.HandleFunc(API_BASE_URL+"/notifications", h.getNotifications)          // 通知列表及未读数
+	mux.HandleFunc(API_BASE_URL+"/notifications/", h.handleNotificationAction) // 标记已读
+	mux.HandleFunc(API_BASE_URL+"/alerts/rules", h.handleAlertRules)           // 余额提醒规则查询/创建
+	mux.HandleFunc(API_BASE_URL+"/alerts/rules/", h.handleAlertRuleAction)     // 余额提醒规则查询/修改/删除
+
+	// 在线客服路由
+	mux.HandleFunc(API_BASE_URL+"/support/chat", h.handleSupportChat)                         // 会话查询/发送消息（客户）
+	mux.HandleFunc(API_BASE_URL+"/admin/support/conversations", h.getSupportQueue)            // 会话队列（管理员）
+	mux.HandleFunc(API_BASE_URL+"/admin/support/conversations/", h.handleSupportConversation) // 会话查询/回复/结束（管理员）
+
+	// 短信验证码路由
+	mux.HandleFunc(API_BASE_URL+"/otp/send", h.handleSendOTP)     // 发送验证码
+	mux.HandleFunc(API_BASE_URL+"/otp/verify", h.handleVerifyOTP) // 校验验证码
+
+	// 风控路由
+	mux.HandleFunc(API_BASE_URL+"/risk/locations", h.getRiskLocations)          // 账户历史地点
+	mux.HandleFunc(API_BASE_URL+"/risk/locations/trust", h.handleTrustLocation) // 标记可信地点
+	mux.HandleFunc(API_BASE_URL+"/devices", h.getDevices)                       // 设备列表
+	mux.HandleFunc(API_BASE_URL+"/devices/", h.handleDevice)                    // 移除设备
+	mux.HandleFunc(API_BASE_URL+"/security/logins", h.getSecurityLogins)        // 登录记录
+	mux.HandleFunc(API_BASE_URL+"/security/sessions", h.getSecuritySessions)    // 在线会话
+	mux.HandleFunc(API_BASE_URL+"/security/sessions/", h.handleSecuritySession) // 远程下线会话
+
+	// 开放银行路由（AIS/PIS）
+	mux.HandleFunc(API_BASE_URL+"/openbanking/consents", h.handleCreateConsent)              // 创建同意书
+	mux.HandleFunc(API_BASE_URL+"/openbanking/consent", h.getConsent)                        // 查询同意书
+	mux.HandleFunc(API_BASE_URL+"/openbanking/consents/authorize", h.handleAuthorizeConsent) // 客户授权
+	mux.HandleFunc(API_BASE_URL+"/openbanking/consents/revoke", h.handleRevokeConsent)       // 撤销同意书
+	mux.HandleFunc(API_BASE_URL+"/openbanking/aisp/accounts", h.getAISAccounts)              // AIS 账户信息
+	mux.HandleFunc(API_BASE_URL+"/openbanking/aisp/balances", h.getAISBalances)              // AIS 余额
+	mux.HandleFunc(API_BASE_URL+"/openbanking/aisp/transactions", h.getAISTransactions)      // AIS 交易流水
+	mux.HandleFunc(API_BASE_URL+"/openbanking/pisp/payments", h.handlePISPayment)            // PIS 发起支付
+	mux.HandleFunc(API_BASE_URL+"/openbanking/pisp/payment", h.getPISPayment)                // PIS 支付状态
+
+	// 日终与对账单路由
+	mux.HandleFunc(API_BASE_URL+"/statements", h.getStatements)                     // 对账单列表
+	mux.HandleFunc(API_BASE_URL+"/statements/subscription", h.handleStatementSub)   // 月度对账单订阅查询/开通/关闭
+	mux.HandleFunc(API_BASE_URL+"/statements/camt053", h.getCamt053Statement)       // camt.053 对账单导出
+	mux.HandleFunc(API_BASE_URL+"/statements/mt940", h.getMT940Statement)           // MT940 对账单导出
+	mux.HandleFunc(API_BASE_URL+"/statements/ofx", h.getOFXStatement)               // OFX 交易导出
+	mux.HandleFunc(API_BASE_URL+"/admin/eod", h.handleRunEOD)                       // 手动触发日终
+	mux.HandleFunc(API_BASE_URL+"/admin/trial-balance", h.getTrialBalance)          // 试算平衡表
+	mux.HandleFunc(API_BASE_URL+"/admin/invariants", h.getInvariants)               // 资金守恒检查
+	mux.HandleFunc(API_BASE_URL+"/admin/periods", h.handlePeriods)                  // 已结账期间/手动结账
+	mux.HandleFunc(API_BASE_URL+"/admin/periods/", h.getPeriodClose)                // 期间结账报告
+	mux.HandleFunc(API_BASE_URL+"/admin/settlement-files", h.getSettlementFiles)    // 清算文件列表
+	mux.HandleFunc(API_BASE_URL+"/admin/settlement-files/", h.getSettlementFile)    // 清算文件下载
+	mux.HandleFunc(API_BASE_URL+"/admin/reports/", h.getRegulatoryReport)           // 监管报表导出
+	mux.HandleFunc(API_BASE_URL+"/admin/financials/", h.getFinancialStatement)      // 资产负债表/损益表
+	mux.HandleFunc(API_BASE_URL+"/admin/treasury", h.handleTreasury)                // 资金头寸看板/准备金配置
+	mux.HandleFunc(API_BASE_URL+"/admin/rtgs/accounts", h.getRTGSAccounts)          // 央行 RTGS 清算账户
+	mux.HandleFunc(API_BASE_URL+"/admin/rtgs/accounts/", h.handleRTGSAccount)       // 调整日间透支限额
+	mux.HandleFunc(API_BASE_URL+"/admin/rtgs/queue", h.getRTGSQueue)                // RTGS 结算队列
+	mux.HandleFunc(API_BASE_URL+"/admin/rtgs/entries", h.getRTGSEntries)            // RTGS 已结算分录
+	mux.HandleFunc(API_BASE_URL+"/admin/netting/cycles", h.getNettingCycles)        // 清算所轧差场次
+	mux.HandleFunc(API_BASE_URL+"/admin/netting/cycles/", h.getNettingCycle)        // 场次报告
+	mux.HandleFunc(API_BASE_URL+"/admin/netting/close", h.closeNettingCycle)        // 立即截止当前场次
+	mux.HandleFunc(API_BASE_URL+"/admin/tax/withholding", h.getWithholdingTax)      // 利息代扣税汇总
+	mux.HandleFunc(API_BASE_URL+"/admin/calendar/holidays", h.handleHolidays)       // 节假日查询/新增/删除
+	mux.HandleFunc(API_BASE_URL+"/admin/gl/balances", h.getGLBalances)              // 总账科目余额
+	mux.HandleFunc(API_BASE_URL+"/admin/ws/metrics", h.getWsMetrics)                // WebSocket 推送统计
+	mux.HandleFunc(API_BASE_URL+"/admin/ws/connections", h.getWsConnections)        // WebSocket 在线连接
+	mux.HandleFunc(API_BASE_URL+"/admin/stats", h.getAdminStats)                    // 运营统计
+	mux.HandleFunc(API_BASE_URL+"/admin/leader", h.getLeaderStatus)                 // 多实例选主状态
+	mux.HandleFunc(API_BASE_URL+"/admin/breakers", h.getBreakers)                   // 模拟外部系统熔断器状态
+	mux.HandleFunc(API_BASE_URL+"/admin/breakers/", h.handleBreaker)                // 强制熔断/恢复熔断器
+	mux.HandleFunc(API_BASE_URL+"/admin/accounts", h.getAdminAccounts)              // 账户查询
+	mux.HandleFunc(API_BASE_URL+"/admin/accounts/status", h.handleAccountStatus)    // 冻结/解冻账户
+	mux.HandleFunc(API_BASE_URL+"/admin/accounts/adjust", h.handleAdjustBalance)    // 人工调账
+	mux.HandleFunc(API_BASE_URL+"/admin/accounts/import", h.handleAccountImport)    // 批量导入账户（CSV）
+	mux.HandleFunc(API_BASE_URL+"/admin/sessions", h.getAdminSessions)              // 登录会话查询
+	mux.HandleFunc(API_BASE_URL+"/admin/sessions/control", h.handleSessionControl)  // 会话控制（强制下线等）
+	mux.HandleFunc(API_BASE_URL+"/admin/login/locks", h.getLoginLocks)              // 登录失败计数与锁定
+	mux.HandleFunc(API_BASE_URL+"/admin/login/unlock", h.handleLoginUnlock)         // 解除登录锁定
+	mux.HandleFunc(API_BASE_URL+"/admin/audit", h.getAuditLog)                      // 审计日志
+	mux.HandleFunc(API_BASE_URL+"/admin/legal-holds", h.handleLegalHolds)           // 司法冻结查询/设置
+	mux.HandleFunc(API_BASE_URL+"/admin/legal-holds/", h.handleLegalHoldRelease)    // 司法冻结部分/全部解除
+	mux.HandleFunc(API_BASE_URL+"/admin/suspense", h.getSuspenseItems)              // 待处理挂账工作队列
+	mux.HandleFunc(API_BASE_URL+"/admin/suspense/", h.handleSuspenseItem)           // 挂账查明入账/退回汇出行
+	mux.HandleFunc(API_BASE_URL+"/admin/broadcast", h.handleBroadcast)              // 广播公告查询/发布
+	mux.HandleFunc(API_BASE_URL+"/admin/broadcast/", h.handleBroadcastAction)       // 取消待发布公告
+	mux.HandleFunc(API_BASE_URL+"/admin/maintenance", h.handleMaintenance)          // 维护窗口查询/开启/预约
+	mux.HandleFunc(API_BASE_URL+"/admin/maintenance/", h.handleMaintenanceAction)   // 结束维护/取消预约
+	mux.HandleFunc(API_BASE_URL+"/maintenance", h.getMaintenanceStatus)             // 维护状态查询
+	mux.HandleFunc(API_BASE_URL+"/admin/flags", h.getFeatureFlags)                  // 功能开关列表
+	mux.HandleFunc(API_BASE_URL+"/admin/sagas", h.getSagas)                         // 事务编排记录列表
+	mux.HandleFunc(API_BASE_URL+"/admin/sagas/", h.handleSaga)                      // 事务编排记录查询/重试补偿
+	mux.HandleFunc(API_BASE_URL+"/admin/flags/", h.handleFeatureFlag)               // 单个功能开关查询/设置/撤销
+	mux.HandleFunc(API_BASE_URL+"/admin/risk/events", h.getRiskEvents)              // 风控事件
+	mux.HandleFunc(API_BASE_URL+"/admin/security/logins", h.getAdminLogins)         // 登录记录（全部账户）
+	mux.HandleFunc(API_BASE_URL+"/admin/products", h.handleProducts)                // 产品定义/参数调整
+	mux.HandleFunc(API_BASE_URL+"/admin/accounts/product", h.handleBindProduct)     // 账户绑定产品
+	mux.HandleFunc(API_BASE_URL+"/admin/promos", h.handlePromos)                    // 营销活动查询/创建
+	mux.HandleFunc(API_BASE_URL+"/admin/promos/", h.handlePromoAction)              // 营销活动修改
+	mux.HandleFunc(API_BASE_URL+"/admin/cashback/config", h.handleCashbackConfig)   // 消费返现配置
+	mux.HandleFunc(API_BASE_URL+"/admin/loyalty/config", h.handleLoyaltyConfig)     // 积分配置
+	mux.HandleFunc(API_BASE_URL+"/admin/collections", h.getCollections)             // 贷款催收看板
+	mux.HandleFunc(API_BASE_URL+"/admin/merchants", h.handleMerchants)              // 收单商户查询/登记
+	mux.HandleFunc(API_BASE_URL+"/admin/templates", h.handleTemplates)              // 消息模板查询/修改
+	mux.HandleFunc(API_BASE_URL+"/admin/templates/reload", h.handleReloadTemplates) // 重新加载模板文件
+	mux.HandleFunc(API_BASE_URL+"/calendar", h.getCalendar)                         // 营业日历/收付款日历查询
+	mux.HandleFunc(API_BASE_URL+"/sim/clock", h.getSimClock)                        // 模拟时钟查询
+	mux.HandleFunc(API_BASE_URL+"/sim/clock/advance", h.handleSimClockAdvance)      // 模拟时钟快进
+	mux.HandleFunc(API_BASE_URL+"/sim/outbox", h.handleOutbox)                      // 模拟邮件发件箱
+	mux.HandleFunc(API_BASE_URL+"/sim/sms", h.handleSMSOutbox)                      // 模拟短信发件箱
+	mux.HandleFunc(API_BASE_URL+"/sim/sms/config", h.handleSMSConfig)               // 短信网关失败率配置
+	mux.HandleFunc(API_BASE_URL+"/sim/seed", h.handleSeed)                          // 批量生成模拟账户
+	mux.HandleFunc(API_BASE_URL+"/sim/interbank/incoming", h.handleIncomingCredit)  // 模拟他行汇入
+	mux.HandleFunc(API_BASE_URL+"/sim/rtgs/transfer", h.handleRTGSTransfer)         // 模拟他行之间经 RTGS 的支付
+	mux.HandleFunc(API_BASE_URL+"/sim/netting/transfer", h.handleNettingTransfer)   // 模拟他行之间的低额支付
+	mux.HandleFunc(API_BASE_URL+"/sim/chaos", h.handleChaos)                        // 故障注入配置
+	mux.HandleFunc(API_BASE_URL+"/sim/chaos/storm", h.handleChaosStorm)             // 立即触发断线风暴
+
+	// 状态快照路由
+	mux.HandleFunc(API_BASE_URL+"/admin/snapshots", h.handleSnapshots)       // 快照列表/创建快照
+	mux.HandleFunc(API_BASE_URL+"/admin/snapshots/", h.handleSnapshotAction) // 快照下载/导入/删除/恢复
+
+	// 柜员业务路由
+	mux.HandleFunc(API_BASE_URL+"/teller/branches", h.getBranches)           // 网点及柜员列表
+	mux.HandleFunc(API_BASE_URL+"/teller/drawer/open", h.handleDrawerOpen)   // 柜员开箱
+	mux.HandleFunc(API_BASE_URL+"/teller/drawer/close", h.handleDrawerClose) // 柜员封箱轧账
+	mux.HandleFunc(API_BASE_URL+"/teller/deposit", h.handleTellerDeposit)    // 柜员代客存款
+	mux.HandleFunc(API_BASE_URL+"/teller/withdraw", h.handleTellerWithdraw)  // 柜员代客取款
+	mux.HandleFunc(API_BASE_URL+"/teller/report", h.getShiftReport)          // 班次轧账报告
+
+	// 跨行转账路由
+	mux.HandleFunc(API_BASE_URL+"/interbank/banks", h.getExternalBanks)           // 他行列表
+	mux.HandleFunc(API_BASE_URL+"/interbank/transfer", h.handleInterbankTransfer) // 跨行转账
+	mux.HandleFunc(API_BASE_URL+"/interbank/payment", h.getInterbankPayment)      // 支付状态查询
+
+	// ISO 20022 批量支付路由
+	mux.HandleFunc(API_BASE_URL+"/payments/pain001", h.handlePain001Import) // pain.001 批量支付导入
+	mux.HandleFunc(API_BASE_URL+"/payments/pain002", h.getPain002Report)    // pain.002 状态报告查询
+
+	// 外汇路由
+	mux.HandleFunc(API_BASE_URL+"/fx/rates", h.getFXRates)            // 汇率牌价
+	mux.HandleFunc(API_BASE_URL+"/admin/fx/rates", h.handleSetFXRate) // 设置汇率（管理员）
+
+	// WebSocket 路由
+	mux.Handle(WS_PATH, h.hub)
+}
+
+// -------------------------- 工具函数 --------------------------
+
+// 发送统一格式响应（内容协商为 XML 时以 XML 输出）
+func (h *Handler) sendResponse(w http.ResponseWriter, code int, message string, data interface{}) {
+	xw, isXML := w.(*xmlResponseWriter)
+	if isXML {
+		w.Header().Set("Content-Type", xw.mime+"; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	// 默认所有响应都返回 200，业务错误通过 code 区分；开启严格 HTTP 状态码后按业务码映射
+	status := http.StatusOK
+	if h.flags != nil && h.flags.Enabled(service.FLAG_STRICT_HTTP_STATUS) {
+		status = model.HTTPStatus(code)
+	}
+	w.WriteHeader(status)
+
+	responseCodes.Lock()
+	responseCodes.counts[code]++
+	responseCodes.Unlock()
+
+	response := Response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+
+	if isXML {
+		if err := writeXMLResponse(w, response); err != nil {
+			log.Printf("响应发送失败: %v", err)
+		}
+		return
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("响应发送失败: %v", err)
+	}
+}
+
+// 发送业务错误响应（参数校验失败时 Data 为逐字段错误列表）
+func (h *Handler) sendError(w http.ResponseWriter, err error) {
+	code, message := model.ErrorCode(err)
+	if ve, ok := err.(*model.ValidationError); ok {
+		h.sendResponse(w, code, message, ve.Fields)
+		return
+	}
+	h.sendResponse(w, code, message, nil)
+}
+
+// 非成功业务码的出现次数（按次数倒序）
+func errorCodeCounts() []CodeCount {
+	responseCodes.Lock()
+	defer responseCodes.Unlock()
+
+	result := make([]CodeCount, 0, len(responseCodes.counts))
+	for code, count := range responseCodes.counts {
+		if code != model.CODE_SUCCESS {
+			result = append(result, CodeCount{Code: code, Count: count})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// 跨域中间件：允许前端页面从其他端口/文件直接访问接口，并处理预检请求
+func WithCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			header := w.Header()
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match, X-Session-Token, X-Device-Id")
+			header.Set("Access-Control-Expose-Headers", "Content-Length, ETag")
+			header.Set("Access-Control-Max-Age", "43200")
+			header.Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
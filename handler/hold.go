@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 资金冻结接口实现 --------------------------
+
+// 冻结列表与设置：GET 查询账户冻结汇总（可按 status 过滤），POST 设置冻结
+func (h *Handler) handleHolds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		accountID := query.Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		summary, err := h.holds.List(accountID, query.Get("status"))
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取冻结信息成功", summary)
+
+	case http.MethodPost:
+		var req service.HoldRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		hold, err := h.holds.Place(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "冻结成功", hold)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单笔冻结：GET /holds/{id} 查询，PUT 修改金额/原因/到期时间，DELETE 解除冻结
+func (h *Handler) handleHoldAction(w http.ResponseWriter, r *http.Request) {
+	holdID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/holds/")
+	if holdID == "" || strings.Contains(holdID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	var (
+		hold    service.Hold
+		err     error
+		message string
+	)
+	switch r.Method {
+	case http.MethodGet:
+		hold, err = h.holds.Get(holdID)
+		message = "获取冻结信息成功"
+	case http.MethodPut:
+		var req service.HoldUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		hold, err = h.holds.Modify(holdID, req)
+		message = "冻结已修改"
+	case http.MethodDelete:
+		hold, err = h.holds.Release(holdID)
+		message = "冻结已解除"
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, hold)
+}
+
+// 司法冻结（管理员）：GET 查询（可按 accountId、status 过滤），POST 设置司法冻结
+func (h *Handler) handleLegalHolds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		h.sendResponse(w, model.CODE_SUCCESS, "获取司法冻结成功", h.holds.ListLegal(query.Get("accountId"), query.Get("status")))
+
+	case http.MethodPost:
+		var req service.LegalHoldRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		hold, err := h.holds.PlaceLegal(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "司法冻结成功", hold)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 解除司法冻结（管理员）：POST /admin/legal-holds/{id}/release，amount 为 0 表示全部解除
+func (h *Handler) handleLegalHoldRelease(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/legal-holds/")
+	holdID := strings.TrimSuffix(path, "/release")
+	if holdID == "" || holdID == path || strings.Contains(holdID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.LegalHoldReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	hold, err := h.holds.ReleaseLegal(holdID, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := "司法冻结已部分解除"
+	if hold.Status != "active" {
+		message = "司法冻结已解除"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, hold)
+}
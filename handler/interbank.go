@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 跨行转账接口实现 --------------------------
+
+// 查询可汇入的他行列表
+func (h *Handler) getExternalBanks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取他行列表成功", h.interbank.Banks())
+}
+
+// 处理跨行转账请求（立即扣款，进入清算队列）
+func (h *Handler) handleInterbankTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.InterbankTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	payment, err := h.interbank.Transfer(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := "跨行转账已受理"
+	if h.interbank.NetworkDown() {
+		message = "跨行转账已受理，清算网络暂不可用，恢复后自动提交"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, payment)
+}
+
+// 按流水号查询跨行支付状态
+func (h *Handler) getInterbankPayment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	reference := r.URL.Query().Get("reference")
+	if reference == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "流水号不能为空", nil)
+		return
+	}
+
+	payment, ok := h.interbank.Payment(reference)
+	if !ok {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "支付指令不存在", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取支付状态成功", payment)
+}
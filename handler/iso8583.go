@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// ISO 8583 字段格式
+const (
+	iso8583Fixed  = iota // 定长
+	iso8583LLVar         // 两位长度前缀变长
+	iso8583LLLVar        // 三位长度前缀变长
+)
+
+// ISO 8583 字段定义
+type iso8583FieldSpec struct {
+	Format int
+	Length int // 定长字段长度或变长字段最大长度
+}
+
+// ISO 8583 报文（ASCII 编码，MTI + 位图 + 字段）
+type iso8583Message struct {
+	MTI    string
+	Fields map[int]string
+}
+
+var (
+	// 支持的字段（模拟器所需的子集）
+	iso8583Fields = map[int]iso8583FieldSpec{
+		2:   {iso8583LLVar, 19},   // 主账号 PAN
+		3:   {iso8583Fixed, 6},    // 交易处理码
+		4:   {iso8583Fixed, 12},   // 交易金额（分）
+		7:   {iso8583Fixed, 10},   // 传输日期时间 MMDDhhmmss
+		11:  {iso8583Fixed, 6},    // 系统跟踪号 STAN
+		12:  {iso8583Fixed, 6},    // 本地交易时间 hhmmss
+		13:  {iso8583Fixed, 4},    // 本地交易日期 MMDD
+		37:  {iso8583Fixed, 12},   // 检索参考号 RRN
+		38:  {iso8583Fixed, 6},    // 授权码
+		39:  {iso8583Fixed, 2},    // 应答码
+		41:  {iso8583Fixed, 8},    // 终端号
+		42:  {iso8583Fixed, 15},   // 商户号
+		49:  {iso8583Fixed, 3},    // 交易币种
+		70:  {iso8583Fixed, 3},    // 网络管理信息码
+		102: {iso8583LLVar, 28},   // 账户标识
+		120: {iso8583LLLVar, 999}, // 附加信息
+	}
+
+	// 应答时原样回送的字段
+	iso8583EchoFields = []int{2, 3, 4, 7, 11, 12, 13, 37, 41, 42, 49, 70, 102}
+)
+
+// -------------------------- ISO 8583 编解码 --------------------------
+
+// 解析 ISO 8583 报文
+func parseISO8583(data []byte) (*iso8583Message, error) {
+	if len(data) < 20 {
+		return nil, errors.New("报文长度不足")
+	}
+
+	msg := &iso8583Message{MTI: string(data[:4]), Fields: make(map[int]string)}
+	pos := 4
+
+	bitmap, err := strconv.ParseUint(string(data[pos:pos+16]), 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("主位图格式错误: %v", err)
+	}
+	pos += 16
+
+	var secondary uint64
+	if bitmap&(1<<63) != 0 {
+		if len(data) < pos+16 {
+			return nil, errors.New("缺少次位图")
+		}
+		secondary, err = strconv.ParseUint(string(data[pos:pos+16]), 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("次位图格式错误: %v", err)
+		}
+		pos += 16
+	}
+
+	for field := 2; field <= 128; field++ {
+		var present bool
+		if field <= 64 {
+			present = bitmap&(1<<uint(64-field)) != 0
+		} else {
+			present = secondary&(1<<uint(128-field)) != 0
+		}
+		if !present {
+			continue
+		}
+
+		spec, ok := iso8583Fields[field]
+		if !ok {
+			return nil, fmt.Errorf("不支持的字段: %d", field)
+		}
+
+		length := spec.Length
+		if spec.Format != iso8583Fixed {
+			prefix := 2
+			if spec.Format == iso8583LLLVar {
+				prefix = 3
+			}
+			if len(data) < pos+prefix {
+				return nil, fmt.Errorf("字段 %d 长度前缀缺失", field)
+			}
+			length, err = strconv.Atoi(string(data[pos : pos+prefix]))
+			if err != nil || length > spec.Length {
+				return nil, fmt.Errorf("字段 %d 长度错误", field)
+			}
+			pos += prefix
+		}
+
+		if len(data) < pos+length {
+			return nil, fmt.Errorf("字段 %d 数据不完整", field)
+		}
+		msg.Fields[field] = string(data[pos : pos+length])
+		pos += length
+	}
+	return msg, nil
+}
+
+// 组装 ISO 8583 报文
+func (m *iso8583Message) pack() ([]byte, error) {
+	var bitmap, secondary uint64
+	fields := make([]int, 0, len(m.Fields))
+	for field := range m.Fields {
+		fields = append(fields, field)
+	}
+	sort.Ints(fields)
+
+	var body strings.Builder
+	for _, field := range fields {
+		spec, ok := iso8583Fields[field]
+		if !ok {
+			return nil, fmt.Errorf("不支持的字段: %d", field)
+		}
+		value := m.Fields[field]
+		switch spec.Format {
+		case iso8583Fixed:
+			if len(value) != spec.Length {
+				return nil, fmt.Errorf("字段 %d 长度应为 %d", field, spec.Length)
+			}
+			body.WriteString(value)
+		case iso8583LLVar:
+			body.WriteString(fmt.Sprintf("%02d%s", len(value), value))
+		case iso8583LLLVar:
+			body.WriteString(fmt.Sprintf("%03d%s", len(value), value))
+		}
+
+		if field <= 64 {
+			bitmap |= 1 << uint(64-field)
+		} else {
+			secondary |= 1 << uint(128-field)
+		}
+	}
+
+	header := m.MTI
+	if secondary != 0 {
+		bitmap |= 1 << 63
+		header += fmt.Sprintf("%016X%016X", bitmap, secondary)
+	} else {
+		header += fmt.Sprintf("%016X", bitmap)
+	}
+	return []byte(header + body.String()), nil
+}
+
+// -------------------------- ISO 8583 监听服务 --------------------------
+
+// 启动 ISO 8583 TCP 监听（报文前带 2 字节大端长度头）
+func (h *Handler) RunISO8583Listener(addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Printf("ISO 8583 监听启动失败: %v", err)
+		return
+	}
+
+	log.Println("\n[💳 ISO 8583 接口]")
+	log.Printf("监听地址: %s", addr)
+	for pan, accountID := range h.cards.Cards() {
+		log.Printf("测试卡号: %s → 账户ID: %s", pan, accountID)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("ISO 8583 连接接受失败: %v", err)
+			continue
+		}
+		go h.serveISO8583Conn(conn)
+	}
+}
+
+// 处理单个 ISO 8583 连接（同一连接上可连续收发多笔报文）
+func (h *Handler) serveISO8583Conn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		var length uint16
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			if err != io.EOF {
+				log.Printf("ISO 8583 读取失败（客户端: %s）: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			log.Printf("ISO 8583 读取失败（客户端: %s）: %v", conn.RemoteAddr(), err)
+			return
+		}
+
+		request, err := parseISO8583(data)
+		if err != nil {
+			log.Printf("ISO 8583 报文解析失败（客户端: %s）: %v", conn.RemoteAddr(), err)
+			return
+		}
+
+		response, err := h.handleISO8583Message(request).pack()
+		if err != nil {
+			log.Printf("ISO 8583 应答组装失败: %v", err)
+			return
+		}
+
+		frame := make([]byte, 2, 2+len(response))
+		binary.BigEndian.PutUint16(frame, uint16(len(response)))
+		if _, err := conn.Write(append(frame, response...)); err != nil {
+			log.Printf("ISO 8583 应答发送失败（客户端: %s）: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// 根据请求报文类型分发处理，返回应答报文
+func (h *Handler) handleISO8583Message(request *iso8583Message) *iso8583Message {
+	response := &iso8583Message{
+		MTI:    iso8583ResponseMTI(request.MTI),
+		Fields: make(map[int]string),
+	}
+	for _, field := range iso8583EchoFields {
+		if value, ok := request.Fields[field]; ok {
+			response.Fields[field] = value
+		}
+	}
+
+	var authCode, code string
+	switch {
+	case (request.MTI == "0100" || request.MTI == "0200") && h.maintenance.Check() != nil:
+		code = "91" // 系统维护中（发卡行不可用）
+	case request.MTI == "0100":
+		authCode, code = h.cards.Authorize(cardRequest(request))
+	case request.MTI == "0200":
+		authCode, code = h.cards.Purchase(cardRequest(request))
+	case request.MTI == "0800":
+		code = "00" // 网络管理（签到/回响测试）
+	default:
+		code = "12" // 无效交易
+	}
+	if authCode != "" {
+		response.Fields[38] = authCode
+	}
+	response.Fields[39] = code
+
+	// 终端提示：ISO 8583 报文
+	log.Println("\n[💳 ISO 8583 报文]")
+	log.Printf("处理时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("报文类型: %s → %s", request.MTI, response.MTI)
+	log.Printf("卡号: %s | 检索参考号: %s", maskPAN(request.Fields[2]), request.Fields[37])
+	if code == "00" {
+		log.Printf("应答码: \033[1;32m%s\033[0m", code) // 绿色高亮
+	} else {
+		log.Printf("应答码: \033[1;31m%s\033[0m", code) // 红色高亮
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+	return response
+}
+
+// 将卡交易报文字段转换为业务请求
+func cardRequest(request *iso8583Message) service.CardRequest {
+	return service.CardRequest{
+		PAN:       request.Fields[2],
+		Amount:    request.Fields[4],
+		RRN:       request.Fields[37],
+		Terminal:  request.Fields[41],
+		Merchant:  request.Fields[42],
+		Currency:  request.Fields[49],
+		AccountID: request.Fields[102],
+	}
+}
+
+// 应答报文类型（请求 MTI 第三位加一）
+func iso8583ResponseMTI(mti string) string {
+	if len(mti) != 4 {
+		return "0810"
+	}
+	return mti[:2] + string(mti[2]+1) + mti[3:]
+}
+
+// 卡号脱敏
+func maskPAN(pan string) string {
+	if len(pan) < 10 {
+		return pan
+	}
+	return pan[:6] + strings.Repeat("*", len(pan)-10) + pan[len(pan)-4:]
+}
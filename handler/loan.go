@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 信用评分与贷款接口实现 --------------------------
+
+// 查询账户信用评分（含评分因子、历史评分与贷款审批规则）
+func (h *Handler) getCreditScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	score, err := h.credit.Score(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取信用评分成功", map[string]interface{}{
+		"score":     score,
+		"loanRules": h.credit.Rules(),
+	})
+}
+
+// 贷款列表与申请：GET 查询账户贷款，POST 申请贷款（信用评分自动审批）
+func (h *Handler) handleLoans(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取贷款列表成功", h.loans.List(accountID))
+
+	case http.MethodPost:
+		var req service.LoanApplication
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		loan, err := h.loans.Apply(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "贷款审批通过，已放款", loan)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单笔贷款：GET /loans/{id} 查询贷款及还款计划，GET /loans/{id}/payoff-quote 提前还款试算，
+// POST /loans/{id}/repay 提前还款（部分或全额）
+func (h *Handler) handleLoanAction(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/loans/")
+	loanID, action, _ := strings.Cut(path, "/")
+	if loanID == "" {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		loan, err := h.loans.Get(loanID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取贷款信息成功", loan)
+
+	case "payoff-quote":
+		if r.Method != http.MethodGet {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		quote, err := h.loans.PayoffQuote(loanID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "提前还款试算成功", quote)
+
+	case "repay":
+		if r.Method != http.MethodPost {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		var req service.LoanRepayRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		result, err := h.loans.Repay(loanID, req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		message := "提前还款成功"
+		if result.PaidOff {
+			message = "提前还款成功，贷款已结清"
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, message, result)
+
+	default:
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+	}
+}
+
+// 贷款催收看板：逾期贷款、各逾期档位笔数与最近的催收事件
+func (h *Handler) getCollections(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取催收看板成功", h.loans.Collections())
+}
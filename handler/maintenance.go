@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 维护期间仍可调用的接口（管理、模拟控制与登录），其余非查询请求视为交易类接口
+var maintenanceExemptPrefixes = []string{
+	API_BASE_URL + "/admin/",
+	API_BASE_URL + "/sim/",
+	API_BASE_URL + "/auth/",
+	API_BASE_URL + "/maintenance",
+}
+
+// 维护模式中间件：维护窗口内交易类接口（非 GET 请求）直接返回维护错误，查询类接口不受影响
+func (h *Handler) WithMaintenance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.maintenance == nil || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions ||
+			!strings.HasPrefix(r.URL.Path, API_BASE_URL+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range maintenanceExemptPrefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		if err := h.maintenance.Check(); err != nil {
+			h.sendError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 查询维护状态（是否维护中及待开始的维护窗口）
+func (h *Handler) getMaintenanceStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取维护状态成功", h.maintenance.Status())
+}
+
+// 维护窗口管理：GET 查询窗口列表（?status= 按状态过滤），POST 立即开启或预约维护窗口
+func (h *Handler) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取维护窗口成功", h.maintenance.List(r.URL.Query().Get("status")))
+	case http.MethodPost:
+		var req service.MaintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		window, err := h.maintenance.Schedule(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		message := "维护窗口已预约"
+		if window.Status == service.MAINTENANCE_ACTIVE {
+			message = "系统已进入维护"
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, message, window)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 结束维护或取消预约：DELETE /api/admin/maintenance/{windowId}
+func (h *Handler) handleMaintenanceAction(w http.ResponseWriter, r *http.Request) {
+	windowID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/maintenance/")
+	if windowID == "" || strings.Contains(windowID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	window, err := h.maintenance.End(windowID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := "维护已结束"
+	if window.Status == service.MAINTENANCE_CANCELLED {
+		message = "维护窗口已取消"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, window)
+}
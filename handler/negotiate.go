@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 统一响应格式支持的媒体类型
+const (
+	MIME_JSON     = "application/json"
+	MIME_XML      = "application/xml"
+	MIME_TEXT_XML = "text/xml"
+)
+
+// 协商为 XML 的响应：统一响应格式改以 XML 输出（文件导出等自行设置 Content-Type 的接口不受影响）
+type xmlResponseWriter struct {
+	http.ResponseWriter
+	mime string // application/xml 或 text/xml，与请求的 Accept 保持一致
+}
+
+// 供 http.ResponseController 访问底层写入器
+func (xw *xmlResponseWriter) Unwrap() http.ResponseWriter {
+	return xw.ResponseWriter
+}
+
+// 内容协商中间件：按 Accept 请求头选择统一响应格式，优先接受 XML 时以 XML 返回，否则返回 JSON
+func WithContentNegotiation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, API_BASE_URL+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept")
+		if mime := negotiateMIME(r.Header.Get("Accept")); mime != MIME_JSON {
+			w = &xmlResponseWriter{ResponseWriter: w, mime: mime}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 按 Accept 请求头（含 q 权重）选择响应媒体类型：权重相同时取先列出的，未指定或无法满足时返回 JSON
+func negotiateMIME(accept string) string {
+	best, bestQ := MIME_JSON, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.TrimSpace(name) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		var candidate string
+		switch mediaType {
+		case MIME_JSON, "application/*", "*/*":
+			candidate = MIME_JSON
+		case MIME_XML, MIME_TEXT_XML:
+			candidate = mediaType
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = candidate, q
+		}
+	}
+	return best
+}
+
+// 以 XML 写出统一响应格式：<response><code/><message/><data/></response>。
+// 数据先按 JSON 序列化规则（字段名、omitempty）转换，对象字段按原顺序输出为子元素，数组元素输出为 <item>，
+// 不是合法 XML 元素名的键（如以数字开头）输出为 <entry key="...">，null 输出为 nil="true" 的空元素
+func writeXMLResponse(w io.Writer, response Response) error {
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	if err := jsonToXML(dec, enc, "response"); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+// 将 JSON 值逐个记号转换为名为 name 的 XML 元素
+func jsonToXML(dec *json.Decoder, enc *xml.Encoder, name string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	start := xmlElement(name)
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for dec.More() {
+			child := "item"
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+			if err := jsonToXML(dec, enc, child); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil { // 结束符 } 或 ]
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	case nil:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+		return enc.EncodeElement("", start)
+	default: // string/json.Number/bool
+		return enc.EncodeElement(fmt.Sprint(t), start)
+	}
+}
+
+// 键对应的 XML 元素
+func xmlElement(key string) xml.StartElement {
+	if validXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}}}
+}
+
+// 是否为合法的 XML 元素名（不含命名空间前缀，且不以 xml 开头）
+func validXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case unicode.IsLetter(c) || c == '_':
+		case i > 0 && (unicode.IsDigit(c) || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// -------------------------- 通知中心接口实现 --------------------------
+
+// 查询账户通知及未读数（unreadOnly=true 时只返回未读）；带 cursor 或 limit 参数时按游标分页返回
+func (h *Handler) getNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	if _, err := h.accounts.Get(accountID); err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	unreadOnly := query.Get("unreadOnly") == "true"
+	if query.Has("cursor") || query.Has("limit") {
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		page, err := h.notifications.Page(accountID, unreadOnly, query.Get("cursor"), limit)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取通知成功", page)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取通知成功", h.notifications.List(accountID, unreadOnly))
+}
+
+// 标记通知已读：POST /api/notifications/{id}/read
+func (h *Handler) handleNotificationAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/notifications/")
+	notificationID, action, found := strings.Cut(path, "/")
+	if !found || notificationID == "" || action != "read" {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	notification, err := h.notifications.MarkRead(accountID, notificationID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "已标记为已读", notification)
+}
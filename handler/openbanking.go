@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 同意书管理接口 --------------------------
+
+// 第三方创建同意书（待客户授权）
+func (h *Handler) handleCreateConsent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.ConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	consent, err := h.openBanking.CreateConsent(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "同意书已创建，等待客户授权", consent)
+}
+
+// 客户授权或拒绝同意书，授权通过后签发访问令牌
+func (h *Handler) handleAuthorizeConsent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.ConsentAuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	consent, token, err := h.openBanking.AuthorizeConsent(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if token == nil {
+		h.sendResponse(w, model.CODE_SUCCESS, "已拒绝授权", consent)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "授权成功", map[string]interface{}{
+		"consent": consent,
+		"token":   *token,
+	})
+}
+
+// 查询同意书状态
+func (h *Handler) getConsent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	consent, err := h.openBanking.Consent(r.URL.Query().Get("consentId"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取同意书成功", consent)
+}
+
+// 撤销同意书（客户或第三方），同时作废已签发的令牌
+func (h *Handler) handleRevokeConsent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.ConsentAuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	consent, err := h.openBanking.RevokeConsent(req.ConsentID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "同意书已撤销", consent)
+}
+
+// -------------------------- AIS 账户信息接口 --------------------------
+
+// AIS：查询授权账户基本信息
+func (h *Handler) getAISAccounts(w http.ResponseWriter, r *http.Request) {
+	consent, ok := h.authorizeOpenBanking(w, r, http.MethodGet, "accounts", "ReadAccountsBasic")
+	if !ok {
+		return
+	}
+
+	account, err := h.accounts.Get(consent.AccountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取账户信息成功", []map[string]interface{}{{
+		"accountId": account.AccountID,
+		"name":      account.UserName,
+		"currency":  account.Currency,
+		"status":    account.Status,
+	}})
+}
+
+// AIS：查询授权账户余额
+func (h *Handler) getAISBalances(w http.ResponseWriter, r *http.Request) {
+	consent, ok := h.authorizeOpenBanking(w, r, http.MethodGet, "accounts", "ReadBalances")
+	if !ok {
+		return
+	}
+
+	account, err := h.accounts.Get(consent.AccountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取余额成功", map[string]interface{}{
+		"accountId": account.AccountID,
+		"balance":   account.Balance,
+		"currency":  account.Currency,
+		"asOf":      h.clock.Now().Format(time.RFC3339),
+	})
+}
+
+// AIS：查询授权账户交易流水
+func (h *Handler) getAISTransactions(w http.ResponseWriter, r *http.Request) {
+	consent, ok := h.authorizeOpenBanking(w, r, http.MethodGet, "accounts", "ReadTransactions")
+	if !ok {
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取交易流水成功",
+		h.ledger.Transactions(consent.AccountID, time.Time{}, time.Time{}))
+}
+
+// -------------------------- PIS 支付发起接口 --------------------------
+
+// PIS：按同意书执行支付（每份同意书只能执行一次）
+func (h *Handler) handlePISPayment(w http.ResponseWriter, r *http.Request) {
+	consent, ok := h.authorizeOpenBanking(w, r, http.MethodPost, "payments", "")
+	if !ok {
+		return
+	}
+
+	payment, err := h.openBanking.ExecutePayment(consent.ConsentID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, payment.Code, payment.Message, payment)
+}
+
+// PIS：查询支付状态
+func (h *Handler) getPISPayment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	payment, ok := h.openBanking.Payment(r.URL.Query().Get("paymentId"))
+	if !ok {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "支付记录不存在", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取支付状态成功", payment)
+}
+
+// 校验请求方法与 Bearer 令牌，失败时直接写回响应
+func (h *Handler) authorizeOpenBanking(w http.ResponseWriter, r *http.Request, method, scope, permission string) (service.Consent, bool) {
+	if r.Method != method {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return service.Consent{}, false
+	}
+
+	raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	consent, err := h.openBanking.Authenticate(raw, scope, permission)
+	if err != nil {
+		h.sendError(w, err)
+		return service.Consent{}, false
+	}
+	return consent, true
+}
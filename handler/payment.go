@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 批量支付接口实现 --------------------------
+
+// 上传 pain.001 批量支付文件，逐笔执行并返回 pain.002 状态报告
+func (h *Handler) handlePain001Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	// 支持 multipart 文件上传（字段名 file）或直接提交 XML 请求体
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "未找到上传文件（字段名 file）", nil)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	var doc service.Pain001Document
+	if err := xml.NewDecoder(io.LimitReader(body, 10<<20)).Decode(&doc); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "pain.001 报文解析失败: "+err.Error(), nil)
+		return
+	}
+
+	output, err := h.payments.Import(&doc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
+
+// 按原 pain.001 MsgId 查询 pain.002 状态报告
+func (h *Handler) getPain002Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	msgID := r.URL.Query().Get("msgId")
+	if msgID == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "MsgId 不能为空", nil)
+		return
+	}
+
+	output, ok := h.payments.Report(msgID)
+	if !ok {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "状态报告不存在", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 代发工资接口实现 --------------------------
+
+// 代发批次：GET 查询付款账户的批次列表，POST 提交代发批次
+// （JSON 请求体，或 multipart 上传 CSV 代发文件：字段 file，表单字段 corporateAccount/reference/memo）
+func (h *Handler) handlePayrollBatches(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取代发批次成功", h.payroll.List(accountID))
+
+	case http.MethodPost:
+		var req service.PayrollRequest
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				h.sendResponse(w, model.CODE_PARAM_ERROR, "未找到上传文件（字段名 file）", nil)
+				return
+			}
+			defer file.Close()
+			items, err := service.ParsePayrollCSV(file)
+			if err != nil {
+				h.sendError(w, err)
+				return
+			}
+			req = service.PayrollRequest{
+				CorporateAccount: r.FormValue("corporateAccount"),
+				Reference:        r.FormValue("reference"),
+				Memo:             r.FormValue("memo"),
+				Items:            items,
+			}
+		} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+
+		batch, err := h.payroll.Submit(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "代发批次已处理", batch)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 代发批次结果报告：GET /payroll/batches/{id}
+func (h *Handler) getPayrollBatch(w http.ResponseWriter, r *http.Request) {
+	batchID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/payroll/batches/")
+	if batchID == "" || strings.Contains(batchID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	batch, err := h.payroll.Get(batchID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取代发批次成功", batch)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 手动结账请求
+type PeriodCloseRequest struct {
+	Period string `json:"period"` // YYYY-MM
+}
+
+// 已结账期间列表 / 手动结账（管理员）
+func (h *Handler) handlePeriods(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取已结账期间成功", h.periods.List())
+	case http.MethodPost:
+		var req PeriodCloseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		result, err := h.periods.Close(req.Period)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "期间结账完成", result)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 查询期间结账报告（管理员）：/admin/periods/{YYYY-MM}
+func (h *Handler) getPeriodClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	period := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/periods/")
+	if period == "" || strings.Contains(period, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	result, err := h.periods.Get(period)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取结账报告成功", result)
+}
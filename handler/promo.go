@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 开户、营销活动、消费返现与积分接口实现 --------------------------
+
+// 开户（可填写推荐码）
+func (h *Handler) handleOpenAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.OpenAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	result, err := h.onboarding.Open(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "开户成功", result)
+}
+
+// 营销活动管理：GET 查询活动列表，POST 创建活动
+func (h *Handler) handlePromos(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取营销活动成功", h.promos.List())
+	case http.MethodPost:
+		var campaign service.Campaign
+		if err := json.NewDecoder(r.Body).Decode(&campaign); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		campaign, err := h.promos.Create(campaign)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "营销活动已创建", campaign)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 修改营销活动：PUT 暂停/恢复、调整预算与单户上限
+func (h *Handler) handlePromoAction(w http.ResponseWriter, r *http.Request) {
+	campaignID := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/promos/")
+	if campaignID == "" || strings.Contains(campaignID, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodPut {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.CampaignUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	campaign, err := h.promos.Update(campaignID, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "营销活动已修改", campaign)
+}
+
+// 查询账户获得的营销奖励
+func (h *Handler) getPromoRewards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取营销奖励成功", h.promos.Rewards(accountID))
+}
+
+// 查询账户消费返现汇总
+func (h *Handler) getCashbackSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	summary, err := h.cashback.Summary(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取消费返现成功", summary)
+}
+
+// 消费返现配置：GET 查询，POST 调整返现比例、消费起点与月度上限
+func (h *Handler) handleCashbackConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取消费返现配置成功", h.cashback.Config())
+	case http.MethodPost:
+		var req service.CashbackConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		cfg, err := h.cashback.SetConfig(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+
+		// 终端提示：消费返现配置调整
+		log.Println("\n[💳 消费返现配置调整]")
+		log.Printf("返现比例: %.4f", cfg.Rate)
+		log.Printf("单笔起点: %.2f 元，月度上限: %.2f 元", cfg.MinSpend, cfg.MonthlyCap)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		h.sendResponse(w, model.CODE_SUCCESS, "消费返现配置已更新", cfg)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 查询账户积分账本
+func (h *Handler) getLoyaltyAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	result, err := h.loyalty.Account(accountID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取积分成功", result)
+}
+
+// 积分兑换为存款
+func (h *Handler) handleRedeemPoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.RedeemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	result, err := h.loyalty.Redeem(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "积分兑换成功", result)
+}
+
+// 积分配置：GET 查询，POST 调整各交易类型积分比例、兑换比例与最少兑换积分
+func (h *Handler) handleLoyaltyConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取积分配置成功", h.loyalty.Config())
+	case http.MethodPost:
+		var req service.LoyaltyConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		cfg, err := h.loyalty.SetConfig(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+
+		// 终端提示：积分配置调整
+		log.Println("\n[🎯 积分配置调整]")
+		log.Printf("积分比例: %v", cfg.EarnRates)
+		log.Printf("兑换比例: 1 积分 = %.4f 元，最少兑换 %d 积分", cfg.RedeemRate, cfg.MinRedeem)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		h.sendResponse(w, model.CODE_SUCCESS, "积分配置已更新", cfg)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// -------------------------- 监管报表接口实现 --------------------------
+
+// 导出监管报表：GET /admin/reports/{large-cash|daily-cash}?from=&to=&format=json|csv（日期默认为当日）
+func (h *Handler) getRegulatoryReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	report := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/reports/")
+	if report == "" || strings.Contains(report, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	today := sim.StartOfDay(h.clock.Now())
+	from, to := today, today
+	var errFrom, errTo error
+	if value := query.Get("from"); value != "" {
+		from, errFrom = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if value := query.Get("to"); value != "" {
+		to, errTo = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "日期区间错误，from/to 应为 YYYY-MM-DD 且 from 不晚于 to", nil)
+		return
+	}
+
+	result, err := h.regulatory.Report(report, from, to)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	switch query.Get("format") {
+	case "", "json":
+		h.sendResponse(w, model.CODE_SUCCESS, "生成监管报表成功", result)
+
+	case "csv":
+		output, err := h.regulatory.CSV(result)
+		if err != nil {
+			h.sendResponse(w, model.CODE_UNKNOWN_ERROR, "报表导出失败", nil)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+report+"_"+from.Format("20060102")+"_"+to.Format("20060102")+".csv")
+		w.WriteHeader(http.StatusOK)
+		w.Write(output)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的文件格式，可选 json/csv", nil)
+	}
+}
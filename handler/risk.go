@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 请求来源 IP：优先取 X-Forwarded-For 的第一个地址（模拟环境可借此模拟不同地点），否则取连接地址
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// 账户历史地点（含可信地点）
+func (h *Handler) getRiskLocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取历史地点成功", h.risk.Locations(accountID))
+}
+
+// 标记可信地点：按 IP 或地点标识（国家/地区/城市）登记，之后来自该地点的交易不再触发新地点风控
+func (h *Handler) handleTrustLocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.TrustLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	if req.AccountID == "" {
+		req.AccountID = defaultAccountID
+	}
+
+	location, err := h.risk.Trust(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "已标记为可信地点", location)
+}
+
+// 风控事件（管理员）：?accountId= 按账户过滤
+func (h *Handler) getRiskEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取风控事件成功", h.risk.Events(r.URL.Query().Get("accountId")))
+}
+
+// 当前登录账户的登录记录（成功与失败，含来源 IP、地点与设备）：?result=success|failure 按结果过滤
+func (h *Handler) getSecurityLogins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	accountID, err := h.currentAccountID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	query, err := loginAttemptQuery(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	query.AccountID = accountID
+	h.sendResponse(w, model.CODE_SUCCESS, "获取登录记录成功", h.risk.LoginAttempts(query))
+}
+
+// 登录记录（管理员）：?accountId=、?result=success|failure、?ip= 过滤，可据此排查撞库等异常登录
+func (h *Handler) getAdminLogins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	query, err := loginAttemptQuery(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	query.AccountID, query.IP = r.URL.Query().Get("accountId"), r.URL.Query().Get("ip")
+	h.sendResponse(w, model.CODE_SUCCESS, "获取登录记录成功", h.risk.LoginAttempts(query))
+}
+
+// 解析登录记录的结果过滤参数
+func loginAttemptQuery(r *http.Request) (service.LoginAttemptQuery, error) {
+	result := r.URL.Query().Get("result")
+	if result != "" && result != service.LOGIN_RESULT_SUCCESS && result != service.LOGIN_RESULT_FAILURE {
+		return service.LoginAttemptQuery{}, model.NewError(model.CODE_PARAM_ERROR, "result 应为 success 或 failure")
+	}
+	return service.LoginAttemptQuery{Result: result}, nil
+}
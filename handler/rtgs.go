@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 央行 RTGS 清算账户（管理员）：各参与行余额、日间透支限额与排队头寸
+func (h *Handler) getRTGSAccounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取清算账户成功", h.rtgs.Accounts())
+}
+
+// 调整参与行日间透支限额（管理员）：POST /admin/rtgs/accounts/{bic}
+func (h *Handler) handleRTGSAccount(w http.ResponseWriter, r *http.Request) {
+	bic := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/rtgs/accounts/")
+	if bic == "" || strings.Contains(bic, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.RTGSLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	account, err := h.rtgs.SetLimit(bic, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "日间透支限额已调整", account)
+}
+
+// RTGS 排队中的结算指令（管理员，按提交顺序）
+func (h *Handler) getRTGSQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取结算队列成功", h.rtgs.Queue())
+}
+
+// RTGS 已结算分录（管理员）：?date=YYYY-MM-DD，默认全部
+func (h *Handler) getRTGSEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取结算分录成功", h.rtgs.Entries(r.URL.Query().Get("date")))
+}
+
+// 模拟他行之间的跨行支付（经央行 RTGS 结算，付款行头寸不足时排队）
+func (h *Handler) handleRTGSTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.RTGSTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	entry, err := h.rtgs.Transfer(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := "RTGS 已结算"
+	if entry.Status == service.RTGS_QUEUED {
+		message = "付款行头寸不足，指令已排队"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, entry)
+}
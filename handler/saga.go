@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 事务编排记录列表（?status= 按状态过滤，如 stuck 查询待人工处理的记录）
+func (h *Handler) getSagas(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取事务编排记录成功", h.sagas.List(r.URL.Query().Get("status")))
+}
+
+// 事务编排记录：GET /api/admin/sagas/{sagaId} 查询，POST /api/admin/sagas/{sagaId}/retry 重试补偿
+func (h *Handler) handleSaga(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/sagas/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		if r.Method != http.MethodGet {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		saga, err := h.sagas.Get(parts[0])
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取事务编排记录成功", saga)
+	case len(parts) == 2 && parts[0] != "" && parts[1] == "retry":
+		if r.Method != http.MethodPost {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		saga, err := h.sagas.Retry(parts[0])
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		message := "补偿已完成"
+		if saga.Status != service.SAGA_COMPENSATED {
+			message = "补偿仍未完成，请稍后重试"
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, message, saga)
+	default:
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 定时转账：GET 查询账户预约的定时转账（?accountId=），POST 预约单次或周期性转账
+func (h *Handler) handleScheduledTransfers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取定时转账成功", h.scheduled.List(accountID))
+
+	case http.MethodPost:
+		var req service.ScheduledTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		transfer, err := h.scheduled.Create(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "定时转账已预约", transfer)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单笔定时转账：GET /transfer/scheduled/{id} 查询，PUT 修改金额或日期，DELETE 取消，
+// POST /transfer/scheduled/{id}/pause 暂停，POST /transfer/scheduled/{id}/resume 恢复（?accountId= 为转出账户）
+func (h *Handler) handleScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	transferID, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/transfer/scheduled/"), "/")
+	if transferID == "" || (action != "" && action != "pause" && action != "resume") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	var (
+		transfer service.ScheduledTransfer
+		err      error
+		message  string
+	)
+	switch {
+	case action != "" && r.Method == http.MethodPost:
+		if action == "pause" {
+			transfer, err = h.scheduled.Pause(transferID, accountID)
+			message = "定时转账已暂停"
+		} else {
+			transfer, err = h.scheduled.Resume(transferID, accountID)
+			message = "定时转账已恢复"
+		}
+	case action == "" && r.Method == http.MethodGet:
+		transfer, err = h.scheduled.Get(transferID)
+		message = "获取定时转账成功"
+	case action == "" && r.Method == http.MethodPut:
+		var req service.ScheduledTransferAmendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = accountID
+		}
+		transfer, err = h.scheduled.Amend(transferID, req)
+		message = "定时转账已修改"
+	case action == "" && r.Method == http.MethodDelete:
+		transfer, err = h.scheduled.Cancel(transferID, accountID)
+		message = "定时转账已取消"
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, transfer)
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 登录令牌请求头
+const SESSION_HEADER = "X-Session-Token"
+
+// 会话校验中间件：携带登录令牌的请求须为有效会话，否则直接拒绝（未携带令牌的请求不受影响）
+func (h *Handler) WithSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(SESSION_HEADER)
+		if token == "" || r.URL.Path == API_BASE_URL+"/auth/login" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, err := h.sessions.Authenticate(token); err != nil {
+			h.sendError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 当前登录会话：携带登录令牌时为令牌对应的会话，否则为默认账户的匿名会话（模拟环境未强制登录，SessionID 为空）
+func (h *Handler) currentSession(r *http.Request) (service.Session, error) {
+	token := r.Header.Get(SESSION_HEADER)
+	if token == "" {
+		return service.Session{AccountID: defaultAccountID}, nil
+	}
+	return h.sessions.Authenticate(token)
+}
+
+// 当前登录账户：携带登录令牌时为会话所属账户，否则为默认账户
+func (h *Handler) currentAccountID(r *http.Request) (string, error) {
+	session, err := h.currentSession(r)
+	if err != nil {
+		return "", err
+	}
+	return session.AccountID, nil
+}
+
+// 登录（账户 + 登录密码，失败次数过多时锁定），返回登录令牌，后续请求通过 X-Session-Token 请求头或 WebSocket ?token= 携带
+func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.AccountID) == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	device, err := deviceID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	req.RemoteAddr, req.UserAgent, req.DeviceID = clientIP(r), r.UserAgent(), device
+	attempt := service.LoginAttempt{AccountID: req.AccountID, IP: req.RemoteAddr, DeviceID: device, UserAgent: req.UserAgent}
+	session, err := h.sessions.Login(req)
+	if err != nil {
+		attempt.Result, attempt.Reason = service.LOGIN_RESULT_FAILURE, err.Error()
+		h.risk.RecordLogin(attempt)
+		h.sendError(w, err)
+		return
+	}
+	attempt.Result, attempt.SessionID = service.LOGIN_RESULT_SUCCESS, session.SessionID
+	h.risk.RecordLogin(attempt)
+	if device != "" {
+		h.risk.ObserveDevice(req.AccountID, device, session.UserAgent, session.RemoteAddr)
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "登录成功", session)
+}
+
+// 忘记密码：经短信或邮件下发限时重置令牌
+func (h *Handler) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.PasswordForgotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.AccountID) == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	result, err := h.passwords.Forgot(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "若账户存在且已登记所选渠道，重置令牌将经短信或邮件送达", result)
+}
+
+// 重置密码：校验重置令牌后设置新密码，并使账户的全部登录会话失效
+func (h *Handler) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	if err := h.passwords.Reset(req); err != nil {
+		h.sendError(w, err)
+		return
+	}
+	result := h.sessions.Terminate(req.AccountID, service.SESSION_CMD_FORCE_LOGOUT, "登录密码已重置")
+	h.sendResponse(w, model.CODE_SUCCESS, "密码已重置，请使用新密码登录", map[string]interface{}{
+		"accountId":       req.AccountID,
+		"revokedSessions": result.Sessions,
+	})
+}
+
+// 当前会话：GET 查询，DELETE 退出登录
+func (h *Handler) handleSession(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get(SESSION_HEADER)
+	if token == "" {
+		h.sendResponse(w, model.CODE_NOT_LOGIN, "未登录", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		session, err := h.sessions.Authenticate(token)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取会话成功", session)
+	case http.MethodDelete:
+		session, err := h.sessions.Logout(token)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "已退出登录", session)
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 查询会话列表（管理员）：?accountId= 按账户过滤
+func (h *Handler) getAdminSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取会话列表成功", h.sessions.List(r.URL.Query().Get("accountId")))
+}
+
+// 会话控制（管理员）：强制下线、要求重新认证或冻结账户，指令推送到该账户的 WebSocket 连接
+func (h *Handler) handleSessionControl(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.SessionControlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	result, err := h.sessions.Control(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "会话控制指令已执行", result)
+}
+
+// 登录失败计数与锁定列表（管理员）：?locked=true 仅返回锁定中的账户与 IP
+func (h *Handler) getLoginLocks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取登录锁定列表成功", h.loginGuard.List(r.URL.Query().Get("locked") == "true"))
+}
+
+// 解除登录锁定（管理员）：按账户和/或 IP 清除失败计数
+func (h *Handler) handleLoginUnlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.LoginUnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	locks, err := h.loginGuard.Unlock(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "已解除登录锁定", locks)
+}
+
+// 当前账户的在线会话：各会话的登录设备、IP 与其上的 WebSocket 连接，以及未关联会话的连接
+func (h *Handler) getSecuritySessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	session, err := h.currentSession(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取在线会话成功", h.sessions.Active(session.AccountID, session.SessionID, h.hub.Connections(session.AccountID)))
+}
+
+// 撤销当前账户的指定会话（DELETE /security/sessions/{sessionId}）：令牌失效并断开其 WebSocket 连接
+func (h *Handler) handleSecuritySession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/security/sessions/")
+	if id == "" || strings.Contains(id, "/") {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "会话编号不能为空", nil)
+		return
+	}
+	accountID, err := h.currentAccountID(r)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	result, err := h.sessions.Revoke(accountID, id)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "会话已下线", result)
+}
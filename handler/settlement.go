@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 清算文件接口实现 --------------------------
+
+// 已生成的清算文件列表（不含明细）
+func (h *Handler) getSettlementFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取清算文件列表成功", h.settlements.Files())
+}
+
+// 下载清算文件：GET /admin/settlement-files/{date}?format=csv|fixed|json
+func (h *Handler) getSettlementFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	date := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/settlement-files/")
+	if date == "" || strings.Contains(date, "/") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	file, ok := h.settlements.File(date)
+	if !ok {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "该日期清算文件尚未生成（需先完成日终）", nil)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "json" {
+		h.sendResponse(w, model.CODE_SUCCESS, "获取清算文件成功", file)
+		return
+	}
+	output, err := h.settlements.Export(file, format)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	contentType, ext := "text/csv; charset=utf-8", ".csv"
+	if format == service.SETTLEMENT_FORMAT_FIXED {
+		contentType, ext = "text/plain; charset=utf-8", ".txt"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+file.FileID+ext)
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 模拟时钟快进请求结构体
+type ClockAdvanceRequest struct {
+	Hours float64 `json:"hours"`
+}
+
+// -------------------------- 模拟时钟接口实现 --------------------------
+
+// 查询模拟时钟
+func (h *Handler) getSimClock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	h.sendResponse(w, model.CODE_SUCCESS, "获取模拟时钟成功", map[string]interface{}{
+		"simTime":  h.clock.Now().Format("2006-01-02 15:04:05"),
+		"realTime": time.Now().Format("2006-01-02 15:04:05"),
+		"speed":    h.clock.Speed(),
+	})
+}
+
+// 快进模拟时钟（跨越日界时由日终调度补跑日终）
+func (h *Handler) handleSimClockAdvance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req ClockAdvanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	if req.Hours <= 0 {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "快进时长必须大于0", nil)
+		return
+	}
+
+	before, after := h.clock.Advance(time.Duration(req.Hours * float64(time.Hour)))
+
+	// 终端提示：模拟时钟快进
+	log.Println("\n[⏩ 模拟时钟快进]")
+	log.Printf("快进时长: %.2f 小时", req.Hours)
+	log.Printf("模拟时间: %s → %s", before.Format("2006-01-02 15:04:05"), after.Format("2006-01-02 15:04:05"))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	h.sendResponse(w, model.CODE_SUCCESS, "模拟时钟已快进", map[string]interface{}{
+		"simTime": after.Format("2006-01-02 15:04:05"),
+	})
+}
+
+// 批量生成模拟账户：余额按指定分布抽取，可附带历史交易
+func (h *Handler) handleSeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.SeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	result, err := h.seeder.Seed(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "模拟数据已生成", result)
+}
+
+// 模拟邮件发件箱：GET 查询（可按 accountId/template 过滤），DELETE 清空
+func (h *Handler) handleOutbox(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		h.sendResponse(w, model.CODE_SUCCESS, "获取发件箱成功", h.emails.Outbox(query.Get("accountId"), query.Get("template")))
+	case http.MethodDelete:
+		h.sendResponse(w, model.CODE_SUCCESS, "发件箱已清空", map[string]interface{}{
+			"cleared": h.emails.ClearOutbox(),
+		})
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 模拟短信发件箱：GET 查询（可按 accountId/type/status 过滤），DELETE 清空
+func (h *Handler) handleSMSOutbox(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		h.sendResponse(w, model.CODE_SUCCESS, "获取短信发件箱成功",
+			h.sms.Messages(query.Get("accountId"), query.Get("type"), query.Get("status")))
+	case http.MethodDelete:
+		h.sendResponse(w, model.CODE_SUCCESS, "短信发件箱已清空", map[string]interface{}{
+			"cleared": h.sms.ClearMessages(),
+		})
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 短信网关配置：GET 查询，POST 调整失败率与最多下发次数
+func (h *Handler) handleSMSConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取短信网关配置成功", smsConfigView(h.sms.Config()))
+	case http.MethodPost:
+		var req service.SMSConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		cfg, err := h.sms.SetConfig(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+
+		// 终端提示：短信网关配置调整
+		log.Println("\n[📱 短信网关配置调整]")
+		log.Printf("失败率: %.2f", cfg.FailureRate)
+		log.Printf("最多下发: %d 次", cfg.MaxAttempts)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		h.sendResponse(w, model.CODE_SUCCESS, "短信网关配置已更新", smsConfigView(cfg))
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 短信网关配置的接口表示
+func smsConfigView(cfg service.SMSConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"failureRate":   cfg.FailureRate,
+		"maxAttempts":   cfg.MaxAttempts,
+		"retryInterval": cfg.RetryInterval.String(),
+	}
+}
+
+// 故障注入中间件：按故障注入配置为接口附加延迟或直接返回服务繁忙（故障注入配置接口本身不受影响）
+func (h *Handler) WithChaos(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.chaos == nil || !strings.HasPrefix(r.URL.Path, API_BASE_URL+"/") || strings.HasPrefix(r.URL.Path, API_BASE_URL+"/sim/chaos") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if delay := h.chaos.Delay(r.URL.Path); delay > 0 {
+			time.Sleep(delay)
+		}
+		if h.chaos.Busy() {
+			h.sendResponse(w, model.CODE_SERVER_BUSY, "服务繁忙，请稍后重试", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// 断线风暴请求结构体
+type ChaosStormRequest struct {
+	Ratio float64 `json:"ratio"` // 断开的连接比例（0~1）
+}
+
+// 故障注入配置：GET 查询配置与统计，POST 调整配置（立即生效）
+func (h *Handler) handleChaos(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取故障注入配置成功", chaosConfigView(h.chaos.Config(), h.chaos.Stats()))
+	case http.MethodPost:
+		var req service.ChaosConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		cfg, err := h.chaos.SetConfig(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+
+		// 终端提示：故障注入配置调整
+		log.Println("\n[🧨 故障注入配置调整]")
+		log.Printf("开启: %t", cfg.Enabled)
+		log.Printf("附加延迟: %s~%s（按接口覆盖 %d 项）", cfg.Latency.Min, cfg.Latency.Max, len(cfg.Endpoints))
+		log.Printf("繁忙概率: %.2f，写入失败概率: %.2f", cfg.ErrorRate, cfg.WriteFailureRate)
+		log.Printf("断线风暴: 每 %s 断开 %.0f%% 连接", cfg.StormInterval, cfg.StormRatio*100)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		h.sendResponse(w, model.CODE_SUCCESS, "故障注入配置已更新", chaosConfigView(cfg, h.chaos.Stats()))
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 立即触发一次 WebSocket 断线风暴（不受总开关限制）
+func (h *Handler) handleChaosStorm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req ChaosStormRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	dropped, err := h.chaos.Storm(req.Ratio)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "断线风暴已触发", map[string]interface{}{
+		"ratio":        req.Ratio,
+		"disconnected": dropped,
+	})
+}
+
+// 故障注入配置的接口表示（延迟以毫秒计）
+func chaosConfigView(cfg service.ChaosConfig, stats service.ChaosStats) map[string]interface{} {
+	endpoints := make(map[string]service.ChaosLatencyRequest, len(cfg.Endpoints))
+	for path, latency := range cfg.Endpoints {
+		endpoints[path] = chaosLatencyView(latency)
+	}
+	return map[string]interface{}{
+		"enabled":          cfg.Enabled,
+		"latency":          chaosLatencyView(cfg.Latency),
+		"endpoints":        endpoints,
+		"errorRate":        cfg.ErrorRate,
+		"writeFailureRate": cfg.WriteFailureRate,
+		"stormIntervalSec": int(cfg.StormInterval / time.Second),
+		"stormRatio":       cfg.StormRatio,
+		"stats":            stats,
+	}
+}
+
+func chaosLatencyView(latency service.ChaosLatency) service.ChaosLatencyRequest {
+	return service.ChaosLatencyRequest{MinMs: int(latency.Min.Milliseconds()), MaxMs: int(latency.Max.Milliseconds())}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 快照文件大小上限（导入时）
+const maxSnapshotSize = 256 << 20
+
+// 状态快照（管理员）：GET 查询快照列表，POST 创建快照
+func (h *Handler) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.snapshots.List()
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取快照列表成功", list)
+
+	case http.MethodPost:
+		var req service.SnapshotRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		info, err := h.snapshots.Create(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "快照已创建", info)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单个快照（管理员）：GET /admin/snapshots/{name} 下载快照文件，PUT 导入快照文件，DELETE 删除，
+// POST .../{name}/restore 回滚到该快照
+func (h *Handler) handleSnapshotAction(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/snapshots/")
+	name, action, _ := strings.Cut(path, "/")
+	if name == "" {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	switch action {
+	case "":
+		switch r.Method {
+		case http.MethodGet:
+			data, err := h.snapshots.Export(name)
+			if err != nil {
+				h.sendError(w, err)
+				return
+			}
+			// 以文件形式下载（统一响应格式使用 application/json，下载内容与之区分）
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", "attachment; filename="+name+".json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+
+		case http.MethodPut:
+			data, err := io.ReadAll(io.LimitReader(r.Body, maxSnapshotSize))
+			if err != nil {
+				h.sendResponse(w, model.CODE_PARAM_ERROR, "快照文件读取失败", nil)
+				return
+			}
+			info, err := h.snapshots.Import(name, data)
+			if err != nil {
+				h.sendError(w, err)
+				return
+			}
+			h.sendResponse(w, model.CODE_SUCCESS, "快照已导入", info)
+
+		case http.MethodDelete:
+			if err := h.snapshots.Delete(name); err != nil {
+				h.sendError(w, err)
+				return
+			}
+			h.sendResponse(w, model.CODE_SUCCESS, "快照已删除", map[string]interface{}{"name": name})
+
+		default:
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		}
+
+	case "restore":
+		if r.Method != http.MethodPost {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		info, err := h.snapshots.Restore(name)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "已恢复到快照", info)
+
+	default:
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+	}
+}
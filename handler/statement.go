@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 手动日终请求结构体
+type EODRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD，为空表示模拟时钟的前一日
+}
+
+// -------------------------- 日终与对账单接口实现 --------------------------
+
+// 手动触发日终（管理员）
+func (h *Handler) handleRunEOD(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req EODRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	day := sim.StartOfDay(h.clock.Now()).AddDate(0, 0, -1)
+	if req.Date != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+		if err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "日期格式错误，应为 YYYY-MM-DD", nil)
+			return
+		}
+		day = parsed
+	}
+
+	h.eod.Run(day)
+	h.sendResponse(w, model.CODE_SUCCESS, "日终处理完成", map[string]interface{}{
+		"date": day.Format("2006-01-02"),
+	})
+}
+
+// 查询账户对账单列表（?period=monthly 查询月度对账单存档）
+func (h *Handler) getStatements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	accountID := r.URL.Query().Get("accountId")
+	if accountID == "" {
+		accountID = defaultAccountID
+	}
+
+	switch r.URL.Query().Get("period") {
+	case "", "daily":
+		h.sendResponse(w, model.CODE_SUCCESS, "获取对账单成功", h.statements.List(accountID))
+	case "monthly":
+		h.sendResponse(w, model.CODE_SUCCESS, "获取月度对账单成功", h.statements.Monthly(accountID))
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "period 应为 daily 或 monthly", nil)
+	}
+}
+
+// 月度对账单订阅：GET 查询，PUT 开通/关闭（开通后每月首个营业日自动生成上月对账单并发送邮件）
+func (h *Handler) handleStatementSub(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		sub, err := h.statements.Subscription(accountID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取月度对账单订阅成功", sub)
+
+	case http.MethodPut:
+		var req service.StatementSubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		sub, err := h.statements.Subscribe(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		message := "月度对账单已关闭"
+		if sub.Enabled {
+			message = "月度对账单已开通，下一期将于 " + sub.NextDelivery + " 发送至 " + sub.Email
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, message, sub)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 导出日终对账单为 camt.053 XML
+func (h *Handler) getCamt053Statement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID, date := query.Get("accountId"), query.Get("date")
+	if accountID == "" || date == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "账户ID和对账日期不能为空", nil)
+		return
+	}
+
+	statement, ok := h.statements.Find(accountID, date)
+	if !ok {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "该日期对账单尚未生成（需先完成日终）", nil)
+		return
+	}
+
+	output, err := xml.MarshalIndent(h.statements.Camt053(statement), "", "  ")
+	if err != nil {
+		h.sendResponse(w, model.CODE_UNKNOWN_ERROR, "camt.053 报文生成失败", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=camt053_"+accountID+"_"+date+".xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append([]byte(xml.Header), output...))
+}
+
+// 导出账户指定日期区间的 MT940 对账单
+func (h *Handler) getMT940Statement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "账户ID不能为空", nil)
+		return
+	}
+
+	from, errFrom := time.ParseInLocation("2006-01-02", query.Get("from"), time.Local)
+	to, errTo := time.ParseInLocation("2006-01-02", query.Get("to"), time.Local)
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "日期区间错误，from/to 应为 YYYY-MM-DD 且 from 不晚于 to", nil)
+		return
+	}
+
+	output, err := h.statements.MT940(accountID, from, to)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=mt940_%s_%s_%s.sta",
+		accountID, from.Format("20060102"), to.Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(output))
+}
+
+// 导出账户指定日期区间的 OFX 交易（GnuCash 等个人理财软件可直接导入）
+func (h *Handler) getOFXStatement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "账户ID不能为空", nil)
+		return
+	}
+
+	from, errFrom := time.ParseInLocation("2006-01-02", query.Get("from"), time.Local)
+	to, errTo := time.ParseInLocation("2006-01-02", query.Get("to"), time.Local)
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "日期区间错误，from/to 应为 YYYY-MM-DD 且 from 不晚于 to", nil)
+		return
+	}
+
+	output, err := h.statements.OFX(accountID, from, to)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ofx; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s_%s_%s.ofx",
+		accountID, from.Format("20060102"), to.Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(output))
+}
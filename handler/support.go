@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 在线客服（客户）：GET 查询会话及消息（客服回复视为已读），POST 发送消息（没有进行中的会话时自动开启）
+func (h *Handler) handleSupportChat(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		conversations, err := h.support.Conversations(accountID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取客服会话成功", conversations)
+
+	case http.MethodPost:
+		var req service.SupportChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		message, err := h.support.Send(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "消息已发送", message)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 客服会话队列（管理员）：?status=open|closed 过滤，进行中且有未读消息的排在前面
+func (h *Handler) getSupportQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && status != service.SUPPORT_CONVERSATION_OPEN && status != service.SUPPORT_CONVERSATION_CLOSED {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "status 应为 open 或 closed", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取客服会话队列成功", h.support.Queue(status))
+}
+
+// 单个客服会话（管理员）：GET /admin/support/conversations/{id} 查询消息（客户消息视为已读），
+// POST .../{id}/reply 回复，POST .../{id}/close 结束会话
+func (h *Handler) handleSupportConversation(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/support/conversations/")
+	conversationID, action, _ := strings.Cut(path, "/")
+	if conversationID == "" {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+
+	switch action {
+	case "":
+		if r.Method != http.MethodGet {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		conversation, err := h.support.Conversation(conversationID)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取客服会话成功", conversation)
+
+	case "reply":
+		if r.Method != http.MethodPost {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		var req service.SupportReplyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		message, err := h.support.Reply(conversationID, req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "回复已发送", message)
+
+	case "close":
+		if r.Method != http.MethodPost {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+			return
+		}
+		var req service.SupportCloseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		conversation, err := h.support.Close(conversationID, req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "客服会话已结束", conversation)
+
+	default:
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+	}
+}
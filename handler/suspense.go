@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 模拟他行汇入：收款账户无法入账时转入待处理挂账
+func (h *Handler) handleIncomingCredit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.IncomingCreditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+	result, err := h.suspense.Receive(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	message := "汇入款项已入账"
+	if result.Status == "suspense" {
+		message = "收款账户无法入账，款项已转入待处理挂账"
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, result)
+}
+
+// 挂账工作队列（管理员）：?status=open/repaired/returned
+func (h *Handler) getSuspenseItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取挂账记录成功", h.suspense.List(r.URL.Query().Get("status")))
+}
+
+// 处理挂账（管理员）：POST /admin/suspense/{id}/repair 查明收款人后入账，/admin/suspense/{id}/return 退回汇出行
+func (h *Handler) handleSuspenseItem(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/admin/suspense/")
+	itemID, action, _ := strings.Cut(path, "/")
+	if itemID == "" || (action != "repair" && action != "return") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var item service.SuspenseItem
+	var err error
+	var message string
+	if action == "repair" {
+		var req service.SuspenseRepairRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		item, err = h.suspense.Repair(itemID, req)
+		message = "挂账款项已入账"
+	} else {
+		var req service.SuspenseReturnRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		item, err = h.suspense.Return(itemID, req)
+		message = "挂账款项已退回汇出行"
+	}
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, item)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// -------------------------- 柜员接口实现 --------------------------
+
+// 查询网点及柜员列表
+func (h *Handler) getBranches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取网点信息成功", h.teller.Branches())
+}
+
+// 柜员开箱（开始班次）
+func (h *Handler) handleDrawerOpen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.DrawerOpenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	drawer, err := h.teller.OpenDrawer(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "开箱成功", drawer)
+}
+
+// 柜员封箱（结束班次并生成轧账报告）
+func (h *Handler) handleDrawerClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.DrawerCloseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	report, err := h.teller.CloseDrawer(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "封箱成功", report)
+}
+
+// 查询柜员班次轧账报告（未封箱时返回实时试算）
+func (h *Handler) getShiftReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	report, err := h.teller.ShiftReport(r.URL.Query().Get("tellerId"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "获取轧账报告成功", report)
+}
+
+// 柜员代客现金存款
+func (h *Handler) handleTellerDeposit(w http.ResponseWriter, r *http.Request) {
+	h.handleTellerCash(w, r, "现金存款", h.teller.Deposit)
+}
+
+// 柜员代客现金取款
+func (h *Handler) handleTellerWithdraw(w http.ResponseWriter, r *http.Request) {
+	h.handleTellerCash(w, r, "现金取款", h.teller.Withdraw)
+}
+
+// 柜员现金业务公共处理
+func (h *Handler) handleTellerCash(w http.ResponseWriter, r *http.Request, opName string,
+	execute func(service.TellerCashRequest) (service.DrawerOperation, error)) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.TellerCashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+		return
+	}
+
+	operation, err := execute(req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, opName+"成功", operation)
+}
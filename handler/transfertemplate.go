@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 转账模板：GET 查询账户模板（?accountId=，按使用次数倒序），POST 创建模板
+func (h *Handler) handleTransferTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		accountID := r.URL.Query().Get("accountId")
+		if accountID == "" {
+			accountID = defaultAccountID
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "获取转账模板成功", h.favorites.List(accountID))
+
+	case http.MethodPost:
+		var req service.TransferTemplateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		if req.AccountID == "" {
+			req.AccountID = defaultAccountID
+		}
+		template, err := h.favorites.Create(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+		h.sendResponse(w, model.CODE_SUCCESS, "转账模板已创建", template)
+
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
+
+// 单个转账模板：GET /transfer/templates/{id} 查询，PUT 修改，DELETE 删除，POST /transfer/templates/{id}/execute 按模板转账
+func (h *Handler) handleTransferTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, API_BASE_URL+"/transfer/templates/"), "/")
+	if templateID == "" || (action != "" && action != "execute") {
+		h.sendResponse(w, model.CODE_RESOURCE_NOT_FOUND, "接口不存在", nil)
+		return
+	}
+	if action == "execute" {
+		h.executeTransferTemplate(w, r, templateID)
+		return
+	}
+
+	var (
+		template service.TransferTemplate
+		err      error
+		message  string
+	)
+	switch r.Method {
+	case http.MethodGet:
+		template, err = h.favorites.Get(templateID)
+		message = "获取转账模板成功"
+	case http.MethodPut:
+		var req service.TransferTemplateUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		template, err = h.favorites.Update(templateID, req)
+		message = "转账模板已修改"
+	case http.MethodDelete:
+		template, err = h.favorites.Delete(templateID)
+		message = "转账模板已删除"
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, message, template)
+}
+
+// 按模板转账：请求体可选（金额为空时使用模板金额），风控与普通转账一致，成功后累计模板使用次数
+func (h *Handler) executeTransferTemplate(w http.ResponseWriter, r *http.Request, templateID string) {
+	if r.Method != http.MethodPost {
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+		return
+	}
+
+	var req service.TemplateExecuteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+	}
+	transfer, err := h.favorites.TransferRequest(templateID, req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	data, err := h.transfer(r, transfer)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if template, ok := h.favorites.RecordUse(templateID); ok {
+		data["template"] = template
+	}
+	h.sendResponse(w, model.CODE_SUCCESS, "转账成功", data)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+)
+
+// 资金头寸看板（管理员）：GET 查询流动性头寸与排队中的大额清算，POST 调整准备金率与大额清算门槛
+func (h *Handler) handleTreasury(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.sendResponse(w, model.CODE_SUCCESS, "获取资金头寸成功", h.treasury.Dashboard(h.interbank.Queued()))
+	case http.MethodPost:
+		var req service.TreasuryConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendResponse(w, model.CODE_PARAM_ERROR, "请求参数格式错误", nil)
+			return
+		}
+		cfg, err := h.treasury.SetConfig(req)
+		if err != nil {
+			h.sendError(w, err)
+			return
+		}
+
+		// 终端提示：准备金配置调整
+		log.Println("\n[🏦 准备金配置调整]")
+		log.Printf("准备金率: %.2f%%", cfg.ReserveRatio*100)
+		log.Printf("大额清算门槛: %.2f 元", cfg.QueueThreshold)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		h.sendResponse(w, model.CODE_SUCCESS, "准备金配置已更新", h.treasury.Dashboard(h.interbank.Queued()))
+	default:
+		h.sendResponse(w, model.CODE_PARAM_ERROR, "不支持的请求方法", nil)
+	}
+}
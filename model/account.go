@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// 账户信息结构体
+type Account struct {
+	AccountID     string  `json:"accountId"`
+	CustomerID    string  `json:"customerId,omitempty"` // 所属客户
+	UserName      string  `json:"userName"`
+	Balance       float64 `json:"balance"`               // 账面余额
+	Held          float64 `json:"heldAmount"`            // 冻结（止付）金额
+	Currency      string  `json:"currency"`              // 账户币种（ISO 4217）
+	Type          string  `json:"accountType"`           // 账户类型：savings（储蓄账户，默认）/checking（结算账户）
+	ProductCode   string  `json:"productCode,omitempty"` // 绑定的存款产品，为空时按账户类型使用内置产品
+	Status        string  `json:"status"`                // normal/frozen/dormant（休眠，需激活后方可交易）
+	CreateAt      string  `json:"createAt"`
+	Email         string  `json:"email,omitempty"`  // 通知邮箱
+	Phone         string  `json:"phone,omitempty"`  // 通知手机号
+	Locale        string  `json:"locale,omitempty"` // 消息语言（如 zh-CN/en-US），为空使用默认语言
+	LoyaltyPoints int64   `json:"loyaltyPoints"`    // 积分余额（积分账本独立维护，查询账户时填充）
+	Version       int64   `json:"version"`          // 版本号，每次更新递增（乐观并发控制）
+}
+
+// 可用余额：账面余额扣除冻结金额
+func (a Account) Available() float64 {
+	return RoundAmount(a.Balance - a.Held)
+}
+
+// 序列化时附带可用余额
+func (a Account) MarshalJSON() ([]byte, error) {
+	type account Account
+	return json.Marshal(struct {
+		account
+		AvailableBalance float64 `json:"availableBalance"`
+	}{account(a), a.Available()})
+}
+
+// 金额保留两位小数（四舍五入）
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
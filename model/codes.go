@@ -0,0 +1,83 @@
+package model
+
+// 本行标识
+const (
+	BANK_BIC      = "ZEROCNBJ" // 本行 BIC（ISO 20022 报文使用）
+	BASE_CURRENCY = "CNY"      // 基准币种
+)
+
+// 错误码定义（与前端保持一致）
+const (
+	CODE_SUCCESS                 = 200
+	CODE_PARAM_ERROR             = 1000
+	CODE_NOT_LOGIN               = 1001
+	CODE_ACCOUNT_ERROR           = 1002
+	CODE_NO_PERMISSION           = 1003
+	CODE_RESOURCE_NOT_FOUND      = 1004
+	CODE_SERVER_BUSY             = 1005
+	CODE_UNKNOWN_ERROR           = 1006
+	CODE_VERSION_CONFLICT        = 1007
+	CODE_SYSTEM_MAINTENANCE      = 1008 // 系统维护中，暂停交易类接口
+	CODE_ACCOUNT_NOT_EXIST       = 2000
+	CODE_ACCOUNT_FROZEN          = 2001
+	CODE_BALANCE_NOT_ENOUGH      = 2002
+	CODE_TARGET_ACCOUNT_ABNORMAL = 2003
+	CODE_ACCOUNT_LIMIT           = 2004
+	CODE_RISK_CONTROL_REJECT     = 2005
+	CODE_ACCOUNT_DORMANT         = 2006
+	CODE_LOGIN_LOCKED            = 2007 // 登录失败次数过多，已锁定
+
+	// 柜员业务错误码
+	CODE_DRAWER_STATE_ERROR     = 3000
+	CODE_DRAWER_CASH_NOT_ENOUGH = 3001
+)
+
+// 业务错误（携带业务错误码，由接口层原样返回给前端）
+type BizError struct {
+	Code    int
+	Message string
+}
+
+func (e *BizError) Error() string { return e.Message }
+
+// 构造业务错误
+func NewError(code int, message string) error {
+	return &BizError{Code: code, Message: message}
+}
+
+// 取错误对应的业务码与提示信息（非业务错误按未知错误处理）
+func ErrorCode(err error) (int, string) {
+	if be, ok := err.(*BizError); ok {
+		return be.Code, be.Message
+	}
+	if _, ok := err.(*ValidationError); ok {
+		return CODE_PARAM_ERROR, err.Error()
+	}
+	return CODE_UNKNOWN_ERROR, err.Error()
+}
+
+// 业务码对应的 HTTP 状态码（严格 HTTP 状态码开关开启时使用）
+func HTTPStatus(code int) int {
+	switch code {
+	case CODE_SUCCESS:
+		return 200
+	case CODE_PARAM_ERROR:
+		return 400
+	case CODE_NOT_LOGIN:
+		return 401
+	case CODE_NO_PERMISSION:
+		return 403
+	case CODE_RESOURCE_NOT_FOUND, CODE_ACCOUNT_NOT_EXIST:
+		return 404
+	case CODE_VERSION_CONFLICT:
+		return 409
+	case CODE_LOGIN_LOCKED:
+		return 429
+	case CODE_UNKNOWN_ERROR:
+		return 500
+	case CODE_SERVER_BUSY, CODE_SYSTEM_MAINTENANCE:
+		return 503
+	default:
+		return 422 // 其余业务规则拒绝（余额不足、账户冻结、风控拒绝等）
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+// KYC 等级
+const (
+	KYC_TIER_BASIC    = "basic"    // 基础（仅登记手机号，限额最低）
+	KYC_TIER_STANDARD = "standard" // 标准（已核验身份证件）
+	KYC_TIER_ENHANCED = "enhanced" // 增强（已核验地址与收入证明）
+)
+
+// 联系地址
+type Address struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	Province   string `json:"province,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Country    string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+}
+
+// 客户（一个客户可持有多个账户，联系方式同步到名下账户供通知渠道使用）
+type Customer struct {
+	CustomerID string  `json:"customerId"`
+	Name       string  `json:"name"`
+	Phone      string  `json:"phone,omitempty"`
+	Email      string  `json:"email,omitempty"`
+	Address    Address `json:"address"`
+	KYCTier    string  `json:"kycTier"`          // basic/standard/enhanced
+	Locale     string  `json:"locale,omitempty"` // 消息语言，为空使用默认语言
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+	Version    int64   `json:"version"` // 版本号，每次更新递增
+}
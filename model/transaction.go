@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// 交易流水（账户每一次余额变动对应一条记录）
+type Transaction struct {
+	TxID         string    `json:"txId"`
+	AccountID    string    `json:"accountId"`
+	Type         string    `json:"type"`      // opening/deposit/transfer_in/transfer_out/teller_deposit/teller_withdraw/interbank_out/interbank_refund/card_purchase
+	Direction    string    `json:"direction"` // credit/debit
+	Amount       float64   `json:"amount"`
+	Currency     string    `json:"currency"`
+	BalanceAfter float64   `json:"balanceAfter"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Reference    string    `json:"reference,omitempty"`
+	Description  string    `json:"description"`
+	Time         time.Time `json:"time"`
+
+	Remittance // 付款方填写的附言、端到端参考号与用途代码
+
+	// 跨币种交易：成交汇率及另一方的金额与币种
+	FXRate          float64 `json:"fxRate,omitempty"`
+	CounterAmount   float64 `json:"counterAmount,omitempty"`
+	CounterCurrency string  `json:"counterCurrency,omitempty"`
+}
+
+// 付款附加信息（随转账指令传递，记入双方流水）
+type Remittance struct {
+	Memo        string `json:"memo,omitempty"`        // 附言
+	EndToEndID  string `json:"endToEndId,omitempty"`  // 端到端参考号（付款方指定，对账用）
+	PurposeCode string `json:"purposeCode,omitempty"` // 用途代码（ISO 20022 ExternalPurpose1Code）
+}
+
+// 内部科目分录（客户流水的对方科目及行内清算分录，保证全行借贷平衡）
+type LedgerEntry struct {
+	TxID      string    `json:"txId"`      // 对应的客户交易流水或内部分录编号
+	Account   string    `json:"account"`   // 总账科目代码
+	Direction string    `json:"direction"` // credit/debit
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	Reference string    `json:"reference,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// 带方向的金额（入账为正，出账为负）
+func (t Transaction) SignedAmount() float64 {
+	if t.Direction == "debit" {
+		return -t.Amount
+	}
+	return t.Amount
+}
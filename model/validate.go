@@ -0,0 +1,241 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 字段级校验错误（接口层放入 Response.Data，前端据此高亮出错字段）
+type FieldError struct {
+	Field string `json:"field"` // 字段的 JSON 名称
+	Error string `json:"error"`
+}
+
+// 参数校验失败，逐字段列出错误
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Field+" "+f.Error)
+	}
+	return "参数校验失败: " + strings.Join(messages, "；")
+}
+
+// 字段校验器：value 为字段值，param 为规则参数（如 max=140 中的 140），校验通过返回空字符串，否则返回错误描述
+type Validator func(value reflect.Value, param string) string
+
+var (
+	accountIDPattern = regexp.MustCompile(`^[0-9]{1,19}$`)
+	currencyPattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+
+	validatorsMu sync.RWMutex
+	validators   = map[string]Validator{
+		"required": validateRequired,
+		"min":      validateMin,
+		"max":      validateMax,
+		"oneof":    validateOneOf,
+		"account":  validateAccount,
+		"amount":   validateAmount,
+		"currency": validateCurrency,
+	}
+)
+
+// 账号格式是否合法（1~19 位数字）
+func ValidAccountID(id string) bool {
+	return accountIDPattern.MatchString(id)
+}
+
+// 注册或替换字段校验器（如按牌价表校验币种是否受支持），之后可在 validate 标签中按名称引用
+func RegisterValidator(name string, fn Validator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+// 按 validate 标签校验请求结构体（含匿名嵌入的结构体），返回全部出错字段。
+// 标签规则以逗号分隔，如 `validate:"required,account"`；含 omitempty 的字段为零值（指针为 nil）时跳过校验，
+// 非 nil 指针按所指的值校验。标签引用了未注册的校验器时返回普通错误（接口层按未知错误处理），不会 panic
+func Validate(v interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+
+	var fields []FieldError
+	if err := validateFields(value, &fields); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// 逐字段校验（匿名嵌入的结构体展开校验，调用方需持有 validatorsMu 读锁）
+func validateFields(value reflect.Value, fields *[]FieldError) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := validateFields(value.Field(i), fields); err != nil {
+				return err
+			}
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		fv := value.Field(i)
+		rules := strings.Split(tag, ",")
+		if rules[0] == "omitempty" {
+			if fv.IsZero() {
+				continue
+			}
+			rules = rules[1:]
+		}
+		if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		for _, rule := range rules {
+			name, param, _ := strings.Cut(rule, "=")
+			fn, ok := validators[name]
+			if !ok {
+				return fmt.Errorf("%s.%s 的 validate 标签引用了未注册的字段校验器 %q", t.Name(), field.Name, name)
+			}
+			if msg := fn(fv, param); msg != "" {
+				*fields = append(*fields, FieldError{Field: jsonName(field), Error: msg})
+				break // 每个字段只报告第一条未通过的规则
+			}
+		}
+	}
+	return nil
+}
+
+// 字段的 JSON 名称（无 json 标签时使用字段名）
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// -------------------------- 内置校验器 --------------------------
+
+func validateRequired(value reflect.Value, _ string) string {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		if value.Len() == 0 {
+			return "不能为空"
+		}
+	}
+	if value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" || value.IsZero() {
+		return "不能为空"
+	}
+	return ""
+}
+
+// 数值不小于参数，字符串长度（按字符计）或列表元素数不少于参数
+func validateMin(value reflect.Value, param string) string {
+	limit, _ := strconv.ParseFloat(param, 64)
+	switch value.Kind() {
+	case reflect.String:
+		if float64(len([]rune(value.String()))) < limit {
+			return fmt.Sprintf("长度不能少于 %s 个字符", param)
+		}
+		return ""
+	case reflect.Slice, reflect.Map:
+		if float64(value.Len()) < limit {
+			return fmt.Sprintf("不能少于 %s 项", param)
+		}
+		return ""
+	}
+	if n, ok := number(value); ok && n < limit {
+		return "不能小于 " + param
+	}
+	return ""
+}
+
+// 数值不大于参数，字符串长度（按字符计）或列表元素数不超过参数
+func validateMax(value reflect.Value, param string) string {
+	limit, _ := strconv.ParseFloat(param, 64)
+	switch value.Kind() {
+	case reflect.String:
+		if float64(len([]rune(value.String()))) > limit {
+			return fmt.Sprintf("长度不能超过 %s 个字符", param)
+		}
+		return ""
+	case reflect.Slice, reflect.Map:
+		if float64(value.Len()) > limit {
+			return fmt.Sprintf("不能超过 %s 项", param)
+		}
+		return ""
+	}
+	if n, ok := number(value); ok && n > limit {
+		return "不能大于 " + param
+	}
+	return ""
+}
+
+// 取值须为参数中以空格分隔的某一项
+func validateOneOf(value reflect.Value, param string) string {
+	options := strings.Fields(param)
+	actual := fmt.Sprint(value.Interface())
+	for _, option := range options {
+		if actual == option {
+			return ""
+		}
+	}
+	return "取值应为 " + strings.Join(options, "/") + " 之一"
+}
+
+func validateAccount(value reflect.Value, _ string) string {
+	if value.Kind() != reflect.String || !ValidAccountID(value.String()) {
+		return "账号应为 1~19 位数字"
+	}
+	return ""
+}
+
+// 金额须大于 0 且最多两位小数
+func validateAmount(value reflect.Value, _ string) string {
+	n, ok := number(value)
+	switch {
+	case !ok || n <= 0 || math.IsInf(n, 0) || math.IsNaN(n):
+		return "必须大于 0"
+	case math.Abs(n-RoundAmount(n)) > 1e-9:
+		return "最多保留两位小数"
+	}
+	return ""
+}
+
+// 币种须为三位大写字母的 ISO 4217 代码（外汇服务启动后替换为按牌价表校验）
+func validateCurrency(value reflect.Value, _ string) string {
+	if value.Kind() != reflect.String || !currencyPattern.MatchString(value.String()) {
+		return "币种应为三位大写字母代码（ISO 4217）"
+	}
+	return ""
+}
+
+// 数值字段转为 float64
+func number(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 乐观锁冲突时的最大重试次数
+const accountUpdateRetries = 3
+
+// 账户存储（模拟数据库，实际项目应使用真实数据库）
+type AccountRepository struct {
+	mu       sync.RWMutex // 账户操作互斥锁（需在 JournalRepository 之前获取）
+	accounts map[string]model.Account
+
+	writeFault func() error // 写入故障注入（为空表示不注入）
+}
+
+// 以初始账户创建账户存储
+func NewAccountRepository(seed []model.Account) *AccountRepository {
+	r := &AccountRepository{accounts: make(map[string]model.Account, len(seed))}
+	for _, account := range seed {
+		r.accounts[account.AccountID] = account
+	}
+	return r
+}
+
+// 加锁（跨多个账户的操作需在锁内调用 Find/Save）
+func (r *AccountRepository) Lock()    { r.mu.Lock() }
+func (r *AccountRepository) Unlock()  { r.mu.Unlock() }
+func (r *AccountRepository) RLock()   { r.mu.RLock() }
+func (r *AccountRepository) RUnlock() { r.mu.RUnlock() }
+
+// 查找账户（调用方需持有锁）
+func (r *AccountRepository) Find(id string) (model.Account, bool) {
+	account, exists := r.accounts[id]
+	return account, exists
+}
+
+// 保存账户并递增版本号（调用方需持有写锁）
+func (r *AccountRepository) Save(account model.Account) model.Account {
+	account.Version++
+	r.accounts[account.AccountID] = account
+	return account
+}
+
+// 设置写入故障注入：返回非 nil 时本次写入失败（用于模拟存储故障）
+func (r *AccountRepository) SetWriteFault(fault func() error) {
+	r.mu.Lock()
+	r.writeFault = fault
+	r.mu.Unlock()
+}
+
+// 写入前检查（调用方需持有写锁）：故障注入命中时返回错误，调用方应放弃本次写入
+func (r *AccountRepository) CheckWrite() error {
+	if r.writeFault == nil {
+		return nil
+	}
+	return r.writeFault()
+}
+
+// 按账号排序的全部账户ID（调用方需持有锁）
+func (r *AccountRepository) IDs() []string {
+	ids := make([]string, 0, len(r.accounts))
+	for id := range r.accounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// 以快照替换全部账户（恢复状态快照时使用，调用方需持有写锁）。
+// 版本号在当前版本与快照版本的较大者之上递增，恢复前签发的 ETag 与 If-Match 版本不会再次匹配
+func (r *AccountRepository) Replace(accounts []model.Account) {
+	replaced := make(map[string]model.Account, len(accounts))
+	for _, account := range accounts {
+		if current, exists := r.accounts[account.AccountID]; exists {
+			if current.Version > account.Version {
+				account.Version = current.Version
+			}
+			account.Version++
+		}
+		replaced[account.AccountID] = account
+	}
+	r.accounts = replaced
+}
+
+// 读取账户快照
+func (r *AccountRepository) Get(id string) (model.Account, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.Find(id)
+}
+
+// 按账号排序的全部账户快照
+func (r *AccountRepository) List() []model.Account {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]model.Account, 0, len(r.accounts))
+	for _, id := range r.IDs() {
+		list = append(list, r.accounts[id])
+	}
+	return list
+}
+
+// 乐观并发更新账户：在锁外基于快照执行 mutate，提交时校验版本未变化，冲突则重新读取重试。
+// expectedVersion 非 0 时为条件更新（If-Match），版本不符直接返回冲突，不重试。
+// commit 在提交的临界区内执行（如记录流水），保证与余额变动一致。
+func (r *AccountRepository) Update(id string, expectedVersion int64, mutate func(*model.Account) error, commit func(model.Account)) (model.Account, error) {
+	for attempt := 1; ; attempt++ {
+		current, exists := r.Get(id)
+		if !exists {
+			return model.Account{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+		}
+		if expectedVersion != 0 && current.Version != expectedVersion {
+			return current, model.NewError(model.CODE_VERSION_CONFLICT, "账户已被修改，请刷新后重试")
+		}
+
+		updated := current
+		if err := mutate(&updated); err != nil {
+			return current, err
+		}
+
+		r.mu.Lock()
+		if r.accounts[id].Version != current.Version {
+			r.mu.Unlock()
+			if expectedVersion != 0 || attempt >= accountUpdateRetries {
+				return current, model.NewError(model.CODE_VERSION_CONFLICT, "账户更新冲突，请稍后重试")
+			}
+			continue
+		}
+		if err := r.CheckWrite(); err != nil {
+			r.mu.Unlock()
+			return current, err
+		}
+		updated = r.Save(updated)
+		if commit != nil {
+			commit(updated)
+		}
+		r.mu.Unlock()
+		return updated, nil
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 客户存储（模拟数据库）
+type CustomerRepository struct {
+	mu        sync.RWMutex
+	customers map[string]model.Customer
+}
+
+// 以初始客户创建客户存储
+func NewCustomerRepository(seed []model.Customer) *CustomerRepository {
+	r := &CustomerRepository{customers: make(map[string]model.Customer, len(seed))}
+	for _, customer := range seed {
+		r.customers[customer.CustomerID] = customer
+	}
+	return r
+}
+
+// 读取客户快照
+func (r *CustomerRepository) Get(id string) (model.Customer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	customer, exists := r.customers[id]
+	return customer, exists
+}
+
+// 保存客户并递增版本号
+func (r *CustomerRepository) Save(customer model.Customer) model.Customer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	customer.Version++
+	r.customers[customer.CustomerID] = customer
+	return customer
+}
+
+// 以快照替换全部客户（恢复状态快照时使用）
+func (r *CustomerRepository) Replace(customers []model.Customer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.customers = make(map[string]model.Customer, len(customers))
+	for _, customer := range customers {
+		r.customers[customer.CustomerID] = customer
+	}
+}
+
+// 按客户号排序的全部客户快照
+func (r *CustomerRepository) List() []model.Customer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]model.Customer, 0, len(r.customers))
+	for _, customer := range r.customers {
+		list = append(list, customer)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CustomerID < list[j].CustomerID })
+	return list
+}
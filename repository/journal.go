@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 交易流水与内部科目分录存储（按记账顺序追加）
+type JournalRepository struct {
+	mu           sync.RWMutex // 需在 AccountRepository 之后获取
+	transactions []model.Transaction
+	entries      []model.LedgerEntry
+	seq          int
+	generation   int // 快照恢复次数：增量读取方据此判断已读取的流水是否仍然有效
+
+	// 账户索引：账户ID → 流水在 transactions 中的位置
+	byAccount map[string][]int            // 按记账顺序
+	byTime    map[string][]int            // 按交易时间（时间相同按记账顺序），补记的历史流水插入对应位置
+	byTerm    map[string]map[string][]int // 全文索引：账户ID → 索引词 → 位置（按记账顺序）
+}
+
+// 流水排序字段
+const (
+	JOURNAL_SORT_TIME   = "time"
+	JOURNAL_SORT_AMOUNT = "amount"
+)
+
+// 账户流水查询条件：时间区间经时间索引定位，Match 对区间内的流水逐条过滤（为空表示不过滤）
+type JournalQuery struct {
+	From   time.Time // 起始时间（含），零值表示不限
+	To     time.Time // 截止时间（不含），零值表示不限
+	Match  func(model.Transaction) bool
+	SortBy string // time（默认）/amount，相同时按记账顺序
+	Asc    bool   // 升序，默认倒序
+}
+
+// 一页账户流水
+type JournalPage struct {
+	Items   []model.Transaction
+	LastPos int  // 本页最后一条流水的位置（作为下一页游标）
+	More    bool // 之后是否还有满足条件的流水
+}
+
+// 流水存储快照（含流水号序列，恢复后新流水号不与快照内重复）
+type JournalSnapshot struct {
+	Transactions []model.Transaction `json:"transactions"`
+	Entries      []model.LedgerEntry `json:"entries"`
+	Seq          int                 `json:"seq"`
+}
+
+func NewJournalRepository() *JournalRepository {
+	return &JournalRepository{
+		transactions: make([]model.Transaction, 0),
+		entries:      make([]model.LedgerEntry, 0),
+		byAccount:    make(map[string][]int),
+		byTime:       make(map[string][]int),
+		byTerm:       make(map[string]map[string][]int),
+	}
+}
+
+// 追加一条交易流水并分配流水号；contra 非空时同时在该科目记一条反方向的内部分录
+func (r *JournalRepository) Append(tx model.Transaction, contra string) model.Transaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	tx.TxID = fmt.Sprintf("TX%s%08d", tx.Time.Format("20060102"), r.seq)
+	r.transactions = append(r.transactions, tx)
+	r.index(len(r.transactions) - 1)
+
+	if contra != "" {
+		direction := "debit"
+		if tx.Direction == "debit" {
+			direction = "credit"
+		}
+		r.entries = append(r.entries, model.LedgerEntry{
+			TxID:      tx.TxID,
+			Account:   contra,
+			Direction: direction,
+			Amount:    tx.Amount,
+			Currency:  tx.Currency,
+			Reference: tx.Reference,
+			Time:      tx.Time,
+		})
+	}
+	return tx
+}
+
+// 追加一笔不涉及客户账户的内部分录（借 debit 科目、贷 credit 科目）
+func (r *JournalRepository) AppendInternal(debit, credit string, amount float64, currency, reference string, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	entryID := fmt.Sprintf("GL%s%08d", at.Format("20060102"), r.seq)
+	r.entries = append(r.entries,
+		model.LedgerEntry{TxID: entryID, Account: debit, Direction: "debit", Amount: amount, Currency: currency, Reference: reference, Time: at},
+		model.LedgerEntry{TxID: entryID, Account: credit, Direction: "credit", Amount: amount, Currency: currency, Reference: reference, Time: at},
+	)
+}
+
+// 查询账户在 [from, to) 区间内的交易流水（零值表示不限）
+func (r *JournalRepository) Transactions(accountID string, from, to time.Time) []model.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]model.Transaction, 0)
+	for _, pos := range r.byAccount[accountID] {
+		tx := r.transactions[pos]
+		if !from.IsZero() && tx.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !tx.Time.Before(to) {
+			continue
+		}
+		result = append(result, tx)
+	}
+	return result
+}
+
+// 全部流水与内部分录的快照
+func (r *JournalRepository) Snapshot() JournalSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return JournalSnapshot{
+		Transactions: append([]model.Transaction{}, r.transactions...),
+		Entries:      append([]model.LedgerEntry{}, r.entries...),
+		Seq:          r.seq,
+	}
+}
+
+// 以快照替换全部流水与内部分录
+func (r *JournalRepository) Restore(snapshot JournalSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.transactions = append(make([]model.Transaction, 0, len(snapshot.Transactions)), snapshot.Transactions...)
+	r.entries = append(make([]model.LedgerEntry, 0, len(snapshot.Entries)), snapshot.Entries...)
+	r.seq = snapshot.Seq
+	r.generation++
+	r.byAccount = make(map[string][]int)
+	r.byTime = make(map[string][]int)
+	r.byTerm = make(map[string]map[string][]int)
+	for i := range r.transactions {
+		r.index(i)
+	}
+}
+
+// 将 pos 位置的流水加入账户索引（调用方需持有写锁）
+func (r *JournalRepository) index(pos int) {
+	tx := r.transactions[pos]
+	r.byAccount[tx.AccountID] = append(r.byAccount[tx.AccountID], pos)
+
+	terms := r.byTerm[tx.AccountID]
+	if terms == nil {
+		terms = make(map[string][]int)
+		r.byTerm[tx.AccountID] = terms
+	}
+	seen := make(map[string]bool)
+	for _, field := range TransactionTextFields(tx) {
+		for _, term := range TextTerms(field.Text) {
+			if !seen[term] {
+				seen[term] = true
+				terms[term] = append(terms[term], pos)
+			}
+		}
+	}
+
+	list := r.byTime[tx.AccountID]
+	if n := len(list); n == 0 || !tx.Time.Before(r.transactions[list[n-1]].Time) {
+		r.byTime[tx.AccountID] = append(list, pos)
+		return
+	}
+	// 补记的历史流水：插入到第一条晚于它的流水之前
+	i := sort.Search(len(list), func(i int) bool { return r.transactions[list[i]].Time.After(tx.Time) })
+	list = append(list, 0)
+	copy(list[i+1:], list[i:])
+	list[i] = pos
+	r.byTime[tx.AccountID] = list
+}
+
+// 按条件查询账户流水（按 q 指定的顺序返回全部匹配项）
+func (r *JournalRepository) Query(accountID string, q JournalQuery) []model.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	positions := r.selectPositions(accountID, q)
+	result := make([]model.Transaction, 0, len(positions))
+	for _, pos := range positions {
+		result = append(result, r.transactions[pos])
+	}
+	return result
+}
+
+// 按条件游标分页查询账户流水：after 为上一页最后一条流水的位置（< 0 表示第一页），该位置的流水号须为 afterID，
+// 否则返回 false（游标已失效）。按排序键（时间或金额，相同时按记账顺序）定位，翻页期间新增的流水不会造成重复或遗漏
+func (r *JournalRepository) Page(accountID string, q JournalQuery, after int, afterID string, limit int) (JournalPage, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if after >= 0 && (after >= len(r.transactions) || r.transactions[after].TxID != afterID || r.transactions[after].AccountID != accountID) {
+		return JournalPage{}, false
+	}
+
+	page := JournalPage{Items: make([]model.Transaction, 0, limit), LastPos: -1}
+	add := func(pos int) bool {
+		if len(page.Items) == limit {
+			page.More = true
+			return false
+		}
+		page.Items = append(page.Items, r.transactions[pos])
+		page.LastPos = pos
+		return true
+	}
+
+	if q.SortBy == JOURNAL_SORT_AMOUNT {
+		// 金额排序：区间内匹配项排序后从游标之后取
+		for _, pos := range r.selectPositions(accountID, q) {
+			if after >= 0 && !r.follows(pos, after, q) {
+				continue
+			}
+			if !add(pos) {
+				break
+			}
+		}
+		return page, true
+	}
+
+	// 时间排序：沿时间索引从游标处继续扫描，开销与账户流水总量无关
+	list := r.byTime[accountID]
+	lo, hi := r.timeRange(list, q.From, q.To)
+	if after >= 0 {
+		k := sort.Search(len(list), func(i int) bool { return !r.timeLess(list[i], after) })
+		if q.Asc {
+			lo = maxInt(lo, k+1)
+		} else {
+			hi = minInt(hi, k)
+		}
+	}
+	for n := 0; n < hi-lo; n++ {
+		i := hi - 1 - n
+		if q.Asc {
+			i = lo + n
+		}
+		tx := r.transactions[list[i]]
+		if q.Match != nil && !q.Match(tx) {
+			continue
+		}
+		if !add(list[i]) {
+			break
+		}
+	}
+	return page, true
+}
+
+// 增量读取：返回位置 from 起追加的流水、下一次读取的起点及当前代次。generation 与当前代次不同
+// （流水已被快照替换）时从头返回，调用方应丢弃此前的累计结果
+func (r *JournalRepository) Tail(from, generation int) ([]model.Transaction, int, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if generation != r.generation || from > len(r.transactions) {
+		from = 0
+	}
+	return append([]model.Transaction(nil), r.transactions[from:]...), len(r.transactions), r.generation
+}
+
+// 账户流水水位：账户最后一条流水的位置（无流水时为 -1）及当前代次，账户有新流水或流水被快照替换时变化
+func (r *JournalRepository) Watermark(accountID string) (int, int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := r.byAccount[accountID]
+	if len(list) == 0 {
+		return -1, r.generation
+	}
+	return list[len(list)-1], r.generation
+}
+
+// 全文检索账户流水：返回包含全部索引词（须为 TextTerms 的结果）且满足 q 的流水，按 q 指定的顺序排列。
+// 由倒排索引求交集，开销与命中词的流水数相关而与账户流水总量无关
+func (r *JournalRepository) SearchText(accountID string, terms []string, q JournalQuery) []model.Transaction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]model.Transaction, 0)
+	if len(terms) == 0 {
+		return result
+	}
+	postings := make([][]int, 0, len(terms))
+	for _, term := range terms {
+		list := r.byTerm[accountID][term]
+		if len(list) == 0 {
+			return result
+		}
+		postings = append(postings, list)
+	}
+	// 从最短的倒排表开始求交集
+	sort.Slice(postings, func(i, j int) bool { return len(postings[i]) < len(postings[j]) })
+	positions := postings[0]
+	for _, list := range postings[1:] {
+		positions = intersectSorted(positions, list)
+	}
+
+	matched := make([]int, 0, len(positions))
+	for _, pos := range positions {
+		tx := r.transactions[pos]
+		if (!q.From.IsZero() && tx.Time.Before(q.From)) || (!q.To.IsZero() && !tx.Time.Before(q.To)) {
+			continue
+		}
+		if q.Match == nil || q.Match(tx) {
+			matched = append(matched, pos)
+		}
+	}
+	less := r.timeLess
+	if q.SortBy == JOURNAL_SORT_AMOUNT {
+		less = r.amountLess
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if q.Asc {
+			return less(matched[i], matched[j])
+		}
+		return less(matched[j], matched[i])
+	})
+	for _, pos := range matched {
+		result = append(result, r.transactions[pos])
+	}
+	return result
+}
+
+// 两个升序位置列表的交集
+func intersectSorted(a, b []int) []int {
+	result := make([]int, 0, minInt(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// 区间内满足条件的流水位置，按查询顺序排列（调用方需持有读锁）
+func (r *JournalRepository) selectPositions(accountID string, q JournalQuery) []int {
+	list := r.byTime[accountID]
+	lo, hi := r.timeRange(list, q.From, q.To)
+	positions := make([]int, 0, hi-lo)
+	for _, pos := range list[lo:hi] {
+		if q.Match == nil || q.Match(r.transactions[pos]) {
+			positions = append(positions, pos)
+		}
+	}
+	if q.SortBy == JOURNAL_SORT_AMOUNT {
+		sort.SliceStable(positions, func(i, j int) bool { return r.amountLess(positions[i], positions[j]) })
+	}
+	if !q.Asc {
+		for i, j := 0, len(positions)-1; i < j; i, j = i+1, j-1 {
+			positions[i], positions[j] = positions[j], positions[i]
+		}
+	}
+	return positions
+}
+
+// 时间索引中 [from, to) 区间对应的下标范围（调用方需持有读锁）
+func (r *JournalRepository) timeRange(list []int, from, to time.Time) (int, int) {
+	lo, hi := 0, len(list)
+	if !from.IsZero() {
+		lo = sort.Search(len(list), func(i int) bool { return !r.transactions[list[i]].Time.Before(from) })
+	}
+	if !to.IsZero() {
+		hi = sort.Search(len(list), func(i int) bool { return !r.transactions[list[i]].Time.Before(to) })
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// 按查询顺序，pos 是否排在游标 after 之后（调用方需持有读锁）
+func (r *JournalRepository) follows(pos, after int, q JournalQuery) bool {
+	less := r.timeLess
+	if q.SortBy == JOURNAL_SORT_AMOUNT {
+		less = r.amountLess
+	}
+	if q.Asc {
+		return less(after, pos)
+	}
+	return less(pos, after)
+}
+
+// 排序键比较：时间（相同时按记账顺序）
+func (r *JournalRepository) timeLess(a, b int) bool {
+	ta, tb := r.transactions[a].Time, r.transactions[b].Time
+	return ta.Before(tb) || (ta.Equal(tb) && a < b)
+}
+
+// 排序键比较：金额（相同时按记账顺序）
+func (r *JournalRepository) amountLess(a, b int) bool {
+	ma, mb := r.transactions[a].Amount, r.transactions[b].Amount
+	return ma < mb || (ma == mb && a < b)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// 在读锁内遍历全部流水与内部分录（fn 不得修改或保留切片）
+func (r *JournalRepository) View(fn func(transactions []model.Transaction, entries []model.LedgerEntry)) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fn(r.transactions, r.entries)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 流水中参与全文检索的字段
+type TextField struct {
+	Name string // JSON 字段名
+	Text string
+}
+
+// 流水的全文检索字段：摘要（含收付款方户名）、对方、业务参考号、附言与端到端参考号
+func TransactionTextFields(tx model.Transaction) []TextField {
+	return []TextField{
+		{Name: "description", Text: tx.Description},
+		{Name: "counterparty", Text: tx.Counterparty},
+		{Name: "reference", Text: tx.Reference},
+		{Name: "memo", Text: tx.Memo},
+		{Name: "endToEndId", Text: tx.EndToEndID},
+	}
+}
+
+// 文本片段：连续的字母数字构成一个词，连续的汉字构成一个汉字串（汉字串按单字建立索引）
+type TextSegment struct {
+	Text  string // 原文
+	Start int    // 在原文中的起止字节位置
+	End   int
+	Han   bool
+}
+
+// 切分文本片段，其余字符（空白、标点等）作为分隔符
+func TextSegments(text string) []TextSegment {
+	segments := make([]TextSegment, 0)
+	start, han := -1, false
+	flush := func(end int) {
+		if start >= 0 {
+			segments = append(segments, TextSegment{Text: text[start:end], Start: start, End: end, Han: han})
+			start = -1
+		}
+	}
+	for i, r := range text {
+		isHan := unicode.Is(unicode.Han, r)
+		isWord := !isHan && (unicode.IsLetter(r) || unicode.IsDigit(r))
+		switch {
+		case !isHan && !isWord:
+			flush(i)
+		case start >= 0 && isHan != han:
+			flush(i)
+			start, han = i, isHan
+		case start < 0:
+			start, han = i, isHan
+		}
+	}
+	flush(len(text))
+	return segments
+}
+
+// 文本的索引词：词转为小写，汉字串拆为单字，去重
+func TextTerms(text string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	for _, segment := range TextSegments(text) {
+		if !segment.Han {
+			add(strings.ToLower(segment.Text))
+			continue
+		}
+		for s := segment.Text; s != ""; {
+			_, size := utf8.DecodeRuneInString(s)
+			add(s[:size])
+			s = s[size:]
+		}
+	}
+	return terms
+}
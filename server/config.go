@@ -0,0 +1,272 @@
+package server
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/handler"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 服务配置（默认值可通过环境变量覆盖）
+type Config struct {
+	Port          string  // 监听端口
+	StaticDir     string  // 前端文件所在目录（indexnew.html 需放在此目录）
+	SimClockSpeed float64 // 模拟时钟倍速（1 表示与真实时间同步）
+
+	Clearing     service.ClearingConfig    // 跨行清算
+	Treasury     service.TreasuryConfig    // 资金头寸与准备金（运行时可通过 /api/admin/treasury 调整）
+	RTGS         service.RTGSConfig        // 央行实时全额结算（日间透支限额可通过 /api/admin/rtgs/accounts 调整）
+	Fees         service.FeeConfig         // 转账手续费与报价
+	Dormancy     service.DormancyConfig    // 休眠账户
+	Cashback     service.CashbackConfig    // 银行卡消费返现
+	Loyalty      service.LoyaltyConfig     // 积分
+	Loan         service.LoanConfig        // 贷款
+	DirectDebit  service.DirectDebitConfig // 直接借记
+	Acquiring    service.AcquiringConfig   // 商户收单
+	Regulatory   service.RegulatoryConfig  // 监管报表
+	Tax          service.TaxConfig         // 利息代扣税
+	Calendar     service.CalendarConfig    // 营业日历
+	Session      service.SessionConfig     // 登录会话
+	LoginGuard   service.LoginGuardConfig  // 登录失败锁定
+	Password     service.PasswordConfig    // 登录密码与重置
+	Risk         service.RiskConfig        // 风控
+	FX           service.FXConfig          // 外汇行情
+	GLConfigPath string                    // 科目表配置文件
+	TemplateDir  string                    // 消息模板目录
+	SnapshotDir  string                    // 状态快照目录
+	FlagsPath    string                    // 功能开关配置文件（运行时可通过 /api/admin/flags 调整）
+
+	WS ws.Config // WebSocket 推送
+
+	Compression handler.CompressionConfig // HTTP 响应压缩（gzip/deflate）
+
+	ISO8583Addr string // ISO 8583 监听地址，为空则不启用（如 ":8583"）
+
+	Seed service.SeedRequest // 启动时生成的模拟数据，账户数为 0 时不生成
+
+	Netting service.ClearingHouseConfig // 低额跨行支付轧差清算所
+
+	Leader service.LeaderConfig // 多实例选主（配置共享租约目录后后台任务只在主节点执行）
+
+	Chaos service.ChaosConfig // 故障注入（运行时可通过 /api/sim/chaos 调整）
+
+	Breaker service.BreakerConfig // 模拟外部系统熔断（状态可通过 /api/admin/breakers 查看）
+
+	Email service.EmailConfig // 邮件通知
+	SMS   service.SMSConfig   // 短信通知
+}
+
+// 读取服务配置
+func LoadConfig() Config {
+	glConfigPath := os.Getenv("GL_CONFIG")
+	if glConfigPath == "" {
+		glConfigPath = "gl_accounts.json"
+	}
+
+	return Config{
+		Port:          "8080",
+		StaticDir:     "./",
+		SimClockSpeed: envFloat("SIM_CLOCK_SPEED", 1),
+		Clearing: service.ClearingConfig{
+			Delay:      envDuration("CLEARING_DELAY", 30*time.Second),
+			RejectRate: envFloat("CLEARING_REJECT_RATE", 0.05),
+			ReturnRate: envFloat("CLEARING_RETURN_RATE", 0.02),
+
+			NetworkFailureRate: envFloat("CLEARING_NETWORK_FAILURE_RATE", 0),
+		},
+		Treasury: service.TreasuryConfig{
+			ReserveRatio:   envFloat("RESERVE_RATIO", 0.1),
+			QueueThreshold: envFloat("LIQUIDITY_QUEUE_THRESHOLD", 50000),
+		},
+		RTGS: service.RTGSConfig{
+			IntradayLimit:     envFloat("RTGS_INTRADAY_LIMIT", 1000000),
+			BankBalance:       envFloat("RTGS_BANK_BALANCE", 5000000),
+			BankIntradayLimit: envFloat("RTGS_BANK_INTRADAY_LIMIT", 1000000),
+		},
+		Leader: service.LeaderConfig{
+			LeaseDir: os.Getenv("LEADER_LEASE_DIR"),
+			NodeID:   os.Getenv("NODE_ID"),
+			LeaseTTL: envDuration("LEADER_LEASE_TTL", 15*time.Second),
+		},
+		Breaker: service.BreakerConfig{
+			FailureThreshold: envInt("BREAKER_FAILURE_THRESHOLD", 5),
+			OpenTimeout:      envDuration("BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Netting: service.ClearingHouseConfig{
+			LowValueLimit: envFloat("CLEARING_HOUSE_LIMIT", 5000),
+			CycleInterval: envDuration("CLEARING_HOUSE_CYCLE", 2*time.Hour),
+		},
+		Fees: service.FeeConfig{
+			FXRate:        envFloat("FEE_FX_RATE", 0.001),
+			FXMin:         envFloat("FEE_FX_MIN", 1),
+			FXMax:         envFloat("FEE_FX_MAX", 50),
+			InterbankRate: envFloat("FEE_INTERBANK_RATE", 0.0005),
+			InterbankMin:  envFloat("FEE_INTERBANK_MIN", 2),
+			InterbankMax:  envFloat("FEE_INTERBANK_MAX", 25),
+			QuoteTTL:      envDuration("TRANSFER_QUOTE_TTL", 60*time.Second),
+		},
+		Dormancy: service.DormancyConfig{
+			Days: envInt("DORMANCY_DAYS", 90),
+		},
+		Cashback: service.CashbackConfig{
+			Rate:       envFloat("CASHBACK_RATE", 0.005),
+			MinSpend:   envFloat("CASHBACK_MIN_SPEND", 10),
+			MonthlyCap: envFloat("CASHBACK_MONTHLY_CAP", 100),
+		},
+		Loyalty: service.LoyaltyConfig{
+			EarnRates: map[string]float64{
+				"card_purchase": envFloat("LOYALTY_CARD_RATE", 1),
+				"transfer_out":  envFloat("LOYALTY_TRANSFER_RATE", 0.1),
+				"interbank_out": envFloat("LOYALTY_TRANSFER_RATE", 0.1),
+			},
+			RedeemRate: envFloat("LOYALTY_REDEEM_RATE", 0.01),
+			MinRedeem:  int64(envInt("LOYALTY_MIN_REDEEM", 100)),
+		},
+		Loan: service.LoanConfig{
+			PrepaymentFeeRate:  envFloat("LOAN_PREPAYMENT_FEE_RATE", 0.01),
+			PenaltyMarkup:      envFloat("LOAN_PENALTY_MARKUP", 0.5),
+			RetryIntervalDays:  envInt("LOAN_RETRY_INTERVAL_DAYS", 3),
+			OriginationFeeRate: envFloat("LOAN_ORIGINATION_FEE_RATE", 0.005),
+		},
+		DirectDebit: service.DirectDebitConfig{
+			RefundDays: envInt("DIRECT_DEBIT_REFUND_DAYS", 56),
+		},
+		Acquiring: service.AcquiringConfig{
+			InterchangeRate: envFloat("POS_INTERCHANGE_RATE", 0.006),
+		},
+		Regulatory: service.RegulatoryConfig{
+			LargeCashThreshold: envFloat("REG_LARGE_CASH_THRESHOLD", 50000),
+		},
+		Tax: service.TaxConfig{
+			WithholdingRate: envFloat("INTEREST_WITHHOLDING_TAX_RATE", 0.2),
+		},
+		Calendar: service.CalendarConfig{
+			Holidays: os.Getenv("BUSINESS_HOLIDAYS"),
+		},
+		Session: service.SessionConfig{
+			IdleTimeout: envDuration("SESSION_IDLE_TIMEOUT", 30*time.Minute),
+		},
+		Password: service.PasswordConfig{
+			DefaultPassword: envString("LOGIN_PASSWORD", "123456"),
+			ResetTTL:        envDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		},
+		LoginGuard: service.LoginGuardConfig{
+			MaxFailures:   envInt("LOGIN_MAX_FAILURES", 5),
+			IPMaxFailures: envInt("LOGIN_IP_MAX_FAILURES", 20),
+			Window:        envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			Lockout:       envDuration("LOGIN_LOCKOUT", 15*time.Minute),
+		},
+		Risk: service.RiskConfig{
+			NewLocationAction:    envString("RISK_NEW_LOCATION_ACTION", service.RISK_ACTION_STEP_UP),
+			NewLocationMinAmount: envFloat("RISK_NEW_LOCATION_MIN_AMOUNT", 1000),
+		},
+		FX: service.FXConfig{
+			Spread:         envFloat("FX_SPREAD", 0.005),
+			Provider:       os.Getenv("FX_PROVIDER"),
+			ProviderURL:    os.Getenv("FX_PROVIDER_URL"),
+			UpdateInterval: envDuration("FX_UPDATE_INTERVAL", 5*time.Second),
+			Volatility:     envFloat("FX_VOLATILITY", 0.001),
+			FailureRate:    envFloat("FX_PROVIDER_FAILURE_RATE", 0),
+		},
+		GLConfigPath: glConfigPath,
+		TemplateDir:  envString("MESSAGE_TEMPLATE_DIR", "templates"),
+		SnapshotDir:  envString("SNAPSHOT_DIR", "snapshots"),
+		FlagsPath:    envString("FEATURE_FLAGS_FILE", "feature_flags.json"),
+		WS: ws.Config{
+			SendBuffer:               envInt("WS_SEND_BUFFER", 64),
+			WriteTimeout:             envDuration("WS_WRITE_TIMEOUT", 10*time.Second),
+			ReplayBuffer:             envInt("WS_REPLAY_BUFFER", 256),
+			MaxConnectionsPerAccount: envInt("WS_MAX_CONNECTIONS_PER_ACCOUNT", 5),
+			ConnectionLimitPolicy:    envString("WS_CONNECTION_LIMIT_POLICY", ws.LIMIT_EVICT_OLDEST),
+			AdminKey:                 envString("WS_ADMIN_KEY", "admin"),
+			Compression:              os.Getenv("WS_COMPRESSION") == "true",
+			CompressionLevel:         envInt("WS_COMPRESSION_LEVEL", 0),
+		},
+		Compression: handler.CompressionConfig{
+			Enabled:      os.Getenv("HTTP_COMPRESSION") != "false",
+			MinSize:      envInt("HTTP_COMPRESSION_MIN_SIZE", 1024),
+			Level:        envInt("HTTP_COMPRESSION_LEVEL", 0),
+			ContentTypes: strings.Split(envString("HTTP_COMPRESSION_TYPES", "application/json,application/xml,text/"), ","),
+		},
+		ISO8583Addr: os.Getenv("ISO8583_ADDR"),
+		Seed: service.SeedRequest{
+			Count:        envInt("SEED_ACCOUNTS", 0),
+			Distribution: os.Getenv("SEED_DISTRIBUTION"),
+			Median:       envFloat("SEED_BALANCE_MEDIAN", 0),
+			Max:          envFloat("SEED_BALANCE_MAX", 0),
+			Transactions: envInt("SEED_TRANSACTIONS", 0),
+			HistoryDays:  envInt("SEED_HISTORY_DAYS", 0),
+			RandSeed:     int64(envInt("SEED_RANDOM_SEED", 0)),
+		},
+		Chaos: service.ChaosConfig{
+			Enabled: os.Getenv("CHAOS_ENABLED") == "true",
+			Latency: service.ChaosLatency{
+				Min: envDuration("CHAOS_LATENCY_MIN", 0),
+				Max: envDuration("CHAOS_LATENCY_MAX", 0),
+			},
+			ErrorRate:        envFloat("CHAOS_ERROR_RATE", 0),
+			WriteFailureRate: envFloat("CHAOS_WRITE_FAILURE_RATE", 0),
+			StormInterval:    envDuration("CHAOS_STORM_INTERVAL", 0),
+			StormRatio:       envFloat("CHAOS_STORM_RATIO", 0.5),
+		},
+		Email: service.EmailConfig{
+			Sender:                 os.Getenv("EMAIL_SENDER"),
+			SMTPAddr:               os.Getenv("SMTP_ADDR"),
+			SMTPUsername:           os.Getenv("SMTP_USERNAME"),
+			SMTPPassword:           os.Getenv("SMTP_PASSWORD"),
+			From:                   envString("SMTP_FROM", "noreply@zerobank.local"),
+			LargeTransferThreshold: envFloat("EMAIL_LARGE_TRANSFER_THRESHOLD", 10000),
+		},
+		SMS: service.SMSConfig{
+			FailureRate:   envFloat("SMS_FAILURE_RATE", 0),
+			MaxAttempts:   envInt("SMS_MAX_ATTEMPTS", 3),
+			RetryInterval: envDuration("SMS_RETRY_INTERVAL", 2*time.Second),
+		},
+	}
+}
+
+// 读取字符串类型的环境变量，未设置时使用默认值
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// 读取时长类型的环境变量（如 "30s"），未设置或格式错误时使用默认值
+func envDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		log.Printf("环境变量 %s 格式错误，使用默认值: %s", key, def)
+	}
+	return def
+}
+
+// 读取浮点类型的环境变量，未设置或格式错误时使用默认值
+func envFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+		log.Printf("环境变量 %s 格式错误，使用默认值: %v", key, def)
+	}
+	return def
+}
+
+// 读取整数类型的环境变量，未设置或格式错误时使用默认值
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		log.Printf("环境变量 %s 格式错误，使用默认值: %d", key, def)
+	}
+	return def
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 测试账户（模拟数据）
+var seedAccounts = []model.Account{
+	{
+		AccountID:   "8001234567",
+		CustomerID:  "C00000001",
+		UserName:    "张三",
+		Balance:     12580.00,
+		Currency:    "CNY",
+		Type:        "checking",
+		ProductCode: "CHK001",
+		Status:      "normal",
+		CreateAt:    "2023-06-15",
+		Email:       "zhangsan@example.com",
+		Phone:       "13800001234",
+		Version:     1,
+	},
+	{
+		AccountID:   "8001234568",
+		CustomerID:  "C00000002",
+		UserName:    "李四",
+		Balance:     5000.00,
+		Currency:    "CNY",
+		Type:        "savings",
+		ProductCode: "SAV001",
+		Status:      "normal",
+		CreateAt:    "2023-07-20",
+		Email:       "lisi@example.com",
+		Phone:       "13900005678",
+		Version:     1,
+	},
+	{
+		AccountID:   "8001234569",
+		CustomerID:  "C00000003",
+		UserName:    "孙七",
+		Balance:     2000.00,
+		Currency:    "USD",
+		Type:        "savings",
+		ProductCode: "SAV001",
+		Status:      "normal",
+		CreateAt:    "2023-09-01",
+		Email:       "sunqi@example.com",
+		Phone:       "13700009012",
+		Locale:      "en-US",
+		Version:     1,
+	},
+}
+
+// 测试客户（与测试账户的联系方式一致）
+var seedCustomers = []model.Customer{
+	{
+		CustomerID: "C00000001",
+		Name:       "张三",
+		Phone:      "13800001234",
+		Email:      "zhangsan@example.com",
+		Address:    model.Address{Street: "朝阳区建国路 88 号", City: "北京", Province: "北京", PostalCode: "100022", Country: "CN"},
+		KYCTier:    model.KYC_TIER_STANDARD,
+		CreatedAt:  "2023-06-15 09:00:00",
+		UpdatedAt:  "2023-06-15 09:00:00",
+		Version:    1,
+	},
+	{
+		CustomerID: "C00000002",
+		Name:       "李四",
+		Phone:      "13900005678",
+		Email:      "lisi@example.com",
+		Address:    model.Address{Street: "浦东新区世纪大道 100 号", City: "上海", Province: "上海", PostalCode: "200120", Country: "CN"},
+		KYCTier:    model.KYC_TIER_BASIC,
+		CreatedAt:  "2023-07-20 09:00:00",
+		UpdatedAt:  "2023-07-20 09:00:00",
+		Version:    1,
+	},
+	{
+		CustomerID: "C00000003",
+		Name:       "孙七",
+		Phone:      "13700009012",
+		Email:      "sunqi@example.com",
+		Address:    model.Address{Street: "天河区珠江新城花城大道 18 号", City: "广州", Province: "广东", PostalCode: "510623", Country: "CN"},
+		KYCTier:    model.KYC_TIER_ENHANCED,
+		Locale:     "en-US",
+		CreatedAt:  "2023-09-01 09:00:00",
+		UpdatedAt:  "2023-09-01 09:00:00",
+		Version:    1,
+	},
+}
+
+// 打印测试账户信息
+func printTestAccounts(accounts []model.Account) {
+	log.Println("\n[📋 测试账户信息]")
+	for _, acc := range accounts {
+		log.Printf("账户ID: %s | 用户名: %s | 初始余额: %.2f %s | 状态: %s",
+			acc.AccountID, acc.UserName, acc.Balance, acc.Currency, acc.Status)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
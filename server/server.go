@@ -0,0 +1,285 @@
+// Package server 负责组装各层依赖并启动 HTTP 服务与后台任务。
+package server
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/handler"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/service"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 服务实例
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+
+	hub           *ws.Hub
+	fx            *service.FXService
+	interbank     *service.InterbankService
+	holds         *service.HoldService
+	announcements *service.AnnouncementService
+	eod           *service.EODService
+	chaos         *service.ChaosService
+	maintenance   *service.MaintenanceService
+	leader        *service.LeaderElector
+	handler       *handler.Handler
+}
+
+// 按配置创建服务：初始化存储、业务服务与路由
+func NewServer(cfg Config) *Server {
+	log.Printf("服务初始化完成，监听端口: %s", cfg.Port)
+	log.Printf("静态文件目录: %s", cfg.StaticDir)
+
+	// 存储与基础设施
+	accountRepo := repository.NewAccountRepository(seedAccounts)
+	customerRepo := repository.NewCustomerRepository(seedCustomers)
+	journalRepo := repository.NewJournalRepository()
+	clock := sim.NewClock(cfg.SimClockSpeed)
+	// 功能开关：风控、手续费、严格 HTTP 状态码等行为按环境开关，无需重新部署
+	flags := service.NewFeatureFlagService(cfg.FlagsPath)
+	// 多步骤操作（转账、贷款放款）经事务编排执行，失败时自动补偿
+	sagas := service.NewSagaService(clock)
+	hub := ws.NewHub(cfg.WS)
+	// 消息文案按事件、语言、通道从模板库渲染
+	templates := service.NewTemplateRegistry(cfg.TemplateDir)
+	// 汇率源、跨行清算网络、短信网关等模拟外部系统的调用经熔断器保护
+	breakers := service.NewBreakerRegistry(cfg.Breaker)
+	// 业务服务经通知中心推送，提醒类消息同时存档，交易提醒同时发送短信
+	sms := service.NewSMSService(cfg.SMS, accountRepo, templates, hub, clock, breakers.Get(service.BREAKER_SMS))
+	notifications := service.NewNotificationService(sms, clock)
+	emails := service.NewEmailService(cfg.Email, templates, clock)
+
+	// 打印测试账户信息，方便测试人员查看
+	printTestAccounts(accountRepo.List())
+
+	// 业务服务
+	chart := service.LoadChartOfAccounts(cfg.GLConfigPath)
+	ledger := service.NewLedgerService(accountRepo, journalRepo, chart, clock, notifications)
+	fx := service.NewFXService(cfg.FX, notifications, breakers.Get(service.BREAKER_FX))
+	credit := service.NewCreditService(accountRepo, ledger, fx, clock)
+	tax := service.NewTaxService(cfg.Tax, accountRepo, journalRepo)
+	products := service.NewProductService(accountRepo, ledger, fx, tax, clock)
+	pricing := service.NewPricingService(cfg.Fees, accountRepo, fx, products, flags, clock)
+	loyalty := service.NewLoyaltyService(cfg.Loyalty, accountRepo, ledger, fx, clock, notifications, templates)
+	promos := service.NewPromoService(accountRepo, ledger, clock, notifications, templates)
+	payees := service.NewPayeeService(accountRepo, ledger, clock)
+	risk := service.NewRiskService(cfg.Risk, accountRepo, sms, payees, flags, clock, notifications, templates)
+	alertRules := service.NewAlertRuleService(accountRepo, ledger, clock, notifications, templates)
+	budgets := service.NewBudgetService(accountRepo, ledger, clock, notifications, templates)
+	favorites := service.NewTransferTemplateService(accountRepo, clock)
+	support := service.NewSupportChatService(accountRepo, clock, notifications, templates)
+	// WebSocket chat 指令：账户连接发送客户消息，管理员连接回复指定会话
+	hub.SetChatHandler(func(accountID string, admin bool, cmd ws.ClientMessage) error {
+		if admin {
+			_, err := support.Reply(cmd.ConversationID, service.SupportReplyRequest{Agent: cmd.Agent, Text: cmd.Text})
+			return err
+		}
+		_, err := support.Send(service.SupportChatRequest{AccountID: accountID, Text: cmd.Text})
+		return err
+	})
+	service.NewFirehoseService(ledger, fx, notifications) // 管理员交易流水实时推送（订阅总账，无需对外暴露）
+	audit := service.NewAuditService(clock)
+	customers := service.NewCustomerService(customerRepo, accountRepo, sms, emails, audit, clock, notifications, templates)
+	aliases := service.NewAliasService(accountRepo, sms, emails, audit, clock, notifications, templates)
+	onboarding := service.NewOnboardingService(accountRepo, customers, fx, products, promos, clock)
+	seeder := service.NewSeedService(accountRepo, customers, ledger, fx, products, clock)
+	importer := service.NewAccountImportService(accountRepo, customers, ledger, fx, products, clock)
+	chaos := service.NewChaosService(cfg.Chaos, accountRepo, hub)
+	accounts := service.NewAccountService(accountRepo, ledger, pricing, promos, loyalty, credit, sagas, audit, notifications, emails, templates)
+	calendar := service.NewCalendarService(cfg.Calendar, clock)
+	announcements := service.NewAnnouncementService(notifications, clock)
+	maintenance := service.NewMaintenanceService(announcements, clock)
+	leader := service.NewLeaderElector(cfg.Leader)
+	loginGuard := service.NewLoginGuardService(cfg.LoginGuard, accountRepo, clock, notifications, templates)
+	passwords := service.NewPasswordService(cfg.Password, accountRepo, loginGuard, sms, emails, clock, notifications, templates)
+	sessions := service.NewSessionService(cfg.Session, accountRepo, accounts, loginGuard, passwords, hub, clock)
+	timeline := service.NewTimelineService(ledger, risk, audit, notifications)
+	// WebSocket 以 ?token= 建立的连接绑定到登录会话
+	hub.SetAuthenticator(func(token string) (string, string, error) {
+		session, err := sessions.Authenticate(token)
+		return session.AccountID, session.SessionID, err
+	})
+	treasury := service.NewTreasuryService(cfg.Treasury, ledger, clock)
+	rtgs := service.NewRTGSService(cfg.RTGS, ledger, clock)
+	clearingHouse := service.NewClearingHouseService(cfg.Netting, rtgs, clock)
+	interbank := service.NewInterbankService(cfg.Clearing, accountRepo, ledger, pricing, credit, notifications, emails, templates, clock, calendar, treasury, rtgs, clearingHouse, breakers.Get(service.BREAKER_INTERBANK))
+	teller := service.NewTellerService(accountRepo, ledger, promos, notifications, templates)
+	statements := service.NewStatementService(accountRepo, ledger, calendar, clock, emails)
+	payments := service.NewPaymentService(accountRepo, accounts, interbank)
+	openBanking := service.NewOpenBankingService(accountRepo, accounts, clock)
+	dormancy := service.NewDormancyService(cfg.Dormancy, accountRepo, ledger, audit, clock, notifications, emails, templates)
+	holds := service.NewHoldService(accountRepo, audit, clock, notifications, templates)
+	suspense := service.NewSuspenseService(accountRepo, ledger, audit, clock, notifications, templates, rtgs)
+	cashback := service.NewCashbackService(cfg.Cashback, accountRepo, ledger, fx, clock, notifications, templates)
+	cards := service.NewCardService(accountRepo, ledger, cashback, credit, holds, clock, notifications, templates)
+	loans := service.NewLoanService(cfg.Loan, accountRepo, ledger, credit, sagas, clock, notifications, templates)
+	directDebits := service.NewDirectDebitService(cfg.DirectDebit, accountRepo, ledger, credit, clock, notifications, templates)
+	payroll := service.NewPayrollService(accountRepo, ledger, clock, notifications, templates)
+	acquiring := service.NewAcquiringService(cfg.Acquiring, accountRepo, ledger, cashback, credit, clock, notifications, templates)
+	settlements := service.NewSettlementService(journalRepo, interbank, clock)
+	regulatory := service.NewRegulatoryService(cfg.Regulatory, accountRepo, journalRepo, fx, clock)
+	stats := service.NewStatsService(accountRepo, journalRepo, clock)
+	cashflow := service.NewCashflowService(accountRepo, journalRepo)
+	recurring := service.NewRecurringService(accountRepo, ledger, clock)
+	scheduled := service.NewScheduledTransferService(accountRepo, accounts, calendar, audit, clock)
+	upcoming := service.NewPaymentCalendarService(accountRepo, loans, scheduled, recurring, calendar, clock)
+
+	// 日终任务（按注册顺序执行）
+	eod := service.NewEODService(clock)
+	eod.AddJob("定时转账", scheduled.Run)
+	eod.AddJob("利息计提", products.Accrue)
+	eod.AddJob("消费返现入账", cashback.Post)
+	eod.AddJob("贷款扣款", loans.Collect)
+	eod.AddJob("生成对账单", statements.Generate)
+	eod.AddJob("月度对账单送达", statements.GenerateMonthly)
+	eod.AddJob("生成清算文件", settlements.Generate)
+	eod.AddJob("对账检查", ledger.Reconcile)
+	eod.AddJob("休眠账户识别", dormancy.Scan)
+	eod.AddJob("信用评分更新", credit.Update)
+	periods := service.NewPeriodCloseService(ledger, calendar, eod, clock)
+	eod.AddJob("月末结账", periods.CloseMonthEnd)
+	snapshots := service.NewSnapshotService(cfg.SnapshotDir, accountRepo, customerRepo, journalRepo, holds, interbank, clearingHouse, rtgs, loans, directDebits,
+		scheduled, cards, loyalty, cashback, products, customers, notifications, periods, eod, clock)
+
+	teller.PrintTestTellers()
+	ledger.RecordOpeningBalances()
+	if cfg.Seed.Count > 0 {
+		if _, err := seeder.Seed(cfg.Seed); err != nil {
+			log.Printf("模拟数据生成失败: %v", err)
+		}
+	}
+
+	h := handler.New(handler.Deps{
+		Accounts:      accounts,
+		Ledger:        ledger,
+		FX:            fx,
+		Interbank:     interbank,
+		Teller:        teller,
+		Statements:    statements,
+		EOD:           eod,
+		Payments:      payments,
+		OpenBanking:   openBanking,
+		Cards:         cards,
+		Cashback:      cashback,
+		Loyalty:       loyalty,
+		Credit:        credit,
+		Loans:         loans,
+		DirectDebits:  directDebits,
+		Payroll:       payroll,
+		Acquiring:     acquiring,
+		Settlements:   settlements,
+		Regulatory:    regulatory,
+		Tax:           tax,
+		Calendar:      calendar,
+		Stats:         stats,
+		Cashflow:      cashflow,
+		Recurring:     recurring,
+		Upcoming:      upcoming,
+		Periods:       periods,
+		Notifications: notifications,
+		Emails:        emails,
+		SMS:           sms,
+		Templates:     templates,
+		Holds:         holds,
+		Suspense:      suspense,
+		Treasury:      treasury,
+		RTGS:          rtgs,
+		ClearingHouse: clearingHouse,
+		Pricing:       pricing,
+		Products:      products,
+		Dormancy:      dormancy,
+		Promos:        promos,
+		Onboarding:    onboarding,
+		Customers:     customers,
+		Audit:         audit,
+		Sessions:      sessions,
+		LoginGuard:    loginGuard,
+		Passwords:     passwords,
+		Announcements: announcements,
+		AlertRules:    alertRules,
+		Budgets:       budgets,
+		Favorites:     favorites,
+		Scheduled:     scheduled,
+		Aliases:       aliases,
+		Payees:        payees,
+		Risk:          risk,
+		Timeline:      timeline,
+		Support:       support,
+		Seeder:        seeder,
+		Importer:      importer,
+		Chaos:         chaos,
+		Maintenance:   maintenance,
+		Leader:        leader,
+		Breakers:      breakers,
+		Flags:         flags,
+		Sagas:         sagas,
+		Snapshots:     snapshots,
+		Clock:         clock,
+		Hub:           hub,
+	})
+
+	// 路由注册
+	mux := http.NewServeMux()
+
+	// 静态文件服务（解决 indexnew.html 404 问题）
+	fileServer := http.FileServer(http.Dir(cfg.StaticDir))
+	mux.Handle("/", http.StripPrefix("/", fileServer))
+
+	// API 与 WebSocket 路由
+	h.Register(mux)
+
+	return &Server{
+		cfg:           cfg,
+		mux:           mux,
+		hub:           hub,
+		fx:            fx,
+		interbank:     interbank,
+		holds:         holds,
+		announcements: announcements,
+		eod:           eod,
+		chaos:         chaos,
+		maintenance:   maintenance,
+		leader:        leader,
+		handler:       h,
+	}
+}
+
+// 启动后台任务并监听 HTTP 请求（阻塞直至服务退出）
+func (s *Server) Run() error {
+	// 后台任务
+	go s.hub.Run()         // WebSocket 消息分发
+	go s.fx.RunFeed()      // 汇率行情（按配置的汇率源定时刷新）
+	go s.chaos.Run()       // 故障注入：定时断线风暴
+	go s.maintenance.Run() // 维护窗口按时开始与结束
+	go s.leader.Run()      // 多实例选主
+
+	// 以下任务多实例部署时只在主节点执行
+	go s.interbank.Run(s.leader.IsLeader)     // 模拟跨行清算系统
+	go s.holds.Run(s.leader.IsLeader)         // 到期冻结自动解除
+	go s.announcements.Run(s.leader.IsLeader) // 广播公告定时发布与过期
+	go s.eod.RunScheduler(s.leader.IsLeader)  // 日终调度（跟随模拟时钟）
+	if s.cfg.ISO8583Addr != "" {
+		go s.handler.RunISO8583Listener(s.cfg.ISO8583Addr) // ISO 8583 卡交易接口（可选）
+	}
+
+	// 启动 HTTP 服务
+	server := &http.Server{
+		Addr:         ":" + s.cfg.Port,
+		Handler:      handler.WithCORS(handler.WithCompression(s.cfg.Compression)(handler.WithContentNegotiation(s.handler.WithChaos(s.handler.WithMaintenance(s.handler.WithSession(s.mux)))))),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+
+	log.Printf("服务启动成功，访问地址: http://localhost:%s", s.cfg.Port)
+	log.Println("=" + strings.Repeat("-", 50) + "=")
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,687 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 存款请求结构体
+type DepositRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"amount"`
+}
+
+// 转账请求结构体
+type TransferRequest struct {
+	FromAccount string  `json:"fromAccount" validate:"required,account"`
+	ToAccount   string  `json:"toAccount" validate:"required,account"`
+	ToAlias     string  `json:"toAlias"` // 收款别名（手机号或邮箱，可代替 ToAccount，由接口层解析）
+	Amount      float64 `json:"amount" validate:"amount"`
+	QuoteID     string  `json:"quoteId"` // 转账报价 ID（可选），引用后按报价锁定的手续费与汇率执行
+	FromVersion int64   `json:"-"`       // 转出账户期望版本（If-Match），0 表示不校验
+	OTPCode     string  `json:"otpCode"` // 短信验证码（风控要求验证时填写，由接口层校验）
+
+	model.Remittance // 附言、端到端参考号、用途代码（可选）
+}
+
+// 存款结果
+type DepositResult struct {
+	Account    model.Account // 存款后的账户
+	OldBalance float64
+}
+
+// 账户服务：账户查询、存款与行内转账
+type AccountService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	pricing   *PricingService
+	promos    *PromoService
+	loyalty   *LoyaltyService
+	credit    *CreditService
+	sagas     *SagaService
+	audit     *AuditService
+	notifier  Notifier
+	mailer    Mailer
+	templates *TemplateRegistry
+}
+
+func NewAccountService(accounts *repository.AccountRepository, ledger *LedgerService, pricing *PricingService, promos *PromoService, loyalty *LoyaltyService, credit *CreditService, sagas *SagaService, audit *AuditService, notifier Notifier, mailer Mailer, templates *TemplateRegistry) *AccountService {
+	return &AccountService{accounts: accounts, ledger: ledger, pricing: pricing, promos: promos, loyalty: loyalty, credit: credit, sagas: sagas, audit: audit, notifier: notifier, mailer: mailer, templates: templates}
+}
+
+// 查询账户
+func (s *AccountService) Get(accountID string) (model.Account, error) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return model.Account{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	account.LoyaltyPoints = s.loyalty.Balance(accountID)
+	return account, nil
+}
+
+// 批量查询账户请求结构体（单次最多 50 个账户）
+type AccountBatchRequest struct {
+	AccountIDs []string `json:"accountIds" validate:"required,max=50"`
+}
+
+// 账户摘要（批量查询结果，不含联系方式等客户资料）
+type AccountSummary struct {
+	AccountID        string  `json:"accountId"`
+	UserName         string  `json:"userName"`
+	Type             string  `json:"accountType"`
+	Currency         string  `json:"currency"`
+	Status           string  `json:"status"`
+	Balance          float64 `json:"balance"`
+	AvailableBalance float64 `json:"availableBalance"`
+	LoyaltyPoints    int64   `json:"loyaltyPoints"`
+	Version          int64   `json:"version"`
+}
+
+// 批量查询中单个账户的结果：成功时返回账户摘要，失败时返回错误码与原因（不影响其他账户）
+type AccountBatchItem struct {
+	AccountID string          `json:"accountId"`
+	Code      int             `json:"code"`
+	Error     string          `json:"error,omitempty"`
+	Account   *AccountSummary `json:"account,omitempty"`
+}
+
+// 批量查询账户摘要：按请求顺序返回（重复的账号只返回一次），callerID 为当前登录账户，
+// 只能查询本账户及同一客户名下的账户，账户不存在或无权查看时在对应条目中返回错误
+func (s *AccountService) BatchGet(callerID string, req AccountBatchRequest) ([]AccountBatchItem, error) {
+	if err := model.Validate(req); err != nil {
+		return nil, err
+	}
+	caller, exists := s.accounts.Get(callerID)
+	if !exists {
+		return nil, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "当前登录账户不存在")
+	}
+
+	items := make([]AccountBatchItem, 0, len(req.AccountIDs))
+	seen := make(map[string]bool, len(req.AccountIDs))
+	for _, id := range req.AccountIDs {
+		id = strings.TrimSpace(id)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item := AccountBatchItem{AccountID: id, Code: model.CODE_SUCCESS}
+		account, exists := s.accounts.Get(id)
+		switch {
+		case !model.ValidAccountID(id):
+			item.Code, item.Error = model.CODE_PARAM_ERROR, "账号应为 1~19 位数字"
+		case !exists:
+			item.Code, item.Error = model.CODE_ACCOUNT_NOT_EXIST, "账户不存在"
+		case id != caller.AccountID && (caller.CustomerID == "" || account.CustomerID != caller.CustomerID):
+			item.Code, item.Error = model.CODE_NO_PERMISSION, "无权查看该账户"
+		default:
+			item.Account = &AccountSummary{
+				AccountID:        account.AccountID,
+				UserName:         account.UserName,
+				Type:             account.Type,
+				Currency:         account.Currency,
+				Status:           account.Status,
+				Balance:          account.Balance,
+				AvailableBalance: account.Available(),
+				LoyaltyPoints:    s.loyalty.Balance(account.AccountID),
+				Version:          account.Version,
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// 存款（乐观并发：版本冲突时自动重试，expectedVersion 非 0 时按条件更新）
+func (s *AccountService) Deposit(req DepositRequest, expectedVersion int64) (DepositResult, error) {
+	// 参数校验
+	if err := model.Validate(req); err != nil {
+		return DepositResult{}, err
+	}
+
+	var oldBalance float64
+	account, err := s.accounts.Update(req.AccountID, expectedVersion, func(account *model.Account) error {
+		// 检查账户状态（休眠账户需先激活）
+		if err := dormantError(*account); err != nil {
+			return err
+		}
+		if account.Status != "normal" {
+			return model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法存款")
+		}
+		// 记录操作前余额
+		oldBalance = account.Balance
+		account.Balance = model.RoundAmount(account.Balance + req.Amount)
+		return nil
+	}, func(account model.Account) {
+		s.ledger.Record(model.Transaction{
+			AccountID:    req.AccountID,
+			Type:         "deposit",
+			Direction:    "credit",
+			Amount:       req.Amount,
+			BalanceAfter: account.Balance,
+			Description:  "存款",
+		})
+	})
+	if err != nil {
+		return DepositResult{}, err
+	}
+
+	// 发送 WebSocket 通知（实时更新余额）
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+
+	// 发送交易提醒
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_DEPOSIT, map[string]interface{}{
+		"Amount":  req.Amount,
+		"Balance": account.Balance,
+	}))
+
+	// 终端提示：存款操作详情（高亮显示金额）
+	log.Println("\n[💰 存款操作]")
+	log.Printf("操作时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", req.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("存款金额: \033[1;32m%.2f 元\033[0m", req.Amount) // 绿色高亮
+	log.Printf("操作前余额: %.2f 元", oldBalance)
+	log.Printf("操作后余额: \033[1;36m%.2f 元\033[0m", account.Balance) // 青色高亮
+	log.Printf("操作状态: \033[1;32m成功\033[0m")                       // 绿色高亮
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	// 存款匹配奖励（另行入账，不影响本次存款结果）
+	s.promos.OnDeposit(account.AccountID, req.Amount)
+
+	return DepositResult{Account: account, OldBalance: oldBalance}, nil
+}
+
+// 执行行内转账（接口、开放银行与批量支付共用），返回接口数据
+func (s *AccountService) Transfer(req TransferRequest) (map[string]interface{}, error) {
+	// 参数校验
+	if err := model.Validate(req); err != nil {
+		return nil, err
+	}
+
+	if req.FromAccount == req.ToAccount {
+		return nil, model.NewError(model.CODE_PARAM_ERROR, "不能向自己转账")
+	}
+
+	if err := validateRemittance(&req.Remittance); err != nil {
+		return nil, err
+	}
+
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	// 检查转出账户
+	fromAccount, fromExists := s.accounts.Find(req.FromAccount)
+	if !fromExists {
+		return nil, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "转出账户不存在")
+	}
+
+	// 条件转账：转出账户版本须与 If-Match 一致
+	if req.FromVersion != 0 && fromAccount.Version != req.FromVersion {
+		return nil, model.NewError(model.CODE_VERSION_CONFLICT, "转出账户已被修改，请刷新后重试")
+	}
+
+	// 检查转出账户状态（休眠账户需先激活）
+	if err := dormantError(fromAccount); err != nil {
+		return nil, err
+	}
+	if fromAccount.Status != "normal" {
+		return nil, model.NewError(model.CODE_ACCOUNT_FROZEN, "转出账户已冻结，无法转账")
+	}
+
+	// 检查收款账户
+	toAccount, toExists := s.accounts.Find(req.ToAccount)
+	if !toExists {
+		// 终端提示：转账失败（收款账户不存在）
+		log.Println("\n[❌ 转账操作 - 失败]")
+		log.Printf("操作时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+		log.Printf("转出账户ID: %s", req.FromAccount)
+		log.Printf("转出用户名: %s", fromAccount.UserName)
+		log.Printf("收款账户ID: %s", req.ToAccount)
+		log.Printf("转账金额: %.2f 元", req.Amount)
+		log.Printf("失败原因: 收款账户不存在")
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		return nil, model.NewError(model.CODE_TARGET_ACCOUNT_ABNORMAL, "收款账户不存在")
+	}
+
+	// 检查收款账户状态
+	if toAccount.Status != "normal" {
+		// 终端提示：转账失败（收款账户异常）
+		log.Println("\n[❌ 转账操作 - 失败]")
+		log.Printf("操作时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+		log.Printf("转出账户ID: %s", req.FromAccount)
+		log.Printf("转出用户名: %s", fromAccount.UserName)
+		log.Printf("收款账户ID: %s", req.ToAccount)
+		log.Printf("收款用户名: %s", toAccount.UserName)
+		log.Printf("转账金额: %.2f 元", req.Amount)
+		log.Printf("失败原因: 收款账户状态异常（%s）", toAccount.Status)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		return nil, model.NewError(model.CODE_TARGET_ACCOUNT_ABNORMAL, "收款账户状态异常")
+	}
+
+	// 记录操作前余额
+	fromOldBalance := fromAccount.Balance
+	toOldBalance := toAccount.Balance
+
+	// 确定手续费与汇率：引用报价时按报价锁定，否则按当前费率与汇率（含点差）计算
+	pricing, err := s.pricing.Resolve(req.QuoteID, QuoteRequest{
+		FromAccount: req.FromAccount,
+		ToAccount:   req.ToAccount,
+		Amount:      req.Amount,
+	}, fromAccount.Currency, toAccount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	// 产品规则：期限、限额、最低余额（拒绝转账或追加低余额管理费）
+	if err := s.pricing.ApplyProductRules(&pricing, fromAccount); err != nil {
+		return nil, err
+	}
+	creditAmount := pricing.CreditAmount
+	fxRate := pricing.FXRate
+	fee := pricing.TotalFee
+
+	// 检查可用余额是否充足（扣除冻结金额）
+	if fromAccount.Available() < pricing.TotalDebit {
+		s.credit.RecordNSF(req.FromAccount)
+
+		// 终端提示：转账失败（余额不足）
+		log.Println("\n[❌ 转账操作 - 失败]")
+		log.Printf("操作时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+		log.Printf("转出账户ID: %s", req.FromAccount)
+		log.Printf("转出用户名: %s", fromAccount.UserName)
+		log.Printf("收款账户ID: %s", req.ToAccount)
+		log.Printf("转账金额: %.2f 元（含手续费 %.2f 元）", pricing.TotalDebit, fee)
+		log.Printf("当前余额: %.2f 元（可用 %.2f 元）", fromAccount.Balance, fromAccount.Available())
+		log.Printf("失败原因: 余额不足")
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		return nil, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "余额不足，无法完成转账")
+	}
+
+	// 执行转账：扣款、入账分步写入，入账失败时编排服务自动退回扣款，全部写入成功后再记账
+	sagaType := SAGA_TYPE_TRANSFER
+	if fxRate != 0 {
+		sagaType = SAGA_TYPE_FX_TRANSFER
+	}
+	_, err = s.sagas.Run(sagaType, req.FromAccount+"→"+req.ToAccount, s.accounts, SagaStep{
+		Name: "debit",
+		Action: func() error {
+			if err := s.accounts.CheckWrite(); err != nil {
+				return err
+			}
+			fromAccount.Balance = model.RoundAmount(fromAccount.Balance - pricing.TotalDebit)
+			fromAccount = s.accounts.Save(fromAccount)
+			return nil
+		},
+		Compensate: func() error { return s.adjustBalance(req.FromAccount, pricing.TotalDebit) },
+	}, SagaStep{
+		Name: "credit",
+		Action: func() error {
+			if err := s.accounts.CheckWrite(); err != nil {
+				return err
+			}
+			toAccount.Balance = model.RoundAmount(toAccount.Balance + creditAmount)
+			toAccount = s.accounts.Save(toAccount)
+			return nil
+		},
+		Compensate: func() error { return s.adjustBalance(req.ToAccount, -creditAmount) },
+	}, SagaStep{
+		Name: "post",
+		Action: func() error {
+			s.recordTransfer(req, pricing, fromAccount, toAccount)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 构造返回数据
+	responseData := map[string]interface{}{
+		"fromAccount": req.FromAccount,
+		"toAccount":   req.ToAccount,
+		"amount":      req.Amount,
+		"newBalance":  fromAccount.Balance,
+		"fee":         fee,
+		"totalDebit":  pricing.TotalDebit,
+		"time":        time.Now().Format("2006-01-02 15:04:05"),
+	}
+	for key, value := range map[string]string{
+		"quoteId":     req.QuoteID,
+		"memo":        req.Memo,
+		"endToEndId":  req.EndToEndID,
+		"purposeCode": req.PurposeCode,
+	} {
+		if value != "" {
+			responseData[key] = value
+		}
+	}
+	if fxRate != 0 {
+		responseData["fromCurrency"] = fromAccount.Currency
+		responseData["toCurrency"] = toAccount.Currency
+		responseData["fxRate"] = fxRate
+		responseData["creditAmount"] = creditAmount
+	}
+
+	// 发送 WebSocket 通知（更新转出账户余额）
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  fromAccount.AccountID,
+		NewBalance: fromAccount.Balance,
+	})
+
+	// 发送交易提醒
+	s.notifier.Send(s.templates.Alert("transactionAlert", fromAccount, EVENT_TRANSFER_OUT, map[string]interface{}{
+		"Amount":       req.Amount,
+		"Balance":      fromAccount.Balance,
+		"Counterparty": toAccount.UserName,
+		"Fee":          fee,
+		"Memo":         req.Memo,
+		"EndToEndID":   req.EndToEndID,
+		"PurposeCode":  req.PurposeCode,
+	}))
+
+	// 大额转账邮件提醒
+	if req.Amount >= s.mailer.LargeTransferThreshold() {
+		s.mailer.Notify(fromAccount, EVENT_LARGE_TRANSFER, map[string]interface{}{
+			"Amount":       req.Amount,
+			"Balance":      fromAccount.Balance,
+			"Counterparty": toAccount.UserName + "（" + toAccount.AccountID + "）",
+			"Time":         s.ledger.clock.Now().Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	// 终端提示：转账操作详情（高亮显示关键信息）
+	log.Println("\n[🔄 转账操作]")
+	log.Printf("操作时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("转出账户ID: %s", req.FromAccount)
+	log.Printf("转出用户名: %s", fromAccount.UserName)
+	log.Printf("收款账户ID: %s", req.ToAccount)
+	log.Printf("收款用户名: %s", toAccount.UserName)
+	log.Printf("转账金额: \033[1;31m%.2f 元\033[0m", req.Amount) // 红色高亮
+	log.Printf("转出账户 - 操作前: %.2f 元 → 操作后: \033[1;36m%.2f 元\033[0m", fromOldBalance, fromAccount.Balance)
+	if fxRate != 0 {
+		log.Printf("货币兑换: %s → %s，成交汇率: %.6f，入账金额: %.2f %s",
+			fromAccount.Currency, toAccount.Currency, fxRate, creditAmount, toAccount.Currency)
+	}
+	if fee != 0 {
+		log.Printf("手续费: %.2f %s", fee, fromAccount.Currency)
+	}
+	if req.QuoteID != "" {
+		log.Printf("引用报价: %s", req.QuoteID)
+	}
+	log.Printf("收款账户 - 操作前: %.2f 元 → 操作后: \033[1;36m%.2f 元\033[0m", toOldBalance, toAccount.Balance)
+	log.Printf("操作状态: \033[1;32m成功\033[0m") // 绿色高亮
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return responseData, nil
+}
+
+// 记录转账流水（双边流水与手续费），并核销引用的报价（调用方需持有账户写锁）
+func (s *AccountService) recordTransfer(req TransferRequest, pricing TransferQuote, fromAccount, toAccount model.Account) {
+	debitLeg := model.Transaction{
+		AccountID:    req.FromAccount,
+		Type:         "transfer_out",
+		Direction:    "debit",
+		Amount:       req.Amount,
+		BalanceAfter: fromAccount.Balance + pricing.TotalFee,
+		Counterparty: req.ToAccount,
+		Description:  "转账至 " + toAccount.UserName,
+		Remittance:   req.Remittance,
+	}
+	creditLeg := model.Transaction{
+		AccountID:    req.ToAccount,
+		Type:         "transfer_in",
+		Direction:    "credit",
+		Amount:       pricing.CreditAmount,
+		BalanceAfter: toAccount.Balance,
+		Counterparty: req.FromAccount,
+		Description:  "来自 " + fromAccount.UserName + " 的转账",
+		Remittance:   req.Remittance,
+	}
+	if pricing.FXRate != 0 {
+		debitLeg.FXRate, debitLeg.CounterAmount, debitLeg.CounterCurrency = pricing.FXRate, pricing.CreditAmount, toAccount.Currency
+		creditLeg.FXRate, creditLeg.CounterAmount, creditLeg.CounterCurrency = pricing.FXRate, req.Amount, fromAccount.Currency
+	}
+	debitLeg = s.ledger.Record(debitLeg)
+	s.ledger.Record(creditLeg)
+	recordFees(s.ledger, pricing, fromAccount, debitLeg.TxID)
+	s.pricing.Consume(req.QuoteID)
+}
+
+// 补偿：按差额调整账户余额，撤销尚未记账的余额变动（调用方需持有账户写锁）
+func (s *AccountService) adjustBalance(accountID string, delta float64) error {
+	if err := s.accounts.CheckWrite(); err != nil {
+		return err
+	}
+	account, exists := s.accounts.Find(accountID)
+	if !exists {
+		return model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	account.Balance = model.RoundAmount(account.Balance + delta)
+	s.accounts.Save(account)
+	return nil
+}
+
+// 账户状态变更请求结构体
+type AccountStatusRequest struct {
+	AccountID string `json:"accountId" validate:"required,account"`
+	Status    string `json:"status" validate:"oneof=normal frozen"`
+	Reason    string `json:"reason"`
+}
+
+// 账户状态变更的审计操作（冻结/解冻、转为休眠、激活），detail 含 from/to/reason
+const AUDIT_ACCOUNT_STATUS_CHANGED = "account.status.changed"
+
+// 冻结/解冻账户（管理员），冻结时推送安全提醒并发送邮件通知
+func (s *AccountService) SetStatus(req AccountStatusRequest) (model.Account, error) {
+	if err := model.Validate(req); err != nil {
+		return model.Account{}, err
+	}
+	if req.Status == "frozen" && strings.TrimSpace(req.Reason) == "" {
+		return model.Account{}, model.NewError(model.CODE_PARAM_ERROR, "冻结账户须填写原因")
+	}
+
+	var oldStatus string
+	account, err := s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+		oldStatus = account.Status
+		if oldStatus == ACCOUNT_STATUS_DORMANT && req.Status == "normal" {
+			return model.NewError(model.CODE_PARAM_ERROR, "休眠账户请通过账户激活接口办理")
+		}
+		account.Status = req.Status
+		return nil
+	}, nil)
+	if err != nil {
+		return model.Account{}, err
+	}
+	if oldStatus == req.Status {
+		return account, nil
+	}
+
+	s.audit.Record(AUDIT_ACTOR_ADMIN, AUDIT_ACCOUNT_STATUS_CHANGED, account.AccountID, map[string]string{
+		"from":   oldStatus,
+		"to":     account.Status,
+		"reason": req.Reason,
+	})
+
+	now := s.ledger.clock.Now().Format("2006-01-02 15:04:05")
+	event := EVENT_ACCOUNT_UNFROZEN
+	vars := map[string]interface{}{"Reason": req.Reason, "Time": now}
+	if req.Status == "frozen" {
+		event = EVENT_ACCOUNT_FROZEN
+		s.mailer.Notify(account, EVENT_ACCOUNT_FROZEN, vars)
+	}
+	s.notifier.Send(s.templates.Alert("securityAlert", account, event, vars))
+
+	// 终端提示：账户状态变更
+	log.Println("\n[🔒 账户状态变更]")
+	log.Printf("操作时间: %s", now)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("状态: %s → %s", oldStatus, account.Status)
+	if req.Reason != "" {
+		log.Printf("原因: %s", req.Reason)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return account, nil
+}
+
+// 人工调账请求结构体
+type BalanceAdjustRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Amount    float64 `json:"amount"` // 正数调增，负数调减
+	Reason    string  `json:"reason" validate:"required,max=200"`
+}
+
+// 人工调账（管理员）：按调整金额记一条 adjustment 流水，对方记入待处理挂账；调减不得超过可用余额
+func (s *AccountService) Adjust(req BalanceAdjustRequest) (DepositResult, error) {
+	req.Amount = model.RoundAmount(req.Amount)
+	if err := model.Validate(req); err != nil {
+		return DepositResult{}, err
+	}
+	if req.Amount == 0 {
+		return DepositResult{}, model.NewError(model.CODE_PARAM_ERROR, "调整金额不能为0")
+	}
+
+	direction, amount := "credit", req.Amount
+	if req.Amount < 0 {
+		direction, amount = "debit", -req.Amount
+	}
+	var oldBalance float64
+	account, err := s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+		if direction == "debit" && account.Available() < amount {
+			return model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "可用余额不足，无法调减")
+		}
+		oldBalance = account.Balance
+		account.Balance = model.RoundAmount(account.Balance + req.Amount)
+		return nil
+	}, func(account model.Account) {
+		s.ledger.Record(model.Transaction{
+			AccountID:    req.AccountID,
+			Type:         "adjustment",
+			Direction:    direction,
+			Amount:       amount,
+			BalanceAfter: account.Balance,
+			Description:  "人工调账：" + req.Reason,
+		})
+	})
+	if err != nil {
+		return DepositResult{}, err
+	}
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+
+	// 终端提示：人工调账
+	log.Println("\n[🛠️ 人工调账]")
+	log.Printf("操作时间: %s", s.ledger.clock.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("调整金额: \033[1;33m%+.2f %s\033[0m", req.Amount, account.Currency) // 黄色高亮
+	log.Printf("余额: %.2f → %.2f", oldBalance, account.Balance)
+	log.Printf("原因: %s", req.Reason)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return DepositResult{Account: account, OldBalance: oldBalance}, nil
+}
+
+// 账户查询条件（零值表示不限）
+type AccountFilter struct {
+	Status      string   // normal/frozen
+	NamePrefix  string   // 户名前缀
+	MinBalance  *float64 // 余额下限（含）
+	MaxBalance  *float64 // 余额上限（含）
+	CreatedFrom string   // 开户日期下限 YYYY-MM-DD（含）
+	CreatedTo   string   // 开户日期上限 YYYY-MM-DD（含）
+	Sort        string   // accountId（默认）/userName/balance/createAt
+	Desc        bool     // 是否倒序
+	Page        int
+	PageSize    int
+}
+
+// 账户分页结果
+type AccountPage struct {
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+	Total    int             `json:"total"`
+	Items    []model.Account `json:"items"`
+}
+
+// 可排序字段
+var accountSortFields = map[string]func(a, b model.Account) bool{
+	"accountId": func(a, b model.Account) bool { return a.AccountID < b.AccountID },
+	"userName":  func(a, b model.Account) bool { return a.UserName < b.UserName },
+	"balance":   func(a, b model.Account) bool { return a.Balance < b.Balance },
+	"createAt":  func(a, b model.Account) bool { return a.CreateAt < b.CreateAt },
+}
+
+// 按条件筛选、排序并分页查询账户（管理员）
+func (s *AccountService) Search(filter AccountFilter) (AccountPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = "accountId"
+	}
+	less, ok := accountSortFields[filter.Sort]
+	if !ok {
+		return AccountPage{}, model.NewError(model.CODE_PARAM_ERROR, "不支持的排序字段: "+filter.Sort)
+	}
+	if filter.Status != "" && filter.Status != "normal" && filter.Status != "frozen" && filter.Status != ACCOUNT_STATUS_DORMANT {
+		return AccountPage{}, model.NewError(model.CODE_PARAM_ERROR, "账户状态应为 normal、frozen 或 dormant")
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+
+	matched := make([]model.Account, 0)
+	for _, account := range s.accounts.List() {
+		switch {
+		case filter.Status != "" && account.Status != filter.Status:
+		case filter.NamePrefix != "" && !strings.HasPrefix(account.UserName, filter.NamePrefix):
+		case filter.MinBalance != nil && account.Balance < *filter.MinBalance:
+		case filter.MaxBalance != nil && account.Balance > *filter.MaxBalance:
+		case filter.CreatedFrom != "" && account.CreateAt < filter.CreatedFrom:
+		case filter.CreatedTo != "" && account.CreateAt > filter.CreatedTo:
+		default:
+			matched = append(matched, account)
+		}
+	}
+
+	// 列表已按账号排序，稳定排序保证同值时按账号排列
+	sort.SliceStable(matched, func(i, j int) bool {
+		if filter.Desc {
+			return less(matched[j], matched[i])
+		}
+		return less(matched[i], matched[j])
+	})
+
+	page := AccountPage{Page: filter.Page, PageSize: filter.PageSize, Total: len(matched), Items: make([]model.Account, 0)}
+	if start := (filter.Page - 1) * filter.PageSize; start < len(matched) {
+		end := start + filter.PageSize
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page.Items = matched[start:end]
+		for i := range page.Items {
+			page.Items[i].LoyaltyPoints = s.loyalty.Balance(page.Items[i].AccountID)
+		}
+	}
+	return page, nil
+}
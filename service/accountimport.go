@@ -0,0 +1,228 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 单次最多导入的账户数
+const ACCOUNT_IMPORT_MAX_ROWS = 10000
+
+// 导入文件的必填列
+var accountImportColumns = []string{"id", "name", "balance", "status", "type"}
+
+// 单行导入结果
+type AccountImportRow struct {
+	Row       int      `json:"row"` // 文件行号（表头为第 1 行）
+	AccountID string   `json:"accountId"`
+	OK        bool     `json:"ok"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// 账户导入报告
+type AccountImportReport struct {
+	DryRun       bool               `json:"dryRun"`   // 仅校验，未写入
+	Total        int                `json:"total"`    // 数据行数
+	Valid        int                `json:"valid"`    // 校验通过的行数
+	Imported     int                `json:"imported"` // 实际导入的账户数（试运行为 0）
+	Failed       int                `json:"failed"`   // 校验失败的行数（未导入）
+	TotalBalance float64            `json:"totalBalance"`
+	Rows         []AccountImportRow `json:"rows"`
+}
+
+// 账户导入服务：从其他测试系统导出的 CSV 迁移账户数据
+type AccountImportService struct {
+	accounts  *repository.AccountRepository
+	customers *CustomerService
+	ledger    *LedgerService
+	fx        *FXService
+	products  *ProductService
+	clock     Clock
+}
+
+func NewAccountImportService(accounts *repository.AccountRepository, customers *CustomerService, ledger *LedgerService, fx *FXService, products *ProductService, clock Clock) *AccountImportService {
+	return &AccountImportService{accounts: accounts, customers: customers, ledger: ledger, fx: fx, products: products, clock: clock}
+}
+
+// 按 CSV 导入账户。首行为表头，必须包含 id,name,balance,status,type 列（顺序不限），
+// 可选 currency,email,phone 列。逐行校验，校验通过的行导入、失败的行跳过并在报告中列出原因；
+// dryRun 为 true 时只校验不写入
+func (s *AccountImportService) Import(r io.Reader, dryRun bool) (AccountImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return AccountImportReport{}, model.NewError(model.CODE_PARAM_ERROR, "导入文件解析失败: "+err.Error())
+	}
+	if len(records) < 2 {
+		return AccountImportReport{}, model.NewError(model.CODE_PARAM_ERROR, "导入文件没有数据行")
+	}
+	if len(records)-1 > ACCOUNT_IMPORT_MAX_ROWS {
+		return AccountImportReport{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("单次最多导入 %d 个账户", ACCOUNT_IMPORT_MAX_ROWS))
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	var missing []string
+	for _, name := range accountImportColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return AccountImportReport{}, model.NewError(model.CODE_PARAM_ERROR, "导入文件缺少列: "+strings.Join(missing, ","))
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	now := s.clock.Now()
+	report := AccountImportReport{DryRun: dryRun, Rows: make([]AccountImportRow, 0, len(records)-1)}
+	accounts := make([]model.Account, 0, len(records)-1)
+	seen := make(map[string]int)
+
+	// 校验与写入在同一写锁内完成，避免校验后账户ID被其他开户占用
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	for i, record := range records[1:] {
+		row := AccountImportRow{Row: i + 2, AccountID: field(record, "id")}
+		account, errs := s.parseRow(record, field, now.Format("2006-01-02"))
+		if first, ok := seen[row.AccountID]; ok && row.AccountID != "" {
+			errs = append(errs, fmt.Sprintf("账户ID与第 %d 行重复", first))
+		} else {
+			seen[row.AccountID] = row.Row
+		}
+		row.OK = len(errs) == 0
+		row.Errors = errs
+		report.Rows = append(report.Rows, row)
+		if row.OK {
+			accounts = append(accounts, account)
+			report.TotalBalance += account.Balance
+		}
+	}
+	report.Total = len(report.Rows)
+	report.Valid = len(accounts)
+	report.Failed = report.Total - report.Valid
+	report.TotalBalance = model.RoundAmount(report.TotalBalance)
+	if dryRun {
+		return report, nil
+	}
+
+	for _, account := range accounts {
+		customer := s.customers.Create(CustomerRequest{Name: account.UserName, Phone: account.Phone, Email: account.Email})
+		account.CustomerID = customer.CustomerID
+		s.accounts.Save(account)
+		if account.Balance > 0 {
+			s.ledger.Record(model.Transaction{
+				AccountID:    account.AccountID,
+				Type:         "opening",
+				Direction:    "credit",
+				Amount:       account.Balance,
+				BalanceAfter: account.Balance,
+				Currency:     account.Currency,
+				Description:  "开户余额（数据导入）",
+			})
+		}
+	}
+	report.Imported = len(accounts)
+
+	// 终端提示：批量导入账户
+	log.Println("\n[📥 批量导入账户]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("数据行: %d，导入: %d，失败: %d", report.Total, report.Imported, report.Failed)
+	log.Printf("余额合计: %.2f", report.TotalBalance)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return report, nil
+}
+
+// 校验一行数据并转换为账户（调用方需持有账户锁），返回全部校验错误
+func (s *AccountImportService) parseRow(record []string, field func([]string, string) string, createAt string) (model.Account, []string) {
+	var errs []string
+	account := model.Account{
+		AccountID: field(record, "id"),
+		UserName:  field(record, "name"),
+		Currency:  strings.ToUpper(field(record, "currency")),
+		Status:    strings.ToLower(field(record, "status")),
+		Type:      strings.ToLower(field(record, "type")),
+		Email:     field(record, "email"),
+		Phone:     field(record, "phone"),
+		CreateAt:  createAt,
+		Version:   1,
+	}
+
+	switch {
+	case account.AccountID == "":
+		errs = append(errs, "账户ID不能为空")
+	case !model.ValidAccountID(account.AccountID):
+		errs = append(errs, "账户ID应为 1~19 位数字")
+	default:
+		if _, exists := s.accounts.Find(account.AccountID); exists {
+			errs = append(errs, "账户ID已存在")
+		}
+	}
+
+	if account.UserName == "" {
+		errs = append(errs, "户名不能为空")
+	}
+
+	if raw := field(record, "balance"); raw == "" {
+		errs = append(errs, "余额不能为空")
+	} else if balance, err := strconv.ParseFloat(raw, 64); err != nil {
+		errs = append(errs, "余额格式错误: "+raw)
+	} else if balance < 0 {
+		errs = append(errs, "余额不能为负数")
+	} else {
+		account.Balance = model.RoundAmount(balance)
+	}
+
+	switch account.Status {
+	case "":
+		account.Status = "normal"
+	case "normal", "frozen", ACCOUNT_STATUS_DORMANT:
+	default:
+		errs = append(errs, "账户状态应为 normal、frozen 或 dormant: "+account.Status)
+	}
+
+	productCode := ""
+	switch account.Type {
+	case "":
+		account.Type = ACCOUNT_TYPE_SAVINGS
+		productCode = PRODUCT_SAVINGS
+	case ACCOUNT_TYPE_SAVINGS:
+		productCode = PRODUCT_SAVINGS
+	case ACCOUNT_TYPE_CHECKING:
+		productCode = PRODUCT_CHECKING
+	default:
+		errs = append(errs, "账户类型应为 savings 或 checking: "+account.Type)
+	}
+	if productCode != "" {
+		if _, ok := s.products.Effective(productCode, s.clock.Now()); !ok {
+			errs = append(errs, "产品不存在或尚未生效: "+productCode)
+		} else {
+			account.ProductCode = productCode
+		}
+	}
+
+	if account.Currency == "" {
+		account.Currency = model.BASE_CURRENCY
+	} else if !s.fx.IsSupported(account.Currency) {
+		errs = append(errs, "不支持的币种: "+account.Currency)
+	}
+	return account, errs
+}
@@ -0,0 +1,550 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// POS 交易状态
+const (
+	POS_APPROVED           = "approved"           // 已消费
+	POS_PARTIALLY_REFUNDED = "partially_refunded" // 部分退货
+	POS_REFUNDED           = "refunded"           // 全额退货
+)
+
+// 收单配置
+type AcquiringConfig struct {
+	InterchangeRate float64 // 默认商户手续费率（登记商户未指定费率时使用）
+}
+
+// 商户登记请求结构体
+type MerchantRequest struct {
+	Name            string   `json:"name" validate:"required"`
+	AccountID       string   `json:"accountId" validate:"required,account"` // 商户结算账户（本行账户）
+	MCC             string   `json:"mcc"`                                   // 商户类别码
+	InterchangeRate *float64 `json:"interchangeRate"`                       // 手续费率，为空使用默认费率
+}
+
+// 收单商户
+type Merchant struct {
+	MerchantID      string  `json:"merchantId"`
+	Name            string  `json:"name"`
+	AccountID       string  `json:"accountId"`
+	Currency        string  `json:"currency"`
+	MCC             string  `json:"mcc,omitempty"`
+	InterchangeRate float64 `json:"interchangeRate"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+// POS 消费请求结构体（按卡号或账户ID扣款，金额为商户结算币种）
+type POSPurchaseRequest struct {
+	MerchantID string  `json:"merchantId" validate:"required"`
+	TerminalID string  `json:"terminalId"`
+	PAN        string  `json:"pan"`
+	AccountID  string  `json:"accountId"`
+	Amount     float64 `json:"amount" validate:"amount"`
+	Reference  string  `json:"reference"` // 商户订单号
+}
+
+// POS 退货请求结构体（金额为空表示退还剩余全部金额）
+type POSRefundRequest struct {
+	MerchantID string  `json:"merchantId" validate:"required"`
+	PosTxID    string  `json:"posTxId" validate:"required"`
+	Amount     float64 `json:"amount" validate:"min=0"`
+}
+
+// POS 退货记录
+type POSRefund struct {
+	RefundID  string  `json:"refundId"`
+	Amount    float64 `json:"amount"`
+	FeeRefund float64 `json:"feeRefund"` // 退还商户的手续费
+	Time      string  `json:"time"`
+}
+
+// POS 交易
+type POSTransaction struct {
+	PosTxID        string      `json:"posTxId"`
+	MerchantID     string      `json:"merchantId"`
+	MerchantName   string      `json:"merchantName"`
+	TerminalID     string      `json:"terminalId,omitempty"`
+	AccountID      string      `json:"accountId"`
+	CardSuffix     string      `json:"cardSuffix,omitempty"`
+	Amount         float64     `json:"amount"`
+	Currency       string      `json:"currency"`
+	InterchangeFee float64     `json:"interchangeFee"`
+	NetAmount      float64     `json:"netAmount"` // 商户实收（交易金额扣除手续费）
+	Refunded       float64     `json:"refunded"`
+	Status         string      `json:"status"` // approved/partially_refunded/refunded
+	AuthCode       string      `json:"authCode"`
+	Reference      string      `json:"reference,omitempty"`
+	Date           string      `json:"date"` // 清算日期
+	Time           string      `json:"time"`
+	Refunds        []POSRefund `json:"refunds"`
+}
+
+// 商户日结算汇总
+type MerchantSettlement struct {
+	MerchantID   string  `json:"merchantId"`
+	Date         string  `json:"date"`
+	Currency     string  `json:"currency"`
+	SalesCount   int     `json:"salesCount"`
+	SalesAmount  float64 `json:"salesAmount"`
+	RefundCount  int     `json:"refundCount"`
+	RefundAmount float64 `json:"refundAmount"`
+	Fees         float64 `json:"fees"`      // 手续费净额（已扣除退货退还部分）
+	NetAmount    float64 `json:"netAmount"` // 商户净入账 = 消费 - 退货 - 手续费
+}
+
+// 收单服务：登记商户，POS 消费时扣客户账户并入账商户（扣除手续费），支持退货与按日结算汇总
+type AcquiringService struct {
+	cfg       AcquiringConfig
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	cashback  *CashbackService
+	credit    *CreditService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu           sync.Mutex // 需先于账户锁获取
+	merchants    map[string]*Merchant
+	transactions map[string]*POSTransaction
+	merchantSeq  int64
+	txSeq        int64
+}
+
+func NewAcquiringService(cfg AcquiringConfig, accounts *repository.AccountRepository, ledger *LedgerService, cashback *CashbackService, credit *CreditService, clock Clock, notifier Notifier, templates *TemplateRegistry) *AcquiringService {
+	return &AcquiringService{
+		cfg:          cfg,
+		accounts:     accounts,
+		ledger:       ledger,
+		cashback:     cashback,
+		credit:       credit,
+		clock:        clock,
+		notifier:     notifier,
+		templates:    templates,
+		merchants:    make(map[string]*Merchant),
+		transactions: make(map[string]*POSTransaction),
+	}
+}
+
+// 登记收单商户
+func (s *AcquiringService) Register(req MerchantRequest) (Merchant, error) {
+	if err := model.Validate(req); err != nil {
+		return Merchant{}, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	rate := s.cfg.InterchangeRate
+	if req.InterchangeRate != nil {
+		rate = *req.InterchangeRate
+	}
+	if rate < 0 || rate >= 0.1 {
+		return Merchant{}, model.NewError(model.CODE_PARAM_ERROR, "手续费率应在 0 到 0.1 之间")
+	}
+
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return Merchant{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "结算账户不存在")
+	}
+	if account.Status != "normal" {
+		return Merchant{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "结算账户状态异常")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.merchantSeq++
+	merchant := &Merchant{
+		MerchantID:      fmt.Sprintf("MC%06d", s.merchantSeq),
+		Name:            req.Name,
+		AccountID:       account.AccountID,
+		Currency:        account.Currency,
+		MCC:             strings.TrimSpace(req.MCC),
+		InterchangeRate: rate,
+		CreatedAt:       s.clock.Now().Format("2006-01-02 15:04:05"),
+	}
+	s.merchants[merchant.MerchantID] = merchant
+
+	// 终端提示：商户登记
+	log.Println("\n[🏪 收单商户登记]")
+	log.Printf("商户号: %s", merchant.MerchantID)
+	log.Printf("商户名称: %s", merchant.Name)
+	log.Printf("结算账户: %s（%s）", account.AccountID, account.UserName)
+	log.Printf("手续费率: %.2f%%", merchant.InterchangeRate*100)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *merchant, nil
+}
+
+// 收单商户列表（按商户号排序）
+func (s *AcquiringService) Merchants() []Merchant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Merchant{}
+	for _, merchant := range s.merchants {
+		result = append(result, *merchant)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].MerchantID < result[j].MerchantID })
+	return result
+}
+
+// POS 消费：扣客户账户，商户结算账户入账交易金额并扣收手续费
+func (s *AcquiringService) Purchase(req POSPurchaseRequest) (POSTransaction, error) {
+	if err := model.Validate(req); err != nil {
+		return POSTransaction{}, err
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+	accountID := req.AccountID
+	if accountID == "" {
+		var ok bool
+		if accountID, ok = cards[req.PAN]; !ok {
+			return POSTransaction{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "无效卡号")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merchant, ok := s.merchants[req.MerchantID]
+	if !ok {
+		return POSTransaction{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "商户不存在")
+	}
+	if accountID == merchant.AccountID {
+		return POSTransaction{}, model.NewError(model.CODE_PARAM_ERROR, "不能向本商户结算账户发起消费")
+	}
+
+	s.accounts.Lock()
+	customer, exists := s.accounts.Find(accountID)
+	if exists && customer.Currency != merchant.Currency {
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_PARAM_ERROR, "卡片账户币种与商户结算币种不一致")
+	}
+	switch checkCardAccount(customer, exists, req.Amount) {
+	case "14":
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "无效卡号")
+	case "62":
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "卡片账户状态异常")
+	case "51":
+		s.accounts.Unlock()
+		s.credit.RecordNSF(accountID)
+		return POSTransaction{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "账户可用余额不足")
+	}
+	merchantAccount, exists := s.accounts.Find(merchant.AccountID)
+	if !exists || merchantAccount.Status != "normal" {
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "商户结算账户状态异常")
+	}
+
+	now := s.clock.Now()
+	s.txSeq++
+	tx := &POSTransaction{
+		PosTxID:        fmt.Sprintf("POS%s%06d", now.Format("20060102"), s.txSeq),
+		MerchantID:     merchant.MerchantID,
+		MerchantName:   merchant.Name,
+		TerminalID:     strings.TrimSpace(req.TerminalID),
+		AccountID:      accountID,
+		Amount:         req.Amount,
+		Currency:       merchant.Currency,
+		InterchangeFee: model.RoundAmount(req.Amount * merchant.InterchangeRate),
+		Status:         POS_APPROVED,
+		AuthCode:       fmt.Sprintf("%06d", rand.Intn(1000000)),
+		Reference:      strings.TrimSpace(req.Reference),
+		Date:           now.Format("2006-01-02"),
+		Time:           now.Format("2006-01-02 15:04:05"),
+		Refunds:        []POSRefund{},
+	}
+	tx.NetAmount = model.RoundAmount(tx.Amount - tx.InterchangeFee)
+	cardSuffix := panSuffix(customer.AccountID)
+	if req.PAN != "" {
+		tx.CardSuffix = panSuffix(req.PAN)
+		cardSuffix = tx.CardSuffix
+	}
+
+	// 客户账户扣款
+	customer.Balance = model.RoundAmount(customer.Balance - tx.Amount)
+	customer = s.accounts.Save(customer)
+	s.ledger.Record(model.Transaction{
+		AccountID:    customer.AccountID,
+		Type:         "card_purchase",
+		Direction:    "debit",
+		Amount:       tx.Amount,
+		BalanceAfter: customer.Balance,
+		Counterparty: merchant.Name,
+		Reference:    tx.PosTxID,
+		Description:  "POS 消费 终端" + tx.TerminalID,
+	})
+	s.cashback.Accrue(customer, tx.Amount, tx.PosTxID, merchant.Name)
+
+	// 商户结算账户入账并扣收手续费
+	merchantAccount.Balance = model.RoundAmount(merchantAccount.Balance + tx.NetAmount)
+	merchantAccount = s.accounts.Save(merchantAccount)
+	s.ledger.Record(model.Transaction{
+		AccountID:    merchantAccount.AccountID,
+		Type:         "pos_sale",
+		Direction:    "credit",
+		Amount:       tx.Amount,
+		BalanceAfter: model.RoundAmount(merchantAccount.Balance + tx.InterchangeFee),
+		Counterparty: customer.UserName,
+		Reference:    tx.PosTxID,
+		Description:  "收单入账 终端" + tx.TerminalID,
+	})
+	if tx.InterchangeFee > 0 {
+		s.ledger.Record(model.Transaction{
+			AccountID:    merchantAccount.AccountID,
+			Type:         "interchange_fee",
+			Direction:    "debit",
+			Amount:       tx.InterchangeFee,
+			BalanceAfter: merchantAccount.Balance,
+			Reference:    tx.PosTxID,
+			Description:  fmt.Sprintf("收单手续费（费率 %.2f%%）", merchant.InterchangeRate*100),
+		})
+	}
+	s.accounts.Unlock()
+	s.transactions[tx.PosTxID] = tx
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  customer.AccountID,
+		NewBalance: customer.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", customer, EVENT_CARD_PURCHASE, map[string]interface{}{
+		"Amount":     tx.Amount,
+		"Balance":    customer.Balance,
+		"CardSuffix": cardSuffix,
+		"Merchant":   merchant.Name,
+	}))
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  merchantAccount.AccountID,
+		NewBalance: merchantAccount.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", merchantAccount, EVENT_POS_SALE, map[string]interface{}{
+		"Amount":    tx.Amount,
+		"Fee":       tx.InterchangeFee,
+		"Net":       tx.NetAmount,
+		"Balance":   merchantAccount.Balance,
+		"Reference": tx.PosTxID,
+	}))
+
+	// 终端提示：POS 消费
+	log.Println("\n[🏪 POS 消费]")
+	log.Printf("交易编号: %s（授权码 %s）", tx.PosTxID, tx.AuthCode)
+	log.Printf("商户: %s（%s）终端: %s", merchant.Name, merchant.MerchantID, tx.TerminalID)
+	log.Printf("付款账户: %s（%s）", customer.AccountID, customer.UserName)
+	log.Printf("交易金额: \033[1;33m%.2f %s\033[0m，手续费 %.2f，商户实收 %.2f", tx.Amount, tx.Currency, tx.InterchangeFee, tx.NetAmount) // 黄色高亮
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return copyPOSTransaction(tx), nil
+}
+
+// POS 退货：商户结算账户退还交易金额（按比例退回手续费），原路退回客户账户
+func (s *AcquiringService) Refund(req POSRefundRequest) (POSTransaction, error) {
+	if err := model.Validate(req); err != nil {
+		return POSTransaction{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.transactions[req.PosTxID]
+	if !ok || tx.MerchantID != req.MerchantID {
+		return POSTransaction{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "交易不存在")
+	}
+	remaining := model.RoundAmount(tx.Amount - tx.Refunded)
+	if remaining <= 0 {
+		return POSTransaction{}, model.NewError(model.CODE_PARAM_ERROR, "该笔交易已全额退货")
+	}
+	amount := model.RoundAmount(req.Amount)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return POSTransaction{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("退货金额超过可退金额 %.2f", remaining))
+	}
+
+	// 按比例退回手续费，末笔退货退回剩余手续费
+	feeRefunded := 0.0
+	for _, refund := range tx.Refunds {
+		feeRefunded += refund.FeeRefund
+	}
+	feeRefund := model.RoundAmount(tx.InterchangeFee * amount / tx.Amount)
+	if amount == remaining {
+		feeRefund = model.RoundAmount(tx.InterchangeFee - feeRefunded)
+	}
+	merchantDebit := model.RoundAmount(amount - feeRefund)
+
+	merchant := s.merchants[tx.MerchantID]
+	s.accounts.Lock()
+	merchantAccount, exists := s.accounts.Find(merchant.AccountID)
+	if !exists || merchantAccount.Status != "normal" {
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "商户结算账户状态异常")
+	}
+	if merchantAccount.Available() < merchantDebit {
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "商户结算账户可用余额不足，无法退货")
+	}
+	customer, exists := s.accounts.Find(tx.AccountID)
+	if !exists {
+		s.accounts.Unlock()
+		return POSTransaction{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "付款账户不存在")
+	}
+
+	now := s.clock.Now()
+	refund := POSRefund{
+		RefundID:  fmt.Sprintf("%s-R%d", tx.PosTxID, len(tx.Refunds)+1),
+		Amount:    amount,
+		FeeRefund: feeRefund,
+		Time:      now.Format("2006-01-02 15:04:05"),
+	}
+
+	merchantAccount.Balance = model.RoundAmount(merchantAccount.Balance - merchantDebit)
+	merchantAccount = s.accounts.Save(merchantAccount)
+	s.ledger.Record(model.Transaction{
+		AccountID:    merchantAccount.AccountID,
+		Type:         "pos_refund",
+		Direction:    "debit",
+		Amount:       amount,
+		BalanceAfter: model.RoundAmount(merchantAccount.Balance - feeRefund),
+		Counterparty: customer.UserName,
+		Reference:    refund.RefundID,
+		Description:  "收单退货（原交易 " + tx.PosTxID + "）",
+	})
+	if feeRefund > 0 {
+		s.ledger.Record(model.Transaction{
+			AccountID:    merchantAccount.AccountID,
+			Type:         "interchange_fee_refund",
+			Direction:    "credit",
+			Amount:       feeRefund,
+			BalanceAfter: merchantAccount.Balance,
+			Reference:    refund.RefundID,
+			Description:  "退货退还收单手续费",
+		})
+	}
+
+	customer.Balance = model.RoundAmount(customer.Balance + amount)
+	customer = s.accounts.Save(customer)
+	s.ledger.Record(model.Transaction{
+		AccountID:    customer.AccountID,
+		Type:         "card_refund",
+		Direction:    "credit",
+		Amount:       amount,
+		BalanceAfter: customer.Balance,
+		Counterparty: merchant.Name,
+		Reference:    refund.RefundID,
+		Description:  "POS 消费退货（原交易 " + tx.PosTxID + "）",
+	})
+	s.accounts.Unlock()
+
+	tx.Refunds = append(tx.Refunds, refund)
+	tx.Refunded = model.RoundAmount(tx.Refunded + amount)
+	tx.Status = POS_PARTIALLY_REFUNDED
+	if tx.Refunded >= tx.Amount {
+		tx.Status = POS_REFUNDED
+	}
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  customer.AccountID,
+		NewBalance: customer.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", customer, EVENT_CARD_REFUND, map[string]interface{}{
+		"Amount":    amount,
+		"Balance":   customer.Balance,
+		"Merchant":  merchant.Name,
+		"Reference": tx.PosTxID,
+	}))
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  merchantAccount.AccountID,
+		NewBalance: merchantAccount.Balance,
+	})
+
+	// 终端提示：POS 退货
+	log.Println("\n[🏪 POS 退货]")
+	log.Printf("退货编号: %s", refund.RefundID)
+	log.Printf("商户: %s（%s）", merchant.Name, merchant.MerchantID)
+	log.Printf("退款账户: %s（%s）", customer.AccountID, customer.UserName)
+	log.Printf("退货金额: \033[1;32m%.2f %s\033[0m，退还手续费 %.2f，累计退货 %.2f/%.2f", amount, tx.Currency, feeRefund, tx.Refunded, tx.Amount) // 绿色高亮
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return copyPOSTransaction(tx), nil
+}
+
+// 查询 POS 交易
+func (s *AcquiringService) Transaction(posTxID string) (POSTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.transactions[posTxID]
+	if !ok {
+		return POSTransaction{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "交易不存在")
+	}
+	return copyPOSTransaction(tx), nil
+}
+
+// 商户按日结算汇总（按日期倒序），date 不为空时只返回该日；消费计入交易日，退货计入退货日
+func (s *AcquiringService) Settlements(merchantID, date string) ([]MerchantSettlement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merchant, ok := s.merchants[merchantID]
+	if !ok {
+		return nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "商户不存在")
+	}
+
+	days := make(map[string]*MerchantSettlement)
+	day := func(d string) *MerchantSettlement {
+		if days[d] == nil {
+			days[d] = &MerchantSettlement{MerchantID: merchantID, Date: d, Currency: merchant.Currency}
+		}
+		return days[d]
+	}
+	for _, tx := range s.transactions {
+		if tx.MerchantID != merchantID {
+			continue
+		}
+		if date == "" || tx.Date == date {
+			total := day(tx.Date)
+			total.SalesCount++
+			total.SalesAmount += tx.Amount
+			total.Fees += tx.InterchangeFee
+		}
+		for _, refund := range tx.Refunds {
+			if refundDate := refund.Time[:10]; date == "" || refundDate == date {
+				total := day(refundDate)
+				total.RefundCount++
+				total.RefundAmount += refund.Amount
+				total.Fees -= refund.FeeRefund
+			}
+		}
+	}
+
+	result := []MerchantSettlement{}
+	for _, total := range days {
+		total.SalesAmount = model.RoundAmount(total.SalesAmount)
+		total.RefundAmount = model.RoundAmount(total.RefundAmount)
+		total.Fees = model.RoundAmount(total.Fees)
+		total.NetAmount = model.RoundAmount(total.SalesAmount - total.RefundAmount - total.Fees)
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date > result[j].Date })
+	return result, nil
+}
+
+func copyPOSTransaction(tx *POSTransaction) POSTransaction {
+	result := *tx
+	result.Refunds = append([]POSRefund{}, tx.Refunds...)
+	return result
+}
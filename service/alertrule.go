@@ -0,0 +1,261 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 余额提醒规则类型（阈值均为账户币种金额）
+const (
+	ALERT_BALANCE_BELOW = "balanceBelow" // 余额由不低于阈值降至低于阈值时提醒
+	ALERT_BALANCE_ABOVE = "balanceAbove" // 余额由不高于阈值升至高于阈值时提醒
+	ALERT_DEBIT_OVER    = "debitOver"    // 单笔支出超过阈值时提醒
+	ALERT_CREDIT_OVER   = "creditOver"   // 单笔入账超过阈值时提醒
+)
+
+// 单账户提醒规则数上限
+const MAX_ALERT_RULES_PER_ACCOUNT = 20
+
+// 余额提醒规则
+type AlertRule struct {
+	RuleID          string  `json:"ruleId"`
+	AccountID       string  `json:"accountId"`
+	Type            string  `json:"type"` // balanceBelow/balanceAbove/debitOver/creditOver
+	Threshold       float64 `json:"threshold"`
+	Enabled         bool    `json:"enabled"`
+	Triggered       int     `json:"triggered"` // 累计触发次数
+	LastTriggeredAt string  `json:"lastTriggeredAt,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+// 创建提醒规则请求
+type AlertRuleRequest struct {
+	AccountID string  `json:"accountId"`
+	Type      string  `json:"type"`
+	Threshold float64 `json:"threshold"`
+}
+
+// 修改提醒规则请求（字段为空表示不变）
+type AlertRuleUpdateRequest struct {
+	Threshold *float64 `json:"threshold"`
+	Enabled   *bool    `json:"enabled"`
+}
+
+// 余额提醒服务：用户自定义余额/单笔金额阈值，订阅总账在每次余额变动时检查，
+// 命中时推送 balanceAlert 消息（WebSocket 推送并存入通知中心）
+type AlertRuleService struct {
+	accounts  *repository.AccountRepository
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu    sync.Mutex              // 叶子锁（流水回调在账户写锁内执行）
+	rules map[string][]*AlertRule // 账户ID → 规则（按创建顺序）
+	seq   int64
+}
+
+func NewAlertRuleService(accounts *repository.AccountRepository, ledger *LedgerService, clock Clock, notifier Notifier, templates *TemplateRegistry) *AlertRuleService {
+	s := &AlertRuleService{
+		accounts:  accounts,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		rules:     make(map[string][]*AlertRule),
+	}
+	ledger.Subscribe(s.onTransaction)
+	return s
+}
+
+// 创建提醒规则
+func (s *AlertRuleService) Create(req AlertRuleRequest) (AlertRule, error) {
+	if _, exists := s.accounts.Get(req.AccountID); !exists {
+		return AlertRule{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	switch req.Type {
+	case ALERT_BALANCE_BELOW, ALERT_BALANCE_ABOVE, ALERT_DEBIT_OVER, ALERT_CREDIT_OVER:
+	default:
+		return AlertRule{}, model.NewError(model.CODE_PARAM_ERROR, "规则类型应为 balanceBelow、balanceAbove、debitOver 或 creditOver")
+	}
+	if err := validateAlertThreshold(req.Type, req.Threshold); err != nil {
+		return AlertRule{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.rules[req.AccountID]) >= MAX_ALERT_RULES_PER_ACCOUNT {
+		return AlertRule{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("每个账户最多设置 %d 条提醒规则", MAX_ALERT_RULES_PER_ACCOUNT))
+	}
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+	s.seq++
+	rule := &AlertRule{
+		RuleID:    fmt.Sprintf("AR%08d", s.seq),
+		AccountID: req.AccountID,
+		Type:      req.Type,
+		Threshold: model.RoundAmount(req.Threshold),
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.rules[req.AccountID] = append(s.rules[req.AccountID], rule)
+
+	// 终端提示：提醒规则创建
+	log.Println("\n[🔔 余额提醒规则创建]")
+	log.Printf("规则ID: %s", rule.RuleID)
+	log.Printf("账户ID: %s", rule.AccountID)
+	log.Printf("规则: %s %.2f", rule.Type, rule.Threshold)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *rule, nil
+}
+
+// 阈值校验：余额类规则允许 0（如余额低于 0 即透支），单笔金额类规则须大于 0
+func validateAlertThreshold(ruleType string, threshold float64) error {
+	if ruleType == ALERT_DEBIT_OVER || ruleType == ALERT_CREDIT_OVER {
+		if threshold <= 0 {
+			return model.NewError(model.CODE_PARAM_ERROR, "单笔金额阈值必须大于0")
+		}
+	} else if threshold < 0 {
+		return model.NewError(model.CODE_PARAM_ERROR, "余额阈值不能为负数")
+	}
+	return nil
+}
+
+// 查询账户的提醒规则
+func (s *AlertRuleService) List(accountID string) []AlertRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]AlertRule, 0, len(s.rules[accountID]))
+	for _, rule := range s.rules[accountID] {
+		result = append(result, *rule)
+	}
+	return result
+}
+
+// 查询单条提醒规则
+func (s *AlertRuleService) Get(ruleID string) (AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, _ := s.find(ruleID)
+	if rule == nil {
+		return AlertRule{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "提醒规则不存在")
+	}
+	return *rule, nil
+}
+
+// 修改提醒规则的阈值或启用状态
+func (s *AlertRuleService) Update(ruleID string, req AlertRuleUpdateRequest) (AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, _ := s.find(ruleID)
+	if rule == nil {
+		return AlertRule{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "提醒规则不存在")
+	}
+	if req.Threshold != nil {
+		if err := validateAlertThreshold(rule.Type, *req.Threshold); err != nil {
+			return AlertRule{}, err
+		}
+		rule.Threshold = model.RoundAmount(*req.Threshold)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	return *rule, nil
+}
+
+// 删除提醒规则
+func (s *AlertRuleService) Delete(ruleID string) (AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, index := s.find(ruleID)
+	if rule == nil {
+		return AlertRule{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "提醒规则不存在")
+	}
+	rules := s.rules[rule.AccountID]
+	s.rules[rule.AccountID] = append(rules[:index:index], rules[index+1:]...)
+	if len(s.rules[rule.AccountID]) == 0 {
+		delete(s.rules, rule.AccountID)
+	}
+	return *rule, nil
+}
+
+// 按编号查找规则及其在账户规则列表中的下标（调用方需持有 s.mu）
+func (s *AlertRuleService) find(ruleID string) (*AlertRule, int) {
+	for _, rules := range s.rules {
+		for i, rule := range rules {
+			if rule.RuleID == ruleID {
+				return rule, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// 流水回调：逐条检查账户的启用规则，余额类规则仅在跨越阈值时触发，避免余额持续低于阈值时重复提醒
+// （在账户写锁内执行，只使用叶子锁，推送在释放 s.mu 后进行）
+func (s *AlertRuleService) onTransaction(tx model.Transaction) {
+	before := model.RoundAmount(tx.BalanceAfter - tx.SignedAmount())
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+
+	s.mu.Lock()
+	var hits []AlertRule
+	for _, rule := range s.rules[tx.AccountID] {
+		if !rule.Enabled {
+			continue
+		}
+		var hit bool
+		switch rule.Type {
+		case ALERT_BALANCE_BELOW:
+			hit = before >= rule.Threshold && tx.BalanceAfter < rule.Threshold
+		case ALERT_BALANCE_ABOVE:
+			hit = before <= rule.Threshold && tx.BalanceAfter > rule.Threshold
+		case ALERT_DEBIT_OVER:
+			hit = tx.Direction == "debit" && tx.Amount > rule.Threshold
+		case ALERT_CREDIT_OVER:
+			hit = tx.Direction == "credit" && tx.Amount > rule.Threshold
+		}
+		if hit {
+			rule.Triggered++
+			rule.LastTriggeredAt = now
+			hits = append(hits, *rule)
+		}
+	}
+	s.mu.Unlock()
+	if len(hits) == 0 {
+		return
+	}
+
+	account, exists := s.accounts.Find(tx.AccountID)
+	if !exists {
+		return
+	}
+	for _, rule := range hits {
+		msg := s.templates.Alert("balanceAlert", account, EVENT_BALANCE_ALERT, map[string]interface{}{
+			"RuleType":  rule.Type,
+			"Threshold": rule.Threshold,
+			"Amount":    tx.Amount,
+			"Balance":   tx.BalanceAfter,
+		})
+		msg.NewBalance = tx.BalanceAfter
+		msg.Data = map[string]interface{}{
+			"ruleId":    rule.RuleID,
+			"ruleType":  rule.Type,
+			"threshold": rule.Threshold,
+			"txId":      tx.TxID,
+			"amount":    tx.Amount,
+			"direction": tx.Direction,
+		}
+		s.notifier.Send(msg)
+	}
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 收款别名类型
+const (
+	ALIAS_PHONE = "phone"
+	ALIAS_EMAIL = "email"
+)
+
+// 别名认领状态
+const (
+	ALIAS_CLAIM_PENDING   = "pending"   // 待验证
+	ALIAS_CLAIM_CONFIRMED = "confirmed" // 已绑定
+	ALIAS_CLAIM_EXPIRED   = "expired"   // 验证码过期
+	ALIAS_CLAIM_CANCELLED = "cancelled" // 被新的认领取代或验证码错误次数过多
+)
+
+// 单账户可绑定的别名数上限
+const MAX_ALIASES_PER_ACCOUNT = 5
+
+const (
+	aliasClaimTTL         = 10 * time.Minute // 认领验证码有效期（模拟时钟）
+	aliasClaimMaxFailures = 5                // 验证码最多允许输错次数
+)
+
+// 别名类型名称（验证码文案使用）
+var aliasTypeNames = map[string]string{
+	ALIAS_PHONE: "手机号",
+	ALIAS_EMAIL: "邮箱",
+}
+
+// 收款别名：手机号或邮箱指向本行账户，转账时可代替账号
+type Alias struct {
+	Alias        string `json:"alias"`
+	Type         string `json:"type"` // phone/email
+	AccountID    string `json:"accountId"`
+	RegisteredAt string `json:"registeredAt"`
+}
+
+// 别名认领：验证码发往别名本身，验证通过后绑定；别名已绑定其他账户时验证通过即转移（携号转移）
+type AliasClaim struct {
+	ClaimID     string `json:"claimId"`
+	Alias       string `json:"alias"` // 脱敏展示
+	Type        string `json:"type"`
+	AccountID   string `json:"accountId"`
+	Portability bool   `json:"portability"` // 别名当前绑定其他账户，确认后将从原账户转移
+	Status      string `json:"status"`
+	RequestedAt string `json:"requestedAt"`
+	ExpiresAt   string `json:"expiresAt"`
+	ConfirmedAt string `json:"confirmedAt,omitempty"`
+
+	alias     string
+	code      string
+	expiresAt time.Time
+	failures  int
+}
+
+// 别名认领请求
+type AliasRequest struct {
+	AccountID string `json:"accountId"`
+	Alias     string `json:"alias"` // 手机号或邮箱
+}
+
+// 确认别名认领请求
+type AliasConfirmRequest struct {
+	ClaimID string `json:"claimId" validate:"required"`
+	Code    string `json:"code" validate:"required"`
+}
+
+// 别名查询结果（转账前核对收款人，户名与账号脱敏）
+type AliasLookup struct {
+	Alias     string `json:"alias"`
+	Type      string `json:"type"`
+	AccountNo string `json:"accountNo"` // 脱敏账号
+	PayeeName string `json:"payeeName"` // 脱敏户名
+	Currency  string `json:"currency"`
+}
+
+// 别名目录服务：维护手机号/邮箱到账户的映射，认领须经别名本身的验证码确认，变更记录审计日志
+type AliasService struct {
+	accounts  *repository.AccountRepository
+	sms       *SMSService
+	emails    *EmailService
+	audit     *AuditService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu      sync.Mutex
+	aliases map[string]*Alias // 规范化别名 → 绑定
+	claims  map[string]*AliasClaim
+	seq     int
+}
+
+func NewAliasService(accounts *repository.AccountRepository, sms *SMSService, emails *EmailService, audit *AuditService, clock Clock, notifier Notifier, templates *TemplateRegistry) *AliasService {
+	return &AliasService{
+		accounts:  accounts,
+		sms:       sms,
+		emails:    emails,
+		audit:     audit,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		aliases:   make(map[string]*Alias),
+		claims:    make(map[string]*AliasClaim),
+	}
+}
+
+// 规范化别名：含 @ 的按邮箱处理（转小写），否则按手机号处理（去除空格与连字符）
+func NormalizeAlias(alias string) (string, string, error) {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return "", "", model.NewError(model.CODE_PARAM_ERROR, "别名不能为空")
+	}
+	if strings.Contains(alias, "@") {
+		alias = strings.ToLower(alias)
+		if err := ValidateEmail(alias); err != nil {
+			return "", "", err
+		}
+		return alias, ALIAS_EMAIL, nil
+	}
+	alias = strings.NewReplacer(" ", "", "-", "").Replace(alias)
+	if err := ValidatePhone(alias); err != nil {
+		return "", "", err
+	}
+	return alias, ALIAS_PHONE, nil
+}
+
+// 申请绑定别名：验证码发往该手机号或邮箱，同一账户对同一别名的未完成认领作废
+func (s *AliasService) Claim(req AliasRequest) (AliasClaim, error) {
+	alias, aliasType, err := NormalizeAlias(req.Alias)
+	if err != nil {
+		return AliasClaim{}, err
+	}
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return AliasClaim{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owner, registered := s.aliases[alias]
+	if registered && owner.AccountID == req.AccountID {
+		return AliasClaim{}, model.NewError(model.CODE_PARAM_ERROR, "该别名已绑定本账户")
+	}
+	if len(s.list(req.AccountID)) >= MAX_ALIASES_PER_ACCOUNT {
+		return AliasClaim{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("每个账户最多绑定 %d 个别名", MAX_ALIASES_PER_ACCOUNT))
+	}
+	for _, claim := range s.claims {
+		if claim.AccountID == req.AccountID && claim.alias == alias && claim.Status == ALIAS_CLAIM_PENDING {
+			claim.Status = ALIAS_CLAIM_CANCELLED
+		}
+	}
+
+	now := s.clock.Now()
+	s.seq++
+	claim := &AliasClaim{
+		ClaimID:     fmt.Sprintf("AC%s%06d", now.Format("20060102"), s.seq),
+		Alias:       maskContact(aliasType, alias),
+		Type:        aliasType,
+		AccountID:   req.AccountID,
+		Portability: registered,
+		Status:      ALIAS_CLAIM_PENDING,
+		RequestedAt: now.Format("2006-01-02 15:04:05"),
+		ExpiresAt:   now.Add(aliasClaimTTL).Format("2006-01-02 15:04:05"),
+		alias:       alias,
+		code:        fmt.Sprintf("%06d", rand.Intn(1000000)),
+		expiresAt:   now.Add(aliasClaimTTL),
+	}
+
+	vars := map[string]interface{}{
+		"Code":     claim.code,
+		"Minutes":  int(aliasClaimTTL.Minutes()),
+		"Type":     aliasType,
+		"TypeName": aliasTypeNames[aliasType],
+	}
+	if aliasType == ALIAS_PHONE {
+		account.Phone = alias
+		if _, err := s.sms.Notify(account, SMS_OTP, EVENT_ALIAS_VERIFY, vars); err != nil {
+			return AliasClaim{}, err
+		}
+	} else {
+		account.Email = alias
+		s.emails.Notify(account, EVENT_ALIAS_VERIFY, vars)
+	}
+	s.claims[claim.ClaimID] = claim
+
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, "alias.claim.requested", req.AccountID, map[string]string{
+		"claimId":     claim.ClaimID,
+		"alias":       claim.Alias,
+		"portability": fmt.Sprint(claim.Portability),
+	})
+
+	// 终端提示：别名认领申请
+	log.Println("\n[📱 收款别名认领]")
+	log.Printf("认领编号: %s", claim.ClaimID)
+	log.Printf("账户ID: %s", claim.AccountID)
+	log.Printf("别名: %s（%s）", claim.Alias, aliasTypeNames[aliasType])
+	if registered {
+		log.Printf("该别名当前绑定账户 %s，确认后转移", owner.AccountID)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *claim, nil
+}
+
+// 确认别名认领：验证码正确后绑定到申请账户；别名原绑定其他账户时从原账户解绑并向原账户推送安全提醒
+func (s *AliasService) Confirm(req AliasConfirmRequest) (Alias, error) {
+	if err := model.Validate(req); err != nil {
+		return Alias{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claim, ok := s.claims[req.ClaimID]
+	if !ok {
+		return Alias{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "别名认领不存在")
+	}
+	now := s.clock.Now()
+	if claim.Status == ALIAS_CLAIM_PENDING && !now.Before(claim.expiresAt) {
+		claim.Status = ALIAS_CLAIM_EXPIRED
+	}
+	if claim.Status != ALIAS_CLAIM_PENDING {
+		return Alias{}, model.NewError(model.CODE_PARAM_ERROR, "别名认领已失效（"+claim.Status+"），请重新申请")
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Code), []byte(claim.code)) != 1 {
+		claim.failures++
+		if claim.failures >= aliasClaimMaxFailures {
+			claim.Status = ALIAS_CLAIM_CANCELLED
+			return Alias{}, model.NewError(model.CODE_RISK_CONTROL_REJECT, "验证码错误次数过多，别名认领已作废")
+		}
+		return Alias{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("验证码错误，还可尝试 %d 次", aliasClaimMaxFailures-claim.failures))
+	}
+	if _, exists := s.accounts.Get(claim.AccountID); !exists {
+		return Alias{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if len(s.list(claim.AccountID)) >= MAX_ALIASES_PER_ACCOUNT {
+		return Alias{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("每个账户最多绑定 %d 个别名", MAX_ALIASES_PER_ACCOUNT))
+	}
+
+	previous, ported := s.aliases[claim.alias]
+	alias := &Alias{
+		Alias:        claim.alias,
+		Type:         claim.Type,
+		AccountID:    claim.AccountID,
+		RegisteredAt: now.Format("2006-01-02 15:04:05"),
+	}
+	s.aliases[claim.alias] = alias
+	claim.Status = ALIAS_CLAIM_CONFIRMED
+	claim.ConfirmedAt = alias.RegisteredAt
+
+	detail := map[string]string{"claimId": claim.ClaimID, "alias": claim.Alias}
+	if ported && previous.AccountID != claim.AccountID {
+		detail["fromAccount"] = previous.AccountID
+		s.audit.Record(AUDIT_ACTOR_CUSTOMER, "alias.ported", claim.AccountID, detail)
+		if account, exists := s.accounts.Get(previous.AccountID); exists {
+			s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_ALIAS_PORTED, map[string]interface{}{
+				"Alias":    claim.Alias,
+				"Type":     claim.Type,
+				"TypeName": aliasTypeNames[claim.Type],
+				"Time":     claim.ConfirmedAt,
+			}))
+		}
+	} else {
+		s.audit.Record(AUDIT_ACTOR_CUSTOMER, "alias.registered", claim.AccountID, detail)
+	}
+
+	// 终端提示：别名绑定
+	log.Println("\n[📱 收款别名绑定]")
+	log.Printf("认领编号: %s", claim.ClaimID)
+	log.Printf("别名: %s → 账户 %s", claim.Alias, claim.AccountID)
+	if ported {
+		log.Printf("原绑定账户: %s（已解绑）", previous.AccountID)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *alias, nil
+}
+
+// 解绑账户的别名
+func (s *AliasService) Remove(accountID, alias string) (Alias, error) {
+	alias, _, err := NormalizeAlias(alias)
+	if err != nil {
+		return Alias{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	registered, ok := s.aliases[alias]
+	if !ok || registered.AccountID != accountID {
+		return Alias{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "该账户未绑定此别名")
+	}
+	delete(s.aliases, alias)
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, "alias.removed", accountID, map[string]string{
+		"alias": maskContact(registered.Type, alias),
+	})
+	return *registered, nil
+}
+
+// 账户绑定的别名（按绑定时间正序）
+func (s *AliasService) List(accountID string) []Alias {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list(accountID)
+}
+
+// 调用方需持有 s.mu
+func (s *AliasService) list(accountID string) []Alias {
+	result := []Alias{}
+	for _, alias := range s.aliases {
+		if alias.AccountID == accountID {
+			result = append(result, *alias)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RegisteredAt != result[j].RegisteredAt {
+			return result[i].RegisteredAt < result[j].RegisteredAt
+		}
+		return result[i].Alias < result[j].Alias
+	})
+	return result
+}
+
+// 解析别名对应的账户ID
+func (s *AliasService) Resolve(alias string) (string, error) {
+	alias, _, err := NormalizeAlias(alias)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	registered, ok := s.aliases[alias]
+	if !ok {
+		return "", model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "该别名未绑定收款账户")
+	}
+	return registered.AccountID, nil
+}
+
+// 转账前核对收款人：返回别名对应账户的脱敏户名与账号
+func (s *AliasService) Lookup(alias string) (AliasLookup, error) {
+	accountID, err := s.Resolve(alias)
+	if err != nil {
+		return AliasLookup{}, err
+	}
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return AliasLookup{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "该别名未绑定收款账户")
+	}
+	normalized, aliasType, _ := NormalizeAlias(alias)
+	return AliasLookup{
+		Alias:     maskContact(aliasType, normalized),
+		Type:      aliasType,
+		AccountNo: maskAccountNo(account.AccountID),
+		PayeeName: maskName(account.UserName),
+		Currency:  account.Currency,
+	}, nil
+}
+
+// 账号脱敏（保留后四位）
+func maskAccountNo(accountID string) string {
+	if len(accountID) <= 4 {
+		return accountID
+	}
+	return strings.Repeat("*", len(accountID)-4) + accountID[len(accountID)-4:]
+}
+
+// 户名脱敏（保留最后一个字）
+func maskName(name string) string {
+	n := utf8.RuneCountInString(name)
+	if n <= 1 {
+		return name
+	}
+	runes := []rune(name)
+	return strings.Repeat("*", n-1) + string(runes[n-1])
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 公告类型
+const (
+	ANNOUNCEMENT_MAINTENANCE = "maintenance" // 系统维护
+	ANNOUNCEMENT_MARKETING   = "marketing"   // 营销推广
+)
+
+// 公告状态
+const (
+	ANNOUNCEMENT_SCHEDULED = "scheduled" // 待发布
+	ANNOUNCEMENT_PUBLISHED = "published" // 已发布
+	ANNOUNCEMENT_EXPIRED   = "expired"   // 已过期
+	ANNOUNCEMENT_CANCELLED = "cancelled" // 已取消（仅待发布公告可取消）
+)
+
+// 管理员广播公告
+type Announcement struct {
+	AnnouncementID string `json:"announcementId"`
+	Kind           string `json:"kind"` // maintenance/marketing
+	Title          string `json:"title"`
+	Message        string `json:"message"`
+	PublishAt      string `json:"publishAt,omitempty"` // 定时发布时间（模拟时间，2006-01-02 15:04:05），为空表示立即发布
+	ExpiresAt      string `json:"expiresAt,omitempty"` // 过期时间，过期后不再出现在通知列表中，为空表示长期有效
+	Status         string `json:"status"`
+	NotificationID string `json:"notificationId,omitempty"` // 发布后对应的通知中心记录
+	PublishedAt    string `json:"publishedAt,omitempty"`
+	CreatedAt      string `json:"createdAt"`
+
+	publishAt time.Time
+	expiresAt time.Time
+}
+
+// 发布公告请求
+type AnnouncementRequest struct {
+	Kind      string `json:"kind" validate:"oneof=maintenance marketing"`
+	Title     string `json:"title" validate:"required"`
+	Message   string `json:"message" validate:"required"`
+	PublishAt string `json:"publishAt"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// 广播公告服务：维护/营销公告推送给全部在线客户端并存入通知中心（离线用户上线后可查看），
+// 支持定时发布与过期时间（按模拟时钟）
+type AnnouncementService struct {
+	notifications *NotificationService
+	clock         Clock
+
+	mu            sync.Mutex
+	announcements map[string]*Announcement
+	seq           int
+}
+
+func NewAnnouncementService(notifications *NotificationService, clock Clock) *AnnouncementService {
+	return &AnnouncementService{
+		notifications: notifications,
+		clock:         clock,
+		announcements: make(map[string]*Announcement),
+	}
+}
+
+// 创建公告：未指定发布时间或发布时间已到时立即推送，否则等待定时发布
+func (s *AnnouncementService) Create(req AnnouncementRequest) (Announcement, error) {
+	if err := model.Validate(req); err != nil {
+		return Announcement{}, err
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Message = strings.TrimSpace(req.Message)
+	publishAt, err := parseSimTime(req.PublishAt)
+	if err != nil {
+		return Announcement{}, model.NewError(model.CODE_PARAM_ERROR, "发布时间格式应为 2006-01-02 15:04:05")
+	}
+	expiresAt, err := parseSimTime(req.ExpiresAt)
+	if err != nil {
+		return Announcement{}, model.NewError(model.CODE_PARAM_ERROR, "过期时间格式应为 2006-01-02 15:04:05")
+	}
+	now := s.clock.Now()
+	if !expiresAt.IsZero() && (!expiresAt.After(now) || (!publishAt.IsZero() && !expiresAt.After(publishAt))) {
+		return Announcement{}, model.NewError(model.CODE_PARAM_ERROR, "过期时间须晚于当前时间与发布时间")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	a := &Announcement{
+		AnnouncementID: fmt.Sprintf("AN%s%04d", now.Format("20060102"), s.seq),
+		Kind:           req.Kind,
+		Title:          req.Title,
+		Message:        req.Message,
+		PublishAt:      req.PublishAt,
+		ExpiresAt:      req.ExpiresAt,
+		Status:         ANNOUNCEMENT_SCHEDULED,
+		CreatedAt:      now.Format("2006-01-02 15:04:05"),
+		publishAt:      publishAt,
+		expiresAt:      expiresAt,
+	}
+	s.announcements[a.AnnouncementID] = a
+
+	if publishAt.IsZero() || !now.Before(publishAt) {
+		s.publish(a, now)
+	} else {
+		// 终端提示：公告定时发布
+		log.Println("\n[📢 广播公告已排期]")
+		log.Printf("公告ID: %s（%s）", a.AnnouncementID, a.Kind)
+		log.Printf("公告标题: %s", a.Title)
+		log.Printf("发布时间: %s", a.PublishAt)
+		if a.ExpiresAt != "" {
+			log.Printf("过期时间: %s", a.ExpiresAt)
+		}
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	}
+	return *a, nil
+}
+
+// 定时发布与过期检查（每秒按模拟时钟检查，active 返回 false 时跳过）
+func (s *AnnouncementService) Run(active func() bool) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if active() {
+			s.Dispatch(s.clock.Now())
+		}
+	}
+}
+
+// 发布已到发布时间的公告，标记已过期的公告
+func (s *AnnouncementService) Dispatch(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*Announcement
+	for _, a := range s.announcements {
+		if a.Status == ANNOUNCEMENT_SCHEDULED && !now.Before(a.publishAt) {
+			due = append(due, a)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].AnnouncementID < due[j].AnnouncementID })
+	for _, a := range due {
+		if !a.expiresAt.IsZero() && !now.Before(a.expiresAt) {
+			a.Status = ANNOUNCEMENT_EXPIRED // 模拟时钟跳过了整个有效期，不再推送
+			continue
+		}
+		s.publish(a, now)
+	}
+	for _, a := range s.announcements {
+		if a.Status == ANNOUNCEMENT_PUBLISHED && !a.expiresAt.IsZero() && !now.Before(a.expiresAt) {
+			a.Status = ANNOUNCEMENT_EXPIRED
+		}
+	}
+}
+
+// 推送公告并存入通知中心（调用方需持有 s.mu）
+func (s *AnnouncementService) publish(a *Announcement, now time.Time) {
+	a.Status = ANNOUNCEMENT_PUBLISHED
+	a.PublishedAt = now.Format("2006-01-02 15:04:05")
+	data := map[string]string{"announcementId": a.AnnouncementID, "kind": a.Kind, "title": a.Title}
+	if a.ExpiresAt != "" {
+		data["expiresAt"] = a.ExpiresAt
+	}
+	a.NotificationID = s.notifications.Broadcast(ws.Message{
+		Type:    "broadcast",
+		Message: "【" + a.Title + "】" + a.Message,
+		Data:    data,
+	}, a.expiresAt)
+
+	// 终端提示：公告发布
+	log.Println("\n[📢 广播公告发布]")
+	log.Printf("发布时间: %s", a.PublishedAt)
+	log.Printf("公告ID: %s（%s）", a.AnnouncementID, a.Kind)
+	log.Printf("公告标题: %s", a.Title)
+	log.Printf("通知编号: %s", a.NotificationID)
+	if a.ExpiresAt != "" {
+		log.Printf("过期时间: %s", a.ExpiresAt)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 取消待发布的公告
+func (s *AnnouncementService) Cancel(announcementID string) (Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.announcements[announcementID]
+	if !ok {
+		return Announcement{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "公告不存在")
+	}
+	if a.Status != ANNOUNCEMENT_SCHEDULED {
+		return Announcement{}, model.NewError(model.CODE_PARAM_ERROR, "只能取消待发布的公告")
+	}
+	a.Status = ANNOUNCEMENT_CANCELLED
+	return *a, nil
+}
+
+// 公告列表（按公告编号倒序），status 为空时返回全部
+func (s *AnnouncementService) List(status string) []Announcement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Announcement{}
+	for _, a := range s.announcements {
+		if status == "" || a.Status == status {
+			result = append(result, *a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AnnouncementID > result[j].AnnouncementID })
+	return result
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 审计操作人类型
+const (
+	AUDIT_ACTOR_CUSTOMER = "customer" // 客户本人
+	AUDIT_ACTOR_ADMIN    = "admin"    // 管理员
+	AUDIT_ACTOR_SYSTEM   = "system"   // 系统
+)
+
+// 审计日志单次查询上限
+const auditQueryLimit = 500
+
+// 审计日志条目（敏感信息记录脱敏后的值）
+type AuditEntry struct {
+	AuditID string            `json:"auditId"`
+	Time    string            `json:"time"`
+	Actor   string            `json:"actor"`  // customer/admin/system
+	Action  string            `json:"action"` // 如 contact.change.requested
+	Target  string            `json:"target"` // 操作对象（客户号、账户ID 等）
+	Detail  map[string]string `json:"detail,omitempty"`
+}
+
+// 审计日志查询条件（字段为空表示不限）
+type AuditQuery struct {
+	Target string
+	Action string
+}
+
+// 审计日志服务：只追加记录敏感操作，供管理员查询（叶子锁，可在其他服务锁内调用）
+type AuditService struct {
+	clock Clock
+
+	mu      sync.Mutex
+	entries []AuditEntry
+	seq     int64
+}
+
+func NewAuditService(clock Clock) *AuditService {
+	return &AuditService{clock: clock}
+}
+
+// 追加审计记录
+func (s *AuditService) Record(actor, action, target string, detail map[string]string) AuditEntry {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	entry := AuditEntry{
+		AuditID: fmt.Sprintf("AU%s%06d", now.Format("20060102"), s.seq),
+		Time:    now.Format("2006-01-02 15:04:05"),
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Detail:  detail,
+	}
+	s.entries = append(s.entries, entry)
+	return entry
+}
+
+// 查询审计记录（按时间倒序，最多返回 500 条）
+func (s *AuditService) List(query AuditQuery) []AuditEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []AuditEntry{}
+	for i := len(s.entries) - 1; i >= 0 && len(result) < auditQueryLimit; i-- {
+		entry := s.entries[i]
+		if (query.Target == "" || entry.Target == query.Target) && (query.Action == "" || entry.Action == query.Action) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// 一页审计记录（游标分页，按时间倒序）
+type AuditPage struct {
+	Limit      int          `json:"limit"`
+	Items      []AuditEntry `json:"items"`
+	NextCursor string       `json:"nextCursor,omitempty"` // 下一页游标，为空表示没有更多
+	HasMore    bool         `json:"hasMore"`
+}
+
+// 游标分页查询审计记录，cursor 为上一页返回的 nextCursor，为空表示第一页
+func (s *AuditService) Page(query AuditQuery, cursor string, limit int) (AuditPage, error) {
+	after, err := parseCursor(cursor)
+	if err != nil {
+		return AuditPage{}, err
+	}
+	limit = cursorLimit(limit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := len(s.entries) - 1
+	if after.Pos >= 0 {
+		if after.Pos >= len(s.entries) || s.entries[after.Pos].AuditID != after.ID {
+			return AuditPage{}, staleCursorError()
+		}
+		start = after.Pos - 1
+	}
+
+	page := AuditPage{Limit: limit, Items: make([]AuditEntry, 0, limit)}
+	last := -1
+	for i := start; i >= 0; i-- {
+		entry := s.entries[i]
+		if (query.Target != "" && entry.Target != query.Target) || (query.Action != "" && entry.Action != query.Action) {
+			continue
+		}
+		if len(page.Items) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Items = append(page.Items, entry)
+		last = i
+	}
+	if page.HasMore {
+		page.NextCursor = pageCursor{Pos: last, ID: s.entries[last].AuditID}.String()
+	}
+	return page, nil
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 熔断器状态
+const (
+	BREAKER_CLOSED    = "closed"   // 正常调用
+	BREAKER_OPEN      = "open"     // 熔断中，调用直接失败并走降级逻辑
+	BREAKER_HALF_OPEN = "halfOpen" // 熔断超时后放行一次试探调用，成功则恢复，失败则重新熔断
+)
+
+// 受熔断保护的模拟外部系统
+const (
+	BREAKER_FX        = "fx"        // 汇率源
+	BREAKER_INTERBANK = "interbank" // 跨行清算网络
+	BREAKER_SMS       = "sms"       // 短信网关
+)
+
+// 熔断器管理操作
+const (
+	BREAKER_ACTION_TRIP  = "trip"  // 强制熔断（模拟外部系统整体不可用），直至手工恢复
+	BREAKER_ACTION_RESET = "reset" // 恢复为正常状态并清零连续失败次数
+)
+
+// 外部系统名称
+var breakerLabels = map[string]string{
+	BREAKER_FX:        "汇率源",
+	BREAKER_INTERBANK: "跨行清算网络",
+	BREAKER_SMS:       "短信网关",
+}
+
+// 熔断配置
+type BreakerConfig struct {
+	FailureThreshold int           // 连续失败达到该次数后熔断
+	OpenTimeout      time.Duration // 熔断持续时间，到期后放行试探调用
+}
+
+// 熔断器管理请求结构体
+type BreakerActionRequest struct {
+	Action string `json:"action"` // trip/reset
+}
+
+// 熔断器状态
+type BreakerStatus struct {
+	Name                string `json:"name"`
+	Dependency          string `json:"dependency"` // 外部系统名称
+	State               string `json:"state"`      // closed/open/halfOpen
+	Forced              bool   `json:"forced"`     // 管理员强制熔断，不会自动恢复
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	FailureThreshold    int    `json:"failureThreshold"`
+	OpenTimeout         string `json:"openTimeout"`
+	OpenedAt            string `json:"openedAt,omitempty"`
+	RetryAt             string `json:"retryAt,omitempty"` // 预计放行试探调用的时间
+	LastError           string `json:"lastError,omitempty"`
+	Successes           int64  `json:"successes"` // 以下为服务启动以来累计
+	Failures            int64  `json:"failures"`
+	Rejected            int64  `json:"rejected"` // 熔断期间被直接拒绝的调用
+	Trips               int64  `json:"trips"`    // 熔断次数
+}
+
+// 熔断器：连续失败达到阈值后熔断，熔断期间调用直接失败，超时后放行一次试探调用。
+// 按真实时间计算，与模拟时钟无关
+type CircuitBreaker struct {
+	name string
+	cfg  BreakerConfig
+
+	mu        sync.Mutex
+	state     string
+	forced    bool
+	probing   bool // 半开状态下已放行试探调用，结果返回前拒绝其他调用
+	failures  int
+	openedAt  time.Time
+	lastError string
+	stats     BreakerStatus
+}
+
+// 调用前检查：熔断中返回 CODE_SERVER_BUSY 错误，调用方应走降级逻辑；放行的调用须以 Record 回报结果
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BREAKER_OPEN && !b.forced && time.Since(b.openedAt) >= b.cfg.OpenTimeout {
+		b.setState(BREAKER_HALF_OPEN, "")
+	}
+	if b.state == BREAKER_CLOSED || (b.state == BREAKER_HALF_OPEN && !b.probing) {
+		b.probing = b.state == BREAKER_HALF_OPEN
+		return nil
+	}
+	b.stats.Rejected++
+	return model.NewError(model.CODE_SERVER_BUSY, breakerLabels[b.name]+"暂不可用（熔断中），请稍后重试")
+}
+
+// 回报调用结果
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.stats.Successes++
+		b.failures = 0
+		if b.state == BREAKER_HALF_OPEN {
+			b.setState(BREAKER_CLOSED, "")
+		}
+		return
+	}
+	b.stats.Failures++
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == BREAKER_HALF_OPEN || (b.state == BREAKER_CLOSED && b.failures >= b.cfg.FailureThreshold) {
+		b.setState(BREAKER_OPEN, b.lastError)
+	}
+}
+
+// 是否处于熔断中（不放行试探调用，供调用方提前走降级逻辑）
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == BREAKER_OPEN && (b.forced || time.Since(b.openedAt) < b.cfg.OpenTimeout)
+}
+
+// 受熔断保护地执行一次调用
+func (b *CircuitBreaker) Call(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.Record(err)
+	return err
+}
+
+// 当前状态
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := b.stats
+	status.Name = b.name
+	status.Dependency = breakerLabels[b.name]
+	status.State = b.state
+	status.Forced = b.forced
+	status.ConsecutiveFailures = b.failures
+	status.FailureThreshold = b.cfg.FailureThreshold
+	status.OpenTimeout = b.cfg.OpenTimeout.String()
+	status.LastError = b.lastError
+	if b.state != BREAKER_CLOSED {
+		status.OpenedAt = b.openedAt.Format("2006-01-02 15:04:05")
+		if !b.forced {
+			status.RetryAt = b.openedAt.Add(b.cfg.OpenTimeout).Format("2006-01-02 15:04:05")
+		}
+	}
+	return status
+}
+
+// 切换状态并在终端提示（调用方需持有 b.mu）
+func (b *CircuitBreaker) setState(state, reason string) {
+	previous := b.state
+	b.state = state
+	switch state {
+	case BREAKER_OPEN:
+		b.openedAt = time.Now()
+		b.stats.Trips++
+	case BREAKER_CLOSED:
+		b.forced = false
+		b.failures = 0
+	}
+
+	// 终端提示：熔断状态变更
+	log.Println("\n[⚡ 熔断器状态变更]")
+	log.Printf("外部系统: %s（%s）", breakerLabels[b.name], b.name)
+	log.Printf("状态: %s → %s", previous, state)
+	switch {
+	case state == BREAKER_OPEN && b.forced:
+		log.Printf("管理员强制熔断，恢复前相关调用均走降级逻辑")
+	case state == BREAKER_OPEN:
+		log.Printf("连续失败: %d 次，最后错误: %s", b.failures, reason)
+		log.Printf("熔断 %s 后放行试探调用", b.cfg.OpenTimeout)
+	case state == BREAKER_HALF_OPEN:
+		log.Printf("熔断超时，放行一次试探调用")
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 熔断器注册表：为各模拟外部系统各维护一个熔断器
+type BreakerRegistry struct {
+	breakers map[string]*CircuitBreaker
+}
+
+func NewBreakerRegistry(cfg BreakerConfig) *BreakerRegistry {
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = 1
+	}
+	r := &BreakerRegistry{breakers: make(map[string]*CircuitBreaker, len(breakerLabels))}
+	for name := range breakerLabels {
+		r.breakers[name] = &CircuitBreaker{name: name, cfg: cfg, state: BREAKER_CLOSED}
+	}
+	return r
+}
+
+// 按名称获取熔断器
+func (r *BreakerRegistry) Get(name string) *CircuitBreaker {
+	return r.breakers[name]
+}
+
+// 全部熔断器状态（按名称排序）
+func (r *BreakerRegistry) Statuses() []BreakerStatus {
+	result := make([]BreakerStatus, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		result = append(result, breaker.Status())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// 管理员强制熔断或恢复熔断器
+func (r *BreakerRegistry) Apply(name string, req BreakerActionRequest) (BreakerStatus, error) {
+	breaker, ok := r.breakers[name]
+	if !ok {
+		return BreakerStatus{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "熔断器不存在: "+name)
+	}
+
+	breaker.mu.Lock()
+	switch req.Action {
+	case BREAKER_ACTION_TRIP:
+		breaker.forced = true
+		breaker.lastError = "管理员强制熔断"
+		if breaker.state != BREAKER_OPEN {
+			breaker.setState(BREAKER_OPEN, breaker.lastError)
+		}
+	case BREAKER_ACTION_RESET:
+		breaker.lastError = ""
+		breaker.probing = false
+		if breaker.state != BREAKER_CLOSED {
+			breaker.setState(BREAKER_CLOSED, "")
+		}
+		breaker.failures = 0
+	default:
+		breaker.mu.Unlock()
+		return BreakerStatus{}, model.NewError(model.CODE_PARAM_ERROR, "不支持的操作，应为 trip 或 reset")
+	}
+	breaker.mu.Unlock()
+	return breaker.Status(), nil
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 预算提醒档位（已用比例，百分比）
+const (
+	BUDGET_WARNING_PERCENT  = 80
+	BUDGET_EXCEEDED_PERCENT = 100
+)
+
+// 预算使用状态
+const (
+	BUDGET_OK       = "ok"       // 未达提醒档位
+	BUDGET_WARNING  = "warning"  // 已用 80% 以上
+	BUDGET_EXCEEDED = "exceeded" // 已用 100% 以上
+)
+
+// 单账户预算数上限
+const MAX_BUDGETS_PER_ACCOUNT = 20
+
+// 退款类交易：入账时冲减所属类别的当月支出
+var budgetRefundTypes = map[string]bool{
+	"card_refund":            true,
+	"interbank_refund":       true,
+	"direct_debit_return":    true,
+	"interchange_fee_refund": true,
+}
+
+// 月度预算（金额为账户币种）
+type Budget struct {
+	BudgetID  string  `json:"budgetId"`
+	AccountID string  `json:"accountId"`
+	Category  string  `json:"category"` // 现金流类别（与 /api/analytics/cashflow 的 category 分组一致）
+	Limit     float64 `json:"limit"`    // 每月预算金额
+	Enabled   bool    `json:"enabled"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
+
+	spent   map[string]float64 // 月份 → 支出（创建当月起累计）
+	alerted map[string]int     // 月份 → 已提醒的最高档位
+}
+
+// 预算使用进度
+type BudgetProgress struct {
+	Budget
+	Month     string  `json:"month"`
+	Spent     float64 `json:"spent"`
+	Remaining float64 `json:"remaining"` // 超支时为负数
+	Percent   float64 `json:"percent"`   // 已用比例（百分比，保留一位小数）
+	Status    string  `json:"status"`    // ok/warning/exceeded
+}
+
+// 创建预算请求
+type BudgetRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Category  string  `json:"category"`
+	Limit     float64 `json:"limit" validate:"amount"`
+}
+
+// 修改预算请求（字段为空表示不变）
+type BudgetUpdateRequest struct {
+	Limit   *float64 `json:"limit" validate:"omitempty,amount"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// 预算服务：用户按类别设置月度预算，订阅总账将每笔支出归入对应类别累计，
+// 当月支出达到预算的 80% 与 100% 时各推送一次 budgetAlert 消息（WebSocket 推送并存入通知中心）
+type BudgetService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu      sync.Mutex           // 叶子锁（流水回调在账户写锁内执行）
+	budgets map[string][]*Budget // 账户ID → 预算（按创建顺序）
+	seq     int64
+}
+
+func NewBudgetService(accounts *repository.AccountRepository, ledger *LedgerService, clock Clock, notifier Notifier, templates *TemplateRegistry) *BudgetService {
+	s := &BudgetService{
+		accounts:  accounts,
+		ledger:    ledger,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		budgets:   make(map[string][]*Budget),
+	}
+	ledger.Subscribe(s.onTransaction)
+	return s
+}
+
+// 创建预算，当月已发生的支出计入进度（不补发提醒）
+func (s *BudgetService) Create(req BudgetRequest) (BudgetProgress, error) {
+	if err := model.Validate(req); err != nil {
+		return BudgetProgress{}, err
+	}
+	if _, exists := s.accounts.Get(req.AccountID); !exists {
+		return BudgetProgress{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if !isCashflowCategory(req.Category) {
+		return BudgetProgress{}, model.NewError(model.CODE_PARAM_ERROR, "预算类别应为 "+strings.Join(CashflowCategories(), "、")+" 之一")
+	}
+
+	// 持有账户读锁统计当月支出并登记预算，期间不会有新流水，避免漏计或重复计入
+	s.accounts.RLock()
+	defer s.accounts.RUnlock()
+
+	now := s.clock.Now()
+	month := now.Format("2006-01")
+	spent := 0.0
+	for _, tx := range s.ledger.Transactions(req.AccountID, monthStart(now), time.Time{}) {
+		if cashflowCategory(tx.Type) == req.Category {
+			spent += budgetSpend(tx)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, budget := range s.budgets[req.AccountID] {
+		if budget.Category == req.Category {
+			return BudgetProgress{}, model.NewError(model.CODE_PARAM_ERROR, "该类别已设置预算（"+budget.BudgetID+"），请修改原预算")
+		}
+	}
+	if len(s.budgets[req.AccountID]) >= MAX_BUDGETS_PER_ACCOUNT {
+		return BudgetProgress{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("每个账户最多设置 %d 个预算", MAX_BUDGETS_PER_ACCOUNT))
+	}
+	s.seq++
+	budget := &Budget{
+		BudgetID:  fmt.Sprintf("BG%08d", s.seq),
+		AccountID: req.AccountID,
+		Category:  req.Category,
+		Limit:     model.RoundAmount(req.Limit),
+		Enabled:   true,
+		CreatedAt: now.Format("2006-01-02 15:04:05"),
+		UpdatedAt: now.Format("2006-01-02 15:04:05"),
+		spent:     map[string]float64{month: math.Max(spent, 0)},
+		alerted:   make(map[string]int),
+	}
+	budget.alerted[month] = budgetLevel(budget, month)
+	s.budgets[req.AccountID] = append(s.budgets[req.AccountID], budget)
+
+	// 终端提示：预算创建
+	log.Println("\n[📊 月度预算创建]")
+	log.Printf("预算ID: %s", budget.BudgetID)
+	log.Printf("账户ID: %s", budget.AccountID)
+	log.Printf("类别: %s，预算: %.2f，本月已支出: %.2f", budget.Category, budget.Limit, budget.spent[month])
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return budgetProgress(budget, month), nil
+}
+
+// 查询账户各预算在指定月份（YYYY-MM，为空表示当月）的使用进度
+func (s *BudgetService) List(accountID, month string) ([]BudgetProgress, error) {
+	month, err := s.month(month)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]BudgetProgress, 0, len(s.budgets[accountID]))
+	for _, budget := range s.budgets[accountID] {
+		result = append(result, budgetProgress(budget, month))
+	}
+	return result, nil
+}
+
+// 查询单个预算在指定月份的使用进度
+func (s *BudgetService) Get(budgetID, month string) (BudgetProgress, error) {
+	month, err := s.month(month)
+	if err != nil {
+		return BudgetProgress{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	budget, _ := s.find(budgetID)
+	if budget == nil {
+		return BudgetProgress{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "预算不存在")
+	}
+	return budgetProgress(budget, month), nil
+}
+
+// 修改预算金额或启用状态。调整金额后按当月新的已用比例重新确定提醒档位，之后再跨越档位时提醒
+func (s *BudgetService) Update(budgetID string, req BudgetUpdateRequest) (BudgetProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	budget, _ := s.find(budgetID)
+	if budget == nil {
+		return BudgetProgress{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "预算不存在")
+	}
+	month := s.clock.Now().Format("2006-01")
+	if req.Limit != nil {
+		budget.Limit = model.RoundAmount(*req.Limit)
+		budget.alerted[month] = budgetLevel(budget, month)
+	}
+	if req.Enabled != nil {
+		budget.Enabled = *req.Enabled
+	}
+	budget.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	return budgetProgress(budget, month), nil
+}
+
+// 删除预算
+func (s *BudgetService) Delete(budgetID string) (BudgetProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	budget, index := s.find(budgetID)
+	if budget == nil {
+		return BudgetProgress{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "预算不存在")
+	}
+	budgets := s.budgets[budget.AccountID]
+	s.budgets[budget.AccountID] = append(budgets[:index:index], budgets[index+1:]...)
+	if len(s.budgets[budget.AccountID]) == 0 {
+		delete(s.budgets, budget.AccountID)
+	}
+	return budgetProgress(budget, s.clock.Now().Format("2006-01")), nil
+}
+
+// 按编号查找预算及其在账户预算列表中的下标（调用方需持有 s.mu）
+func (s *BudgetService) find(budgetID string) (*Budget, int) {
+	for _, budgets := range s.budgets {
+		for i, budget := range budgets {
+			if budget.BudgetID == budgetID {
+				return budget, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// 校验月份参数，为空时取模拟时钟当月
+func (s *BudgetService) month(month string) (string, error) {
+	if month == "" {
+		return s.clock.Now().Format("2006-01"), nil
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return "", model.NewError(model.CODE_PARAM_ERROR, "月份格式错误，应为 YYYY-MM")
+	}
+	return month, nil
+}
+
+// 流水回调：将支出计入对应类别的预算，已用比例首次达到 80%、100% 时提醒（一笔支出同时跨越两档时只提醒 100%）
+// （在账户写锁内执行，只使用叶子锁，推送在释放 s.mu 后进行）
+func (s *BudgetService) onTransaction(tx model.Transaction) {
+	amount := budgetSpend(tx)
+	if amount == 0 {
+		return
+	}
+	category := cashflowCategory(tx.Type)
+	month := tx.Time.Format("2006-01")
+
+	s.mu.Lock()
+	var hits []BudgetProgress
+	for _, budget := range s.budgets[tx.AccountID] {
+		if budget.Category != category {
+			continue
+		}
+		budget.spent[month] = math.Max(model.RoundAmount(budget.spent[month]+amount), 0)
+		level := budgetLevel(budget, month)
+		if level > budget.alerted[month] {
+			if budget.Enabled {
+				hits = append(hits, budgetProgress(budget, month))
+			}
+			budget.alerted[month] = level
+		} else if level < budget.alerted[month] {
+			budget.alerted[month] = level // 退款使已用比例回落后，再次达到时重新提醒
+		}
+	}
+	s.mu.Unlock()
+	if len(hits) == 0 {
+		return
+	}
+
+	account, exists := s.accounts.Find(tx.AccountID)
+	if !exists {
+		return
+	}
+	for _, progress := range hits {
+		msg := s.templates.Alert("budgetAlert", account, EVENT_BUDGET_ALERT, map[string]interface{}{
+			"Category":  progress.Category,
+			"Month":     progress.Month,
+			"Limit":     progress.Limit,
+			"Spent":     progress.Spent,
+			"Percent":   progress.Percent,
+			"Exceeded":  progress.Status == BUDGET_EXCEEDED,
+			"Remaining": progress.Remaining,
+		})
+		msg.Data = map[string]interface{}{
+			"budgetId": progress.BudgetID,
+			"category": progress.Category,
+			"month":    progress.Month,
+			"limit":    progress.Limit,
+			"spent":    progress.Spent,
+			"percent":  progress.Percent,
+			"status":   progress.Status,
+			"txId":     tx.TxID,
+		}
+		s.notifier.Send(msg)
+	}
+}
+
+// 流水计入预算的支出金额：支出为正，退款为负，其余为 0
+func budgetSpend(tx model.Transaction) float64 {
+	if tx.Direction == "debit" {
+		return tx.Amount
+	}
+	if budgetRefundTypes[tx.Type] {
+		return -tx.Amount
+	}
+	return 0
+}
+
+// 预算当月达到的提醒档位（0、80 或 100）
+func budgetLevel(budget *Budget, month string) int {
+	spent := budget.spent[month]
+	switch {
+	case spent >= budget.Limit:
+		return BUDGET_EXCEEDED_PERCENT
+	case spent >= budget.Limit*BUDGET_WARNING_PERCENT/100:
+		return BUDGET_WARNING_PERCENT
+	}
+	return 0
+}
+
+func budgetProgress(budget *Budget, month string) BudgetProgress {
+	spent := model.RoundAmount(budget.spent[month])
+	progress := BudgetProgress{
+		Budget:    *budget,
+		Month:     month,
+		Spent:     spent,
+		Remaining: model.RoundAmount(budget.Limit - spent),
+		Percent:   math.Round(spent/budget.Limit*1000) / 10,
+		Status:    BUDGET_OK,
+	}
+	switch budgetLevel(budget, month) {
+	case BUDGET_EXCEEDED_PERCENT:
+		progress.Status = BUDGET_EXCEEDED
+	case BUDGET_WARNING_PERCENT:
+		progress.Status = BUDGET_WARNING
+	}
+	return progress
+}
+
+func isCashflowCategory(category string) bool {
+	for _, c := range CashflowCategories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 日历查询的最大天数
+const CALENDAR_MAX_DAYS = 366
+
+// 营业日历配置
+type CalendarConfig struct {
+	Holidays string // 节假日列表，格式 "2026-10-01:国庆节,2026-10-02:国庆节"（名称可省略）
+}
+
+// 节假日
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+// 日历中的一天
+type CalendarDay struct {
+	Date        string `json:"date"`
+	Weekday     string `json:"weekday"`
+	BusinessDay bool   `json:"businessDay"`
+	Holiday     string `json:"holiday,omitempty"` // 节假日名称
+}
+
+// 日历查询结果
+type CalendarView struct {
+	Today           string        `json:"today"`
+	NextBusinessDay string        `json:"nextBusinessDay"` // 今日及以后的首个营业日
+	Days            []CalendarDay `json:"days"`
+}
+
+var weekdayNames = [...]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+// 营业日历：周末与节假日为非营业日，跨行清算等计划处理遇非营业日顺延至下一营业日
+type CalendarService struct {
+	clock Clock
+
+	mu       sync.RWMutex      // 叶子锁
+	holidays map[string]string // 日期 → 节假日名称
+}
+
+func NewCalendarService(cfg CalendarConfig, clock Clock) *CalendarService {
+	s := &CalendarService{
+		clock:    clock,
+		holidays: make(map[string]string),
+	}
+	for _, item := range strings.Split(cfg.Holidays, ",") {
+		date, name, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if date == "" {
+			continue
+		}
+		if _, err := time.ParseInLocation("2006-01-02", date, time.Local); err != nil {
+			log.Printf("节假日配置 %s 格式错误，已忽略", item)
+			continue
+		}
+		if name == "" {
+			name = "节假日"
+		}
+		s.holidays[date] = name
+	}
+	return s
+}
+
+// 是否为营业日（非周末且非节假日）
+func (s *CalendarService) IsBusinessDay(t time.Time) bool {
+	if weekday := t.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, holiday := s.holidays[t.Format("2006-01-02")]
+	return !holiday
+}
+
+// 当日及以后的首个营业日（零点）
+func (s *CalendarService) NextBusinessDay(t time.Time) time.Time {
+	day := sim.StartOfDay(t)
+	for i := 0; i < CALENDAR_MAX_DAYS && !s.IsBusinessDay(day); i++ {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// 计划处理时间顺延：营业日原样返回，非营业日顺延至下一营业日零点
+func (s *CalendarService) Roll(t time.Time) time.Time {
+	if s.IsBusinessDay(t) {
+		return t
+	}
+	return s.NextBusinessDay(t)
+}
+
+// 查询 [from, to] 日期区间（含首尾）的营业日历
+func (s *CalendarService) Days(from, to time.Time) (CalendarView, error) {
+	from, to = sim.StartOfDay(from), sim.StartOfDay(to)
+	if to.Before(from) || to.Sub(from) >= CALENDAR_MAX_DAYS*24*time.Hour {
+		return CalendarView{}, model.NewError(model.CODE_PARAM_ERROR, "日期区间错误，from 不能晚于 to 且最多查询 366 天")
+	}
+
+	now := s.clock.Now()
+	view := CalendarView{
+		Today:           now.Format("2006-01-02"),
+		NextBusinessDay: s.NextBusinessDay(now).Format("2006-01-02"),
+		Days:            []CalendarDay{},
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		s.mu.RLock()
+		holiday := s.holidays[date]
+		s.mu.RUnlock()
+		view.Days = append(view.Days, CalendarDay{
+			Date:        date,
+			Weekday:     weekdayNames[day.Weekday()],
+			BusinessDay: s.IsBusinessDay(day),
+			Holiday:     holiday,
+		})
+	}
+	return view, nil
+}
+
+// 节假日列表（按日期排序）
+func (s *CalendarService) Holidays() []Holiday {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []Holiday{}
+	for date, name := range s.holidays {
+		result = append(result, Holiday{Date: date, Name: name})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// 新增或修改节假日
+func (s *CalendarService) AddHoliday(holiday Holiday) (Holiday, error) {
+	holiday.Name = strings.TrimSpace(holiday.Name)
+	if _, err := time.ParseInLocation("2006-01-02", holiday.Date, time.Local); err != nil {
+		return Holiday{}, model.NewError(model.CODE_PARAM_ERROR, "日期格式错误，应为 YYYY-MM-DD")
+	}
+	if holiday.Name == "" {
+		holiday.Name = "节假日"
+	}
+
+	s.mu.Lock()
+	s.holidays[holiday.Date] = holiday.Name
+	s.mu.Unlock()
+
+	log.Printf("[📅 营业日历] 新增节假日: %s %s", holiday.Date, holiday.Name)
+	return holiday, nil
+}
+
+// 删除节假日
+func (s *CalendarService) RemoveHoliday(date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.holidays[date]; !ok {
+		return model.NewError(model.CODE_RESOURCE_NOT_FOUND, "该日期不是节假日")
+	}
+	delete(s.holidays, date)
+	log.Printf("[📅 营业日历] 删除节假日: %s", date)
+	return nil
+}
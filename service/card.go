@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 卡交易请求（由 ISO 8583 报文字段转换而来）
+type CardRequest struct {
+	PAN       string // 主账号（字段 2）
+	Amount    string // 交易金额，单位为分（字段 4）
+	RRN       string // 检索参考号（字段 37）
+	Terminal  string // 终端号（字段 41）
+	Merchant  string // 商户号（字段 42）
+	Currency  string // ISO 4217 数字币种码（字段 49）
+	AccountID string // 账户标识（字段 102，为空时按卡号查找）
+}
+
+// 预授权有效期（模拟时间）：到期未消费的授权失效，冻结的资金自动解除
+const CARD_AUTH_TTL = 7 * 24 * time.Hour
+
+// 卡交易授权记录
+type CardAuthorization struct {
+	RRN       string  `json:"rrn"`
+	PAN       string  `json:"pan"`
+	AccountID string  `json:"accountId"`
+	Amount    float64 `json:"amount"`
+	AuthCode  string  `json:"authCode"`
+	HoldID    string  `json:"holdId"` // 授权金额对应的冻结
+	Time      string  `json:"time"`
+	ExpiresAt string  `json:"expiresAt"`
+
+	expiresAt time.Time
+}
+
+// 卡交易被拒绝（应答码另行返回）
+var errCardDeclined = errors.New("卡交易被拒绝")
+
+var (
+	// 模拟卡片库（卡号 → 账户ID）
+	cards = map[string]string{
+		"6228480012345678": "8001234567",
+		"6228480087654321": "8001234568",
+	}
+
+	// ISO 4217 数字币种码 → 字母币种码
+	iso4217Numeric = map[string]string{
+		"156": "CNY",
+		"840": "USD",
+		"978": "EUR",
+		"826": "GBP",
+		"344": "HKD",
+		"392": "JPY",
+	}
+)
+
+// 银行卡服务：卡交易授权与消费扣款，结果以 ISO 8583 应答码表示
+type CardService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	cashback  *CashbackService
+	credit    *CreditService
+	holds     *HoldService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	authorizations map[string]CardAuthorization // 有效的授权记录（按 RRN 索引，0200 消费时核销，到期清理）
+	mu             sync.Mutex
+}
+
+func NewCardService(accounts *repository.AccountRepository, ledger *LedgerService, cashback *CashbackService, credit *CreditService, holds *HoldService, clock Clock, notifier Notifier, templates *TemplateRegistry) *CardService {
+	return &CardService{
+		accounts:       accounts,
+		ledger:         ledger,
+		cashback:       cashback,
+		credit:         credit,
+		holds:          holds,
+		clock:          clock,
+		notifier:       notifier,
+		templates:      templates,
+		authorizations: make(map[string]CardAuthorization),
+	}
+}
+
+// 测试卡片（卡号 → 账户ID）
+func (s *CardService) Cards() map[string]string {
+	return cards
+}
+
+// 0100 授权请求：校验卡片、账户状态与余额，按授权金额冻结资金并记录授权（须送检索参考号，消费时据此核销），
+// 返回授权码与应答码
+func (s *CardService) Authorize(req CardRequest) (string, string) {
+	accountID, amount, code := s.resolve(req)
+	if code != "" {
+		return "", code
+	}
+	rrn := strings.TrimSpace(req.RRN)
+	if rrn == "" {
+		return "", "30" // 格式错误
+	}
+	now := s.clock.Now()
+	s.expireAuthorizations(now)
+
+	s.mu.Lock()
+	_, duplicate := s.authorizations[rrn]
+	s.mu.Unlock()
+	if duplicate {
+		return "", "94" // 重复交易
+	}
+
+	account, exists := s.accounts.Get(accountID)
+	if code := checkCardAccount(account, exists, amount); code != "" {
+		if code == "51" {
+			s.credit.RecordNSF(accountID)
+		}
+		return "", code
+	}
+
+	// 冻结授权金额，消费前其他交易不能动用
+	expiresAt := now.Add(CARD_AUTH_TTL)
+	hold, err := s.holds.Place(HoldRequest{
+		AccountID: accountID,
+		Amount:    amount,
+		Reason:    fmt.Sprintf("银行卡预授权（卡号尾号 %s，检索参考号 %s）", panSuffix(req.PAN), rrn),
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+	})
+	if err != nil {
+		if code, _ := model.ErrorCode(err); code == model.CODE_BALANCE_NOT_ENOUGH {
+			s.credit.RecordNSF(accountID) // 校验后可用余额被其他交易占用
+			return "", "51"
+		}
+		return "", "96" // 系统故障
+	}
+
+	authCode := fmt.Sprintf("%06d", rand.Intn(1000000))
+	s.mu.Lock()
+	s.authorizations[rrn] = CardAuthorization{
+		RRN:       rrn,
+		PAN:       req.PAN,
+		AccountID: accountID,
+		Amount:    amount,
+		AuthCode:  authCode,
+		HoldID:    hold.HoldID,
+		Time:      now.Format("2006-01-02 15:04:05"),
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+		expiresAt: expiresAt,
+	}
+	s.mu.Unlock()
+	return authCode, "00"
+}
+
+// 0200 金融交易请求：扣款并记账，返回授权码与应答码。有同 RRN 的有效授权时核销授权冻结，
+// 解冻与扣款在同一账户锁内完成；授权冻结已被解除时按无授权消费处理
+func (s *CardService) Purchase(req CardRequest) (string, string) {
+	accountID, amount, code := s.resolve(req)
+	if code != "" {
+		return "", code
+	}
+	s.expireAuthorizations(s.clock.Now())
+
+	var account model.Account
+	debit := func(current model.Account) (model.Account, error) {
+		if code = checkCardAccount(current, true, amount); code != "" {
+			return current, errCardDeclined
+		}
+		current.Balance = model.RoundAmount(current.Balance - amount)
+		account = s.accounts.Save(current)
+		s.ledger.Record(model.Transaction{
+			AccountID:    accountID,
+			Type:         "card_purchase",
+			Direction:    "debit",
+			Amount:       amount,
+			BalanceAfter: account.Balance,
+			Counterparty: strings.TrimSpace(req.Merchant),
+			Reference:    req.RRN,
+			Description:  "银行卡消费 终端" + strings.TrimSpace(req.Terminal),
+		})
+		s.cashback.Accrue(account, amount, req.RRN, strings.TrimSpace(req.Merchant))
+		return account, nil
+	}
+
+	authCode := fmt.Sprintf("%06d", rand.Intn(1000000))
+	rrn := strings.TrimSpace(req.RRN)
+	s.mu.Lock()
+	auth, authorized := s.authorizations[rrn]
+	s.mu.Unlock()
+
+	captured := false
+	if authorized && rrn != "" && auth.AccountID == accountID {
+		captured = s.holds.Capture(auth.HoldID, debit) == nil
+		if code == "" {
+			s.mu.Lock()
+			delete(s.authorizations, rrn) // 已核销，或授权冻结已被解除（按无授权消费处理）；被拒绝时保留授权
+			s.mu.Unlock()
+		}
+		if captured {
+			authCode = auth.AuthCode
+		}
+	}
+	if !captured && code == "" {
+		s.accounts.Lock()
+		current, exists := s.accounts.Find(accountID)
+		if !exists {
+			code = "14" // 无效卡号
+		} else {
+			debit(current)
+		}
+		s.accounts.Unlock()
+	}
+	if code != "" {
+		if code == "51" {
+			s.credit.RecordNSF(accountID)
+		}
+		return "", code
+	}
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_CARD_PURCHASE, map[string]interface{}{
+		"Amount":     amount,
+		"Balance":    account.Balance,
+		"CardSuffix": panSuffix(req.PAN),
+		"Merchant":   strings.TrimSpace(req.Merchant),
+	}))
+	return authCode, "00"
+}
+
+// 清理已到期的授权记录（对应的冻结由冻结服务按到期时间自动解除）
+func (s *CardService) expireAuthorizations(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for rrn, auth := range s.authorizations {
+		if !now.Before(auth.expiresAt) {
+			delete(s.authorizations, rrn)
+		}
+	}
+}
+
+// 导出有效的授权记录（状态快照使用，按检索参考号排序）
+func (s *CardService) Snapshot() []CardAuthorization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]CardAuthorization, 0, len(s.authorizations))
+	for _, auth := range s.authorizations {
+		result = append(result, auth)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RRN < result[j].RRN })
+	return result
+}
+
+// 以快照替换授权记录（状态快照使用）
+func (s *CardService) Restore(authorizations []CardAuthorization) {
+	restored := make(map[string]CardAuthorization, len(authorizations))
+	for _, auth := range authorizations {
+		auth.expiresAt, _ = time.ParseInLocation("2006-01-02 15:04:05", auth.ExpiresAt, time.Local)
+		restored[auth.RRN] = auth
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.authorizations = restored
+}
+
+// 解析卡交易的账户与金额，失败时返回应答码
+func (s *CardService) resolve(req CardRequest) (string, float64, string) {
+	accountID := req.AccountID
+	if accountID == "" {
+		var ok bool
+		if accountID, ok = cards[req.PAN]; !ok {
+			return "", 0, "14" // 无效卡号
+		}
+	}
+
+	minor, err := strconv.ParseInt(req.Amount, 10, 64)
+	if err != nil || minor <= 0 {
+		return "", 0, "13" // 无效金额
+	}
+
+	// 交易币种须与账户币种一致（未送币种时按账户币种处理）
+	if req.Currency != "" {
+		account, exists := s.accounts.Get(accountID)
+		if exists && iso4217Numeric[req.Currency] != account.Currency {
+			return "", 0, "57" // 不允许的交易
+		}
+	}
+	return accountID, float64(minor) / 100, ""
+}
+
+// 校验卡交易账户，失败时返回应答码
+func checkCardAccount(account model.Account, exists bool, amount float64) string {
+	switch {
+	case !exists:
+		return "14" // 无效卡号
+	case account.Status != "normal":
+		return "62" // 受限制的卡
+	case account.Available() < amount:
+		return "51" // 余额不足
+	}
+	return ""
+}
+
+// 卡号后四位
+func panSuffix(pan string) string {
+	if len(pan) < 4 {
+		return pan
+	}
+	return pan[len(pan)-4:]
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 预授权冻结授权金额，消费时核销冻结并扣款：授权期间可用余额减少，消费后冻结归零、账面余额扣减
+func TestCardAuthorizationHoldsFundsUntilPurchase(t *testing.T) {
+	cards, accounts, holds, _ := newCardFixture(t, 1000)
+	req := CardRequest{PAN: "6228480012345678", Amount: "80000", RRN: "000000000001", Terminal: "T1", Merchant: "M1"}
+
+	authCode, code := cards.Authorize(req)
+	if code != "00" {
+		t.Fatalf("预授权应答码 %s，应为 00", code)
+	}
+	account, _ := accounts.Get("8001234567")
+	if account.Held != 800 || account.Available() != 200 {
+		t.Fatalf("预授权后冻结 %.2f、可用 %.2f，应为 800、200", account.Held, account.Available())
+	}
+
+	// 授权金额已冻结，同一账户的其他交易不能再动用
+	if _, code := cards.Purchase(CardRequest{PAN: req.PAN, Amount: "30000", RRN: "000000000002"}); code != "51" {
+		t.Fatalf("超出可用余额的无授权消费应答码 %s，应为 51", code)
+	}
+
+	purchaseCode, code := cards.Purchase(req)
+	if code != "00" || purchaseCode != authCode {
+		t.Fatalf("消费应答码 %s、授权码 %s，应为 00、%s", code, purchaseCode, authCode)
+	}
+	account, _ = accounts.Get("8001234567")
+	if account.Balance != 200 || account.Held != 0 {
+		t.Fatalf("消费后余额 %.2f、冻结 %.2f，应为 200、0", account.Balance, account.Held)
+	}
+	summary, _ := holds.List("8001234567", "captured")
+	if len(summary.Holds) != 1 {
+		t.Fatalf("已核销的冻结 %d 笔，应为 1 笔", len(summary.Holds))
+	}
+	if _, code := cards.Purchase(req); code != "51" {
+		t.Fatalf("授权已核销，重复的消费报文应按无授权消费处理（余额不足），应答码 %s", code)
+	}
+}
+
+// 预授权须送检索参考号，重复的检索参考号拒绝
+func TestCardAuthorizationRequiresUniqueRRN(t *testing.T) {
+	cards, _, _, _ := newCardFixture(t, 1000)
+	if _, code := cards.Authorize(CardRequest{PAN: "6228480012345678", Amount: "100"}); code != "30" {
+		t.Fatalf("未送检索参考号应答码 %s，应为 30", code)
+	}
+	req := CardRequest{PAN: "6228480012345678", Amount: "100", RRN: "000000000003"}
+	cards.Authorize(req)
+	if _, code := cards.Authorize(req); code != "94" {
+		t.Fatalf("重复检索参考号应答码 %s，应为 94", code)
+	}
+}
+
+// 到期未消费的授权清理记录并解除冻结
+func TestCardAuthorizationExpires(t *testing.T) {
+	cards, accounts, holds, clock := newCardFixture(t, 1000)
+	cards.Authorize(CardRequest{PAN: "6228480012345678", Amount: "50000", RRN: "000000000004"})
+
+	clock.now = clock.now.Add(CARD_AUTH_TTL)
+	holds.ExpireHolds(clock.now)
+	cards.expireAuthorizations(clock.now)
+
+	if n := len(cards.authorizations); n != 0 {
+		t.Fatalf("到期后仍有 %d 条授权记录", n)
+	}
+	account, _ := accounts.Get("8001234567")
+	if math.Abs(account.Held) > 0.001 || account.Available() != 1000 {
+		t.Fatalf("授权到期后冻结 %.2f、可用 %.2f，应为 0、1000", account.Held, account.Available())
+	}
+}
+
+// 构造银行卡服务（测试卡 6228480012345678 对应账户 8001234567）
+func newCardFixture(t *testing.T, balance float64) (*CardService, *repository.AccountRepository, *HoldService, *testClock) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2025, 3, 3, 10, 0, 0, 0, time.Local)}
+	accounts := repository.NewAccountRepository([]model.Account{
+		{AccountID: "8001234567", UserName: "持卡人", Balance: balance, Currency: "CNY", Type: ACCOUNT_TYPE_SAVINGS, Status: "normal"},
+	})
+	journal := repository.NewJournalRepository()
+	notifier := nopNotifier{}
+	templates := NewTemplateRegistry(filepath.Join(t.TempDir(), "templates"))
+
+	ledger := NewLedgerService(accounts, journal, NewChartOfAccounts(nil), clock, notifier)
+	fx := NewFXService(FXConfig{}, notifier, nil)
+	credit := NewCreditService(accounts, ledger, fx, clock)
+	cashback := NewCashbackService(CashbackConfig{}, accounts, ledger, fx, clock, notifier, templates)
+	holds := NewHoldService(accounts, NewAuditService(clock), clock, notifier, templates)
+	return NewCardService(accounts, ledger, cashback, credit, holds, clock, notifier, templates), accounts, holds, clock
+}
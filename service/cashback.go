@@ -0,0 +1,324 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 消费返现配置（金额为人民币，其他币种账户按汇率折算）
+type CashbackConfig struct {
+	Rate       float64 `json:"rate"`       // 返现比例（如 0.005 表示 0.5%），0 表示不启用
+	MinSpend   float64 `json:"minSpend"`   // 单笔消费起点
+	MonthlyCap float64 `json:"monthlyCap"` // 每个账户每月返现上限，0 表示不限
+}
+
+// 消费返现配置调整请求结构体（字段为空表示不变）
+type CashbackConfigRequest struct {
+	Rate       *float64 `json:"rate" validate:"omitempty,min=0,max=0.1"`
+	MinSpend   *float64 `json:"minSpend" validate:"omitempty,min=0"`
+	MonthlyCap *float64 `json:"monthlyCap" validate:"omitempty,min=0"`
+}
+
+// 单笔消费返现明细
+type CashbackEntry struct {
+	RRN      string  `json:"rrn"`
+	Merchant string  `json:"merchant,omitempty"`
+	Spend    float64 `json:"spend"`    // 消费金额
+	Cashback float64 `json:"cashback"` // 返现金额
+	Month    string  `json:"month"`    // 所属月份（2006-01）
+	Time     string  `json:"time"`
+	Posted   bool    `json:"posted"`
+}
+
+// 月度返现入账记录
+type CashbackPosting struct {
+	Month  string  `json:"month"`
+	Amount float64 `json:"amount"`
+	TxID   string  `json:"txId"`
+	Time   string  `json:"time"`
+}
+
+// 账户返现汇总
+type CashbackSummary struct {
+	AccountID    string            `json:"accountId"`
+	Currency     string            `json:"currency"`
+	Rate         float64           `json:"rate"`
+	MonthlyCap   float64           `json:"monthlyCap"`   // 折算为账户币种
+	Pending      float64           `json:"pending"`      // 待入账返现
+	MonthEarned  float64           `json:"monthEarned"`  // 本月已累计返现
+	TotalPosted  float64           `json:"totalPosted"`  // 累计已入账返现
+	PendingItems []CashbackEntry   `json:"pendingItems"` // 待入账明细
+	Postings     []CashbackPosting `json:"postings"`     // 入账记录（按时间倒序）
+}
+
+// 消费返现服务：银行卡消费按比例累计待入账返现，月末日终统一入账
+type CashbackService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	fx        *FXService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu       sync.Mutex // 叶子锁：持有期间不得获取账户锁（卡消费在账户锁内累计返现）
+	cfg      CashbackConfig
+	entries  map[string][]CashbackEntry   // 账户ID -> 返现明细
+	postings map[string][]CashbackPosting // 账户ID -> 入账记录
+}
+
+func NewCashbackService(cfg CashbackConfig, accounts *repository.AccountRepository, ledger *LedgerService, fx *FXService, clock Clock, notifier Notifier, templates *TemplateRegistry) *CashbackService {
+	return &CashbackService{
+		accounts:  accounts,
+		ledger:    ledger,
+		fx:        fx,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		cfg:       cfg,
+		entries:   make(map[string][]CashbackEntry),
+		postings:  make(map[string][]CashbackPosting),
+	}
+}
+
+// 查询返现配置
+func (s *CashbackService) Config() CashbackConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// 调整返现配置（对之后的消费生效）
+func (s *CashbackService) SetConfig(req CashbackConfigRequest) (CashbackConfig, error) {
+	if err := model.Validate(req); err != nil {
+		return CashbackConfig{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Rate != nil {
+		s.cfg.Rate = *req.Rate
+	}
+	if req.MinSpend != nil {
+		s.cfg.MinSpend = model.RoundAmount(*req.MinSpend)
+	}
+	if req.MonthlyCap != nil {
+		s.cfg.MonthlyCap = model.RoundAmount(*req.MonthlyCap)
+	}
+	return s.cfg, nil
+}
+
+// 银行卡消费累计返现（调用方可持有账户锁），返回本笔返现金额
+func (s *CashbackService) Accrue(account model.Account, spend float64, rrn, merchant string) float64 {
+	now := s.clock.Now()
+	month := now.Format("2006-01")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.Rate <= 0 || spend < s.fx.FromBase(s.cfg.MinSpend, account.Currency) {
+		return 0
+	}
+	cashback := math.Floor(spend*s.cfg.Rate*100) / 100
+	if s.cfg.MonthlyCap > 0 {
+		earned := 0.0
+		for _, e := range s.entries[account.AccountID] {
+			if e.Month == month {
+				earned += e.Cashback
+			}
+		}
+		if remaining := model.RoundAmount(s.fx.FromBase(s.cfg.MonthlyCap, account.Currency) - earned); cashback > remaining {
+			cashback = remaining
+		}
+	}
+	if cashback <= 0 {
+		return 0
+	}
+	s.entries[account.AccountID] = append(s.entries[account.AccountID], CashbackEntry{
+		RRN:      rrn,
+		Merchant: merchant,
+		Spend:    spend,
+		Cashback: cashback,
+		Month:    month,
+		Time:     now.Format("2006-01-02 15:04:05"),
+	})
+	return cashback
+}
+
+// 日终任务：月末将截至当月的待入账返现转入账户（非正常状态的账户顺延至下月）
+func (s *CashbackService) Post(day time.Time) {
+	if day.AddDate(0, 0, 1).Day() != 1 {
+		return
+	}
+	month := day.Format("2006-01")
+
+	type pending struct {
+		accountID string
+		amount    float64
+	}
+	var due []pending
+	s.mu.Lock()
+	for accountID, entries := range s.entries {
+		amount := 0.0
+		for _, e := range entries {
+			if !e.Posted && e.Month <= month {
+				amount += e.Cashback
+			}
+		}
+		if amount = model.RoundAmount(amount); amount > 0 {
+			due = append(due, pending{accountID: accountID, amount: amount})
+		}
+	}
+	s.mu.Unlock()
+
+	type posted struct {
+		account model.Account
+		amount  float64
+		txID    string
+	}
+	var results []posted
+	s.accounts.Lock()
+	for _, p := range due {
+		account, exists := s.accounts.Find(p.accountID)
+		if !exists || account.Status != "normal" {
+			continue
+		}
+		account.Balance = model.RoundAmount(account.Balance + p.amount)
+		account = s.accounts.Save(account)
+		tx := s.ledger.Record(model.Transaction{
+			AccountID:    p.accountID,
+			Type:         "cashback",
+			Direction:    "credit",
+			Amount:       p.amount,
+			BalanceAfter: account.Balance,
+			Description:  fmt.Sprintf("%s消费返现", day.Format("2006年01月")),
+		})
+		results = append(results, posted{account: account, amount: p.amount, txID: tx.TxID})
+	}
+	s.accounts.Unlock()
+
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+	s.mu.Lock()
+	for _, r := range results {
+		entries := s.entries[r.account.AccountID]
+		for i := range entries {
+			if entries[i].Month <= month {
+				entries[i].Posted = true
+			}
+		}
+		s.postings[r.account.AccountID] = append(s.postings[r.account.AccountID], CashbackPosting{
+			Month:  month,
+			Amount: r.amount,
+			TxID:   r.txID,
+			Time:   now,
+		})
+	}
+	s.mu.Unlock()
+
+	for _, r := range results {
+		s.notifier.Send(ws.Message{
+			Type:       "balanceUpdate",
+			AccountID:  r.account.AccountID,
+			NewBalance: r.account.Balance,
+		})
+		s.notifier.Send(s.templates.Alert("transactionAlert", r.account, EVENT_CASHBACK_POSTED, map[string]interface{}{
+			"Amount":  r.amount,
+			"Balance": r.account.Balance,
+			"Month":   month,
+		}))
+	}
+
+	// 终端提示：月末消费返现入账
+	log.Println("\n[💳 月末消费返现入账]")
+	log.Printf("入账日期: %s", day.Format("2006-01-02"))
+	for _, r := range results {
+		log.Printf("账户ID: %s | 用户名: %s | 返现: +%.2f %s | 余额: %.2f %s",
+			r.account.AccountID, r.account.UserName, r.amount, r.account.Currency, r.account.Balance, r.account.Currency)
+	}
+	log.Printf("入账账户数: %d", len(results))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 账户返现汇总：待入账明细、本月累计与入账记录
+func (s *CashbackService) Summary(accountID string) (CashbackSummary, error) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return CashbackSummary{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	month := s.clock.Now().Format("2006-01")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := CashbackSummary{
+		AccountID:    accountID,
+		Currency:     account.Currency,
+		Rate:         s.cfg.Rate,
+		MonthlyCap:   s.fx.FromBase(s.cfg.MonthlyCap, account.Currency),
+		PendingItems: []CashbackEntry{},
+		Postings:     []CashbackPosting{},
+	}
+	for _, e := range s.entries[accountID] {
+		if e.Month == month {
+			summary.MonthEarned += e.Cashback
+		}
+		if !e.Posted {
+			summary.Pending += e.Cashback
+			summary.PendingItems = append(summary.PendingItems, e)
+		}
+	}
+	postings := s.postings[accountID]
+	for i := len(postings) - 1; i >= 0; i-- {
+		summary.TotalPosted += postings[i].Amount
+		summary.Postings = append(summary.Postings, postings[i])
+	}
+	summary.Pending = model.RoundAmount(summary.Pending)
+	summary.MonthEarned = model.RoundAmount(summary.MonthEarned)
+	summary.TotalPosted = model.RoundAmount(summary.TotalPosted)
+	return summary, nil
+}
+
+// 返现账本快照（随状态快照导出）
+type CashbackSnapshot struct {
+	Entries  map[string][]CashbackEntry   `json:"entries"`
+	Postings map[string][]CashbackPosting `json:"postings"`
+}
+
+// 导出返现明细与入账记录
+func (s *CashbackService) Snapshot() CashbackSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := CashbackSnapshot{Entries: make(map[string][]CashbackEntry, len(s.entries)), Postings: make(map[string][]CashbackPosting, len(s.postings))}
+	for accountID, entries := range s.entries {
+		snapshot.Entries[accountID] = append([]CashbackEntry{}, entries...)
+	}
+	for accountID, postings := range s.postings {
+		snapshot.Postings[accountID] = append([]CashbackPosting{}, postings...)
+	}
+	return snapshot
+}
+
+// 以快照替换返现明细与入账记录
+func (s *CashbackService) Restore(snapshot CashbackSnapshot) {
+	entries := make(map[string][]CashbackEntry, len(snapshot.Entries))
+	for accountID, list := range snapshot.Entries {
+		entries[accountID] = append([]CashbackEntry{}, list...)
+	}
+	postings := make(map[string][]CashbackPosting, len(snapshot.Postings))
+	for accountID, list := range snapshot.Postings {
+		postings[accountID] = append([]CashbackPosting{}, list...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries, s.postings = entries, postings
+}
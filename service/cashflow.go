@@ -0,0 +1,244 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 现金流分组维度
+const (
+	CASHFLOW_GROUP_CATEGORY     = "category"
+	CASHFLOW_GROUP_COUNTERPARTY = "counterparty"
+)
+
+// 交易类型 → 现金流类别（未列出的类型归入 other；开户余额不是资金流动，不计入）
+var cashflowCategories = map[string]string{
+	"transfer_in":            "transfer",
+	"transfer_out":           "transfer",
+	"interbank_out":          "transfer",
+	"interbank_refund":       "transfer",
+	"interbank_in":           "transfer",
+	"suspense_release":       "transfer",
+	"card_purchase":          "card",
+	"card_refund":            "card",
+	"pos_sale":               "merchant",
+	"pos_refund":             "merchant",
+	"interchange_fee":        "merchant",
+	"interchange_fee_refund": "merchant",
+	"deposit":                "cash",
+	"teller_deposit":         "cash",
+	"teller_withdraw":        "cash",
+	"payroll_credit":         "salary",
+	"payroll_debit":          "salary",
+	"direct_debit":           "direct_debit",
+	"direct_debit_return":    "direct_debit",
+	"loan_disbursement":      "loan",
+	"loan_principal":         "loan",
+	"loan_interest":          "loan",
+	"loan_penalty":           "loan",
+	"loan_reversal":          "loan",
+	"interest":               "interest",
+	"withholding_tax":        "tax",
+	"fee":                    "fee",
+	"cashback":               "rewards",
+	"points_redemption":      "rewards",
+}
+
+// 流入、流出合计
+type CashflowTotals struct {
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Net     float64 `json:"net"`
+	Count   int     `json:"count"`
+}
+
+// 分组合计（类别或对方）
+type CashflowGroup struct {
+	Key string `json:"key"`
+	CashflowTotals
+}
+
+// 单月现金流
+type CashflowMonth struct {
+	Month string `json:"month"` // YYYY-MM
+	CashflowTotals
+	Groups []CashflowGroup `json:"groups,omitempty"` // 按流入流出总额倒序
+}
+
+// 账户现金流汇总（账户币种，月份正序，仅含有交易的月份）
+type CashflowReport struct {
+	AccountID string          `json:"accountId"`
+	Currency  string          `json:"currency"`
+	GroupBy   string          `json:"groupBy,omitempty"`
+	Months    []CashflowMonth `json:"months"`
+	Total     CashflowTotals  `json:"total"`
+}
+
+// 单月累计
+type cashflowBucket struct {
+	totals         CashflowTotals
+	byCategory     map[string]*CashflowTotals
+	byCounterparty map[string]*CashflowTotals
+}
+
+// 现金流服务：按账户、月份累计流入与流出。每次查询只读取上次之后新增的流水，
+// 流水被快照替换时重新累计
+type CashflowService struct {
+	accounts *repository.AccountRepository
+	journal  *repository.JournalRepository
+
+	mu         sync.Mutex                            // 需在 JournalRepository 之前获取
+	next       int                                   // 下一条待累计流水的位置
+	generation int                                   // 已累计流水所属的代次
+	buckets    map[string]map[string]*cashflowBucket // 账户ID → 月份 → 累计
+}
+
+func NewCashflowService(accounts *repository.AccountRepository, journal *repository.JournalRepository) *CashflowService {
+	return &CashflowService{accounts: accounts, journal: journal, buckets: make(map[string]map[string]*cashflowBucket)}
+}
+
+// 查询账户现金流：from/to 为 YYYY-MM（含，空表示不限），groupBy 为空或 category/counterparty
+func (s *CashflowService) Report(accountID, from, to, groupBy string) (CashflowReport, error) {
+	account, ok := s.accounts.Find(accountID)
+	if !ok {
+		return CashflowReport{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	for _, month := range []string{from, to} {
+		if _, err := time.Parse("2006-01", month); month != "" && err != nil {
+			return CashflowReport{}, model.NewError(model.CODE_PARAM_ERROR, "月份格式错误，应为 YYYY-MM")
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return CashflowReport{}, model.NewError(model.CODE_PARAM_ERROR, "起始月份不能晚于截止月份")
+	}
+	if groupBy != "" && groupBy != CASHFLOW_GROUP_CATEGORY && groupBy != CASHFLOW_GROUP_COUNTERPARTY {
+		return CashflowReport{}, model.NewError(model.CODE_PARAM_ERROR, "分组维度仅支持 category/counterparty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.catchUp()
+
+	report := CashflowReport{AccountID: accountID, Currency: account.Currency, GroupBy: groupBy, Months: make([]CashflowMonth, 0)}
+	for month, bucket := range s.buckets[accountID] {
+		if (from != "" && month < from) || (to != "" && month > to) {
+			continue
+		}
+		entry := CashflowMonth{Month: month, CashflowTotals: roundTotals(bucket.totals)}
+		groups := bucket.byCategory
+		if groupBy == CASHFLOW_GROUP_COUNTERPARTY {
+			groups = bucket.byCounterparty
+		}
+		if groupBy != "" {
+			entry.Groups = make([]CashflowGroup, 0, len(groups))
+			for key, totals := range groups {
+				entry.Groups = append(entry.Groups, CashflowGroup{Key: key, CashflowTotals: roundTotals(*totals)})
+			}
+			sort.Slice(entry.Groups, func(i, j int) bool {
+				a, b := entry.Groups[i], entry.Groups[j]
+				if a.Inflow+a.Outflow != b.Inflow+b.Outflow {
+					return a.Inflow+a.Outflow > b.Inflow+b.Outflow
+				}
+				return a.Key < b.Key
+			})
+		}
+		report.Months = append(report.Months, entry)
+
+		report.Total.Inflow += bucket.totals.Inflow
+		report.Total.Outflow += bucket.totals.Outflow
+		report.Total.Count += bucket.totals.Count
+	}
+	sort.Slice(report.Months, func(i, j int) bool { return report.Months[i].Month < report.Months[j].Month })
+	report.Total.Net = report.Total.Inflow - report.Total.Outflow
+	report.Total = roundTotals(report.Total)
+	return report, nil
+}
+
+// 累计上次查询之后新增的流水（调用方需持有 mu）
+func (s *CashflowService) catchUp() {
+	transactions, next, generation := s.journal.Tail(s.next, s.generation)
+	if generation != s.generation {
+		s.buckets = make(map[string]map[string]*cashflowBucket)
+	}
+	s.next, s.generation = next, generation
+
+	for _, tx := range transactions {
+		if tx.Type == "opening" {
+			continue
+		}
+		months := s.buckets[tx.AccountID]
+		if months == nil {
+			months = make(map[string]*cashflowBucket)
+			s.buckets[tx.AccountID] = months
+		}
+		month := tx.Time.Local().Format("2006-01")
+		bucket := months[month]
+		if bucket == nil {
+			bucket = &cashflowBucket{byCategory: make(map[string]*CashflowTotals), byCounterparty: make(map[string]*CashflowTotals)}
+			months[month] = bucket
+		}
+
+		category := cashflowCategory(tx.Type)
+		counterparty := tx.Counterparty
+		if counterparty == "" {
+			counterparty = "unknown"
+		}
+		addCashflow(&bucket.totals, tx)
+		addCashflow(groupTotals(bucket.byCategory, category), tx)
+		addCashflow(groupTotals(bucket.byCounterparty, counterparty), tx)
+	}
+}
+
+// 交易类型对应的现金流类别
+func cashflowCategory(txType string) string {
+	if category, ok := cashflowCategories[txType]; ok {
+		return category
+	}
+	return "other"
+}
+
+// 全部现金流类别（按名称排序）
+func CashflowCategories() []string {
+	seen := map[string]bool{"other": true}
+	categories := []string{"other"}
+	for _, category := range cashflowCategories {
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func groupTotals(groups map[string]*CashflowTotals, key string) *CashflowTotals {
+	totals := groups[key]
+	if totals == nil {
+		totals = &CashflowTotals{}
+		groups[key] = totals
+	}
+	return totals
+}
+
+func addCashflow(totals *CashflowTotals, tx model.Transaction) {
+	if tx.Direction == "credit" {
+		totals.Inflow += tx.Amount
+	} else {
+		totals.Outflow += tx.Amount
+	}
+	totals.Net = totals.Inflow - totals.Outflow
+	totals.Count++
+}
+
+func roundTotals(totals CashflowTotals) CashflowTotals {
+	return CashflowTotals{
+		Inflow:  model.RoundAmount(totals.Inflow),
+		Outflow: model.RoundAmount(totals.Outflow),
+		Net:     model.RoundAmount(totals.Net),
+		Count:   totals.Count,
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 故障注入配置
+type ChaosConfig struct {
+	Enabled          bool                    // 总开关，关闭时以下配置均不生效
+	Latency          ChaosLatency            // 接口附加延迟（全部接口）
+	Endpoints        map[string]ChaosLatency // 按接口路径前缀（如 /api/transfer）覆盖附加延迟，最长前缀优先
+	ErrorRate        float64                 // 接口直接返回 CODE_SERVER_BUSY 的概率
+	WriteFailureRate float64                 // 账户存储写入失败的概率
+	StormInterval    time.Duration           // WebSocket 断线风暴间隔，0 表示不定时触发
+	StormRatio       float64                 // 每次断线风暴断开的连接比例
+}
+
+// 附加延迟范围（在 Min~Max 之间均匀抽取）
+type ChaosLatency struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// 附加延迟范围的请求表示（毫秒）
+type ChaosLatencyRequest struct {
+	MinMs int `json:"minMs" validate:"min=0"`
+	MaxMs int `json:"maxMs" validate:"min=0"`
+}
+
+// 故障注入配置调整请求结构体（字段为空表示不变；endpoints 非空时整体替换）
+type ChaosConfigRequest struct {
+	Enabled          *bool                          `json:"enabled"`
+	Latency          *ChaosLatencyRequest           `json:"latency"`
+	Endpoints        map[string]ChaosLatencyRequest `json:"endpoints"`
+	ErrorRate        *float64                       `json:"errorRate" validate:"omitempty,min=0,max=1"`
+	WriteFailureRate *float64                       `json:"writeFailureRate" validate:"omitempty,min=0,max=1"`
+	StormIntervalSec *int                           `json:"stormIntervalSec" validate:"omitempty,min=0"`
+	StormRatio       *float64                       `json:"stormRatio" validate:"omitempty,min=0,max=1"`
+}
+
+// 故障注入统计（服务启动以来累计）
+type ChaosStats struct {
+	DelayedRequests int64 `json:"delayedRequests"` // 附加延迟的请求数
+	TotalDelayMs    int64 `json:"totalDelayMs"`    // 附加延迟合计（毫秒）
+	BusyErrors      int64 `json:"busyErrors"`      // 注入 CODE_SERVER_BUSY 的请求数
+	WriteFailures   int64 `json:"writeFailures"`   // 注入的存储写入失败次数
+	Storms          int64 `json:"storms"`          // 断线风暴次数
+	Disconnects     int64 `json:"disconnects"`     // 断线风暴断开的连接数
+}
+
+// 连接断开（生产环境为 WebSocket hub）：按比例随机断开连接，返回断开的连接数
+type ConnectionDropper interface {
+	Drop(ratio float64) int
+}
+
+// 故障注入服务：按配置为接口附加随机延迟、返回服务繁忙、使存储写入失败，并定时触发 WebSocket 断线风暴，
+// 用于测试客户端的超时、重试与重连逻辑
+type ChaosService struct {
+	cfg     ChaosConfig
+	dropper ConnectionDropper
+	mu      sync.Mutex
+
+	lastStorm time.Time
+	stats     ChaosStats // 原子读写
+}
+
+func NewChaosService(cfg ChaosConfig, accounts *repository.AccountRepository, dropper ConnectionDropper) *ChaosService {
+	s := &ChaosService{cfg: cfg, dropper: dropper, lastStorm: time.Now()}
+	accounts.SetWriteFault(s.writeFault)
+	if cfg.Enabled {
+		log.Printf("故障注入: 已开启，附加延迟: %s~%s，繁忙概率: %.2f，写入失败概率: %.2f，断线风暴间隔: %s",
+			cfg.Latency.Min, cfg.Latency.Max, cfg.ErrorRate, cfg.WriteFailureRate, cfg.StormInterval)
+	}
+	return s
+}
+
+// 当前配置
+func (s *ChaosService) Config() ChaosConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cfg := s.cfg
+	cfg.Endpoints = make(map[string]ChaosLatency, len(s.cfg.Endpoints))
+	for path, latency := range s.cfg.Endpoints {
+		cfg.Endpoints[path] = latency
+	}
+	return cfg
+}
+
+// 调整故障注入配置（立即生效）
+func (s *ChaosService) SetConfig(req ChaosConfigRequest) (ChaosConfig, error) {
+	if err := model.Validate(req); err != nil {
+		return ChaosConfig{}, err
+	}
+	var latency ChaosLatency
+	if req.Latency != nil {
+		var err error
+		if latency, err = chaosLatency(*req.Latency); err != nil {
+			return ChaosConfig{}, err
+		}
+	}
+	var endpoints map[string]ChaosLatency
+	if req.Endpoints != nil {
+		endpoints = make(map[string]ChaosLatency, len(req.Endpoints))
+		for path, r := range req.Endpoints {
+			if !strings.HasPrefix(path, "/") {
+				return ChaosConfig{}, model.NewError(model.CODE_PARAM_ERROR, "接口路径应以 / 开头: "+path)
+			}
+			l, err := chaosLatency(r)
+			if err != nil {
+				return ChaosConfig{}, err
+			}
+			endpoints[path] = l
+		}
+	}
+
+	s.mu.Lock()
+	if req.Enabled != nil {
+		s.cfg.Enabled = *req.Enabled
+	}
+	if req.Latency != nil {
+		s.cfg.Latency = latency
+	}
+	if endpoints != nil {
+		s.cfg.Endpoints = endpoints
+	}
+	if req.ErrorRate != nil {
+		s.cfg.ErrorRate = *req.ErrorRate
+	}
+	if req.WriteFailureRate != nil {
+		s.cfg.WriteFailureRate = *req.WriteFailureRate
+	}
+	if req.StormIntervalSec != nil {
+		s.cfg.StormInterval = time.Duration(*req.StormIntervalSec) * time.Second
+		s.lastStorm = time.Now()
+	}
+	if req.StormRatio != nil {
+		s.cfg.StormRatio = *req.StormRatio
+	}
+	s.mu.Unlock()
+
+	return s.Config(), nil
+}
+
+// 累计统计
+func (s *ChaosService) Stats() ChaosStats {
+	return ChaosStats{
+		DelayedRequests: atomic.LoadInt64(&s.stats.DelayedRequests),
+		TotalDelayMs:    atomic.LoadInt64(&s.stats.TotalDelayMs),
+		BusyErrors:      atomic.LoadInt64(&s.stats.BusyErrors),
+		WriteFailures:   atomic.LoadInt64(&s.stats.WriteFailures),
+		Storms:          atomic.LoadInt64(&s.stats.Storms),
+		Disconnects:     atomic.LoadInt64(&s.stats.Disconnects),
+	}
+}
+
+// 为接口请求抽取附加延迟（未开启或未配置时为 0）
+func (s *ChaosService) Delay(path string) time.Duration {
+	s.mu.Lock()
+	if !s.cfg.Enabled {
+		s.mu.Unlock()
+		return 0
+	}
+	latency := s.cfg.Latency
+	matched := ""
+	for prefix, l := range s.cfg.Endpoints {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			latency, matched = l, prefix
+		}
+	}
+	s.mu.Unlock()
+
+	if latency.Max <= 0 {
+		return 0
+	}
+	delay := latency.Min
+	if latency.Max > latency.Min {
+		delay += time.Duration(rand.Int63n(int64(latency.Max - latency.Min)))
+	}
+	atomic.AddInt64(&s.stats.DelayedRequests, 1)
+	atomic.AddInt64(&s.stats.TotalDelayMs, delay.Milliseconds())
+	return delay
+}
+
+// 是否对本次请求注入服务繁忙
+func (s *ChaosService) Busy() bool {
+	s.mu.Lock()
+	hit := s.cfg.Enabled && rand.Float64() < s.cfg.ErrorRate
+	s.mu.Unlock()
+	if hit {
+		atomic.AddInt64(&s.stats.BusyErrors, 1)
+	}
+	return hit
+}
+
+// 存储写入故障注入（由账户存储在写入前调用）
+func (s *ChaosService) writeFault() error {
+	s.mu.Lock()
+	hit := s.cfg.Enabled && rand.Float64() < s.cfg.WriteFailureRate
+	s.mu.Unlock()
+	if !hit {
+		return nil
+	}
+	atomic.AddInt64(&s.stats.WriteFailures, 1)
+	return model.NewError(model.CODE_SERVER_BUSY, "存储写入失败，请稍后重试")
+}
+
+// 立即触发一次断线风暴：按比例随机断开 WebSocket 连接，返回断开的连接数
+func (s *ChaosService) Storm(ratio float64) (int, error) {
+	if ratio <= 0 || ratio > 1 {
+		return 0, model.NewError(model.CODE_PARAM_ERROR, "断开比例应在 0 到 1 之间（不含 0）")
+	}
+	dropped := s.dropper.Drop(ratio)
+	atomic.AddInt64(&s.stats.Storms, 1)
+	atomic.AddInt64(&s.stats.Disconnects, int64(dropped))
+
+	// 终端提示：断线风暴
+	log.Println("\n[🌪️ 故障注入 - 断线风暴]")
+	log.Printf("触发时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("断开比例: %.0f%%，断开连接: %d 个", ratio*100, dropped)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return dropped, nil
+}
+
+// 定时断线风暴（阻塞运行，按配置的间隔触发）
+func (s *ChaosService) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.mu.Lock()
+		due := s.cfg.Enabled && s.cfg.StormInterval > 0 && s.cfg.StormRatio > 0 && now.Sub(s.lastStorm) >= s.cfg.StormInterval
+		ratio := s.cfg.StormRatio
+		if due {
+			s.lastStorm = now
+		}
+		s.mu.Unlock()
+		if due {
+			s.Storm(ratio)
+		}
+	}
+}
+
+// 校验并转换附加延迟范围
+func chaosLatency(req ChaosLatencyRequest) (ChaosLatency, error) {
+	if err := model.Validate(req); err != nil {
+		return ChaosLatency{}, err
+	}
+	if req.MaxMs < req.MinMs {
+		return ChaosLatency{}, model.NewError(model.CODE_PARAM_ERROR, "附加延迟上限不能小于下限")
+	}
+	if req.MaxMs > 60000 {
+		return ChaosLatency{}, model.NewError(model.CODE_PARAM_ERROR, "附加延迟不能超过 60000 毫秒")
+	}
+	return ChaosLatency{Min: time.Duration(req.MinMs) * time.Millisecond, Max: time.Duration(req.MaxMs) * time.Millisecond}, nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 模拟清算所在央行 RTGS 的结算账户
+const CLEARING_HOUSE_BIC = "CLHSCNBJ"
+
+// 轧差场次状态
+const (
+	CYCLE_OPEN    = "open"    // 收集支付中
+	CYCLE_CLOSED  = "closed"  // 已截止轧差，待 RTGS 结算（净应付行头寸不足时顺延重试）
+	CYCLE_SETTLED = "settled" // 净额已结算
+)
+
+// 清算所配置
+type ClearingHouseConfig struct {
+	LowValueLimit float64       // 低于该金额的跨行支付经清算所轧差结算，0 表示不启用
+	CycleInterval time.Duration // 轧差场次间隔（模拟时钟，自当日零点起对齐）
+}
+
+// 清算所收到的一笔支付
+type ClearingItem struct {
+	Reference   string  `json:"reference"`
+	FromBank    string  `json:"fromBank"`
+	ToBank      string  `json:"toBank"`
+	Amount      float64 `json:"amount"`
+	SubmittedAt string  `json:"submittedAt"`
+}
+
+// 参与行在一个场次的多边净额
+type MultilateralPosition struct {
+	BIC           string  `json:"bic"`
+	Name          string  `json:"name"`
+	Sent          float64 `json:"sent"` // 付出总额
+	SentCount     int     `json:"sentCount"`
+	Received      float64 `json:"received"` // 收入总额
+	ReceivedCount int     `json:"receivedCount"`
+	Net           float64 `json:"net"` // 净额 = 收入 - 付出（正数为净应收，负数为净应付）
+}
+
+// 轧差场次报告
+type ClearingCycle struct {
+	CycleID      string                 `json:"cycleId"`
+	Status       string                 `json:"status"` // open/closed/settled
+	OpenedAt     string                 `json:"openedAt"`
+	CutoffAt     string                 `json:"cutoffAt"` // 计划截止时间
+	ClosedAt     string                 `json:"closedAt,omitempty"`
+	SettledAt    string                 `json:"settledAt,omitempty"`
+	ItemCount    int                    `json:"itemCount"`
+	GrossAmount  float64                `json:"grossAmount"`  // 逐笔结算所需的支付总额
+	NetAmount    float64                `json:"netAmount"`    // 轧差后实际划转的净应付总额
+	NettingRatio float64                `json:"nettingRatio"` // 轧差节约比例 = 1 - 净额 / 总额
+	Positions    []MultilateralPosition `json:"positions"`
+	Items        []ClearingItem         `json:"items"`
+	Entries      []RTGSEntry            `json:"entries"`             // 净额结算的 RTGS 分录
+	LastError    string                 `json:"lastError,omitempty"` // 最近一次结算失败原因
+
+	cutoff time.Time
+}
+
+// 模拟他行之间经清算所的低额支付请求
+type ClearingHouseTransferRequest struct {
+	FromBank string  `json:"fromBank"`
+	ToBank   string  `json:"toBank"`
+	Amount   float64 `json:"amount"`
+}
+
+// 模拟他行低额支付受理结果
+type ClearingHouseReceipt struct {
+	ClearingItem
+	CycleID string `json:"cycleId"` // 所在场次
+}
+
+// 清算所服务：低额跨行支付按场次收集，场次截止时计算各参与行多边净额，
+// 每行只以一笔净额经央行 RTGS 结算；净应付行头寸不足时整场顺延，后续场次依次等待
+type ClearingHouseService struct {
+	cfg   ClearingHouseConfig
+	rtgs  *RTGSService
+	clock Clock
+
+	mu      sync.Mutex // 需先于 RTGS 锁获取
+	current *ClearingCycle
+	cycles  []*ClearingCycle // 已截止的场次（按截止顺序）
+	seq     int
+	itemSeq int // 模拟他行支付的流水号序号
+}
+
+func NewClearingHouseService(cfg ClearingHouseConfig, rtgs *RTGSService, clock Clock) *ClearingHouseService {
+	if cfg.CycleInterval <= 0 {
+		cfg.CycleInterval = 2 * time.Hour
+	}
+	return &ClearingHouseService{cfg: cfg, rtgs: rtgs, clock: clock}
+}
+
+// 该金额是否经清算所轧差结算
+func (s *ClearingHouseService) Eligible(amount float64) bool {
+	return amount > 0 && amount < s.cfg.LowValueLimit
+}
+
+// 提交一笔支付至当前场次，返回场次编号
+func (s *ClearingHouseService) Submit(item ClearingItem) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.submit(item)
+}
+
+// 加入当前场次，尚无收集中的场次时开启新场次（调用方需持有 mu）
+func (s *ClearingHouseService) submit(item ClearingItem) string {
+	now := s.clock.Now()
+	if s.current == nil {
+		s.seq++
+		s.current = &ClearingCycle{
+			CycleID:  fmt.Sprintf("CH%s%04d", now.Format("20060102"), s.seq),
+			Status:   CYCLE_OPEN,
+			OpenedAt: now.Format("2006-01-02 15:04:05"),
+			Items:    []ClearingItem{},
+			cutoff:   s.nextCutoff(now),
+		}
+		s.current.CutoffAt = s.current.cutoff.Format("2006-01-02 15:04:05")
+	}
+	item.Amount = model.RoundAmount(item.Amount)
+	item.SubmittedAt = now.Format("2006-01-02 15:04:05")
+	s.current.Items = append(s.current.Items, item)
+	s.current.ItemCount++
+	s.current.GrossAmount = model.RoundAmount(s.current.GrossAmount + item.Amount)
+	return s.current.CycleID
+}
+
+// 模拟他行之间的低额支付（进入当前场次）
+func (s *ClearingHouseService) Transfer(req ClearingHouseTransferRequest) (ClearingHouseReceipt, error) {
+	req.Amount = model.RoundAmount(req.Amount)
+	if !s.Eligible(req.Amount) {
+		return ClearingHouseReceipt{}, model.NewError(model.CODE_PARAM_ERROR,
+			fmt.Sprintf("金额须大于0且低于 %.2f 元（大额支付请经 RTGS 结算）", s.cfg.LowValueLimit))
+	}
+	_, fromOK := externalBanks[req.FromBank]
+	_, toOK := externalBanks[req.ToBank]
+	if !fromOK || !toOK {
+		return ClearingHouseReceipt{}, model.NewError(model.CODE_PARAM_ERROR, "付款行、收款行须为模拟他行")
+	}
+	if req.FromBank == req.ToBank {
+		return ClearingHouseReceipt{}, model.NewError(model.CODE_PARAM_ERROR, "付款行与收款行不能相同")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.itemSeq++
+	item := ClearingItem{
+		Reference: fmt.Sprintf("CX%s%06d", s.clock.Now().Format("20060102"), s.itemSeq),
+		FromBank:  req.FromBank,
+		ToBank:    req.ToBank,
+		Amount:    req.Amount,
+	}
+	cycleID := s.submit(item)
+	return ClearingHouseReceipt{ClearingItem: s.current.Items[len(s.current.Items)-1], CycleID: cycleID}, nil
+}
+
+// 截止到期场次并按顺序结算待结算场次，返回本次完成结算的支付
+func (s *ClearingHouseService) ProcessCycles(now time.Time) []ClearingItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil && !now.Before(s.current.cutoff) {
+		s.close(now)
+	}
+
+	settled := make([]ClearingItem, 0)
+	for _, cycle := range s.cycles {
+		if cycle.Status != CYCLE_CLOSED {
+			continue
+		}
+		positions := make(map[string]float64, len(cycle.Positions))
+		for _, p := range cycle.Positions {
+			positions[p.BIC] = p.Net
+		}
+		entries, err := s.rtgs.SettleNet(cycle.CycleID, positions, GL_CLEARING)
+		if err != nil {
+			if cycle.LastError != err.Error() {
+				cycle.LastError = err.Error()
+				log.Printf("[🧮 清算所] 场次 %s 结算顺延: %s", cycle.CycleID, cycle.LastError)
+			}
+			break // 后续场次须等待本场次结算
+		}
+		cycle.Status = CYCLE_SETTLED
+		cycle.SettledAt = now.Format("2006-01-02 15:04:05")
+		cycle.Entries = entries
+		cycle.LastError = ""
+		settled = append(settled, cycle.Items...)
+		logCycle("✅ 清算所净额结算", *cycle)
+	}
+	return settled
+}
+
+// 立即截止当前场次（不等待计划截止时间），净额在下一次清算处理时结算
+func (s *ClearingHouseService) Close() (ClearingCycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		return ClearingCycle{}, model.NewError(model.CODE_PARAM_ERROR, "当前没有收集中的场次")
+	}
+	return s.close(s.clock.Now()), nil
+}
+
+// 场次列表（最近的在前，含收集中的场次）
+func (s *ClearingHouseService) Cycles() []ClearingCycle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]ClearingCycle, 0, len(s.cycles)+1)
+	if s.current != nil {
+		result = append(result, *s.current)
+	}
+	for i := len(s.cycles) - 1; i >= 0; i-- {
+		result = append(result, *s.cycles[i])
+	}
+	return result
+}
+
+// 查询场次报告
+func (s *ClearingHouseService) Cycle(cycleID string) (ClearingCycle, error) {
+	for _, cycle := range s.Cycles() {
+		if cycle.CycleID == cycleID {
+			return cycle, nil
+		}
+	}
+	return ClearingCycle{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "场次不存在")
+}
+
+// 截止当前场次并计算多边净额（调用方需持有 mu）
+func (s *ClearingHouseService) close(now time.Time) ClearingCycle {
+	cycle := s.current
+	s.current = nil
+
+	positions := make(map[string]*MultilateralPosition)
+	get := func(bic string) *MultilateralPosition {
+		p, ok := positions[bic]
+		if !ok {
+			p = &MultilateralPosition{BIC: bic, Name: participantName(bic)}
+			positions[bic] = p
+		}
+		return p
+	}
+	for _, item := range cycle.Items {
+		from, to := get(item.FromBank), get(item.ToBank)
+		from.Sent += item.Amount
+		from.SentCount++
+		to.Received += item.Amount
+		to.ReceivedCount++
+	}
+	cycle.Positions = make([]MultilateralPosition, 0, len(positions))
+	for _, p := range positions {
+		p.Sent = model.RoundAmount(p.Sent)
+		p.Received = model.RoundAmount(p.Received)
+		p.Net = model.RoundAmount(p.Received - p.Sent)
+		if p.Net < 0 {
+			cycle.NetAmount += -p.Net
+		}
+		cycle.Positions = append(cycle.Positions, *p)
+	}
+	sort.Slice(cycle.Positions, func(i, j int) bool { return cycle.Positions[i].BIC < cycle.Positions[j].BIC })
+	cycle.NetAmount = model.RoundAmount(cycle.NetAmount)
+	if cycle.GrossAmount > 0 {
+		cycle.NettingRatio = model.RoundAmount(1 - cycle.NetAmount/cycle.GrossAmount)
+	}
+	cycle.Status = CYCLE_CLOSED
+	cycle.ClosedAt = now.Format("2006-01-02 15:04:05")
+	cycle.Entries = []RTGSEntry{}
+	s.cycles = append(s.cycles, cycle)
+
+	logCycle("🧮 清算所场次截止", *cycle)
+	return *cycle
+}
+
+// 下一个场次截止时间：自当日零点起按场次间隔对齐
+func (s *ClearingHouseService) nextCutoff(now time.Time) time.Time {
+	start := sim.StartOfDay(now)
+	return start.Add((now.Sub(start)/s.cfg.CycleInterval + 1) * s.cfg.CycleInterval)
+}
+
+// 终端提示：轧差场次
+func logCycle(title string, cycle ClearingCycle) {
+	log.Println("\n[" + title + "]")
+	log.Printf("场次: %s（%s 开始，%s 截止）", cycle.CycleID, cycle.OpenedAt, cycle.ClosedAt)
+	log.Printf("支付 %d 笔，总额 %.2f 元，净额 %.2f 元，轧差节约 %.0f%%", cycle.ItemCount, cycle.GrossAmount, cycle.NetAmount, cycle.NettingRatio*100)
+	for _, p := range cycle.Positions {
+		log.Printf("%s: 付出 %.2f，收入 %.2f，净额 %+.2f", p.Name, p.Sent, p.Received, p.Net)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 清算所快照（随状态快照导出）
+type ClearingHouseSnapshot struct {
+	Current *ClearingCycle  `json:"current,omitempty"` // 收集中的场次
+	Cycles  []ClearingCycle `json:"cycles"`
+	Seq     int             `json:"seq"`
+	ItemSeq int             `json:"itemSeq"`
+}
+
+// 导出场次（调用方需持有 s.mu，并在其后导出 RTGS，使待结算场次与净额结算分录取自同一时刻）
+func (s *ClearingHouseService) snapshot() ClearingHouseSnapshot {
+	snapshot := ClearingHouseSnapshot{Cycles: make([]ClearingCycle, 0, len(s.cycles)), Seq: s.seq, ItemSeq: s.itemSeq}
+	if s.current != nil {
+		current := s.current.clone()
+		snapshot.Current = &current
+	}
+	for _, cycle := range s.cycles {
+		snapshot.Cycles = append(snapshot.Cycles, cycle.clone())
+	}
+	return snapshot
+}
+
+// 以快照替换场次（调用方需持有 s.mu）
+func (s *ClearingHouseService) restore(snapshot ClearingHouseSnapshot) {
+	s.current, s.cycles = nil, make([]*ClearingCycle, 0, len(snapshot.Cycles))
+	if snapshot.Current != nil {
+		current := snapshot.Current.clone()
+		s.current = &current
+	}
+	for _, cycle := range snapshot.Cycles {
+		cycle := cycle.clone()
+		s.cycles = append(s.cycles, &cycle)
+	}
+	s.seq, s.itemSeq = snapshot.Seq, snapshot.ItemSeq
+}
+
+// 场次副本（截止时间由 CutoffAt 还原）
+func (c ClearingCycle) clone() ClearingCycle {
+	c.Positions = append([]MultilateralPosition{}, c.Positions...)
+	c.Items = append([]ClearingItem{}, c.Items...)
+	c.Entries = append([]RTGSEntry{}, c.Entries...)
+	c.cutoff, _ = time.ParseInLocation("2006-01-02 15:04:05", c.CutoffAt, time.Local)
+	return c
+}
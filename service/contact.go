@@ -0,0 +1,278 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 联系方式字段
+const (
+	CONTACT_PHONE = "phone"
+	CONTACT_EMAIL = "email"
+)
+
+// 联系方式变更状态
+const (
+	CONTACT_CHANGE_PENDING   = "pending"   // 待验证
+	CONTACT_CHANGE_CONFIRMED = "confirmed" // 已生效
+	CONTACT_CHANGE_EXPIRED   = "expired"   // 验证码过期
+	CONTACT_CHANGE_CANCELLED = "cancelled" // 被新的变更申请取代或验证码错误次数过多
+)
+
+const (
+	contactChangeTTL         = 10 * time.Minute // 变更验证码有效期（模拟时钟）
+	contactChangeMaxFailures = 5                // 验证码最多允许输错次数
+)
+
+// 联系方式字段名称（提醒文案使用）
+var contactFieldNames = map[string]string{
+	CONTACT_PHONE: "联系手机",
+	CONTACT_EMAIL: "联系邮箱",
+}
+
+// 联系方式变更（新旧值脱敏展示）
+type ContactChange struct {
+	ChangeID    string `json:"changeId"`
+	CustomerID  string `json:"customerId"`
+	Field       string `json:"field"` // phone/email
+	OldValue    string `json:"oldValue"`
+	NewValue    string `json:"newValue"`
+	Status      string `json:"status"`
+	RequestedAt string `json:"requestedAt"`
+	ExpiresAt   string `json:"expiresAt"`
+	ConfirmedAt string `json:"confirmedAt,omitempty"`
+
+	oldValue  string
+	newValue  string
+	code      string
+	expiresAt time.Time
+	failures  int
+}
+
+// 确认联系方式变更请求
+type ContactConfirmRequest struct {
+	ChangeID string `json:"changeId" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// 申请变更手机号或邮箱：变更暂不生效，验证码发送到新的联系方式，同一字段的未完成申请作废（调用方需持有 s.mu）
+func (s *CustomerService) requestChange(customer model.Customer, field, value string) (ContactChange, error) {
+	if value == "" {
+		return ContactChange{}, model.NewError(model.CODE_PARAM_ERROR, contactFieldNames[field]+"不能为空")
+	}
+	account, err := s.primaryAccount(customer.CustomerID)
+	if err != nil {
+		return ContactChange{}, err
+	}
+	now := s.clock.Now()
+	for _, change := range s.changes {
+		if change.CustomerID == customer.CustomerID && change.Field == field && change.Status == CONTACT_CHANGE_PENDING {
+			change.Status = CONTACT_CHANGE_CANCELLED
+		}
+	}
+
+	s.changeSeq++
+	change := &ContactChange{
+		ChangeID:    fmt.Sprintf("CC%s%06d", now.Format("20060102"), s.changeSeq),
+		CustomerID:  customer.CustomerID,
+		Field:       field,
+		Status:      CONTACT_CHANGE_PENDING,
+		RequestedAt: now.Format("2006-01-02 15:04:05"),
+		ExpiresAt:   now.Add(contactChangeTTL).Format("2006-01-02 15:04:05"),
+		oldValue:    customer.Phone,
+		newValue:    value,
+		code:        fmt.Sprintf("%06d", rand.Intn(1000000)),
+		expiresAt:   now.Add(contactChangeTTL),
+	}
+	if field == CONTACT_EMAIL {
+		change.oldValue = customer.Email
+	}
+	change.OldValue, change.NewValue = maskContact(field, change.oldValue), maskContact(field, change.newValue)
+
+	// 验证码发往新的联系方式
+	vars := map[string]interface{}{
+		"Code":      change.code,
+		"Minutes":   int(contactChangeTTL.Minutes()),
+		"Field":     field,
+		"FieldName": contactFieldNames[field],
+	}
+	if field == CONTACT_PHONE {
+		account.Phone = value
+		if _, err := s.sms.Notify(account, SMS_OTP, EVENT_CONTACT_VERIFY, vars); err != nil {
+			return ContactChange{}, err
+		}
+	} else {
+		account.Email = value
+		s.emails.Notify(account, EVENT_CONTACT_VERIFY, vars)
+	}
+	s.changes[change.ChangeID] = change
+
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, "contact.change.requested", customer.CustomerID, map[string]string{
+		"changeId": change.ChangeID,
+		"field":    field,
+		"oldValue": change.OldValue,
+		"newValue": change.NewValue,
+	})
+
+	// 终端提示：联系方式变更申请
+	log.Println("\n[📇 联系方式变更申请]")
+	log.Printf("变更编号: %s", change.ChangeID)
+	log.Printf("客户号: %s", customer.CustomerID)
+	log.Printf("变更内容: %s %s → %s", field, change.OldValue, change.NewValue)
+	log.Printf("验证码有效期至: %s", change.ExpiresAt)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *change, nil
+}
+
+// 确认联系方式变更：验证码正确后生效并同步到名下账户，记录审计日志并向原联系方式发送安全提醒
+func (s *CustomerService) ConfirmChange(req ContactConfirmRequest) (CustomerProfile, error) {
+	if err := model.Validate(req); err != nil {
+		return CustomerProfile{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change, ok := s.changes[req.ChangeID]
+	if !ok {
+		return CustomerProfile{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "变更申请不存在")
+	}
+	now := s.clock.Now()
+	if change.Status == CONTACT_CHANGE_PENDING && !now.Before(change.expiresAt) {
+		change.Status = CONTACT_CHANGE_EXPIRED
+	}
+	if change.Status != CONTACT_CHANGE_PENDING {
+		return CustomerProfile{}, model.NewError(model.CODE_PARAM_ERROR, "变更申请已失效（"+change.Status+"），请重新提交")
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Code), []byte(change.code)) != 1 {
+		change.failures++
+		if change.failures >= contactChangeMaxFailures {
+			change.Status = CONTACT_CHANGE_CANCELLED
+			return CustomerProfile{}, model.NewError(model.CODE_RISK_CONTROL_REJECT, "验证码错误次数过多，变更申请已作废")
+		}
+		return CustomerProfile{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("验证码错误，还可尝试 %d 次", contactChangeMaxFailures-change.failures))
+	}
+
+	customer, exists := s.customers.Get(change.CustomerID)
+	if !exists {
+		return CustomerProfile{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "客户不存在")
+	}
+	if change.Field == CONTACT_PHONE {
+		customer.Phone = change.newValue
+	} else {
+		customer.Email = change.newValue
+	}
+	customer.UpdatedAt = now.Format("2006-01-02 15:04:05")
+	customer = s.customers.Save(customer)
+	accountIDs := s.syncAccounts(customer)
+
+	change.Status = CONTACT_CHANGE_CONFIRMED
+	change.ConfirmedAt = now.Format("2006-01-02 15:04:05")
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, "contact.change.confirmed", customer.CustomerID, map[string]string{
+		"changeId": change.ChangeID,
+		"field":    change.Field,
+		"oldValue": change.OldValue,
+		"newValue": change.NewValue,
+	})
+
+	// 终端提示：联系方式变更生效
+	log.Println("\n[📇 联系方式变更生效]")
+	log.Printf("变更编号: %s", change.ChangeID)
+	log.Printf("客户号: %s", customer.CustomerID)
+	log.Printf("变更内容: %s %s → %s", change.Field, change.OldValue, change.NewValue)
+	log.Printf("同步账户: %s", strings.Join(accountIDs, ", "))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.alertOldContact(change)
+	return CustomerProfile{Customer: customer, Accounts: accountIDs, PendingChanges: s.pendingChanges(customer.CustomerID)}, nil
+}
+
+// 变更生效后的安全提醒：推送到客户端并存入通知中心，同时发往原手机号或原邮箱（原联系方式不再接收后续通知）
+func (s *CustomerService) alertOldContact(change *ContactChange) {
+	account, err := s.primaryAccount(change.CustomerID)
+	if err != nil {
+		return
+	}
+	vars := map[string]interface{}{
+		"Field":     change.Field,
+		"FieldName": contactFieldNames[change.Field],
+		"OldValue":  change.OldValue,
+		"NewValue":  change.NewValue,
+		"Time":      change.ConfirmedAt,
+	}
+	s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_CONTACT_CHANGED, vars))
+	if change.oldValue == "" {
+		return
+	}
+	if change.Field == CONTACT_PHONE {
+		account.Phone = change.oldValue
+		if _, err := s.sms.Notify(account, SMS_SECURITY_ALERT, EVENT_CONTACT_CHANGED, vars); err != nil {
+			log.Printf("原手机号安全提醒发送失败: %v", err)
+		}
+	} else {
+		account.Email = change.oldValue
+		s.emails.Notify(account, EVENT_CONTACT_CHANGED, vars)
+	}
+}
+
+// 客户的联系方式变更记录（按编号倒序）
+func (s *CustomerService) Changes(customerID string) []ContactChange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	result := []ContactChange{}
+	for _, change := range s.changes {
+		if change.CustomerID != customerID {
+			continue
+		}
+		if change.Status == CONTACT_CHANGE_PENDING && !now.Before(change.expiresAt) {
+			change.Status = CONTACT_CHANGE_EXPIRED
+		}
+		result = append(result, *change)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ChangeID > result[j].ChangeID })
+	return result
+}
+
+// 客户待验证的变更（调用方需持有 s.mu）
+func (s *CustomerService) pendingChanges(customerID string) []ContactChange {
+	now := s.clock.Now()
+	var result []ContactChange
+	for _, change := range s.changes {
+		if change.CustomerID == customerID && change.Status == CONTACT_CHANGE_PENDING && now.Before(change.expiresAt) {
+			result = append(result, *change)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ChangeID < result[j].ChangeID })
+	return result
+}
+
+// 客户的首个账户（渲染通知模板使用）
+func (s *CustomerService) primaryAccount(customerID string) (model.Account, error) {
+	ids := s.accountIDs(customerID)
+	if len(ids) == 0 {
+		return model.Account{}, model.NewError(model.CODE_ACCOUNT_ERROR, "客户名下没有账户，无法发送通知")
+	}
+	account, _ := s.accounts.Get(ids[0])
+	return account, nil
+}
+
+// 联系方式脱敏
+func maskContact(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	if field == CONTACT_PHONE {
+		return maskPhone(value)
+	}
+	return maskEmail(value)
+}
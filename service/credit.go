@@ -0,0 +1,287 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 信用评分参数
+const (
+	CREDIT_SCORE_MIN     = 300
+	CREDIT_SCORE_MAX     = 850
+	CREDIT_SCORE_BASE    = 600
+	CREDIT_WINDOW_DAYS   = 90  // 余额与透支统计窗口（天）
+	CREDIT_LOW_BALANCE   = 100 // 日终余额低于该值（人民币）计为低余额日
+	CREDIT_HISTORY_LIMIT = 90  // 保留的历史评分条数
+)
+
+// 贷款自动审批规则：评分不低于 MinScore 时可获批的最高金额（人民币）与年利率
+type LoanRule struct {
+	MinScore   int     `json:"minScore"`
+	Grade      string  `json:"grade"`
+	MaxAmount  float64 `json:"maxAmount"`
+	AnnualRate float64 `json:"annualRate"`
+}
+
+// 内置审批规则（按评分从高到低匹配）
+var loanRules = []LoanRule{
+	{MinScore: 750, Grade: "A", MaxAmount: 200000, AnnualRate: 0.0435},
+	{MinScore: 680, Grade: "B", MaxAmount: 50000, AnnualRate: 0.0595},
+	{MinScore: 600, Grade: "C", MaxAmount: 10000, AnnualRate: 0.0850},
+}
+
+// 评分因子
+type CreditFactors struct {
+	AvgBalance        float64 `json:"avgBalance"`        // 近 90 天日均余额（折合人民币）
+	LowBalanceDays    int     `json:"lowBalanceDays"`    // 近 90 天日终余额偏低的天数
+	OnTimePayments    int     `json:"onTimePayments"`    // 按期还款期数
+	MissedPayments    int     `json:"missedPayments"`    // 逾期期数
+	OverdraftAttempts int     `json:"overdraftAttempts"` // 近 90 天因余额不足被拒的交易笔数
+}
+
+// 历史评分
+type ScorePoint struct {
+	Date  string `json:"date"`
+	Score int    `json:"score"`
+}
+
+// 账户信用评分
+type CreditScore struct {
+	AccountID string        `json:"accountId"`
+	Score     int           `json:"score"`
+	Grade     string        `json:"grade"` // A/B/C/D
+	Factors   CreditFactors `json:"factors"`
+	UpdatedAt string        `json:"updatedAt"`
+	History   []ScorePoint  `json:"history"`
+}
+
+// 贷款审批结果
+type LoanDecision struct {
+	Approved   bool    `json:"approved"`
+	Score      int     `json:"score"`
+	Grade      string  `json:"grade"`
+	MaxAmount  float64 `json:"maxAmount"`
+	AnnualRate float64 `json:"annualRate"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// 信用评分服务：按余额历史、贷款还款表现与透支（余额不足）情况评分，日终更新
+type CreditService struct {
+	accounts *repository.AccountRepository
+	ledger   *LedgerService
+	fx       *FXService
+	clock    Clock
+
+	mu     sync.Mutex // 叶子锁：余额不足事件在账户锁内上报，持有期间不得获取账户锁
+	scores map[string]*CreditScore
+	nsf    map[string][]time.Time // 账户ID -> 余额不足被拒时间
+	onTime map[string]int
+	missed map[string]int
+}
+
+func NewCreditService(accounts *repository.AccountRepository, ledger *LedgerService, fx *FXService, clock Clock) *CreditService {
+	return &CreditService{
+		accounts: accounts,
+		ledger:   ledger,
+		fx:       fx,
+		clock:    clock,
+		scores:   make(map[string]*CreditScore),
+		nsf:      make(map[string][]time.Time),
+		onTime:   make(map[string]int),
+		missed:   make(map[string]int),
+	}
+}
+
+// 记录一次余额不足被拒的交易（视为透支尝试，可在账户锁内调用）
+func (s *CreditService) RecordNSF(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nsf[accountID] = append(s.nsf[accountID], s.clock.Now())
+}
+
+// 记录一期贷款还款表现（按期/逾期）
+func (s *CreditService) RecordRepayment(accountID string, onTime bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if onTime {
+		s.onTime[accountID]++
+	} else {
+		s.missed[accountID]++
+	}
+}
+
+// 日终任务：重新计算全部账户的信用评分
+func (s *CreditService) Update(day time.Time) {
+	asOf := sim.StartOfDay(day).AddDate(0, 0, 1)
+	date := day.Format("2006-01-02")
+
+	var changed []string
+	for _, account := range s.accounts.List() {
+		factors := s.factors(account, asOf)
+		score := computeScore(factors)
+
+		s.mu.Lock()
+		current, ok := s.scores[account.AccountID]
+		if !ok {
+			current = &CreditScore{AccountID: account.AccountID}
+			s.scores[account.AccountID] = current
+		}
+		if ok && current.Score != score {
+			changed = append(changed, fmt.Sprintf("账户ID: %s | 用户名: %s | 评分: %d → %d", account.AccountID, account.UserName, current.Score, score))
+		}
+		current.Score = score
+		current.Grade = creditGrade(score)
+		current.Factors = factors
+		current.UpdatedAt = date
+		current.History = append(current.History, ScorePoint{Date: date, Score: score})
+		if len(current.History) > CREDIT_HISTORY_LIMIT {
+			current.History = current.History[len(current.History)-CREDIT_HISTORY_LIMIT:]
+		}
+		s.mu.Unlock()
+	}
+
+	// 终端提示：信用评分更新
+	log.Println("\n[📈 信用评分更新]")
+	log.Printf("评分日期: %s", date)
+	for _, line := range changed {
+		log.Println(line)
+	}
+	log.Printf("评分变动账户数: %d", len(changed))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 查询账户信用评分（尚未经过日终评分的账户按当前数据即时计算）
+func (s *CreditService) Score(accountID string) (CreditScore, error) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return CreditScore{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	if current, ok := s.scores[accountID]; ok {
+		result := *current
+		result.History = append([]ScorePoint{}, current.History...)
+		s.mu.Unlock()
+		return result, nil
+	}
+	s.mu.Unlock()
+
+	now := s.clock.Now()
+	factors := s.factors(account, now)
+	score := computeScore(factors)
+	return CreditScore{
+		AccountID: accountID,
+		Score:     score,
+		Grade:     creditGrade(score),
+		Factors:   factors,
+		UpdatedAt: now.Format("2006-01-02"),
+		History:   []ScorePoint{},
+	}, nil
+}
+
+// 贷款自动审批：按评分匹配审批规则，申请金额（人民币）不得超过规则上限
+func (s *CreditService) Evaluate(accountID string, amount float64) (LoanDecision, error) {
+	score, err := s.Score(accountID)
+	if err != nil {
+		return LoanDecision{}, err
+	}
+	decision := LoanDecision{Score: score.Score, Grade: score.Grade}
+	for _, rule := range loanRules {
+		if score.Score < rule.MinScore {
+			continue
+		}
+		decision.MaxAmount = rule.MaxAmount
+		decision.AnnualRate = rule.AnnualRate
+		if amount > rule.MaxAmount {
+			decision.Reason = fmt.Sprintf("信用评分 %d 可申请额度上限为 %.2f 元", score.Score, rule.MaxAmount)
+			return decision, nil
+		}
+		decision.Approved = true
+		return decision, nil
+	}
+	decision.Reason = fmt.Sprintf("信用评分 %d 低于最低准入分 %d", score.Score, loanRules[len(loanRules)-1].MinScore)
+	return decision, nil
+}
+
+// 贷款审批规则
+func (s *CreditService) Rules() []LoanRule {
+	return append([]LoanRule{}, loanRules...)
+}
+
+// 统计评分因子：asOf 之前 90 天的日终余额、透支尝试与还款表现
+func (s *CreditService) factors(account model.Account, asOf time.Time) CreditFactors {
+	var factors CreditFactors
+
+	// 余额历史自账户首笔流水起算（模拟系统启动前的余额无从追溯）
+	var since time.Time
+	if history := s.ledger.Transactions(account.AccountID, time.Time{}, time.Time{}); len(history) > 0 {
+		since = history[0].Time
+	}
+
+	end := sim.StartOfDay(asOf)
+	days, total := 0, 0.0
+	for i := 0; i < CREDIT_WINDOW_DAYS; i++ {
+		at := end.AddDate(0, 0, -i)
+		if since.IsZero() || at.Before(since) {
+			break
+		}
+		balance, ok := s.ledger.BalanceAt(account.AccountID, at)
+		if !ok {
+			break
+		}
+		balance = s.fx.ToBase(balance, account.Currency)
+		total += balance
+		days++
+		if balance < CREDIT_LOW_BALANCE {
+			factors.LowBalanceDays++
+		}
+	}
+	if days > 0 {
+		factors.AvgBalance = model.RoundAmount(total / float64(days))
+	} else {
+		factors.AvgBalance = model.RoundAmount(s.fx.ToBase(account.Balance, account.Currency))
+	}
+
+	windowStart := end.AddDate(0, 0, -CREDIT_WINDOW_DAYS)
+	s.mu.Lock()
+	for _, t := range s.nsf[account.AccountID] {
+		if !t.Before(windowStart) && t.Before(asOf) {
+			factors.OverdraftAttempts++
+		}
+	}
+	factors.OnTimePayments = s.onTime[account.AccountID]
+	factors.MissedPayments = s.missed[account.AccountID]
+	s.mu.Unlock()
+	return factors
+}
+
+// 按评分因子计算信用评分（300～850）
+func computeScore(f CreditFactors) int {
+	score := float64(CREDIT_SCORE_BASE)
+	if f.AvgBalance > 0 {
+		score += math.Min(150, 50*math.Log10(1+f.AvgBalance/1000)) // 日均余额加分
+	}
+	score -= math.Min(50, float64(f.LowBalanceDays))        // 低余额日扣分
+	score += math.Min(100, 10*float64(f.OnTimePayments))    // 按期还款加分
+	score -= math.Min(200, 40*float64(f.MissedPayments))    // 逾期扣分
+	score -= math.Min(150, 15*float64(f.OverdraftAttempts)) // 透支尝试扣分
+	return int(math.Max(CREDIT_SCORE_MIN, math.Min(CREDIT_SCORE_MAX, math.Round(score))))
+}
+
+// 评分等级
+func creditGrade(score int) string {
+	for _, rule := range loanRules {
+		if score >= rule.MinScore {
+			return rule.Grade
+		}
+	}
+	return "D"
+}
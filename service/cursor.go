@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 游标分页每页条数
+const (
+	CURSOR_DEFAULT_LIMIT = 20
+	CURSOR_MAX_LIMIT     = 100
+)
+
+// 分页游标：上一页最后一条记录在存储中的位置及其编号。记录按追加顺序存储、位置不变，
+// 按位置向前翻页不受新记录插入影响；编号用于校验游标仍指向同一条记录（快照恢复后旧游标失效）
+type pageCursor struct {
+	Pos int
+	ID  string
+}
+
+// 编码为不透明的游标令牌
+func (c pageCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(c.Pos) + ":" + c.ID))
+}
+
+// 解析游标令牌，空令牌表示从最新记录开始（Pos 为 -1）
+func parseCursor(token string) (pageCursor, error) {
+	if token == "" {
+		return pageCursor{Pos: -1}, nil
+	}
+	invalid := model.NewError(model.CODE_PARAM_ERROR, "分页游标无效")
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageCursor{}, invalid
+	}
+	pos, id, found := strings.Cut(string(raw), ":")
+	if !found || id == "" {
+		return pageCursor{}, invalid
+	}
+	n, err := strconv.Atoi(pos)
+	if err != nil || n < 0 {
+		return pageCursor{}, invalid
+	}
+	return pageCursor{Pos: n, ID: id}, nil
+}
+
+// 游标已失效（记录已不在原位置）
+func staleCursorError() error {
+	return model.NewError(model.CODE_PARAM_ERROR, "分页游标已失效，请从第一页重新查询")
+}
+
+// 规范每页条数
+func cursorLimit(limit int) int {
+	if limit <= 0 {
+		return CURSOR_DEFAULT_LIMIT
+	}
+	if limit > CURSOR_MAX_LIMIT {
+		return CURSOR_MAX_LIMIT
+	}
+	return limit
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 客户资料（含名下账户）
+type CustomerProfile struct {
+	model.Customer
+	Accounts       []string        `json:"accounts"`                 // 名下账户ID
+	PendingChanges []ContactChange `json:"pendingChanges,omitempty"` // 待验证的联系方式变更
+}
+
+// 新建客户请求（开户时使用）
+type CustomerRequest struct {
+	Name   string
+	Phone  string
+	Email  string
+	Locale string
+}
+
+// 修改客户资料请求（字段为空表示不变，手机号与邮箱须经新联系方式验证后生效）
+type CustomerUpdateRequest struct {
+	Name    *string        `json:"name"`
+	Phone   *string        `json:"phone"`
+	Email   *string        `json:"email"`
+	Address *model.Address `json:"address"`
+	KYCTier *string        `json:"kycTier"`
+	Locale  *string        `json:"locale"`
+}
+
+// 客户服务：维护独立于账户的客户资料（姓名、联系方式、地址、KYC 等级），
+// 联系方式变更后同步到名下全部账户，短信、邮件等通知渠道随之使用新的联系方式
+type CustomerService struct {
+	customers *repository.CustomerRepository
+	accounts  *repository.AccountRepository
+	sms       *SMSService
+	emails    *EmailService
+	audit     *AuditService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu        sync.Mutex                // 资料修改互斥（需先于账户锁获取）
+	changes   map[string]*ContactChange // 变更编号 → 联系方式变更
+	changeSeq int64
+	seqMu     sync.Mutex // 客户号分配（叶子锁，开户时在账户锁内调用）
+	seq       int64
+}
+
+func NewCustomerService(customers *repository.CustomerRepository, accounts *repository.AccountRepository, sms *SMSService, emails *EmailService, audit *AuditService, clock Clock, notifier Notifier, templates *TemplateRegistry) *CustomerService {
+	s := &CustomerService{
+		customers: customers,
+		accounts:  accounts,
+		sms:       sms,
+		emails:    emails,
+		audit:     audit,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		changes:   make(map[string]*ContactChange),
+	}
+	s.syncSequence()
+	return s
+}
+
+// 按现有最大客户号同步客户号序列（启动及恢复状态快照后调用）
+func (s *CustomerService) syncSequence() {
+	s.seqMu.Lock()
+	defer s.seqMu.Unlock()
+
+	s.seq = 0
+	for _, customer := range s.customers.List() {
+		if n, err := strconv.ParseInt(strings.TrimPrefix(customer.CustomerID, "C"), 10, 64); err == nil && n > s.seq {
+			s.seq = n
+		}
+	}
+}
+
+// 新建客户（KYC 等级为基础级）
+func (s *CustomerService) Create(req CustomerRequest) model.Customer {
+	s.seqMu.Lock()
+	s.seq++
+	customerID := fmt.Sprintf("C%08d", s.seq)
+	s.seqMu.Unlock()
+
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+	return s.customers.Save(model.Customer{
+		CustomerID: customerID,
+		Name:       req.Name,
+		Phone:      req.Phone,
+		Email:      req.Email,
+		KYCTier:    model.KYC_TIER_BASIC,
+		Locale:     req.Locale,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+}
+
+// 查询客户资料
+func (s *CustomerService) Get(customerID string) (CustomerProfile, error) {
+	customer, exists := s.customers.Get(customerID)
+	if !exists {
+		return CustomerProfile{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "客户不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return CustomerProfile{Customer: customer, Accounts: s.accountIDs(customerID), PendingChanges: s.pendingChanges(customerID)}, nil
+}
+
+// 查询账户所属客户
+func (s *CustomerService) Owner(accountID string) (string, error) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return "", model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if account.CustomerID == "" {
+		return "", model.NewError(model.CODE_RESOURCE_NOT_FOUND, "账户未关联客户")
+	}
+	return account.CustomerID, nil
+}
+
+// 修改客户资料：姓名、地址、KYC 等级与语言立即生效（姓名与语言同步到名下全部账户），
+// 手机号与邮箱变更转为待验证申请，验证码发送到新的联系方式
+func (s *CustomerService) Update(customerID string, req CustomerUpdateRequest) (CustomerProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, exists := s.customers.Get(customerID)
+	if !exists {
+		return CustomerProfile{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "客户不存在")
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return CustomerProfile{}, model.NewError(model.CODE_PARAM_ERROR, "姓名不能为空")
+		}
+		customer.Name = name
+	}
+	var phone, email string
+	if req.Phone != nil {
+		phone = strings.TrimSpace(*req.Phone)
+		if err := ValidatePhone(phone); err != nil {
+			return CustomerProfile{}, err
+		}
+	}
+	if req.Email != nil {
+		email = strings.TrimSpace(*req.Email)
+		if err := ValidateEmail(email); err != nil {
+			return CustomerProfile{}, err
+		}
+	}
+	if req.Address != nil {
+		address := *req.Address
+		address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+		if address.Country != "" && len(address.Country) != 2 {
+			return CustomerProfile{}, model.NewError(model.CODE_PARAM_ERROR, "国家应为 ISO 3166-1 两位字母代码")
+		}
+		customer.Address = address
+	}
+	if req.KYCTier != nil {
+		switch *req.KYCTier {
+		case model.KYC_TIER_BASIC, model.KYC_TIER_STANDARD, model.KYC_TIER_ENHANCED:
+			customer.KYCTier = *req.KYCTier
+		default:
+			return CustomerProfile{}, model.NewError(model.CODE_PARAM_ERROR, "KYC 等级应为 basic、standard 或 enhanced")
+		}
+	}
+	if req.Locale != nil {
+		customer.Locale = strings.TrimSpace(*req.Locale)
+	}
+	if req.Phone != nil && phone != customer.Phone {
+		if _, err := s.requestChange(customer, CONTACT_PHONE, phone); err != nil {
+			return CustomerProfile{}, err
+		}
+	}
+	if req.Email != nil && email != customer.Email {
+		if _, err := s.requestChange(customer, CONTACT_EMAIL, email); err != nil {
+			return CustomerProfile{}, err
+		}
+	}
+	customer.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	customer = s.customers.Save(customer)
+	accountIDs := s.syncAccounts(customer)
+
+	// 终端提示：客户资料修改
+	log.Println("\n[👤 客户资料修改]")
+	log.Printf("修改时间: %s", customer.UpdatedAt)
+	log.Printf("客户号: %s（%s）", customer.CustomerID, customer.Name)
+	log.Printf("KYC 等级: %s", customer.KYCTier)
+	log.Printf("同步账户: %s", strings.Join(accountIDs, ", "))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return CustomerProfile{Customer: customer, Accounts: accountIDs, PendingChanges: s.pendingChanges(customerID)}, nil
+}
+
+// 将客户的姓名、联系方式与语言同步到名下全部账户，返回账户ID
+func (s *CustomerService) syncAccounts(customer model.Customer) []string {
+	accountIDs := s.accountIDs(customer.CustomerID)
+	for _, accountID := range accountIDs {
+		_, err := s.accounts.Update(accountID, 0, func(account *model.Account) error {
+			account.UserName = customer.Name
+			account.Phone = customer.Phone
+			account.Email = customer.Email
+			account.Locale = customer.Locale
+			return nil
+		}, nil)
+		if err != nil {
+			log.Printf("客户资料同步到账户 %s 失败: %v", accountID, err)
+		}
+	}
+	return accountIDs
+}
+
+// 客户名下账户ID（按账号排序）
+func (s *CustomerService) accountIDs(customerID string) []string {
+	ids := []string{}
+	for _, account := range s.accounts.List() {
+		if account.CustomerID == customerID {
+			ids = append(ids, account.AccountID)
+		}
+	}
+	return ids
+}
+
+// 校验手机号：可选 + 前缀，6-15 位数字（空表示清除）
+func ValidatePhone(phone string) error {
+	if phone == "" {
+		return nil
+	}
+	digits := strings.TrimPrefix(phone, "+")
+	if len(digits) < 6 || len(digits) > 15 || strings.Trim(digits, "0123456789") != "" {
+		return model.NewError(model.CODE_PARAM_ERROR, "手机号格式错误")
+	}
+	return nil
+}
+
+// 校验邮箱（空表示清除）
+func ValidateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 || !strings.Contains(email[at+1:], ".") || strings.ContainsAny(email, " \t\r\n") {
+		return model.NewError(model.CODE_PARAM_ERROR, "邮箱格式错误")
+	}
+	return nil
+}
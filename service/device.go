@@ -0,0 +1,173 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 设备状态
+const (
+	DEVICE_TRUSTED      = "trusted"      // 信任设备（交易无需验证设备）
+	DEVICE_UNRECOGNIZED = "unrecognized" // 未识别设备（交易须短信验证码验证，验证通过后转为信任设备）
+	DEVICE_REVOKED      = "revoked"      // 已移除（再次交易须重新验证）
+)
+
+// 设备标识最大长度
+const MAX_DEVICE_ID_LENGTH = 64
+
+// 登录设备
+type Device struct {
+	DeviceID  string `json:"deviceId"`
+	AccountID string `json:"accountId"`
+	Name      string `json:"name,omitempty"` // 设备名称（登录时的 User-Agent）
+	Status    string `json:"status"`         // trusted/unrecognized/revoked
+	LastIP    string `json:"lastIp,omitempty"`
+	FirstSeen string `json:"firstSeen"`
+	LastSeen  string `json:"lastSeen"`
+	TrustedAt string `json:"trustedAt,omitempty"`
+	RevokedAt string `json:"revokedAt,omitempty"`
+}
+
+// 校验设备标识（请求头原样透传，限制长度与字符，避免污染日志）
+func ValidateDeviceID(deviceID string) error {
+	if len(deviceID) > MAX_DEVICE_ID_LENGTH {
+		return model.NewError(model.CODE_PARAM_ERROR, "设备标识过长")
+	}
+	for _, c := range deviceID {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return model.NewError(model.CODE_PARAM_ERROR, "设备标识只能包含字母、数字及 -_.:")
+		}
+	}
+	return nil
+}
+
+// 登录时登记设备：账户首个设备自动信任，此后的新设备为未识别设备并推送安全提醒
+func (s *RiskService) ObserveDevice(accountID, deviceID, name, ip string) Device {
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+
+	s.mu.Lock()
+	devices := s.devices[accountID]
+	if devices == nil {
+		devices = make(map[string]*Device)
+		s.devices[accountID] = devices
+	}
+	device, exists := devices[deviceID]
+	if !exists {
+		device = &Device{DeviceID: deviceID, AccountID: accountID, Status: DEVICE_UNRECOGNIZED, FirstSeen: now}
+		if len(devices) == 0 {
+			device.Status = DEVICE_TRUSTED
+			device.TrustedAt = now
+		}
+		devices[deviceID] = device
+	}
+	if name != "" {
+		device.Name = name
+	}
+	device.LastIP = ip
+	device.LastSeen = now
+	result := *device
+	s.mu.Unlock()
+
+	if exists {
+		return result
+	}
+
+	// 终端提示：新设备
+	log.Println("\n[📱 新设备登录]")
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("设备标识: %s（%s）", deviceID, result.Status)
+	if result.Name != "" {
+		log.Printf("设备名称: %s", result.Name)
+	}
+	log.Printf("来源IP: %s", ip)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	if result.Status == DEVICE_UNRECOGNIZED {
+		if account, ok := s.accounts.Get(accountID); ok {
+			s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_NEW_DEVICE, map[string]interface{}{
+				"DeviceID": deviceID,
+				"Name":     result.Name,
+				"IP":       ip,
+			}))
+		}
+	}
+	return result
+}
+
+// 设备是否为信任设备（调用方需持有 s.mu）
+func (s *RiskService) deviceTrusted(accountID, deviceID string) bool {
+	device := s.devices[accountID][deviceID]
+	return device != nil && device.Status == DEVICE_TRUSTED
+}
+
+// 验证通过后将设备加入信任列表
+func (s *RiskService) trustDevice(accountID, deviceID, ip string) {
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.devices[accountID] == nil {
+		s.devices[accountID] = make(map[string]*Device)
+	}
+	device := s.devices[accountID][deviceID]
+	if device == nil {
+		device = &Device{DeviceID: deviceID, AccountID: accountID, FirstSeen: now}
+		s.devices[accountID][deviceID] = device
+	}
+	device.Status = DEVICE_TRUSTED
+	device.TrustedAt = now
+	device.RevokedAt = ""
+	device.LastIP = ip
+	device.LastSeen = now
+}
+
+// 账户设备列表（按最近使用时间倒序）
+func (s *RiskService) Devices(accountID string) []Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Device, 0, len(s.devices[accountID]))
+	for _, device := range s.devices[accountID] {
+		result = append(result, *device)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LastSeen != result[j].LastSeen {
+			return result[i].LastSeen > result[j].LastSeen
+		}
+		return result[i].DeviceID < result[j].DeviceID
+	})
+	return result
+}
+
+// 移除设备：设备不再信任，之后来自该设备的交易须重新验证（该设备的登录会话由调用方终止）
+func (s *RiskService) RevokeDevice(accountID, deviceID string) (Device, error) {
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+
+	s.mu.Lock()
+	device := s.devices[accountID][deviceID]
+	if device == nil {
+		s.mu.Unlock()
+		return Device{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "设备不存在")
+	}
+	if device.Status == DEVICE_REVOKED {
+		s.mu.Unlock()
+		return Device{}, model.NewError(model.CODE_PARAM_ERROR, "设备已移除")
+	}
+	device.Status = DEVICE_REVOKED
+	device.RevokedAt = now
+	result := *device
+	s.mu.Unlock()
+
+	// 终端提示：移除设备
+	log.Println("\n[📱 移除设备]")
+	log.Printf("操作时间: %s", now)
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("设备标识: %s", deviceID)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
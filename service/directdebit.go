@@ -0,0 +1,463 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 直接借记授权状态
+const (
+	MANDATE_ACTIVE    = "active"    // 有效
+	MANDATE_CANCELLED = "cancelled" // 客户已撤销
+)
+
+// 直接借记扣款状态
+const (
+	DIRECT_DEBIT_COLLECTED = "collected" // 已扣款
+	DIRECT_DEBIT_RETURNED  = "returned"  // 客户已退回
+)
+
+// 直接借记配置
+type DirectDebitConfig struct {
+	RefundDays int // 扣款后客户可申请退回的天数（模拟日）
+}
+
+// 直接借记授权登记请求结构体（由收款商户发起）
+type MandateRequest struct {
+	AccountID    string  `json:"accountId" validate:"required,account"`
+	CreditorID   string  `json:"creditorId" validate:"required"`   // 收款商户号
+	CreditorName string  `json:"creditorName" validate:"required"` // 收款商户名称
+	Reference    string  `json:"reference" validate:"required"`    // 商户侧授权编号（同一商户内唯一）
+	MaxAmount    float64 `json:"maxAmount" validate:"min=0"`       // 单笔扣款上限，0 表示不限
+}
+
+// 直接借记授权
+type Mandate struct {
+	MandateID       string  `json:"mandateId"`
+	AccountID       string  `json:"accountId"`
+	Currency        string  `json:"currency"`
+	CreditorID      string  `json:"creditorId"`
+	CreditorName    string  `json:"creditorName"`
+	Reference       string  `json:"reference"`
+	MaxAmount       float64 `json:"maxAmount"`
+	Status          string  `json:"status"` // active/cancelled
+	CreatedAt       string  `json:"createdAt"`
+	CancelledAt     string  `json:"cancelledAt,omitempty"`
+	LastCollectedAt string  `json:"lastCollectedAt,omitempty"`
+}
+
+// 直接借记扣款请求结构体（由收款商户发起，商户号须与授权一致）
+type DirectDebitRequest struct {
+	MandateID  string  `json:"mandateId" validate:"required"`
+	CreditorID string  `json:"creditorId" validate:"required"`
+	Amount     float64 `json:"amount" validate:"amount"`
+	Reference  string  `json:"reference"` // 商户侧扣款编号（如账单号）
+}
+
+// 直接借记退回请求结构体（由客户发起）
+type DirectDebitReturnRequest struct {
+	AccountID string `json:"accountId"`
+	Reason    string `json:"reason" validate:"required,max=200"`
+}
+
+// 直接借记扣款记录
+type DirectDebit struct {
+	CollectionID   string  `json:"collectionId"`
+	MandateID      string  `json:"mandateId"`
+	AccountID      string  `json:"accountId"`
+	CreditorID     string  `json:"creditorId"`
+	CreditorName   string  `json:"creditorName"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	Reference      string  `json:"reference,omitempty"`
+	Status         string  `json:"status"` // collected/returned
+	TxID           string  `json:"txId"`
+	CollectedAt    string  `json:"collectedAt"`
+	RefundDeadline string  `json:"refundDeadline"` // 可申请退回的截止时间
+	ReturnedAt     string  `json:"returnedAt,omitempty"`
+	ReturnReason   string  `json:"returnReason,omitempty"`
+	ReturnTxID     string  `json:"returnTxId,omitempty"`
+
+	refundDeadline time.Time
+}
+
+// 直接借记服务：商户登记授权后按授权扣款，客户可撤销授权，并可在退回期内退回扣款
+type DirectDebitService struct {
+	cfg       DirectDebitConfig
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	credit    *CreditService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu          sync.Mutex // 需先于账户锁获取
+	mandates    map[string]*Mandate
+	collections map[string]*DirectDebit
+	seq         int64
+}
+
+func NewDirectDebitService(cfg DirectDebitConfig, accounts *repository.AccountRepository, ledger *LedgerService, credit *CreditService, clock Clock, notifier Notifier, templates *TemplateRegistry) *DirectDebitService {
+	return &DirectDebitService{
+		cfg:         cfg,
+		accounts:    accounts,
+		ledger:      ledger,
+		credit:      credit,
+		clock:       clock,
+		notifier:    notifier,
+		templates:   templates,
+		mandates:    make(map[string]*Mandate),
+		collections: make(map[string]*DirectDebit),
+	}
+}
+
+// 登记直接借记授权
+func (s *DirectDebitService) Register(req MandateRequest) (Mandate, error) {
+	if err := model.Validate(req); err != nil {
+		return Mandate{}, err
+	}
+	req.CreditorID = strings.TrimSpace(req.CreditorID)
+	req.CreditorName = strings.TrimSpace(req.CreditorName)
+	req.Reference = strings.TrimSpace(req.Reference)
+
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return Mandate{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if account.Status != "normal" {
+		return Mandate{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法登记授权")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, mandate := range s.mandates {
+		if mandate.CreditorID == req.CreditorID && mandate.Reference == req.Reference && mandate.Status == MANDATE_ACTIVE {
+			return Mandate{}, model.NewError(model.CODE_PARAM_ERROR, "该商户已存在相同编号的有效授权")
+		}
+	}
+
+	now := s.clock.Now()
+	s.seq++
+	mandate := &Mandate{
+		MandateID:    fmt.Sprintf("MD%s%06d", now.Format("20060102"), s.seq),
+		AccountID:    req.AccountID,
+		Currency:     account.Currency,
+		CreditorID:   req.CreditorID,
+		CreditorName: req.CreditorName,
+		Reference:    req.Reference,
+		MaxAmount:    model.RoundAmount(req.MaxAmount),
+		Status:       MANDATE_ACTIVE,
+		CreatedAt:    now.Format("2006-01-02 15:04:05"),
+	}
+	s.mandates[mandate.MandateID] = mandate
+
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_MANDATE_CREATED, map[string]interface{}{
+		"Creditor":  mandate.CreditorName,
+		"MandateID": mandate.MandateID,
+		"MaxAmount": mandate.MaxAmount,
+	}))
+
+	// 终端提示：直接借记授权登记
+	log.Println("\n[🧾 直接借记授权登记]")
+	log.Printf("授权编号: %s（商户侧 %s）", mandate.MandateID, mandate.Reference)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("收款商户: %s（%s）", mandate.CreditorName, mandate.CreditorID)
+	if mandate.MaxAmount > 0 {
+		log.Printf("单笔上限: %.2f %s", mandate.MaxAmount, mandate.Currency)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *mandate, nil
+}
+
+// 查询授权
+func (s *DirectDebitService) Mandate(mandateID string) (Mandate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mandate, ok := s.mandates[mandateID]
+	if !ok {
+		return Mandate{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "授权不存在")
+	}
+	return *mandate, nil
+}
+
+// 账户的授权列表（按登记时间倒序）
+func (s *DirectDebitService) Mandates(accountID string) []Mandate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Mandate{}
+	for _, mandate := range s.mandates {
+		if mandate.AccountID == accountID {
+			result = append(result, *mandate)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].MandateID > result[j].MandateID })
+	return result
+}
+
+// 客户撤销授权：撤销后商户不能再发起扣款，已扣款项仍可在退回期内退回
+func (s *DirectDebitService) Cancel(mandateID, accountID string) (Mandate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mandate, ok := s.mandates[mandateID]
+	if !ok || mandate.AccountID != accountID {
+		return Mandate{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "授权不存在")
+	}
+	if mandate.Status != MANDATE_ACTIVE {
+		return Mandate{}, model.NewError(model.CODE_PARAM_ERROR, "授权已撤销")
+	}
+	mandate.Status = MANDATE_CANCELLED
+	mandate.CancelledAt = s.clock.Now().Format("2006-01-02 15:04:05")
+
+	// 终端提示：直接借记授权撤销
+	log.Println("\n[🧾 直接借记授权撤销]")
+	log.Printf("授权编号: %s", mandate.MandateID)
+	log.Printf("账户ID: %s", mandate.AccountID)
+	log.Printf("收款商户: %s（%s）", mandate.CreditorName, mandate.CreditorID)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *mandate, nil
+}
+
+// 商户按授权扣款
+func (s *DirectDebitService) Collect(req DirectDebitRequest) (DirectDebit, error) {
+	if err := model.Validate(req); err != nil {
+		return DirectDebit{}, err
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mandate, ok := s.mandates[req.MandateID]
+	if !ok || mandate.CreditorID != strings.TrimSpace(req.CreditorID) {
+		return DirectDebit{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "授权不存在")
+	}
+	if mandate.Status != MANDATE_ACTIVE {
+		return DirectDebit{}, model.NewError(model.CODE_RISK_CONTROL_REJECT, "授权已撤销，拒绝扣款")
+	}
+	if mandate.MaxAmount > 0 && req.Amount > mandate.MaxAmount {
+		return DirectDebit{}, model.NewError(model.CODE_ACCOUNT_LIMIT, fmt.Sprintf("扣款金额超过授权单笔上限 %.2f", mandate.MaxAmount))
+	}
+
+	now := s.clock.Now()
+	collection := &DirectDebit{
+		MandateID:      mandate.MandateID,
+		AccountID:      mandate.AccountID,
+		CreditorID:     mandate.CreditorID,
+		CreditorName:   mandate.CreditorName,
+		Amount:         req.Amount,
+		Currency:       mandate.Currency,
+		Reference:      strings.TrimSpace(req.Reference),
+		Status:         DIRECT_DEBIT_COLLECTED,
+		CollectedAt:    now.Format("2006-01-02 15:04:05"),
+		refundDeadline: now.AddDate(0, 0, s.cfg.RefundDays),
+	}
+	collection.RefundDeadline = collection.refundDeadline.Format("2006-01-02 15:04:05")
+
+	account, err := s.accounts.Update(mandate.AccountID, 0, func(account *model.Account) error {
+		if err := dormantError(*account); err != nil {
+			return err
+		}
+		if account.Status != "normal" {
+			return model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法扣款")
+		}
+		if account.Available() < req.Amount {
+			s.credit.RecordNSF(account.AccountID)
+			return model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "账户可用余额不足")
+		}
+		account.Balance = model.RoundAmount(account.Balance - req.Amount)
+		return nil
+	}, func(account model.Account) {
+		s.seq++
+		collection.CollectionID = fmt.Sprintf("DD%s%06d", now.Format("20060102"), s.seq)
+		collection.TxID = s.ledger.Record(model.Transaction{
+			AccountID:    account.AccountID,
+			Type:         "direct_debit",
+			Direction:    "debit",
+			Amount:       req.Amount,
+			BalanceAfter: account.Balance,
+			Counterparty: mandate.CreditorName,
+			Reference:    collection.CollectionID,
+			Description:  "直接借记扣款（授权 " + mandate.MandateID + "）",
+		}).TxID
+	})
+	if err != nil {
+		// 终端提示：直接借记扣款失败
+		log.Println("\n[❌ 直接借记扣款 - 失败]")
+		log.Printf("授权编号: %s", mandate.MandateID)
+		log.Printf("收款商户: %s（%s）", mandate.CreditorName, mandate.CreditorID)
+		log.Printf("扣款金额: %.2f %s", req.Amount, mandate.Currency)
+		log.Printf("失败原因: %v", err)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+		return DirectDebit{}, err
+	}
+	s.collections[collection.CollectionID] = collection
+	mandate.LastCollectedAt = collection.CollectedAt
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_DIRECT_DEBIT_COLLECTED, map[string]interface{}{
+		"Amount":         req.Amount,
+		"Balance":        account.Balance,
+		"Creditor":       mandate.CreditorName,
+		"Reference":      collection.CollectionID,
+		"RefundDeadline": collection.RefundDeadline,
+	}))
+
+	// 终端提示：直接借记扣款
+	log.Println("\n[🧾 直接借记扣款]")
+	log.Printf("扣款编号: %s（授权 %s）", collection.CollectionID, mandate.MandateID)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("收款商户: %s（%s）", mandate.CreditorName, mandate.CreditorID)
+	log.Printf("扣款金额: \033[1;33m%.2f %s\033[0m", req.Amount, account.Currency) // 黄色高亮
+	log.Printf("可退回截止: %s", collection.RefundDeadline)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *collection, nil
+}
+
+// 查询扣款记录（按扣款时间倒序），mandateID 不为空时只返回该授权下的扣款
+func (s *DirectDebitService) Collections(accountID, mandateID string) []DirectDebit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []DirectDebit{}
+	for _, collection := range s.collections {
+		if collection.AccountID != accountID || mandateID != "" && collection.MandateID != mandateID {
+			continue
+		}
+		result = append(result, *collection)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CollectionID > result[j].CollectionID })
+	return result
+}
+
+// 客户在退回期内退回扣款，款项原路退回账户
+func (s *DirectDebitService) Return(collectionID string, req DirectDebitReturnRequest) (DirectDebit, error) {
+	if err := model.Validate(req); err != nil {
+		return DirectDebit{}, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	collection, ok := s.collections[collectionID]
+	if !ok || collection.AccountID != req.AccountID {
+		return DirectDebit{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "扣款记录不存在")
+	}
+	if collection.Status != DIRECT_DEBIT_COLLECTED {
+		return DirectDebit{}, model.NewError(model.CODE_PARAM_ERROR, "该笔扣款已退回")
+	}
+	now := s.clock.Now()
+	if now.After(collection.refundDeadline) {
+		return DirectDebit{}, model.NewError(model.CODE_PARAM_ERROR, "已超过退回期限（"+collection.RefundDeadline+"）")
+	}
+
+	account, err := s.accounts.Update(collection.AccountID, 0, func(account *model.Account) error {
+		account.Balance = model.RoundAmount(account.Balance + collection.Amount)
+		return nil
+	}, func(account model.Account) {
+		collection.ReturnTxID = s.ledger.Record(model.Transaction{
+			AccountID:    account.AccountID,
+			Type:         "direct_debit_return",
+			Direction:    "credit",
+			Amount:       collection.Amount,
+			BalanceAfter: account.Balance,
+			Counterparty: collection.CreditorName,
+			Reference:    collection.CollectionID,
+			Description:  "直接借记退回：" + reason,
+		}).TxID
+	})
+	if err != nil {
+		return DirectDebit{}, err
+	}
+	collection.Status = DIRECT_DEBIT_RETURNED
+	collection.ReturnedAt = now.Format("2006-01-02 15:04:05")
+	collection.ReturnReason = reason
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_DIRECT_DEBIT_RETURNED, map[string]interface{}{
+		"Amount":    collection.Amount,
+		"Balance":   account.Balance,
+		"Creditor":  collection.CreditorName,
+		"Reference": collection.CollectionID,
+	}))
+
+	// 终端提示：直接借记退回
+	log.Println("\n[🧾 直接借记退回]")
+	log.Printf("扣款编号: %s（授权 %s）", collection.CollectionID, collection.MandateID)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("收款商户: %s（%s）", collection.CreditorName, collection.CreditorID)
+	log.Printf("退回金额: \033[1;32m%.2f %s\033[0m", collection.Amount, account.Currency) // 绿色高亮
+	log.Printf("退回原因: %s", reason)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *collection, nil
+}
+
+// 直接借记快照（随状态快照导出）
+type DirectDebitSnapshot struct {
+	Mandates    []Mandate     `json:"mandates"`
+	Collections []DirectDebit `json:"collections"`
+	Seq         int64         `json:"seq"`
+}
+
+// 导出授权与扣款记录（调用方需持有 s.mu，并在其后获取账户锁，使扣款记录与账户余额取自同一时刻）
+func (s *DirectDebitService) snapshot() DirectDebitSnapshot {
+	snapshot := DirectDebitSnapshot{
+		Mandates:    make([]Mandate, 0, len(s.mandates)),
+		Collections: make([]DirectDebit, 0, len(s.collections)),
+		Seq:         s.seq,
+	}
+	for _, mandate := range s.mandates {
+		snapshot.Mandates = append(snapshot.Mandates, *mandate)
+	}
+	for _, collection := range s.collections {
+		snapshot.Collections = append(snapshot.Collections, *collection)
+	}
+	sort.Slice(snapshot.Mandates, func(i, j int) bool { return snapshot.Mandates[i].MandateID < snapshot.Mandates[j].MandateID })
+	sort.Slice(snapshot.Collections, func(i, j int) bool {
+		return snapshot.Collections[i].CollectionID < snapshot.Collections[j].CollectionID
+	})
+	return snapshot
+}
+
+// 以快照替换授权与扣款记录（调用方需持有 s.mu）
+func (s *DirectDebitService) restore(snapshot DirectDebitSnapshot) {
+	mandates := make(map[string]*Mandate, len(snapshot.Mandates))
+	for _, mandate := range snapshot.Mandates {
+		mandate := mandate
+		mandates[mandate.MandateID] = &mandate
+	}
+	collections := make(map[string]*DirectDebit, len(snapshot.Collections))
+	for _, collection := range snapshot.Collections {
+		collection := collection
+		collection.refundDeadline, _ = time.ParseInLocation("2006-01-02 15:04:05", collection.RefundDeadline, time.Local)
+		collections[collection.CollectionID] = &collection
+	}
+	s.mandates, s.collections, s.seq = mandates, collections, snapshot.Seq
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 休眠账户状态
+const ACCOUNT_STATUS_DORMANT = "dormant"
+
+// 不计入账户活动的交易类型（银行侧自动记账，非客户发起）
+var passiveTxTypes = map[string]bool{
+	"fee":                    true,
+	"interest":               true,
+	"withholding_tax":        true,
+	"cashback":               true,
+	"loan_principal":         true,
+	"loan_interest":          true,
+	"loan_penalty":           true,
+	"direct_debit":           true, // 商户按授权发起
+	"direct_debit_return":    true,
+	"interchange_fee":        true,
+	"interchange_fee_refund": true,
+}
+
+// 休眠配置
+type DormancyConfig struct {
+	Days int // 连续无活动天数（模拟日），达到后转为休眠，0 表示不启用
+}
+
+// 账户激活请求结构体
+type ReactivateRequest struct {
+	AccountID string `json:"accountId" validate:"required,account"`
+}
+
+// 休眠服务：日终识别长期无活动的账户并转为休眠，休眠账户需激活后方可交易
+type DormancyService struct {
+	cfg       DormancyConfig
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	audit     *AuditService
+	clock     Clock
+	notifier  Notifier
+	mailer    Mailer
+	templates *TemplateRegistry
+
+	mu            sync.Mutex           // 需先于账户锁获取
+	reactivatedAt map[string]time.Time // 最近一次激活时间（激活视为一次账户活动）
+}
+
+func NewDormancyService(cfg DormancyConfig, accounts *repository.AccountRepository, ledger *LedgerService, audit *AuditService, clock Clock, notifier Notifier, mailer Mailer, templates *TemplateRegistry) *DormancyService {
+	return &DormancyService{
+		cfg:           cfg,
+		accounts:      accounts,
+		ledger:        ledger,
+		audit:         audit,
+		clock:         clock,
+		notifier:      notifier,
+		mailer:        mailer,
+		templates:     templates,
+		reactivatedAt: make(map[string]time.Time),
+	}
+}
+
+// 休眠账户需先激活才能交易
+func dormantError(account model.Account) error {
+	if account.Status == ACCOUNT_STATUS_DORMANT {
+		return model.NewError(model.CODE_ACCOUNT_DORMANT, "账户处于休眠状态，请先激活后再办理业务")
+	}
+	return nil
+}
+
+// 日终任务：截至 day 日终已连续无活动达到阈值的正常账户转为休眠
+func (s *DormancyService) Scan(day time.Time) {
+	if s.cfg.Days <= 0 {
+		return
+	}
+	cutoff := sim.StartOfDay(day).AddDate(0, 0, 1-s.cfg.Days)
+
+	type dormantAccount struct {
+		account      model.Account
+		lastActivity time.Time
+	}
+	var dormant []dormantAccount
+
+	s.mu.Lock()
+	s.accounts.Lock()
+	for _, id := range s.accounts.IDs() {
+		account, _ := s.accounts.Find(id)
+		if account.Status != "normal" {
+			continue
+		}
+		last := s.lastActivity(account)
+		if !last.Before(cutoff) {
+			continue
+		}
+		account.Status = ACCOUNT_STATUS_DORMANT
+		account = s.accounts.Save(account)
+		dormant = append(dormant, dormantAccount{account: account, lastActivity: last})
+	}
+	s.accounts.Unlock()
+	s.mu.Unlock()
+
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+	for _, d := range dormant {
+		vars := map[string]interface{}{
+			"Days":         s.cfg.Days,
+			"LastActivity": d.lastActivity.Format("2006-01-02"),
+			"Time":         now,
+		}
+		s.audit.Record(AUDIT_ACTOR_SYSTEM, AUDIT_ACCOUNT_STATUS_CHANGED, d.account.AccountID, map[string]string{
+			"from":   "normal",
+			"to":     ACCOUNT_STATUS_DORMANT,
+			"reason": fmt.Sprintf("连续 %d 天无交易", s.cfg.Days),
+		})
+		s.notifier.Send(s.templates.Alert("securityAlert", d.account, EVENT_ACCOUNT_DORMANT, vars))
+		s.mailer.Notify(d.account, EVENT_ACCOUNT_DORMANT, vars)
+
+		// 终端提示：账户转为休眠
+		log.Println("\n[💤 账户休眠]")
+		log.Printf("日终日期: %s", day.Format("2006-01-02"))
+		log.Printf("账户ID: %s", d.account.AccountID)
+		log.Printf("用户名: %s", d.account.UserName)
+		log.Printf("最近活动: %s（已连续 %d 天无交易）", d.lastActivity.Format("2006-01-02"), s.cfg.Days)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	}
+}
+
+// 激活休眠账户，激活时间计为一次账户活动
+func (s *DormancyService) Reactivate(req ReactivateRequest) (model.Account, error) {
+	if err := model.Validate(req); err != nil {
+		return model.Account{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, err := s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+		if account.Status != ACCOUNT_STATUS_DORMANT {
+			return model.NewError(model.CODE_PARAM_ERROR, "账户未处于休眠状态")
+		}
+		account.Status = "normal"
+		return nil
+	}, nil)
+	if err != nil {
+		return model.Account{}, err
+	}
+	now := s.clock.Now()
+	s.reactivatedAt[account.AccountID] = now
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, AUDIT_ACCOUNT_STATUS_CHANGED, account.AccountID, map[string]string{
+		"from": ACCOUNT_STATUS_DORMANT,
+		"to":   account.Status,
+	})
+
+	s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_ACCOUNT_REACTIVATED, map[string]interface{}{
+		"Time": now.Format("2006-01-02 15:04:05"),
+	}))
+
+	// 终端提示：休眠账户激活
+	log.Println("\n[🔔 休眠账户激活]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return account, nil
+}
+
+// 账户最近一次活动时间：客户发起的最近一笔交易或最近一次激活，均无时按开户日期（调用方持有账户锁）
+func (s *DormancyService) lastActivity(account model.Account) time.Time {
+	last := s.reactivatedAt[account.AccountID]
+	for _, tx := range s.ledger.Transactions(account.AccountID, time.Time{}, time.Time{}) {
+		if !passiveTxTypes[tx.Type] && tx.Time.After(last) {
+			last = tx.Time
+		}
+	}
+	if last.IsZero() {
+		if created, err := time.ParseInLocation("2006-01-02", account.CreateAt, time.Local); err == nil {
+			last = created
+		}
+	}
+	return last
+}
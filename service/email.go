@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 邮件记录（发件箱）
+type Email struct {
+	ID        string `json:"id"`
+	AccountID string `json:"accountId"`
+	To        string `json:"to"`
+	Template  string `json:"template"` // 消息事件类型
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	Status    string `json:"status"` // queued/sent/failed
+	Error     string `json:"error,omitempty"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// 邮件发送通道
+type EmailSender interface {
+	Name() string
+	Send(email Email) error
+}
+
+// 邮件配置
+type EmailConfig struct {
+	Sender                 string  // console（默认，仅打印并存入发件箱）/smtp
+	SMTPAddr               string  // SMTP 服务器地址（host:port）
+	SMTPUsername           string  // SMTP 用户名，为空则不认证
+	SMTPPassword           string  // SMTP 密码
+	From                   string  // 发件人地址
+	LargeTransferThreshold float64 // 大额转账提醒阈值（按转出账户币种）
+}
+
+// 邮件服务：渲染模板、存入发件箱并异步投递
+type EmailService struct {
+	cfg       EmailConfig
+	sender    EmailSender
+	templates *TemplateRegistry
+	clock     Clock
+
+	outbox []*Email
+	seq    int64
+	mu     sync.Mutex
+}
+
+func NewEmailService(cfg EmailConfig, templates *TemplateRegistry, clock Clock) *EmailService {
+	var sender EmailSender = consoleEmailSender{}
+	if cfg.Sender == "smtp" {
+		if cfg.SMTPAddr == "" {
+			log.Printf("未配置 SMTP 服务器地址，邮件改为输出到控制台")
+		} else {
+			sender = &smtpEmailSender{cfg: cfg}
+		}
+	}
+	log.Printf("邮件通道: %s，大额转账提醒阈值: %.2f", sender.Name(), cfg.LargeTransferThreshold)
+	return &EmailService{cfg: cfg, sender: sender, templates: templates, clock: clock}
+}
+
+// 大额转账提醒阈值
+func (s *EmailService) LargeTransferThreshold() float64 {
+	return s.cfg.LargeTransferThreshold
+}
+
+// 按模板给账户发送邮件（账户未登记邮箱时跳过），投递在后台进行，不阻塞业务
+func (s *EmailService) Notify(account model.Account, name string, vars map[string]interface{}) {
+	if account.Email == "" {
+		return
+	}
+	subject, body, err := s.templates.Render(name, CHANNEL_EMAIL, account, vars)
+	if err != nil {
+		log.Printf("邮件模板渲染失败: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	now := s.clock.Now()
+	s.seq++
+	email := &Email{
+		ID:        fmt.Sprintf("EM%s%06d", now.Format("20060102"), s.seq),
+		AccountID: account.AccountID,
+		To:        account.Email,
+		Template:  name,
+		Subject:   subject,
+		Body:      body,
+		Status:    "queued",
+		CreatedAt: now.Format("2006-01-02 15:04:05"),
+	}
+	s.outbox = append(s.outbox, email)
+	snapshot := *email
+	s.mu.Unlock()
+
+	go s.deliver(email, snapshot)
+}
+
+// 投递邮件并回写发送状态
+func (s *EmailService) deliver(email *Email, snapshot Email) {
+	err := s.sender.Send(snapshot)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		email.Status = "failed"
+		email.Error = err.Error()
+		log.Printf("邮件发送失败（%s → %s）: %v", email.ID, email.To, err)
+		return
+	}
+	email.Status = "sent"
+}
+
+// 查询发件箱（按时间倒序），accountID/template 为空表示不限
+func (s *EmailService) Outbox(accountID, template string) []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Email, 0)
+	for i := len(s.outbox) - 1; i >= 0; i-- {
+		email := s.outbox[i]
+		if (accountID != "" && email.AccountID != accountID) || (template != "" && email.Template != template) {
+			continue
+		}
+		result = append(result, *email)
+	}
+	return result
+}
+
+// 清空发件箱（测试用例之间重置）
+func (s *EmailService) ClearOutbox() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.outbox)
+	s.outbox = nil
+	return count
+}
+
+// -------------------------- 邮件发送通道 --------------------------
+
+// 控制台通道：仅打印邮件内容
+type consoleEmailSender struct{}
+
+func (consoleEmailSender) Name() string { return "console" }
+
+func (consoleEmailSender) Send(email Email) error {
+	log.Println("\n[📧 邮件发送]")
+	log.Printf("收件人: %s", email.To)
+	log.Printf("主题: %s", email.Subject)
+	log.Printf("正文: %s", strings.ReplaceAll(email.Body, "\n", " "))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+	return nil
+}
+
+// SMTP 通道
+type smtpEmailSender struct {
+	cfg EmailConfig
+}
+
+func (p *smtpEmailSender) Name() string { return "smtp" }
+
+func (p *smtpEmailSender) Send(email Email) error {
+	var auth smtp.Auth
+	if p.cfg.SMTPUsername != "" {
+		host := p.cfg.SMTPAddr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", p.cfg.SMTPUsername, p.cfg.SMTPPassword, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", p.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", email.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", email.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(p.cfg.SMTPAddr, auth, p.cfg.From, []string{email.To}, msg.Bytes())
+}
+
+// -------------------------- 工具函数 --------------------------
+
+// 账号后四位
+func accountSuffix(accountID string) string {
+	if len(accountID) < 4 {
+		return accountID
+	}
+	return accountID[len(accountID)-4:]
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 日终任务
+type EODJob struct {
+	Name string
+	Run  func(day time.Time)
+}
+
+// 日终服务：按注册顺序执行日终任务，并跟随模拟时钟自动补跑
+type EODService struct {
+	clock       Clock
+	jobs        []EODJob
+	mu          sync.Mutex // 保证同一时间只有一个日终在执行
+	lastEODDate time.Time
+}
+
+func NewEODService(clock Clock) *EODService {
+	return &EODService{
+		clock:       clock,
+		lastEODDate: sim.StartOfDay(clock.Now()).AddDate(0, 0, -1),
+	}
+}
+
+// 注册日终任务（需在调度启动前完成）
+func (s *EODService) AddJob(name string, run func(day time.Time)) {
+	s.jobs = append(s.jobs, EODJob{Name: name, Run: run})
+}
+
+// 日终调度：模拟时钟跨越日界后，依次补跑每个未处理日期的日终（active 返回 false 时跳过）
+func (s *EODService) RunScheduler(active func() bool) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !active() {
+			continue
+		}
+		today := sim.StartOfDay(s.clock.Now())
+		for {
+			s.mu.Lock()
+			next := s.lastEODDate.AddDate(0, 0, 1)
+			s.mu.Unlock()
+			if !next.Before(today) {
+				break
+			}
+			s.Run(next)
+		}
+	}
+}
+
+// 最近一次已完成日终的日期
+func (s *EODService) LastDate() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastEODDate
+}
+
+// 设置最近一次已完成日终的日期（恢复状态快照时使用，调度从次日继续补跑）
+func (s *EODService) SetLastDate(day time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastEODDate = sim.StartOfDay(day)
+}
+
+// 执行指定日期的日终
+func (s *EODService) Run(day time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	log.Println("\n[🌙 日终处理]")
+	log.Printf("日终日期: %s", day.Format("2006-01-02"))
+	for _, job := range s.jobs {
+		job.Run(day)
+		log.Printf("任务完成: %s", job.Name)
+	}
+	log.Printf("耗时: %s", time.Since(start))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	if day.After(s.lastEODDate) {
+		s.lastEODDate = day
+	}
+}
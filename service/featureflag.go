@@ -0,0 +1,206 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 功能开关
+const (
+	FLAG_RISK_ENGINE        = "risk_engine"        // 转账风控（新地点、新设备验证）
+	FLAG_FEES               = "fees"               // 转账手续费与低余额管理费
+	FLAG_STRICT_HTTP_STATUS = "strict_http_status" // 按业务码返回对应的 HTTP 状态码（默认统一返回 200）
+	FLAG_PAYEE_CHECK        = "payee_check"        // 首次向收款人转账前须核对收款人户名
+)
+
+// 功能开关取值来源
+const (
+	FLAG_SOURCE_DEFAULT = "default" // 内置默认值
+	FLAG_SOURCE_FILE    = "file"    // 配置文件
+	FLAG_SOURCE_RUNTIME = "runtime" // 管理接口设置
+)
+
+// 内置功能开关及默认值
+var featureFlagDefaults = []struct {
+	Name        string
+	Description string
+	Default     bool
+}{
+	{FLAG_RISK_ENGINE, "转账风控（新地点、新设备交易按规则标记或要求短信验证码）", true},
+	{FLAG_FEES, "转账手续费（跨币种、跨行）与低余额管理费", true},
+	{FLAG_STRICT_HTTP_STATUS, "按业务码返回对应的 HTTP 状态码（关闭时统一返回 200）", false},
+	{FLAG_PAYEE_CHECK, "首次向收款人转账前须核对收款人户名（须随风控开启）", false},
+}
+
+// 功能开关
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Default     bool   `json:"default"`
+	Source      string `json:"source"` // default/file/runtime
+	UpdatedAt   string `json:"updatedAt,omitempty"`
+}
+
+// 功能开关设置请求
+type FeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// 功能开关服务：取值依次取管理接口设置、配置文件与内置默认值，修改即时生效，无需重启
+type FeatureFlagService struct {
+	path string
+
+	mu      sync.RWMutex
+	file    map[string]bool // 配置文件中的取值
+	runtime map[string]bool // 管理接口设置的取值
+	updated map[string]time.Time
+}
+
+// 以配置文件创建功能开关服务（文件格式 {"fees": false}，文件不存在时使用默认值）
+func NewFeatureFlagService(path string) *FeatureFlagService {
+	s := &FeatureFlagService{path: path, runtime: make(map[string]bool), updated: make(map[string]time.Time)}
+	if err := s.Reload(); err != nil {
+		log.Printf("功能开关配置 %s 读取失败: %v，使用默认值", path, err)
+	}
+	return s
+}
+
+// 重新读取配置文件（管理接口设置的取值保持不变）
+func (s *FeatureFlagService) Reload() error {
+	file := make(map[string]bool)
+	data, err := os.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return model.NewError(model.CODE_PARAM_ERROR, "功能开关配置格式错误: "+err.Error())
+		}
+		for name := range file {
+			if _, ok := featureFlagDefault(name); !ok {
+				log.Printf("功能开关配置 %s 包含未知开关 %s，已忽略", s.path, name)
+				delete(file, name)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.file = file
+	s.mu.Unlock()
+	log.Printf("功能开关: %s", s.summary())
+	return nil
+}
+
+// 开关是否开启（未知开关视为关闭）
+func (s *FeatureFlagService) Enabled(name string) bool {
+	flag, ok := s.Get(name)
+	return ok && flag.Enabled
+}
+
+// 查询单个开关
+func (s *FeatureFlagService) Get(name string) (FeatureFlag, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, def := range featureFlagDefaults {
+		if def.Name == name {
+			return s.flag(def.Name, def.Description, def.Default), true
+		}
+	}
+	return FeatureFlag{}, false
+}
+
+// 全部开关（按内置顺序）
+func (s *FeatureFlagService) List() []FeatureFlag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flags := make([]FeatureFlag, 0, len(featureFlagDefaults))
+	for _, def := range featureFlagDefaults {
+		flags = append(flags, s.flag(def.Name, def.Description, def.Default))
+	}
+	return flags
+}
+
+// 设置开关（即时生效，重启后恢复为配置文件或默认值）
+func (s *FeatureFlagService) Set(name string, enabled bool) (FeatureFlag, error) {
+	if _, ok := featureFlagDefault(name); !ok {
+		return FeatureFlag{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "功能开关不存在: "+name)
+	}
+	s.mu.Lock()
+	s.runtime[name] = enabled
+	s.updated[name] = time.Now()
+	s.mu.Unlock()
+
+	flag, _ := s.Get(name)
+
+	// 终端提示：功能开关调整
+	log.Println("\n[🚩 功能开关调整]")
+	log.Printf("开关: %s（%s）", flag.Name, flag.Description)
+	log.Printf("状态: %s", onOff(flag.Enabled))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return flag, nil
+}
+
+// 撤销管理接口的设置，恢复为配置文件或默认值
+func (s *FeatureFlagService) Reset(name string) (FeatureFlag, error) {
+	if _, ok := featureFlagDefault(name); !ok {
+		return FeatureFlag{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "功能开关不存在: "+name)
+	}
+	s.mu.Lock()
+	delete(s.runtime, name)
+	s.updated[name] = time.Now()
+	s.mu.Unlock()
+
+	flag, _ := s.Get(name)
+	log.Printf("功能开关 %s 已撤销管理接口设置，当前: %s（来源: %s）", flag.Name, onOff(flag.Enabled), flag.Source)
+	return flag, nil
+}
+
+// 组装开关当前取值（调用方需持有读锁）
+func (s *FeatureFlagService) flag(name, description string, def bool) FeatureFlag {
+	flag := FeatureFlag{Name: name, Description: description, Enabled: def, Default: def, Source: FLAG_SOURCE_DEFAULT}
+	if enabled, ok := s.file[name]; ok {
+		flag.Enabled, flag.Source = enabled, FLAG_SOURCE_FILE
+	}
+	if enabled, ok := s.runtime[name]; ok {
+		flag.Enabled, flag.Source = enabled, FLAG_SOURCE_RUNTIME
+	}
+	if t, ok := s.updated[name]; ok {
+		flag.UpdatedAt = t.Format("2006-01-02 15:04:05")
+	}
+	return flag
+}
+
+// 全部开关的取值摘要
+func (s *FeatureFlagService) summary() string {
+	parts := make([]string, 0, len(featureFlagDefaults))
+	for _, flag := range s.List() {
+		parts = append(parts, flag.Name+"="+onOff(flag.Enabled))
+	}
+	return strings.Join(parts, "，")
+}
+
+// 内置开关的默认值
+func featureFlagDefault(name string) (bool, bool) {
+	for _, def := range featureFlagDefaults {
+		if def.Name == name {
+			return def.Default, true
+		}
+	}
+	return false, false
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "开启"
+	}
+	return "关闭"
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 报表中的一个科目
+type StatementLine struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"` // 按科目余额方向
+}
+
+// 单一币种的资产负债表
+type BalanceSheetCurrency struct {
+	Currency         string          `json:"currency"`
+	Assets           []StatementLine `json:"assets"`      // 现金、存放央行、待清算、贷款等
+	Liabilities      []StatementLine `json:"liabilities"` // 客户存款、应交税费、清算往来、挂账等
+	Equity           []StatementLine `json:"equity"`
+	CurrentEarnings  float64         `json:"currentEarnings"` // 尚未结账期间的累计损益（收入 - 费用）
+	TotalAssets      float64         `json:"totalAssets"`
+	TotalLiabilities float64         `json:"totalLiabilities"`
+	TotalEquity      float64         `json:"totalEquity"` // 含尚未结账的累计损益
+	Balanced         bool            `json:"balanced"`    // 资产 = 负债 + 所有者权益
+}
+
+// 资产负债表
+type BalanceSheet struct {
+	AsOf        string                 `json:"asOf"` // 截至该日日终
+	GeneratedAt string                 `json:"generatedAt"`
+	Currencies  []BalanceSheetCurrency `json:"currencies"`
+}
+
+// 单一币种的损益表
+type ProfitLossCurrency struct {
+	Currency     string          `json:"currency"`
+	Income       []StatementLine `json:"income"`   // 利息收入、手续费收入等
+	Expenses     []StatementLine `json:"expenses"` // 利息支出、营销费用等
+	TotalIncome  float64         `json:"totalIncome"`
+	TotalExpense float64         `json:"totalExpense"`
+	NetIncome    float64         `json:"netIncome"`
+}
+
+// 损益表
+type ProfitLoss struct {
+	From        string               `json:"from"`
+	To          string               `json:"to"`
+	GeneratedAt string               `json:"generatedAt"`
+	Currencies  []ProfitLossCurrency `json:"currencies"`
+}
+
+// 资产负债表：截至 asOf 当日日终的总账科目余额，按资产、负债、所有者权益分列，
+// 尚未结转的收入、费用余额以当期累计损益列入所有者权益
+func (s *LedgerService) BalanceSheet(asOf time.Time) BalanceSheet {
+	sheet := BalanceSheet{
+		AsOf:        asOf.Format("2006-01-02"),
+		GeneratedAt: s.clock.Now().Format("2006-01-02 15:04:05"),
+		Currencies:  make([]BalanceSheetCurrency, 0),
+	}
+	currencies := make(map[string]*BalanceSheetCurrency)
+	for _, balance := range s.glBalances(asOf.AddDate(0, 0, 1)) {
+		c, ok := currencies[balance.Currency]
+		if !ok {
+			c = &BalanceSheetCurrency{Currency: balance.Currency, Assets: []StatementLine{}, Liabilities: []StatementLine{}, Equity: []StatementLine{}}
+			currencies[balance.Currency] = c
+		}
+		line := StatementLine{Code: balance.Code, Name: balance.Name, Amount: balance.Balance}
+		switch balance.Type {
+		case "asset":
+			c.Assets = append(c.Assets, line)
+			c.TotalAssets += line.Amount
+		case "liability":
+			c.Liabilities = append(c.Liabilities, line)
+			c.TotalLiabilities += line.Amount
+		case "equity":
+			c.Equity = append(c.Equity, line)
+			c.TotalEquity += line.Amount
+		case "income":
+			c.CurrentEarnings += line.Amount
+		case "expense":
+			c.CurrentEarnings -= line.Amount
+		}
+	}
+	for _, c := range currencies {
+		c.CurrentEarnings = model.RoundAmount(c.CurrentEarnings)
+		c.TotalAssets = model.RoundAmount(c.TotalAssets)
+		c.TotalLiabilities = model.RoundAmount(c.TotalLiabilities)
+		c.TotalEquity = model.RoundAmount(c.TotalEquity + c.CurrentEarnings)
+		c.Balanced = c.TotalAssets == model.RoundAmount(c.TotalLiabilities+c.TotalEquity)
+		sheet.Currencies = append(sheet.Currencies, *c)
+	}
+	sort.Slice(sheet.Currencies, func(i, j int) bool { return sheet.Currencies[i].Currency < sheet.Currencies[j].Currency })
+	return sheet
+}
+
+// 损益表：[from, to] 日期区间内收入、费用科目的发生额（不含期间结账的结转分录）
+func (s *LedgerService) ProfitAndLoss(from, to time.Time) ProfitLoss {
+	end := to.AddDate(0, 0, 1)
+	type key struct{ code, currency string }
+	amounts := make(map[key]float64)
+	s.journal.View(func(_ []model.Transaction, entries []model.LedgerEntry) {
+		for _, entry := range entries {
+			if entry.Time.Before(from) || !entry.Time.Before(end) || strings.HasPrefix(entry.Reference, PERIOD_CLOSE_REFERENCE) {
+				continue
+			}
+			accountType := s.chart.Account(entry.Account).Type
+			if accountType != "income" && accountType != "expense" {
+				continue
+			}
+			// 收入科目贷方为正，费用科目借方为正
+			amount := entry.Amount
+			if (entry.Direction == "debit") != isDebitNormal(accountType) {
+				amount = -amount
+			}
+			amounts[key{entry.Account, entry.Currency}] += amount
+		}
+	})
+
+	report := ProfitLoss{
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		GeneratedAt: s.clock.Now().Format("2006-01-02 15:04:05"),
+		Currencies:  make([]ProfitLossCurrency, 0),
+	}
+	currencies := make(map[string]*ProfitLossCurrency)
+	for k, amount := range amounts {
+		c, ok := currencies[k.currency]
+		if !ok {
+			c = &ProfitLossCurrency{Currency: k.currency, Income: []StatementLine{}, Expenses: []StatementLine{}}
+			currencies[k.currency] = c
+		}
+		account := s.chart.Account(k.code)
+		line := StatementLine{Code: k.code, Name: account.Name, Amount: model.RoundAmount(amount)}
+		if account.Type == "income" {
+			c.Income = append(c.Income, line)
+			c.TotalIncome += line.Amount
+		} else {
+			c.Expenses = append(c.Expenses, line)
+			c.TotalExpense += line.Amount
+		}
+	}
+	byCode := func(lines []StatementLine) {
+		sort.Slice(lines, func(i, j int) bool { return lines[i].Code < lines[j].Code })
+	}
+	for _, c := range currencies {
+		byCode(c.Income)
+		byCode(c.Expenses)
+		c.TotalIncome = model.RoundAmount(c.TotalIncome)
+		c.TotalExpense = model.RoundAmount(c.TotalExpense)
+		c.NetIncome = model.RoundAmount(c.TotalIncome - c.TotalExpense)
+		report.Currencies = append(report.Currencies, *c)
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool { return report.Currencies[i].Currency < report.Currencies[j].Currency })
+	return report
+}
+
+// 导出资产负债表为 CSV：每行一个科目，末尾为各类合计
+func (b BalanceSheet) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"asOf", "currency", "section", "code", "name", "amount"})
+	for _, c := range b.Currencies {
+		for _, section := range []struct {
+			name  string
+			lines []StatementLine
+		}{{"asset", c.Assets}, {"liability", c.Liabilities}, {"equity", c.Equity}} {
+			for _, line := range section.lines {
+				w.Write([]string{b.AsOf, c.Currency, section.name, line.Code, line.Name, fmt.Sprintf("%.2f", line.Amount)})
+			}
+		}
+		w.Write([]string{b.AsOf, c.Currency, "equity", "", "current_earnings", fmt.Sprintf("%.2f", c.CurrentEarnings)})
+		w.Write([]string{b.AsOf, c.Currency, "total", "", "total_assets", fmt.Sprintf("%.2f", c.TotalAssets)})
+		w.Write([]string{b.AsOf, c.Currency, "total", "", "total_liabilities", fmt.Sprintf("%.2f", c.TotalLiabilities)})
+		w.Write([]string{b.AsOf, c.Currency, "total", "", "total_equity", fmt.Sprintf("%.2f", c.TotalEquity)})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// 导出损益表为 CSV：每行一个科目，末尾为收入、费用合计与净利润
+func (p ProfitLoss) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"from", "to", "currency", "section", "code", "name", "amount"})
+	for _, c := range p.Currencies {
+		for _, line := range c.Income {
+			w.Write([]string{p.From, p.To, c.Currency, "income", line.Code, line.Name, fmt.Sprintf("%.2f", line.Amount)})
+		}
+		for _, line := range c.Expenses {
+			w.Write([]string{p.From, p.To, c.Currency, "expense", line.Code, line.Name, fmt.Sprintf("%.2f", line.Amount)})
+		}
+		w.Write([]string{p.From, p.To, c.Currency, "total", "", "total_income", fmt.Sprintf("%.2f", c.TotalIncome)})
+		w.Write([]string{p.From, p.To, c.Currency, "total", "", "total_expense", fmt.Sprintf("%.2f", c.TotalExpense)})
+		w.Write([]string{p.From, p.To, c.Currency, "total", "", "net_income", fmt.Sprintf("%.2f", c.NetIncome)})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"math"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 交易流水实时推送事件（管理员主题 admin.transactions 的消息负载）
+type FirehoseEvent struct {
+	model.Transaction
+	AmountBase float64 `json:"amountBase"` // 折合人民币金额（过滤条件 minAmount 按此比较）
+}
+
+// 过滤字段：所属账户与折合人民币金额
+func (e FirehoseEvent) FilterFields() (string, float64) {
+	return e.AccountID, e.AmountBase
+}
+
+// 交易流水实时推送：订阅总账，将全行每一笔交易流水推送到管理员主题，
+// 供监控、反欺诈看板演示（订阅者可按账户或金额阈值过滤）
+type FirehoseService struct {
+	fx       *FXService
+	notifier Notifier
+}
+
+func NewFirehoseService(ledger *LedgerService, fx *FXService, notifier Notifier) *FirehoseService {
+	s := &FirehoseService{fx: fx, notifier: notifier}
+	ledger.Subscribe(s.onTransaction)
+	return s
+}
+
+// 流水回调（在账户写锁内执行，推送交由 hub 异步分发，不逐条打印日志）
+func (s *FirehoseService) onTransaction(tx model.Transaction) {
+	s.notifier.Publish(ws.TOPIC_ADMIN_TRANSACTIONS, "transaction", FirehoseEvent{
+		Transaction: tx,
+		AmountBase:  math.Round(s.fx.ToBase(tx.Amount, tx.Currency)*100) / 100,
+	})
+}
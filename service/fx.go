@@ -0,0 +1,225 @@
+package service
+
+import (
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 汇率设置请求结构体
+type FXRateRequest struct {
+	Currency string   `json:"currency"`
+	Rate     float64  `json:"rate"`   // 中间价：1 单位外币折合人民币
+	Spread   *float64 `json:"spread"` // 可选，同时调整点差
+}
+
+// 汇率牌价
+type FXRate struct {
+	Currency  string  `json:"currency"`
+	MidRate   float64 `json:"midRate"`
+	BuyRate   float64 `json:"buyRate"`  // 银行买入价
+	SellRate  float64 `json:"sellRate"` // 银行卖出价
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+// 外汇配置
+type FXConfig struct {
+	Spread         float64       // 点差（单边），客户兑换时按不利方向计价
+	Provider       string        // 汇率源：simulator（默认）/static/http，或通过 RegisterRateProvider 注册的外部实现
+	ProviderURL    string        // http 汇率源地址
+	UpdateInterval time.Duration // 行情刷新间隔
+	Volatility     float64       // 模拟器单步波动率
+	FailureRate    float64       // 模拟汇率源拉取失败概率（用于测试熔断与降级）
+}
+
+// 外汇服务：汇率牌价、兑换报价与行情刷新
+type FXService struct {
+	cfg       FXConfig
+	notifier  Notifier
+	providers map[string]RateProviderFactory
+	breaker   *CircuitBreaker
+
+	mu        sync.RWMutex
+	rates     map[string]float64 // 1 单位币种折合人民币的中间价
+	spread    float64
+	updatedAt time.Time
+}
+
+func NewFXService(cfg FXConfig, notifier Notifier, breaker *CircuitBreaker) *FXService {
+	s := &FXService{
+		cfg:       cfg,
+		notifier:  notifier,
+		providers: defaultRateProviders(),
+		breaker:   breaker,
+		rates: map[string]float64{
+			"CNY": 1,
+			"USD": 7.10,
+			"EUR": 7.75,
+			"GBP": 9.05,
+			"HKD": 0.91,
+			"JPY": 0.048,
+		},
+		spread:    cfg.Spread,
+		updatedAt: time.Now(),
+	}
+	// 请求中的币种字段按牌价表校验是否受支持
+	model.RegisterValidator("currency", func(value reflect.Value, _ string) string {
+		if !s.IsSupported(value.String()) {
+			return "不支持的币种: " + value.String()
+		}
+		return ""
+	})
+	return s
+}
+
+// 计算客户从 from 币种兑换到 to 币种的成交汇率（已扣点差）与中间价
+func (s *FXService) Quote(from, to string) (rate float64, mid float64, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fromRate, okFrom := s.rates[from]
+	toRate, okTo := s.rates[to]
+	if !okFrom || !okTo {
+		return 0, 0, false
+	}
+	mid = fromRate / toRate
+	if from == to {
+		return mid, mid, true
+	}
+	return mid * (1 - s.spread), mid, true
+}
+
+// 将人民币计价的金额（如手续费、限额）按中间价折算为指定币种，不支持的币种原样返回
+func (s *FXService) FromBase(amount float64, currency string) float64 {
+	_, perBase, ok := s.Quote(model.BASE_CURRENCY, currency)
+	if !ok {
+		return amount
+	}
+	return model.RoundAmount(amount * perBase)
+}
+
+// 将指定币种金额按中间价折算为人民币（不四舍五入，供积分、评分等统计使用），不支持的币种原样返回
+func (s *FXService) ToBase(amount float64, currency string) float64 {
+	if currency == "" || currency == model.BASE_CURRENCY {
+		return amount
+	}
+	_, perBase, ok := s.Quote(model.BASE_CURRENCY, currency)
+	if !ok || perBase <= 0 {
+		return amount
+	}
+	return amount / perBase
+}
+
+// 生成币种牌价（调用方需持有锁）
+func (s *FXService) newFXRate(currency string) FXRate {
+	mid := s.rates[currency]
+	return FXRate{
+		Currency:  currency,
+		MidRate:   mid,
+		BuyRate:   mid * (1 - s.spread),
+		SellRate:  mid * (1 + s.spread),
+		UpdatedAt: s.updatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// 币种是否受支持
+func (s *FXService) IsSupported(currency string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.rates[currency]
+	return ok
+}
+
+// 全部外币牌价（按币种排序）及当前点差
+func (s *FXService) Rates() ([]FXRate, float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := make([]FXRate, 0, len(s.rates))
+	for currency := range s.rates {
+		if currency != model.BASE_CURRENCY {
+			rates = append(rates, s.newFXRate(currency))
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Currency < rates[j].Currency })
+	return rates, s.spread
+}
+
+// 设置汇率中间价（可同时调整点差），返回生效后的点差
+func (s *FXService) SetRate(req FXRateRequest) (float64, error) {
+	currency := strings.ToUpper(req.Currency)
+	if len(currency) != 3 || currency == model.BASE_CURRENCY || req.Rate <= 0 {
+		return 0, model.NewError(model.CODE_PARAM_ERROR, "币种应为三位代码（非人民币），汇率必须大于0")
+	}
+	if req.Spread != nil && (*req.Spread < 0 || *req.Spread >= 0.5) {
+		return 0, model.NewError(model.CODE_PARAM_ERROR, "点差应在 0 到 0.5 之间")
+	}
+
+	s.mu.Lock()
+	oldRate := s.rates[currency]
+	s.rates[currency] = req.Rate
+	if req.Spread != nil {
+		s.spread = *req.Spread
+	}
+	s.updatedAt = time.Now()
+	spread := s.spread
+	rate := s.newFXRate(currency)
+	s.mu.Unlock()
+
+	s.notifier.Publish("rates", "rateUpdate", []FXRate{rate})
+
+	// 终端提示：汇率调整
+	log.Println("\n[💱 汇率调整]")
+	log.Printf("调整时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("币种: %s", currency)
+	log.Printf("中间价: %.4f → \033[1;36m%.4f\033[0m", oldRate, req.Rate)
+	log.Printf("点差: %.4f", spread)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return spread, nil
+}
+
+// 更新汇率表，返回发生变动的牌价（忽略基准币种与非正数汇率）
+func (s *FXService) Apply(rates map[string]float64) []FXRate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var currencies []string
+	for currency, rate := range rates {
+		currency = strings.ToUpper(currency)
+		if currency == model.BASE_CURRENCY || rate <= 0 || s.rates[currency] == rate {
+			continue
+		}
+		s.rates[currency] = rate
+		currencies = append(currencies, currency)
+	}
+	if len(currencies) == 0 {
+		return nil
+	}
+
+	s.updatedAt = time.Now()
+	changed := make([]FXRate, 0, len(currencies))
+	for _, currency := range currencies {
+		changed = append(changed, s.newFXRate(currency))
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Currency < changed[j].Currency })
+	return changed
+}
+
+// 复制当前汇率表
+func (s *FXService) Snapshot() map[string]float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := make(map[string]float64, len(s.rates))
+	for currency, rate := range s.rates {
+		rates[currency] = rate
+	}
+	return rates
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 汇率源：定时提供最新中间价（1 单位币种折合人民币）
+type RateProvider interface {
+	Name() string
+	Rates() (map[string]float64, error)
+}
+
+// 汇率源构造函数（按 FXConfig.Provider 选择）
+type RateProviderFactory func(s *FXService) (RateProvider, error)
+
+// 内置汇率源
+func defaultRateProviders() map[string]RateProviderFactory {
+	return map[string]RateProviderFactory{
+		"simulator": func(s *FXService) (RateProvider, error) {
+			return &randomWalkProvider{volatility: s.cfg.Volatility, snapshot: s.Snapshot}, nil
+		},
+		"static": func(*FXService) (RateProvider, error) { return staticRateProvider{}, nil },
+		"http":   newHTTPRateProvider,
+	}
+}
+
+// 注册外部汇率源实现，之后可通过 FX_PROVIDER=<name> 启用（需在 RunFeed 之前调用）
+func (s *FXService) RegisterRateProvider(name string, factory RateProviderFactory) {
+	s.providers[name] = factory
+}
+
+// -------------------------- 内置汇率源 --------------------------
+
+// 随机游走模拟器：以当前牌价为起点，每次按对数正态步长随机波动
+type randomWalkProvider struct {
+	volatility float64
+	snapshot   func() map[string]float64
+}
+
+func (p *randomWalkProvider) Name() string { return "simulator" }
+
+func (p *randomWalkProvider) Rates() (map[string]float64, error) {
+	rates := p.snapshot()
+	for currency, rate := range rates {
+		if currency == model.BASE_CURRENCY {
+			continue
+		}
+		next := rate * math.Exp(p.volatility*rand.NormFloat64())
+		rates[currency] = math.Round(next*1e6) / 1e6
+	}
+	return rates, nil
+}
+
+// 静态汇率源：不自动刷新，仅由管理员手工调整
+type staticRateProvider struct{}
+
+func (staticRateProvider) Name() string { return "static" }
+
+func (staticRateProvider) Rates() (map[string]float64, error) { return nil, nil }
+
+// HTTP 汇率源：GET FX_PROVIDER_URL，返回 {"rates": {"USD": 7.1, ...}}
+type httpRateProvider struct {
+	url    string
+	client *http.Client
+}
+
+func newHTTPRateProvider(s *FXService) (RateProvider, error) {
+	if s.cfg.ProviderURL == "" {
+		return nil, fmt.Errorf("FX_PROVIDER_URL 未配置")
+	}
+	return &httpRateProvider{url: s.cfg.ProviderURL, client: &http.Client{Timeout: 5 * time.Second}}, nil
+}
+
+func (p *httpRateProvider) Name() string { return "http" }
+
+func (p *httpRateProvider) Rates() (map[string]float64, error) {
+	resp, err := p.client.Get(p.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("汇率源返回状态码 %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Rates, nil
+}
+
+// -------------------------- 行情刷新 --------------------------
+
+// 汇率行情：按配置创建汇率源，定时拉取并推送变动到 WebSocket "rates" 主题
+func (s *FXService) RunFeed() {
+	name := s.cfg.Provider
+	if name == "" {
+		name = "simulator"
+	}
+	factory, ok := s.providers[name]
+	if !ok {
+		log.Printf("未知的汇率源: %s，汇率行情未启动", name)
+		return
+	}
+	provider, err := factory(s)
+	if err != nil {
+		log.Printf("汇率源 %s 初始化失败: %v，汇率行情未启动", name, err)
+		return
+	}
+	log.Printf("汇率行情已启动，汇率源: %s，刷新间隔: %s", provider.Name(), s.cfg.UpdateInterval)
+
+	ticker := time.NewTicker(s.cfg.UpdateInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// 汇率源熔断期间不再拉取，沿用最近一次牌价报价与折算
+		if err := s.breaker.Allow(); err != nil {
+			continue
+		}
+		rates, err := provider.Rates()
+		if err == nil && rand.Float64() < s.cfg.FailureRate {
+			rates, err = nil, fmt.Errorf("汇率源响应超时")
+		}
+		s.breaker.Record(err)
+		if err != nil {
+			log.Printf("汇率源 %s 拉取失败: %v", provider.Name(), err)
+			continue
+		}
+		if changed := s.Apply(rates); len(changed) > 0 {
+			s.notifier.Publish("rates", "rateUpdate", changed)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 总账科目
+type GLAccount struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"` // asset/liability/equity/income/expense
+	Role string `json:"role"` // 科目用途，系统按用途记账
+}
+
+// 总账科目余额
+type GLBalance struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Currency    string  `json:"currency"`
+	DebitTotal  float64 `json:"debitTotal"`
+	CreditTotal float64 `json:"creditTotal"`
+	Balance     float64 `json:"balance"` // 按科目余额方向计算（资产/费用为借方余额，其余为贷方余额）
+}
+
+// 科目用途
+const (
+	GL_CUSTOMER_DEPOSITS = "customer_deposits" // 客户存款
+	GL_CASH              = "cash"              // 库存现金
+	GL_CENTRAL_BANK      = "central_bank"      // 存放中央银行款项（跨行清算备付金）
+	GL_CLEARING          = "clearing"          // 跨行清算往来
+	GL_CARD_SETTLEMENT   = "card_settlement"   // 银行卡待清算
+	GL_FX_POSITION       = "fx_position"       // 外汇敞口
+	GL_FEE_INCOME        = "fee_income"        // 手续费收入
+	GL_INTEREST_EXPENSE  = "interest_expense"  // 利息支出
+	GL_TAX_PAYABLE       = "tax_payable"       // 应交税费（代扣利息税）
+	GL_MARKETING_EXPENSE = "marketing_expense" // 营销费用
+	GL_LOANS             = "loans"             // 贷款
+	GL_INTEREST_INCOME   = "interest_income"   // 利息收入
+	GL_SUSPENSE          = "suspense"          // 待处理挂账
+	GL_EQUITY            = "equity"            // 实收资本
+	GL_RETAINED_EARNINGS = "retained_earnings" // 未分配利润（月末结账时损益结转）
+)
+
+// 默认科目表（配置文件缺失或缺少某用途时使用）
+var defaultGLAccounts = []GLAccount{
+	{Code: "1001", Name: "库存现金", Type: "asset", Role: GL_CASH},
+	{Code: "1003", Name: "存放中央银行款项", Type: "asset", Role: GL_CENTRAL_BANK},
+	{Code: "1131", Name: "银行卡待清算款项", Type: "asset", Role: GL_CARD_SETTLEMENT},
+	{Code: "1301", Name: "贷款", Type: "asset", Role: GL_LOANS},
+	{Code: "2011", Name: "客户存款", Type: "liability", Role: GL_CUSTOMER_DEPOSITS},
+	{Code: "2221", Name: "应交税费", Type: "liability", Role: GL_TAX_PAYABLE},
+	{Code: "2311", Name: "跨行清算往来", Type: "liability", Role: GL_CLEARING},
+	{Code: "2901", Name: "待处理挂账", Type: "liability", Role: GL_SUSPENSE},
+	{Code: "3001", Name: "实收资本", Type: "equity", Role: GL_EQUITY},
+	{Code: "3101", Name: "外汇敞口", Type: "equity", Role: GL_FX_POSITION},
+	{Code: "3131", Name: "未分配利润", Type: "equity", Role: GL_RETAINED_EARNINGS},
+	{Code: "6011", Name: "利息收入", Type: "income", Role: GL_INTEREST_INCOME},
+	{Code: "6021", Name: "手续费收入", Type: "income", Role: GL_FEE_INCOME},
+	{Code: "6411", Name: "利息支出", Type: "expense", Role: GL_INTEREST_EXPENSE},
+	{Code: "6601", Name: "营销费用", Type: "expense", Role: GL_MARKETING_EXPENSE},
+}
+
+// 科目表（按代码排序）及用途、代码索引
+type ChartOfAccounts struct {
+	accounts []GLAccount
+	byRole   map[string]GLAccount
+	byCode   map[string]GLAccount
+}
+
+// 由科目列表构造科目表，缺少的用途以默认科目补齐（同一用途重复时保留首个）
+func NewChartOfAccounts(configured []GLAccount) *ChartOfAccounts {
+	c := &ChartOfAccounts{
+		byRole: make(map[string]GLAccount),
+		byCode: make(map[string]GLAccount),
+	}
+	for _, account := range configured {
+		if account.Code == "" || account.Role == "" {
+			continue
+		}
+		if _, dup := c.byRole[account.Role]; dup {
+			log.Printf("科目表配置中用途 %s 重复，忽略科目 %s", account.Role, account.Code)
+			continue
+		}
+		c.add(account)
+	}
+	for _, account := range defaultGLAccounts {
+		if _, ok := c.byRole[account.Role]; !ok {
+			c.add(account)
+		}
+	}
+	sort.Slice(c.accounts, func(i, j int) bool { return c.accounts[i].Code < c.accounts[j].Code })
+	return c
+}
+
+func (c *ChartOfAccounts) add(account GLAccount) {
+	c.accounts = append(c.accounts, account)
+	c.byRole[account.Role] = account
+	c.byCode[account.Code] = account
+}
+
+// 加载科目表：读取配置文件，缺少的用途以默认科目补齐
+func LoadChartOfAccounts(path string) *ChartOfAccounts {
+	var configured []GLAccount
+	if data, err := os.ReadFile(path); err != nil {
+		log.Printf("未读取到科目表配置 %s，使用默认科目表", path)
+	} else if err := json.Unmarshal(data, &configured); err != nil {
+		log.Printf("科目表配置 %s 格式错误: %v，使用默认科目表", path, err)
+		configured = nil
+	}
+
+	chart := NewChartOfAccounts(configured)
+	log.Printf("科目表加载完成，共 %d 个科目", len(chart.accounts))
+	return chart
+}
+
+// 按用途取科目代码
+func (c *ChartOfAccounts) Code(role string) string {
+	return c.byRole[role].Code
+}
+
+// 按代码取科目
+func (c *ChartOfAccounts) Account(code string) GLAccount {
+	return c.byCode[code]
+}
+
+// 全部科目（按代码排序）
+func (c *ChartOfAccounts) Accounts() []GLAccount {
+	return append([]GLAccount{}, c.accounts...)
+}
+
+// 资产、费用类科目为借方余额
+func isDebitNormal(accountType string) bool {
+	return accountType == "asset" || accountType == "expense"
+}
+
+// 总账科目余额：客户流水汇总记入客户存款科目，内部分录记入各自科目
+func (s *LedgerService) GLBalances() []GLBalance {
+	return s.glBalances(time.Time{})
+}
+
+// 截至 before（不含）的总账科目余额，零值表示不限
+func (s *LedgerService) glBalances(before time.Time) []GLBalance {
+	type key struct{ code, currency string }
+	totals := make(map[key]*GLBalance)
+	post := func(code, currency, direction string, amount float64) {
+		k := key{code, currency}
+		balance, ok := totals[k]
+		if !ok {
+			account := s.chart.Account(code)
+			balance = &GLBalance{Code: code, Name: account.Name, Type: account.Type, Currency: currency}
+			totals[k] = balance
+		}
+		if direction == "debit" {
+			balance.DebitTotal += amount
+		} else {
+			balance.CreditTotal += amount
+		}
+	}
+
+	depositsCode := s.chart.Code(GL_CUSTOMER_DEPOSITS)
+	s.journal.View(func(transactions []model.Transaction, entries []model.LedgerEntry) {
+		for _, tx := range transactions {
+			if before.IsZero() || tx.Time.Before(before) {
+				post(depositsCode, tx.Currency, tx.Direction, tx.Amount)
+			}
+		}
+		for _, entry := range entries {
+			if before.IsZero() || entry.Time.Before(before) {
+				post(entry.Account, entry.Currency, entry.Direction, entry.Amount)
+			}
+		}
+	})
+
+	balances := make([]GLBalance, 0, len(totals))
+	for _, balance := range totals {
+		balance.DebitTotal = model.RoundAmount(balance.DebitTotal)
+		balance.CreditTotal = model.RoundAmount(balance.CreditTotal)
+		if isDebitNormal(balance.Type) {
+			balance.Balance = model.RoundAmount(balance.DebitTotal - balance.CreditTotal)
+		} else {
+			balance.Balance = model.RoundAmount(balance.CreditTotal - balance.DebitTotal)
+		}
+		balances = append(balances, *balance)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Code != balances[j].Code {
+			return balances[i].Code < balances[j].Code
+		}
+		return balances[i].Currency < balances[j].Currency
+	})
+	return balances
+}
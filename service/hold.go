@@ -0,0 +1,615 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 冻结类型
+const (
+	HOLD_TYPE_STANDARD = "standard" // 普通冻结（止付）
+	HOLD_TYPE_LEGAL    = "legal"    // 司法冻结（法院强制执行、扣划令），仅管理员可设置与解除，不自动到期
+)
+
+// 冻结（止付）请求结构体
+type HoldRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"amount"`
+	Reason    string  `json:"reason" validate:"required,max=200"`
+	ExpiresAt string  `json:"expiresAt"` // 到期时间（模拟时间，格式 2006-01-02 15:04:05），为空表示长期有效
+}
+
+// 冻结修改请求结构体（字段为空表示不变，expiresAt 为空字符串表示改为长期有效）
+type HoldUpdateRequest struct {
+	Amount    *float64 `json:"amount" validate:"omitempty,amount"`
+	Reason    *string  `json:"reason" validate:"omitempty,required,max=200"`
+	ExpiresAt *string  `json:"expiresAt"`
+}
+
+// 司法冻结请求结构体（管理员）
+type LegalHoldRequest struct {
+	AccountID   string  `json:"accountId" validate:"required,account"`
+	Amount      float64 `json:"amount" validate:"omitempty,amount"` // 冻结金额，fullBalance 为 true 时忽略
+	FullBalance bool    `json:"fullBalance"`                        // 冻结全部可用余额
+	OrderRef    string  `json:"orderRef" validate:"required"`       // 法律文书编号
+	Authority   string  `json:"authority" validate:"required"`      // 执行机关
+	Reason      string  `json:"reason"`
+	Operator    string  `json:"operator"`
+}
+
+// 司法冻结解除请求结构体（amount 为 0 表示全部解除）
+type LegalHoldReleaseRequest struct {
+	Amount   float64 `json:"amount" validate:"min=0"`
+	Reason   string  `json:"reason" validate:"required"`
+	Operator string  `json:"operator"`
+}
+
+// 司法冻结的部分解除记录
+type HoldRelease struct {
+	Amount   float64 `json:"amount"`
+	Reason   string  `json:"reason"`
+	Operator string  `json:"operator"`
+	At       string  `json:"at"`
+}
+
+// 冻结（止付）记录
+type Hold struct {
+	HoldID      string        `json:"holdId"`
+	AccountID   string        `json:"accountId"`
+	Type        string        `json:"type"`   // standard/legal
+	Amount      float64       `json:"amount"` // 当前冻结金额（司法冻结部分解除后减少）
+	Reason      string        `json:"reason"`
+	Status      string        `json:"status"` // active/released/expired/captured（已核销，如卡预授权转为消费扣款）
+	ExpiresAt   string        `json:"expiresAt,omitempty"`
+	CreatedAt   string        `json:"createdAt"`
+	UpdatedAt   string        `json:"updatedAt"`
+	ReleasedAt  string        `json:"releasedAt,omitempty"`
+	OrderRef    string        `json:"orderRef,omitempty"`    // 司法冻结：法律文书编号
+	Authority   string        `json:"authority,omitempty"`   // 司法冻结：执行机关
+	FullBalance bool          `json:"fullBalance,omitempty"` // 司法冻结：设置时冻结全部可用余额
+	Original    float64       `json:"original,omitempty"`    // 司法冻结：初始冻结金额
+	Releases    []HoldRelease `json:"releases,omitempty"`    // 司法冻结：解除记录
+
+	expiresAt time.Time
+}
+
+// 账户冻结汇总
+type HoldSummary struct {
+	AccountID        string  `json:"accountId"`
+	Currency         string  `json:"currency"`
+	BookedBalance    float64 `json:"bookedBalance"`
+	HeldAmount       float64 `json:"heldAmount"`
+	AvailableBalance float64 `json:"availableBalance"`
+	Holds            []Hold  `json:"holds"` // 按时间倒序
+}
+
+// 冻结服务：对账户资金设置、修改、解除冻结，维护账面余额与可用余额的差额，到期自动解除
+type HoldService struct {
+	accounts  *repository.AccountRepository
+	audit     *AuditService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	holds map[string]*Hold
+	seq   int64
+	mu    sync.Mutex // 需先于账户锁获取
+}
+
+func NewHoldService(accounts *repository.AccountRepository, audit *AuditService, clock Clock, notifier Notifier, templates *TemplateRegistry) *HoldService {
+	return &HoldService{
+		accounts:  accounts,
+		audit:     audit,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		holds:     make(map[string]*Hold),
+	}
+}
+
+// 设置冻结：冻结金额不得超过可用余额
+func (s *HoldService) Place(req HoldRequest) (Hold, error) {
+	if err := model.Validate(req); err != nil {
+		return Hold{}, err
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+	now := s.clock.Now()
+	expiresAt, err := parseHoldExpiry(req.ExpiresAt, now)
+	if err != nil {
+		return Hold{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	account, exists := s.accounts.Find(req.AccountID)
+	if !exists {
+		return Hold{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if account.Status != "normal" {
+		return Hold{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "账户状态异常，无法冻结资金")
+	}
+	if account.Available() < req.Amount {
+		return Hold{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH,
+			fmt.Sprintf("可用余额不足，无法冻结（可用余额 %.2f）", account.Available()))
+	}
+
+	s.seq++
+	hold := &Hold{
+		HoldID:    fmt.Sprintf("HD%s%06d", now.Format("20060102"), s.seq),
+		AccountID: req.AccountID,
+		Type:      HOLD_TYPE_STANDARD,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+		Status:    "active",
+		CreatedAt: now.Format("2006-01-02 15:04:05"),
+		UpdatedAt: now.Format("2006-01-02 15:04:05"),
+		expiresAt: expiresAt,
+	}
+	if !expiresAt.IsZero() {
+		hold.ExpiresAt = expiresAt.Format("2006-01-02 15:04:05")
+	}
+	s.holds[hold.HoldID] = hold
+
+	account.Held = model.RoundAmount(account.Held + hold.Amount)
+	account = s.accounts.Save(account)
+	s.notify(account, EVENT_HOLD_PLACED, *hold)
+	logHold("🔐 资金冻结", account, *hold)
+	return *hold, nil
+}
+
+// 修改冻结的金额、原因或到期时间（增加的金额不得超过可用余额）
+func (s *HoldService) Modify(holdID string, req HoldUpdateRequest) (Hold, error) {
+	if err := model.Validate(req); err != nil {
+		return Hold{}, err
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, ok := s.holds[holdID]
+	if !ok {
+		return Hold{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "冻结记录不存在")
+	}
+	if hold.Status != "active" {
+		return Hold{}, model.NewError(model.CODE_PARAM_ERROR, "冻结已解除，无法修改")
+	}
+	if hold.Type == HOLD_TYPE_LEGAL {
+		return Hold{}, model.NewError(model.CODE_NO_PERMISSION, "司法冻结不可修改，请通过管理接口部分解除")
+	}
+
+	amount := hold.Amount
+	if req.Amount != nil {
+		amount = model.RoundAmount(*req.Amount)
+	}
+	expiresAt := hold.expiresAt
+	if req.ExpiresAt != nil {
+		var err error
+		if expiresAt, err = parseHoldExpiry(*req.ExpiresAt, now); err != nil {
+			return Hold{}, err
+		}
+	}
+
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	account, exists := s.accounts.Find(hold.AccountID)
+	if !exists {
+		return Hold{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if delta := model.RoundAmount(amount - hold.Amount); delta > 0 && account.Available() < delta {
+		return Hold{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH,
+			fmt.Sprintf("可用余额不足，无法追加冻结（可用余额 %.2f）", account.Available()))
+	}
+
+	account.Held = model.RoundAmount(account.Held - hold.Amount + amount)
+	hold.Amount = amount
+	if req.Reason != nil {
+		hold.Reason = *req.Reason
+	}
+	hold.expiresAt = expiresAt
+	hold.ExpiresAt = ""
+	if !expiresAt.IsZero() {
+		hold.ExpiresAt = expiresAt.Format("2006-01-02 15:04:05")
+	}
+	hold.UpdatedAt = now.Format("2006-01-02 15:04:05")
+	account = s.accounts.Save(account)
+	logHold("🔐 冻结修改", account, *hold)
+	return *hold, nil
+}
+
+// 解除冻结
+func (s *HoldService) Release(holdID string) (Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, ok := s.holds[holdID]
+	if !ok {
+		return Hold{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "冻结记录不存在")
+	}
+	if hold.Status != "active" {
+		return Hold{}, model.NewError(model.CODE_PARAM_ERROR, "冻结已解除")
+	}
+	if hold.Type == HOLD_TYPE_LEGAL {
+		return Hold{}, model.NewError(model.CODE_NO_PERMISSION, "司法冻结仅可由管理员解除")
+	}
+	s.release(hold, "released", s.clock.Now())
+	return *hold, nil
+}
+
+// 核销冻结（如卡预授权后的消费）：在同一账户锁内解除冻结并执行 debit 扣款，扣款前的冻结金额已归还可用余额，
+// 期间其他交易无法动用该笔资金；debit 返回错误时不保存账户，冻结保持不变，成功时按扣款后的账户输出核销日志
+func (s *HoldService) Capture(holdID string, debit func(account model.Account) (model.Account, error)) error {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, ok := s.holds[holdID]
+	if !ok {
+		return model.NewError(model.CODE_RESOURCE_NOT_FOUND, "冻结记录不存在")
+	}
+	if hold.Status != "active" {
+		return model.NewError(model.CODE_PARAM_ERROR, "冻结已解除")
+	}
+
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	account, exists := s.accounts.Find(hold.AccountID)
+	if !exists {
+		return model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	account.Held = model.RoundAmount(account.Held - hold.Amount)
+	if account.Held < 0 {
+		account.Held = 0
+	}
+	account, err := debit(account)
+	if err != nil {
+		return err
+	}
+	hold.Status = "captured"
+	hold.ReleasedAt = now.Format("2006-01-02 15:04:05")
+	hold.UpdatedAt = hold.ReleasedAt
+	logHold("💳 冻结核销", account, *hold)
+	return nil
+}
+
+// 设置司法冻结（管理员）：冻结指定金额或全部可用余额，不自动到期，记录审计日志
+func (s *HoldService) PlaceLegal(req LegalHoldRequest) (Hold, error) {
+	if err := model.Validate(req); err != nil {
+		return Hold{}, err
+	}
+	if !req.FullBalance && req.Amount == 0 {
+		return Hold{}, model.NewError(model.CODE_PARAM_ERROR, "请指定冻结金额或冻结全部余额")
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+	if req.Operator == "" {
+		req.Operator = AUDIT_ACTOR_ADMIN
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	account, exists := s.accounts.Find(req.AccountID)
+	if !exists {
+		return Hold{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	amount := req.Amount
+	if req.FullBalance {
+		amount = model.RoundAmount(account.Available())
+		if amount <= 0 {
+			return Hold{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "账户无可用余额可冻结")
+		}
+	} else if account.Available() < amount {
+		return Hold{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH,
+			fmt.Sprintf("可用余额不足，无法冻结（可用余额 %.2f），可改为冻结全部余额", account.Available()))
+	}
+
+	s.seq++
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = "司法冻结（" + req.Authority + " " + req.OrderRef + "）"
+	}
+	hold := &Hold{
+		HoldID:      fmt.Sprintf("HD%s%06d", now.Format("20060102"), s.seq),
+		AccountID:   req.AccountID,
+		Type:        HOLD_TYPE_LEGAL,
+		Amount:      amount,
+		Reason:      reason,
+		Status:      "active",
+		CreatedAt:   now.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   now.Format("2006-01-02 15:04:05"),
+		OrderRef:    req.OrderRef,
+		Authority:   req.Authority,
+		FullBalance: req.FullBalance,
+		Original:    amount,
+	}
+	s.holds[hold.HoldID] = hold
+
+	account.Held = model.RoundAmount(account.Held + hold.Amount)
+	account = s.accounts.Save(account)
+	s.audit.Record(req.Operator, "legal_hold.placed", account.AccountID, map[string]string{
+		"holdId":      hold.HoldID,
+		"amount":      fmt.Sprintf("%.2f", hold.Amount),
+		"currency":    account.Currency,
+		"fullBalance": fmt.Sprintf("%t", hold.FullBalance),
+		"orderRef":    hold.OrderRef,
+		"authority":   hold.Authority,
+		"reason":      hold.Reason,
+	})
+	s.notify(account, EVENT_HOLD_PLACED, *hold)
+	logHold("⚖️ 司法冻结", account, *hold)
+	return *hold, nil
+}
+
+// 解除司法冻结（管理员）：amount 小于冻结金额时部分解除，为 0 或等于冻结金额时全部解除，记录审计日志
+func (s *HoldService) ReleaseLegal(holdID string, req LegalHoldReleaseRequest) (Hold, error) {
+	if err := model.Validate(req); err != nil {
+		return Hold{}, err
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+	if req.Operator == "" {
+		req.Operator = AUDIT_ACTOR_ADMIN
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, ok := s.holds[holdID]
+	if !ok || hold.Type != HOLD_TYPE_LEGAL {
+		return Hold{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "司法冻结记录不存在")
+	}
+	if hold.Status != "active" {
+		return Hold{}, model.NewError(model.CODE_PARAM_ERROR, "司法冻结已解除")
+	}
+	if req.Amount > hold.Amount {
+		return Hold{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("解除金额超过冻结金额 %.2f", hold.Amount))
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	hold.Releases = append(hold.Releases, HoldRelease{
+		Amount:   amount,
+		Reason:   req.Reason,
+		Operator: req.Operator,
+		At:       now.Format("2006-01-02 15:04:05"),
+	})
+
+	action := "legal_hold.released"
+	if amount == hold.Amount {
+		s.release(hold, "released", now)
+	} else {
+		action = "legal_hold.partially_released"
+		s.accounts.Lock()
+		account, exists := s.accounts.Find(hold.AccountID)
+		if exists {
+			account.Held = model.RoundAmount(account.Held - amount)
+			if account.Held < 0 {
+				account.Held = 0
+			}
+			account = s.accounts.Save(account)
+		}
+		hold.Amount = model.RoundAmount(hold.Amount - amount)
+		hold.UpdatedAt = now.Format("2006-01-02 15:04:05")
+		if exists {
+			s.notify(account, EVENT_HOLD_RELEASED, Hold{HoldID: hold.HoldID, Amount: amount, Reason: req.Reason})
+			logHold("⚖️ 司法冻结部分解除", account, *hold)
+		}
+		s.accounts.Unlock()
+	}
+	remaining := hold.Amount
+	if hold.Status != "active" {
+		remaining = 0
+	}
+	s.audit.Record(req.Operator, action, hold.AccountID, map[string]string{
+		"holdId":    hold.HoldID,
+		"amount":    fmt.Sprintf("%.2f", amount),
+		"remaining": fmt.Sprintf("%.2f", remaining),
+		"orderRef":  hold.OrderRef,
+		"reason":    req.Reason,
+	})
+	return *hold, nil
+}
+
+// 查询司法冻结（管理员），accountId、status 为空表示不限，按时间倒序
+func (s *HoldService) ListLegal(accountID, status string) []Hold {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	holds := make([]Hold, 0)
+	for _, hold := range s.holds {
+		if hold.Type != HOLD_TYPE_LEGAL {
+			continue
+		}
+		if (accountID == "" || hold.AccountID == accountID) && (status == "" || hold.Status == status) {
+			holds = append(holds, *hold)
+		}
+	}
+	sort.Slice(holds, func(i, j int) bool { return holds[i].HoldID > holds[j].HoldID })
+	return holds
+}
+
+// 查询冻结记录
+func (s *HoldService) Get(holdID string) (Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, ok := s.holds[holdID]
+	if !ok {
+		return Hold{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "冻结记录不存在")
+	}
+	return *hold, nil
+}
+
+// 查询账户的冻结汇总及冻结记录，status 为空表示不限
+func (s *HoldService) List(accountID, status string) (HoldSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return HoldSummary{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	summary := HoldSummary{
+		AccountID:        account.AccountID,
+		Currency:         account.Currency,
+		BookedBalance:    account.Balance,
+		HeldAmount:       account.Held,
+		AvailableBalance: account.Available(),
+		Holds:            make([]Hold, 0),
+	}
+	for _, hold := range s.holds {
+		if hold.AccountID == accountID && (status == "" || hold.Status == status) {
+			summary.Holds = append(summary.Holds, *hold)
+		}
+	}
+	sort.Slice(summary.Holds, func(i, j int) bool { return summary.Holds[i].HoldID > summary.Holds[j].HoldID })
+	return summary, nil
+}
+
+// 冻结记录快照（随状态快照导出，与账户冻结金额一致）
+type HoldSnapshot struct {
+	Holds []Hold `json:"holds"`
+	Seq   int64  `json:"seq"`
+}
+
+// 导出冻结记录（调用方需持有 s.mu，并在其后获取账户锁，使冻结记录与账户冻结金额取自同一时刻）
+func (s *HoldService) snapshot() HoldSnapshot {
+	snapshot := HoldSnapshot{Holds: make([]Hold, 0, len(s.holds)), Seq: s.seq}
+	for _, hold := range s.holds {
+		item := *hold
+		item.Releases = append([]HoldRelease(nil), hold.Releases...)
+		snapshot.Holds = append(snapshot.Holds, item)
+	}
+	sort.Slice(snapshot.Holds, func(i, j int) bool { return snapshot.Holds[i].HoldID < snapshot.Holds[j].HoldID })
+	return snapshot
+}
+
+// 以快照替换冻结记录（调用方需持有 s.mu）
+func (s *HoldService) restore(snapshot HoldSnapshot) {
+	holds := make(map[string]*Hold, len(snapshot.Holds))
+	for _, hold := range snapshot.Holds {
+		hold := hold
+		hold.expiresAt = time.Time{}
+		if hold.ExpiresAt != "" {
+			hold.expiresAt, _ = time.ParseInLocation("2006-01-02 15:04:05", hold.ExpiresAt, time.Local)
+		}
+		holds[hold.HoldID] = &hold
+	}
+	s.holds, s.seq = holds, snapshot.Seq
+}
+
+// 到期冻结自动解除（每秒按模拟时钟检查，active 返回 false 时跳过）
+func (s *HoldService) Run(active func() bool) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if active() {
+			s.ExpireHolds(s.clock.Now())
+		}
+	}
+}
+
+// 解除已到期的冻结
+func (s *HoldService) ExpireHolds(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, hold := range s.holds {
+		if hold.Status == "active" && !hold.expiresAt.IsZero() && !now.Before(hold.expiresAt) {
+			s.release(hold, "expired", now)
+		}
+	}
+}
+
+// 解除冻结并恢复可用余额（调用方需持有 s.mu）
+func (s *HoldService) release(hold *Hold, status string, now time.Time) {
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	hold.Status = status
+	hold.ReleasedAt = now.Format("2006-01-02 15:04:05")
+	hold.UpdatedAt = hold.ReleasedAt
+
+	account, exists := s.accounts.Find(hold.AccountID)
+	if !exists {
+		return
+	}
+	account.Held = model.RoundAmount(account.Held - hold.Amount)
+	if account.Held < 0 {
+		account.Held = 0
+	}
+	account = s.accounts.Save(account)
+	s.notify(account, EVENT_HOLD_RELEASED, *hold)
+
+	title := "🔓 解除冻结"
+	if status == "expired" {
+		title = "🔓 冻结到期解除"
+	}
+	logHold(title, account, *hold)
+}
+
+// 推送冻结变动：可用余额与交易提醒
+func (s *HoldService) notify(account model.Account, event string, hold Hold) {
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+		Data:       map[string]interface{}{"availableBalance": account.Available(), "heldAmount": account.Held},
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, event, map[string]interface{}{
+		"Amount":    hold.Amount,
+		"Reason":    hold.Reason,
+		"Available": account.Available(),
+		"HoldID":    hold.HoldID,
+	}))
+}
+
+// 解析冻结到期时间（为空表示长期有效，须晚于当前模拟时间）
+func parseHoldExpiry(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	expiresAt, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	if err != nil {
+		return time.Time{}, model.NewError(model.CODE_PARAM_ERROR, "到期时间格式错误，应为 YYYY-MM-DD HH:MM:SS")
+	}
+	if !expiresAt.After(now) {
+		return time.Time{}, model.NewError(model.CODE_PARAM_ERROR, "到期时间须晚于当前时间")
+	}
+	return expiresAt, nil
+}
+
+// 终端提示：冻结变动
+func logHold(title string, account model.Account, hold Hold) {
+	log.Printf("\n[%s]", title)
+	log.Printf("冻结编号: %s", hold.HoldID)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("冻结金额: %.2f %s", hold.Amount, account.Currency)
+	log.Printf("原因: %s", hold.Reason)
+	if hold.ExpiresAt != "" {
+		log.Printf("到期时间: %s", hold.ExpiresAt)
+	}
+	log.Printf("账面余额: %.2f，冻结合计: %.2f，可用余额: \033[1;36m%.2f\033[0m", account.Balance, account.Held, account.Available())
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
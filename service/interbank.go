@@ -0,0 +1,573 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 跨行转账请求结构体
+type InterbankTransferRequest struct {
+	FromAccount string  `json:"fromAccount" validate:"required,account"`
+	ToBank      string  `json:"toBank" validate:"required"` // 收款行 BIC
+	ToAccount   string  `json:"toAccount" validate:"required,account"`
+	ToName      string  `json:"toName" validate:"required,max=60"`
+	Amount      float64 `json:"amount" validate:"amount"`
+	QuoteID     string  `json:"quoteId"` // 转账报价 ID（可选），引用后按报价锁定的手续费执行
+
+	model.Remittance // 附言、端到端参考号、用途代码（可选）
+}
+
+// 跨行支付指令（清算系统中的一笔支付）
+type InterbankPayment struct {
+	Reference        string  `json:"reference"`
+	FromAccount      string  `json:"fromAccount"`
+	ToBank           string  `json:"toBank"`
+	ToBankName       string  `json:"toBankName"`
+	ToAccount        string  `json:"toAccount"`
+	ToName           string  `json:"toName"`
+	Amount           float64 `json:"amount"`
+	Fee              float64 `json:"fee,omitempty"`        // 跨行手续费（拒绝/退汇时不退还）
+	Status           string  `json:"status"`               // pending/queued/netting/settled/rejected/returned
+	ReasonCode       string  `json:"reasonCode,omitempty"` // 拒绝/退汇原因码（ISO 20022）
+	Reason           string  `json:"reason,omitempty"`
+	CreatedAt        string  `json:"createdAt"`
+	SettledAt        string  `json:"settledAt,omitempty"`
+	ExpectedSettleAt string  `json:"expectedSettleAt,omitempty"` // 预计清算时间（非营业日顺延）
+	QueuedAt         string  `json:"queuedAt,omitempty"`         // 因流动性不足开始排队的时间
+	CycleID          string  `json:"cycleId,omitempty"`          // 低额支付所在的清算所轧差场次
+	ReturnedAt       string  `json:"returnedAt,omitempty"`
+
+	model.Remittance
+
+	settleAt  time.Time // 预计清算时间
+	returnAt  time.Time // 预计退汇时间（零值表示不退汇）
+	rtgsEntry string    // 已提交央行 RTGS 但因头寸不足排队的指令编号
+}
+
+// 他行信息
+type ExternalBank struct {
+	BIC  string `json:"bic"`
+	Name string `json:"name"`
+}
+
+// 清算拒绝/退汇原因
+type clearingReason struct {
+	Code string
+	Text string
+}
+
+// 清算系统配置
+type ClearingConfig struct {
+	Delay      time.Duration // 清算延迟
+	RejectRate float64       // 收款行拒绝概率
+	ReturnRate float64       // 清算后退汇概率
+
+	NetworkFailureRate float64 // 提交清算网络失败概率（用于测试熔断与降级）
+}
+
+var (
+	// 模拟他行列表（BIC → 行名）
+	externalBanks = map[string]string{
+		"ICBKCNBJ": "中国工商银行",
+		"ABOCCNBJ": "中国农业银行",
+		"PCBCCNBJ": "中国建设银行",
+		"BKCHCNBJ": "中国银行",
+	}
+
+	clearingReasons = []clearingReason{
+		{Code: "AC01", Text: "收款账号错误"},
+		{Code: "AC04", Text: "收款账户已销户"},
+		{Code: "AC06", Text: "收款账户已冻结"},
+		{Code: "AM05", Text: "重复支付"},
+	}
+)
+
+// 跨行转账服务：受理跨行支付并模拟清算系统的清算、拒绝与退汇
+type InterbankService struct {
+	cfg       ClearingConfig
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	pricing   *PricingService
+	credit    *CreditService
+	notifier  Notifier
+	mailer    Mailer
+	templates *TemplateRegistry
+	clock     Clock
+	calendar  *CalendarService
+	treasury  *TreasuryService
+	rtgs      *RTGSService
+	house     *ClearingHouseService
+	network   *CircuitBreaker
+
+	payments map[string]*InterbankPayment
+	seq      int
+	mu       sync.Mutex // 需先于账户锁获取
+}
+
+func NewInterbankService(cfg ClearingConfig, accounts *repository.AccountRepository, ledger *LedgerService, pricing *PricingService, credit *CreditService, notifier Notifier, mailer Mailer, templates *TemplateRegistry, clock Clock, calendar *CalendarService, treasury *TreasuryService, rtgs *RTGSService, house *ClearingHouseService, network *CircuitBreaker) *InterbankService {
+	return &InterbankService{
+		cfg:       cfg,
+		accounts:  accounts,
+		ledger:    ledger,
+		pricing:   pricing,
+		credit:    credit,
+		notifier:  notifier,
+		mailer:    mailer,
+		templates: templates,
+		clock:     clock,
+		calendar:  calendar,
+		treasury:  treasury,
+		rtgs:      rtgs,
+		house:     house,
+		network:   network,
+		payments:  make(map[string]*InterbankPayment),
+	}
+}
+
+// 可汇入的他行列表
+func (s *InterbankService) Banks() []ExternalBank {
+	result := make([]ExternalBank, 0, len(externalBanks))
+	for bic, name := range externalBanks {
+		result = append(result, ExternalBank{BIC: bic, Name: name})
+	}
+	return result
+}
+
+// 执行跨行转账（立即扣款，进入清算队列），接口与批量支付共用
+func (s *InterbankService) Transfer(req InterbankTransferRequest) (InterbankPayment, error) {
+	if err := model.Validate(req); err != nil {
+		return InterbankPayment{}, err
+	}
+
+	bankName, ok := externalBanks[req.ToBank]
+	if !ok {
+		return InterbankPayment{}, model.NewError(model.CODE_TARGET_ACCOUNT_ABNORMAL, "不支持的收款行")
+	}
+
+	if err := validateRemittance(&req.Remittance); err != nil {
+		return InterbankPayment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	fromAccount, exists := s.accounts.Find(req.FromAccount)
+	if !exists {
+		return InterbankPayment{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "转出账户不存在")
+	}
+
+	if err := dormantError(fromAccount); err != nil {
+		return InterbankPayment{}, err
+	}
+	if fromAccount.Status != "normal" {
+		return InterbankPayment{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "转出账户已冻结，无法转账")
+	}
+
+	// 模拟清算系统为境内人民币清算
+	if fromAccount.Currency != model.BASE_CURRENCY {
+		return InterbankPayment{}, model.NewError(model.CODE_PARAM_ERROR, "跨行转账仅支持人民币账户")
+	}
+
+	// 确定跨行手续费：引用报价时按报价锁定
+	pricing, err := s.pricing.Resolve(req.QuoteID, QuoteRequest{
+		FromAccount: req.FromAccount,
+		ToAccount:   req.ToAccount,
+		ToBank:      req.ToBank,
+		Amount:      req.Amount,
+	}, fromAccount.Currency, model.BASE_CURRENCY)
+	if err != nil {
+		return InterbankPayment{}, err
+	}
+	if err := s.pricing.ApplyProductRules(&pricing, fromAccount); err != nil {
+		return InterbankPayment{}, err
+	}
+
+	if fromAccount.Available() < pricing.TotalDebit {
+		s.credit.RecordNSF(req.FromAccount)
+		return InterbankPayment{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "余额不足，无法完成转账")
+	}
+
+	// 立即扣款（含手续费）
+	oldBalance := fromAccount.Balance
+	fromAccount.Balance = model.RoundAmount(fromAccount.Balance - pricing.TotalDebit)
+	fromAccount = s.accounts.Save(fromAccount)
+
+	// 生成支付指令并加入清算队列（遇非营业日顺延至下一营业日清算）
+	now := s.clock.Now()
+	s.seq++
+	payment := &InterbankPayment{
+		Reference:   fmt.Sprintf("IB%s%06d", now.Format("20060102"), s.seq),
+		FromAccount: req.FromAccount,
+		ToBank:      req.ToBank,
+		ToBankName:  bankName,
+		ToAccount:   req.ToAccount,
+		ToName:      req.ToName,
+		Amount:      req.Amount,
+		Fee:         pricing.TotalFee,
+		Status:      "pending",
+		CreatedAt:   now.Format("2006-01-02 15:04:05"),
+		Remittance:  req.Remittance,
+		settleAt:    s.calendar.Roll(now.Add(s.cfg.Delay)),
+	}
+	payment.ExpectedSettleAt = payment.settleAt.Format("2006-01-02 15:04:05")
+	s.payments[payment.Reference] = payment
+	s.ledger.Record(model.Transaction{
+		AccountID:    req.FromAccount,
+		Type:         "interbank_out",
+		Direction:    "debit",
+		Amount:       req.Amount,
+		BalanceAfter: fromAccount.Balance + pricing.TotalFee,
+		Counterparty: req.ToAccount,
+		Reference:    payment.Reference,
+		Description:  "跨行转账至 " + bankName + " " + req.ToName,
+		Remittance:   req.Remittance,
+	})
+	recordFees(s.ledger, pricing, fromAccount, payment.Reference)
+	s.pricing.Consume(req.QuoteID)
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  fromAccount.AccountID,
+		NewBalance: fromAccount.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", fromAccount, EVENT_INTERBANK_ACCEPTED, map[string]interface{}{
+		"Amount":       req.Amount,
+		"Balance":      fromAccount.Balance,
+		"Reference":    payment.Reference,
+		"Counterparty": bankName + " " + req.ToName,
+		"Fee":          pricing.TotalFee,
+		"Memo":         req.Memo,
+		"EndToEndID":   req.EndToEndID,
+		"PurposeCode":  req.PurposeCode,
+	}))
+
+	// 大额转账邮件提醒
+	if req.Amount >= s.mailer.LargeTransferThreshold() {
+		s.mailer.Notify(fromAccount, EVENT_LARGE_TRANSFER, map[string]interface{}{
+			"Amount":       req.Amount,
+			"Balance":      fromAccount.Balance,
+			"Counterparty": bankName + " " + req.ToName,
+			"Time":         payment.CreatedAt,
+		})
+	}
+
+	// 终端提示：跨行转账受理
+	log.Println("\n[🌐 跨行转账 - 已受理]")
+	log.Printf("受理时间: %s", payment.CreatedAt)
+	log.Printf("流水号: %s", payment.Reference)
+	log.Printf("转出账户ID: %s", req.FromAccount)
+	log.Printf("收款行: %s (%s)", bankName, req.ToBank)
+	log.Printf("收款账户: %s (%s)", req.ToAccount, req.ToName)
+	log.Printf("转账金额: \033[1;31m%.2f 元\033[0m", req.Amount) // 红色高亮
+	if pricing.TotalFee != 0 {
+		log.Printf("手续费: %.2f 元", pricing.TotalFee)
+	}
+	if req.QuoteID != "" {
+		log.Printf("引用报价: %s", req.QuoteID)
+	}
+	log.Printf("转出账户 - 操作前: %.2f 元 → 操作后: \033[1;36m%.2f 元\033[0m", oldBalance, fromAccount.Balance)
+	log.Printf("预计清算时间: %s", payment.ExpectedSettleAt)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *payment, nil
+}
+
+// 按流水号查询跨行支付
+func (s *InterbankService) Payment(reference string) (InterbankPayment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payment, ok := s.payments[reference]
+	if !ok {
+		return InterbankPayment{}, false
+	}
+	return *payment, true
+}
+
+// -------------------------- 模拟清算系统 --------------------------
+
+// 清算系统主循环：按模拟时钟定时处理到期的支付指令（active 返回 false 时跳过）
+func (s *InterbankService) Run(active func() bool) {
+	log.Printf("模拟清算系统已启动，清算延迟: %s，拒绝率: %.2f，退汇率: %.2f",
+		s.cfg.Delay, s.cfg.RejectRate, s.cfg.ReturnRate)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if active() {
+			s.ProcessClearing(s.clock.Now())
+		}
+	}
+}
+
+// 处理到期的清算与退汇。到期支付按受理顺序处理，大额支付流动性不足时排队，
+// 此后的大额支付不得越过排队中的支付（先进先出），每次处理时按顺序重试；
+// 通过流动性检查的支付提交央行 RTGS 结算，本行头寸不足时在 RTGS 排队；低额支付提交清算所按场次轧差结算
+func (s *InterbankService) ProcessClearing(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, item := range s.house.ProcessCycles(now) {
+		if payment, ok := s.payments[item.Reference]; ok && payment.Status == "netting" {
+			s.settled(payment, now)
+		}
+	}
+
+	for _, entry := range s.rtgs.ProcessQueue() {
+		if payment, ok := s.payments[entry.Reference]; ok && payment.rtgsEntry == entry.EntryID {
+			s.settled(payment, now)
+		}
+	}
+
+	payments := make([]*InterbankPayment, 0, len(s.payments))
+	for _, payment := range s.payments {
+		payments = append(payments, payment)
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].Reference < payments[j].Reference })
+
+	blocked := false // 已有大额支付因流动性不足未能清算
+	for _, payment := range payments {
+		switch {
+		case payment.Status == "pending" && !now.Before(payment.settleAt):
+			if !s.submit(payment) {
+				continue
+			}
+			if rand.Float64() < s.cfg.RejectRate {
+				reason := clearingReasons[rand.Intn(len(clearingReasons))]
+				payment.Status = "rejected"
+				payment.ReasonCode = reason.Code
+				payment.Reason = reason.Text
+				s.refund(payment, EVENT_INTERBANK_REJECTED, "跨行转账被收款行拒绝")
+				continue
+			}
+			s.settle(payment, now, &blocked)
+
+		case payment.Status == "queued" && payment.rtgsEntry == "":
+			s.settle(payment, now, &blocked)
+
+		case payment.Status == "settled" && !payment.returnAt.IsZero() && !now.Before(payment.returnAt):
+			reason := clearingReasons[rand.Intn(len(clearingReasons))]
+			payment.Status = "returned"
+			payment.ReasonCode = reason.Code
+			payment.Reason = reason.Text
+			payment.ReturnedAt = now.Format("2006-01-02 15:04:05")
+			// 退汇资金经央行 RTGS 由收款行退回（不受其日间透支限额约束），经央行备付金退回清算往来，再退回客户账户
+			s.rtgs.Settle(RTGSInstruction{Reference: payment.Reference, Debtor: payment.ToBank, Creditor: model.BANK_BIC, Amount: payment.Amount}, true)
+			s.ledger.PostInternal(GL_CENTRAL_BANK, GL_CLEARING, payment.Amount, model.BASE_CURRENCY, payment.Reference)
+			s.refund(payment, EVENT_INTERBANK_RETURNED, "跨行转账被退汇")
+		}
+	}
+}
+
+// 将到期支付提交跨行清算网络：网络熔断或本次提交失败时支付留在待清算，下一轮重试（调用方需持有 s.mu）
+func (s *InterbankService) submit(payment *InterbankPayment) bool {
+	if err := s.network.Allow(); err != nil {
+		return false
+	}
+	var err error
+	if rand.Float64() < s.cfg.NetworkFailureRate {
+		err = fmt.Errorf("清算网络报文超时")
+	}
+	s.network.Record(err)
+	if err != nil {
+		log.Printf("[🌐 跨行清算] 流水号: %s | 提交清算网络失败: %v，稍后重试", payment.Reference, err)
+		return false
+	}
+	return true
+}
+
+// 清算网络是否熔断中（熔断期间仍受理跨行转账，恢复后自动提交）
+func (s *InterbankService) NetworkDown() bool {
+	return s.network.Open()
+}
+
+// 清算一笔支付：大额支付须通过流动性检查，否则进入（或留在）排队；通过后低额支付提交清算所、
+// 其余提交央行 RTGS（调用方需持有 s.mu）
+func (s *InterbankService) settle(payment *InterbankPayment, now time.Time, blocked *bool) {
+	if payment.Amount >= s.treasury.Config().QueueThreshold {
+		admitted := false
+		var position LiquidityPosition
+		if !*blocked {
+			admitted, position = s.treasury.Admit(payment.Amount)
+		}
+		if !admitted {
+			*blocked = true
+			if payment.Status != "queued" {
+				payment.Status = "queued"
+				payment.QueuedAt = now.Format("2006-01-02 15:04:05")
+
+				// 终端提示：流动性不足，大额清算排队
+				log.Println("\n[🏦 大额清算排队]")
+				log.Printf("流水号: %s", payment.Reference)
+				log.Printf("清算金额: %.2f 元", payment.Amount)
+				if position.Currency != "" {
+					log.Printf("流动性资产: %.2f 元，应缴准备金: %.2f 元（准备金率 %.2f%%）",
+						position.LiquidAssets, position.RequiredReserve, position.ReserveRatio*100)
+				} else {
+					log.Printf("前序大额清算排队中，按先进先出顺序等待")
+				}
+				log.Println("-" + strings.Repeat("-", 50) + "-")
+			}
+			return
+		}
+	}
+
+	// 低额支付提交清算所，场次截止后以净额经 RTGS 结算
+	if s.house.Eligible(payment.Amount) {
+		payment.Status = "netting"
+		payment.CycleID = s.house.Submit(ClearingItem{
+			Reference: payment.Reference,
+			FromBank:  model.BANK_BIC,
+			ToBank:    payment.ToBank,
+			Amount:    payment.Amount,
+		})
+		return
+	}
+
+	// 提交央行 RTGS：结算时清算往来转出至央行备付金，本行头寸不足时排队等待
+	entry := s.rtgs.Submit(RTGSInstruction{
+		Reference: payment.Reference,
+		Debtor:    model.BANK_BIC,
+		Creditor:  payment.ToBank,
+		Amount:    payment.Amount,
+		Contra:    GL_CLEARING,
+	})
+	if entry.Status != RTGS_SETTLED {
+		payment.rtgsEntry = entry.EntryID
+		if payment.Status != "queued" {
+			payment.Status = "queued"
+			payment.QueuedAt = now.Format("2006-01-02 15:04:05")
+		}
+		return
+	}
+	s.settled(payment, now)
+}
+
+// 央行 RTGS 逐笔或清算所净额结算完成（调用方需持有 s.mu）
+func (s *InterbankService) settled(payment *InterbankPayment, now time.Time) {
+	payment.Status = "settled"
+	payment.SettledAt = now.Format("2006-01-02 15:04:05")
+	payment.rtgsEntry = ""
+	if rand.Float64() < s.cfg.ReturnRate {
+		payment.returnAt = s.calendar.Roll(now.Add(s.cfg.Delay))
+	}
+	if account, exists := s.accounts.Get(payment.FromAccount); exists {
+		s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_INTERBANK_SETTLED, map[string]interface{}{
+			"Amount":    payment.Amount,
+			"BankName":  payment.ToBankName,
+			"Reference": payment.Reference,
+		}))
+	}
+	log.Printf("[🌐 跨行清算] 流水号: %s | 状态: \033[1;32m已清算\033[0m", payment.Reference)
+}
+
+// 因流动性不足排队中的清算（含本行准备金约束与央行 RTGS 头寸不足）
+func (s *InterbankService) Queued() []InterbankPayment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]InterbankPayment, 0)
+	for _, payment := range s.payments {
+		if payment.Status == "queued" {
+			result = append(result, *payment)
+		}
+	}
+	return result
+}
+
+// 拒绝/退汇时将款项退回转出账户（调用方需持有 s.mu）
+func (s *InterbankService) refund(payment *InterbankPayment, event, title string) {
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	account, exists := s.accounts.Find(payment.FromAccount)
+	if !exists {
+		log.Printf("跨行退款失败，转出账户不存在（流水号: %s）", payment.Reference)
+		return
+	}
+	account.Balance = model.RoundAmount(account.Balance + payment.Amount)
+	account = s.accounts.Save(account)
+	s.ledger.Record(model.Transaction{
+		AccountID:    payment.FromAccount,
+		Type:         "interbank_refund",
+		Direction:    "credit",
+		Amount:       payment.Amount,
+		BalanceAfter: account.Balance,
+		Counterparty: payment.ToAccount,
+		Reference:    payment.Reference,
+		Description:  title + "：" + payment.Reason,
+		Remittance:   model.Remittance{EndToEndID: payment.EndToEndID, PurposeCode: payment.PurposeCode},
+	})
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, event, map[string]interface{}{
+		"Amount":    payment.Amount,
+		"Balance":   account.Balance,
+		"Reason":    payment.Reason,
+		"Reference": payment.Reference,
+	}))
+
+	// 终端提示：跨行退款
+	log.Printf("\n[🌐 %s]", title)
+	log.Printf("流水号: %s", payment.Reference)
+	log.Printf("转出账户ID: %s", payment.FromAccount)
+	log.Printf("原因: %s %s", payment.ReasonCode, payment.Reason)
+	log.Printf("退回金额: \033[1;32m%.2f 元\033[0m，当前余额: %.2f 元", payment.Amount, account.Balance)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 跨行支付快照（随状态快照导出）
+type InterbankSnapshot struct {
+	Payments []InterbankPaymentState `json:"payments"`
+	Seq      int                     `json:"seq"`
+}
+
+// 跨行支付及其内部调度字段（预计清算/退汇时间、排队中的 RTGS 指令）
+type InterbankPaymentState struct {
+	InterbankPayment
+	SettleAt  time.Time `json:"settleAt"`
+	ReturnAt  time.Time `json:"returnAt"`
+	RTGSEntry string    `json:"rtgsEntry,omitempty"`
+}
+
+// 导出跨行支付（调用方需持有 s.mu，并在其后获取账户锁，使在途支付与转出账户余额取自同一时刻）
+func (s *InterbankService) snapshot() InterbankSnapshot {
+	snapshot := InterbankSnapshot{Payments: make([]InterbankPaymentState, 0, len(s.payments)), Seq: s.seq}
+	for _, payment := range s.payments {
+		snapshot.Payments = append(snapshot.Payments, InterbankPaymentState{
+			InterbankPayment: *payment,
+			SettleAt:         payment.settleAt,
+			ReturnAt:         payment.returnAt,
+			RTGSEntry:        payment.rtgsEntry,
+		})
+	}
+	sort.Slice(snapshot.Payments, func(i, j int) bool { return snapshot.Payments[i].Reference < snapshot.Payments[j].Reference })
+	return snapshot
+}
+
+// 以快照替换跨行支付（调用方需持有 s.mu）
+func (s *InterbankService) restore(snapshot InterbankSnapshot) {
+	payments := make(map[string]*InterbankPayment, len(snapshot.Payments))
+	for _, state := range snapshot.Payments {
+		payment := state.InterbankPayment
+		payment.settleAt, payment.returnAt, payment.rtgsEntry = state.SettleAt, state.ReturnAt, state.RTGSEntry
+		payments[payment.Reference] = &payment
+	}
+	s.payments, s.seq = payments, snapshot.Seq
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 资金出入行的边界科目：现金存取、跨行清算备付金划拨、银行卡网络清算。
+// 其余内部科目（手续费、利息、挂账、外汇敞口等）与客户账户之间的资金往来均视为行内流转
+var boundaryRoles = []string{GL_CASH, GL_CENTRAL_BANK, GL_CARD_SETTLEMENT}
+
+// 单一币种的资金守恒核对
+type CurrencyInvariant struct {
+	Currency         string  `json:"currency"`
+	CustomerBalances float64 `json:"customerBalances"` // 客户账户余额合计（取自账户当前余额）
+	InternalBalances float64 `json:"internalBalances"` // 内部科目余额合计（贷方 - 借方，不含边界科目）
+	Total            float64 `json:"total"`            // 行内资金合计
+	NetInflow        float64 `json:"netInflow"`        // 经边界科目净流入（存款、跨行转入等减去取款、跨行转出等）
+	Drift            float64 `json:"drift"`            // 行内资金合计 - 净流入，应为 0
+	DebitTotal       float64 `json:"debitTotal"`       // 全部分录借方合计
+	CreditTotal      float64 `json:"creditTotal"`      // 全部分录贷方合计
+}
+
+// 资金守恒检查结果
+type InvariantReport struct {
+	CheckedAt  string              `json:"checkedAt"`
+	Holds      bool                `json:"holds"`
+	Accounts   int                 `json:"accounts"`
+	Currencies []CurrencyInvariant `json:"currencies"`
+	Violations []string            `json:"violations"`
+}
+
+// 资金守恒检查：各币种 客户账户余额 + 内部科目余额 = 经边界科目的净流入，且全部分录借贷轧平。
+// 行内转账、手续费、利息等只在行内流转，不应凭空产生或消失资金；
+// 客户余额取自账户当前余额而非流水，余额被修改却未记账时同样会被发现
+func (s *LedgerService) CheckInvariants() InvariantReport {
+	s.accounts.RLock()
+	defer s.accounts.RUnlock()
+
+	boundary := make(map[string]bool, len(boundaryRoles))
+	for _, role := range boundaryRoles {
+		boundary[s.chart.Code(role)] = true
+	}
+
+	currencies := make(map[string]*CurrencyInvariant)
+	get := func(currency string) *CurrencyInvariant {
+		c, ok := currencies[currency]
+		if !ok {
+			c = &CurrencyInvariant{Currency: currency}
+			currencies[currency] = c
+		}
+		return c
+	}
+
+	ids := s.accounts.IDs()
+	for _, id := range ids {
+		account, _ := s.accounts.Find(id)
+		get(account.Currency).CustomerBalances += account.Balance
+	}
+	s.journal.View(func(transactions []model.Transaction, entries []model.LedgerEntry) {
+		for _, tx := range transactions {
+			c := get(tx.Currency)
+			if tx.Direction == "debit" {
+				c.DebitTotal += tx.Amount
+			} else {
+				c.CreditTotal += tx.Amount
+			}
+		}
+		for _, entry := range entries {
+			c := get(entry.Currency)
+			signed := entry.Amount
+			if entry.Direction == "debit" {
+				c.DebitTotal += entry.Amount
+				signed = -entry.Amount
+			} else {
+				c.CreditTotal += entry.Amount
+			}
+			if boundary[entry.Account] {
+				c.NetInflow -= signed // 边界科目借方为资金流入
+			} else {
+				c.InternalBalances += signed
+			}
+		}
+	})
+
+	report := InvariantReport{
+		CheckedAt:  s.clock.Now().Format("2006-01-02 15:04:05"),
+		Accounts:   len(ids),
+		Currencies: make([]CurrencyInvariant, 0, len(currencies)),
+		Violations: make([]string, 0),
+	}
+	for _, c := range currencies {
+		c.CustomerBalances = model.RoundAmount(c.CustomerBalances)
+		c.InternalBalances = model.RoundAmount(c.InternalBalances)
+		c.Total = model.RoundAmount(c.CustomerBalances + c.InternalBalances)
+		c.NetInflow = model.RoundAmount(c.NetInflow)
+		c.Drift = model.RoundAmount(c.Total - c.NetInflow)
+		c.DebitTotal = model.RoundAmount(c.DebitTotal)
+		c.CreditTotal = model.RoundAmount(c.CreditTotal)
+		report.Currencies = append(report.Currencies, *c)
+
+		if c.Drift != 0 {
+			report.Violations = append(report.Violations,
+				fmt.Sprintf("%s 资金不守恒：行内资金合计 %.2f，净流入 %.2f，差额 %.2f",
+					c.Currency, c.Total, c.NetInflow, c.Drift))
+		}
+		if diff := model.RoundAmount(c.CreditTotal - c.DebitTotal); diff != 0 {
+			report.Violations = append(report.Violations,
+				fmt.Sprintf("%s 分录借贷不平：借方 %.2f，贷方 %.2f，差额 %.2f",
+					c.Currency, c.DebitTotal, c.CreditTotal, diff))
+		}
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool { return report.Currencies[i].Currency < report.Currencies[j].Currency })
+	sort.Strings(report.Violations)
+	report.Holds = len(report.Violations) == 0
+
+	if !report.Holds {
+		// 终端提示：资金守恒检查失败
+		log.Println("\n[🚨 资金守恒检查失败]")
+		for _, v := range report.Violations {
+			log.Printf("\033[1;31m%s\033[0m", v) // 红色高亮
+		}
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	}
+	return report
+}
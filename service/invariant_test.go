@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 不发送邮件
+type nopMailer struct{}
+
+func (nopMailer) Notify(model.Account, string, map[string]interface{}) {}
+func (nopMailer) LargeTransferThreshold() float64                      { return 1e12 }
+
+// 资金守恒性质：任意一组随机并发转账（含余额不足被拒、低余额管理费、跨币种兑换）执行后，
+// 守恒检查通过，且各币种 客户余额 + 内部科目余额 保持为期初存款合计
+func TestConcurrentTransfersPreserveInvariants(t *testing.T) {
+	accounts, ledger := newTransferFixture(t)
+	opening := invariantTotals(t, ledger.CheckInvariants())
+
+	round := 0
+	var succeeded, rejected int64
+	property := func(seed int64) bool {
+		round++
+		rng := rand.New(rand.NewSource(seed))
+		ids := []string{"6200000001", "6200000002", "6200000003", "6200000004", "6200000005"}
+
+		const transfers, workers = 60, 8
+		requests := make(chan TransferRequest, transfers)
+		for i := 0; i < transfers; i++ {
+			from := rng.Intn(len(ids))
+			to := (from + 1 + rng.Intn(len(ids)-1)) % len(ids)
+			requests <- TransferRequest{
+				FromAccount: ids[from],
+				ToAccount:   ids[to],
+				Amount:      model.RoundAmount(0.01 + rng.Float64()*1500), // 部分金额超过余额，应被拒绝且不改变余额
+			}
+		}
+		close(requests)
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for req := range requests {
+					if _, err := accounts.Transfer(req); err != nil {
+						atomic.AddInt64(&rejected, 1) // 业务拒绝不应改变任何余额
+					} else {
+						atomic.AddInt64(&succeeded, 1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		report := ledger.CheckInvariants()
+		if !report.Holds {
+			t.Logf("第 %d 轮（seed %d）守恒检查未通过: %v", round, seed, report.Violations)
+			return false
+		}
+		for currency, total := range invariantTotals(t, report) {
+			if total != opening[currency] {
+				t.Logf("第 %d 轮（seed %d）%s 行内资金合计 %.2f，期初 %.2f", round, seed, currency, total, opening[currency])
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 30, Rand: rand.New(rand.NewSource(1419))}); err != nil {
+		t.Fatal(err)
+	}
+	if succeeded == 0 || rejected == 0 {
+		t.Fatalf("随机转账未覆盖成功与拒绝两种情形（成功 %d 笔，拒绝 %d 笔）", succeeded, rejected)
+	}
+}
+
+// 各币种行内资金合计（客户余额 + 内部科目余额）
+func invariantTotals(t *testing.T, report InvariantReport) map[string]float64 {
+	t.Helper()
+	totals := make(map[string]float64, len(report.Currencies))
+	for _, c := range report.Currencies {
+		totals[c.Currency] = c.Total
+	}
+	return totals
+}
+
+// 构造进程内账户服务：储蓄、结算（低余额管理费）与美元账户各若干，经存款入账期初资金
+func newTransferFixture(t *testing.T) (*AccountService, *LedgerService) {
+	t.Helper()
+	clock := &testClock{now: time.Date(2025, 3, 3, 10, 0, 0, 0, time.Local)}
+	accounts := repository.NewAccountRepository([]model.Account{
+		{AccountID: "6200000001", UserName: "储蓄甲", Currency: "CNY", Type: ACCOUNT_TYPE_SAVINGS, Status: "normal"},
+		{AccountID: "6200000002", UserName: "储蓄乙", Currency: "CNY", Type: ACCOUNT_TYPE_SAVINGS, Status: "normal"},
+		{AccountID: "6200000003", UserName: "结算丙", Currency: "CNY", Type: ACCOUNT_TYPE_CHECKING, Status: "normal"},
+		{AccountID: "6200000004", UserName: "结算丁", Currency: "CNY", Type: ACCOUNT_TYPE_CHECKING, Status: "normal"},
+		{AccountID: "6200000005", UserName: "美元戊", Currency: "USD", Type: ACCOUNT_TYPE_SAVINGS, Status: "normal"},
+	})
+	journal := repository.NewJournalRepository()
+	notifier := nopNotifier{}
+	templates := NewTemplateRegistry(t.TempDir())
+	flags := NewFeatureFlagService(filepath.Join(t.TempDir(), "flags.json"))
+
+	ledger := NewLedgerService(accounts, journal, NewChartOfAccounts(nil), clock, notifier)
+	fx := NewFXService(FXConfig{Spread: 0.002}, notifier, nil)
+	credit := NewCreditService(accounts, ledger, fx, clock)
+	products := NewProductService(accounts, ledger, fx, NewTaxService(TaxConfig{}, accounts, journal), clock)
+	pricing := NewPricingService(FeeConfig{FXRate: 0.001, FXMin: 1, QuoteTTL: time.Minute}, accounts, fx, products, flags, clock)
+	loyalty := NewLoyaltyService(LoyaltyConfig{}, accounts, ledger, fx, clock, notifier, templates)
+	promos := NewPromoService(accounts, ledger, clock, notifier, templates)
+	service := NewAccountService(accounts, ledger, pricing, promos, loyalty, credit, NewSagaService(clock), NewAuditService(clock), notifier, nopMailer{}, templates)
+
+	for _, id := range accounts.IDs() {
+		if _, err := service.Deposit(DepositRequest{AccountID: id, Amount: 5000}, 0); err != nil {
+			t.Fatal(fmt.Errorf("期初存款失败: %w", err))
+		}
+	}
+	return service, ledger
+}
@@ -0,0 +1,576 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// -------------------------- pain.001 客户转账发起报文 --------------------------
+
+// pain.001.001.03 报文（仅解析模拟器需要的字段）
+type Pain001Document struct {
+	XMLName  xml.Name `xml:"Document"`
+	Initiate struct {
+		GrpHdr struct {
+			MsgId    string  `xml:"MsgId"`
+			CreDtTm  string  `xml:"CreDtTm"`
+			NbOfTxs  string  `xml:"NbOfTxs"`
+			CtrlSum  float64 `xml:"CtrlSum"`
+			InitgPty struct {
+				Nm string `xml:"Nm"`
+			} `xml:"InitgPty"`
+		} `xml:"GrpHdr"`
+		PmtInf []Pain001PaymentInfo `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+// pain.001 付款信息块（同一付款账户下的一组交易）
+type Pain001PaymentInfo struct {
+	PmtInfId string `xml:"PmtInfId"`
+	PmtMtd   string `xml:"PmtMtd"`
+	Dbtr     struct {
+		Nm string `xml:"Nm"`
+	} `xml:"Dbtr"`
+	DbtrAcct    Iso20022Account        `xml:"DbtrAcct"`
+	CdtTrfTxInf []Pain001TransferTxInf `xml:"CdtTrfTxInf"`
+}
+
+// pain.001 单笔转账信息
+type Pain001TransferTxInf struct {
+	PmtId struct {
+		InstrId    string `xml:"InstrId"`
+		EndToEndId string `xml:"EndToEndId"`
+	} `xml:"PmtId"`
+	Amt struct {
+		InstdAmt struct {
+			Ccy   string  `xml:"Ccy,attr"`
+			Value float64 `xml:",chardata"`
+		} `xml:"InstdAmt"`
+	} `xml:"Amt"`
+	CdtrAgt struct {
+		FinInstnId struct {
+			BIC string `xml:"BIC"`
+		} `xml:"FinInstnId"`
+	} `xml:"CdtrAgt"`
+	Cdtr struct {
+		Nm string `xml:"Nm"`
+	} `xml:"Cdtr"`
+	CdtrAcct Iso20022Account `xml:"CdtrAcct"`
+	Purp     struct {
+		Cd string `xml:"Cd"`
+	} `xml:"Purp"`
+	RmtInf struct {
+		Ustrd string `xml:"Ustrd"`
+	} `xml:"RmtInf"`
+}
+
+// 付款附加信息（EndToEndId 为 NOTPROVIDED 时视为未提供）
+func (tx Pain001TransferTxInf) Remittance() model.Remittance {
+	remittance := model.Remittance{Memo: tx.RmtInf.Ustrd, PurposeCode: tx.Purp.Cd}
+	if tx.PmtId.EndToEndId != "NOTPROVIDED" {
+		remittance.EndToEndID = tx.PmtId.EndToEndId
+	}
+	return remittance
+}
+
+// ISO 20022 账户标识（支持 IBAN 或其他账号）
+type Iso20022Account struct {
+	Id struct {
+		IBAN string `xml:"IBAN,omitempty"`
+		Othr *struct {
+			Id string `xml:"Id"`
+		} `xml:"Othr,omitempty"`
+	} `xml:"Id"`
+}
+
+// 账号（优先取 IBAN）
+func (a Iso20022Account) AccountID() string {
+	if a.Id.IBAN != "" {
+		return a.Id.IBAN
+	}
+	if a.Id.Othr != nil {
+		return a.Id.Othr.Id
+	}
+	return ""
+}
+
+// -------------------------- pain.002 支付状态报告 --------------------------
+
+// pain.002.001.03 报文
+type Pain002Document struct {
+	XMLName xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:pain.002.001.03 Document"`
+	Report  struct {
+		GrpHdr struct {
+			MsgId   string `xml:"MsgId"`
+			CreDtTm string `xml:"CreDtTm"`
+			DbtrAgt struct {
+				FinInstnId struct {
+					BIC string `xml:"BIC"`
+				} `xml:"FinInstnId"`
+			} `xml:"DbtrAgt"`
+		} `xml:"GrpHdr"`
+		OrgnlGrpInfAndSts struct {
+			OrgnlMsgId   string               `xml:"OrgnlMsgId"`
+			OrgnlMsgNmId string               `xml:"OrgnlMsgNmId"`
+			OrgnlNbOfTxs string               `xml:"OrgnlNbOfTxs"`
+			OrgnlCtrlSum float64              `xml:"OrgnlCtrlSum"`
+			GrpSts       string               `xml:"GrpSts"` // ACCP/ACSP/PART/RJCT
+			StsRsnInf    *Pain002StatusReason `xml:"StsRsnInf,omitempty"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		OrgnlPmtInfAndSts []Pain002PaymentInfoStatus `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+// pain.002 付款信息块状态
+type Pain002PaymentInfoStatus struct {
+	OrgnlPmtInfId string            `xml:"OrgnlPmtInfId"`
+	PmtInfSts     string            `xml:"PmtInfSts"`
+	TxInfAndSts   []Pain002TxStatus `xml:"TxInfAndSts"`
+}
+
+// pain.002 单笔交易状态
+type Pain002TxStatus struct {
+	StsId           string               `xml:"StsId"`
+	OrgnlInstrId    string               `xml:"OrgnlInstrId,omitempty"`
+	OrgnlEndToEndId string               `xml:"OrgnlEndToEndId"`
+	TxSts           string               `xml:"TxSts"` // ACSC 已清算/ACSP 清算中/RJCT 拒绝
+	StsRsnInf       *Pain002StatusReason `xml:"StsRsnInf,omitempty"`
+	AcctSvcrRef     string               `xml:"AcctSvcrRef,omitempty"` // 本行流水号
+}
+
+// pain.002 状态原因
+type Pain002StatusReason struct {
+	Rsn struct {
+		Cd string `xml:"Cd"`
+	} `xml:"Rsn"`
+	AddtlInf string `xml:"AddtlInf,omitempty"`
+}
+
+// 构造状态原因
+func newPain002StatusReason(code, info string) *Pain002StatusReason {
+	reason := &Pain002StatusReason{AddtlInf: info}
+	reason.Rsn.Cd = code
+	return reason
+}
+
+// 业务码与 ISO 20022 原因码映射
+var iso20022ReasonCodes = map[int]string{
+	model.CODE_PARAM_ERROR:             "FF01", // 报文格式/参数错误
+	model.CODE_ACCOUNT_NOT_EXIST:       "AC01", // 账号错误
+	model.CODE_ACCOUNT_FROZEN:          "AC06", // 账户冻结
+	model.CODE_BALANCE_NOT_ENOUGH:      "AM04", // 余额不足
+	model.CODE_TARGET_ACCOUNT_ABNORMAL: "AC04", // 收款账户异常
+	model.CODE_ACCOUNT_LIMIT:           "AM02", // 超出限额
+	model.CODE_RISK_CONTROL_REJECT:     "MS03", // 风控拒绝
+}
+
+// 批量支付服务：执行 pain.001 批量转账并生成 pain.002 状态报告
+type PaymentService struct {
+	accounts  *repository.AccountRepository
+	transfers *AccountService
+	interbank *InterbankService
+
+	reports map[string][]byte // 已生成的 pain.002 报告（按原 pain.001 MsgId 索引）
+	seq     int
+	mu      sync.Mutex
+}
+
+func NewPaymentService(accounts *repository.AccountRepository, transfers *AccountService, interbank *InterbankService) *PaymentService {
+	return &PaymentService{
+		accounts:  accounts,
+		transfers: transfers,
+		interbank: interbank,
+		reports:   make(map[string][]byte),
+	}
+}
+
+// 导入 pain.001 批量支付，逐笔执行并返回（同时存档）pain.002 状态报告 XML
+func (s *PaymentService) Import(doc *Pain001Document) ([]byte, error) {
+	if doc.Initiate.GrpHdr.MsgId == "" || len(doc.Initiate.PmtInf) == 0 {
+		return nil, model.NewError(model.CODE_PARAM_ERROR, "pain.001 报文缺少 MsgId 或付款信息")
+	}
+
+	report := s.process(doc)
+	output, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, model.NewError(model.CODE_UNKNOWN_ERROR, "pain.002 报告生成失败")
+	}
+	output = append([]byte(xml.Header), output...)
+
+	s.mu.Lock()
+	s.reports[doc.Initiate.GrpHdr.MsgId] = output
+	s.mu.Unlock()
+	return output, nil
+}
+
+// 按原 pain.001 MsgId 查询 pain.002 状态报告
+func (s *PaymentService) Report(msgID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	output, ok := s.reports[msgID]
+	return output, ok
+}
+
+// 校验并逐笔执行 pain.001 中的转账，生成 pain.002 报告
+func (s *PaymentService) process(doc *Pain001Document) *Pain002Document {
+	grpHdr := doc.Initiate.GrpHdr
+	now := time.Now()
+
+	s.mu.Lock()
+	s.seq++
+	reportID := fmt.Sprintf("PSR%s%06d", now.Format("20060102"), s.seq)
+	s.mu.Unlock()
+
+	report := &Pain002Document{}
+	report.Report.GrpHdr.MsgId = reportID
+	report.Report.GrpHdr.CreDtTm = now.Format("2006-01-02T15:04:05")
+	report.Report.GrpHdr.DbtrAgt.FinInstnId.BIC = model.BANK_BIC
+	orgnl := &report.Report.OrgnlGrpInfAndSts
+	orgnl.OrgnlMsgId = grpHdr.MsgId
+	orgnl.OrgnlMsgNmId = "pain.001.001.03"
+	orgnl.OrgnlNbOfTxs = grpHdr.NbOfTxs
+	orgnl.OrgnlCtrlSum = grpHdr.CtrlSum
+
+	// 组级校验：笔数与控制总额必须与明细一致，否则整批拒绝
+	count, sum := 0, 0.0
+	for _, pmtInf := range doc.Initiate.PmtInf {
+		for _, tx := range pmtInf.CdtTrfTxInf {
+			count++
+			sum += tx.Amt.InstdAmt.Value
+		}
+	}
+	if grpHdr.NbOfTxs != "" && grpHdr.NbOfTxs != fmt.Sprint(count) {
+		orgnl.GrpSts = "RJCT"
+		orgnl.StsRsnInf = newPain002StatusReason("AM18", "NbOfTxs 与明细笔数不一致")
+		return report
+	}
+	if grpHdr.CtrlSum != 0 && math.Abs(grpHdr.CtrlSum-sum) > 0.005 {
+		orgnl.GrpSts = "RJCT"
+		orgnl.StsRsnInf = newPain002StatusReason("AM10", "CtrlSum 与明细金额合计不一致")
+		return report
+	}
+
+	// 终端提示：批量支付导入
+	log.Println("\n[📦 pain.001 批量支付导入]")
+	log.Printf("导入时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("报文ID: %s", grpHdr.MsgId)
+	log.Printf("发起方: %s", grpHdr.InitgPty.Nm)
+	log.Printf("交易笔数: %d | 金额合计: %.2f 元", count, sum)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	accepted, rejected := 0, 0
+	seq := 0
+	for _, pmtInf := range doc.Initiate.PmtInf {
+		pmtStatus := Pain002PaymentInfoStatus{OrgnlPmtInfId: pmtInf.PmtInfId}
+		debtor := pmtInf.DbtrAcct.AccountID()
+		pmtAccepted, pmtRejected := 0, 0
+
+		for _, tx := range pmtInf.CdtTrfTxInf {
+			seq++
+			status := s.executeTransfer(debtor, tx)
+			status.StsId = fmt.Sprintf("%s-%d", reportID, seq)
+			if status.TxSts == "RJCT" {
+				pmtRejected++
+			} else {
+				pmtAccepted++
+			}
+			pmtStatus.TxInfAndSts = append(pmtStatus.TxInfAndSts, status)
+		}
+
+		pmtStatus.PmtInfSts = groupStatus(pmtAccepted, pmtRejected)
+		report.Report.OrgnlPmtInfAndSts = append(report.Report.OrgnlPmtInfAndSts, pmtStatus)
+		accepted += pmtAccepted
+		rejected += pmtRejected
+	}
+	orgnl.GrpSts = groupStatus(accepted, rejected)
+
+	log.Printf("[📦 pain.001 批量支付导入] 报文ID: %s | 成功: %d 笔 | 失败: %d 笔 | 状态: %s",
+		grpHdr.MsgId, accepted, rejected, orgnl.GrpSts)
+	return report
+}
+
+// 执行 pain.001 中的单笔转账：收款行为本行（或为空）走行内转账，否则走跨行清算
+func (s *PaymentService) executeTransfer(debtor string, tx Pain001TransferTxInf) Pain002TxStatus {
+	status := Pain002TxStatus{
+		OrgnlInstrId:    tx.PmtId.InstrId,
+		OrgnlEndToEndId: tx.PmtId.EndToEndId,
+	}
+
+	// 指令币种须与付款账户币种一致
+	debtorAccount, _ := s.accounts.Get(debtor)
+	if ccy := tx.Amt.InstdAmt.Ccy; ccy != "" && debtorAccount.Currency != "" && ccy != debtorAccount.Currency {
+		status.TxSts = "RJCT"
+		status.StsRsnInf = newPain002StatusReason("AM03", "指令币种与付款账户币种不一致: "+ccy)
+		return status
+	}
+
+	// 行内转账实时入账；跨行转账待清算
+	var err error
+	status.TxSts = "ACSC"
+	creditorBIC := tx.CdtrAgt.FinInstnId.BIC
+	if creditorBIC == "" || creditorBIC == model.BANK_BIC {
+		_, err = s.transfers.Transfer(TransferRequest{
+			FromAccount: debtor,
+			ToAccount:   tx.CdtrAcct.AccountID(),
+			Amount:      tx.Amt.InstdAmt.Value,
+			Remittance:  tx.Remittance(),
+		})
+	} else {
+		var payment InterbankPayment
+		payment, err = s.interbank.Transfer(InterbankTransferRequest{
+			FromAccount: debtor,
+			ToBank:      creditorBIC,
+			ToAccount:   tx.CdtrAcct.AccountID(),
+			ToName:      tx.Cdtr.Nm,
+			Amount:      tx.Amt.InstdAmt.Value,
+			Remittance:  tx.Remittance(),
+		})
+		status.TxSts = "ACSP"
+		status.AcctSvcrRef = payment.Reference
+	}
+
+	if err != nil {
+		code, message := model.ErrorCode(err)
+		reasonCode, ok := iso20022ReasonCodes[code]
+		if !ok {
+			reasonCode = "NARR"
+		}
+		status.TxSts = "RJCT"
+		status.StsRsnInf = newPain002StatusReason(reasonCode, message)
+		status.AcctSvcrRef = ""
+	}
+	return status
+}
+
+// 根据成功/失败笔数计算组状态
+func groupStatus(accepted, rejected int) string {
+	switch {
+	case rejected == 0:
+		return "ACCP"
+	case accepted == 0:
+		return "RJCT"
+	default:
+		return "PART"
+	}
+}
+
+// -------------------------- camt.053 银行对账单报文 --------------------------
+
+// camt.053.001.02 报文
+type Camt053Document struct {
+	XMLName xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+	Stmt    struct {
+		GrpHdr struct {
+			MsgId   string `xml:"MsgId"`
+			CreDtTm string `xml:"CreDtTm"`
+		} `xml:"GrpHdr"`
+		Stmt Camt053Statement `xml:"Stmt"`
+	} `xml:"BkToCstmrStmt"`
+}
+
+// camt.053 对账单
+type Camt053Statement struct {
+	Id           string `xml:"Id"`
+	ElctrncSeqNb int    `xml:"ElctrncSeqNb"`
+	CreDtTm      string `xml:"CreDtTm"`
+	FrToDt       struct {
+		FrDtTm string `xml:"FrDtTm"`
+		ToDtTm string `xml:"ToDtTm"`
+	} `xml:"FrToDt"`
+	Acct struct {
+		Iso20022Account
+		Ccy  string `xml:"Ccy"`
+		Ownr struct {
+			Nm string `xml:"Nm"`
+		} `xml:"Ownr"`
+		Svcr struct {
+			FinInstnId struct {
+				BIC string `xml:"BIC"`
+			} `xml:"FinInstnId"`
+		} `xml:"Svcr"`
+	} `xml:"Acct"`
+	Bal       []Camt053Balance `xml:"Bal"`
+	TxsSummry struct {
+		TtlNtries    Camt053EntrySummary `xml:"TtlNtries"`
+		TtlCdtNtries Camt053EntrySummary `xml:"TtlCdtNtries"`
+		TtlDbtNtries Camt053EntrySummary `xml:"TtlDbtNtries"`
+	} `xml:"TxsSummry"`
+	Ntry []Camt053Entry `xml:"Ntry"`
+}
+
+// ISO 20022 带币种金额
+type Iso20022Amount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+// 构造带币种金额（保留两位小数）
+func newIso20022Amount(amount float64, currency string) Iso20022Amount {
+	return Iso20022Amount{Ccy: currency, Value: fmt.Sprintf("%.2f", math.Abs(amount))}
+}
+
+// 金额方向（余额为负时为借方）
+func creditDebitIndicator(amount float64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// camt.053 余额
+type Camt053Balance struct {
+	Tp struct {
+		CdOrPrtry struct {
+			Cd string `xml:"Cd"` // OPBD 期初/CLBD 期末
+		} `xml:"CdOrPrtry"`
+	} `xml:"Tp"`
+	Amt       Iso20022Amount `xml:"Amt"`
+	CdtDbtInd string         `xml:"CdtDbtInd"`
+	Dt        struct {
+		Dt string `xml:"Dt"`
+	} `xml:"Dt"`
+}
+
+// camt.053 分录汇总
+type Camt053EntrySummary struct {
+	NbOfNtries int    `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+// camt.053 分录
+type Camt053Entry struct {
+	NtryRef   string         `xml:"NtryRef"`
+	Amt       Iso20022Amount `xml:"Amt"`
+	CdtDbtInd string         `xml:"CdtDbtInd"`
+	Sts       string         `xml:"Sts"`
+	BookgDt   struct {
+		DtTm string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	ValDt struct {
+		Dt string `xml:"Dt"`
+	} `xml:"ValDt"`
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	BkTxCd      struct {
+		Prtry struct {
+			Cd string `xml:"Cd"`
+		} `xml:"Prtry"`
+	} `xml:"BkTxCd"`
+	NtryDtls struct {
+		TxDtls struct {
+			Refs struct {
+				EndToEndId string `xml:"EndToEndId,omitempty"`
+			} `xml:"Refs"`
+			RltdPties *struct {
+				CdtrAcct *Iso20022Account `xml:"CdtrAcct,omitempty"`
+				DbtrAcct *Iso20022Account `xml:"DbtrAcct,omitempty"`
+			} `xml:"RltdPties,omitempty"`
+			Purp *struct {
+				Cd string `xml:"Cd"`
+			} `xml:"Purp,omitempty"`
+			RmtInf struct {
+				Ustrd string `xml:"Ustrd"`
+			} `xml:"RmtInf"`
+		} `xml:"TxDtls"`
+	} `xml:"NtryDtls"`
+}
+
+// 构造账户标识
+func newIso20022Account(accountID string) Iso20022Account {
+	var acct Iso20022Account
+	acct.Id.Othr = &struct {
+		Id string `xml:"Id"`
+	}{Id: accountID}
+	return acct
+}
+
+// 构造 camt.053 余额
+func newCamt053Balance(code string, amount float64, currency, date string) Camt053Balance {
+	var bal Camt053Balance
+	bal.Tp.CdOrPrtry.Cd = code
+	bal.Amt = newIso20022Amount(amount, currency)
+	bal.CdtDbtInd = creditDebitIndicator(amount)
+	bal.Dt.Dt = date
+	return bal
+}
+
+// 将日终对账单转换为 camt.053 报文
+func (s *StatementService) Camt053(statement Statement) *Camt053Document {
+	now := s.clock.Now()
+	doc := &Camt053Document{}
+	doc.Stmt.GrpHdr.MsgId = "CAMT053" + statement.StatementID
+	doc.Stmt.GrpHdr.CreDtTm = now.Format("2006-01-02T15:04:05")
+
+	stmt := &doc.Stmt.Stmt
+	stmt.Id = statement.StatementID
+	stmt.ElctrncSeqNb = statement.SeqNo
+	stmt.CreDtTm = doc.Stmt.GrpHdr.CreDtTm
+	stmt.FrToDt.FrDtTm = statement.Date + "T00:00:00"
+	stmt.FrToDt.ToDtTm = statement.Date + "T23:59:59"
+	stmt.Acct.Iso20022Account = newIso20022Account(statement.AccountID)
+	stmt.Acct.Ccy = statement.Currency
+	stmt.Acct.Ownr.Nm = statement.UserName
+	stmt.Acct.Svcr.FinInstnId.BIC = model.BANK_BIC
+	stmt.Bal = []Camt053Balance{
+		newCamt053Balance("OPBD", statement.OpeningBalance, statement.Currency, statement.Date),
+		newCamt053Balance("CLBD", statement.ClosingBalance, statement.Currency, statement.Date),
+	}
+
+	stmt.TxsSummry.TtlNtries = Camt053EntrySummary{
+		NbOfNtries: statement.CreditCount + statement.DebitCount,
+		Sum:        fmt.Sprintf("%.2f", statement.CreditTotal+statement.DebitTotal),
+	}
+	stmt.TxsSummry.TtlCdtNtries = Camt053EntrySummary{NbOfNtries: statement.CreditCount, Sum: fmt.Sprintf("%.2f", statement.CreditTotal)}
+	stmt.TxsSummry.TtlDbtNtries = Camt053EntrySummary{NbOfNtries: statement.DebitCount, Sum: fmt.Sprintf("%.2f", statement.DebitTotal)}
+
+	for _, tx := range statement.Entries {
+		var entry Camt053Entry
+		entry.NtryRef = tx.TxID
+		entry.Amt = newIso20022Amount(tx.Amount, tx.Currency)
+		entry.CdtDbtInd = "CRDT"
+		if tx.Direction == "debit" {
+			entry.CdtDbtInd = "DBIT"
+		}
+		entry.Sts = "BOOK"
+		entry.BookgDt.DtTm = tx.Time.Format("2006-01-02T15:04:05")
+		entry.ValDt.Dt = tx.Time.Format("2006-01-02")
+		entry.AcctSvcrRef = tx.TxID
+		entry.BkTxCd.Prtry.Cd = strings.ToUpper(tx.Type)
+		// 端到端参考号优先使用付款方指定值，附言优先使用付款方填写内容
+		entry.NtryDtls.TxDtls.Refs.EndToEndId = tx.Reference
+		if tx.EndToEndID != "" {
+			entry.NtryDtls.TxDtls.Refs.EndToEndId = tx.EndToEndID
+		}
+		entry.NtryDtls.TxDtls.RmtInf.Ustrd = tx.Description
+		if tx.Memo != "" {
+			entry.NtryDtls.TxDtls.RmtInf.Ustrd = tx.Memo
+		}
+		if tx.PurposeCode != "" {
+			entry.NtryDtls.TxDtls.Purp = &struct {
+				Cd string `xml:"Cd"`
+			}{Cd: tx.PurposeCode}
+		}
+		if tx.Counterparty != "" {
+			counterparty := newIso20022Account(tx.Counterparty)
+			entry.NtryDtls.TxDtls.RltdPties = &struct {
+				CdtrAcct *Iso20022Account `xml:"CdtrAcct,omitempty"`
+				DbtrAcct *Iso20022Account `xml:"DbtrAcct,omitempty"`
+			}{}
+			if tx.Direction == "debit" {
+				entry.NtryDtls.TxDtls.RltdPties.CdtrAcct = &counterparty
+			} else {
+				entry.NtryDtls.TxDtls.RltdPties.DbtrAcct = &counterparty
+			}
+		}
+		stmt.Ntry = append(stmt.Ntry, entry)
+	}
+	return doc
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 选主模式
+const (
+	LEADER_MODE_SINGLE = "single" // 未配置租约目录，单实例部署，本节点始终为主节点
+	LEADER_MODE_LEASE  = "lease"  // 基于共享目录的租约选主
+)
+
+// 选主配置
+type LeaderConfig struct {
+	LeaseDir string        // 各实例共享的租约目录（如网络存储挂载点），为空表示单实例部署
+	NodeID   string        // 本节点标识，为空时取主机名与进程号
+	LeaseTTL time.Duration // 租约有效期，主节点超过该时间未续约即由其他节点接管
+}
+
+// 租约文件内容
+type LeaderLease struct {
+	NodeID     string    `json:"nodeId"`
+	Term       int64     `json:"term"` // 任期，每次易主递增
+	AcquiredAt time.Time `json:"acquiredAt"`
+	RenewedAt  time.Time `json:"renewedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// 选主状态
+type LeaderStatus struct {
+	Mode           string `json:"mode"` // single/lease
+	NodeID         string `json:"nodeId"`
+	IsLeader       bool   `json:"isLeader"`
+	Leader         string `json:"leader"` // 当前主节点，租约过期后为空
+	Term           int64  `json:"term"`
+	AcquiredAt     string `json:"acquiredAt,omitempty"`
+	LeaseExpiresAt string `json:"leaseExpiresAt,omitempty"`
+	LeaseTTL       string `json:"leaseTtl,omitempty"`
+	LastError      string `json:"lastError,omitempty"` // 最近一次读写租约失败原因
+}
+
+// 选主服务：多实例部署时，日终、清算、冻结到期、公告发布等后台任务只在主节点执行。
+// 各节点定期尝试获取或续约共享目录中的租约文件，主节点失联超过租约有效期后由其他节点自动接管。
+// 租约按真实时间计算，与模拟时钟无关
+type LeaderElector struct {
+	cfg  LeaderConfig
+	path string
+
+	mu        sync.Mutex
+	lease     LeaderLease // 最近一次读到或写入的租约
+	leader    bool
+	lastError string
+}
+
+func NewLeaderElector(cfg LeaderConfig) *LeaderElector {
+	if cfg.NodeID == "" {
+		host, _ := os.Hostname()
+		cfg.NodeID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = 15 * time.Second
+	}
+	e := &LeaderElector{cfg: cfg}
+	if cfg.LeaseDir == "" {
+		e.leader = true
+		return e
+	}
+	if err := os.MkdirAll(cfg.LeaseDir, 0o755); err != nil {
+		log.Printf("创建选主租约目录失败: %v", err)
+	}
+	e.path = filepath.Join(cfg.LeaseDir, "leader.lease")
+	return e
+}
+
+// 选主主循环：按租约有效期的三分之一定时获取或续约租约（单实例部署时直接返回）
+func (e *LeaderElector) Run() {
+	if e.path == "" {
+		log.Printf("未配置选主租约目录，按单实例运行，本节点 %s 执行全部后台任务", e.cfg.NodeID)
+		return
+	}
+	log.Printf("选主已启动，节点: %s，租约目录: %s，租约有效期: %s", e.cfg.NodeID, e.cfg.LeaseDir, e.cfg.LeaseTTL)
+
+	e.Elect(time.Now())
+	ticker := time.NewTicker(e.cfg.LeaseTTL / 3)
+	defer ticker.Stop()
+	for range ticker.C {
+		e.Elect(time.Now())
+	}
+}
+
+// 本节点当前是否为主节点（后台任务每次执行前检查）
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// 选主状态
+func (e *LeaderElector) Status() LeaderStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := LeaderStatus{Mode: LEADER_MODE_SINGLE, NodeID: e.cfg.NodeID, IsLeader: e.leader, LastError: e.lastError}
+	if e.path == "" {
+		status.Leader = e.cfg.NodeID
+		return status
+	}
+	status.Mode = LEADER_MODE_LEASE
+	status.LeaseTTL = e.cfg.LeaseTTL.String()
+	status.Term = e.lease.Term
+	if e.lease.NodeID != "" && time.Now().Before(e.lease.ExpiresAt) {
+		status.Leader = e.lease.NodeID
+		status.AcquiredAt = e.lease.AcquiredAt.Format("2006-01-02 15:04:05")
+		status.LeaseExpiresAt = e.lease.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	return status
+}
+
+// 执行一轮选主：租约空缺或已过期时接管，本节点持有租约时续约，否则跟随现任主节点
+func (e *LeaderElector) Elect(now time.Time) {
+	lease, leader, err := e.elect(now)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		// 无法确认租约时不再执行后台任务，避免与其他节点同时执行
+		e.lastError = err.Error()
+		if e.leader && now.After(e.lease.ExpiresAt) {
+			e.leader = false
+			logLeaderChange(e.cfg.NodeID, false, e.lease, e.lastError)
+		}
+		return
+	}
+	e.lastError = ""
+	e.lease = lease
+	if leader != e.leader {
+		e.leader = leader
+		logLeaderChange(e.cfg.NodeID, leader, lease, "")
+	}
+}
+
+// 在租约锁保护下读取并更新租约文件
+func (e *LeaderElector) elect(now time.Time) (LeaderLease, bool, error) {
+	unlock, err := e.lockLease(now)
+	if err != nil {
+		return LeaderLease{}, false, err
+	}
+	defer unlock()
+
+	lease, err := e.readLease()
+	if err != nil {
+		return LeaderLease{}, false, err
+	}
+	switch {
+	case lease.NodeID == e.cfg.NodeID && now.Before(lease.ExpiresAt):
+		lease.RenewedAt = now
+	case lease.NodeID == "" || !now.Before(lease.ExpiresAt):
+		lease = LeaderLease{NodeID: e.cfg.NodeID, Term: lease.Term + 1, AcquiredAt: now, RenewedAt: now}
+	default:
+		return lease, false, nil
+	}
+	lease.ExpiresAt = now.Add(e.cfg.LeaseTTL)
+	if err := e.writeLease(lease); err != nil {
+		return LeaderLease{}, false, err
+	}
+	return lease, true, nil
+}
+
+// 以独占创建锁文件的方式互斥读写租约；锁文件残留超过租约有效期视为持有者已崩溃，予以清除
+func (e *LeaderElector) lockLease(now time.Time) (func(), error) {
+	lockPath := e.path + ".lock"
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.WriteString(e.cfg.NodeID)
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		info, statErr := os.Stat(lockPath)
+		if statErr != nil || now.Sub(info.ModTime()) < e.cfg.LeaseTTL {
+			return nil, errors.New("租约锁被其他节点持有")
+		}
+		os.Remove(lockPath)
+	}
+	return nil, errors.New("租约锁被其他节点持有")
+}
+
+// 读取租约文件（不存在时返回空租约）
+func (e *LeaderElector) readLease() (LeaderLease, error) {
+	var lease LeaderLease
+	data, err := os.ReadFile(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lease, nil
+	}
+	if err != nil {
+		return lease, err
+	}
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return LeaderLease{}, fmt.Errorf("租约文件格式错误: %v", err)
+	}
+	return lease, nil
+}
+
+// 写入租约文件（先写临时文件再改名，避免其他节点读到不完整内容）
+func (e *LeaderElector) writeLease(lease LeaderLease) error {
+	data, err := json.MarshalIndent(lease, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := e.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.path)
+}
+
+// 终端提示：主节点变更
+func logLeaderChange(nodeID string, leader bool, lease LeaderLease, reason string) {
+	if leader {
+		log.Println("\n[👑 成为主节点]")
+		log.Printf("节点: %s，任期: %d", nodeID, lease.Term)
+		log.Printf("租约到期: %s，后台任务在本节点执行", lease.ExpiresAt.Format("2006-01-02 15:04:05"))
+	} else {
+		log.Println("\n[👑 失去主节点身份]")
+		log.Printf("节点: %s", nodeID)
+		if lease.NodeID != "" && lease.NodeID != nodeID {
+			log.Printf("现任主节点: %s（任期 %d）", lease.NodeID, lease.Term)
+		}
+		if reason != "" {
+			log.Printf("原因: %s", reason)
+		}
+		log.Println("后台任务暂停，等待重新当选")
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 客户交易的对方科目用途（交易类型 → 科目用途，未列出的类型记入待处理挂账）
+var contraRoles = map[string]string{
+	"opening":                GL_CASH, // 测试账户初始余额视同现金存入
+	"deposit":                GL_CASH,
+	"teller_deposit":         GL_CASH,
+	"teller_withdraw":        GL_CASH,
+	"card_purchase":          GL_CARD_SETTLEMENT,
+	"card_refund":            GL_CARD_SETTLEMENT,
+	"pos_sale":               GL_CARD_SETTLEMENT,
+	"pos_refund":             GL_CARD_SETTLEMENT,
+	"interchange_fee":        GL_FEE_INCOME,
+	"interchange_fee_refund": GL_FEE_INCOME,
+	"interbank_out":          GL_CLEARING,
+	"interbank_refund":       GL_CLEARING,
+	"interbank_in":           GL_CENTRAL_BANK, // 他行汇入经央行备付金到账
+	"suspense_release":       GL_SUSPENSE,     // 挂账款项查明后入账
+	"direct_debit":           GL_CLEARING,
+	"direct_debit_return":    GL_CLEARING,
+	"fee":                    GL_FEE_INCOME,
+	"interest":               GL_INTEREST_EXPENSE,
+	"withholding_tax":        GL_TAX_PAYABLE,
+	"signup_bonus":           GL_MARKETING_EXPENSE,
+	"deposit_match":          GL_MARKETING_EXPENSE,
+	"referral_bonus":         GL_MARKETING_EXPENSE,
+	"cashback":               GL_MARKETING_EXPENSE,
+	"points_redemption":      GL_MARKETING_EXPENSE,
+	"loan_disbursement":      GL_LOANS,
+	"loan_reversal":          GL_LOANS, // 放款冲正
+	"loan_principal":         GL_LOANS,
+	"loan_interest":          GL_INTEREST_INCOME,
+	"loan_penalty":           GL_INTEREST_INCOME,
+	"transfer_in":            "", // 行内转账两条流水互为对方
+	"transfer_out":           "",
+	"payroll_debit":          "", // 代发扣款与各笔入账互为对方
+	"payroll_credit":         "",
+	"adjustment":             GL_SUSPENSE, // 人工调账先记入待处理挂账，查明原因后结转
+}
+
+// 期间余额汇总
+type PeriodBalance struct {
+	OpeningBalance float64
+	ClosingBalance float64
+	CreditTotal    float64
+	DebitTotal     float64
+	Count          int
+}
+
+// 记账服务：交易流水、内部分录、总账科目与对账
+type LedgerService struct {
+	accounts *repository.AccountRepository
+	journal  *repository.JournalRepository
+	chart    *ChartOfAccounts
+	clock    Clock
+	notifier Notifier
+
+	subscribers []func(model.Transaction) // 交易流水订阅者（启动时注册）
+
+	lastReconciliation *ReconciliationResult
+	reconcileMutex     sync.RWMutex
+
+	closedBefore time.Time // 已结账期间的截止时间（不含），此前不得补记流水
+	periodMutex  sync.RWMutex
+}
+
+func NewLedgerService(accounts *repository.AccountRepository, journal *repository.JournalRepository,
+	chart *ChartOfAccounts, clock Clock, notifier Notifier) *LedgerService {
+	return &LedgerService{
+		accounts: accounts,
+		journal:  journal,
+		chart:    chart,
+		clock:    clock,
+		notifier: notifier,
+	}
+}
+
+// 科目表
+func (s *LedgerService) Chart() *ChartOfAccounts {
+	return s.chart
+}
+
+// 订阅交易流水：每记录一条流水同步回调（回调在账户写锁内执行，只可使用叶子锁），须在服务启动前注册
+func (s *LedgerService) Subscribe(fn func(model.Transaction)) {
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// 记录一条交易流水（调用方需持有账户写锁，保证与余额变动一致）
+func (s *LedgerService) Record(tx model.Transaction) model.Transaction {
+	if tx.Currency == "" {
+		account, _ := s.accounts.Find(tx.AccountID)
+		tx.Currency = account.Currency
+	}
+	tx.Time = s.clock.Now()
+
+	// 对方记入内部科目（同币种行内转账的两条流水互为对方，无需内部分录）
+	tx = s.journal.Append(tx, s.contraAccount(tx))
+	for _, fn := range s.subscribers {
+		fn(tx)
+	}
+	return tx
+}
+
+// 补记一条指定时间的历史流水（用于生成模拟数据，不通知订阅者；调用方需持有账户写锁，
+// 并先以 CheckOpenPeriod 确认补记时间不在已结账期间内）
+func (s *LedgerService) RecordAt(tx model.Transaction, at time.Time) model.Transaction {
+	tx.Time = at
+	return s.journal.Append(tx, s.contraAccount(tx))
+}
+
+// 交易流水的对方科目代码（跨币种转账的每条流水对应各自币种的外汇敞口）
+func (s *LedgerService) contraAccount(tx model.Transaction) string {
+	if tx.FXRate != 0 {
+		return s.chart.Code(GL_FX_POSITION)
+	}
+	role, ok := contraRoles[tx.Type]
+	if !ok {
+		role = GL_SUSPENSE
+	}
+	if role == "" {
+		return ""
+	}
+	return s.chart.Code(role)
+}
+
+// 记录一笔不涉及客户账户的内部分录（借 debitRole、贷 creditRole），如清算资金划拨、费用结转
+func (s *LedgerService) PostInternal(debitRole, creditRole string, amount float64, currency, reference string) {
+	s.journal.AppendInternal(s.chart.Code(debitRole), s.chart.Code(creditRole), amount, currency, reference, s.clock.Now())
+}
+
+// 为测试账户的初始余额补记开户流水，使账户余额可由流水完整推导
+func (s *LedgerService) RecordOpeningBalances() {
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	for _, id := range s.accounts.IDs() {
+		account, _ := s.accounts.Find(id)
+		if account.Balance == 0 {
+			continue
+		}
+		s.Record(model.Transaction{
+			AccountID:    id,
+			Type:         "opening",
+			Direction:    "credit",
+			Amount:       account.Balance,
+			BalanceAfter: account.Balance,
+			Description:  "开户余额",
+		})
+	}
+}
+
+// 查询账户在 [from, to) 区间内的交易流水（零值表示不限）
+func (s *LedgerService) Transactions(accountID string, from, to time.Time) []model.Transaction {
+	return s.journal.Transactions(accountID, from, to)
+}
+
+// 账户流水版本（流水只追加不修改，账户有新流水记账或流水被快照替换时变化），用于生成交易流水查询的 ETag
+func (s *LedgerService) HistoryVersion(accountID string) string {
+	last, generation := s.journal.Watermark(accountID)
+	return fmt.Sprintf("%d.%d", generation, last+1)
+}
+
+// 交易流水查询条件（零值表示不限）
+type TransactionFilter struct {
+	Reference    string    // 参考号（匹配流水号、业务参考号或端到端参考号，不区分大小写的子串匹配）
+	PurposeCode  string    // 用途代码
+	MinAmount    *float64  // 最小金额（含）
+	MaxAmount    *float64  // 最大金额（含）
+	From         time.Time // 起始时间（含）
+	To           time.Time // 截止时间（不含）
+	Types        []string  // 交易类型，多个时匹配任一
+	Counterparty string    // 对方账户
+	Direction    string    // 借贷方向 credit/debit
+	Status       string    // 交易状态：流水均为已入账交易，仅支持 posted
+
+	SortBy    string // 排序字段 time（默认）/amount
+	Ascending bool   // 升序，默认倒序
+}
+
+// 交易流水状态（流水仅在记账后生成）
+const TX_STATUS_POSTED = "posted"
+
+// 一页交易流水（游标分页，默认按时间倒序）
+type TransactionPage struct {
+	AccountID  string              `json:"accountId"`
+	Limit      int                 `json:"limit"`
+	Items      []model.Transaction `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"` // 下一页游标，为空表示没有更多
+	HasMore    bool                `json:"hasMore"`
+}
+
+// 按条件查询账户的交易流水（按过滤条件指定的顺序，默认按时间倒序）
+func (s *LedgerService) SearchTransactions(accountID string, filter TransactionFilter) ([]model.Transaction, error) {
+	query, err := filter.query()
+	if err != nil {
+		return nil, err
+	}
+	return s.journal.Query(accountID, query), nil
+}
+
+// 按条件游标分页查询账户的交易流水：cursor 为上一页返回的 nextCursor，为空表示第一页（游标须与相同的过滤与排序条件一起使用）。
+// 按账户流水的时间索引定位，翻页开销与流水总量无关，翻页期间新增的流水不会造成重复或遗漏
+func (s *LedgerService) TransactionPage(accountID string, filter TransactionFilter, cursor string, limit int) (TransactionPage, error) {
+	query, err := filter.query()
+	if err != nil {
+		return TransactionPage{}, err
+	}
+	after, err := parseCursor(cursor)
+	if err != nil {
+		return TransactionPage{}, err
+	}
+	limit = cursorLimit(limit)
+	page, ok := s.journal.Page(accountID, query, after.Pos, after.ID, limit)
+	if !ok {
+		return TransactionPage{}, staleCursorError()
+	}
+
+	result := TransactionPage{AccountID: accountID, Limit: limit, Items: page.Items, HasMore: page.More}
+	if page.More {
+		result.NextCursor = pageCursor{Pos: page.LastPos, ID: page.Items[len(page.Items)-1].TxID}.String()
+	}
+	return result, nil
+}
+
+// 校验过滤条件并转换为流水查询条件
+func (f TransactionFilter) query() (repository.JournalQuery, error) {
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return repository.JournalQuery{}, model.NewError(model.CODE_PARAM_ERROR, "最小金额不能大于最大金额")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return repository.JournalQuery{}, model.NewError(model.CODE_PARAM_ERROR, "起始时间不能晚于截止时间")
+	}
+	if f.Status != "" && f.Status != TX_STATUS_POSTED {
+		return repository.JournalQuery{}, model.NewError(model.CODE_PARAM_ERROR, "交易状态仅支持 posted（流水均为已入账交易）")
+	}
+	if f.Direction != "" && f.Direction != "credit" && f.Direction != "debit" {
+		return repository.JournalQuery{}, model.NewError(model.CODE_PARAM_ERROR, "借贷方向仅支持 credit/debit")
+	}
+	sortBy := f.SortBy
+	if sortBy == "" {
+		sortBy = repository.JOURNAL_SORT_TIME
+	}
+	if sortBy != repository.JOURNAL_SORT_TIME && sortBy != repository.JOURNAL_SORT_AMOUNT {
+		return repository.JournalQuery{}, model.NewError(model.CODE_PARAM_ERROR, "排序字段仅支持 time/amount")
+	}
+	return repository.JournalQuery{From: f.From, To: f.To, Match: f.matcher(), SortBy: sortBy, Asc: f.Ascending}, nil
+}
+
+// 过滤条件的匹配函数（不含时间区间，由时间索引定位；无条件时为空）
+func (f TransactionFilter) matcher() func(model.Transaction) bool {
+	reference := strings.ToLower(strings.TrimSpace(f.Reference))
+	purposeCode := strings.ToUpper(strings.TrimSpace(f.PurposeCode))
+	counterparty := strings.TrimSpace(f.Counterparty)
+	types := make(map[string]bool)
+	for _, t := range f.Types {
+		if t = strings.TrimSpace(t); t != "" {
+			types[t] = true
+		}
+	}
+	if reference == "" && purposeCode == "" && counterparty == "" && len(types) == 0 &&
+		f.Direction == "" && f.MinAmount == nil && f.MaxAmount == nil {
+		return nil
+	}
+	return func(tx model.Transaction) bool {
+		if purposeCode != "" && tx.PurposeCode != purposeCode {
+			return false
+		}
+		if counterparty != "" && tx.Counterparty != counterparty {
+			return false
+		}
+		if len(types) > 0 && !types[tx.Type] {
+			return false
+		}
+		if f.Direction != "" && tx.Direction != f.Direction {
+			return false
+		}
+		if (f.MinAmount != nil && tx.Amount < *f.MinAmount) || (f.MaxAmount != nil && tx.Amount > *f.MaxAmount) {
+			return false
+		}
+		return reference == "" || strings.Contains(strings.ToLower(tx.TxID), reference) ||
+			strings.Contains(strings.ToLower(tx.Reference), reference) ||
+			strings.Contains(strings.ToLower(tx.EndToEndID), reference)
+	}
+}
+
+// 计算账户在指定时刻的余额：当前余额减去该时刻之后发生的所有变动
+func (s *LedgerService) BalanceAt(accountID string, at time.Time) (float64, bool) {
+	s.accounts.RLock()
+	defer s.accounts.RUnlock()
+
+	account, exists := s.accounts.Find(accountID)
+	if !exists {
+		return 0, false
+	}
+
+	balance := account.Balance
+	for _, tx := range s.journal.Transactions(accountID, at, time.Time{}) {
+		balance -= tx.SignedAmount()
+	}
+	return balance, true
+}
+
+// 计算账户在 [from, to) 期间的期初/期末余额及借贷发生额
+func (s *LedgerService) PeriodBalance(accountID string, from, to time.Time) (PeriodBalance, bool) {
+	opening, exists := s.BalanceAt(accountID, from)
+	if !exists {
+		return PeriodBalance{}, false
+	}
+	closing, _ := s.BalanceAt(accountID, to)
+
+	period := PeriodBalance{OpeningBalance: opening, ClosingBalance: closing}
+	entries := s.journal.Transactions(accountID, from, to)
+	for _, tx := range entries {
+		if tx.Direction == "credit" {
+			period.CreditTotal += tx.Amount
+		} else {
+			period.DebitTotal += tx.Amount
+		}
+	}
+	period.Count = len(entries)
+	return period, true
+}
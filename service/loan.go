@@ -0,0 +1,1043 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 贷款状态
+const (
+	LOAN_STATUS_ACTIVE   = "active"   // 还款中
+	LOAN_STATUS_PAST_DUE = "past_due" // 逾期（存在逾期未还期次）
+	LOAN_STATUS_PAID_OFF = "paid_off" // 已结清
+)
+
+// 逾期档位（按最早一期逾期的天数划分）
+const (
+	DELINQUENCY_CURRENT = "current" // 未逾期
+	DELINQUENCY_1_30    = "1-30"
+	DELINQUENCY_31_60   = "31-60"
+	DELINQUENCY_60_PLUS = "60+"
+)
+
+// 催收事件类型
+const (
+	COLLECTION_EVENT_PAST_DUE  = "past_due"  // 转入逾期
+	COLLECTION_EVENT_ESCALATED = "escalated" // 逾期档位升级
+	COLLECTION_EVENT_CURED     = "cured"     // 逾期结清，恢复正常
+)
+
+const LOAN_EVENT_LIMIT = 200 // 保留的催收事件条数
+
+// 还款计划状态
+const (
+	INSTALLMENT_PENDING = "pending" // 未到期
+	INSTALLMENT_PAID    = "paid"    // 已还
+	INSTALLMENT_OVERDUE = "overdue" // 逾期未还（日终继续扣款）
+	INSTALLMENT_PREPAID = "prepaid" // 提前结清
+)
+
+// 贷款期限范围（月）
+const (
+	LOAN_MIN_TERM = 3
+	LOAN_MAX_TERM = 60
+)
+
+// 贷款配置
+type LoanConfig struct {
+	PrepaymentFeeRate  float64 // 提前还款违约金费率（按提前偿还的未到期本金计收）
+	PenaltyMarkup      float64 // 罚息上浮比例：罚息年利率 = 合同年利率 × (1 + 上浮比例)，按逾期应还金额逐日计收
+	RetryIntervalDays  int     // 逾期期次重新扣款的间隔（天）
+	OriginationFeeRate float64 // 贷款手续费费率（按放款金额计收，放款后立即从账户扣收）
+}
+
+// 贷款申请请求结构体
+type LoanApplication struct {
+	AccountID  string  `json:"accountId" validate:"required,account"`
+	Amount     float64 `json:"amount" validate:"amount"`
+	TermMonths int     `json:"termMonths"`
+}
+
+// 还款计划（等额本息，按月还款）
+type Installment struct {
+	No        int     `json:"no"`
+	DueDate   string  `json:"dueDate"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Amount    float64 `json:"amount"`
+	Penalty   float64 `json:"penalty,omitempty"` // 累计罚息（随本期一并扣收）
+	Status    string  `json:"status"`            // pending/paid/overdue/prepaid
+	PaidAt    string  `json:"paidAt,omitempty"`
+}
+
+// 贷款
+type Loan struct {
+	LoanID         string        `json:"loanId"`
+	AccountID      string        `json:"accountId"`
+	Principal      float64       `json:"principal"`
+	OriginationFee float64       `json:"originationFee,omitempty"` // 放款时扣收的贷款手续费
+	Currency       string        `json:"currency"`
+	AnnualRate     float64       `json:"annualRate"`
+	TermMonths     int           `json:"termMonths"`
+	Outstanding    float64       `json:"outstanding"` // 剩余本金
+	Status         string        `json:"status"`      // active/past_due/paid_off
+	DaysPastDue    int           `json:"daysPastDue"` // 最早一期逾期的天数
+	Delinquency    string        `json:"delinquency"` // 逾期档位 current/1-30/31-60/60+
+	NextRetry      string        `json:"nextRetry,omitempty"`
+	Score          int           `json:"score"` // 审批时的信用评分
+	Grade          string        `json:"grade"`
+	Installments   []Installment `json:"installments"`
+	CreatedAt      string        `json:"createdAt"`
+	PrepaidAt      string        `json:"prepaidAt,omitempty"` // 最近一次提前还款日期（利息已结至该日）
+	PaidOffAt      string        `json:"paidOffAt,omitempty"`
+}
+
+// 提前还款试算
+type PayoffQuote struct {
+	LoanID             string  `json:"loanId"`
+	AsOf               string  `json:"asOf"`               // 试算时间（模拟时钟）
+	RemainingPrincipal float64 `json:"remainingPrincipal"` // 剩余本金
+	OverduePrincipal   float64 `json:"overduePrincipal"`   // 其中已到期未还本金（不收违约金）
+	AccruedInterest    float64 `json:"accruedInterest"`    // 逾期期次利息及自 InterestFrom 起按日计提的利息
+	Penalty            float64 `json:"penalty"`            // 逾期期次累计罚息
+	InterestFrom       string  `json:"interestFrom"`
+	FeeRate            float64 `json:"feeRate"`
+	EarlyRepaymentFee  float64 `json:"earlyRepaymentFee"` // 按未到期本金计收的违约金
+	Total              float64 `json:"total"`             // 全额结清应还金额
+}
+
+// 提前还款请求结构体（Full 为 true 或金额不低于结清金额时全额结清）
+type LoanRepayRequest struct {
+	Amount float64 `json:"amount" validate:"omitempty,amount"` // full 为 true 时忽略
+	Full   bool    `json:"full"`
+}
+
+// 提前还款结果
+type LoanRepayResult struct {
+	Loan      Loan    `json:"loan"`
+	Amount    float64 `json:"amount"`    // 实际扣款金额
+	Principal float64 `json:"principal"` // 偿还本金
+	Interest  float64 `json:"interest"`  // 结清利息
+	Penalty   float64 `json:"penalty"`   // 结清罚息
+	Fee       float64 `json:"fee"`       // 违约金
+	PaidOff   bool    `json:"paidOff"`
+	Balance   float64 `json:"balance"` // 账户余额
+}
+
+// 贷款服务：按信用评分自动审批、放款与日终按期扣款
+type LoanService struct {
+	cfg       LoanConfig
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	credit    *CreditService
+	sagas     *SagaService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu     sync.Mutex // 需先于账户锁获取
+	loans  map[string]*Loan
+	seq    int
+	events []DelinquencyEvent
+}
+
+// 催收事件（逾期档位变化）
+type DelinquencyEvent struct {
+	Event         string  `json:"event"` // past_due/escalated/cured
+	LoanID        string  `json:"loanId"`
+	AccountID     string  `json:"accountId"`
+	UserName      string  `json:"userName"`
+	From          string  `json:"from"` // 原逾期档位
+	To            string  `json:"to"`   // 新逾期档位
+	DaysPastDue   int     `json:"daysPastDue"`
+	OverdueAmount float64 `json:"overdueAmount"` // 逾期应还合计（含罚息）
+	Date          string  `json:"date"`          // 业务日期
+}
+
+// 催收看板中的逾期贷款
+type DelinquentLoan struct {
+	LoanID              string  `json:"loanId"`
+	AccountID           string  `json:"accountId"`
+	DaysPastDue         int     `json:"daysPastDue"`
+	Delinquency         string  `json:"delinquency"`
+	OverdueInstallments int     `json:"overdueInstallments"`
+	OverdueAmount       float64 `json:"overdueAmount"` // 逾期应还合计（含罚息）
+	Penalty             float64 `json:"penalty"`
+	Outstanding         float64 `json:"outstanding"`
+	NextRetry           string  `json:"nextRetry"`
+}
+
+// 催收看板
+type CollectionsReport struct {
+	AsOf          string             `json:"asOf"`
+	Buckets       map[string]int     `json:"buckets"`       // 各逾期档位贷款笔数
+	OverdueAmount float64            `json:"overdueAmount"` // 全部逾期应还合计（含罚息）
+	Loans         []DelinquentLoan   `json:"loans"`         // 按逾期天数倒序
+	Events        []DelinquencyEvent `json:"events"`        // 最近的催收事件，按发生先后倒序
+}
+
+func NewLoanService(cfg LoanConfig, accounts *repository.AccountRepository, ledger *LedgerService, credit *CreditService, sagas *SagaService, clock Clock, notifier Notifier, templates *TemplateRegistry) *LoanService {
+	return &LoanService{
+		cfg:       cfg,
+		accounts:  accounts,
+		ledger:    ledger,
+		credit:    credit,
+		sagas:     sagas,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		loans:     make(map[string]*Loan),
+	}
+}
+
+// 贷款申请：信用评分自动审批，通过后立即放款至账户
+func (s *LoanService) Apply(req LoanApplication) (Loan, error) {
+	if err := model.Validate(req); err != nil {
+		return Loan{}, err
+	}
+	if req.TermMonths < LOAN_MIN_TERM || req.TermMonths > LOAN_MAX_TERM {
+		return Loan{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("贷款期限应在 %d 到 %d 个月之间", LOAN_MIN_TERM, LOAN_MAX_TERM))
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return Loan{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if account.Currency != model.BASE_CURRENCY {
+		return Loan{}, model.NewError(model.CODE_PARAM_ERROR, "仅支持人民币账户申请贷款")
+	}
+
+	decision, err := s.credit.Evaluate(req.AccountID, req.Amount)
+	if err != nil {
+		return Loan{}, err
+	}
+	if !decision.Approved {
+		// 终端提示：贷款审批拒绝
+		log.Println("\n[❌ 贷款审批 - 拒绝]")
+		log.Printf("账户ID: %s", account.AccountID)
+		log.Printf("用户名: %s", account.UserName)
+		log.Printf("申请金额: %.2f 元，期限 %d 个月", req.Amount, req.TermMonths)
+		log.Printf("信用评分: %d（%s）", decision.Score, decision.Grade)
+		log.Printf("拒绝原因: %s", decision.Reason)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		return Loan{}, model.NewError(model.CODE_RISK_CONTROL_REJECT, "贷款申请未通过："+decision.Reason)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	loan := &Loan{
+		AccountID:   req.AccountID,
+		Principal:   req.Amount,
+		Currency:    account.Currency,
+		AnnualRate:  decision.AnnualRate,
+		TermMonths:  req.TermMonths,
+		Status:      LOAN_STATUS_ACTIVE,
+		Delinquency: DELINQUENCY_CURRENT,
+		Score:       decision.Score,
+		Grade:       decision.Grade,
+		CreatedAt:   now.Format("2006-01-02 15:04:05"),
+	}
+
+	s.seq++
+	loan.LoanID = fmt.Sprintf("LN%s%04d", now.Format("20060102"), s.seq)
+	loan.OriginationFee = model.RoundAmount(req.Amount * s.cfg.OriginationFeeRate)
+
+	// 放款与扣收手续费分步执行，手续费扣收失败时编排服务冲正放款，不留下无手续费的贷款
+	steps := []SagaStep{{
+		Name: "disburse",
+		Action: func() error {
+			account, err = s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+				if err := dormantError(*account); err != nil {
+					return err
+				}
+				if account.Status != "normal" {
+					return model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法放款")
+				}
+				account.Balance = model.RoundAmount(account.Balance + req.Amount)
+				return nil
+			}, func(account model.Account) {
+				s.ledger.Record(model.Transaction{
+					AccountID:    req.AccountID,
+					Type:         "loan_disbursement",
+					Direction:    "credit",
+					Amount:       req.Amount,
+					BalanceAfter: account.Balance,
+					Reference:    loan.LoanID,
+					Description:  fmt.Sprintf("贷款放款（%d 期）", req.TermMonths),
+				})
+			})
+			return err
+		},
+		Compensate: func() error {
+			// 冲正不校验账户状态与余额：放款资金须全额收回
+			var err error
+			account, err = s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+				account.Balance = model.RoundAmount(account.Balance - req.Amount)
+				return nil
+			}, func(account model.Account) {
+				s.ledger.Record(model.Transaction{
+					AccountID:    req.AccountID,
+					Type:         "loan_reversal",
+					Direction:    "debit",
+					Amount:       req.Amount,
+					BalanceAfter: account.Balance,
+					Reference:    loan.LoanID,
+					Description:  "贷款放款冲正",
+				})
+			})
+			return err
+		},
+	}}
+	if loan.OriginationFee > 0 {
+		steps = append(steps, SagaStep{
+			Name: "origination_fee",
+			Action: func() error {
+				account, err = s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+					if account.Status != "normal" {
+						return model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法扣收贷款手续费")
+					}
+					account.Balance = model.RoundAmount(account.Balance - loan.OriginationFee)
+					return nil
+				}, func(account model.Account) {
+					s.ledger.Record(model.Transaction{
+						AccountID:    req.AccountID,
+						Type:         "fee",
+						Direction:    "debit",
+						Amount:       loan.OriginationFee,
+						BalanceAfter: account.Balance,
+						Reference:    loan.LoanID,
+						Description:  fmt.Sprintf("贷款手续费（费率 %.2f%%）", s.cfg.OriginationFeeRate*100),
+					})
+				})
+				return err
+			},
+		})
+	}
+	if _, err := s.sagas.Run(SAGA_TYPE_LOAN, loan.LoanID, nil, steps...); err != nil {
+		return Loan{}, err
+	}
+	loan.Outstanding = req.Amount
+	loan.Installments = amortize(req.Amount, decision.AnnualRate, req.TermMonths, now)
+	s.loans[loan.LoanID] = loan
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_LOAN_DISBURSED, map[string]interface{}{
+		"Amount":      req.Amount,
+		"Balance":     account.Balance,
+		"Reference":   loan.LoanID,
+		"Terms":       req.TermMonths,
+		"Installment": loan.Installments[0].Amount,
+	}))
+
+	// 终端提示：贷款放款
+	log.Println("\n[🏦 贷款放款]")
+	log.Printf("贷款编号: %s", loan.LoanID)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("信用评分: %d（%s），年利率 %.2f%%", decision.Score, decision.Grade, decision.AnnualRate*100)
+	log.Printf("放款金额: \033[1;32m%.2f 元\033[0m，期限 %d 个月，月供 %.2f 元", req.Amount, req.TermMonths, loan.Installments[0].Amount) // 绿色高亮
+	if loan.OriginationFee > 0 {
+		log.Printf("贷款手续费: %.2f 元", loan.OriginationFee)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return copyLoan(loan), nil
+}
+
+// 查询贷款
+func (s *LoanService) Get(loanID string) (Loan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loan, ok := s.loans[loanID]
+	if !ok {
+		return Loan{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "贷款不存在")
+	}
+	return copyLoan(loan), nil
+}
+
+// 账户的贷款列表（按贷款编号排序），accountID 为空表示全部
+func (s *LoanService) List(accountID string) []Loan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Loan{}
+	for _, loan := range s.loans {
+		if accountID == "" || loan.AccountID == accountID {
+			result = append(result, copyLoan(loan))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LoanID < result[j].LoanID })
+	return result
+}
+
+// 提前还款试算：截至当前模拟时间的剩余本金、应计利息与违约金
+func (s *LoanService) PayoffQuote(loanID string) (PayoffQuote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loan, ok := s.loans[loanID]
+	if !ok {
+		return PayoffQuote{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "贷款不存在")
+	}
+	if loan.Status == LOAN_STATUS_PAID_OFF {
+		return PayoffQuote{}, model.NewError(model.CODE_PARAM_ERROR, "贷款已结清")
+	}
+	return s.payoffQuote(loan, s.clock.Now()), nil
+}
+
+// 提前还款：部分还款按利息、已到期本金、未到期本金（含违约金）顺序冲抵并重算还款计划，足额时全部结清
+func (s *LoanService) Repay(loanID string, req LoanRepayRequest) (LoanRepayResult, error) {
+	if err := model.Validate(req); err != nil {
+		return LoanRepayResult{}, err
+	}
+	if !req.Full && req.Amount == 0 {
+		return LoanRepayResult{}, model.NewError(model.CODE_PARAM_ERROR, "请指定还款金额或全部结清")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loan, ok := s.loans[loanID]
+	if !ok {
+		return LoanRepayResult{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "贷款不存在")
+	}
+	if loan.Status == LOAN_STATUS_PAID_OFF {
+		return LoanRepayResult{}, model.NewError(model.CODE_PARAM_ERROR, "贷款已结清")
+	}
+
+	now := s.clock.Now()
+	quote := s.payoffQuote(loan, now)
+	result := LoanRepayResult{Interest: quote.AccruedInterest, Penalty: quote.Penalty}
+	amount := model.RoundAmount(req.Amount)
+	if req.Full || amount >= quote.Total {
+		result.PaidOff = true
+		result.Amount = quote.Total
+		result.Principal = quote.RemainingPrincipal
+		result.Fee = quote.EarlyRepaymentFee
+	} else {
+		// 部分还款须先结清应计利息、罚息与已到期本金（还款计划重算后逾期期次随之结清）
+		rest := model.RoundAmount(amount - quote.AccruedInterest - quote.Penalty - quote.OverduePrincipal)
+		if rest <= 0 {
+			return LoanRepayResult{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("还款金额须大于应计利息、罚息与逾期本金合计 %.2f 元",
+				model.RoundAmount(quote.AccruedInterest+quote.Penalty+quote.OverduePrincipal)))
+		}
+		prepaid := model.RoundAmount(rest / (1 + s.cfg.PrepaymentFeeRate))
+		result.Amount = amount
+		result.Principal = model.RoundAmount(quote.OverduePrincipal + prepaid)
+		result.Fee = model.RoundAmount(rest - prepaid)
+	}
+
+	account, err := s.accounts.Update(loan.AccountID, 0, func(account *model.Account) error {
+		if err := dormantError(*account); err != nil {
+			return err
+		}
+		if account.Status != "normal" {
+			return model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法还款")
+		}
+		if account.Available() < result.Amount {
+			s.credit.RecordNSF(account.AccountID)
+			return model.NewError(model.CODE_BALANCE_NOT_ENOUGH, fmt.Sprintf("可用余额不足，本次还款需 %.2f 元", result.Amount))
+		}
+		account.Balance = model.RoundAmount(account.Balance - result.Amount)
+		return nil
+	}, func(account model.Account) {
+		balance := model.RoundAmount(account.Balance + result.Interest + result.Penalty + result.Fee)
+		s.ledger.Record(model.Transaction{
+			AccountID:    loan.AccountID,
+			Type:         "loan_principal",
+			Direction:    "debit",
+			Amount:       result.Principal,
+			BalanceAfter: balance,
+			Reference:    loan.LoanID,
+			Description:  "贷款提前还本",
+		})
+		if result.Interest > 0 {
+			balance = model.RoundAmount(balance - result.Interest)
+			s.ledger.Record(model.Transaction{
+				AccountID:    loan.AccountID,
+				Type:         "loan_interest",
+				Direction:    "debit",
+				Amount:       result.Interest,
+				BalanceAfter: balance,
+				Reference:    loan.LoanID,
+				Description:  "贷款提前还款结息（截至 " + now.Format("2006-01-02") + "）",
+			})
+		}
+		if result.Penalty > 0 {
+			balance = model.RoundAmount(balance - result.Penalty)
+			s.ledger.Record(model.Transaction{
+				AccountID:    loan.AccountID,
+				Type:         "loan_penalty",
+				Direction:    "debit",
+				Amount:       result.Penalty,
+				BalanceAfter: balance,
+				Reference:    loan.LoanID,
+				Description:  "贷款逾期罚息",
+			})
+		}
+		if result.Fee > 0 {
+			s.ledger.Record(model.Transaction{
+				AccountID:    loan.AccountID,
+				Type:         "fee",
+				Direction:    "debit",
+				Amount:       result.Fee,
+				BalanceAfter: account.Balance,
+				Reference:    loan.LoanID,
+				Description:  fmt.Sprintf("提前还款违约金（费率 %.2f%%）", s.cfg.PrepaymentFeeRate*100),
+			})
+		}
+	})
+	if err != nil {
+		return LoanRepayResult{}, err
+	}
+
+	today := now.Format("2006-01-02")
+	loan.Outstanding = model.RoundAmount(loan.Outstanding - result.Principal)
+	loan.PrepaidAt = today
+	if result.PaidOff || loan.Outstanding <= 0 {
+		result.PaidOff = true
+		loan.Outstanding = 0
+		loan.Status = LOAN_STATUS_PAID_OFF
+		loan.PaidOffAt = now.Format("2006-01-02 15:04:05")
+		for i := range loan.Installments {
+			if loan.Installments[i].Status != INSTALLMENT_PAID {
+				loan.Installments[i].Status = INSTALLMENT_PREPAID
+				loan.Installments[i].PaidAt = loan.PaidOffAt
+			}
+		}
+	} else {
+		reschedule(loan, now)
+	}
+	event, changed := s.updateDelinquency(loan, account, today, false)
+	result.Loan = copyLoan(loan)
+	result.Balance = account.Balance
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_LOAN_PREPAID, map[string]interface{}{
+		"Amount":      result.Amount,
+		"Principal":   result.Principal,
+		"Interest":    result.Interest,
+		"Penalty":     result.Penalty,
+		"Fee":         result.Fee,
+		"Outstanding": loan.Outstanding,
+		"PaidOff":     result.PaidOff,
+		"Balance":     account.Balance,
+		"Reference":   loan.LoanID,
+	}))
+	if changed {
+		s.notifier.Publish("collections", "delinquency", event)
+	}
+
+	// 终端提示：贷款提前还款
+	log.Println("\n[🏦 贷款提前还款]")
+	log.Printf("贷款编号: %s", loan.LoanID)
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("还款金额: \033[1;33m%.2f 元\033[0m（本金 %.2f，利息 %.2f，罚息 %.2f，违约金 %.2f）", result.Amount, result.Principal, result.Interest, result.Penalty, result.Fee) // 黄色高亮
+	if result.PaidOff {
+		log.Printf("贷款状态: \033[1;32m已结清\033[0m")
+	} else {
+		log.Printf("剩余本金: %.2f 元，剩余 %d 期，月供 %.2f 元", loan.Outstanding, len(loan.Installments)-paidCount(loan), loan.Installments[len(loan.Installments)-1].Amount)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 结清试算（调用方持有 s.mu）
+func (s *LoanService) payoffQuote(loan *Loan, now time.Time) PayoffQuote {
+	today := now.Format("2006-01-02")
+
+	// 计息起点：最近一个已到期的还款日、最近一次提前还款日与放款日中的最晚者
+	from := loan.CreatedAt[:10]
+	if loan.PrepaidAt > from {
+		from = loan.PrepaidAt
+	}
+	quote := PayoffQuote{
+		LoanID:             loan.LoanID,
+		AsOf:               now.Format("2006-01-02 15:04:05"),
+		RemainingPrincipal: loan.Outstanding,
+		FeeRate:            s.cfg.PrepaymentFeeRate,
+	}
+	interest := 0.0
+	for _, inst := range loan.Installments {
+		if inst.DueDate > today {
+			continue
+		}
+		if inst.DueDate > from {
+			from = inst.DueDate
+		}
+		if inst.Status == INSTALLMENT_OVERDUE {
+			quote.OverduePrincipal += inst.Principal
+			quote.Penalty += inst.Penalty
+			interest += inst.Interest
+		}
+	}
+	start, _ := time.ParseInLocation("2006-01-02", from, time.Local)
+	if days := int(sim.StartOfDay(now).Sub(start).Hours() / 24); days > 0 {
+		interest += loan.Outstanding * loan.AnnualRate / DAYS_PER_YEAR * float64(days)
+	}
+	quote.InterestFrom = from
+	quote.OverduePrincipal = model.RoundAmount(quote.OverduePrincipal)
+	quote.AccruedInterest = model.RoundAmount(interest)
+	quote.Penalty = model.RoundAmount(quote.Penalty)
+	quote.EarlyRepaymentFee = model.RoundAmount((loan.Outstanding - quote.OverduePrincipal) * s.cfg.PrepaymentFeeRate)
+	quote.Total = model.RoundAmount(quote.RemainingPrincipal + quote.AccruedInterest + quote.Penalty + quote.EarlyRepaymentFee)
+	return quote
+}
+
+// 日终任务：逐日计收逾期罚息，扣收到期期次；余额不足的期次转为逾期，
+// 之后按重试间隔重新扣款，并按最早一期逾期的天数调整逾期档位
+func (s *LoanService) Collect(day time.Time) {
+	date := day.Format("2006-01-02")
+
+	type collection struct {
+		loan        *Loan
+		installment Installment
+		account     model.Account
+		paid        bool
+	}
+	var results []collection
+	var events []DelinquencyEvent
+
+	s.mu.Lock()
+	s.accounts.Lock()
+	ids := make([]string, 0, len(s.loans))
+	for id := range s.loans {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		loan := s.loans[id]
+		if loan.Status == LOAN_STATUS_PAID_OFF {
+			continue
+		}
+		account, exists := s.accounts.Find(loan.AccountID)
+		if !exists {
+			continue
+		}
+
+		// 此前已逾期的期次计收一天罚息
+		penaltyRate := loan.AnnualRate * (1 + s.cfg.PenaltyMarkup) / DAYS_PER_YEAR
+		for i := range loan.Installments {
+			if inst := &loan.Installments[i]; inst.Status == INSTALLMENT_OVERDUE {
+				inst.Penalty = model.RoundAmount(inst.Penalty + inst.Amount*penaltyRate)
+			}
+		}
+
+		// 逾期贷款未到重试日时只登记新到期的期次，不扣款
+		collectable := loan.NextRetry == "" || date >= loan.NextRetry
+		failed := false
+		for i := range loan.Installments {
+			inst := &loan.Installments[i]
+			if inst.Status != INSTALLMENT_PENDING && inst.Status != INSTALLMENT_OVERDUE || inst.DueDate > date {
+				continue
+			}
+			due := model.RoundAmount(inst.Amount + inst.Penalty)
+			if !collectable || account.Available() < due {
+				if collectable {
+					failed = true
+				}
+				collectable = false // 前一期未还清时不扣收后续期次
+				// 首次扣款失败计一次逾期
+				if inst.Status == INSTALLMENT_PENDING {
+					inst.Status = INSTALLMENT_OVERDUE
+					s.credit.RecordRepayment(loan.AccountID, false)
+					results = append(results, collection{loan: loan, installment: *inst, account: account})
+				}
+				continue
+			}
+
+			account.Balance = model.RoundAmount(account.Balance - due)
+			account = s.accounts.Save(account)
+			s.recordInstallment(loan, *inst, account)
+			if inst.Status == INSTALLMENT_PENDING {
+				s.credit.RecordRepayment(loan.AccountID, true)
+			}
+			inst.Status = INSTALLMENT_PAID
+			inst.PaidAt = s.clock.Now().Format("2006-01-02 15:04:05")
+			loan.Outstanding = model.RoundAmount(loan.Outstanding - inst.Principal)
+			if i == len(loan.Installments)-1 {
+				loan.Status = LOAN_STATUS_PAID_OFF
+				loan.Outstanding = 0
+			}
+			results = append(results, collection{loan: loan, installment: *inst, account: account, paid: true})
+		}
+		if event, changed := s.updateDelinquency(loan, account, date, failed); changed {
+			events = append(events, event)
+		}
+	}
+	s.accounts.Unlock()
+	s.mu.Unlock()
+
+	for _, r := range results {
+		vars := map[string]interface{}{
+			"Amount":    model.RoundAmount(r.installment.Amount + r.installment.Penalty),
+			"Balance":   r.account.Balance,
+			"Reference": r.loan.LoanID,
+			"No":        r.installment.No,
+			"Terms":     r.loan.TermMonths,
+			"DueDate":   r.installment.DueDate,
+		}
+		if r.paid {
+			s.notifier.Send(ws.Message{
+				Type:       "balanceUpdate",
+				AccountID:  r.account.AccountID,
+				NewBalance: r.account.Balance,
+			})
+			s.notifier.Send(s.templates.Alert("transactionAlert", r.account, EVENT_LOAN_REPAID, vars))
+		} else {
+			s.notifier.Send(s.templates.Alert("transactionAlert", r.account, EVENT_LOAN_OVERDUE, vars))
+		}
+	}
+	for _, event := range events {
+		s.notifier.Publish("collections", "delinquency", event)
+	}
+
+	if len(results) > 0 {
+		// 终端提示：贷款扣款
+		log.Println("\n[🏦 贷款扣款]")
+		log.Printf("扣款日期: %s", date)
+		for _, r := range results {
+			result := "\033[1;32m成功\033[0m"
+			if !r.paid {
+				result = "\033[1;31m余额不足，已逾期\033[0m"
+			}
+			log.Printf("贷款编号: %s | 账户ID: %s | 第 %d/%d 期 | 应还: %.2f 元 | %s",
+				r.loan.LoanID, r.account.AccountID, r.installment.No, r.loan.TermMonths, r.installment.Amount+r.installment.Penalty, result)
+		}
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	}
+	if len(events) > 0 {
+		// 终端提示：逾期档位变化
+		log.Println("\n[📞 贷款催收]")
+		log.Printf("日期: %s", date)
+		for _, event := range events {
+			log.Printf("贷款编号: %s | 账户ID: %s | 逾期档位: %s → %s | 逾期 %d 天 | 逾期应还: %.2f 元",
+				event.LoanID, event.AccountID, event.From, event.To, event.DaysPastDue, event.OverdueAmount)
+		}
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	}
+}
+
+// 催收看板：当前逾期贷款、各档位笔数与最近的催收事件
+func (s *LoanService) Collections() CollectionsReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := CollectionsReport{
+		AsOf: s.clock.Now().Format("2006-01-02 15:04:05"),
+		Buckets: map[string]int{
+			DELINQUENCY_1_30:    0,
+			DELINQUENCY_31_60:   0,
+			DELINQUENCY_60_PLUS: 0,
+		},
+		Loans:  []DelinquentLoan{},
+		Events: []DelinquencyEvent{},
+	}
+	for _, loan := range s.loans {
+		if loan.Status != LOAN_STATUS_PAST_DUE {
+			continue
+		}
+		item := DelinquentLoan{
+			LoanID:      loan.LoanID,
+			AccountID:   loan.AccountID,
+			DaysPastDue: loan.DaysPastDue,
+			Delinquency: loan.Delinquency,
+			Outstanding: loan.Outstanding,
+			NextRetry:   loan.NextRetry,
+		}
+		for _, inst := range loan.Installments {
+			if inst.Status == INSTALLMENT_OVERDUE {
+				item.OverdueInstallments++
+				item.Penalty += inst.Penalty
+			}
+		}
+		item.Penalty = model.RoundAmount(item.Penalty)
+		item.OverdueAmount = overdueAmount(loan)
+		report.Buckets[loan.Delinquency]++
+		report.OverdueAmount += item.OverdueAmount
+		report.Loans = append(report.Loans, item)
+	}
+	report.OverdueAmount = model.RoundAmount(report.OverdueAmount)
+	sort.Slice(report.Loans, func(i, j int) bool {
+		if report.Loans[i].DaysPastDue != report.Loans[j].DaysPastDue {
+			return report.Loans[i].DaysPastDue > report.Loans[j].DaysPastDue
+		}
+		return report.Loans[i].LoanID < report.Loans[j].LoanID
+	})
+	for i := len(s.events) - 1; i >= 0; i-- {
+		report.Events = append(report.Events, s.events[i])
+	}
+	return report
+}
+
+// 按最早一期逾期的天数更新贷款状态与逾期档位，本次扣款失败时安排下次重试；
+// 档位变化时记录并返回催收事件（调用方持有 s.mu）
+func (s *LoanService) updateDelinquency(loan *Loan, account model.Account, date string, failed bool) (DelinquencyEvent, bool) {
+	previous := loan.Delinquency
+	loan.DaysPastDue = 0
+	loan.Delinquency = DELINQUENCY_CURRENT
+	for _, inst := range loan.Installments {
+		if inst.Status != INSTALLMENT_OVERDUE {
+			continue
+		}
+		due, _ := time.ParseInLocation("2006-01-02", inst.DueDate, time.Local)
+		today, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		loan.DaysPastDue = int(today.Sub(due).Hours()/24) + 1 // 到期日日终扣款失败即逾期第 1 天
+		loan.Delinquency = delinquencyBucket(loan.DaysPastDue)
+		break
+	}
+
+	if loan.Delinquency == DELINQUENCY_CURRENT {
+		loan.NextRetry = ""
+		if loan.Status == LOAN_STATUS_PAST_DUE {
+			loan.Status = LOAN_STATUS_ACTIVE
+		}
+	} else {
+		loan.Status = LOAN_STATUS_PAST_DUE
+		if failed {
+			day, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+			loan.NextRetry = day.AddDate(0, 0, s.cfg.RetryIntervalDays).Format("2006-01-02")
+		}
+	}
+	if previous == "" {
+		previous = DELINQUENCY_CURRENT
+	}
+	if loan.Delinquency == previous {
+		return DelinquencyEvent{}, false
+	}
+
+	event := DelinquencyEvent{
+		Event:         COLLECTION_EVENT_ESCALATED,
+		LoanID:        loan.LoanID,
+		AccountID:     loan.AccountID,
+		UserName:      account.UserName,
+		From:          previous,
+		To:            loan.Delinquency,
+		DaysPastDue:   loan.DaysPastDue,
+		OverdueAmount: overdueAmount(loan),
+		Date:          date,
+	}
+	switch {
+	case loan.Delinquency == DELINQUENCY_CURRENT:
+		event.Event = COLLECTION_EVENT_CURED
+	case previous == DELINQUENCY_CURRENT:
+		event.Event = COLLECTION_EVENT_PAST_DUE
+	}
+	s.events = append(s.events, event)
+	if len(s.events) > LOAN_EVENT_LIMIT {
+		s.events = s.events[len(s.events)-LOAN_EVENT_LIMIT:]
+	}
+	return event, true
+}
+
+// 逾期档位
+func delinquencyBucket(days int) string {
+	switch {
+	case days <= 0:
+		return DELINQUENCY_CURRENT
+	case days <= 30:
+		return DELINQUENCY_1_30
+	case days <= 60:
+		return DELINQUENCY_31_60
+	default:
+		return DELINQUENCY_60_PLUS
+	}
+}
+
+// 逾期应还合计（含罚息）
+func overdueAmount(loan *Loan) float64 {
+	total := 0.0
+	for _, inst := range loan.Installments {
+		if inst.Status == INSTALLMENT_OVERDUE {
+			total += inst.Amount + inst.Penalty
+		}
+	}
+	return model.RoundAmount(total)
+}
+
+// 记录一期还款流水：本金冲减贷款，利息与罚息计入利息收入（调用方持有账户锁）
+func (s *LoanService) recordInstallment(loan *Loan, inst Installment, account model.Account) {
+	s.ledger.Record(model.Transaction{
+		AccountID:    loan.AccountID,
+		Type:         "loan_principal",
+		Direction:    "debit",
+		Amount:       inst.Principal,
+		BalanceAfter: model.RoundAmount(account.Balance + inst.Interest + inst.Penalty),
+		Reference:    loan.LoanID,
+		Description:  fmt.Sprintf("贷款还本（第 %d/%d 期）", inst.No, loan.TermMonths),
+	})
+	if inst.Interest > 0 {
+		s.ledger.Record(model.Transaction{
+			AccountID:    loan.AccountID,
+			Type:         "loan_interest",
+			Direction:    "debit",
+			Amount:       inst.Interest,
+			BalanceAfter: model.RoundAmount(account.Balance + inst.Penalty),
+			Reference:    loan.LoanID,
+			Description:  fmt.Sprintf("贷款利息（第 %d/%d 期）", inst.No, loan.TermMonths),
+		})
+	}
+	if inst.Penalty > 0 {
+		s.ledger.Record(model.Transaction{
+			AccountID:    loan.AccountID,
+			Type:         "loan_penalty",
+			Direction:    "debit",
+			Amount:       inst.Penalty,
+			BalanceAfter: account.Balance,
+			Reference:    loan.LoanID,
+			Description:  fmt.Sprintf("贷款逾期罚息（第 %d/%d 期）", inst.No, loan.TermMonths),
+		})
+	}
+}
+
+// 部分提前还款后重算还款计划：未还期次按剩余本金重新等额本息，保留原未到期还款日，
+// 首期利息自提前还款日按日计算（原还款日均已到期时顺延一个月）
+func reschedule(loan *Loan, now time.Time) {
+	today := now.Format("2006-01-02")
+	paid := paidCount(loan)
+	var dueDates []string
+	for _, inst := range loan.Installments[paid:] {
+		if inst.DueDate > today {
+			dueDates = append(dueDates, inst.DueDate)
+		}
+	}
+	if len(dueDates) == 0 {
+		dueDates = []string{now.AddDate(0, 1, 0).Format("2006-01-02")}
+	}
+
+	rate := loan.AnnualRate / 12
+	months := len(dueDates)
+	payment := loan.Outstanding / float64(months)
+	if rate > 0 {
+		payment = loan.Outstanding * rate / (1 - math.Pow(1+rate, -float64(months)))
+	}
+	payment = model.RoundAmount(payment)
+
+	installments := loan.Installments[:paid:paid]
+	remaining := loan.Outstanding
+	for i, dueDate := range dueDates {
+		interest := model.RoundAmount(remaining * rate)
+		if i == 0 {
+			due, _ := time.ParseInLocation("2006-01-02", dueDate, time.Local)
+			days := int(due.Sub(sim.StartOfDay(now)).Hours() / 24)
+			interest = model.RoundAmount(remaining * loan.AnnualRate / DAYS_PER_YEAR * float64(days))
+		}
+		principalPart := model.RoundAmount(payment - interest)
+		if i == months-1 || principalPart > remaining {
+			principalPart = model.RoundAmount(remaining)
+		}
+		remaining = model.RoundAmount(remaining - principalPart)
+		installments = append(installments, Installment{
+			No:        paid + i + 1,
+			DueDate:   dueDate,
+			Principal: principalPart,
+			Interest:  interest,
+			Amount:    model.RoundAmount(principalPart + interest),
+			Status:    INSTALLMENT_PENDING,
+		})
+	}
+	loan.Installments = installments
+	loan.TermMonths = len(installments)
+}
+
+// 已还期数（已还期次总在还款计划前部）
+func paidCount(loan *Loan) int {
+	for i, inst := range loan.Installments {
+		if inst.Status != INSTALLMENT_PAID {
+			return i
+		}
+	}
+	return len(loan.Installments)
+}
+
+// 等额本息还款计划：首期为放款日次月同日，末期本金轧差
+func amortize(principal, annualRate float64, months int, start time.Time) []Installment {
+	rate := annualRate / 12
+	payment := principal / float64(months)
+	if rate > 0 {
+		payment = principal * rate / (1 - math.Pow(1+rate, -float64(months)))
+	}
+	payment = model.RoundAmount(payment)
+
+	installments := make([]Installment, 0, months)
+	remaining := principal
+	for i := 1; i <= months; i++ {
+		interest := model.RoundAmount(remaining * rate)
+		principalPart := model.RoundAmount(payment - interest)
+		if i == months {
+			principalPart = model.RoundAmount(remaining)
+		}
+		remaining = model.RoundAmount(remaining - principalPart)
+		installments = append(installments, Installment{
+			No:        i,
+			DueDate:   start.AddDate(0, i, 0).Format("2006-01-02"),
+			Principal: principalPart,
+			Interest:  interest,
+			Amount:    model.RoundAmount(principalPart + interest),
+			Status:    INSTALLMENT_PENDING,
+		})
+	}
+	return installments
+}
+
+// 贷款副本（还款计划独立拷贝）
+func copyLoan(loan *Loan) Loan {
+	result := *loan
+	result.Installments = append([]Installment{}, loan.Installments...)
+	return result
+}
+
+// 贷款快照（随状态快照导出）
+type LoanSnapshot struct {
+	Loans  []Loan             `json:"loans"`
+	Seq    int                `json:"seq"`
+	Events []DelinquencyEvent `json:"events,omitempty"`
+}
+
+// 导出贷款与还款计划（调用方需持有 s.mu，并在其后获取账户锁，使剩余本金与账户余额取自同一时刻）
+func (s *LoanService) snapshot() LoanSnapshot {
+	snapshot := LoanSnapshot{Loans: make([]Loan, 0, len(s.loans)), Seq: s.seq, Events: append([]DelinquencyEvent{}, s.events...)}
+	for _, loan := range s.loans {
+		item := *loan
+		item.Installments = append([]Installment{}, loan.Installments...)
+		snapshot.Loans = append(snapshot.Loans, item)
+	}
+	sort.Slice(snapshot.Loans, func(i, j int) bool { return snapshot.Loans[i].LoanID < snapshot.Loans[j].LoanID })
+	return snapshot
+}
+
+// 以快照替换贷款（调用方需持有 s.mu）
+func (s *LoanService) restore(snapshot LoanSnapshot) {
+	loans := make(map[string]*Loan, len(snapshot.Loans))
+	for _, loan := range snapshot.Loans {
+		loan := loan
+		loan.Installments = append([]Installment{}, loan.Installments...)
+		loans[loan.LoanID] = &loan
+	}
+	s.loans, s.seq, s.events = loans, snapshot.Seq, append([]DelinquencyEvent{}, snapshot.Events...)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 登录锁定对象
+const (
+	LOGIN_LOCK_ACCOUNT = "account" // 账户凭证锁定（同一账户连续失败）
+	LOGIN_LOCK_IP      = "ip"      // 来源 IP 锁定（同一 IP 跨账户撞库）
+)
+
+// 登录防护配置
+type LoginGuardConfig struct {
+	MaxFailures   int           // 账户在计数窗口内的失败次数上限，达到后锁定账户凭证
+	IPMaxFailures int           // 单个 IP 在计数窗口内的失败次数上限（跨账户累计），达到后锁定该 IP
+	Window        time.Duration // 失败计数窗口（按模拟时钟）
+	Lockout       time.Duration // 锁定时长（按模拟时钟）
+}
+
+// 登录失败计数与锁定状态
+type LoginLock struct {
+	Kind        string `json:"kind"` // account/ip
+	Key         string `json:"key"`  // 账户ID 或 IP
+	Failures    int    `json:"failures"`
+	LastFailure string `json:"lastFailure,omitempty"`
+	Locked      bool   `json:"locked"`
+	LockedAt    string `json:"lockedAt,omitempty"`
+	LockedUntil string `json:"lockedUntil,omitempty"`
+
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// 解除锁定请求（管理员），accountId 与 ip 至少填写一项
+type LoginUnlockRequest struct {
+	AccountID string `json:"accountId"`
+	IP        string `json:"ip"`
+}
+
+// 登录防护服务：按账户与来源 IP 统计登录失败次数，超过阈值后在冷却期内拒绝登录，
+// 用于模拟暴力破解与撞库（credential stuffing）防御，管理员可提前解除锁定
+type LoginGuardService struct {
+	cfg       LoginGuardConfig
+	accounts  *repository.AccountRepository
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu    sync.Mutex
+	locks map[string]*LoginLock // 锁定对象:标识 → 计数
+}
+
+func NewLoginGuardService(cfg LoginGuardConfig, accounts *repository.AccountRepository, clock Clock, notifier Notifier, templates *TemplateRegistry) *LoginGuardService {
+	return &LoginGuardService{
+		cfg:       cfg,
+		accounts:  accounts,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		locks:     make(map[string]*LoginLock),
+	}
+}
+
+// 登录前检查：账户或来源 IP 处于锁定期内时拒绝登录
+func (s *LoginGuardService) Check(accountID, ip string) error {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, lock := range []*LoginLock{s.locks[LOGIN_LOCK_IP+":"+ip], s.locks[LOGIN_LOCK_ACCOUNT+":"+accountID]} {
+		if lock != nil && now.Before(lock.lockedUntil) {
+			target := "账户"
+			if lock.Kind == LOGIN_LOCK_IP {
+				target = "当前IP"
+			}
+			return model.NewError(model.CODE_LOGIN_LOCKED, fmt.Sprintf("登录失败次数过多，%s已锁定至 %s", target, lock.LockedUntil))
+		}
+	}
+	return nil
+}
+
+// 记录登录失败，返回账户剩余可尝试次数（账户不存在时仅计入来源 IP）；达到阈值时锁定并推送安全提醒
+func (s *LoginGuardService) Fail(accountID, ip, reason string) int {
+	now := s.clock.Now()
+	account, exists := s.accounts.Get(accountID)
+
+	s.mu.Lock()
+	var newLocks []LoginLock
+	if lock, locked := s.count(LOGIN_LOCK_IP, ip, s.cfg.IPMaxFailures, now); locked {
+		newLocks = append(newLocks, lock)
+	}
+	remaining := 0
+	if exists {
+		lock, locked := s.count(LOGIN_LOCK_ACCOUNT, accountID, s.cfg.MaxFailures, now)
+		if locked {
+			newLocks = append(newLocks, lock)
+		}
+		if remaining = s.cfg.MaxFailures - lock.Failures; remaining < 0 {
+			remaining = 0
+		}
+	}
+	s.mu.Unlock()
+
+	// 终端提示：登录失败
+	log.Println("\n[🚫 登录失败]")
+	log.Printf("失败时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("来源IP: %s", ip)
+	log.Printf("失败原因: %s", reason)
+	if exists {
+		log.Printf("剩余尝试次数: %d", remaining)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	for _, lock := range newLocks {
+		// 终端提示：登录锁定
+		log.Println("\n[🔒 登录锁定]")
+		log.Printf("锁定对象: %s %s", lock.Kind, lock.Key)
+		log.Printf("失败次数: %d", lock.Failures)
+		log.Printf("锁定至: %s", lock.LockedUntil)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		if lock.Kind == LOGIN_LOCK_ACCOUNT {
+			s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_LOGIN_LOCKED, map[string]interface{}{
+				"Failures":    lock.Failures,
+				"IP":          ip,
+				"LockedUntil": lock.LockedUntil,
+			}))
+		}
+	}
+	return remaining
+}
+
+// 累计失败次数，超出计数窗口或锁定已过期时重新计数，达到上限时锁定并返回 true（调用方需持有 s.mu）
+func (s *LoginGuardService) count(kind, key string, max int, now time.Time) (LoginLock, bool) {
+	lock := s.locks[kind+":"+key]
+	if lock == nil {
+		lock = &LoginLock{Kind: kind, Key: key}
+		s.locks[kind+":"+key] = lock
+	}
+	expired := lock.Locked && !now.Before(lock.lockedUntil)
+	if expired || lock.Failures == 0 || now.Sub(lock.windowStart) > s.cfg.Window {
+		*lock = LoginLock{Kind: kind, Key: key, windowStart: now}
+	}
+	lock.Failures++
+	lock.LastFailure = now.Format("2006-01-02 15:04:05")
+	locked := max > 0 && lock.Failures >= max && !lock.Locked
+	if locked {
+		lock.Locked = true
+		lock.lockedUntil = now.Add(s.cfg.Lockout)
+		lock.LockedAt = now.Format("2006-01-02 15:04:05")
+		lock.LockedUntil = lock.lockedUntil.Format("2006-01-02 15:04:05")
+	}
+	return *lock, locked
+}
+
+// 登录成功：清零账户失败计数（来源 IP 计数保留，避免撞库成功个别账户后重置）
+func (s *LoginGuardService) Succeed(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.locks, LOGIN_LOCK_ACCOUNT+":"+accountID)
+}
+
+// 失败计数与锁定列表（管理员，锁定中的排在前面），lockedOnly 为真时仅返回锁定中的记录
+func (s *LoginGuardService) List(lockedOnly bool) []LoginLock {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []LoginLock{}
+	for _, lock := range s.locks {
+		item := *lock
+		item.Locked = lock.Locked && now.Before(lock.lockedUntil)
+		if lockedOnly && !item.Locked {
+			continue
+		}
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Locked != result[j].Locked {
+			return result[i].Locked
+		}
+		return result[i].LastFailure > result[j].LastFailure
+	})
+	return result
+}
+
+// 解除锁定（管理员）：清除账户和/或 IP 的失败计数
+func (s *LoginGuardService) Unlock(req LoginUnlockRequest) ([]LoginLock, error) {
+	if req.AccountID == "" && req.IP == "" {
+		return nil, model.NewError(model.CODE_PARAM_ERROR, "accountId 与 ip 至少填写一项")
+	}
+
+	s.mu.Lock()
+	result := []LoginLock{}
+	for _, key := range []string{LOGIN_LOCK_ACCOUNT + ":" + req.AccountID, LOGIN_LOCK_IP + ":" + req.IP} {
+		if lock, ok := s.locks[key]; ok {
+			result = append(result, *lock)
+			delete(s.locks, key)
+		}
+	}
+	s.mu.Unlock()
+	if len(result) == 0 {
+		return nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "没有需要解除的登录锁定")
+	}
+
+	// 终端提示：解除锁定
+	log.Println("\n[🔓 解除登录锁定]")
+	for _, lock := range result {
+		log.Printf("解除对象: %s %s（失败 %d 次）", lock.Kind, lock.Key, lock.Failures)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
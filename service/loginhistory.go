@@ -0,0 +1,92 @@
+package service
+
+import "fmt"
+
+// 登录尝试结果
+const (
+	LOGIN_RESULT_SUCCESS = "success"
+	LOGIN_RESULT_FAILURE = "failure"
+)
+
+// 登录记录单次查询上限
+const loginQueryLimit = 500
+
+// 登录尝试记录
+type LoginAttempt struct {
+	AttemptID   string      `json:"attemptId"`
+	AccountID   string      `json:"accountId"`
+	Result      string      `json:"result"`           // success/failure
+	Reason      string      `json:"reason,omitempty"` // 失败原因
+	IP          string      `json:"ip"`
+	Location    GeoLocation `json:"location"`
+	NewLocation bool        `json:"newLocation"` // 登录地点不在账户历史地点中
+	DeviceID    string      `json:"deviceId,omitempty"`
+	UserAgent   string      `json:"userAgent,omitempty"`
+	SessionID   string      `json:"sessionId,omitempty"`   // 登录成功时建立的会话
+	RiskEventID string      `json:"riskEventId,omitempty"` // 新地点登录对应的风控事件
+	Time        string      `json:"time"`
+}
+
+// 登录记录查询条件（字段为空表示不限）
+type LoginAttemptQuery struct {
+	AccountID string
+	Result    string // success/failure
+	IP        string
+}
+
+// 记录一次登录尝试（成功与失败均记录，由接口层按登录结果填写 Result/Reason/SessionID）。
+// 成功登录参与新地点风控：账户已有历史地点而本次地点不在其中时记录风控事件并提醒客户，
+// 该地点暂不计入历史地点（之后在此发起的交易仍按新地点规则处置）；其余成功登录的地点计入账户历史
+func (s *RiskService) RecordLogin(attempt LoginAttempt) LoginAttempt {
+	location := LookupGeo(attempt.IP)
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	history := s.locations[attempt.AccountID]
+	_, known := history[location.Key()]
+	s.loginSeq++
+	attempt.AttemptID = fmt.Sprintf("LG%s%06d", now.Format("20060102"), s.loginSeq)
+	attempt.Location = location
+	attempt.NewLocation = len(history) > 0 && !known
+	attempt.Time = now.Format("2006-01-02 15:04:05")
+	s.mu.Unlock()
+
+	if attempt.Result == LOGIN_RESULT_SUCCESS {
+		switch {
+		case !attempt.NewLocation || !s.flags.Enabled(FLAG_RISK_ENGINE):
+			s.observe(attempt.AccountID, attempt.IP, location)
+		default:
+			req := RiskRequest{AccountID: attempt.AccountID, IP: attempt.IP, DeviceID: attempt.DeviceID}
+			attempt.RiskEventID = s.record(req, location, []string{RISK_RULE_NEW_LOCATION}, RISK_ACTION_FLAG, "flagged").EventID
+			if account, exists := s.accounts.Get(attempt.AccountID); exists {
+				s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_NEW_LOCATION, map[string]interface{}{
+					"City": location.City,
+					"IP":   attempt.IP,
+				}))
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.logins = append(s.logins, attempt)
+	s.mu.Unlock()
+
+	return attempt
+}
+
+// 查询登录记录（按时间倒序，最多返回 500 条）
+func (s *RiskService) LoginAttempts(query LoginAttemptQuery) []LoginAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []LoginAttempt{}
+	for i := len(s.logins) - 1; i >= 0 && len(result) < loginQueryLimit; i-- {
+		attempt := s.logins[i]
+		if (query.AccountID == "" || attempt.AccountID == query.AccountID) &&
+			(query.Result == "" || attempt.Result == query.Result) &&
+			(query.IP == "" || attempt.IP == query.IP) {
+			result = append(result, attempt)
+		}
+	}
+	return result
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 积分流水类型
+const (
+	POINTS_EARN   = "earn"   // 交易获得积分
+	POINTS_REDEEM = "redeem" // 积分兑换现金
+)
+
+// 积分配置（金额为人民币，其他币种交易按中间价折算）
+type LoyaltyConfig struct {
+	EarnRates  map[string]float64 `json:"earnRates"`  // 交易类型 -> 每 1 元交易金额获得的积分，未列出的类型不积分
+	RedeemRate float64            `json:"redeemRate"` // 每 1 积分兑换的金额（元）
+	MinRedeem  int64              `json:"minRedeem"`  // 单次最少兑换积分
+}
+
+// 积分配置调整请求结构体（字段为空表示不变，EarnRates 中比例为 0 表示取消该类型积分）
+type LoyaltyConfigRequest struct {
+	EarnRates  map[string]float64 `json:"earnRates"`
+	RedeemRate *float64           `json:"redeemRate"`
+	MinRedeem  *int64             `json:"minRedeem" validate:"omitempty,min=1"`
+}
+
+// 积分兑换请求结构体
+type RedeemRequest struct {
+	AccountID string `json:"accountId" validate:"required,account"`
+	Points    int64  `json:"points" validate:"min=1"`
+}
+
+// 积分流水
+type PointsEntry struct {
+	Type         string  `json:"type"`             // earn/redeem
+	TxType       string  `json:"txType,omitempty"` // 获得积分的交易类型
+	TxID         string  `json:"txId"`             // 关联的交易流水号
+	Amount       float64 `json:"amount"`           // 交易金额（earn）或兑换金额（redeem），账户币种
+	Points       int64   `json:"points"`           // 积分变动（兑换为负数）
+	BalanceAfter int64   `json:"balanceAfter"`
+	Time         string  `json:"time"`
+}
+
+// 账户积分账本
+type LoyaltyAccount struct {
+	AccountID string        `json:"accountId"`
+	Points    int64         `json:"points"`
+	Entries   []PointsEntry `json:"entries"` // 按时间倒序
+}
+
+// 积分兑换结果
+type RedeemResult struct {
+	Points   int64         `json:"points"` // 兑换积分
+	Amount   float64       `json:"amount"` // 入账金额（账户币种）
+	Currency string        `json:"currency"`
+	Balance  int64         `json:"balance"` // 剩余积分
+	Account  model.Account `json:"account"`
+	TxID     string        `json:"txId"`
+}
+
+// 积分服务：按交易类型累积积分，维护独立于资金的积分账本，并支持积分兑换为存款
+type LoyaltyService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	fx        *FXService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu      sync.Mutex // 叶子锁：交易流水在账户锁内同步通知积分服务，持有期间不得获取账户锁
+	cfg     LoyaltyConfig
+	points  map[string]int64         // 账户ID -> 积分余额
+	entries map[string][]PointsEntry // 账户ID -> 积分流水
+}
+
+func NewLoyaltyService(cfg LoyaltyConfig, accounts *repository.AccountRepository, ledger *LedgerService, fx *FXService, clock Clock, notifier Notifier, templates *TemplateRegistry) *LoyaltyService {
+	s := &LoyaltyService{
+		accounts:  accounts,
+		ledger:    ledger,
+		fx:        fx,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		cfg:       cfg,
+		points:    make(map[string]int64),
+		entries:   make(map[string][]PointsEntry),
+	}
+	ledger.Subscribe(s.onTransaction)
+	return s
+}
+
+// 查询积分配置
+func (s *LoyaltyService) Config() LoyaltyConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.copyConfig()
+}
+
+// 调整积分配置（对之后的交易与兑换生效）
+func (s *LoyaltyService) SetConfig(req LoyaltyConfigRequest) (LoyaltyConfig, error) {
+	if err := model.Validate(req); err != nil {
+		return LoyaltyConfig{}, err
+	}
+	for txType, rate := range req.EarnRates {
+		if rate < 0 || rate > 100 {
+			return LoyaltyConfig{}, model.NewError(model.CODE_PARAM_ERROR, "交易类型 "+txType+" 的积分比例应在 0 到 100 之间")
+		}
+	}
+	if req.RedeemRate != nil && (*req.RedeemRate <= 0 || *req.RedeemRate > 1) {
+		return LoyaltyConfig{}, model.NewError(model.CODE_PARAM_ERROR, "兑换比例应在 0 到 1 之间")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	earnRates := make(map[string]float64, len(s.cfg.EarnRates))
+	for txType, rate := range s.cfg.EarnRates {
+		earnRates[txType] = rate
+	}
+	for txType, rate := range req.EarnRates {
+		if rate == 0 {
+			delete(earnRates, txType)
+		} else {
+			earnRates[txType] = rate
+		}
+	}
+	s.cfg.EarnRates = earnRates
+	if req.RedeemRate != nil {
+		s.cfg.RedeemRate = *req.RedeemRate
+	}
+	if req.MinRedeem != nil {
+		s.cfg.MinRedeem = *req.MinRedeem
+	}
+	return s.copyConfig(), nil
+}
+
+// 账户积分余额
+func (s *LoyaltyService) Balance(accountID string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.points[accountID]
+}
+
+// 账户积分账本
+func (s *LoyaltyService) Account(accountID string) (LoyaltyAccount, error) {
+	if _, exists := s.accounts.Get(accountID); !exists {
+		return LoyaltyAccount{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := LoyaltyAccount{AccountID: accountID, Points: s.points[accountID], Entries: []PointsEntry{}}
+	entries := s.entries[accountID]
+	for i := len(entries) - 1; i >= 0; i-- {
+		result.Entries = append(result.Entries, entries[i])
+	}
+	return result, nil
+}
+
+// 积分兑换：按兑换比例折算为账户币种金额存入账户
+func (s *LoyaltyService) Redeem(req RedeemRequest) (RedeemResult, error) {
+	if err := model.Validate(req); err != nil {
+		return RedeemResult{}, err
+	}
+
+	// 先扣减积分（入账在账户锁内完成，不能同时持有积分锁）
+	s.mu.Lock()
+	if req.Points < s.cfg.MinRedeem {
+		s.mu.Unlock()
+		return RedeemResult{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("单次最少兑换 %d 积分", s.cfg.MinRedeem))
+	}
+	if s.points[req.AccountID] < req.Points {
+		balance := s.points[req.AccountID]
+		s.mu.Unlock()
+		return RedeemResult{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, fmt.Sprintf("积分不足，当前积分 %d", balance))
+	}
+	s.points[req.AccountID] -= req.Points
+	redeemRate := s.cfg.RedeemRate
+	s.mu.Unlock()
+
+	var txID string
+	var amount float64
+	account, err := s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+		if err := dormantError(*account); err != nil {
+			return err
+		}
+		if account.Status != "normal" {
+			return model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法兑换积分")
+		}
+		amount = s.fx.FromBase(model.RoundAmount(float64(req.Points)*redeemRate), account.Currency)
+		if amount <= 0 {
+			return model.NewError(model.CODE_PARAM_ERROR, "兑换金额不足 0.01")
+		}
+		account.Balance = model.RoundAmount(account.Balance + amount)
+		return nil
+	}, func(account model.Account) {
+		txID = s.ledger.Record(model.Transaction{
+			AccountID:    req.AccountID,
+			Type:         "points_redemption",
+			Direction:    "credit",
+			Amount:       amount,
+			BalanceAfter: account.Balance,
+			Description:  fmt.Sprintf("积分兑换（%d 积分）", req.Points),
+		}).TxID
+	})
+
+	s.mu.Lock()
+	if err != nil {
+		s.points[req.AccountID] += req.Points
+		s.mu.Unlock()
+		return RedeemResult{}, err
+	}
+	balance := s.points[req.AccountID]
+	s.entries[req.AccountID] = append(s.entries[req.AccountID], PointsEntry{
+		Type:         POINTS_REDEEM,
+		TxID:         txID,
+		Amount:       amount,
+		Points:       -req.Points,
+		BalanceAfter: balance,
+		Time:         s.clock.Now().Format("2006-01-02 15:04:05"),
+	})
+	s.mu.Unlock()
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_POINTS_REDEEMED, map[string]interface{}{
+		"Points":    req.Points,
+		"Amount":    amount,
+		"Balance":   account.Balance,
+		"Remaining": balance,
+	}))
+
+	// 终端提示：积分兑换
+	log.Println("\n[🎯 积分兑换]")
+	log.Printf("操作时间: %s", s.clock.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("兑换积分: %d（剩余 %d）", req.Points, balance)
+	log.Printf("入账金额: \033[1;32m%.2f %s\033[0m", amount, account.Currency) // 绿色高亮
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	account.LoyaltyPoints = balance
+	return RedeemResult{
+		Points:   req.Points,
+		Amount:   amount,
+		Currency: account.Currency,
+		Balance:  balance,
+		Account:  account,
+		TxID:     txID,
+	}, nil
+}
+
+// 交易流水订阅：按交易类型累积积分（在账户锁内调用）
+func (s *LoyaltyService) onTransaction(tx model.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rate := s.cfg.EarnRates[tx.Type]
+	if rate <= 0 {
+		return
+	}
+	points := int64(math.Floor(s.fx.ToBase(tx.Amount, tx.Currency) * rate))
+	if points <= 0 {
+		return
+	}
+	s.points[tx.AccountID] += points
+	s.entries[tx.AccountID] = append(s.entries[tx.AccountID], PointsEntry{
+		Type:         POINTS_EARN,
+		TxType:       tx.Type,
+		TxID:         tx.TxID,
+		Amount:       tx.Amount,
+		Points:       points,
+		BalanceAfter: s.points[tx.AccountID],
+		Time:         tx.Time.Format("2006-01-02 15:04:05"),
+	})
+}
+
+// 积分配置副本（调用方持有 s.mu）
+func (s *LoyaltyService) copyConfig() LoyaltyConfig {
+	cfg := s.cfg
+	cfg.EarnRates = make(map[string]float64, len(s.cfg.EarnRates))
+	for txType, rate := range s.cfg.EarnRates {
+		cfg.EarnRates[txType] = rate
+	}
+	return cfg
+}
+
+// 积分账本快照（随状态快照导出）
+type LoyaltySnapshot struct {
+	Points  map[string]int64         `json:"points"`
+	Entries map[string][]PointsEntry `json:"entries"`
+}
+
+// 导出积分余额与积分流水
+func (s *LoyaltyService) Snapshot() LoyaltySnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := LoyaltySnapshot{Points: make(map[string]int64, len(s.points)), Entries: make(map[string][]PointsEntry, len(s.entries))}
+	for accountID, points := range s.points {
+		snapshot.Points[accountID] = points
+	}
+	for accountID, entries := range s.entries {
+		snapshot.Entries[accountID] = append([]PointsEntry{}, entries...)
+	}
+	return snapshot
+}
+
+// 以快照替换积分余额与积分流水
+func (s *LoyaltyService) Restore(snapshot LoyaltySnapshot) {
+	points := make(map[string]int64, len(snapshot.Points))
+	for accountID, balance := range snapshot.Points {
+		points[accountID] = balance
+	}
+	entries := make(map[string][]PointsEntry, len(snapshot.Entries))
+	for accountID, list := range snapshot.Entries {
+		entries[accountID] = append([]PointsEntry{}, list...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.points, s.entries = points, entries
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+// 测试时不输出终端提示
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 维护窗口状态
+const (
+	MAINTENANCE_SCHEDULED = "scheduled" // 待开始
+	MAINTENANCE_ACTIVE    = "active"    // 维护中
+	MAINTENANCE_COMPLETED = "completed" // 已结束
+	MAINTENANCE_CANCELLED = "cancelled" // 已取消（仅待开始的窗口可取消）
+)
+
+// 默认提前通知时长（分钟）
+const MAINTENANCE_DEFAULT_NOTICE = 30
+
+// 维护窗口：窗口内交易类接口暂停服务，查询类接口不受影响
+type MaintenanceWindow struct {
+	WindowID      string `json:"windowId"`
+	Message       string `json:"message"`
+	StartAt       string `json:"startAt"`                // 开始时间（模拟时间）
+	EndAt         string `json:"endAt,omitempty"`        // 预计结束时间，为空表示直至手动结束
+	NoticeMinutes int    `json:"noticeMinutes"`          // 提前通知时长（分钟）
+	Status        string `json:"status"`                 // scheduled/active/completed/cancelled
+	EndedAt       string `json:"endedAt,omitempty"`      // 实际结束时间
+	NoticeID      string `json:"noticeId,omitempty"`     // 维护预告公告
+	CompletionID  string `json:"completionId,omitempty"` // 维护结束公告
+	CreatedAt     string `json:"createdAt"`
+
+	startAt time.Time
+	endAt   time.Time
+}
+
+// 维护窗口请求
+type MaintenanceRequest struct {
+	Message       string `json:"message"`       // 维护说明，为空使用默认说明
+	StartAt       string `json:"startAt"`       // 为空表示立即开始
+	EndAt         string `json:"endAt"`         // 为空表示直至手动结束
+	NoticeMinutes *int   `json:"noticeMinutes"` // 提前通知时长（分钟），为空默认 30
+}
+
+// 维护状态（供客户端查询）
+type MaintenanceStatus struct {
+	Active   bool                `json:"active"`
+	Current  *MaintenanceWindow  `json:"current,omitempty"`
+	Upcoming []MaintenanceWindow `json:"upcoming"`
+}
+
+// 维护模式服务：管理员可立即开启或预约维护窗口，窗口内交易类接口返回维护错误；
+// 窗口开始前推送维护预告、结束后推送恢复公告（经广播公告服务下发，按模拟时钟）
+type MaintenanceService struct {
+	announcements *AnnouncementService
+	clock         Clock
+
+	mu      sync.Mutex
+	windows map[string]*MaintenanceWindow
+	seq     int
+}
+
+func NewMaintenanceService(announcements *AnnouncementService, clock Clock) *MaintenanceService {
+	return &MaintenanceService{
+		announcements: announcements,
+		clock:         clock,
+		windows:       make(map[string]*MaintenanceWindow),
+	}
+}
+
+// 创建维护窗口：未指定开始时间时立即进入维护
+func (s *MaintenanceService) Schedule(req MaintenanceRequest) (MaintenanceWindow, error) {
+	now := s.clock.Now()
+	startAt, err := parseSimTime(req.StartAt)
+	if err != nil {
+		return MaintenanceWindow{}, model.NewError(model.CODE_PARAM_ERROR, "开始时间格式应为 2006-01-02 15:04:05")
+	}
+	endAt, err := parseSimTime(req.EndAt)
+	if err != nil {
+		return MaintenanceWindow{}, model.NewError(model.CODE_PARAM_ERROR, "结束时间格式应为 2006-01-02 15:04:05")
+	}
+	if startAt.Before(now) {
+		startAt = now
+	}
+	if !endAt.IsZero() && !endAt.After(startAt) {
+		return MaintenanceWindow{}, model.NewError(model.CODE_PARAM_ERROR, "结束时间须晚于开始时间与当前时间")
+	}
+	notice := MAINTENANCE_DEFAULT_NOTICE
+	if req.NoticeMinutes != nil {
+		notice = *req.NoticeMinutes
+	}
+	if notice < 0 {
+		return MaintenanceWindow{}, model.NewError(model.CODE_PARAM_ERROR, "提前通知时长不能为负数")
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		message = "维护期间暂停转账、存款等交易，查询功能不受影响"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range s.windows {
+		if (w.Status == MAINTENANCE_SCHEDULED || w.Status == MAINTENANCE_ACTIVE) && windowsOverlap(w.startAt, w.endAt, startAt, endAt) {
+			return MaintenanceWindow{}, model.NewError(model.CODE_PARAM_ERROR, "与维护窗口 "+w.WindowID+" 时间重叠")
+		}
+	}
+
+	s.seq++
+	w := &MaintenanceWindow{
+		WindowID:      fmt.Sprintf("MW%s%04d", now.Format("20060102"), s.seq),
+		Message:       message,
+		StartAt:       startAt.Format("2006-01-02 15:04:05"),
+		NoticeMinutes: notice,
+		Status:        MAINTENANCE_SCHEDULED,
+		CreatedAt:     now.Format("2006-01-02 15:04:05"),
+		startAt:       startAt,
+		endAt:         endAt,
+	}
+	if !endAt.IsZero() {
+		w.EndAt = endAt.Format("2006-01-02 15:04:05")
+	}
+	s.windows[w.WindowID] = w
+
+	// 维护预告：提前 noticeMinutes 发布（已进入通知期时立即发布），维护结束后过期
+	noticeReq := AnnouncementRequest{
+		Kind:      ANNOUNCEMENT_MAINTENANCE,
+		Title:     "系统维护通知",
+		Message:   fmt.Sprintf("本行将于 %s 至 %s 进行系统维护。%s", w.StartAt, maintenanceEnd(w.EndAt), w.Message),
+		ExpiresAt: w.EndAt,
+	}
+	if publishAt := startAt.Add(-time.Duration(notice) * time.Minute); publishAt.After(now) {
+		noticeReq.PublishAt = publishAt.Format("2006-01-02 15:04:05")
+	}
+	if a, err := s.announcements.Create(noticeReq); err == nil {
+		w.NoticeID = a.AnnouncementID
+	} else {
+		log.Printf("维护预告发布失败: %v", err)
+	}
+
+	// 终端提示：维护窗口
+	log.Println("\n[🚧 维护窗口已创建]")
+	log.Printf("窗口编号: %s", w.WindowID)
+	log.Printf("维护时间: %s ~ %s", w.StartAt, maintenanceEnd(w.EndAt))
+	log.Printf("提前通知: %d 分钟", w.NoticeMinutes)
+	log.Printf("维护说明: %s", w.Message)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.dispatch(now)
+	return *w, nil
+}
+
+// 结束维护窗口：维护中的窗口立即结束并推送恢复公告，待开始的窗口取消（未发布的预告一并取消）
+func (s *MaintenanceService) End(windowID string) (MaintenanceWindow, error) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dispatch(now)
+	w, ok := s.windows[windowID]
+	if !ok {
+		return MaintenanceWindow{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "维护窗口不存在")
+	}
+	switch w.Status {
+	case MAINTENANCE_ACTIVE:
+		s.complete(w, now)
+	case MAINTENANCE_SCHEDULED:
+		w.Status = MAINTENANCE_CANCELLED
+		if _, err := s.announcements.Cancel(w.NoticeID); err != nil && w.NoticeID != "" {
+			// 预告已发布：补发取消通知
+			s.announcements.Create(AnnouncementRequest{
+				Kind:      ANNOUNCEMENT_MAINTENANCE,
+				Title:     "系统维护取消",
+				Message:   fmt.Sprintf("原定于 %s 开始的系统维护已取消，各项业务正常办理。", w.StartAt),
+				ExpiresAt: now.Add(24 * time.Hour).Format("2006-01-02 15:04:05"),
+			})
+		}
+
+		// 终端提示：维护窗口取消
+		log.Println("\n[🚧 维护窗口已取消]")
+		log.Printf("窗口编号: %s（原定 %s ~ %s）", w.WindowID, w.StartAt, maintenanceEnd(w.EndAt))
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	default:
+		return MaintenanceWindow{}, model.NewError(model.CODE_PARAM_ERROR, "维护窗口已结束或已取消")
+	}
+	return *w, nil
+}
+
+// 维护中时返回维护错误（交易类接口调用前检查）
+func (s *MaintenanceService) Check() error {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dispatch(now)
+	for _, w := range s.windows {
+		if w.Status == MAINTENANCE_ACTIVE {
+			message := "系统维护中，暂停交易"
+			if w.EndAt != "" {
+				message += "，预计 " + w.EndAt + " 恢复"
+			}
+			return model.NewError(model.CODE_SYSTEM_MAINTENANCE, message)
+		}
+	}
+	return nil
+}
+
+// 当前维护状态与待开始的维护窗口
+func (s *MaintenanceService) Status() MaintenanceStatus {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dispatch(now)
+	status := MaintenanceStatus{Upcoming: []MaintenanceWindow{}}
+	for _, w := range s.windows {
+		switch w.Status {
+		case MAINTENANCE_ACTIVE:
+			current := *w
+			status.Active, status.Current = true, &current
+		case MAINTENANCE_SCHEDULED:
+			status.Upcoming = append(status.Upcoming, *w)
+		}
+	}
+	sort.Slice(status.Upcoming, func(i, j int) bool { return status.Upcoming[i].StartAt < status.Upcoming[j].StartAt })
+	return status
+}
+
+// 维护窗口列表（按编号倒序），status 为空时返回全部
+func (s *MaintenanceService) List(status string) []MaintenanceWindow {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dispatch(now)
+	result := []MaintenanceWindow{}
+	for _, w := range s.windows {
+		if status == "" || w.Status == status {
+			result = append(result, *w)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].WindowID > result[j].WindowID })
+	return result
+}
+
+// 按模拟时钟推进窗口状态（每秒检查，保证无请求时也能按时推送恢复公告）
+func (s *MaintenanceService) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := s.clock.Now()
+		s.mu.Lock()
+		s.dispatch(now)
+		s.mu.Unlock()
+	}
+}
+
+// 到开始时间的窗口进入维护，到结束时间的窗口结束维护（调用方需持有 s.mu）
+func (s *MaintenanceService) dispatch(now time.Time) {
+	var due []*MaintenanceWindow
+	for _, w := range s.windows {
+		if (w.Status == MAINTENANCE_SCHEDULED && !now.Before(w.startAt)) ||
+			(w.Status == MAINTENANCE_ACTIVE && !w.endAt.IsZero() && !now.Before(w.endAt)) {
+			due = append(due, w)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].WindowID < due[j].WindowID })
+	for _, w := range due {
+		if w.Status == MAINTENANCE_SCHEDULED {
+			w.Status = MAINTENANCE_ACTIVE
+
+			// 终端提示：进入维护
+			log.Println("\n[🚧 系统进入维护]")
+			log.Printf("窗口编号: %s", w.WindowID)
+			log.Printf("维护时间: %s ~ %s", w.StartAt, maintenanceEnd(w.EndAt))
+			log.Println("-" + strings.Repeat("-", 50) + "-")
+		}
+		if !w.endAt.IsZero() && !now.Before(w.endAt) {
+			s.complete(w, w.endAt) // 模拟时钟快进越过结束时间时按预定时间结束
+		}
+	}
+}
+
+// 结束维护并推送恢复公告（调用方需持有 s.mu）
+func (s *MaintenanceService) complete(w *MaintenanceWindow, at time.Time) {
+	w.Status = MAINTENANCE_COMPLETED
+	w.EndedAt = at.Format("2006-01-02 15:04:05")
+	a, err := s.announcements.Create(AnnouncementRequest{
+		Kind:      ANNOUNCEMENT_MAINTENANCE,
+		Title:     "系统维护完成",
+		Message:   "系统维护已于 " + w.EndedAt + " 结束，各项业务恢复正常办理。",
+		ExpiresAt: s.clock.Now().Add(24 * time.Hour).Format("2006-01-02 15:04:05"),
+	})
+	if err == nil {
+		w.CompletionID = a.AnnouncementID
+	} else {
+		log.Printf("维护完成公告发布失败: %v", err)
+	}
+
+	// 终端提示：维护结束
+	log.Println("\n[✅ 系统维护结束]")
+	log.Printf("窗口编号: %s", w.WindowID)
+	log.Printf("维护时间: %s ~ %s", w.StartAt, w.EndedAt)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 两个时间段是否重叠（结束时间为零值表示不限）
+func windowsOverlap(start1, end1, start2, end2 time.Time) bool {
+	return (end2.IsZero() || start1.Before(end2)) && (end1.IsZero() || start2.Before(end1))
+}
+
+// 结束时间的展示文字
+func maintenanceEnd(endAt string) string {
+	if endAt == "" {
+		return "另行通知"
+	}
+	return endAt
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 月度对账单订阅
+type StatementSubscription struct {
+	AccountID     string `json:"accountId"`
+	Enabled       bool   `json:"enabled"`
+	Email         string `json:"email,omitempty"`         // 送达邮箱（脱敏）
+	SubscribedAt  string `json:"subscribedAt,omitempty"`  // 最近一次开通时间
+	LastPeriod    string `json:"lastPeriod,omitempty"`    // 最近一次已生成的月份 YYYY-MM
+	NextDelivery  string `json:"nextDelivery,omitempty"`  // 下一期对账单的送达日期（次月首个营业日）
+	LastDelivered string `json:"lastDelivered,omitempty"` // 最近一次送达时间
+}
+
+// 开通/关闭月度对账单请求
+type StatementSubscriptionRequest struct {
+	AccountID string `json:"accountId"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// 开通或关闭月度对账单：开通须已登记邮箱，首期为开通当月的对账单
+func (s *StatementService) Subscribe(req StatementSubscriptionRequest) (StatementSubscription, error) {
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return StatementSubscription{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if req.Enabled && account.Email == "" {
+		return StatementSubscription{}, model.NewError(model.CODE_ACCOUNT_ERROR, "账户未登记邮箱，无法开通月度对账单")
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	sub := s.subscriptions[req.AccountID]
+	if sub == nil {
+		sub = &StatementSubscription{AccountID: req.AccountID}
+		s.subscriptions[req.AccountID] = sub
+	}
+	if req.Enabled && !sub.Enabled {
+		sub.SubscribedAt = now.Format("2006-01-02 15:04:05")
+		// 开通前的月份不补发
+		if previous := monthStart(now).AddDate(0, -1, 0).Format("2006-01"); sub.LastPeriod < previous {
+			sub.LastPeriod = previous
+		}
+	}
+	sub.Enabled = req.Enabled
+	result := s.subscriptionView(*sub, account)
+	s.mu.Unlock()
+
+	// 终端提示：月度对账单订阅
+	log.Println("\n[📬 月度对账单订阅]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", req.AccountID)
+	if req.Enabled {
+		log.Printf("订阅状态: 已开通（送达邮箱 %s，下一期送达日期 %s）", result.Email, result.NextDelivery)
+	} else {
+		log.Printf("订阅状态: 已关闭")
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 查询月度对账单订阅（未开通时 enabled 为 false）
+func (s *StatementService) Subscription(accountID string) (StatementSubscription, error) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return StatementSubscription{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sub := StatementSubscription{AccountID: accountID}
+	if existing := s.subscriptions[accountID]; existing != nil {
+		sub = *existing
+	}
+	return s.subscriptionView(sub, account), nil
+}
+
+// 订阅展示信息：脱敏邮箱与下一期送达日期（调用方需持有 s.mu）
+func (s *StatementService) subscriptionView(sub StatementSubscription, account model.Account) StatementSubscription {
+	if !sub.Enabled {
+		sub.Email, sub.NextDelivery = "", ""
+		return sub
+	}
+	sub.Email = maskEmail(account.Email)
+	period, _ := time.ParseInLocation("2006-01", sub.LastPeriod, time.Local)
+	sub.NextDelivery = s.deliveryDate(period.AddDate(0, 1, 0)).Format("2006-01-02")
+	return sub
+}
+
+// 日终任务：每月首个营业日为订阅账户生成上月对账单，存入月度对账单存档并发送邮件
+// （营业日按营业日历判断，遇周末、节假日顺延；日终漏跑时在下一次日终补发）
+func (s *StatementService) GenerateMonthly(day time.Time) {
+	period := monthStart(day).AddDate(0, -1, 0)
+	if day.Before(s.deliveryDate(period)) {
+		return
+	}
+	from, to := period, period.AddDate(0, 1, 0)
+	label := period.Format("2006-01")
+
+	var delivered []Statement
+	for _, account := range s.accounts.List() {
+		s.mu.RLock()
+		sub := s.subscriptions[account.AccountID]
+		due := sub != nil && sub.Enabled && sub.LastPeriod < label
+		s.mu.RUnlock()
+		if !due {
+			continue
+		}
+		statement, ok := s.build(account, "MS"+account.AccountID+period.Format("200601"), from, to)
+		if !ok {
+			continue
+		}
+		statement.Date = to.AddDate(0, 0, -1).Format("2006-01-02")
+		statement.Period = label
+
+		s.mu.Lock()
+		statement.SeqNo = len(s.monthly[account.AccountID]) + 1
+		s.monthly[account.AccountID] = append(s.monthly[account.AccountID], statement)
+		sub.LastPeriod = label
+		sub.LastDelivered = s.clock.Now().Format("2006-01-02 15:04:05")
+		s.mu.Unlock()
+
+		s.mailer.Notify(account, EVENT_MONTHLY_STATEMENT, map[string]interface{}{
+			"Period":         label,
+			"StatementID":    statement.StatementID,
+			"OpeningBalance": statement.OpeningBalance,
+			"ClosingBalance": statement.ClosingBalance,
+			"CreditCount":    statement.CreditCount,
+			"CreditTotal":    statement.CreditTotal,
+			"DebitCount":     statement.DebitCount,
+			"DebitTotal":     statement.DebitTotal,
+			"NetInterest":    statement.NetInterest,
+		})
+		delivered = append(delivered, statement)
+	}
+	if len(delivered) == 0 {
+		return
+	}
+
+	// 终端提示：月度对账单送达
+	log.Println("\n[📬 月度对账单送达]")
+	log.Printf("对账月份: %s", label)
+	log.Printf("送达日期: %s", day.Format("2006-01-02"))
+	for _, statement := range delivered {
+		log.Printf("账户ID: %s | 用户名: %s | 期初: %.2f | 期末: %.2f %s | 流水 %d 笔",
+			statement.AccountID, statement.UserName, statement.OpeningBalance, statement.ClosingBalance,
+			statement.Currency, len(statement.Entries))
+	}
+	log.Printf("送达账户数: %d", len(delivered))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 账户的月度对账单（按月份排序）
+func (s *StatementService) Monthly(accountID string) []Statement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Statement{}, s.monthly[accountID]...)
+}
+
+// 指定月份对账单的送达日期：次月首个营业日
+func (s *StatementService) deliveryDate(period time.Time) time.Time {
+	return s.calendar.NextBusinessDay(monthStart(period).AddDate(0, 1, 0))
+}
+
+// 所在月份的 1 日零点
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 交易类型与 SWIFT 交易类型码映射
+var mt940TypeCodes = map[string]string{
+	"deposit":          "NMSC",
+	"teller_deposit":   "NMSC",
+	"teller_withdraw":  "NMSC",
+	"card_purchase":    "NMSC",
+	"transfer_in":      "NTRF",
+	"transfer_out":     "NTRF",
+	"interbank_out":    "NTRF",
+	"interbank_refund": "NRTI",
+	"interbank_in":     "NTRF",
+	"suspense_release": "NTRF",
+	"interest":         "NINT",
+	"withholding_tax":  "NTAX",
+}
+
+// 生成账户 [from, to] 日期区间（按自然日）的 MT940 对账单
+func (s *StatementService) MT940(accountID string, from, to time.Time) (string, error) {
+	end := to.AddDate(0, 0, 1)
+	period, exists := s.ledger.PeriodBalance(accountID, from, end)
+	if !exists {
+		return "", model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	account, _ := s.accounts.Get(accountID)
+	entries := s.ledger.Transactions(accountID, from, end)
+
+	return buildMT940(accountID, account.Currency, from, to, period.OpeningBalance, period.ClosingBalance, entries), nil
+}
+
+// 生成 MT940 报文（SWIFT 块 1/2/4）
+func buildMT940(accountID, currency string, from, to time.Time, opening, closing float64, entries []model.Transaction) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("{1:F01%sAXXX0000000000}{2:I940%sXXXXN}{4:\r\n", model.BANK_BIC, model.BANK_BIC))
+	b.WriteString(fmt.Sprintf(":20:ST%s%s\r\n", from.Format("060102"), to.Format("0102")))
+	b.WriteString(fmt.Sprintf(":25:%s/%s\r\n", model.BANK_BIC, accountID))
+	b.WriteString(":28C:00001/001\r\n")
+	b.WriteString(fmt.Sprintf(":60F:%s\r\n", mt940Balance(opening, currency, from)))
+
+	for _, tx := range entries {
+		mark := "C"
+		if tx.Direction == "debit" {
+			mark = "D"
+		}
+		typeCode, ok := mt940TypeCodes[tx.Type]
+		if !ok {
+			typeCode = "NMSC"
+		}
+		customerRef := tx.Reference
+		if tx.EndToEndID != "" {
+			customerRef = tx.EndToEndID
+		}
+		if customerRef == "" {
+			customerRef = "NONREF"
+		}
+		if len(customerRef) > 16 {
+			customerRef = customerRef[:16]
+		}
+
+		b.WriteString(fmt.Sprintf(":61:%s%s%s%s%s%s//%s\r\n",
+			tx.Time.Format("060102"), tx.Time.Format("0102"), mark,
+			mt940Amount(tx.Amount), typeCode, customerRef, tx.TxID))
+
+		// 附言仅使用 SWIFT 字符集，结构化描述交易类型与对手方
+		narrative := "/TYPE/" + strings.ToUpper(tx.Type)
+		if tx.Counterparty != "" {
+			narrative += "/CPTY/" + tx.Counterparty
+		}
+		if tx.Reference != "" {
+			narrative += "/REF/" + tx.Reference
+		}
+		if tx.EndToEndID != "" {
+			narrative += "/EREF/" + tx.EndToEndID
+		}
+		if tx.PurposeCode != "" {
+			narrative += "/PURP/" + tx.PurposeCode
+		}
+		if tx.Memo != "" && isSwiftText(tx.Memo) {
+			narrative += "/REMI/" + tx.Memo
+		}
+		b.WriteString(":86:" + narrative + "\r\n")
+	}
+
+	b.WriteString(fmt.Sprintf(":62F:%s\r\n", mt940Balance(closing, currency, to)))
+	b.WriteString("-}")
+	return b.String()
+}
+
+// MT940 余额字段：借贷标记 + YYMMDD + 币种 + 金额
+func mt940Balance(amount float64, currency string, date time.Time) string {
+	mark := "C"
+	if amount < 0 {
+		mark = "D"
+	}
+	return mark + date.Format("060102") + currency + mt940Amount(amount)
+}
+
+// MT940 金额格式（逗号作小数点）
+func mt940Amount(amount float64) string {
+	return strings.Replace(fmt.Sprintf("%.2f", math.Abs(amount)), ".", ",", 1)
+}
+
+// 是否仅包含 SWIFT X 字符集（非 SWIFT 字符的附言不写入 MT940）
+func isSwiftText(text string) bool {
+	return endToEndIDPattern.MatchString(text) && !strings.Contains(text, "//")
+}
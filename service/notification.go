@@ -0,0 +1,251 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 需要存入通知中心的消息类型
+var notificationTypes = map[string]bool{
+	"transactionAlert": true, // 交易提醒
+	"securityAlert":    true, // 安全提醒
+	"balanceAlert":     true, // 余额提醒（用户自定义规则）
+	"budgetAlert":      true, // 月度预算提醒
+	"broadcast":        true, // 管理员广播
+	"supportChat":      true, // 在线客服回复
+}
+
+// 通知记录
+type Notification struct {
+	ID        string `json:"id"`
+	AccountID string `json:"accountId,omitempty"` // 为空表示面向全部用户的广播
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	Read      bool   `json:"read"`
+	CreatedAt string `json:"createdAt"`
+	ExpiresAt string `json:"expiresAt,omitempty"` // 过期后不再出现在通知列表中（管理员广播）
+
+	expiresAt time.Time
+}
+
+// 账户通知列表
+type NotificationList struct {
+	AccountID string         `json:"accountId"`
+	Unread    int            `json:"unread"`
+	Total     int            `json:"total"`
+	Items     []Notification `json:"items"` // 按时间倒序
+}
+
+// 通知中心：包装推送通道，推送提醒类消息前先存档并附上通知编号，保证与 WebSocket 推送内容一致
+type NotificationService struct {
+	next  Notifier
+	clock Clock
+
+	notifications []Notification
+	read          map[string]map[string]bool // 账户ID → 已读通知ID（广播按账户分别记录已读）
+	seq           int64
+	mu            sync.Mutex
+}
+
+func NewNotificationService(next Notifier, clock Clock) *NotificationService {
+	return &NotificationService{
+		next:  next,
+		clock: clock,
+		read:  make(map[string]map[string]bool),
+	}
+}
+
+// 通知中心快照
+type NotificationSnapshot struct {
+	Notifications []Notification             `json:"notifications"`
+	Read          map[string]map[string]bool `json:"read"` // 账户ID → 已读通知ID
+	Seq           int64                      `json:"seq"`
+}
+
+// 通知中心快照（含已读记录）
+func (s *NotificationService) Snapshot() NotificationSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	read := make(map[string]map[string]bool, len(s.read))
+	for accountID, ids := range s.read {
+		read[accountID] = make(map[string]bool, len(ids))
+		for id := range ids {
+			read[accountID][id] = true
+		}
+	}
+	return NotificationSnapshot{Notifications: append([]Notification{}, s.notifications...), Read: read, Seq: s.seq}
+}
+
+// 以快照替换通知中心内容
+func (s *NotificationService) Restore(snapshot NotificationSnapshot) {
+	notifications := make([]Notification, 0, len(snapshot.Notifications))
+	for _, notification := range snapshot.Notifications {
+		notification.Read = false
+		if notification.ExpiresAt != "" {
+			notification.expiresAt, _ = time.ParseInLocation("2006-01-02 15:04:05", notification.ExpiresAt, time.Local)
+		}
+		notifications = append(notifications, notification)
+	}
+	read := snapshot.Read
+	if read == nil {
+		read = make(map[string]map[string]bool)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifications, s.read, s.seq = notifications, read, snapshot.Seq
+}
+
+// 推送消息（提醒类消息同时存入通知中心）
+func (s *NotificationService) Send(msg ws.Message) {
+	if notificationTypes[msg.Type] && msg.Topic == "" {
+		msg.NotificationID = s.store(msg, time.Time{})
+	}
+	s.next.Send(msg)
+}
+
+// 推送主题消息（行情、运维告警等不存档）
+func (s *NotificationService) Publish(topic, msgType string, payload interface{}) {
+	s.next.Publish(topic, msgType, payload)
+}
+
+// 推送面向全部用户的广播并存入通知中心（离线用户上线后可在通知列表中查看），expiresAt 非零时到期后不再列出，返回通知编号
+func (s *NotificationService) Broadcast(msg ws.Message, expiresAt time.Time) string {
+	msg.AccountID = ""
+	msg.NotificationID = s.store(msg, expiresAt)
+	s.next.Send(msg)
+	return msg.NotificationID
+}
+
+// 存档一条通知（expiresAt 为零表示长期有效），返回通知编号
+func (s *NotificationService) store(msg ws.Message, expiresAt time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.seq++
+	notification := Notification{
+		ID:        fmt.Sprintf("NT%s%08d", now.Format("20060102"), s.seq),
+		AccountID: msg.AccountID,
+		Type:      msg.Type,
+		Message:   msg.Message,
+		CreatedAt: now.Format("2006-01-02 15:04:05"),
+		expiresAt: expiresAt,
+	}
+	if !expiresAt.IsZero() {
+		notification.ExpiresAt = expiresAt.Format("2006-01-02 15:04:05")
+	}
+	s.notifications = append(s.notifications, notification)
+	return notification.ID
+}
+
+// 查询账户的通知（含广播），unreadOnly 为 true 时只返回未读
+func (s *NotificationService) List(accountID string, unreadOnly bool) NotificationList {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	list := NotificationList{AccountID: accountID, Items: make([]Notification, 0)}
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		notification := s.notifications[i]
+		if notification.AccountID != "" && notification.AccountID != accountID {
+			continue
+		}
+		if !notification.expiresAt.IsZero() && !now.Before(notification.expiresAt) {
+			continue // 已过期的广播
+		}
+		notification.Read = s.read[accountID][notification.ID]
+		list.Total++
+		if !notification.Read {
+			list.Unread++
+		} else if unreadOnly {
+			continue
+		}
+		list.Items = append(list.Items, notification)
+	}
+	return list
+}
+
+// 一页通知（游标分页，按时间倒序）
+type NotificationPage struct {
+	AccountID  string         `json:"accountId"`
+	Limit      int            `json:"limit"`
+	Items      []Notification `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"` // 下一页游标，为空表示没有更多
+	HasMore    bool           `json:"hasMore"`
+}
+
+// 游标分页查询账户的通知（含广播），cursor 为上一页返回的 nextCursor，为空表示第一页
+func (s *NotificationService) Page(accountID string, unreadOnly bool, cursor string, limit int) (NotificationPage, error) {
+	after, err := parseCursor(cursor)
+	if err != nil {
+		return NotificationPage{}, err
+	}
+	limit = cursorLimit(limit)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := len(s.notifications) - 1
+	if after.Pos >= 0 {
+		if after.Pos >= len(s.notifications) || s.notifications[after.Pos].ID != after.ID {
+			return NotificationPage{}, staleCursorError()
+		}
+		start = after.Pos - 1
+	}
+
+	now := s.clock.Now()
+	page := NotificationPage{AccountID: accountID, Limit: limit, Items: make([]Notification, 0, limit)}
+	last := -1
+	for i := start; i >= 0; i-- {
+		notification := s.notifications[i]
+		if notification.AccountID != "" && notification.AccountID != accountID {
+			continue
+		}
+		if !notification.expiresAt.IsZero() && !now.Before(notification.expiresAt) {
+			continue
+		}
+		notification.Read = s.read[accountID][notification.ID]
+		if unreadOnly && notification.Read {
+			continue
+		}
+		if len(page.Items) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Items = append(page.Items, notification)
+		last = i
+	}
+	if page.HasMore {
+		page.NextCursor = pageCursor{Pos: last, ID: s.notifications[last].ID}.String()
+	}
+	return page, nil
+}
+
+// 将账户的一条通知标记为已读
+func (s *NotificationService) MarkRead(accountID, notificationID string) (Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, notification := range s.notifications {
+		if notification.ID != notificationID {
+			continue
+		}
+		if notification.AccountID != "" && notification.AccountID != accountID {
+			break
+		}
+		if s.read[accountID] == nil {
+			s.read[accountID] = make(map[string]bool)
+		}
+		s.read[accountID][notificationID] = true
+		notification.Read = true
+		return notification, nil
+	}
+	return Notification{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "通知不存在")
+}
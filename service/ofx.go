@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 交易类型与 OFX TRNTYPE 映射（未列出的按借贷方向记为 CREDIT/DEBIT）
+var ofxTypeCodes = map[string]string{
+	"deposit":             "DEP",
+	"teller_deposit":      "DEP",
+	"teller_withdraw":     "CASH",
+	"card_purchase":       "POS",
+	"card_refund":         "POS",
+	"transfer_in":         "XFER",
+	"transfer_out":        "XFER",
+	"interbank_out":       "XFER",
+	"interbank_in":        "XFER",
+	"suspense_release":    "XFER",
+	"interest":            "INT",
+	"fee":                 "FEE",
+	"direct_debit":        "DIRECTDEBIT",
+	"direct_debit_return": "DIRECTDEBIT",
+	"payroll_credit":      "DIRECTDEP",
+	"payroll_debit":       "PAYMENT",
+	"loan_principal":      "PAYMENT",
+	"loan_interest":       "PAYMENT",
+}
+
+// OFX 文本字段转义
+var ofxEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// 生成账户 [from, to] 日期区间（按自然日）的 OFX 对账单，供 GnuCash 等个人理财软件导入
+func (s *StatementService) OFX(accountID string, from, to time.Time) (string, error) {
+	end := to.AddDate(0, 0, 1)
+	period, exists := s.ledger.PeriodBalance(accountID, from, end)
+	if !exists {
+		return "", model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	account, _ := s.accounts.Get(accountID)
+	entries := s.ledger.Transactions(accountID, from, end)
+
+	return buildOFX(account, from, end, period.ClosingBalance, entries, s.clock.Now()), nil
+}
+
+// 生成 OFX 1.02（SGML）报文：银行对账单响应 STMTRS
+func buildOFX(account model.Account, from, end time.Time, closing float64, entries []model.Transaction, now time.Time) string {
+	acctType := "SAVINGS"
+	if accountType(account) == ACCOUNT_TYPE_CHECKING {
+		acctType = "CHECKING"
+	}
+
+	var b strings.Builder
+	b.WriteString("OFXHEADER:100\r\nDATA:OFXSGML\r\nVERSION:102\r\nSECURITY:NONE\r\nENCODING:UNICODE\r\nCHARSET:NONE\r\n")
+	b.WriteString("COMPRESSION:NONE\r\nOLDFILEUID:NONE\r\nNEWFILEUID:NONE\r\n\r\n")
+	b.WriteString("<OFX>\r\n<SIGNONMSGSRSV1>\r\n<SONRS>\r\n<STATUS>\r\n<CODE>0\r\n<SEVERITY>INFO\r\n</STATUS>\r\n")
+	fmt.Fprintf(&b, "<DTSERVER>%s\r\n<LANGUAGE>CHI\r\n<FI>\r\n<ORG>ZeroBank\r\n<FID>%s\r\n</FI>\r\n</SONRS>\r\n</SIGNONMSGSRSV1>\r\n",
+		ofxTime(now), model.BANK_BIC)
+	b.WriteString("<BANKMSGSRSV1>\r\n<STMTTRNRS>\r\n")
+	fmt.Fprintf(&b, "<TRNUID>%s%s\r\n<STATUS>\r\n<CODE>0\r\n<SEVERITY>INFO\r\n</STATUS>\r\n", account.AccountID, from.Format("20060102"))
+	fmt.Fprintf(&b, "<STMTRS>\r\n<CURDEF>%s\r\n<BANKACCTFROM>\r\n<BANKID>%s\r\n<ACCTID>%s\r\n<ACCTTYPE>%s\r\n</BANKACCTFROM>\r\n",
+		account.Currency, model.BANK_BIC, account.AccountID, acctType)
+	fmt.Fprintf(&b, "<BANKTRANLIST>\r\n<DTSTART>%s\r\n<DTEND>%s\r\n", ofxTime(from), ofxTime(end))
+
+	for _, tx := range entries {
+		trnType, ok := ofxTypeCodes[tx.Type]
+		if !ok {
+			trnType = "CREDIT"
+			if tx.Direction == "debit" {
+				trnType = "DEBIT"
+			}
+		}
+		b.WriteString("<STMTTRN>\r\n")
+		fmt.Fprintf(&b, "<TRNTYPE>%s\r\n<DTPOSTED>%s\r\n<TRNAMT>%.2f\r\n<FITID>%s\r\n", trnType, ofxTime(tx.Time), tx.SignedAmount(), tx.TxID)
+		if tx.Reference != "" {
+			fmt.Fprintf(&b, "<REFNUM>%s\r\n", ofxText(tx.Reference, 32))
+		}
+		name := tx.Counterparty
+		if name == "" {
+			name = tx.Description
+		}
+		fmt.Fprintf(&b, "<NAME>%s\r\n", ofxText(name, 32))
+		memo := tx.Description
+		if tx.Memo != "" {
+			memo += " " + tx.Memo
+		}
+		fmt.Fprintf(&b, "<MEMO>%s\r\n</STMTTRN>\r\n", ofxText(memo, 255))
+	}
+
+	b.WriteString("</BANKTRANLIST>\r\n")
+	fmt.Fprintf(&b, "<LEDGERBAL>\r\n<BALAMT>%.2f\r\n<DTASOF>%s\r\n</LEDGERBAL>\r\n", closing, ofxTime(end))
+	b.WriteString("</STMTRS>\r\n</STMTTRNRS>\r\n</BANKMSGSRSV1>\r\n</OFX>\r\n")
+	return b.String()
+}
+
+// OFX 日期时间：YYYYMMDDHHMMSS[时区偏移:时区名]
+func ofxTime(t time.Time) string {
+	name, offset := t.Zone()
+	return fmt.Sprintf("%s[%+d:%s]", t.Format("20060102150405"), offset/3600, name)
+}
+
+// OFX 文本字段：去除换行、转义并按字符数截断
+func ofxText(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > max {
+		text = string(runes[:max])
+	}
+	return ofxEscaper.Replace(text)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 开户请求结构体
+type OpenAccountRequest struct {
+	CustomerID   string `json:"customerId"` // 已有客户开立新账户时填写，账户沿用客户的姓名与联系方式
+	UserName     string `json:"userName" validate:"required,max=50"`
+	Currency     string `json:"currency" validate:"omitempty,currency"` // 为空默认人民币
+	ProductCode  string `json:"productCode"`                            // 为空默认储蓄产品
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Locale       string `json:"locale"`
+	ReferralCode string `json:"referralCode"` // 推荐码（推荐人账户ID）
+}
+
+// 开户结果
+type OpenAccountResult struct {
+	Account model.Account `json:"account"`
+	Rewards []PromoReward `json:"rewards"` // 开户即时到账的营销奖励
+}
+
+// 开户服务：新开账户并触发开户/推荐营销奖励
+type OnboardingService struct {
+	accounts  *repository.AccountRepository
+	customers *CustomerService
+	fx        *FXService
+	products  *ProductService
+	promos    *PromoService
+	clock     Clock
+
+	mu sync.Mutex // 需先于账户锁获取
+}
+
+func NewOnboardingService(accounts *repository.AccountRepository, customers *CustomerService, fx *FXService, products *ProductService, promos *PromoService, clock Clock) *OnboardingService {
+	return &OnboardingService{accounts: accounts, customers: customers, fx: fx, products: products, promos: promos, clock: clock}
+}
+
+// 开户：账号按现有最大账号顺延，新账户余额为 0；未指定客户时按开户资料新建客户
+func (s *OnboardingService) Open(req OpenAccountRequest) (OpenAccountResult, error) {
+	req.UserName = strings.TrimSpace(req.UserName)
+	req.Email = strings.TrimSpace(req.Email)
+	req.Phone = strings.TrimSpace(req.Phone)
+	if req.CustomerID != "" {
+		profile, err := s.customers.Get(req.CustomerID)
+		if err != nil {
+			return OpenAccountResult{}, err
+		}
+		req.UserName, req.Email, req.Phone, req.Locale = profile.Name, profile.Email, profile.Phone, profile.Locale
+	}
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if err := model.Validate(req); err != nil {
+		return OpenAccountResult{}, err
+	}
+	if err := ValidatePhone(req.Phone); err != nil {
+		return OpenAccountResult{}, err
+	}
+	if err := ValidateEmail(req.Email); err != nil {
+		return OpenAccountResult{}, err
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = model.BASE_CURRENCY
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.ProductCode))
+	if code == "" {
+		code = PRODUCT_SAVINGS
+	}
+	now := s.clock.Now()
+	product, ok := s.products.Effective(code, now)
+	if !ok {
+		return OpenAccountResult{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "产品不存在或尚未生效")
+	}
+
+	s.mu.Lock()
+	s.accounts.Lock()
+	referrerID := strings.TrimSpace(req.ReferralCode)
+	if referrerID != "" {
+		if _, exists := s.accounts.Find(referrerID); !exists {
+			s.accounts.Unlock()
+			s.mu.Unlock()
+			return OpenAccountResult{}, model.NewError(model.CODE_PARAM_ERROR, "推荐码无效")
+		}
+	}
+	customerID := req.CustomerID
+	if customerID == "" {
+		customerID = s.customers.Create(CustomerRequest{Name: req.UserName, Phone: req.Phone, Email: req.Email, Locale: req.Locale}).CustomerID
+	}
+	account := s.accounts.Save(model.Account{
+		AccountID:   nextAccountID(s.accounts),
+		CustomerID:  customerID,
+		UserName:    req.UserName,
+		Currency:    currency,
+		Type:        product.AccountType,
+		ProductCode: product.Code,
+		Status:      "normal",
+		CreateAt:    now.Format("2006-01-02"),
+		Email:       req.Email,
+		Phone:       req.Phone,
+		Locale:      req.Locale,
+	})
+	s.accounts.Unlock()
+	s.mu.Unlock()
+
+	// 终端提示：开户
+	log.Println("\n[🆕 开户]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s（客户号 %s）", account.UserName, account.CustomerID)
+	log.Printf("币种: %s，产品: %s（%s）", account.Currency, product.Code, product.Name)
+	if referrerID != "" {
+		log.Printf("推荐人账户: %s", referrerID)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	rewards := s.promos.OnSignUp(account, referrerID)
+	if rewards == nil {
+		rewards = []PromoReward{}
+	}
+	if latest, exists := s.accounts.Get(account.AccountID); exists {
+		account = latest
+	}
+	return OpenAccountResult{Account: account, Rewards: rewards}, nil
+}
+
+// 下一个账号：现有最大数字账号加一（调用方持有账户锁）
+func nextAccountID(accounts *repository.AccountRepository) string {
+	var max int64 = 8001234566
+	for _, id := range accounts.IDs() {
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil && n > max {
+			max = n
+		}
+	}
+	return fmt.Sprintf("%d", max+1)
+}
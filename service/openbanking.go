@@ -0,0 +1,368 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 开放银行授权同意书
+type Consent struct {
+	ConsentID   string          `json:"consentId"`
+	TppID       string          `json:"tppId"`       // 第三方服务商标识
+	Type        string          `json:"type"`        // ais 账户信息/pis 支付发起
+	AccountID   string          `json:"accountId"`   // 授权账户
+	Permissions []string        `json:"permissions"` // AIS 权限：ReadAccountsBasic/ReadBalances/ReadTransactions
+	Payment     *ConsentPayment `json:"payment,omitempty"`
+	Status      string          `json:"status"` // AwaitingAuthorisation/Authorised/Rejected/Revoked/Expired/Consumed
+	CreatedAt   time.Time       `json:"createdAt"`
+	ExpiresAt   time.Time       `json:"expiresAt"`
+}
+
+// PIS 同意书中的支付信息
+type ConsentPayment struct {
+	ToAccount string  `json:"toAccount" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"amount"`
+	PaymentID string  `json:"paymentId,omitempty"` // 支付执行后回填
+}
+
+// 第三方访问令牌
+type AccessToken struct {
+	Token     string    `json:"accessToken"`
+	ConsentID string    `json:"consentId"`
+	Scope     string    `json:"scope"` // accounts/payments
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// 开放银行支付记录
+type OpenBankingPayment struct {
+	PaymentID string      `json:"paymentId"`
+	ConsentID string      `json:"consentId"`
+	TppID     string      `json:"tppId"`
+	Status    string      `json:"status"` // AcceptedSettlementCompleted/Rejected
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Detail    interface{} `json:"detail,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// 创建同意书请求结构体
+type ConsentRequest struct {
+	TppID            string          `json:"tppId" validate:"required"`
+	Type             string          `json:"type" validate:"oneof=ais pis"`
+	AccountID        string          `json:"accountId" validate:"required,account"`
+	Permissions      []string        `json:"permissions"`
+	ExpiresInMinutes int             `json:"expiresInMinutes"`
+	Payment          *ConsentPayment `json:"payment"`
+}
+
+// 客户授权同意书请求结构体
+type ConsentAuthorizeRequest struct {
+	ConsentID string `json:"consentId"`
+	Approve   bool   `json:"approve"`
+}
+
+var aisPermissions = map[string]bool{
+	"ReadAccountsBasic": true,
+	"ReadBalances":      true,
+	"ReadTransactions":  true,
+}
+
+const (
+	consentMaxLifetime = 90 * 24 * time.Hour // AIS 同意书最长有效期
+	pisConsentLifetime = 10 * time.Minute    // PIS 同意书有效期
+)
+
+// 开放银行服务：同意书、访问令牌与 PIS 支付
+type OpenBankingService struct {
+	accounts  *repository.AccountRepository
+	transfers *AccountService
+	clock     Clock
+
+	consents     map[string]*Consent
+	accessTokens map[string]*AccessToken
+	payments     map[string]*OpenBankingPayment
+	seq          int
+	mu           sync.Mutex
+}
+
+func NewOpenBankingService(accounts *repository.AccountRepository, transfers *AccountService, clock Clock) *OpenBankingService {
+	return &OpenBankingService{
+		accounts:     accounts,
+		transfers:    transfers,
+		clock:        clock,
+		consents:     make(map[string]*Consent),
+		accessTokens: make(map[string]*AccessToken),
+		payments:     make(map[string]*OpenBankingPayment),
+	}
+}
+
+// -------------------------- 同意书管理 --------------------------
+
+// 第三方创建同意书（待客户授权）
+func (s *OpenBankingService) CreateConsent(req ConsentRequest) (Consent, error) {
+	if err := model.Validate(req); err != nil {
+		return Consent{}, err
+	}
+
+	now := s.clock.Now()
+	consent := &Consent{
+		TppID:     req.TppID,
+		Type:      req.Type,
+		AccountID: req.AccountID,
+		Status:    "AwaitingAuthorisation",
+		CreatedAt: now,
+	}
+
+	switch req.Type {
+	case "ais":
+		if len(req.Permissions) == 0 {
+			return Consent{}, model.NewError(model.CODE_PARAM_ERROR, "AIS 同意书至少需要一项权限")
+		}
+		for _, permission := range req.Permissions {
+			if !aisPermissions[permission] {
+				return Consent{}, model.NewError(model.CODE_PARAM_ERROR, "不支持的权限: "+permission)
+			}
+		}
+		lifetime := time.Duration(req.ExpiresInMinutes) * time.Minute
+		if lifetime <= 0 || lifetime > consentMaxLifetime {
+			lifetime = consentMaxLifetime
+		}
+		consent.Permissions = req.Permissions
+		consent.ExpiresAt = now.Add(lifetime)
+	case "pis":
+		if req.Payment == nil {
+			return Consent{}, model.NewError(model.CODE_PARAM_ERROR, "PIS 同意书需指定收款账户和金额")
+		}
+		if err := model.Validate(req.Payment); err != nil {
+			return Consent{}, err
+		}
+		consent.Payment = &ConsentPayment{ToAccount: req.Payment.ToAccount, Amount: req.Payment.Amount}
+		consent.ExpiresAt = now.Add(pisConsentLifetime)
+	}
+
+	if _, exists := s.accounts.Get(req.AccountID); !exists {
+		return Consent{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	s.seq++
+	consent.ConsentID = fmt.Sprintf("CONSENT%s%06d", now.Format("20060102"), s.seq)
+	s.consents[consent.ConsentID] = consent
+	s.mu.Unlock()
+
+	log.Printf("[🔐 开放银行] 第三方 %s 创建 %s 同意书: %s（账户: %s）",
+		consent.TppID, strings.ToUpper(consent.Type), consent.ConsentID, consent.AccountID)
+
+	return *consent, nil
+}
+
+// 客户授权或拒绝同意书，授权通过后签发访问令牌（拒绝时令牌为 nil）
+func (s *OpenBankingService) AuthorizeConsent(req ConsentAuthorizeRequest) (Consent, *AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	consent, ok := s.consents[req.ConsentID]
+	if !ok {
+		return Consent{}, nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "同意书不存在")
+	}
+
+	s.refreshConsentStatus(consent)
+	if consent.Status != "AwaitingAuthorisation" {
+		return Consent{}, nil, model.NewError(model.CODE_NO_PERMISSION, "同意书当前状态不可授权: "+consent.Status)
+	}
+
+	if !req.Approve {
+		consent.Status = "Rejected"
+		return *consent, nil, nil
+	}
+
+	// AIS 令牌只能读取账户信息，PIS 令牌只能发起支付
+	scope := "accounts"
+	if consent.Type == "pis" {
+		scope = "payments"
+	}
+
+	consent.Status = "Authorised"
+	token := &AccessToken{
+		Token:     newAccessToken(),
+		ConsentID: consent.ConsentID,
+		Scope:     scope,
+		ExpiresAt: consent.ExpiresAt,
+	}
+	s.accessTokens[token.Token] = token
+
+	// 终端提示：开放银行授权
+	log.Println("\n[🔐 开放银行授权]")
+	log.Printf("同意书: %s", consent.ConsentID)
+	log.Printf("第三方: %s", consent.TppID)
+	log.Printf("账户ID: %s", consent.AccountID)
+	log.Printf("令牌范围: %s", token.Scope)
+	log.Printf("有效期至: %s", token.ExpiresAt.Format("2006-01-02 15:04:05"))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	issued := *token
+	return *consent, &issued, nil
+}
+
+// 查询同意书状态
+func (s *OpenBankingService) Consent(consentID string) (Consent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	consent, ok := s.consents[consentID]
+	if !ok {
+		return Consent{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "同意书不存在")
+	}
+	s.refreshConsentStatus(consent)
+	return *consent, nil
+}
+
+// 撤销同意书（客户或第三方），同时作废已签发的令牌
+func (s *OpenBankingService) RevokeConsent(consentID string) (Consent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	consent, ok := s.consents[consentID]
+	if !ok {
+		return Consent{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "同意书不存在")
+	}
+
+	consent.Status = "Revoked"
+	s.revokeTokens(consent.ConsentID)
+	return *consent, nil
+}
+
+// 校验 Bearer 令牌、令牌范围与同意书权限，返回令牌对应的同意书
+func (s *OpenBankingService) Authenticate(raw, scope, permission string) (Consent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.accessTokens[raw]
+	if !ok || raw == "" {
+		return Consent{}, model.NewError(model.CODE_NOT_LOGIN, "访问令牌无效")
+	}
+
+	consent := s.consents[token.ConsentID]
+	s.refreshConsentStatus(consent)
+	if !s.clock.Now().Before(token.ExpiresAt) || consent.Status != "Authorised" {
+		delete(s.accessTokens, raw)
+		return Consent{}, model.NewError(model.CODE_NOT_LOGIN, "访问令牌已过期或同意书已失效")
+	}
+
+	if token.Scope != scope {
+		return Consent{}, model.NewError(model.CODE_NO_PERMISSION, "令牌范围不足，需要: "+scope)
+	}
+
+	if permission != "" && !containsString(consent.Permissions, permission) {
+		return Consent{}, model.NewError(model.CODE_NO_PERMISSION, "同意书未授予权限: "+permission)
+	}
+	return *consent, nil
+}
+
+// -------------------------- PIS 支付发起 --------------------------
+
+// 按同意书执行支付（每份同意书只能执行一次），转账结果记录在支付记录的 Code/Message 中
+func (s *OpenBankingService) ExecutePayment(consentID string) (OpenBankingPayment, error) {
+	s.mu.Lock()
+	consent, ok := s.consents[consentID]
+	if !ok || consent.Status != "Authorised" {
+		s.mu.Unlock()
+		return OpenBankingPayment{}, model.NewError(model.CODE_NO_PERMISSION, "同意书已使用或失效")
+	}
+	consent.Status = "Consumed"
+	s.mu.Unlock()
+
+	code, message := model.CODE_SUCCESS, "转账成功"
+	data, err := s.transfers.Transfer(TransferRequest{
+		FromAccount: consent.AccountID,
+		ToAccount:   consent.Payment.ToAccount,
+		Amount:      consent.Payment.Amount,
+	})
+	if err != nil {
+		code, message = model.ErrorCode(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	now := s.clock.Now()
+	payment := &OpenBankingPayment{
+		PaymentID: fmt.Sprintf("OBPAY%s%06d", now.Format("20060102"), s.seq),
+		ConsentID: consent.ConsentID,
+		TppID:     consent.TppID,
+		Status:    "AcceptedSettlementCompleted",
+		Code:      code,
+		Message:   message,
+		CreatedAt: now,
+	}
+	if err != nil {
+		payment.Status = "Rejected"
+	} else {
+		payment.Detail = data
+	}
+	s.payments[payment.PaymentID] = payment
+	consent.Payment.PaymentID = payment.PaymentID
+
+	// 同意书已消费，作废其令牌
+	s.revokeTokens(consent.ConsentID)
+
+	log.Printf("[🔐 开放银行] 第三方 %s 发起支付 %s: %s", consent.TppID, payment.PaymentID, payment.Status)
+	return *payment, nil
+}
+
+// 查询支付状态
+func (s *OpenBankingService) Payment(paymentID string) (OpenBankingPayment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payment, ok := s.payments[paymentID]
+	if !ok {
+		return OpenBankingPayment{}, false
+	}
+	return *payment, true
+}
+
+// -------------------------- 开放银行工具函数 --------------------------
+
+// 同意书到期后标记为过期（调用方需持有 s.mu）
+func (s *OpenBankingService) refreshConsentStatus(consent *Consent) {
+	if (consent.Status == "AwaitingAuthorisation" || consent.Status == "Authorised") &&
+		!s.clock.Now().Before(consent.ExpiresAt) {
+		consent.Status = "Expired"
+	}
+}
+
+// 作废同意书已签发的全部令牌（调用方需持有 s.mu）
+func (s *OpenBankingService) revokeTokens(consentID string) {
+	for key, token := range s.accessTokens {
+		if token.ConsentID == consentID {
+			delete(s.accessTokens, key)
+		}
+	}
+}
+
+// 生成随机访问令牌
+func newAccessToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// 字符串切片是否包含指定值
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
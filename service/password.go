@@ -0,0 +1,333 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 密码哈希参数：PBKDF2-HMAC-SHA256（项目未引入 golang.org/x/crypto，不使用 bcrypt/argon2），
+// 迭代次数参照 OWASP 密码存储建议
+const (
+	passwordHashScheme     = "pbkdf2-sha256"
+	passwordHashIterations = 600000
+	passwordSaltLength     = 16
+)
+
+// 密码规则
+const (
+	passwordMinLength       = 8
+	passwordMaxLength       = 64
+	passwordResetMaxFailure = 5 // 重置令牌最多允许输错次数，超过后作废
+)
+
+// 重置令牌下发渠道
+const (
+	PASSWORD_RESET_SMS   = "sms"
+	PASSWORD_RESET_EMAIL = "email"
+)
+
+// 密码配置
+type PasswordConfig struct {
+	DefaultPassword string        // 模拟环境未设置过密码的账户使用的初始登录密码
+	ResetTTL        time.Duration // 重置令牌有效期（按模拟时钟）
+}
+
+// 忘记密码请求
+type PasswordForgotRequest struct {
+	AccountID string `json:"accountId"`
+	Channel   string `json:"channel"` // sms/email，为空时优先短信
+}
+
+// 重置申请受理结果（无论账户是否存在、令牌是否下发均相同，避免泄露账户信息）
+type PasswordForgotResult struct {
+	AccountID string `json:"accountId"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// 重置密码请求
+type PasswordResetRequest struct {
+	AccountID   string `json:"accountId" validate:"required,account"`
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword"`
+}
+
+// 重置令牌（仅保存令牌摘要）
+type resetToken struct {
+	digest    [sha256.Size]byte
+	expiresAt time.Time
+	failures  int
+}
+
+// 密码服务：登录密码以加盐 PBKDF2 哈希保存，忘记密码时经短信或邮件下发限时重置令牌，
+// 重置成功后由接口层终止账户的全部会话
+type PasswordService struct {
+	cfg       PasswordConfig
+	accounts  *repository.AccountRepository
+	guard     *LoginGuardService
+	sms       *SMSService
+	emails    *EmailService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	defaultHash string // 初始密码的哈希（未设置过密码的账户共用，校验耗时与已设置密码一致）
+
+	mu     sync.Mutex
+	hashes map[string]string      // 账户ID → 密码哈希
+	tokens map[string]*resetToken // 账户ID → 未使用的重置令牌
+}
+
+func NewPasswordService(cfg PasswordConfig, accounts *repository.AccountRepository, guard *LoginGuardService, sms *SMSService, emails *EmailService, clock Clock, notifier Notifier, templates *TemplateRegistry) *PasswordService {
+	return &PasswordService{
+		cfg:         cfg,
+		accounts:    accounts,
+		guard:       guard,
+		sms:         sms,
+		emails:      emails,
+		clock:       clock,
+		notifier:    notifier,
+		templates:   templates,
+		defaultHash: hashPassword(cfg.DefaultPassword),
+		hashes:      make(map[string]string),
+		tokens:      make(map[string]*resetToken),
+	}
+}
+
+// 校验登录密码
+func (s *PasswordService) Verify(accountID, password string) bool {
+	s.mu.Lock()
+	encoded, ok := s.hashes[accountID]
+	s.mu.Unlock()
+	if !ok {
+		encoded = s.defaultHash
+	}
+	return verifyPassword(encoded, password)
+}
+
+// 忘记密码：生成限时重置令牌并经短信或邮件下发（同一账户重复申请时旧令牌作废）。
+// 为防止枚举账户，账户不存在、未登记所选渠道或下发失败时同样返回受理结果，原因仅输出到终端
+func (s *PasswordService) Forgot(req PasswordForgotRequest) (PasswordForgotResult, error) {
+	if req.Channel != "" && req.Channel != PASSWORD_RESET_SMS && req.Channel != PASSWORD_RESET_EMAIL {
+		return PasswordForgotResult{}, model.NewError(model.CODE_PARAM_ERROR, "下发渠道应为 sms 或 email")
+	}
+	expiresAt := s.clock.Now().Add(s.cfg.ResetTTL)
+	result := PasswordForgotResult{
+		AccountID: req.AccountID,
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+	}
+
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		logPasswordForgotSkipped(req.AccountID, "账户不存在")
+		return result, nil
+	}
+	channel := req.Channel
+	if channel == "" {
+		channel = PASSWORD_RESET_SMS
+		if account.Phone == "" {
+			channel = PASSWORD_RESET_EMAIL
+		}
+	}
+	var destination string
+	if channel == PASSWORD_RESET_SMS {
+		if account.Phone == "" {
+			logPasswordForgotSkipped(req.AccountID, "账户未登记手机号")
+			return result, nil
+		}
+		destination = maskPhone(account.Phone)
+	} else {
+		if account.Email == "" {
+			logPasswordForgotSkipped(req.AccountID, "账户未登记邮箱")
+			return result, nil
+		}
+		destination = maskEmail(account.Email)
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return PasswordForgotResult{}, err
+	}
+	token := hex.EncodeToString(raw)
+	vars := map[string]interface{}{
+		"Token":   token,
+		"Minutes": int(s.cfg.ResetTTL.Minutes()),
+	}
+	if channel == PASSWORD_RESET_SMS {
+		if _, err := s.sms.Notify(account, SMS_PASSWORD_RESET, EVENT_PASSWORD_RESET, vars); err != nil {
+			logPasswordForgotSkipped(req.AccountID, "短信下发失败: "+err.Error())
+			return result, nil
+		}
+	} else {
+		s.emails.Notify(account, EVENT_PASSWORD_RESET, vars)
+	}
+
+	s.mu.Lock()
+	s.tokens[req.AccountID] = &resetToken{digest: sha256.Sum256([]byte(token)), expiresAt: expiresAt}
+	s.mu.Unlock()
+
+	// 终端提示：重置令牌下发（令牌仅经短信/邮件送达，不打印）
+	log.Println("\n[🔑 密码重置申请]")
+	log.Printf("账户ID: %s", req.AccountID)
+	log.Printf("下发渠道: %s（%s）", channel, destination)
+	log.Printf("有效期至: %s", result.ExpiresAt)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 终端提示：重置申请已受理但未下发令牌
+func logPasswordForgotSkipped(accountID, reason string) {
+	log.Println("\n[🔑 密码重置申请未下发]")
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("原因: %s", reason)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 重置密码：校验重置令牌（一次性，输错次数过多作废）后保存新密码哈希，并解除账户的登录锁定
+func (s *PasswordService) Reset(req PasswordResetRequest) error {
+	if err := model.Validate(req); err != nil {
+		return err
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		return err
+	}
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	entry, ok := s.tokens[req.AccountID]
+	if !ok || !s.clock.Now().Before(entry.expiresAt) {
+		delete(s.tokens, req.AccountID)
+		s.mu.Unlock()
+		return model.NewError(model.CODE_PARAM_ERROR, "重置令牌已失效，请重新申请")
+	}
+	digest := sha256.Sum256([]byte(req.Token))
+	if subtle.ConstantTimeCompare(digest[:], entry.digest[:]) != 1 {
+		entry.failures++
+		if entry.failures >= passwordResetMaxFailure {
+			delete(s.tokens, req.AccountID)
+			s.mu.Unlock()
+			return model.NewError(model.CODE_RISK_CONTROL_REJECT, "重置令牌错误次数过多，请重新申请")
+		}
+		remaining := passwordResetMaxFailure - entry.failures // 须在解锁前读取，解锁后 entry 可能被并发修改
+		s.mu.Unlock()
+		return model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("重置令牌错误，还可尝试 %d 次", remaining))
+	}
+	delete(s.tokens, req.AccountID)
+	s.mu.Unlock()
+
+	// 哈希计算较慢，在锁外进行
+	encoded := hashPassword(req.NewPassword)
+	s.mu.Lock()
+	s.hashes[req.AccountID] = encoded
+	s.mu.Unlock()
+	s.guard.Succeed(req.AccountID)
+
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+
+	// 终端提示：密码重置
+	log.Println("\n[🔑 密码已重置]")
+	log.Printf("重置时间: %s", now)
+	log.Printf("账户ID: %s", req.AccountID)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.notifier.Send(s.templates.Alert("securityAlert", account, EVENT_PASSWORD_CHANGED, map[string]interface{}{
+		"Time": now,
+	}))
+	return nil
+}
+
+// 密码规则：8-64 位，须同时包含字母与数字
+func validatePassword(password string) error {
+	if len(password) < passwordMinLength || len(password) > passwordMaxLength {
+		return model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("密码长度应为 %d-%d 位", passwordMinLength, passwordMaxLength))
+	}
+	var letter, digit bool
+	for _, c := range password {
+		letter = letter || unicode.IsLetter(c)
+		digit = digit || unicode.IsDigit(c)
+	}
+	if !letter || !digit {
+		return model.NewError(model.CODE_PARAM_ERROR, "密码须同时包含字母与数字")
+	}
+	return nil
+}
+
+// 生成密码哈希，格式：pbkdf2-sha256$迭代次数$盐$哈希（盐与哈希为 base64）
+func hashPassword(password string) string {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		panic(err)
+	}
+	key := pbkdf2SHA256([]byte(password), salt, passwordHashIterations)
+	return strings.Join([]string{
+		passwordHashScheme,
+		strconv.Itoa(passwordHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$")
+}
+
+// 校验密码与哈希是否匹配
+func verifyPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != passwordHashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(pbkdf2SHA256([]byte(password), salt, iterations), want) == 1
+}
+
+// PBKDF2-HMAC-SHA256（RFC 8018），输出长度与 SHA-256 摘要相同，只需计算一个分块
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+	prf.Write(salt)
+	prf.Write([]byte{0, 0, 0, 1})
+	u := prf.Sum(nil)
+	key := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
+
+// 邮箱脱敏：保留首字符与域名
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return email
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 收款人核对结果
+const (
+	PAYEE_MATCH         = "match"         // 户名一致
+	PAYEE_PARTIAL_MATCH = "partial_match" // 户名相近（返回登记户名供付款人确认）
+	PAYEE_NO_MATCH      = "no_match"      // 户名不符
+)
+
+// 核对结果的有效期（模拟时钟）：有效期内向该收款人的首次转账视为已核对
+const payeeCheckTTL = 30 * time.Minute
+
+// 收款人核对请求
+type PayeeCheckRequest struct {
+	FromAccount string `json:"fromAccount"`
+	ToAccount   string `json:"toAccount"`
+	ToAlias     string `json:"toAlias"`                  // 收款别名（可代替 ToAccount，由接口层解析）
+	Name        string `json:"name" validate:"required"` // 付款人填写的收款人户名
+}
+
+// 收款人核对结果
+type PayeeCheck struct {
+	CheckID     string `json:"checkId"`
+	FromAccount string `json:"fromAccount"`
+	ToAccount   string `json:"toAccount"`
+	Name        string `json:"name"`
+	Result      string `json:"result"`               // match/partial_match/no_match
+	ActualName  string `json:"actualName,omitempty"` // 户名相近时返回登记户名
+	FirstTime   bool   `json:"firstTime"`            // 此前未向该收款人转账
+	CheckedAt   string `json:"checkedAt"`
+	ExpiresAt   string `json:"expiresAt"`
+
+	expiresAt time.Time
+}
+
+// 收款人核对服务（Confirmation of Payee）：转账前比对付款人填写的户名与收款账户登记户名，
+// 户名一致或相近的核对结果在有效期内可满足风控对首次收款人的核对要求
+type PayeeService struct {
+	accounts *repository.AccountRepository
+	ledger   *LedgerService
+	clock    Clock
+
+	mu     sync.Mutex             // 叶子锁
+	checks map[string]*PayeeCheck // 转出账户 + 收款账户 → 最近一次核对
+	seq    int64
+}
+
+func NewPayeeService(accounts *repository.AccountRepository, ledger *LedgerService, clock Clock) *PayeeService {
+	return &PayeeService{
+		accounts: accounts,
+		ledger:   ledger,
+		clock:    clock,
+		checks:   make(map[string]*PayeeCheck),
+	}
+}
+
+// 核对收款人户名
+func (s *PayeeService) Verify(req PayeeCheckRequest) (PayeeCheck, error) {
+	if err := model.Validate(req); err != nil {
+		return PayeeCheck{}, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if _, exists := s.accounts.Get(req.FromAccount); !exists {
+		return PayeeCheck{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "转出账户不存在")
+	}
+	payee, exists := s.accounts.Get(req.ToAccount)
+	if !exists {
+		return PayeeCheck{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "收款账户不存在")
+	}
+
+	now := s.clock.Now()
+	check := &PayeeCheck{
+		FromAccount: req.FromAccount,
+		ToAccount:   req.ToAccount,
+		Name:        name,
+		Result:      matchPayeeName(name, payee.UserName),
+		FirstTime:   s.FirstTime(req.FromAccount, req.ToAccount),
+		CheckedAt:   now.Format("2006-01-02 15:04:05"),
+		ExpiresAt:   now.Add(payeeCheckTTL).Format("2006-01-02 15:04:05"),
+		expiresAt:   now.Add(payeeCheckTTL),
+	}
+	if check.Result == PAYEE_PARTIAL_MATCH {
+		check.ActualName = payee.UserName
+	}
+
+	s.mu.Lock()
+	s.seq++
+	check.CheckID = fmt.Sprintf("PC%s%06d", now.Format("20060102"), s.seq)
+	s.checks[payeeKey(req.FromAccount, req.ToAccount)] = check
+	s.mu.Unlock()
+
+	return *check, nil
+}
+
+// 此前是否未向该收款人转账
+func (s *PayeeService) FirstTime(fromAccount, toAccount string) bool {
+	previous, err := s.ledger.SearchTransactions(fromAccount, TransactionFilter{Types: []string{"transfer_out"}, Counterparty: toAccount})
+	return err == nil && len(previous) == 0
+}
+
+// 有效期内户名一致或相近的最近一次核对（户名不符不算已核对）
+func (s *PayeeService) Verified(fromAccount, toAccount string) (PayeeCheck, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	check, ok := s.checks[payeeKey(fromAccount, toAccount)]
+	if !ok || check.Result == PAYEE_NO_MATCH || !s.clock.Now().Before(check.expiresAt) {
+		return PayeeCheck{}, false
+	}
+	return *check, true
+}
+
+func payeeKey(fromAccount, toAccount string) string {
+	return fromAccount + "/" + toAccount
+}
+
+// 比对户名：忽略大小写、空白与标点后完全一致为 match；
+// 词序不同、拼写差异在允许范围内、或英文名姓氏一致且名字首字母一致为 partial_match
+func matchPayeeName(supplied, registered string) string {
+	a, b := payeeNameTokens(supplied), payeeNameTokens(registered)
+	if len(a) == 0 || len(b) == 0 {
+		return PAYEE_NO_MATCH
+	}
+	joinedA, joinedB := strings.Join(a, ""), strings.Join(b, "")
+	if strings.Join(a, " ") == strings.Join(b, " ") {
+		return PAYEE_MATCH
+	}
+
+	sortedA, sortedB := append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	if strings.Join(sortedA, " ") == strings.Join(sortedB, " ") || joinedA == joinedB {
+		return PAYEE_PARTIAL_MATCH
+	}
+
+	// 每 4 个字符允许 1 处差异（3 个字符以内的短户名须完全一致）
+	longest := len([]rune(joinedB))
+	if n := len([]rune(joinedA)); n > longest {
+		longest = n
+	}
+	if editDistance(joinedA, joinedB) <= longest/4 {
+		return PAYEE_PARTIAL_MATCH
+	}
+
+	// 英文名：姓氏（末词）一致且名字首字母一致，如 J Smith / John Smith
+	if len(a) > 1 && len(b) > 1 && a[len(a)-1] == b[len(b)-1] && []rune(a[0])[0] == []rune(b[0])[0] {
+		return PAYEE_PARTIAL_MATCH
+	}
+	return PAYEE_NO_MATCH
+}
+
+// 户名分词：转小写，以空白与标点分隔（中文名为一个词）
+func payeeNameTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+}
+
+// 按字符计算的编辑距离（Levenshtein）
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
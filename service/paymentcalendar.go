@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 收付款日历最多可查询的未来月数（含本月）
+const PAYMENT_CALENDAR_MAX_MONTHS = 12
+
+// 预计收付款来源
+const (
+	EXPECTED_LOAN      = "loan"      // 贷款还款计划
+	EXPECTED_SCHEDULED = "scheduled" // 定时转账与约定转账
+	EXPECTED_RECURRING = "recurring" // 由流水识别的周期性收支
+)
+
+// 预计发生的一笔收付款
+type ExpectedPayment struct {
+	Date        string  `json:"date"`
+	Source      string  `json:"source"`    // loan/scheduled/recurring
+	Direction   string  `json:"direction"` // debit/credit
+	Amount      float64 `json:"amount"`
+	Payee       string  `json:"payee"`     // 贷款为贷款编号，定时转账与周期性收支为收（付）款方
+	Reference   string  `json:"reference"` // 贷款期次、定时转账预约编号或周期频率
+	Description string  `json:"description"`
+}
+
+// 收付款日历中的一天
+type PaymentCalendarDay struct {
+	CalendarDay
+	Debits  float64           `json:"debits"`
+	Credits float64           `json:"credits"`
+	Balance float64           `json:"balance"` // 当日日终预计余额
+	Items   []ExpectedPayment `json:"items"`
+}
+
+// 月度收付款日历：自今日起按预计收付款逐日推算余额
+type PaymentCalendar struct {
+	AccountID        string               `json:"accountId"`
+	Currency         string               `json:"currency"`
+	Month            string               `json:"month"`          // YYYY-MM
+	AsOf             string               `json:"asOf"`           // 推算时间（模拟时钟）
+	CurrentBalance   float64              `json:"currentBalance"` // 当前账面余额（推算起点）
+	OpeningBalance   float64              `json:"openingBalance"` // 首个展示日的日初预计余额
+	TotalDebits      float64              `json:"totalDebits"`
+	TotalCredits     float64              `json:"totalCredits"`
+	ProjectedBalance float64              `json:"projectedBalance"` // 月末预计余额
+	Days             []PaymentCalendarDay `json:"days"`             // 本月为今日至月末，以后的月份为整月
+}
+
+// 收付款日历服务：汇总贷款还款计划、定时转账与识别出的周期性收支，推算月内每日的预计收付款与余额
+type PaymentCalendarService struct {
+	accounts  *repository.AccountRepository
+	loans     *LoanService
+	scheduled *ScheduledTransferService
+	recurring *RecurringService
+	calendar  *CalendarService
+	clock     Clock
+}
+
+func NewPaymentCalendarService(accounts *repository.AccountRepository, loans *LoanService, scheduled *ScheduledTransferService, recurring *RecurringService, calendar *CalendarService, clock Clock) *PaymentCalendarService {
+	return &PaymentCalendarService{accounts: accounts, loans: loans, scheduled: scheduled, recurring: recurring, calendar: calendar, clock: clock}
+}
+
+// 查询账户某月的收付款日历（month 为 YYYY-MM，为空表示本月）。
+// 已逾期的贷款期次计入今日（或下次重试日），已超过预计日期但未失效的周期性收支计入今日
+func (s *PaymentCalendarService) Month(accountID, month string) (PaymentCalendar, error) {
+	account, exists := s.accounts.Find(accountID)
+	if !exists {
+		return PaymentCalendar{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	now := s.clock.Now()
+	today := sim.StartOfDay(now)
+	thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	first := thisMonth
+	if month != "" {
+		parsed, err := time.ParseInLocation("2006-01", month, today.Location())
+		if err != nil {
+			return PaymentCalendar{}, model.NewError(model.CODE_PARAM_ERROR, "月份格式错误，应为 YYYY-MM")
+		}
+		first = parsed
+	}
+	if first.Before(thisMonth) || !first.Before(thisMonth.AddDate(0, PAYMENT_CALENDAR_MAX_MONTHS, 0)) {
+		return PaymentCalendar{}, model.NewError(model.CODE_PARAM_ERROR, "仅支持查询本月起 12 个月内的收付款日历")
+	}
+	last := first.AddDate(0, 1, -1)
+	start := first
+	if start.Before(today) {
+		start = today
+	}
+
+	// 今日至月末的全部预计收付款（展示区间之前的部分只计入期初余额）
+	expected := make([]ExpectedPayment, 0)
+	add := func(due time.Time, payment ExpectedPayment) {
+		if due.Before(today) {
+			due = today
+		}
+		if due.After(last) {
+			return
+		}
+		payment.Date = due.Format("2006-01-02")
+		expected = append(expected, payment)
+	}
+
+	for _, loan := range s.loans.List(accountID) {
+		if loan.Status == LOAN_STATUS_PAID_OFF {
+			continue
+		}
+		for _, inst := range loan.Installments {
+			if inst.Status != INSTALLMENT_PENDING && inst.Status != INSTALLMENT_OVERDUE {
+				continue
+			}
+			due, _ := time.ParseInLocation("2006-01-02", inst.DueDate, today.Location())
+			if inst.Status == INSTALLMENT_OVERDUE && loan.NextRetry != "" {
+				due, _ = time.ParseInLocation("2006-01-02", loan.NextRetry, today.Location())
+			}
+			add(due, ExpectedPayment{
+				Source:      EXPECTED_LOAN,
+				Direction:   "debit",
+				Amount:      model.RoundAmount(inst.Amount + inst.Penalty),
+				Payee:       loan.LoanID,
+				Reference:   fmt.Sprintf("第 %d/%d 期", inst.No, loan.TermMonths),
+				Description: "贷款还款",
+			})
+		}
+	}
+
+	// 定时转账按营业日历顺延后的执行日计入；转入方仅在币种相同时计入（跨币种入账金额取决于执行时汇率）
+	scheduledPayees := make(map[string]bool)
+	for _, transfer := range s.scheduled.Pending(accountID) {
+		payment := ExpectedPayment{
+			Source:      EXPECTED_SCHEDULED,
+			Direction:   "debit",
+			Amount:      transfer.Amount,
+			Payee:       transfer.ToAccount,
+			Reference:   transfer.TransferID,
+			Description: "定时转账至 " + transfer.PayeeName,
+		}
+		if transfer.ToAccount == accountID {
+			if transfer.Currency != account.Currency {
+				continue
+			}
+			payment.Direction, payment.Payee, payment.Description = "credit", transfer.AccountID, "定时转账转入"
+		}
+		scheduledPayees[payment.Direction+"|"+payment.Payee] = true
+		date, _ := time.ParseInLocation("2006-01-02", transfer.NextDate, today.Location())
+		for ok := true; ok && !date.After(last); date, ok = nextRecurringDate(transfer.Frequency, date) {
+			add(s.calendar.Roll(date), payment)
+		}
+	}
+
+	outgoing, err := s.recurring.Detect(accountID)
+	if err != nil {
+		return PaymentCalendar{}, err
+	}
+	incoming, err := s.recurring.DetectIncoming(accountID)
+	if err != nil {
+		return PaymentCalendar{}, err
+	}
+	for _, payment := range append(outgoing, incoming...) {
+		// 贷款扣款与定时转账已分别按还款计划与预约计入
+		if payment.Status != RECURRING_ACTIVE || payment.Category == "loan" || scheduledPayees[payment.Direction+"|"+payment.Payee] {
+			continue
+		}
+		next, err := time.ParseInLocation("2006-01-02", payment.NextDate, today.Location())
+		if err != nil {
+			continue
+		}
+		for due, ok := next, true; ok && !due.After(last); due, ok = nextRecurringDate(payment.Frequency, due) {
+			add(due, ExpectedPayment{
+				Source:      EXPECTED_RECURRING,
+				Direction:   payment.Direction,
+				Amount:      payment.NextAmount,
+				Payee:       payment.Payee,
+				Reference:   payment.Frequency,
+				Description: payment.Category,
+			})
+		}
+	}
+	sort.SliceStable(expected, func(i, j int) bool { return expected[i].Date < expected[j].Date })
+
+	view, err := s.calendar.Days(start, last)
+	if err != nil {
+		return PaymentCalendar{}, err
+	}
+	result := PaymentCalendar{
+		AccountID:      account.AccountID,
+		Currency:       account.Currency,
+		Month:          first.Format("2006-01"),
+		AsOf:           now.Format("2006-01-02 15:04:05"),
+		CurrentBalance: account.Balance,
+		Days:           make([]PaymentCalendarDay, 0, len(view.Days)),
+	}
+	balance := account.Balance
+	i := 0
+	for ; i < len(expected) && expected[i].Date < view.Days[0].Date; i++ {
+		balance += signedAmount(expected[i])
+	}
+	result.OpeningBalance = model.RoundAmount(balance)
+	for _, calendarDay := range view.Days {
+		day := PaymentCalendarDay{CalendarDay: calendarDay, Items: []ExpectedPayment{}}
+		for ; i < len(expected) && expected[i].Date == calendarDay.Date; i++ {
+			if expected[i].Direction == "credit" {
+				day.Credits += expected[i].Amount
+			} else {
+				day.Debits += expected[i].Amount
+			}
+			balance += signedAmount(expected[i])
+			day.Items = append(day.Items, expected[i])
+		}
+		day.Debits = model.RoundAmount(day.Debits)
+		day.Credits = model.RoundAmount(day.Credits)
+		day.Balance = model.RoundAmount(balance)
+		result.TotalDebits += day.Debits
+		result.TotalCredits += day.Credits
+		result.Days = append(result.Days, day)
+	}
+	result.TotalDebits = model.RoundAmount(result.TotalDebits)
+	result.TotalCredits = model.RoundAmount(result.TotalCredits)
+	result.ProjectedBalance = model.RoundAmount(balance)
+	return result, nil
+}
+
+// 收入为正、支出为负
+func signedAmount(payment ExpectedPayment) float64 {
+	if payment.Direction == "credit" {
+		return payment.Amount
+	}
+	return -payment.Amount
+}
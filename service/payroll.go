@@ -0,0 +1,388 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 代发批次状态
+const (
+	PAYROLL_COMPLETED = "completed" // 全部入账
+	PAYROLL_PARTIAL   = "partial"   // 部分明细被拒
+	PAYROLL_REJECTED  = "rejected"  // 全部明细被拒，未扣款
+)
+
+// 代发明细状态
+const (
+	PAYROLL_ITEM_CREDITED = "credited"
+	PAYROLL_ITEM_REJECTED = "rejected"
+)
+
+const PAYROLL_MAX_ITEMS = 1000 // 单批最多明细笔数
+
+// 代发明细
+type PayrollItem struct {
+	AccountID string  `json:"accountId"`
+	Amount    float64 `json:"amount"`
+	Memo      string  `json:"memo,omitempty"` // 附言（如“10月工资”），为空使用批次附言
+}
+
+// 代发工资请求结构体（付款账户须为结算账户）
+type PayrollRequest struct {
+	CorporateAccount string        `json:"corporateAccount" validate:"required,account"`
+	Reference        string        `json:"reference" validate:"required"` // 企业侧批次号（同一付款账户内唯一）
+	Memo             string        `json:"memo"`
+	Items            []PayrollItem `json:"items" validate:"required"` // 最多 PAYROLL_MAX_ITEMS 笔
+}
+
+// 代发明细结果
+type PayrollItemResult struct {
+	No        int     `json:"no"`
+	AccountID string  `json:"accountId"`
+	UserName  string  `json:"userName,omitempty"`
+	Amount    float64 `json:"amount"`
+	Status    string  `json:"status"` // credited/rejected
+	Reason    string  `json:"reason,omitempty"`
+	TxID      string  `json:"txId,omitempty"`
+}
+
+// 代发批次结果报告
+type PayrollBatch struct {
+	BatchID          string              `json:"batchId"`
+	Reference        string              `json:"reference"`
+	CorporateAccount string              `json:"corporateAccount"`
+	CorporateName    string              `json:"corporateName"`
+	Currency         string              `json:"currency"`
+	Memo             string              `json:"memo,omitempty"`
+	Status           string              `json:"status"` // completed/partial/rejected
+	TotalCount       int                 `json:"totalCount"`
+	SuccessCount     int                 `json:"successCount"`
+	FailedCount      int                 `json:"failedCount"`
+	TotalAmount      float64             `json:"totalAmount"`    // 申请代发合计
+	CreditedAmount   float64             `json:"creditedAmount"` // 实际入账合计（即付款账户扣款金额）
+	DebitTxID        string              `json:"debitTxId,omitempty"`
+	CreatedAt        string              `json:"createdAt"`
+	Items            []PayrollItemResult `json:"items"`
+}
+
+// 代发工资服务：付款账户一次性扣款，逐笔入账员工账户，被拒明细不扣款
+type PayrollService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu      sync.Mutex // 需先于账户锁获取
+	batches map[string]*PayrollBatch
+	seq     int64
+}
+
+func NewPayrollService(accounts *repository.AccountRepository, ledger *LedgerService, clock Clock, notifier Notifier, templates *TemplateRegistry) *PayrollService {
+	return &PayrollService{
+		accounts:  accounts,
+		ledger:    ledger,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		batches:   make(map[string]*PayrollBatch),
+	}
+}
+
+// 提交代发批次：先逐笔校验收款账户，再按有效明细合计一次性扣款并逐笔入账
+func (s *PayrollService) Submit(req PayrollRequest) (PayrollBatch, error) {
+	if err := model.Validate(req); err != nil {
+		return PayrollBatch{}, err
+	}
+	req.Reference = strings.TrimSpace(req.Reference)
+	req.Memo = strings.TrimSpace(req.Memo)
+	if len(req.Items) > PAYROLL_MAX_ITEMS {
+		return PayrollBatch{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("代发明细应为 1 到 %d 笔", PAYROLL_MAX_ITEMS))
+	}
+	if req.Memo == "" {
+		req.Memo = "代发工资"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, batch := range s.batches {
+		if batch.CorporateAccount == req.CorporateAccount && batch.Reference == req.Reference {
+			return PayrollBatch{}, model.NewError(model.CODE_PARAM_ERROR, "批次号已提交过（"+batch.BatchID+"）")
+		}
+	}
+
+	s.accounts.Lock()
+	corporate, exists := s.accounts.Find(req.CorporateAccount)
+	if !exists {
+		s.accounts.Unlock()
+		return PayrollBatch{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "付款账户不存在")
+	}
+	if err := s.checkCorporate(corporate); err != nil {
+		s.accounts.Unlock()
+		return PayrollBatch{}, err
+	}
+
+	now := s.clock.Now()
+	batch := &PayrollBatch{
+		BatchID:          fmt.Sprintf("PR%s%06d", now.Format("20060102"), s.seq+1),
+		Reference:        req.Reference,
+		CorporateAccount: corporate.AccountID,
+		CorporateName:    corporate.UserName,
+		Currency:         corporate.Currency,
+		Memo:             req.Memo,
+		TotalCount:       len(req.Items),
+		CreatedAt:        now.Format("2006-01-02 15:04:05"),
+		Items:            make([]PayrollItemResult, len(req.Items)),
+	}
+
+	// 逐笔校验，被拒明细不计入扣款金额
+	seen := make(map[string]bool)
+	employees := make(map[string]model.Account)
+	for i, item := range req.Items {
+		result := &batch.Items[i]
+		result.No = i + 1
+		result.AccountID = item.AccountID
+		result.Amount = model.RoundAmount(item.Amount)
+		batch.TotalAmount += result.Amount
+
+		employee, reason := s.checkItem(corporate, item, result.Amount, seen)
+		if reason != "" {
+			result.Status = PAYROLL_ITEM_REJECTED
+			result.Reason = reason
+			batch.FailedCount++
+			continue
+		}
+		seen[item.AccountID] = true
+		employees[employee.AccountID] = employee
+		result.UserName = employee.UserName
+		result.Status = PAYROLL_ITEM_CREDITED
+		batch.SuccessCount++
+		batch.CreditedAmount += result.Amount
+	}
+	batch.TotalAmount = model.RoundAmount(batch.TotalAmount)
+	batch.CreditedAmount = model.RoundAmount(batch.CreditedAmount)
+
+	if batch.CreditedAmount > 0 && corporate.Available() < batch.CreditedAmount {
+		s.accounts.Unlock()
+		return PayrollBatch{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH,
+			fmt.Sprintf("付款账户可用余额不足，本批次需 %.2f，可用 %.2f", batch.CreditedAmount, corporate.Available()))
+	}
+
+	// 付款账户一次性扣款，再逐笔入账
+	type credit struct {
+		account model.Account
+		amount  float64
+	}
+	var credited []credit
+	if batch.SuccessCount == 0 {
+		batch.Status = PAYROLL_REJECTED
+	} else {
+		batch.Status = PAYROLL_COMPLETED
+		if batch.FailedCount > 0 {
+			batch.Status = PAYROLL_PARTIAL
+		}
+		corporate.Balance = model.RoundAmount(corporate.Balance - batch.CreditedAmount)
+		corporate = s.accounts.Save(corporate)
+		batch.DebitTxID = s.ledger.Record(model.Transaction{
+			AccountID:    corporate.AccountID,
+			Type:         "payroll_debit",
+			Direction:    "debit",
+			Amount:       batch.CreditedAmount,
+			BalanceAfter: corporate.Balance,
+			Reference:    batch.BatchID,
+			Description:  fmt.Sprintf("%s（批次 %s，%d 笔）", req.Memo, req.Reference, batch.SuccessCount),
+		}).TxID
+
+		for i := range batch.Items {
+			result := &batch.Items[i]
+			if result.Status != PAYROLL_ITEM_CREDITED {
+				continue
+			}
+			employee := employees[result.AccountID]
+			employee.Balance = model.RoundAmount(employee.Balance + result.Amount)
+			employee = s.accounts.Save(employee)
+			employees[result.AccountID] = employee
+
+			memo := strings.TrimSpace(req.Items[i].Memo)
+			if memo == "" {
+				memo = req.Memo
+			}
+			result.TxID = s.ledger.Record(model.Transaction{
+				AccountID:    employee.AccountID,
+				Type:         "payroll_credit",
+				Direction:    "credit",
+				Amount:       result.Amount,
+				BalanceAfter: employee.Balance,
+				Counterparty: corporate.UserName,
+				Reference:    batch.BatchID,
+				Description:  memo,
+			}).TxID
+			credited = append(credited, credit{account: employee, amount: result.Amount})
+		}
+	}
+	s.accounts.Unlock()
+
+	s.seq++
+	s.batches[batch.BatchID] = batch
+
+	// 推送到账提醒
+	for _, c := range credited {
+		s.notifier.Send(ws.Message{
+			Type:       "balanceUpdate",
+			AccountID:  c.account.AccountID,
+			NewBalance: c.account.Balance,
+		})
+		s.notifier.Send(s.templates.Alert("transactionAlert", c.account, EVENT_PAYROLL_CREDITED, map[string]interface{}{
+			"Amount":    c.amount,
+			"Balance":   c.account.Balance,
+			"Payer":     corporate.UserName,
+			"Memo":      req.Memo,
+			"Reference": batch.BatchID,
+		}))
+	}
+	if batch.SuccessCount > 0 {
+		s.notifier.Send(ws.Message{
+			Type:       "balanceUpdate",
+			AccountID:  corporate.AccountID,
+			NewBalance: corporate.Balance,
+		})
+		s.notifier.Send(s.templates.Alert("transactionAlert", corporate, EVENT_PAYROLL_DEBITED, map[string]interface{}{
+			"Amount":    batch.CreditedAmount,
+			"Balance":   corporate.Balance,
+			"Success":   batch.SuccessCount,
+			"Failed":    batch.FailedCount,
+			"Reference": batch.BatchID,
+		}))
+	}
+
+	// 终端提示：代发工资
+	log.Println("\n[💼 代发工资]")
+	log.Printf("批次号: %s（企业批次号 %s）", batch.BatchID, batch.Reference)
+	log.Printf("付款账户: %s（%s）", corporate.AccountID, corporate.UserName)
+	log.Printf("明细笔数: %d | 成功: \033[1;32m%d\033[0m | 被拒: \033[1;31m%d\033[0m", batch.TotalCount, batch.SuccessCount, batch.FailedCount)
+	log.Printf("扣款金额: \033[1;33m%.2f %s\033[0m（申请 %.2f）", batch.CreditedAmount, batch.Currency, batch.TotalAmount) // 黄色高亮
+	for _, item := range batch.Items {
+		if item.Status == PAYROLL_ITEM_REJECTED {
+			log.Printf("第 %d 笔 | 账户ID: %s | 金额: %.2f | 被拒: %s", item.No, item.AccountID, item.Amount, item.Reason)
+		}
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return copyPayrollBatch(batch), nil
+}
+
+// 查询代发批次
+func (s *PayrollService) Get(batchID string) (PayrollBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch, ok := s.batches[batchID]
+	if !ok {
+		return PayrollBatch{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "代发批次不存在")
+	}
+	return copyPayrollBatch(batch), nil
+}
+
+// 付款账户的代发批次列表（按提交时间倒序）
+func (s *PayrollService) List(corporateAccount string) []PayrollBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []PayrollBatch{}
+	for _, batch := range s.batches {
+		if batch.CorporateAccount == corporateAccount {
+			result = append(result, copyPayrollBatch(batch))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].BatchID > result[j].BatchID })
+	return result
+}
+
+// 校验付款账户（调用方持有账户锁）
+func (s *PayrollService) checkCorporate(account model.Account) error {
+	if accountType(account) != ACCOUNT_TYPE_CHECKING {
+		return model.NewError(model.CODE_PARAM_ERROR, "仅结算账户可办理代发工资")
+	}
+	if err := dormantError(account); err != nil {
+		return err
+	}
+	if account.Status != "normal" {
+		return model.NewError(model.CODE_ACCOUNT_FROZEN, "付款账户已冻结")
+	}
+	return nil
+}
+
+// 校验单笔代发明细，返回收款账户与拒绝原因（调用方持有账户锁）
+func (s *PayrollService) checkItem(corporate model.Account, item PayrollItem, amount float64, seen map[string]bool) (model.Account, string) {
+	if item.AccountID == "" || amount <= 0 {
+		return model.Account{}, "收款账户不能为空，金额必须大于0"
+	}
+	if item.AccountID == corporate.AccountID {
+		return model.Account{}, "收款账户不能为付款账户"
+	}
+	if seen[item.AccountID] {
+		return model.Account{}, "同一批次内收款账户重复"
+	}
+	employee, exists := s.accounts.Find(item.AccountID)
+	if !exists {
+		return model.Account{}, "收款账户不存在"
+	}
+	if employee.Currency != corporate.Currency {
+		return model.Account{}, "收款账户币种与付款账户不一致"
+	}
+	if employee.Status == ACCOUNT_STATUS_DORMANT {
+		return model.Account{}, "收款账户处于休眠状态"
+	}
+	if employee.Status != "normal" {
+		return model.Account{}, "收款账户已冻结"
+	}
+	return employee, ""
+}
+
+// 解析代发文件（CSV，每行：收款账户,金额[,附言]，首行为表头时跳过）
+func ParsePayrollCSV(r io.Reader) ([]PayrollItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, model.NewError(model.CODE_PARAM_ERROR, "代发文件解析失败: "+err.Error())
+	}
+
+	var items []PayrollItem
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("代发文件第 %d 行格式错误", i+1))
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			if i == 0 {
+				continue // 表头
+			}
+			return nil, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("代发文件第 %d 行金额格式错误", i+1))
+		}
+		item := PayrollItem{AccountID: strings.TrimSpace(record[0]), Amount: amount}
+		if len(record) > 2 {
+			item.Memo = strings.TrimSpace(record[2])
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func copyPayrollBatch(batch *PayrollBatch) PayrollBatch {
+	result := *batch
+	result.Items = append([]PayrollItemResult{}, batch.Items...)
+	return result
+}
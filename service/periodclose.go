@@ -0,0 +1,301 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 结账触发方式
+const (
+	PERIOD_CLOSE_SCHEDULED = "scheduled" // 日终在次月首个营业日自动结账
+	PERIOD_CLOSE_MANUAL    = "manual"    // 管理员手动结账
+)
+
+// 损益结转分录的附言前缀（损益表据此排除结转分录）
+const PERIOD_CLOSE_REFERENCE = "期间结账 "
+
+// 损益结转明细：收入、费用科目余额转入未分配利润
+type PeriodCloseLine struct {
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"` // income/expense
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"` // 结转金额（按科目余额方向，收入为贷方余额、费用为借方余额）
+}
+
+// 单一币种的本期损益
+type PeriodCloseCurrency struct {
+	Currency         string  `json:"currency"`
+	Income           float64 `json:"income"`
+	Expense          float64 `json:"expense"`
+	NetIncome        float64 `json:"netIncome"`        // 本期净利润 = 收入 - 费用
+	RetainedEarnings float64 `json:"retainedEarnings"` // 结转后的未分配利润余额
+}
+
+// 期间结账报告
+type PeriodClose struct {
+	Period        string                `json:"period"` // YYYY-MM
+	StartDate     string                `json:"startDate"`
+	EndDate       string                `json:"endDate"`
+	Trigger       string                `json:"trigger"`  // scheduled/manual
+	ClosedAt      string                `json:"closedAt"` // 结账时间（模拟时钟）
+	Lines         []PeriodCloseLine     `json:"lines"`
+	Currencies    []PeriodCloseCurrency `json:"currencies"`
+	Balances      []GLBalance           `json:"balances"` // 结账后期末总账科目余额
+	Balanced      bool                  `json:"balanced"` // 结账时试算平衡
+	Discrepancies []string              `json:"discrepancies"`
+}
+
+// 月末结账服务：按月将收入、费用科目余额结转至未分配利润，锁定已结账期间禁止补记流水，并保存结账报告。
+// 日终在次月首个营业日（按营业日历）自动结账上月，也可由管理员手动结账
+type PeriodCloseService struct {
+	ledger   *LedgerService
+	calendar *CalendarService
+	eod      *EODService
+	clock    Clock
+
+	mu     sync.Mutex // 保证结账按月份顺序逐一进行
+	closes []PeriodClose
+}
+
+func NewPeriodCloseService(ledger *LedgerService, calendar *CalendarService, eod *EODService, clock Clock) *PeriodCloseService {
+	return &PeriodCloseService{ledger: ledger, calendar: calendar, eod: eod, clock: clock}
+}
+
+// 日终任务：营业日在本月首个营业日或之后、且上月尚未结账时，依次结账至上月
+// （尚无结账记录时只结账上月）
+func (s *PeriodCloseService) CloseMonthEnd(day time.Time) {
+	if !s.calendar.IsBusinessDay(day) {
+		return
+	}
+	thisMonth := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	if day.Before(s.calendar.NextBusinessDay(thisMonth)) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	month := thisMonth.AddDate(0, -1, 0)
+	if last, ok := s.lastClosed(); ok {
+		month = last.AddDate(0, 1, 0)
+	}
+	for ; month.Before(thisMonth); month = month.AddDate(0, 1, 0) {
+		if _, err := s.close(month, PERIOD_CLOSE_SCHEDULED); err != nil {
+			log.Printf("月末结账 %s 失败: %v", month.Format("2006-01"), err)
+			return
+		}
+	}
+}
+
+// 手动结账（period 为 YYYY-MM）：期间须已结束且日终已处理至期末，并须紧接上一已结账期间
+func (s *PeriodCloseService) Close(period string) (PeriodClose, error) {
+	month, err := time.ParseInLocation("2006-01", period, time.Local)
+	if err != nil {
+		return PeriodClose{}, model.NewError(model.CODE_PARAM_ERROR, "期间格式错误，应为 YYYY-MM")
+	}
+	end := month.AddDate(0, 1, 0)
+	if end.After(sim.StartOfDay(s.clock.Now())) {
+		return PeriodClose{}, model.NewError(model.CODE_PARAM_ERROR, "期间尚未结束，不能结账")
+	}
+	if s.eod.LastDate().Before(end.AddDate(0, 0, -1)) {
+		return PeriodClose{}, model.NewError(model.CODE_PARAM_ERROR, "期末日终尚未完成，不能结账")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.lastClosed(); ok {
+		if !month.After(last) {
+			return PeriodClose{}, model.NewError(model.CODE_PARAM_ERROR, "该期间已结账")
+		}
+		if next := last.AddDate(0, 1, 0); !month.Equal(next) {
+			return PeriodClose{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("须先结账 %s", next.Format("2006-01")))
+		}
+	}
+	return s.close(month, PERIOD_CLOSE_MANUAL)
+}
+
+// 已结账期间（最近的在前）
+func (s *PeriodCloseService) List() []PeriodClose {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]PeriodClose, 0, len(s.closes))
+	for i := len(s.closes) - 1; i >= 0; i-- {
+		result = append(result, s.closes[i])
+	}
+	return result
+}
+
+// 查询期间结账报告
+func (s *PeriodCloseService) Get(period string) (PeriodClose, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pc := range s.closes {
+		if pc.Period == period {
+			return pc, nil
+		}
+	}
+	return PeriodClose{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "该期间尚未结账")
+}
+
+// 导出结账记录（状态快照使用）
+func (s *PeriodCloseService) Snapshot() []PeriodClose {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]PeriodClose{}, s.closes...)
+}
+
+// 恢复结账记录，并按最近一次结账重新锁定期间（状态快照使用）
+func (s *PeriodCloseService) Restore(closes []PeriodClose) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closes = append([]PeriodClose{}, closes...)
+	var closedBefore time.Time
+	if last, ok := s.lastClosed(); ok {
+		closedBefore = last.AddDate(0, 1, 0)
+	}
+	s.ledger.setClosedBefore(closedBefore)
+}
+
+// 最近一次结账的月份（调用方需持有 mu）
+func (s *PeriodCloseService) lastClosed() (time.Time, bool) {
+	if len(s.closes) == 0 {
+		return time.Time{}, false
+	}
+	month, err := time.ParseInLocation("2006-01", s.closes[len(s.closes)-1].Period, time.Local)
+	return month, err == nil
+}
+
+// 结账一个月份（调用方需持有 mu）
+func (s *PeriodCloseService) close(month time.Time, trigger string) (PeriodClose, error) {
+	end := month.AddDate(0, 1, 0)
+	period := month.Format("2006-01")
+	lines, err := s.ledger.closePeriod(end, PERIOD_CLOSE_REFERENCE+period)
+	if err != nil {
+		return PeriodClose{}, err
+	}
+
+	result := PeriodClose{
+		Period:     period,
+		StartDate:  month.Format("2006-01-02"),
+		EndDate:    end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Trigger:    trigger,
+		ClosedAt:   s.clock.Now().Format("2006-01-02 15:04:05"),
+		Lines:      lines,
+		Currencies: make([]PeriodCloseCurrency, 0),
+		Balances:   s.ledger.glBalances(end),
+	}
+	currencies := make(map[string]*PeriodCloseCurrency)
+	get := func(currency string) *PeriodCloseCurrency {
+		c, ok := currencies[currency]
+		if !ok {
+			c = &PeriodCloseCurrency{Currency: currency}
+			currencies[currency] = c
+		}
+		return c
+	}
+	for _, line := range lines {
+		if line.Type == "income" {
+			get(line.Currency).Income += line.Amount
+		} else {
+			get(line.Currency).Expense += line.Amount
+		}
+	}
+	retainedCode := s.ledger.Chart().Code(GL_RETAINED_EARNINGS)
+	for _, balance := range result.Balances {
+		if balance.Code == retainedCode {
+			get(balance.Currency).RetainedEarnings = balance.Balance
+		}
+	}
+	for _, c := range currencies {
+		c.Income = model.RoundAmount(c.Income)
+		c.Expense = model.RoundAmount(c.Expense)
+		c.NetIncome = model.RoundAmount(c.Income - c.Expense)
+		result.Currencies = append(result.Currencies, *c)
+	}
+	sort.Slice(result.Currencies, func(i, j int) bool { return result.Currencies[i].Currency < result.Currencies[j].Currency })
+
+	tb := s.ledger.TrialBalance()
+	result.Balanced, result.Discrepancies = tb.Balanced, tb.Discrepancies
+	s.closes = append(s.closes, result)
+
+	// 终端提示：月末结账
+	log.Println("\n[📕 月末结账]")
+	log.Printf("结账期间: %s（%s ~ %s）", result.Period, result.StartDate, result.EndDate)
+	for _, c := range result.Currencies {
+		log.Printf("%s 收入 %.2f，费用 %.2f，净利润 %.2f，未分配利润 %.2f", c.Currency, c.Income, c.Expense, c.NetIncome, c.RetainedEarnings)
+	}
+	log.Printf("期间已锁定，%s 及以前不得补记流水", result.EndDate)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 确认指定时间不在已结账期间内
+func (s *LedgerService) CheckOpenPeriod(at time.Time) error {
+	s.periodMutex.RLock()
+	defer s.periodMutex.RUnlock()
+
+	if at.Before(s.closedBefore) {
+		return model.NewError(model.CODE_PARAM_ERROR,
+			fmt.Sprintf("%s 及以前的期间已结账，不能补记流水", s.closedBefore.AddDate(0, 0, -1).Format("2006-01-02")))
+	}
+	return nil
+}
+
+func (s *LedgerService) setClosedBefore(t time.Time) {
+	s.periodMutex.Lock()
+	defer s.periodMutex.Unlock()
+
+	s.closedBefore = t
+}
+
+// 将 end（不含）以前的收入、费用科目余额结转至未分配利润，结转分录记于期末最后一秒，并锁定期间。
+// 持有账户写锁，保证结转与锁定期间之间没有其他流水入账
+func (s *LedgerService) closePeriod(end time.Time, reference string) ([]PeriodCloseLine, error) {
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	if err := s.CheckOpenPeriod(end.Add(-time.Second)); err != nil {
+		return nil, err
+	}
+
+	retainedCode := s.chart.Code(GL_RETAINED_EARNINGS)
+	at := end.Add(-time.Second)
+	lines := make([]PeriodCloseLine, 0)
+	for _, balance := range s.glBalances(end) {
+		if (balance.Type != "income" && balance.Type != "expense") || balance.Balance == 0 {
+			continue
+		}
+		// 收入科目贷方余额借记转出，费用科目借方余额贷记转出（余额为负时方向相反）
+		debit, credit, amount := balance.Code, retainedCode, balance.Balance
+		if balance.Type == "expense" {
+			debit, credit = retainedCode, balance.Code
+		}
+		if amount < 0 {
+			debit, credit, amount = credit, debit, -amount
+		}
+		s.journal.AppendInternal(debit, credit, amount, balance.Currency, reference, at)
+		lines = append(lines, PeriodCloseLine{
+			Code:     balance.Code,
+			Name:     balance.Name,
+			Type:     balance.Type,
+			Currency: balance.Currency,
+			Amount:   balance.Balance,
+		})
+	}
+	s.setClosedBefore(end)
+	return lines, nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 手续费类型
+const (
+	FEE_TYPE_FX          = "crossCurrency" // 跨币种转账手续费
+	FEE_TYPE_INTERBANK   = "interbank"     // 跨行转账手续费
+	FEE_TYPE_MIN_BALANCE = "minBalance"    // 低于最低余额管理费
+)
+
+// 账户类型
+const (
+	ACCOUNT_TYPE_SAVINGS  = "savings"  // 储蓄账户
+	ACCOUNT_TYPE_CHECKING = "checking" // 结算账户
+)
+
+// 最低余额处理策略
+const (
+	MIN_BALANCE_BLOCK = "block" // 拒绝导致余额低于最低余额的转账
+	MIN_BALANCE_FEE   = "fee"   // 允许转账，但收取低余额管理费
+)
+
+// 手续费配置（按转账金额计费率，最低/最高收费以人民币计，外币账户按中间价折算）
+type FeeConfig struct {
+	FXRate        float64       // 跨币种转账手续费率
+	FXMin         float64       // 跨币种转账最低收费
+	FXMax         float64       // 跨币种转账最高收费（0 表示不封顶）
+	InterbankRate float64       // 跨行转账手续费率
+	InterbankMin  float64       // 跨行转账最低收费
+	InterbankMax  float64       // 跨行转账最高收费（0 表示不封顶）
+	QuoteTTL      time.Duration // 报价有效期（模拟时间）
+}
+
+// 转账报价请求结构体
+type QuoteRequest struct {
+	FromAccount string  `json:"fromAccount" validate:"required,account"`
+	ToAccount   string  `json:"toAccount" validate:"required,account"`
+	ToBank      string  `json:"toBank"` // 收款行 BIC，为空或本行 BIC 表示行内转账
+	Amount      float64 `json:"amount" validate:"amount"`
+}
+
+// 手续费明细
+type FeeItem struct {
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+}
+
+// 转账报价（锁定手续费与汇率，有效期内可在转账时引用）
+type TransferQuote struct {
+	QuoteID        string    `json:"quoteId,omitempty"`
+	FromAccount    string    `json:"fromAccount"`
+	ToAccount      string    `json:"toAccount"`
+	ToBank         string    `json:"toBank,omitempty"`
+	Amount         float64   `json:"amount"`
+	Currency       string    `json:"currency"`
+	Fees           []FeeItem `json:"fees"`
+	TotalFee       float64   `json:"totalFee"`
+	TotalDebit     float64   `json:"totalDebit"` // 转账金额 + 手续费
+	FXRate         float64   `json:"fxRate,omitempty"`
+	MidRate        float64   `json:"midRate,omitempty"`
+	CreditAmount   float64   `json:"creditAmount"`
+	CreditCurrency string    `json:"creditCurrency"`
+	CreatedAt      string    `json:"createdAt,omitempty"`
+	ExpiresAt      string    `json:"expiresAt,omitempty"`
+
+	expiresAt time.Time
+	used      bool
+}
+
+// 定价服务：转账手续费计算与报价锁定
+type PricingService struct {
+	cfg      FeeConfig
+	accounts *repository.AccountRepository
+	fx       *FXService
+	products *ProductService
+	flags    *FeatureFlagService
+	clock    Clock
+
+	mu     sync.Mutex
+	quotes map[string]*TransferQuote
+	seq    int
+}
+
+func NewPricingService(cfg FeeConfig, accounts *repository.AccountRepository, fx *FXService, products *ProductService, flags *FeatureFlagService, clock Clock) *PricingService {
+	return &PricingService{
+		cfg:      cfg,
+		accounts: accounts,
+		fx:       fx,
+		products: products,
+		flags:    flags,
+		clock:    clock,
+		quotes:   make(map[string]*TransferQuote),
+	}
+}
+
+// 是否为跨行转账
+func (r QuoteRequest) interbank() bool {
+	return r.ToBank != "" && r.ToBank != model.BANK_BIC
+}
+
+// 生成转账报价：返回手续费、汇率、扣款总额，并生成短期有效的报价 ID
+func (s *PricingService) Quote(req QuoteRequest) (TransferQuote, error) {
+	if err := model.Validate(req); err != nil {
+		return TransferQuote{}, err
+	}
+
+	fromAccount, exists := s.accounts.Get(req.FromAccount)
+	if !exists {
+		return TransferQuote{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "转出账户不存在")
+	}
+	if err := dormantError(fromAccount); err != nil {
+		return TransferQuote{}, err
+	}
+	if fromAccount.Status != "normal" {
+		return TransferQuote{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "转出账户已冻结，无法转账")
+	}
+
+	// 确定入账币种：行内转账为收款账户币种，跨行转账仅支持人民币
+	toCurrency := model.BASE_CURRENCY
+	if req.interbank() {
+		if _, ok := externalBanks[req.ToBank]; !ok {
+			return TransferQuote{}, model.NewError(model.CODE_TARGET_ACCOUNT_ABNORMAL, "不支持的收款行")
+		}
+		if fromAccount.Currency != model.BASE_CURRENCY {
+			return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "跨行转账仅支持人民币账户")
+		}
+	} else {
+		if req.FromAccount == req.ToAccount {
+			return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "不能向自己转账")
+		}
+		toAccount, exists := s.accounts.Get(req.ToAccount)
+		if !exists {
+			return TransferQuote{}, model.NewError(model.CODE_TARGET_ACCOUNT_ABNORMAL, "收款账户不存在")
+		}
+		toCurrency = toAccount.Currency
+	}
+
+	quote, err := s.Resolve("", req, fromAccount.Currency, toCurrency)
+	if err != nil {
+		return TransferQuote{}, err
+	}
+	// 产品限额与低余额管理费按当前余额预估，转账时按实际余额重新计算
+	if err := s.ApplyProductRules(&quote, fromAccount); err != nil {
+		return TransferQuote{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.purgeExpired(now)
+	s.seq++
+	quote.QuoteID = fmt.Sprintf("QT%s%06d", now.Format("20060102"), s.seq)
+	quote.CreatedAt = now.Format("2006-01-02 15:04:05")
+	quote.expiresAt = now.Add(s.cfg.QuoteTTL)
+	quote.ExpiresAt = quote.expiresAt.Format("2006-01-02 15:04:05")
+	stored := quote
+	s.quotes[quote.QuoteID] = &stored
+
+	// 终端提示：转账报价
+	log.Println("\n[🧾 转账报价]")
+	log.Printf("报价ID: %s", quote.QuoteID)
+	log.Printf("转出账户ID: %s", req.FromAccount)
+	log.Printf("收款账户ID: %s", req.ToAccount)
+	if req.interbank() {
+		log.Printf("收款行: %s", req.ToBank)
+	}
+	log.Printf("转账金额: %.2f %s", quote.Amount, quote.Currency)
+	for _, fee := range quote.Fees {
+		log.Printf("%s: %.2f %s", fee.Description, fee.Amount, fee.Currency)
+	}
+	if quote.FXRate != 0 {
+		log.Printf("成交汇率: %.6f（中间价 %.6f），入账金额: %.2f %s", quote.FXRate, quote.MidRate, quote.CreditAmount, quote.CreditCurrency)
+	}
+	log.Printf("扣款总额: \033[1;31m%.2f %s\033[0m", quote.TotalDebit, quote.Currency) // 红色高亮
+	log.Printf("有效期至: %s", quote.ExpiresAt)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return quote, nil
+}
+
+// 确定转账定价：quoteID 为空时按当前费率与汇率计算，否则校验并返回已锁定的报价（不标记为已使用）
+// 调用方在转账成功后须调用 Consume
+func (s *PricingService) Resolve(quoteID string, req QuoteRequest, fromCurrency, toCurrency string) (TransferQuote, error) {
+	if quoteID != "" {
+		return s.lookup(quoteID, req)
+	}
+
+	quote := TransferQuote{
+		FromAccount:    req.FromAccount,
+		ToAccount:      req.ToAccount,
+		Amount:         req.Amount,
+		Currency:       fromCurrency,
+		Fees:           []FeeItem{},
+		CreditAmount:   req.Amount,
+		CreditCurrency: toCurrency,
+	}
+	if req.interbank() {
+		quote.ToBank = req.ToBank
+	}
+
+	if fromCurrency != toCurrency {
+		rate, mid, ok := s.fx.Quote(fromCurrency, toCurrency)
+		if !ok {
+			return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "不支持的币种兑换: "+fromCurrency+"/"+toCurrency)
+		}
+		quote.FXRate, quote.MidRate = rate, mid
+		quote.CreditAmount = model.RoundAmount(req.Amount * rate)
+		quote.addFee(FEE_TYPE_FX, "跨币种转账手续费", s.fee(req.Amount, fromCurrency, s.cfg.FXRate, s.cfg.FXMin, s.cfg.FXMax))
+	}
+	if req.interbank() {
+		quote.addFee(FEE_TYPE_INTERBANK, "跨行转账手续费", s.fee(req.Amount, fromCurrency, s.cfg.InterbankRate, s.cfg.InterbankMin, s.cfg.InterbankMax))
+	}
+	quote.TotalDebit = model.RoundAmount(req.Amount + quote.TotalFee)
+	return quote, nil
+}
+
+// 按转出账户所绑定产品的参数校验转账：定期未到期、超过单笔/日累计限额时拒绝；
+// 跌破最低余额时按产品策略拒绝或追加低余额管理费（每次调用按账户当前余额重新计算，报价中预估的管理费会被替换）
+func (s *PricingService) ApplyProductRules(quote *TransferQuote, account model.Account) error {
+	quote.removeFee(FEE_TYPE_MIN_BALANCE)
+
+	now := s.clock.Now()
+	product := s.products.ProductFor(account, now)
+
+	if maturity, ok := s.products.Maturity(account, product); ok && now.Before(maturity) {
+		return model.NewError(model.CODE_ACCOUNT_LIMIT,
+			fmt.Sprintf("%s未到期（到期日 %s），不能转出", product.Name, maturity.Format("2006-01-02")))
+	}
+	if product.SingleLimit > 0 {
+		if limit := s.fx.FromBase(product.SingleLimit, account.Currency); quote.Amount > limit {
+			return model.NewError(model.CODE_ACCOUNT_LIMIT,
+				fmt.Sprintf("单笔转账金额超过限额 %.2f %s", limit, account.Currency))
+		}
+	}
+	if product.DailyLimit > 0 {
+		limit := s.fx.FromBase(product.DailyLimit, account.Currency)
+		if used := s.products.OutgoingSince(account.AccountID, now); used+quote.Amount > limit {
+			return model.NewError(model.CODE_ACCOUNT_LIMIT,
+				fmt.Sprintf("今日累计转账金额将超过日限额 %.2f %s（已转出 %.2f %s）", limit, account.Currency, used, account.Currency))
+		}
+	}
+
+	if product.MinBalance <= 0 {
+		return nil
+	}
+	minBalance := s.fx.FromBase(product.MinBalance, account.Currency)
+	if model.RoundAmount(account.Balance-quote.TotalDebit) >= minBalance {
+		return nil
+	}
+	if product.MinBalancePolicy == MIN_BALANCE_BLOCK {
+		return model.NewError(model.CODE_ACCOUNT_LIMIT,
+			fmt.Sprintf("转账后余额将低于最低余额 %.2f %s，交易已拒绝", minBalance, account.Currency))
+	}
+	if !s.flags.Enabled(FLAG_FEES) {
+		return nil
+	}
+	quote.addFee(FEE_TYPE_MIN_BALANCE, "低于最低余额管理费", s.fx.FromBase(product.MinBalanceFee, account.Currency))
+	return nil
+}
+
+// 账户类型（未设置时按储蓄账户处理）
+func accountType(account model.Account) string {
+	if account.Type == "" {
+		return ACCOUNT_TYPE_SAVINGS
+	}
+	return account.Type
+}
+
+// 标记报价已使用（每个报价仅可用于一笔转账）
+func (s *PricingService) Consume(quoteID string) {
+	if quoteID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if quote, ok := s.quotes[quoteID]; ok {
+		quote.used = true
+	}
+}
+
+// 查找并校验报价：须存在、未使用、未过期，且与本次转账的账户和金额一致
+func (s *PricingService) lookup(quoteID string, req QuoteRequest) (TransferQuote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quote, ok := s.quotes[quoteID]
+	if !ok {
+		return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "报价不存在")
+	}
+	if quote.used {
+		return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "报价已使用，请重新获取报价")
+	}
+	if s.clock.Now().After(quote.expiresAt) {
+		return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "报价已过期，请重新获取报价")
+	}
+
+	toBank := ""
+	if req.interbank() {
+		toBank = req.ToBank
+	}
+	if quote.FromAccount != req.FromAccount || quote.ToAccount != req.ToAccount || quote.ToBank != toBank ||
+		model.RoundAmount(quote.Amount) != model.RoundAmount(req.Amount) {
+		return TransferQuote{}, model.NewError(model.CODE_PARAM_ERROR, "转账信息与报价不一致")
+	}
+	return *quote, nil
+}
+
+// 清理过期已久的报价（保留一个有效期，以便提示"报价已过期"；调用方持有 s.mu）
+func (s *PricingService) purgeExpired(now time.Time) {
+	for id, quote := range s.quotes {
+		if now.Sub(quote.expiresAt) > s.cfg.QuoteTTL {
+			delete(s.quotes, id)
+		}
+	}
+}
+
+// 按费率计算手续费，并以折算为账户币种的最低/最高收费限制
+func (s *PricingService) fee(amount float64, currency string, rate, min, max float64) float64 {
+	if (rate <= 0 && min <= 0) || !s.flags.Enabled(FLAG_FEES) {
+		return 0
+	}
+
+	fee := amount * rate
+	if min > 0 && fee < s.fx.FromBase(min, currency) {
+		fee = s.fx.FromBase(min, currency)
+	}
+	if max > 0 && fee > s.fx.FromBase(max, currency) {
+		fee = s.fx.FromBase(max, currency)
+	}
+	return model.RoundAmount(fee)
+}
+
+// 记录转账手续费流水（对方科目为手续费收入），account 为扣费后的转出账户，reference 为对应转账流水号
+// 调用方持有 AccountRepository 锁
+func recordFees(ledger *LedgerService, pricing TransferQuote, account model.Account, reference string) {
+	balance := account.Balance + pricing.TotalFee
+	for _, fee := range pricing.Fees {
+		balance -= fee.Amount
+		ledger.Record(model.Transaction{
+			AccountID:    account.AccountID,
+			Type:         "fee",
+			Direction:    "debit",
+			Amount:       fee.Amount,
+			BalanceAfter: model.RoundAmount(balance),
+			Reference:    reference,
+			Description:  fee.Description,
+		})
+	}
+}
+
+// 追加一项手续费（金额为 0 时忽略）
+func (q *TransferQuote) addFee(feeType, description string, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	q.Fees = append(q.Fees, FeeItem{Type: feeType, Description: description, Amount: amount, Currency: q.Currency})
+	q.TotalFee = model.RoundAmount(q.TotalFee + amount)
+	q.TotalDebit = model.RoundAmount(q.Amount + q.TotalFee)
+}
+
+// 移除指定类型的手续费
+func (q *TransferQuote) removeFee(feeType string) {
+	fees := make([]FeeItem, 0, len(q.Fees)) // 报价副本与已存储报价共享底层数组，不能原地修改
+	for _, fee := range q.Fees {
+		if fee.Type == feeType {
+			q.TotalFee = model.RoundAmount(q.TotalFee - fee.Amount)
+			continue
+		}
+		fees = append(fees, fee)
+	}
+	q.Fees = fees
+	q.TotalDebit = model.RoundAmount(q.Amount + q.TotalFee)
+}
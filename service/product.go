@@ -0,0 +1,757 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 内置产品编号（未绑定产品的账户按账户类型使用）
+const (
+	PRODUCT_SAVINGS  = "SAV001" // 活期储蓄
+	PRODUCT_CHECKING = "CHK001" // 结算账户
+)
+
+// 计息天数基准（实际天数/365）
+const DAYS_PER_YEAR = 365
+
+// 复利频率：计提利息并入计息基数的周期（按月、按季在期末结息入账，按日逐日并入基数、月末结息入账）
+const (
+	COMPOUNDING_DAILY     = "daily"
+	COMPOUNDING_MONTHLY   = "monthly"
+	COMPOUNDING_QUARTERLY = "quarterly"
+)
+
+// 计息方式
+const (
+	INTEREST_COMPOUND = "compound" // 复利：已计提、已结息的利息计入计息基数
+	INTEREST_SIMPLE   = "simple"   // 单利：只按本金计息，结息周期仅决定入账时间
+)
+
+// 产品编号格式：大写字母、数字与连字符
+var productCodePattern = regexp.MustCompile(`^[A-Z0-9-]{2,16}$`)
+
+// 存款产品参数（金额类参数以人民币计，外币账户按中间价折算）
+type Product struct {
+	Code             string     `json:"code"`
+	Name             string     `json:"name"`
+	AccountType      string     `json:"accountType"`                // savings/checking
+	InterestRate     float64    `json:"interestRate"`               // 年利率（如 0.0035 表示 0.35%），按日计提、月末结息；设置分档利率时不使用
+	RateTiers        []RateTier `json:"rateTiers,omitempty"`        // 分档利率（按余额分段计息，各档只对落在本档的余额部分按本档利率计息）
+	Compounding      string     `json:"compounding"`                // 复利频率 daily/monthly/quarterly，默认 monthly
+	InterestMethod   string     `json:"interestMethod"`             // 计息方式 compound/simple，默认 compound
+	MonthlyFee       float64    `json:"monthlyFee"`                 // 账户管理费（月末收取）
+	MinBalance       float64    `json:"minBalance"`                 // 最低余额，0 表示不限制
+	MinBalancePolicy string     `json:"minBalancePolicy,omitempty"` // 跌破最低余额时的处理：block/fee
+	MinBalanceFee    float64    `json:"minBalanceFee,omitempty"`    // fee 策略下每笔转账收取的低余额管理费
+	SingleLimit      float64    `json:"singleTransferLimit"`        // 单笔转账限额，0 表示不限
+	DailyLimit       float64    `json:"dailyTransferLimit"`         // 日累计转账限额，0 表示不限
+	TenorDays        int        `json:"tenorDays"`                  // 存期（天），0 表示活期；定期产品到期前不能转出
+	EffectiveDate    string     `json:"effectiveDate"`              // 生效日期（模拟日期，2006-01-02），为空表示自下一次计息起生效
+}
+
+// 利率分档：余额不超过 UpTo 的部分按 Rate 计息（UpTo 以人民币计，最后一档为 0 表示不设上限）
+type RateTier struct {
+	UpTo float64 `json:"upTo"`
+	Rate float64 `json:"rate"`
+}
+
+// 计息分段：余额落在某一档位的部分及其适用利率
+type InterestSlice struct {
+	From   float64 `json:"from"`   // 档位下限（账户币种）
+	UpTo   float64 `json:"upTo"`   // 档位上限（账户币种），0 表示不设上限
+	Amount float64 `json:"amount"` // 落在本档的余额
+	Rate   float64 `json:"rate"`
+}
+
+// 产品参数版本表
+type ProductSchedule struct {
+	Code      string    `json:"code"`
+	Current   *Product  `json:"current,omitempty"`   // 当前生效的参数
+	Scheduled []Product `json:"scheduled,omitempty"` // 尚未生效的参数（按生效日期升序）
+}
+
+// 账户绑定产品请求结构体
+type ProductBindRequest struct {
+	AccountID   string `json:"accountId" validate:"required,account"`
+	ProductCode string `json:"productCode" validate:"required"`
+}
+
+// 账户产品信息
+type AccountProduct struct {
+	AccountID       string          `json:"accountId"`
+	Product         Product         `json:"product"`
+	AccruedInterest float64         `json:"accruedInterest"`          // 本期已计提、尚未结息的利息
+	NextPostingDate string          `json:"nextPostingDate"`          // 下一结息日
+	EffectiveRate   float64         `json:"effectiveRate"`            // 按当前余额的综合年利率（分档产品为各档加权）
+	InterestSlices  []InterestSlice `json:"interestSlices,omitempty"` // 分档产品按当前余额的计息分段
+	BoundAt         string          `json:"boundAt,omitempty"`
+	MaturityDate    string          `json:"maturityDate,omitempty"`
+	Scheduled       []Product       `json:"scheduled,omitempty"` // 该产品尚未生效的参数调整
+}
+
+// 内置产品
+var defaultProducts = []Product{
+	{
+		Code:             PRODUCT_SAVINGS,
+		Name:             "活期储蓄",
+		AccountType:      ACCOUNT_TYPE_SAVINGS,
+		InterestRate:     0.0035,
+		Compounding:      COMPOUNDING_MONTHLY,
+		InterestMethod:   INTEREST_COMPOUND,
+		MinBalance:       100,
+		MinBalancePolicy: MIN_BALANCE_BLOCK,
+		SingleLimit:      50000,
+		DailyLimit:       200000,
+	},
+	{
+		Code:             PRODUCT_CHECKING,
+		Name:             "结算账户",
+		AccountType:      ACCOUNT_TYPE_CHECKING,
+		InterestRate:     0.001,
+		Compounding:      COMPOUNDING_MONTHLY,
+		InterestMethod:   INTEREST_COMPOUND,
+		MonthlyFee:       5,
+		MinBalance:       1000,
+		MinBalancePolicy: MIN_BALANCE_FEE,
+		MinBalanceFee:    10,
+		SingleLimit:      200000,
+		DailyLimit:       1000000,
+	},
+	{
+		Code:        "SAVTIER",
+		Name:        "阶梯利率储蓄",
+		AccountType: ACCOUNT_TYPE_SAVINGS,
+		RateTiers: []RateTier{
+			{UpTo: 10000, Rate: 0.005},
+			{Rate: 0.012},
+		},
+		Compounding:    COMPOUNDING_MONTHLY,
+		InterestMethod: INTEREST_COMPOUND,
+		SingleLimit:    50000,
+		DailyLimit:     200000,
+	},
+	{
+		Code:           "TD12M",
+		Name:           "一年期定期存款",
+		AccountType:    ACCOUNT_TYPE_SAVINGS,
+		InterestRate:   0.0175,
+		Compounding:    COMPOUNDING_QUARTERLY,
+		InterestMethod: INTEREST_SIMPLE,
+		TenorDays:      365,
+	},
+}
+
+// 产品服务：产品参数（利率、费用、限额、存期）的定义与生效管理、账户绑定、日终计息
+type ProductService struct {
+	accounts *repository.AccountRepository
+	ledger   *LedgerService
+	fx       *FXService
+	tax      *TaxService
+	clock    Clock
+
+	mu       sync.Mutex           // 叶子锁：持有期间不获取账户锁
+	versions map[string][]Product // 产品编号 -> 参数版本（按生效日期升序）
+	accrued  map[string]float64   // 账户本期已计提利息（未舍入）
+	posted   map[string]float64   // 单利产品账户已结息入账的利息（税后），不计入计息基数
+	boundAt  map[string]time.Time // 账户绑定产品的时间
+}
+
+func NewProductService(accounts *repository.AccountRepository, ledger *LedgerService, fx *FXService, tax *TaxService, clock Clock) *ProductService {
+	s := &ProductService{
+		accounts: accounts,
+		ledger:   ledger,
+		fx:       fx,
+		tax:      tax,
+		clock:    clock,
+		versions: make(map[string][]Product),
+		accrued:  make(map[string]float64),
+		posted:   make(map[string]float64),
+		boundAt:  make(map[string]time.Time),
+	}
+	since := sim.StartOfDay(clock.Now()).AddDate(0, 0, -1).Format("2006-01-02")
+	for _, p := range defaultProducts {
+		p.EffectiveDate = since
+		s.versions[p.Code] = []Product{p}
+	}
+	return s
+}
+
+// 新增产品或调整产品参数：按生效日期登记为新版本，默认自下一次计息（当日日终）起生效
+func (s *ProductService) Define(p Product) (Product, error) {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	p.Name = strings.TrimSpace(p.Name)
+	if !productCodePattern.MatchString(p.Code) || p.Name == "" {
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "产品编号应为 2-16 位大写字母、数字或连字符，产品名称不能为空")
+	}
+	if p.AccountType != ACCOUNT_TYPE_SAVINGS && p.AccountType != ACCOUNT_TYPE_CHECKING {
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "账户类型应为 savings 或 checking")
+	}
+	if p.InterestRate < 0 || p.InterestRate >= 1 {
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "年利率应在 0 到 1 之间（如 0.0035 表示 0.35%）")
+	}
+	if err := validateRateTiers(p.RateTiers); err != nil {
+		return Product{}, err
+	}
+	if p.Compounding == "" {
+		p.Compounding = COMPOUNDING_MONTHLY
+	}
+	if p.Compounding != COMPOUNDING_DAILY && p.Compounding != COMPOUNDING_MONTHLY && p.Compounding != COMPOUNDING_QUARTERLY {
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "复利频率应为 daily、monthly 或 quarterly")
+	}
+	if p.InterestMethod == "" {
+		p.InterestMethod = INTEREST_COMPOUND
+	}
+	if p.InterestMethod != INTEREST_COMPOUND && p.InterestMethod != INTEREST_SIMPLE {
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "计息方式应为 compound 或 simple")
+	}
+	if p.MonthlyFee < 0 || p.MinBalance < 0 || p.MinBalanceFee < 0 || p.SingleLimit < 0 || p.DailyLimit < 0 || p.TenorDays < 0 {
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "费用、限额与存期不能为负数")
+	}
+	if p.MinBalance > 0 {
+		if p.MinBalancePolicy == "" {
+			p.MinBalancePolicy = MIN_BALANCE_BLOCK
+		}
+		if p.MinBalancePolicy != MIN_BALANCE_BLOCK && p.MinBalancePolicy != MIN_BALANCE_FEE {
+			return Product{}, model.NewError(model.CODE_PARAM_ERROR, "最低余额处理策略应为 block 或 fee")
+		}
+		if p.MinBalancePolicy == MIN_BALANCE_FEE && p.MinBalanceFee == 0 {
+			return Product{}, model.NewError(model.CODE_PARAM_ERROR, "fee 策略须设置低余额管理费")
+		}
+	} else {
+		p.MinBalancePolicy = ""
+	}
+	if p.MinBalancePolicy != MIN_BALANCE_FEE {
+		p.MinBalanceFee = 0
+	}
+
+	// 生效日期不能早于下一次计息日（当日日终计息）
+	nextAccrual := sim.StartOfDay(s.clock.Now())
+	effective := nextAccrual
+	if p.EffectiveDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", p.EffectiveDate, nextAccrual.Location())
+		if err != nil {
+			return Product{}, model.NewError(model.CODE_PARAM_ERROR, "生效日期格式应为 2006-01-02")
+		}
+		if date.Before(nextAccrual) {
+			return Product{}, model.NewError(model.CODE_PARAM_ERROR, "生效日期不能早于下一次计息日 "+nextAccrual.Format("2006-01-02"))
+		}
+		effective = date
+	}
+	p.EffectiveDate = effective.Format("2006-01-02")
+	p.InterestRate = roundRate(p.InterestRate)
+	if len(p.RateTiers) > 0 {
+		p.InterestRate = 0
+		for i := range p.RateTiers {
+			p.RateTiers[i].UpTo = model.RoundAmount(p.RateTiers[i].UpTo)
+			p.RateTiers[i].Rate = roundRate(p.RateTiers[i].Rate)
+		}
+	} else {
+		p.RateTiers = nil
+	}
+	p.MonthlyFee = model.RoundAmount(p.MonthlyFee)
+	p.MinBalance = model.RoundAmount(p.MinBalance)
+	p.MinBalanceFee = model.RoundAmount(p.MinBalanceFee)
+	p.SingleLimit = model.RoundAmount(p.SingleLimit)
+	p.DailyLimit = model.RoundAmount(p.DailyLimit)
+
+	s.mu.Lock()
+	versions := s.versions[p.Code]
+	if len(versions) > 0 && versions[0].AccountType != p.AccountType {
+		s.mu.Unlock()
+		return Product{}, model.NewError(model.CODE_PARAM_ERROR, "产品的账户类型不可修改")
+	}
+	// 同一生效日期的版本以最后一次定义为准
+	updated := make([]Product, 0, len(versions)+1)
+	for _, v := range versions {
+		if v.EffectiveDate != p.EffectiveDate {
+			updated = append(updated, v)
+		}
+	}
+	updated = append(updated, p)
+	sort.Slice(updated, func(i, j int) bool { return updated[i].EffectiveDate < updated[j].EffectiveDate })
+	s.versions[p.Code] = updated
+	s.mu.Unlock()
+
+	// 终端提示：产品参数登记
+	log.Println("\n[📦 产品参数登记]")
+	log.Printf("产品: %s %s（%s）", p.Code, p.Name, p.AccountType)
+	if len(p.RateTiers) > 0 {
+		log.Printf("分档利率: %s，账户管理费: %.2f 元/月", describeRateTiers(p.RateTiers), p.MonthlyFee)
+	} else {
+		log.Printf("年利率: %.4f%%，账户管理费: %.2f 元/月", p.InterestRate*100, p.MonthlyFee)
+	}
+	log.Printf("计息方式: %s，复利频率: %s", p.InterestMethod, p.Compounding)
+	if p.MinBalance > 0 {
+		log.Printf("最低余额: %.2f 元（%s）", p.MinBalance, p.MinBalancePolicy)
+	}
+	log.Printf("转账限额: 单笔 %.2f 元，日累计 %.2f 元（0 表示不限）", p.SingleLimit, p.DailyLimit)
+	if p.TenorDays > 0 {
+		log.Printf("存期: %d 天", p.TenorDays)
+	}
+	log.Printf("生效日期: %s", p.EffectiveDate)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return p, nil
+}
+
+// 产品列表（含当前生效参数与待生效参数）
+func (s *ProductService) List() []ProductSchedule {
+	today := sim.StartOfDay(s.clock.Now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make([]ProductSchedule, 0, len(s.versions))
+	for code := range s.versions {
+		schedules = append(schedules, s.schedule(code, today))
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Code < schedules[j].Code })
+	return schedules
+}
+
+// 产品版本表（调用方持有 s.mu）
+func (s *ProductService) schedule(code string, day time.Time) ProductSchedule {
+	schedule := ProductSchedule{Code: code}
+	date := day.Format("2006-01-02")
+	for _, v := range s.versions[code] {
+		if v.EffectiveDate <= date {
+			current := v
+			schedule.Current = &current
+		} else {
+			schedule.Scheduled = append(schedule.Scheduled, v)
+		}
+	}
+	return schedule
+}
+
+// 产品在指定日期生效的参数
+func (s *ProductService) Effective(code string, day time.Time) (Product, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule := s.schedule(code, day)
+	if schedule.Current == nil {
+		return Product{}, false
+	}
+	return *schedule.Current, true
+}
+
+// 账户在指定时间适用的产品参数（未绑定产品的账户按账户类型使用内置产品）
+func (s *ProductService) ProductFor(account model.Account, at time.Time) Product {
+	code := productCode(account)
+	if product, ok := s.Effective(code, at); ok {
+		return product
+	}
+	return Product{Code: code, AccountType: accountType(account)}
+}
+
+// 绑定账户与产品（产品须已生效），账户类型随产品调整
+func (s *ProductService) Bind(req ProductBindRequest) (AccountProduct, error) {
+	if err := model.Validate(req); err != nil {
+		return AccountProduct{}, err
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.ProductCode))
+	now := s.clock.Now()
+	product, ok := s.Effective(code, now)
+	if !ok {
+		return AccountProduct{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "产品不存在或尚未生效")
+	}
+
+	var oldCode string
+	account, err := s.accounts.Update(req.AccountID, 0, func(account *model.Account) error {
+		oldCode = productCode(*account)
+		account.ProductCode = product.Code
+		account.Type = product.AccountType
+		return nil
+	}, nil)
+	if err != nil {
+		return AccountProduct{}, err
+	}
+
+	s.mu.Lock()
+	s.boundAt[account.AccountID] = now
+	s.mu.Unlock()
+
+	// 终端提示：账户绑定产品
+	log.Println("\n[📦 账户绑定产品]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", account.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("产品: %s → %s（%s）", oldCode, product.Code, product.Name)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return s.AccountProduct(account.AccountID)
+}
+
+// 查询账户的产品信息、已计提利息与到期日
+func (s *ProductService) AccountProduct(accountID string) (AccountProduct, error) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return AccountProduct{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	now := s.clock.Now()
+	product := s.ProductFor(account, now)
+
+	result := AccountProduct{AccountID: accountID, Product: product}
+	slices := s.interestSlices(product, account.Balance, account.Currency)
+	result.EffectiveRate = effectiveRate(slices, account.Balance)
+	if len(product.RateTiers) > 0 {
+		result.InterestSlices = slices
+	}
+	if maturity, ok := s.Maturity(account, product); ok {
+		result.MaturityDate = maturity.Format("2006-01-02")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result.AccruedInterest = model.RoundAmount(s.accrued[accountID])
+	result.NextPostingDate = nextPostingDate(product, sim.StartOfDay(now)).Format("2006-01-02")
+	if bound, ok := s.boundAt[accountID]; ok {
+		result.BoundAt = bound.Format("2006-01-02 15:04:05")
+	}
+	result.Scheduled = s.schedule(product.Code, sim.StartOfDay(now)).Scheduled
+	return result, nil
+}
+
+// 定期产品的到期日（自绑定产品起算，未在运行期间绑定的按开户日期）
+func (s *ProductService) Maturity(account model.Account, product Product) (time.Time, bool) {
+	if product.TenorDays <= 0 {
+		return time.Time{}, false
+	}
+	s.mu.Lock()
+	start, ok := s.boundAt[account.AccountID]
+	s.mu.Unlock()
+	if !ok {
+		created, err := time.ParseInLocation("2006-01-02", account.CreateAt, time.Local)
+		if err != nil {
+			return time.Time{}, false
+		}
+		start = created
+	}
+	return sim.StartOfDay(start).AddDate(0, 0, product.TenorDays), true
+}
+
+// 账户自 day 零点起已转出的金额（行内与跨行转账，账户币种）
+func (s *ProductService) OutgoingSince(accountID string, day time.Time) float64 {
+	total := 0.0
+	for _, tx := range s.ledger.Transactions(accountID, sim.StartOfDay(day), time.Time{}) {
+		if tx.Type == "transfer_out" || tx.Type == "interbank_out" {
+			total += tx.Amount
+		}
+	}
+	return model.RoundAmount(total)
+}
+
+// 日终任务：按 day 当日生效的产品利率与计息方式逐户计提利息，结息日（月末或季末）结息（代扣利息税），
+// 月末收取账户管理费。复利按日时已计提利息逐日计入计息基数，单利时计息基数扣除已结息的利息
+func (s *ProductService) Accrue(day time.Time) {
+	monthEnd := day.AddDate(0, 0, 1).Day() == 1
+
+	type posting struct {
+		account  model.Account
+		interest float64
+		tax      float64
+		fee      float64
+	}
+	var postings []posting
+
+	s.accounts.Lock()
+	for _, id := range s.accounts.IDs() {
+		account, _ := s.accounts.Find(id)
+		product, ok := s.Effective(productCode(account), day)
+
+		s.mu.Lock()
+		base := account.Balance
+		if product.InterestMethod == INTEREST_SIMPLE {
+			base -= s.posted[id]
+		} else if product.Compounding == COMPOUNDING_DAILY {
+			base += s.accrued[id]
+		}
+		s.mu.Unlock()
+
+		daily, rate := 0.0, product.InterestRate
+		if ok && base > 0 {
+			slices := s.interestSlices(product, base, account.Currency)
+			rate = effectiveRate(slices, base)
+			daily = annualInterest(slices) / DAYS_PER_YEAR
+		}
+		post := postingDue(product, day)
+		s.mu.Lock()
+		s.accrued[id] += daily
+		accrued := s.accrued[id]
+		if post {
+			// 结息按分舍入，不足一分的尾差结转下期继续计提，避免逐期舍弃造成利息少计
+			if residual := accrued - model.RoundAmount(accrued); residual != 0 {
+				s.accrued[id] = residual
+			} else {
+				delete(s.accrued, id)
+			}
+		}
+		s.mu.Unlock()
+
+		if !monthEnd {
+			continue
+		}
+		p := posting{account: account}
+
+		// 结息：本期计提利息入账
+		if interest := model.RoundAmount(accrued); post && interest > 0 {
+			account.Balance = model.RoundAmount(account.Balance + interest)
+			account = s.accounts.Save(account)
+			s.ledger.Record(model.Transaction{
+				AccountID:    id,
+				Type:         "interest",
+				Direction:    "credit",
+				Amount:       interest,
+				BalanceAfter: account.Balance,
+				Description:  interestDescription(day, product, rate),
+			})
+			p.interest = interest
+
+			// 利息税：按结息金额代扣，单独记入应交税费
+			if tax := s.tax.Withhold(interest); tax > 0 {
+				account.Balance = model.RoundAmount(account.Balance - tax)
+				account = s.accounts.Save(account)
+				s.ledger.Record(model.Transaction{
+					AccountID:    id,
+					Type:         "withholding_tax",
+					Direction:    "debit",
+					Amount:       tax,
+					BalanceAfter: account.Balance,
+					Description:  fmt.Sprintf("%s利息代扣税（税率 %.2f%%）", day.Format("2006年01月"), s.tax.WithholdingRate()*100),
+				})
+				p.tax = tax
+			}
+			if product.InterestMethod == INTEREST_SIMPLE {
+				s.mu.Lock()
+				s.posted[id] += interest - p.tax
+				s.mu.Unlock()
+			}
+		}
+
+		// 账户管理费：可用余额（扣除冻结金额）不足时本月免收
+		if ok && product.MonthlyFee > 0 {
+			fee := s.fx.FromBase(product.MonthlyFee, account.Currency)
+			if account.Available() >= fee {
+				account.Balance = model.RoundAmount(account.Balance - fee)
+				account = s.accounts.Save(account)
+				s.ledger.Record(model.Transaction{
+					AccountID:    id,
+					Type:         "fee",
+					Direction:    "debit",
+					Amount:       fee,
+					BalanceAfter: account.Balance,
+					Description:  fmt.Sprintf("%s账户管理费（%s）", day.Format("2006年01月"), product.Name),
+				})
+				p.fee = fee
+			}
+		}
+		if p.interest > 0 || p.fee > 0 {
+			p.account = account
+			postings = append(postings, p)
+		}
+	}
+	s.accounts.Unlock()
+
+	if !monthEnd {
+		return
+	}
+
+	// 终端提示：月末结息
+	log.Println("\n[💹 月末结息]")
+	log.Printf("结息日期: %s", day.Format("2006-01-02"))
+	for _, p := range postings {
+		log.Printf("账户ID: %s | 用户名: %s | 利息: +%.2f %s | 利息税: -%.2f %s | 管理费: -%.2f %s | 余额: %.2f %s",
+			p.account.AccountID, p.account.UserName, p.interest, p.account.Currency, p.tax, p.account.Currency,
+			p.fee, p.account.Currency, p.account.Balance, p.account.Currency)
+	}
+	log.Printf("结息账户数: %d", len(postings))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 按余额划分计息分段：未设置分档时整笔余额按统一利率，设置分档时逐档切分（档位上限按中间价折算为账户币种）
+func (s *ProductService) interestSlices(product Product, balance float64, currency string) []InterestSlice {
+	if balance <= 0 {
+		return nil
+	}
+	if len(product.RateTiers) == 0 {
+		return []InterestSlice{{Amount: balance, Rate: product.InterestRate}}
+	}
+	var slices []InterestSlice
+	from := 0.0
+	for _, tier := range product.RateTiers {
+		upTo := 0.0
+		if tier.UpTo > 0 {
+			upTo = s.fx.FromBase(tier.UpTo, currency)
+		}
+		amount := balance - from
+		if upTo > 0 && balance > upTo {
+			amount = upTo - from
+		}
+		if amount <= 0 {
+			break
+		}
+		slices = append(slices, InterestSlice{From: from, UpTo: upTo, Amount: model.RoundAmount(amount), Rate: tier.Rate})
+		if upTo == 0 || balance <= upTo {
+			break
+		}
+		from = upTo
+	}
+	return slices
+}
+
+// 按分段计算的年利息（未舍入）
+func annualInterest(slices []InterestSlice) float64 {
+	interest := 0.0
+	for _, slice := range slices {
+		interest += slice.Amount * slice.Rate
+	}
+	return interest
+}
+
+// 综合年利率：各分段年利息之和除以余额
+func effectiveRate(slices []InterestSlice, balance float64) float64 {
+	if balance <= 0 {
+		return 0
+	}
+	return roundRate(annualInterest(slices) / balance)
+}
+
+// 结息流水说明
+func interestDescription(day time.Time, product Product, rate float64) string {
+	period := day.Format("2006年01月")
+	if product.Compounding == COMPOUNDING_QUARTERLY {
+		period = fmt.Sprintf("%d年第%d季度", day.Year(), (int(day.Month())-1)/3+1)
+	}
+	if len(product.RateTiers) > 0 {
+		return fmt.Sprintf("%s结息（%s，分档计息，期末综合年利率 %.4f%%）", period, product.Name, rate*100)
+	}
+	return fmt.Sprintf("%s结息（%s，年利率 %.4f%%）", period, product.Name, product.InterestRate*100)
+}
+
+// 是否为结息日：按季复利在季末（3、6、9、12 月末），其余在月末
+func postingDue(product Product, day time.Time) bool {
+	next := day.AddDate(0, 0, 1)
+	if next.Day() != 1 {
+		return false
+	}
+	return product.Compounding != COMPOUNDING_QUARTERLY || next.Month()%3 == 1
+}
+
+// 下一结息日（day 当日为结息日时返回当日）
+func nextPostingDate(product Product, day time.Time) time.Time {
+	end := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location())
+	for !postingDue(product, end) {
+		end = time.Date(end.Year(), end.Month()+2, 0, 0, 0, 0, 0, end.Location())
+	}
+	return end
+}
+
+// 校验分档利率：各档利率在 0 到 1 之间，上限严格递增，最后一档不设上限
+func validateRateTiers(tiers []RateTier) error {
+	for i, tier := range tiers {
+		if tier.Rate < 0 || tier.Rate >= 1 {
+			return model.NewError(model.CODE_PARAM_ERROR, "分档利率应在 0 到 1 之间（如 0.005 表示 0.5%）")
+		}
+		last := i == len(tiers)-1
+		if last && tier.UpTo != 0 {
+			return model.NewError(model.CODE_PARAM_ERROR, "最后一档利率不设上限（upTo 为 0）")
+		}
+		if !last && (tier.UpTo <= 0 || (i > 0 && tier.UpTo <= tiers[i-1].UpTo)) {
+			return model.NewError(model.CODE_PARAM_ERROR, "分档上限应为正数且逐档递增")
+		}
+	}
+	return nil
+}
+
+// 分档利率说明，如“≤10000.00 0.5000%，>10000.00 1.2000%”
+func describeRateTiers(tiers []RateTier) string {
+	parts := make([]string, 0, len(tiers))
+	for i, tier := range tiers {
+		if tier.UpTo > 0 {
+			parts = append(parts, fmt.Sprintf("≤%.2f %.4f%%", tier.UpTo, tier.Rate*100))
+		} else if i > 0 {
+			parts = append(parts, fmt.Sprintf(">%.2f %.4f%%", tiers[i-1].UpTo, tier.Rate*100))
+		} else {
+			parts = append(parts, fmt.Sprintf("%.4f%%", tier.Rate*100))
+		}
+	}
+	return strings.Join(parts, "，")
+}
+
+// 账户的产品编号（未绑定时按账户类型取内置产品）
+func productCode(account model.Account) string {
+	if account.ProductCode != "" {
+		return account.ProductCode
+	}
+	if accountType(account) == ACCOUNT_TYPE_CHECKING {
+		return PRODUCT_CHECKING
+	}
+	return PRODUCT_SAVINGS
+}
+
+// 利率保留 6 位小数
+func roundRate(rate float64) float64 {
+	return math.Round(rate*1e6) / 1e6
+}
+
+// 计息状态快照（随状态快照导出；产品定义与参数版本属于配置，不在快照范围内）
+type ProductSnapshot struct {
+	Accrued map[string]float64   `json:"accrued"` // 账户本期已计提利息（未舍入）
+	Posted  map[string]float64   `json:"posted"`  // 单利产品账户已结息入账的利息
+	BoundAt map[string]time.Time `json:"boundAt"` // 账户绑定产品的时间
+}
+
+// 导出计提利息、单利已结息与产品绑定时间
+func (s *ProductService) Snapshot() ProductSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := ProductSnapshot{
+		Accrued: make(map[string]float64, len(s.accrued)),
+		Posted:  make(map[string]float64, len(s.posted)),
+		BoundAt: make(map[string]time.Time, len(s.boundAt)),
+	}
+	for id, amount := range s.accrued {
+		snapshot.Accrued[id] = amount
+	}
+	for id, amount := range s.posted {
+		snapshot.Posted[id] = amount
+	}
+	for id, at := range s.boundAt {
+		snapshot.BoundAt[id] = at
+	}
+	return snapshot
+}
+
+// 以快照替换计提利息、单利已结息与产品绑定时间
+func (s *ProductService) Restore(snapshot ProductSnapshot) {
+	accrued := make(map[string]float64, len(snapshot.Accrued))
+	for id, amount := range snapshot.Accrued {
+		accrued[id] = amount
+	}
+	posted := make(map[string]float64, len(snapshot.Posted))
+	for id, amount := range snapshot.Posted {
+		posted[id] = amount
+	}
+	boundAt := make(map[string]time.Time, len(snapshot.BoundAt))
+	for id, at := range snapshot.BoundAt {
+		boundAt[id] = at
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accrued, s.posted, s.boundAt = accrued, posted, boundAt
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 测试时钟：由测试逐日推进
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+// 空推送
+type nopNotifier struct{}
+
+func (nopNotifier) Send(ws.Message)                     {}
+func (nopNotifier) Publish(string, string, interface{}) {}
+
+// 计息引擎与闭式公式对照：以一个非闰年（365 天，与计息基准一致）逐日推进模拟时钟执行日终计息，
+// 期末余额与复利 P(1+r/n)^(nt)、单利 P(1+r·t) 的差额不超过 1 分
+func TestAccrueMatchesClosedForm(t *testing.T) {
+	const (
+		principal = 100000.0
+		rate      = 0.05
+	)
+	cases := []struct {
+		name        string
+		compounding string
+		method      string
+		want        float64
+	}{
+		{"按日复利", COMPOUNDING_DAILY, INTEREST_COMPOUND, principal * math.Pow(1+rate/365, 365)},
+		{"按月复利", COMPOUNDING_MONTHLY, INTEREST_COMPOUND, principal * math.Pow(1+rate/12, 12)},
+		{"按季复利", COMPOUNDING_QUARTERLY, INTEREST_COMPOUND, principal * math.Pow(1+rate/4, 4)},
+		{"单利", COMPOUNDING_QUARTERLY, INTEREST_SIMPLE, principal * (1 + rate*1)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+			clock := &testClock{now: start}
+			products, accounts := newAccrualFixture(clock, Product{
+				Code:           "TEST",
+				Name:           "测试产品",
+				AccountType:    ACCOUNT_TYPE_SAVINGS,
+				InterestRate:   rate,
+				Compounding:    tc.compounding,
+				InterestMethod: tc.method,
+			}, principal)
+
+			for day := start; day.Year() == start.Year(); day = day.AddDate(0, 0, 1) {
+				clock.now = day.Add(23*time.Hour + 59*time.Minute)
+				products.Accrue(day)
+			}
+
+			account, _ := accounts.Get("6200000001")
+			if diff := math.Abs(account.Balance - tc.want); diff > 0.01 {
+				t.Fatalf("期末余额 %.4f，公式 %.4f，差额 %.4f 超过 1 分", account.Balance, tc.want, diff)
+			}
+		})
+	}
+}
+
+// 单利结息日之间的计提不随已结息利息增长：每个结息期入账利息只与本金和天数有关
+func TestAccrueSimpleInterestIgnoresPostedInterest(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	clock := &testClock{now: start}
+	products, accounts := newAccrualFixture(clock, Product{
+		Code:           "TEST",
+		Name:           "测试产品",
+		AccountType:    ACCOUNT_TYPE_SAVINGS,
+		InterestRate:   0.04,
+		Compounding:    COMPOUNDING_MONTHLY,
+		InterestMethod: INTEREST_SIMPLE,
+	}, 36500)
+
+	for day := start; day.Month() <= time.March && day.Year() == start.Year(); day = day.AddDate(0, 0, 1) {
+		clock.now = day.Add(23 * time.Hour)
+		products.Accrue(day)
+	}
+
+	// 每日利息 36500 × 4% / 365 = 4.00，一季度 90 天
+	account, _ := accounts.Get("6200000001")
+	if want := 36500 + 4.0*90; math.Abs(account.Balance-want) > 0.001 {
+		t.Fatalf("一季度末余额 %.2f，应为 %.2f", account.Balance, want)
+	}
+}
+
+// 月末管理费按可用余额判断：司法冻结等冻结金额不得用于扣收管理费
+func TestMonthlyFeeSkipsHeldFunds(t *testing.T) {
+	day := time.Date(2025, 1, 31, 0, 0, 0, 0, time.Local)
+	clock := &testClock{now: day}
+	products, accounts := newAccrualFixture(clock, Product{
+		Code:           "TEST",
+		Name:           "测试产品",
+		AccountType:    ACCOUNT_TYPE_CHECKING,
+		Compounding:    COMPOUNDING_MONTHLY,
+		InterestMethod: INTEREST_COMPOUND,
+		MonthlyFee:     5,
+	}, 1000)
+	accounts.Lock()
+	account, _ := accounts.Find("6200000001")
+	account.Held = 998
+	accounts.Save(account)
+	accounts.Unlock()
+
+	products.Accrue(day)
+
+	if account, _ = accounts.Get("6200000001"); account.Balance != 1000 {
+		t.Fatalf("可用余额 2.00 不足管理费 5.00，余额应保持 1000.00，实际 %.2f", account.Balance)
+	}
+}
+
+// 以单一账户与单一产品构造计息引擎（不代扣利息税；管理费等参数由传入的 product 决定）
+func newAccrualFixture(clock Clock, product Product, balance float64) (*ProductService, *repository.AccountRepository) {
+	accounts := repository.NewAccountRepository([]model.Account{{
+		AccountID:   "6200000001",
+		UserName:    "测试用户",
+		Balance:     balance,
+		Currency:    "CNY",
+		Type:        ACCOUNT_TYPE_SAVINGS,
+		ProductCode: product.Code,
+		Status:      "normal",
+	}})
+	journal := repository.NewJournalRepository()
+	ledger := NewLedgerService(accounts, journal, NewChartOfAccounts(nil), clock, nopNotifier{})
+	fx := NewFXService(FXConfig{}, nopNotifier{}, nil)
+	tax := NewTaxService(TaxConfig{}, accounts, journal)
+	products := NewProductService(accounts, ledger, fx, tax, clock)
+
+	product.EffectiveDate = clock.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	products.versions[product.Code] = []Product{product}
+	return products, accounts
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 营销活动类型
+const (
+	PROMO_SIGNUP        = "signup"       // 开户奖励
+	PROMO_DEPOSIT_MATCH = "depositMatch" // 存款匹配奖励
+	PROMO_REFERRAL      = "referral"     // 推荐奖励（推荐人与被推荐人）
+)
+
+// 营销活动状态
+const (
+	PROMO_STATUS_ACTIVE    = "active"
+	PROMO_STATUS_PAUSED    = "paused"
+	PROMO_STATUS_EXHAUSTED = "exhausted" // 预算用尽
+)
+
+// 各类奖励对应的交易类型（对方科目为营销费用）
+var promoTxTypes = map[string]string{
+	PROMO_SIGNUP:        "signup_bonus",
+	PROMO_DEPOSIT_MATCH: "deposit_match",
+	PROMO_REFERRAL:      "referral_bonus",
+}
+
+// 营销活动（金额均为人民币，仅人民币账户参与）
+type Campaign struct {
+	CampaignID    string  `json:"campaignId"`
+	Name          string  `json:"name" validate:"required"`
+	Type          string  `json:"type" validate:"oneof=signup depositMatch referral"` // signup/depositMatch/referral
+	Amount        float64 `json:"amount,omitempty" validate:"min=0"`                  // signup：开户奖励；referral：推荐人奖励
+	RefereeAmount float64 `json:"refereeAmount,omitempty" validate:"min=0"`           // referral：被推荐人奖励
+	MatchRate     float64 `json:"matchRate,omitempty"`                                // depositMatch：匹配比例（如 0.1 表示存 100 奖 10）
+	MinDeposit    float64 `json:"minDeposit,omitempty" validate:"min=0"`              // depositMatch：单笔存款起点
+	PerUserCap    float64 `json:"perUserCap" validate:"min=0"`                        // 每个账户累计奖励上限，0 表示不限
+	Budget        float64 `json:"budget" validate:"amount"`                           // 活动总预算
+	Spent         float64 `json:"spent"`                                              // 已发放金额
+	Status        string  `json:"status"`                                             // active/paused/exhausted
+	StartAt       string  `json:"startAt,omitempty"`                                  // 活动开始时间（模拟时间，2006-01-02 15:04:05），为空表示立即开始
+	EndAt         string  `json:"endAt,omitempty"`                                    // 活动结束时间，为空表示长期有效
+	CreatedAt     string  `json:"createdAt"`
+
+	startAt time.Time
+	endAt   time.Time
+}
+
+// 营销活动修改请求结构体（字段为空表示不变）
+type CampaignUpdateRequest struct {
+	Status     *string  `json:"status" validate:"omitempty,oneof=active paused"`
+	Budget     *float64 `json:"budget"`
+	PerUserCap *float64 `json:"perUserCap" validate:"omitempty,min=0"`
+}
+
+// 奖励发放记录
+type PromoReward struct {
+	CampaignID string  `json:"campaignId"`
+	Campaign   string  `json:"campaign"`
+	Type       string  `json:"type"`
+	AccountID  string  `json:"accountId"`
+	Amount     float64 `json:"amount"`
+	TxID       string  `json:"txId"`
+	Reason     string  `json:"reason"`
+	Time       string  `json:"time"`
+}
+
+// 营销服务：营销活动配置、奖励自动发放与预算/单户上限控制
+type PromoService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu        sync.Mutex // 需先于账户锁获取
+	campaigns map[string]*Campaign
+	awarded   map[string]map[string]float64 // 活动ID -> 账户ID -> 累计奖励
+	rewards   []PromoReward
+	seq       int
+}
+
+func NewPromoService(accounts *repository.AccountRepository, ledger *LedgerService, clock Clock, notifier Notifier, templates *TemplateRegistry) *PromoService {
+	return &PromoService{
+		accounts:  accounts,
+		ledger:    ledger,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		campaigns: make(map[string]*Campaign),
+		awarded:   make(map[string]map[string]float64),
+	}
+}
+
+// 创建营销活动
+func (s *PromoService) Create(c Campaign) (Campaign, error) {
+	if err := model.Validate(c); err != nil {
+		return Campaign{}, err
+	}
+	c.Name = strings.TrimSpace(c.Name)
+	switch c.Type {
+	case PROMO_SIGNUP:
+		if c.Amount <= 0 {
+			return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "开户奖励金额必须大于0")
+		}
+	case PROMO_DEPOSIT_MATCH:
+		if c.MatchRate <= 0 || c.MatchRate > 1 {
+			return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "存款匹配比例应在 0 到 1 之间")
+		}
+		if c.PerUserCap <= 0 {
+			return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "存款匹配活动须设置单户奖励上限")
+		}
+	case PROMO_REFERRAL:
+		if c.Amount <= 0 && c.RefereeAmount <= 0 {
+			return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "推荐人或被推荐人奖励至少设置一项")
+		}
+	}
+
+	now := s.clock.Now()
+	var err error
+	if c.startAt, err = parseSimTime(c.StartAt); err != nil {
+		return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "开始时间格式应为 2006-01-02 15:04:05")
+	}
+	if c.endAt, err = parseSimTime(c.EndAt); err != nil {
+		return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "结束时间格式应为 2006-01-02 15:04:05")
+	}
+	if !c.endAt.IsZero() && !c.endAt.After(now) {
+		return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "结束时间须晚于当前时间")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	c.CampaignID = fmt.Sprintf("PC%s%04d", now.Format("20060102"), s.seq)
+	c.Amount = model.RoundAmount(c.Amount)
+	c.RefereeAmount = model.RoundAmount(c.RefereeAmount)
+	c.MinDeposit = model.RoundAmount(c.MinDeposit)
+	c.PerUserCap = model.RoundAmount(c.PerUserCap)
+	c.Budget = model.RoundAmount(c.Budget)
+	c.Spent = 0
+	c.Status = PROMO_STATUS_ACTIVE
+	c.CreatedAt = now.Format("2006-01-02 15:04:05")
+	s.campaigns[c.CampaignID] = &c
+	s.awarded[c.CampaignID] = make(map[string]float64)
+
+	// 终端提示：营销活动创建
+	log.Println("\n[🎁 营销活动创建]")
+	log.Printf("活动ID: %s", c.CampaignID)
+	log.Printf("活动名称: %s（%s）", c.Name, c.Type)
+	log.Printf("活动预算: %.2f 元，单户上限: %.2f 元（0 表示不限）", c.Budget, c.PerUserCap)
+	if c.StartAt != "" || c.EndAt != "" {
+		log.Printf("活动时间: %s ~ %s", c.StartAt, c.EndAt)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return c, nil
+}
+
+// 修改营销活动：暂停/恢复、调整预算与单户上限
+func (s *PromoService) Update(campaignID string, req CampaignUpdateRequest) (Campaign, error) {
+	if err := model.Validate(req); err != nil {
+		return Campaign{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.campaigns[campaignID]
+	if !ok {
+		return Campaign{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "营销活动不存在")
+	}
+	updated := *c
+	if req.Budget != nil {
+		if *req.Budget < updated.Spent {
+			return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("活动预算不能低于已发放金额 %.2f 元", updated.Spent))
+		}
+		updated.Budget = model.RoundAmount(*req.Budget)
+	}
+	if req.PerUserCap != nil {
+		if updated.Type == PROMO_DEPOSIT_MATCH && *req.PerUserCap == 0 {
+			return Campaign{}, model.NewError(model.CODE_PARAM_ERROR, "存款匹配活动须设置单户奖励上限")
+		}
+		updated.PerUserCap = model.RoundAmount(*req.PerUserCap)
+	}
+	if req.Status != nil {
+		updated.Status = *req.Status
+	}
+	// 追加预算后恢复已用尽的活动
+	if updated.Status == PROMO_STATUS_EXHAUSTED && updated.Spent < updated.Budget {
+		updated.Status = PROMO_STATUS_ACTIVE
+	}
+	if updated.Status == PROMO_STATUS_ACTIVE && updated.Spent >= updated.Budget {
+		updated.Status = PROMO_STATUS_EXHAUSTED
+	}
+	*c = updated
+	return updated, nil
+}
+
+// 营销活动列表（按创建顺序）
+func (s *PromoService) List() []Campaign {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Campaign, 0, len(s.campaigns))
+	for _, c := range s.campaigns {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CampaignID < result[j].CampaignID })
+	return result
+}
+
+// 奖励发放记录（按时间倒序），accountID 为空表示全部账户
+func (s *PromoService) Rewards(accountID string) []PromoReward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []PromoReward{}
+	for i := len(s.rewards) - 1; i >= 0; i-- {
+		if accountID == "" || s.rewards[i].AccountID == accountID {
+			result = append(result, s.rewards[i])
+		}
+	}
+	return result
+}
+
+// 开户事件：发放开户奖励；referrerID 非空时向推荐人与被推荐人发放推荐奖励
+func (s *PromoService) OnSignUp(account model.Account, referrerID string) []PromoReward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rewards []PromoReward
+	for _, c := range s.activeCampaigns() {
+		switch c.Type {
+		case PROMO_SIGNUP:
+			rewards = s.award(c, account.AccountID, c.Amount, "开户奖励", rewards)
+		case PROMO_REFERRAL:
+			if referrerID == "" {
+				continue
+			}
+			rewards = s.award(c, referrerID, c.Amount, "推荐 "+account.UserName+" 开户奖励", rewards)
+			rewards = s.award(c, account.AccountID, c.RefereeAmount, "受邀开户奖励", rewards)
+		}
+	}
+	return rewards
+}
+
+// 存款事件：按存款匹配活动发放奖励
+func (s *PromoService) OnDeposit(accountID string, amount float64) []PromoReward {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rewards []PromoReward
+	for _, c := range s.activeCampaigns() {
+		if c.Type != PROMO_DEPOSIT_MATCH || amount < c.MinDeposit {
+			continue
+		}
+		rewards = s.award(c, accountID, math.Floor(amount*c.MatchRate*100)/100,
+			fmt.Sprintf("存款 %.2f 元匹配奖励", amount), rewards)
+	}
+	return rewards
+}
+
+// 当前有效的活动（按创建顺序，调用方持有 s.mu）
+func (s *PromoService) activeCampaigns() []*Campaign {
+	now := s.clock.Now()
+	var result []*Campaign
+	for _, c := range s.campaigns {
+		if c.Status != PROMO_STATUS_ACTIVE {
+			continue
+		}
+		if (!c.startAt.IsZero() && now.Before(c.startAt)) || (!c.endAt.IsZero() && !now.Before(c.endAt)) {
+			continue
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CampaignID < result[j].CampaignID })
+	return result
+}
+
+// 向账户发放奖励：受活动剩余预算与单户上限约束，仅正常状态的人民币账户可获奖（调用方持有 s.mu）
+func (s *PromoService) award(c *Campaign, accountID string, amount float64, reason string, rewards []PromoReward) []PromoReward {
+	if amount <= 0 {
+		return rewards
+	}
+	if remaining := model.RoundAmount(c.Budget - c.Spent); amount > remaining {
+		amount = remaining
+	}
+	if c.PerUserCap > 0 {
+		if remaining := model.RoundAmount(c.PerUserCap - s.awarded[c.CampaignID][accountID]); amount > remaining {
+			amount = remaining
+		}
+	}
+	if amount <= 0 {
+		return rewards
+	}
+
+	s.accounts.Lock()
+	account, exists := s.accounts.Find(accountID)
+	if !exists || account.Status != "normal" || account.Currency != model.BASE_CURRENCY {
+		s.accounts.Unlock()
+		return rewards
+	}
+	account.Balance = model.RoundAmount(account.Balance + amount)
+	account = s.accounts.Save(account)
+	tx := s.ledger.Record(model.Transaction{
+		AccountID:    accountID,
+		Type:         promoTxTypes[c.Type],
+		Direction:    "credit",
+		Amount:       amount,
+		BalanceAfter: account.Balance,
+		Reference:    c.CampaignID,
+		Description:  c.Name + " - " + reason,
+	})
+	s.accounts.Unlock()
+
+	c.Spent = model.RoundAmount(c.Spent + amount)
+	s.awarded[c.CampaignID][accountID] = model.RoundAmount(s.awarded[c.CampaignID][accountID] + amount)
+	if c.Spent >= c.Budget {
+		c.Status = PROMO_STATUS_EXHAUSTED
+	}
+	reward := PromoReward{
+		CampaignID: c.CampaignID,
+		Campaign:   c.Name,
+		Type:       c.Type,
+		AccountID:  accountID,
+		Amount:     amount,
+		TxID:       tx.TxID,
+		Reason:     reason,
+		Time:       s.clock.Now().Format("2006-01-02 15:04:05"),
+	}
+	s.rewards = append(s.rewards, reward)
+
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  accountID,
+		NewBalance: account.Balance,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_PROMO_BONUS, map[string]interface{}{
+		"Amount":   amount,
+		"Balance":  account.Balance,
+		"Campaign": c.Name,
+		"Reason":   reason,
+	}))
+
+	// 终端提示：营销奖励发放
+	log.Println("\n[🎁 营销奖励发放]")
+	log.Printf("活动: %s (%s)", c.Name, c.CampaignID)
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("奖励原因: %s", reason)
+	log.Printf("奖励金额: \033[1;32m%.2f 元\033[0m", amount) // 绿色高亮
+	log.Printf("活动已发放: %.2f / %.2f 元（%s）", c.Spent, c.Budget, c.Status)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return append(rewards, reward)
+}
+
+// 解析模拟时间字符串（为空返回零值）
+func parseSimTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 试算平衡表中的一行（客户账户或内部账户）
+type TrialBalanceLine struct {
+	Account     string  `json:"account"` // 客户账号或总账科目代码
+	Name        string  `json:"name,omitempty"`
+	Kind        string  `json:"kind"` // customer/internal
+	Currency    string  `json:"currency"`
+	DebitTotal  float64 `json:"debitTotal"`
+	CreditTotal float64 `json:"creditTotal"`
+	Balance     float64 `json:"balance"` // 贷方 - 借方
+}
+
+// 试算平衡表币种合计
+type TrialBalanceTotal struct {
+	Currency    string  `json:"currency"`
+	DebitTotal  float64 `json:"debitTotal"`
+	CreditTotal float64 `json:"creditTotal"`
+	Difference  float64 `json:"difference"`
+}
+
+// 试算平衡表
+type TrialBalance struct {
+	GeneratedAt   string              `json:"generatedAt"`
+	Balanced      bool                `json:"balanced"`
+	Lines         []TrialBalanceLine  `json:"lines"`
+	Totals        []TrialBalanceTotal `json:"totals"`
+	Discrepancies []string            `json:"discrepancies"`
+}
+
+// 对账结果
+type ReconciliationResult struct {
+	Date          string   `json:"date"`
+	RunAt         string   `json:"runAt"`
+	Balanced      bool     `json:"balanced"`
+	Discrepancies []string `json:"discrepancies"`
+}
+
+// 生成试算平衡表，并核对各币种借贷是否轧平、账户余额是否与流水一致
+func (s *LedgerService) TrialBalance() TrialBalance {
+	s.accounts.RLock()
+	defer s.accounts.RUnlock()
+
+	lines := make(map[string]*TrialBalanceLine)
+	post := func(account, kind, currency, direction string, amount float64) {
+		key := account + "/" + currency
+		line, ok := lines[key]
+		if !ok {
+			line = &TrialBalanceLine{Account: account, Kind: kind, Currency: currency}
+			if kind == "internal" {
+				line.Name = s.chart.Account(account).Name
+			}
+			lines[key] = line
+		}
+		if direction == "debit" {
+			line.DebitTotal += amount
+		} else {
+			line.CreditTotal += amount
+		}
+	}
+	s.journal.View(func(transactions []model.Transaction, entries []model.LedgerEntry) {
+		for _, tx := range transactions {
+			post(tx.AccountID, "customer", tx.Currency, tx.Direction, tx.Amount)
+		}
+		for _, entry := range entries {
+			post(entry.Account, "internal", entry.Currency, entry.Direction, entry.Amount)
+		}
+	})
+
+	tb := TrialBalance{
+		GeneratedAt:   s.clock.Now().Format("2006-01-02 15:04:05"),
+		Lines:         make([]TrialBalanceLine, 0, len(lines)),
+		Discrepancies: make([]string, 0),
+	}
+	totals := make(map[string]*TrialBalanceTotal)
+	for _, line := range lines {
+		line.DebitTotal = model.RoundAmount(line.DebitTotal)
+		line.CreditTotal = model.RoundAmount(line.CreditTotal)
+		line.Balance = model.RoundAmount(line.CreditTotal - line.DebitTotal)
+		tb.Lines = append(tb.Lines, *line)
+
+		total, ok := totals[line.Currency]
+		if !ok {
+			total = &TrialBalanceTotal{Currency: line.Currency}
+			totals[line.Currency] = total
+		}
+		total.DebitTotal += line.DebitTotal
+		total.CreditTotal += line.CreditTotal
+	}
+	sort.Slice(tb.Lines, func(i, j int) bool {
+		if tb.Lines[i].Kind != tb.Lines[j].Kind {
+			return tb.Lines[i].Kind == "customer"
+		}
+		if tb.Lines[i].Account != tb.Lines[j].Account {
+			return tb.Lines[i].Account < tb.Lines[j].Account
+		}
+		return tb.Lines[i].Currency < tb.Lines[j].Currency
+	})
+
+	// 检查一：各币种全部分录借贷轧平
+	for _, total := range totals {
+		total.DebitTotal = model.RoundAmount(total.DebitTotal)
+		total.CreditTotal = model.RoundAmount(total.CreditTotal)
+		total.Difference = model.RoundAmount(total.CreditTotal - total.DebitTotal)
+		tb.Totals = append(tb.Totals, *total)
+		if total.Difference != 0 {
+			tb.Discrepancies = append(tb.Discrepancies,
+				fmt.Sprintf("%s 借贷不平：借方 %.2f，贷方 %.2f，差额 %.2f",
+					total.Currency, total.DebitTotal, total.CreditTotal, total.Difference))
+		}
+	}
+	sort.Slice(tb.Totals, func(i, j int) bool { return tb.Totals[i].Currency < tb.Totals[j].Currency })
+
+	// 检查二：账户余额与流水累计一致
+	for _, id := range s.accounts.IDs() {
+		account, _ := s.accounts.Find(id)
+		var journalBalance float64
+		if line, ok := lines[id+"/"+account.Currency]; ok {
+			journalBalance = line.Balance
+		}
+		if diff := model.RoundAmount(account.Balance - journalBalance); diff != 0 {
+			tb.Discrepancies = append(tb.Discrepancies,
+				fmt.Sprintf("账户 %s 余额 %.2f 与流水累计 %.2f 不符，差额 %.2f",
+					id, account.Balance, journalBalance, diff))
+		}
+	}
+
+	tb.Balanced = len(tb.Discrepancies) == 0
+	return tb
+}
+
+// 日终任务：对账检查，发现差异时推送告警
+func (s *LedgerService) Reconcile(day time.Time) {
+	tb := s.TrialBalance()
+	result := &ReconciliationResult{
+		Date:          day.Format("2006-01-02"),
+		RunAt:         tb.GeneratedAt,
+		Balanced:      tb.Balanced,
+		Discrepancies: tb.Discrepancies,
+	}
+
+	s.reconcileMutex.Lock()
+	s.lastReconciliation = result
+	s.reconcileMutex.Unlock()
+
+	if result.Balanced {
+		return
+	}
+
+	// 终端提示：对账差异告警
+	log.Println("\n[🚨 对账差异告警]")
+	log.Printf("对账日期: %s", result.Date)
+	for _, d := range result.Discrepancies {
+		log.Printf("\033[1;31m%s\033[0m", d) // 红色高亮
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.notifier.Publish("alerts", "reconciliationAlert", result)
+}
+
+// 最近一次日终对账结果（尚未执行时为 nil）
+func (s *LedgerService) LastReconciliation() *ReconciliationResult {
+	s.reconcileMutex.RLock()
+	defer s.reconcileMutex.RUnlock()
+
+	return s.lastReconciliation
+}
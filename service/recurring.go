@@ -0,0 +1,224 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 周期性支出识别参数
+const (
+	RECURRING_MIN_OCCURRENCES  = 3    // 至少出现的次数
+	RECURRING_AMOUNT_TOLERANCE = 0.15 // 金额与中位数的最大偏差比例
+)
+
+// 周期性支出状态
+const (
+	RECURRING_ACTIVE = "active" // 按周期持续发生
+	RECURRING_LAPSED = "lapsed" // 超过预计日期仍未发生（可能已停止）
+)
+
+// 支持识别的支付周期：标准间隔天数与允许偏差
+var recurringFrequencies = []struct {
+	Name      string
+	Days      float64
+	Tolerance float64
+	next      func(time.Time) time.Time
+}{
+	{"weekly", 7, 1, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }},
+	{"biweekly", 14, 2, func(t time.Time) time.Time { return t.AddDate(0, 0, 14) }},
+	{"monthly", 30.4, 3.5, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"quarterly", 91.3, 7, func(t time.Time) time.Time { return t.AddDate(0, 3, 0) }},
+	{"yearly", 365.25, 10, func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// 识别出的周期性支出
+type RecurringPayment struct {
+	Payee        string   `json:"payee"`        // 收款方（收入为付款方）：对方账户、商户或摘要
+	Direction    string   `json:"direction"`    // debit（支出）/credit（收入）
+	Category     string   `json:"category"`     // 现金流类别
+	Frequency    string   `json:"frequency"`    // weekly/biweekly/monthly/quarterly/yearly
+	IntervalDays float64  `json:"intervalDays"` // 实际平均间隔天数
+	Occurrences  int      `json:"occurrences"`  // 已发生次数
+	Currency     string   `json:"currency"`
+	AvgAmount    float64  `json:"avgAmount"`  // 平均金额
+	LastAmount   float64  `json:"lastAmount"` // 最近一次金额
+	LastDate     string   `json:"lastDate"`   // 最近一次日期
+	NextDate     string   `json:"nextDate"`   // 预计下次日期
+	NextAmount   float64  `json:"nextAmount"` // 预计下次金额（最近三次的平均值）
+	Status       string   `json:"status"`     // active/lapsed
+	TxIDs        []string `json:"txIds"`      // 构成该周期的流水号（按时间正序）
+	Confidence   float64  `json:"confidence"` // 0~1，间隔与金额越稳定越高
+}
+
+// 周期性支出识别服务：分析账户支出流水，识别同一收款方、金额相近、间隔规律的支出，并预测下次日期与金额
+type RecurringService struct {
+	accounts *repository.AccountRepository
+	ledger   *LedgerService
+	clock    Clock
+}
+
+func NewRecurringService(accounts *repository.AccountRepository, ledger *LedgerService, clock Clock) *RecurringService {
+	return &RecurringService{accounts: accounts, ledger: ledger, clock: clock}
+}
+
+// 识别账户的周期性支出（按预计下次日期正序）
+func (s *RecurringService) Detect(accountID string) ([]RecurringPayment, error) {
+	return s.detect(accountID, "debit")
+}
+
+// 识别账户的周期性收入（如工资），规则与支出相同
+func (s *RecurringService) DetectIncoming(accountID string) ([]RecurringPayment, error) {
+	return s.detect(accountID, "credit")
+}
+
+func (s *RecurringService) detect(accountID, direction string) ([]RecurringPayment, error) {
+	account, exists := s.accounts.Find(accountID)
+	if !exists {
+		return nil, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	// 按收款方分组（同一收款方的不同交易类别分开识别）
+	groups := make(map[string][]model.Transaction)
+	keys := make([]string, 0)
+	for _, tx := range s.ledger.Transactions(accountID, time.Time{}, time.Time{}) {
+		if tx.Direction != direction || tx.Amount <= 0 {
+			continue
+		}
+		key := cashflowCategory(tx.Type) + "|" + recurringPayee(tx)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], tx)
+	}
+
+	now := s.clock.Now()
+	result := make([]RecurringPayment, 0)
+	for _, key := range keys {
+		if payment, ok := detectRecurring(groups[key], now); ok {
+			payment.Currency = account.Currency
+			result = append(result, payment)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NextDate != result[j].NextDate {
+			return result[i].NextDate < result[j].NextDate
+		}
+		return result[i].Payee < result[j].Payee
+	})
+	return result, nil
+}
+
+// 收款方：优先对方账户/商户，其次为摘要
+func recurringPayee(tx model.Transaction) string {
+	if payee := strings.TrimSpace(tx.Counterparty); payee != "" {
+		return payee
+	}
+	return strings.TrimSpace(tx.Description)
+}
+
+// 识别同一收款方的支出是否构成周期：剔除金额偏离中位数过多的流水后，相邻间隔须全部落在同一周期的允许偏差内
+func detectRecurring(transactions []model.Transaction, now time.Time) (RecurringPayment, bool) {
+	if len(transactions) < RECURRING_MIN_OCCURRENCES {
+		return RecurringPayment{}, false
+	}
+	amounts := make([]float64, 0, len(transactions))
+	for _, tx := range transactions {
+		amounts = append(amounts, tx.Amount)
+	}
+	median := medianOf(amounts)
+
+	similar := make([]model.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if math.Abs(tx.Amount-median) <= median*RECURRING_AMOUNT_TOLERANCE {
+			similar = append(similar, tx)
+		}
+	}
+	if len(similar) < RECURRING_MIN_OCCURRENCES {
+		return RecurringPayment{}, false
+	}
+	sort.SliceStable(similar, func(i, j int) bool { return similar[i].Time.Before(similar[j].Time) })
+
+	intervals := make([]float64, 0, len(similar)-1)
+	for i := 1; i < len(similar); i++ {
+		intervals = append(intervals, similar[i].Time.Sub(similar[i-1].Time).Hours()/24)
+	}
+	for _, freq := range recurringFrequencies {
+		deviation := 0.0
+		matched := true
+		for _, days := range intervals {
+			if math.Abs(days-freq.Days) > freq.Tolerance {
+				matched = false
+				break
+			}
+			deviation += math.Abs(days-freq.Days) / freq.Tolerance
+		}
+		if !matched {
+			continue
+		}
+
+		last := similar[len(similar)-1]
+		next := freq.next(last.Time)
+		recent := similar[len(similar)-RECURRING_MIN_OCCURRENCES:]
+		total, recentTotal, amountDeviation := 0.0, 0.0, 0.0
+		for _, tx := range similar {
+			total += tx.Amount
+			amountDeviation += math.Abs(tx.Amount-median) / (median * RECURRING_AMOUNT_TOLERANCE)
+		}
+		for _, tx := range recent {
+			recentTotal += tx.Amount
+		}
+
+		payment := RecurringPayment{
+			Payee:        recurringPayee(last),
+			Direction:    last.Direction,
+			Category:     cashflowCategory(last.Type),
+			Frequency:    freq.Name,
+			IntervalDays: math.Round(last.Time.Sub(similar[0].Time).Hours()/24/float64(len(intervals))*10) / 10,
+			Occurrences:  len(similar),
+			AvgAmount:    model.RoundAmount(total / float64(len(similar))),
+			LastAmount:   last.Amount,
+			LastDate:     last.Time.Format("2006-01-02"),
+			NextDate:     next.Format("2006-01-02"),
+			NextAmount:   model.RoundAmount(recentTotal / float64(len(recent))),
+			Status:       RECURRING_ACTIVE,
+			TxIDs:        make([]string, 0, len(similar)),
+		}
+		if now.After(next.Add(time.Duration(freq.Tolerance*24) * time.Hour)) {
+			payment.Status = RECURRING_LAPSED
+		}
+		for _, tx := range similar {
+			payment.TxIDs = append(payment.TxIDs, tx.TxID)
+		}
+		// 间隔与金额的平均偏差各占一半，次数越多越可信
+		stability := 1 - (deviation/float64(len(intervals))+amountDeviation/float64(len(similar)))/2
+		support := math.Min(1, float64(len(similar))/6)
+		payment.Confidence = math.Round(stability*(0.5+0.5*support)*100) / 100
+		return payment, true
+	}
+	return RecurringPayment{}, false
+}
+
+// 按周期推算下一次日期；未知周期返回 false
+func nextRecurringDate(frequency string, t time.Time) (time.Time, bool) {
+	for _, freq := range recurringFrequencies {
+		if freq.Name == frequency {
+			return freq.next(t), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 监管报表类型
+const (
+	REPORT_LARGE_CASH = "large-cash" // 大额现金交易报告
+	REPORT_DAILY_CASH = "daily-cash" // 每日现金存取汇总
+)
+
+// 现金交易方向
+const (
+	CASH_DEPOSIT    = "deposit"    // 现金存入
+	CASH_WITHDRAWAL = "withdrawal" // 现金支取
+)
+
+// 大额认定规则
+const (
+	LARGE_CASH_SINGLE    = "single"    // 单笔达到起报金额
+	LARGE_CASH_AGGREGATE = "aggregate" // 当日同方向累计达到起报金额
+)
+
+// 纳入监管报表的现金交易类型 → 方向
+var regulatoryCashTypes = map[string]string{
+	"deposit":         CASH_DEPOSIT,
+	"teller_deposit":  CASH_DEPOSIT,
+	"teller_withdraw": CASH_WITHDRAWAL,
+}
+
+// 监管报表配置
+type RegulatoryConfig struct {
+	LargeCashThreshold float64 // 大额现金交易起报金额（人民币，外币按中间价折算）
+}
+
+// 大额现金交易报告明细（同一账户、同一日、同一方向合并为一条）
+type LargeCashRecord struct {
+	Date      string   `json:"date"`
+	AccountID string   `json:"accountId"`
+	UserName  string   `json:"userName"`
+	Direction string   `json:"direction"` // deposit/withdrawal
+	Rule      string   `json:"rule"`      // single/aggregate
+	Count     int      `json:"count"`
+	Amount    float64  `json:"amount"`
+	Currency  string   `json:"currency"`
+	AmountCNY float64  `json:"amountCny"`
+	TxIDs     []string `json:"txIds"`
+}
+
+// 每日现金存取汇总（按日期、币种）
+type DailyCashAggregate struct {
+	Date             string  `json:"date"`
+	Currency         string  `json:"currency"`
+	DepositCount     int     `json:"depositCount"`
+	DepositAmount    float64 `json:"depositAmount"`
+	WithdrawalCount  int     `json:"withdrawalCount"`
+	WithdrawalAmount float64 `json:"withdrawalAmount"`
+	NetAmount        float64 `json:"netAmount"` // 存入 - 支取
+	Accounts         int     `json:"accounts"`  // 发生现金交易的账户数
+}
+
+// 监管报表
+type RegulatoryReport struct {
+	Report      string               `json:"report"`
+	From        string               `json:"from"`
+	To          string               `json:"to"`
+	Threshold   float64              `json:"threshold,omitempty"` // 大额现金交易起报金额（人民币）
+	RecordCount int                  `json:"recordCount"`
+	LargeCash   []LargeCashRecord    `json:"largeCash,omitempty"`
+	DailyCash   []DailyCashAggregate `json:"dailyCash,omitempty"`
+	GeneratedAt string               `json:"generatedAt"`
+}
+
+// 监管报表服务：基于交易流水生成大额现金交易报告与每日现金存取汇总
+type RegulatoryService struct {
+	cfg      RegulatoryConfig
+	accounts *repository.AccountRepository
+	journal  *repository.JournalRepository
+	fx       *FXService
+	clock    Clock
+}
+
+func NewRegulatoryService(cfg RegulatoryConfig, accounts *repository.AccountRepository, journal *repository.JournalRepository, fx *FXService, clock Clock) *RegulatoryService {
+	return &RegulatoryService{
+		cfg:      cfg,
+		accounts: accounts,
+		journal:  journal,
+		fx:       fx,
+		clock:    clock,
+	}
+}
+
+// 生成 [from, to] 日期区间（含首尾）的监管报表
+func (s *RegulatoryService) Report(report string, from, to time.Time) (RegulatoryReport, error) {
+	result := RegulatoryReport{
+		Report:      report,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		GeneratedAt: s.clock.Now().Format("2006-01-02 15:04:05"),
+	}
+	cash := s.cashTransactions(from, to.AddDate(0, 0, 1))
+
+	switch report {
+	case REPORT_LARGE_CASH:
+		result.Threshold = s.cfg.LargeCashThreshold
+		result.LargeCash = s.largeCash(cash)
+		result.RecordCount = len(result.LargeCash)
+	case REPORT_DAILY_CASH:
+		result.DailyCash = dailyCash(cash)
+		result.RecordCount = len(result.DailyCash)
+	default:
+		return RegulatoryReport{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "报表不存在，可选 large-cash/daily-cash")
+	}
+	return result, nil
+}
+
+// 区间内的现金交易（按时间顺序）
+func (s *RegulatoryService) cashTransactions(from, to time.Time) []model.Transaction {
+	var result []model.Transaction
+	s.journal.View(func(transactions []model.Transaction, _ []model.LedgerEntry) {
+		for _, tx := range transactions {
+			if _, ok := regulatoryCashTypes[tx.Type]; ok && !tx.Time.Before(from) && tx.Time.Before(to) {
+				result = append(result, tx)
+			}
+		}
+	})
+	return result
+}
+
+// 大额现金交易：单笔或当日同方向累计折合人民币达到起报金额
+func (s *RegulatoryService) largeCash(cash []model.Transaction) []LargeCashRecord {
+	groups := make(map[string]*LargeCashRecord)
+	single := make(map[string]bool)
+	var keys []string
+	for _, tx := range cash {
+		date, direction := tx.Time.Format("2006-01-02"), regulatoryCashTypes[tx.Type]
+		key := date + "|" + tx.AccountID + "|" + direction
+		record := groups[key]
+		if record == nil {
+			record = &LargeCashRecord{Date: date, AccountID: tx.AccountID, Direction: direction, Currency: tx.Currency, TxIDs: []string{}}
+			if account, exists := s.accounts.Get(tx.AccountID); exists {
+				record.UserName = account.UserName
+			}
+			groups[key] = record
+			keys = append(keys, key)
+		}
+		record.Count++
+		record.Amount += tx.Amount
+		record.TxIDs = append(record.TxIDs, tx.TxID)
+		if s.fx.ToBase(tx.Amount, tx.Currency) >= s.cfg.LargeCashThreshold {
+			single[key] = true
+		}
+	}
+
+	result := []LargeCashRecord{}
+	for _, key := range keys {
+		record := groups[key]
+		record.Amount = model.RoundAmount(record.Amount)
+		record.AmountCNY = model.RoundAmount(s.fx.ToBase(record.Amount, record.Currency))
+		if record.AmountCNY < s.cfg.LargeCashThreshold && !single[key] {
+			continue
+		}
+		record.Rule = LARGE_CASH_AGGREGATE
+		if single[key] {
+			record.Rule = LARGE_CASH_SINGLE
+		}
+		result = append(result, *record)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// 每日现金存取汇总（按日期、币种排序）
+func dailyCash(cash []model.Transaction) []DailyCashAggregate {
+	groups := make(map[string]*DailyCashAggregate)
+	accounts := make(map[string]map[string]bool)
+	for _, tx := range cash {
+		date := tx.Time.Format("2006-01-02")
+		key := date + "|" + tx.Currency
+		aggregate := groups[key]
+		if aggregate == nil {
+			aggregate = &DailyCashAggregate{Date: date, Currency: tx.Currency}
+			groups[key] = aggregate
+			accounts[key] = make(map[string]bool)
+		}
+		if regulatoryCashTypes[tx.Type] == CASH_DEPOSIT {
+			aggregate.DepositCount++
+			aggregate.DepositAmount += tx.Amount
+		} else {
+			aggregate.WithdrawalCount++
+			aggregate.WithdrawalAmount += tx.Amount
+		}
+		accounts[key][tx.AccountID] = true
+	}
+
+	result := []DailyCashAggregate{}
+	for key, aggregate := range groups {
+		aggregate.DepositAmount = model.RoundAmount(aggregate.DepositAmount)
+		aggregate.WithdrawalAmount = model.RoundAmount(aggregate.WithdrawalAmount)
+		aggregate.NetAmount = model.RoundAmount(aggregate.DepositAmount - aggregate.WithdrawalAmount)
+		aggregate.Accounts = len(accounts[key])
+		result = append(result, *aggregate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result
+}
+
+// 导出报表为 CSV
+func (s *RegulatoryService) CSV(report RegulatoryReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	switch report.Report {
+	case REPORT_LARGE_CASH:
+		w.Write([]string{"date", "accountId", "userName", "direction", "rule", "count", "amount", "currency", "amountCny", "txIds"})
+		for _, r := range report.LargeCash {
+			w.Write([]string{r.Date, r.AccountID, r.UserName, r.Direction, r.Rule, fmt.Sprint(r.Count),
+				fmt.Sprintf("%.2f", r.Amount), r.Currency, fmt.Sprintf("%.2f", r.AmountCNY), strings.Join(r.TxIDs, ";")})
+		}
+	case REPORT_DAILY_CASH:
+		w.Write([]string{"date", "currency", "depositCount", "depositAmount", "withdrawalCount", "withdrawalAmount", "netAmount", "accounts"})
+		for _, r := range report.DailyCash {
+			w.Write([]string{r.Date, r.Currency, fmt.Sprint(r.DepositCount), fmt.Sprintf("%.2f", r.DepositAmount),
+				fmt.Sprint(r.WithdrawalCount), fmt.Sprintf("%.2f", r.WithdrawalAmount), fmt.Sprintf("%.2f", r.NetAmount), fmt.Sprint(r.Accounts)})
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
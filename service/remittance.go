@@ -0,0 +1,87 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+const (
+	memoMaxLength       = 140 // 附言最大长度（字符，同 ISO 20022 Ustrd）
+	endToEndIDMaxLength = 35  // 端到端参考号最大长度（同 ISO 20022 EndToEndId）
+)
+
+// 用途代码（ISO 20022 ExternalPurpose1Code 常用子集）
+var purposeCodes = map[string]string{
+	"SALA": "工资",
+	"BONU": "奖金",
+	"PENS": "养老金",
+	"SUPP": "供应商付款",
+	"GDDS": "购买商品",
+	"SCVE": "购买服务",
+	"RENT": "租金",
+	"LOAN": "贷款",
+	"TAXS": "税款",
+	"INSU": "保险费",
+	"EDUC": "教育费用",
+	"MDCS": "医疗费用",
+	"CHAR": "慈善捐款",
+	"INTC": "集团内部付款",
+	"OTHR": "其他",
+}
+
+// 端到端参考号字符集（SWIFT X 字符集，不含 // 以免与 MT940 分隔符冲突）
+var endToEndIDPattern = regexp.MustCompile(`^[A-Za-z0-9/\-?:().,'+ ]+$`)
+
+// 用途代码及说明
+type PurposeCode struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// 全部用途代码（按代码排序）
+func PurposeCodes() []PurposeCode {
+	codes := make([]PurposeCode, 0, len(purposeCodes))
+	for code, description := range purposeCodes {
+		codes = append(codes, PurposeCode{Code: code, Description: description})
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// 规范化并校验付款附加信息（去除首尾空白、用途代码转大写）
+func validateRemittance(r *model.Remittance) error {
+	r.Memo = strings.TrimSpace(r.Memo)
+	r.EndToEndID = strings.TrimSpace(r.EndToEndID)
+	r.PurposeCode = strings.ToUpper(strings.TrimSpace(r.PurposeCode))
+
+	if utf8.RuneCountInString(r.Memo) > memoMaxLength {
+		return model.NewError(model.CODE_PARAM_ERROR, "附言不能超过140个字符")
+	}
+	for _, c := range r.Memo {
+		if unicode.IsControl(c) {
+			return model.NewError(model.CODE_PARAM_ERROR, "附言不能包含控制字符")
+		}
+	}
+
+	if r.EndToEndID != "" {
+		if len(r.EndToEndID) > endToEndIDMaxLength {
+			return model.NewError(model.CODE_PARAM_ERROR, "端到端参考号不能超过35个字符")
+		}
+		if !endToEndIDPattern.MatchString(r.EndToEndID) || strings.Contains(r.EndToEndID, "//") ||
+			strings.HasPrefix(r.EndToEndID, "/") || strings.HasSuffix(r.EndToEndID, "/") {
+			return model.NewError(model.CODE_PARAM_ERROR, "端到端参考号只能包含字母、数字及 /-?:().,'+ 空格，且不能以 / 开头或结尾、不能包含 //")
+		}
+	}
+
+	if r.PurposeCode != "" {
+		if _, ok := purposeCodes[r.PurposeCode]; !ok {
+			return model.NewError(model.CODE_PARAM_ERROR, "不支持的用途代码: "+r.PurposeCode)
+		}
+	}
+	return nil
+}
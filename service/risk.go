@@ -0,0 +1,402 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 风控规则命中后的处置方式
+const (
+	RISK_ACTION_FLAG         = "flag"        // 仅标记并提醒客户，交易放行
+	RISK_ACTION_STEP_UP      = "stepUp"      // 要求短信验证码验证后放行
+	RISK_ACTION_VERIFY_PAYEE = "verifyPayee" // 要求先核对收款人户名
+)
+
+// 风控规则
+const (
+	RISK_RULE_NEW_LOCATION = "newLocation" // 交易地点不在账户历史地点中
+	RISK_RULE_NEW_DEVICE   = "newDevice"   // 交易设备不在账户信任设备列表中（始终要求验证码）
+	RISK_RULE_NEW_PAYEE    = "newPayee"    // 首次向该收款人转账且未核对收款人户名（开启 payee_check 时拒绝）
+)
+
+// 新地点交易验证使用的短信验证码业务场景
+const OTP_PURPOSE_TRANSFER = "transfer"
+
+// 风控配置
+type RiskConfig struct {
+	NewLocationAction    string  // 新地点交易处置方式 flag/stepUp
+	NewLocationMinAmount float64 // 新地点交易金额不低于该值时才处置（0 表示不限）
+}
+
+// 模拟地理位置（由 IP 推算，同一 /16 网段视为同一城市）
+type GeoLocation struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+	City    string `json:"city"`
+}
+
+// 地点标识
+func (g GeoLocation) Key() string {
+	return g.Country + "/" + g.Region + "/" + g.City
+}
+
+// 账户历史地点
+type KnownLocation struct {
+	GeoLocation
+	Location  string `json:"location"` // 地点标识（country/region/city），标记信任时使用
+	LastIP    string `json:"lastIp,omitempty"`
+	Count     int    `json:"count"`   // 出现次数
+	Trusted   bool   `json:"trusted"` // 客户标记为可信
+	FirstSeen string `json:"firstSeen"`
+	LastSeen  string `json:"lastSeen"`
+}
+
+// 风控评估请求
+type RiskRequest struct {
+	AccountID string
+	ToAccount string // 收款账户（开启收款人核对时检查是否为首次收款人）
+	IP        string
+	Amount    float64
+	DeviceID  string // 设备标识（X-Device-Id 请求头），为空表示客户端未上报，不做设备检查
+	OTPCode   string // 新地点、新设备交易的短信验证码（要求验证时填写）
+}
+
+// 风控评估结果（附在交易结果中返回）
+type RiskAssessment struct {
+	IP          string      `json:"ip"`
+	Location    GeoLocation `json:"location"`
+	NewLocation bool        `json:"newLocation"`
+	DeviceID    string      `json:"deviceId,omitempty"`
+	NewDevice   bool        `json:"newDevice"`
+	PayeeCheck  string      `json:"payeeCheck,omitempty"` // 首次收款人转账引用的收款人核对编号
+	Rules       []string    `json:"rules,omitempty"`      // 命中的规则
+	Action      string      `json:"action,omitempty"`     // 命中规则时的处置方式
+	EventID     string      `json:"eventId,omitempty"`    // 风控事件编号
+}
+
+// 风控事件
+type RiskEvent struct {
+	EventID   string      `json:"eventId"`
+	AccountID string      `json:"accountId"`
+	Rules     []string    `json:"rules"`
+	Action    string      `json:"action"`
+	Outcome   string      `json:"outcome"` // flagged/challenged/verified
+	IP        string      `json:"ip"`
+	Location  GeoLocation `json:"location"`
+	DeviceID  string      `json:"deviceId,omitempty"`
+	Amount    float64     `json:"amount"`
+	Time      string      `json:"time"`
+}
+
+// 标记可信地点请求（ip 与 location 二选一，ip 按模拟地理位置换算为地点）
+type TrustLocationRequest struct {
+	AccountID string `json:"accountId"`
+	IP        string `json:"ip"`
+	Location  string `json:"location"`
+}
+
+// 模拟 IP 地理库的城市表
+var simulatedCities = []GeoLocation{
+	{"CN", "北京", "北京"},
+	{"CN", "上海", "上海"},
+	{"CN", "广东", "深圳"},
+	{"CN", "广东", "广州"},
+	{"CN", "浙江", "杭州"},
+	{"CN", "四川", "成都"},
+	{"HK", "香港", "香港"},
+	{"SG", "新加坡", "新加坡"},
+	{"US", "California", "San Francisco"},
+	{"GB", "England", "London"},
+}
+
+// 风控引擎：记录账户的交易来源 IP 与模拟地理位置、登录设备，交易地点不在账户历史地点（或可信地点）中时
+// 按配置标记提醒或要求短信验证码验证，交易设备未被信任时要求短信验证码验证
+type RiskService struct {
+	cfg       RiskConfig
+	accounts  *repository.AccountRepository
+	sms       *SMSService
+	payees    *PayeeService
+	flags     *FeatureFlagService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu        sync.Mutex
+	locations map[string]map[string]*KnownLocation // 账户ID → 地点标识 → 历史地点
+	devices   map[string]map[string]*Device        // 账户ID → 设备标识 → 设备
+	events    []RiskEvent
+	seq       int64
+	logins    []LoginAttempt // 登录尝试记录（按时间顺序）
+	loginSeq  int64
+}
+
+func NewRiskService(cfg RiskConfig, accounts *repository.AccountRepository, sms *SMSService, payees *PayeeService, flags *FeatureFlagService, clock Clock, notifier Notifier, templates *TemplateRegistry) *RiskService {
+	if cfg.NewLocationAction != RISK_ACTION_FLAG && cfg.NewLocationAction != RISK_ACTION_STEP_UP {
+		log.Printf("新地点交易处置方式 %q 无效，使用 %s", cfg.NewLocationAction, RISK_ACTION_STEP_UP)
+		cfg.NewLocationAction = RISK_ACTION_STEP_UP
+	}
+	return &RiskService{
+		cfg:       cfg,
+		accounts:  accounts,
+		sms:       sms,
+		payees:    payees,
+		flags:     flags,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		locations: make(map[string]map[string]*KnownLocation),
+		devices:   make(map[string]map[string]*Device),
+	}
+}
+
+// 模拟 IP 地理定位：回环与内网地址视为本地网络，公网地址按 /16 网段映射到固定城市
+func LookupGeo(ip string) GeoLocation {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return GeoLocation{"ZZ", "未知", "未知"}
+	}
+	if parsed.IsLoopback() || parsed.IsPrivate() {
+		return GeoLocation{"CN", "本地网络", "本地网络"}
+	}
+	prefix := parsed.To16()[:14]
+	if v4 := parsed.To4(); v4 != nil {
+		prefix = v4[:2]
+	}
+	h := fnv.New32a()
+	h.Write(prefix)
+	return simulatedCities[h.Sum32()%uint32(len(simulatedCities))]
+}
+
+// 交易风控评估：新地点交易按配置标记或要求验证码，新设备交易要求验证码（验证码错误、缺失时返回风控拒绝，
+// 一个验证码同时满足全部命中规则），处置后地点计入账户历史、设备加入信任列表。
+// 开启收款人核对时，首次向收款人转账须先核对收款人户名，否则拒绝
+func (s *RiskService) AssessTransfer(req RiskRequest) (RiskAssessment, error) {
+	location := LookupGeo(req.IP)
+	assessment := RiskAssessment{IP: req.IP, Location: location, DeviceID: req.DeviceID}
+	if !s.flags.Enabled(FLAG_RISK_ENGINE) {
+		return assessment, nil // 风控已关闭：不评估、不记录地点与设备
+	}
+
+	if req.ToAccount != "" && s.flags.Enabled(FLAG_PAYEE_CHECK) && s.payees.FirstTime(req.AccountID, req.ToAccount) {
+		check, verified := s.payees.Verified(req.AccountID, req.ToAccount)
+		if !verified {
+			event := s.record(req, location, []string{RISK_RULE_NEW_PAYEE}, RISK_ACTION_VERIFY_PAYEE, "challenged")
+			return assessment, model.NewError(model.CODE_RISK_CONTROL_REJECT,
+				"首次向该收款人转账，请先核对收款人户名（POST /api/transfer/verify-payee）后重新提交，风控事件："+event.EventID)
+		}
+		assessment.PayeeCheck = check.CheckID
+	}
+
+	s.mu.Lock()
+	history := s.locations[req.AccountID]
+	_, known := history[location.Key()]
+	// 账户首次交易的地点作为基准地点，不视为新地点
+	assessment.NewLocation = len(history) > 0 && !known
+	// 账户首个设备同样作为基准设备自动信任
+	assessment.NewDevice = req.DeviceID != "" && len(s.devices[req.AccountID]) > 0 && !s.deviceTrusted(req.AccountID, req.DeviceID)
+	s.mu.Unlock()
+
+	// 小额交易不处置新地点，但也不计入历史地点，避免借小额交易绕过验证
+	if assessment.NewLocation && req.Amount >= s.cfg.NewLocationMinAmount {
+		assessment.Rules = append(assessment.Rules, RISK_RULE_NEW_LOCATION)
+	}
+	if assessment.NewDevice {
+		assessment.Rules = append(assessment.Rules, RISK_RULE_NEW_DEVICE)
+	}
+	if len(assessment.Rules) == 0 {
+		if !assessment.NewLocation {
+			s.observe(req.AccountID, req.IP, location)
+		}
+		if req.DeviceID != "" {
+			s.ObserveDevice(req.AccountID, req.DeviceID, "", req.IP)
+		}
+		return assessment, nil
+	}
+
+	assessment.Action = s.cfg.NewLocationAction
+	if assessment.NewDevice {
+		assessment.Action = RISK_ACTION_STEP_UP
+	}
+	outcome := "flagged"
+	if assessment.Action == RISK_ACTION_STEP_UP {
+		if req.OTPCode == "" {
+			event := s.record(req, location, assessment.Rules, assessment.Action, "challenged")
+			return assessment, model.NewError(model.CODE_RISK_CONTROL_REJECT,
+				fmt.Sprintf("检测到%s，请获取短信验证码（业务场景 %s）后携带 otpCode 重新提交，风控事件：%s", riskReason(assessment), OTP_PURPOSE_TRANSFER, event.EventID))
+		}
+		if err := s.sms.VerifyOTP(OTPVerifyRequest{AccountID: req.AccountID, Purpose: OTP_PURPOSE_TRANSFER, Code: req.OTPCode}); err != nil {
+			return assessment, err
+		}
+		outcome = "verified"
+	}
+	event := s.record(req, location, assessment.Rules, assessment.Action, outcome)
+	assessment.EventID = event.EventID
+	if !assessment.NewLocation || req.Amount >= s.cfg.NewLocationMinAmount {
+		s.observe(req.AccountID, req.IP, location)
+	}
+	if assessment.NewDevice {
+		s.trustDevice(req.AccountID, req.DeviceID, req.IP)
+	}
+
+	if account, exists := s.accounts.Get(req.AccountID); exists {
+		for _, rule := range assessment.Rules {
+			event := EVENT_NEW_LOCATION
+			if rule == RISK_RULE_NEW_DEVICE {
+				event = EVENT_NEW_DEVICE
+			}
+			s.notifier.Send(s.templates.Alert("securityAlert", account, event, map[string]interface{}{
+				"City":     location.City,
+				"IP":       req.IP,
+				"DeviceID": req.DeviceID,
+				"Amount":   req.Amount,
+			}))
+		}
+	}
+	return assessment, nil
+}
+
+// 风控拒绝原因说明
+func riskReason(assessment RiskAssessment) string {
+	var reasons []string
+	for _, rule := range assessment.Rules {
+		switch rule {
+		case RISK_RULE_NEW_LOCATION:
+			reasons = append(reasons, "新的交易地点（"+assessment.Location.City+"）")
+		case RISK_RULE_NEW_DEVICE:
+			reasons = append(reasons, "未信任的设备（"+assessment.DeviceID+"）")
+		}
+	}
+	return strings.Join(reasons, "、")
+}
+
+// 地点计入账户历史
+func (s *RiskService) observe(accountID, ip string, location GeoLocation) {
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := s.known(accountID, location, now)
+	known.LastIP = ip
+	known.Count++
+	known.LastSeen = now
+}
+
+// 查找或登记账户历史地点（调用方需持有 s.mu）
+func (s *RiskService) known(accountID string, location GeoLocation, now string) *KnownLocation {
+	if s.locations[accountID] == nil {
+		s.locations[accountID] = make(map[string]*KnownLocation)
+	}
+	known := s.locations[accountID][location.Key()]
+	if known == nil {
+		known = &KnownLocation{GeoLocation: location, Location: location.Key(), FirstSeen: now, LastSeen: now}
+		s.locations[accountID][location.Key()] = known
+	}
+	return known
+}
+
+// 记录风控事件
+func (s *RiskService) record(req RiskRequest, location GeoLocation, rules []string, action, outcome string) RiskEvent {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	s.seq++
+	event := RiskEvent{
+		EventID:   fmt.Sprintf("RK%s%06d", now.Format("20060102"), s.seq),
+		AccountID: req.AccountID,
+		Rules:     rules,
+		Action:    action,
+		Outcome:   outcome,
+		IP:        req.IP,
+		Location:  location,
+		DeviceID:  req.DeviceID,
+		Amount:    req.Amount,
+		Time:      now.Format("2006-01-02 15:04:05"),
+	}
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+
+	// 终端提示：风控事件
+	log.Println("\n[🛡️ 风控事件]")
+	log.Printf("事件编号: %s", event.EventID)
+	log.Printf("账户ID: %s", event.AccountID)
+	log.Printf("命中规则: %s（%s → %s）", strings.Join(event.Rules, ","), event.Action, event.Outcome)
+	log.Printf("来源IP: %s（%s）", event.IP, location.Key())
+	if event.DeviceID != "" {
+		log.Printf("设备标识: %s", event.DeviceID)
+	}
+	log.Printf("交易金额: %.2f", event.Amount)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return event
+}
+
+// 标记可信地点（可提前登记出行目的地），之后来自该地点的交易不再处置
+func (s *RiskService) Trust(req TrustLocationRequest) (KnownLocation, error) {
+	if _, exists := s.accounts.Get(req.AccountID); !exists {
+		return KnownLocation{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	var location GeoLocation
+	switch {
+	case req.IP != "":
+		if net.ParseIP(req.IP) == nil {
+			return KnownLocation{}, model.NewError(model.CODE_PARAM_ERROR, "IP 地址格式错误")
+		}
+		location = LookupGeo(req.IP)
+	case req.Location != "":
+		parts := strings.Split(req.Location, "/")
+		if len(parts) != 3 {
+			return KnownLocation{}, model.NewError(model.CODE_PARAM_ERROR, "地点格式应为 国家/地区/城市")
+		}
+		location = GeoLocation{parts[0], parts[1], parts[2]}
+	default:
+		return KnownLocation{}, model.NewError(model.CODE_PARAM_ERROR, "ip 与 location 至少填写一项")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := s.known(req.AccountID, location, s.clock.Now().Format("2006-01-02 15:04:05"))
+	known.Trusted = true
+	return *known, nil
+}
+
+// 账户历史地点（按最近出现时间倒序）
+func (s *RiskService) Locations(accountID string) []KnownLocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]KnownLocation, 0, len(s.locations[accountID]))
+	for _, known := range s.locations[accountID] {
+		result = append(result, *known)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LastSeen != result[j].LastSeen {
+			return result[i].LastSeen > result[j].LastSeen
+		}
+		return result[i].Location < result[j].Location
+	})
+	return result
+}
+
+// 风控事件（按时间倒序），accountID 为空时返回全部
+func (s *RiskService) Events(accountID string) []RiskEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []RiskEvent{}
+	for i := len(s.events) - 1; i >= 0; i-- {
+		if accountID == "" || s.events[i].AccountID == accountID {
+			result = append(result, s.events[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,447 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// RTGS 结算状态
+const (
+	RTGS_QUEUED  = "queued"  // 付款行头寸不足，排队等待
+	RTGS_SETTLED = "settled" // 已逐笔全额结算
+)
+
+// 央行实时全额结算系统（RTGS）配置
+type RTGSConfig struct {
+	IntradayLimit     float64 // 本行日间透支限额（存放央行款项可透支至 -IntradayLimit）
+	BankBalance       float64 // 模拟他行清算账户期初余额
+	BankIntradayLimit float64 // 模拟他行日间透支限额
+}
+
+// 参与行在央行开立的清算账户
+type SettlementAccount struct {
+	BIC           string  `json:"bic"`
+	Name          string  `json:"name"`
+	Own           bool    `json:"own"`     // 本行（余额取自存放中央银行款项科目）
+	Balance       float64 `json:"balance"` // 清算账户余额，可为负（日间透支）
+	IntradayLimit float64 `json:"intradayLimit"`
+	Available     float64 `json:"available"`    // 可用头寸 = 余额 + 日间透支限额
+	QueuedCount   int     `json:"queuedCount"`  // 作为付款行排队中的指令笔数
+	QueuedAmount  float64 `json:"queuedAmount"` // 作为付款行排队中的指令金额
+}
+
+// 调整日间透支限额请求结构体
+type RTGSLimitRequest struct {
+	IntradayLimit float64 `json:"intradayLimit" validate:"min=0"`
+}
+
+// 模拟他行之间的 RTGS 支付请求
+type RTGSTransferRequest struct {
+	FromBank string  `json:"fromBank"`
+	ToBank   string  `json:"toBank"`
+	Amount   float64 `json:"amount" validate:"amount"`
+}
+
+// RTGS 结算指令，本行作为付款行结算时借记 Contra 科目、贷记存放中央银行款项
+type RTGSInstruction struct {
+	Reference string // 为空时以指令编号作为流水号
+	Debtor    string // 付款行 BIC
+	Creditor  string // 收款行 BIC
+	Amount    float64
+	Contra    string // 本行付款时的借方科目用途（如跨行清算往来、待处理挂账）
+}
+
+// RTGS 结算分录：付款行清算账户借记、收款行清算账户贷记
+type RTGSEntry struct {
+	EntryID      string  `json:"entryId"`
+	Reference    string  `json:"reference"` // 业务流水号
+	Debtor       string  `json:"debtor"`
+	DebtorName   string  `json:"debtorName"`
+	Creditor     string  `json:"creditor"`
+	CreditorName string  `json:"creditorName"`
+	Amount       float64 `json:"amount"`
+	Status       string  `json:"status"` // queued/settled
+	SubmittedAt  string  `json:"submittedAt"`
+	SettledAt    string  `json:"settledAt,omitempty"`
+
+	contra string
+}
+
+// 央行 RTGS 模拟：各参与行（本行与模拟他行）在央行开立清算账户，跨行支付逐笔全额实时结算。
+// 付款行可用头寸（余额 + 日间透支限额）不足时指令排队，同一付款行的指令先进先出，
+// 由清算系统每次处理时重试。本行清算账户余额即存放中央银行款项科目余额
+type RTGSService struct {
+	cfg    RTGSConfig
+	ledger *LedgerService
+	clock  Clock
+
+	mu       sync.Mutex
+	balances map[string]float64 // 他行清算账户余额
+	limits   map[string]float64 // 日间透支限额
+	queue    []*RTGSEntry
+	entries  []RTGSEntry // 已结算分录
+	seq      int
+}
+
+func NewRTGSService(cfg RTGSConfig, ledger *LedgerService, clock Clock) *RTGSService {
+	s := &RTGSService{
+		cfg:      cfg,
+		ledger:   ledger,
+		clock:    clock,
+		balances: make(map[string]float64),
+		limits:   map[string]float64{model.BANK_BIC: cfg.IntradayLimit},
+	}
+	for bic := range externalBanks {
+		s.balances[bic] = cfg.BankBalance
+		s.limits[bic] = cfg.BankIntradayLimit
+	}
+	return s
+}
+
+// 提交一笔结算指令：付款行头寸充足且无排队指令时立即结算，否则排队
+func (s *RTGSService) Submit(ins RTGSInstruction) RTGSEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.newEntry(ins)
+	if s.queuedFor(ins.Debtor) || !s.covered(ins.Debtor, ins.Amount) {
+		s.queue = append(s.queue, entry)
+		logRTGSQueued(*entry, s.available(ins.Debtor))
+		return *entry
+	}
+	s.settle(entry)
+	return *entry
+}
+
+// 立即结算一笔指令，不排队：头寸不足时返回错误；force 为 true 时不检查头寸（如退汇）
+func (s *RTGSService) Settle(ins RTGSInstruction, force bool) (RTGSEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !force && !s.covered(ins.Debtor, ins.Amount) {
+		return RTGSEntry{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH,
+			fmt.Sprintf("%s 央行清算账户头寸不足", participantName(ins.Debtor)))
+	}
+	entry := s.newEntry(ins)
+	s.settle(entry)
+	return *entry, nil
+}
+
+// 按先进先出重试排队指令（某付款行的队首指令仍无法结算时，其后续指令继续等待），返回本次结算的指令
+func (s *RTGSService) ProcessQueue() []RTGSEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settled := make([]RTGSEntry, 0)
+	blocked := make(map[string]bool)
+	remaining := s.queue[:0]
+	for _, entry := range s.queue {
+		if blocked[entry.Debtor] || !s.covered(entry.Debtor, entry.Amount) {
+			blocked[entry.Debtor] = true
+			remaining = append(remaining, entry)
+			continue
+		}
+		s.settle(entry)
+		settled = append(settled, *entry)
+	}
+	s.queue = remaining
+	return settled
+}
+
+// 清算所轧差结算：positions 为各参与行的多边净额（正数为净应收、负数为净应付），
+// 全部净应付行头寸充足时，净应付行划入清算所、清算所划付净应收行，否则整体不结算。
+// 本行净应付时借记 contra 科目
+func (s *RTGSService) SettleNet(reference string, positions map[string]float64, contra string) ([]RTGSEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bics := make([]string, 0, len(positions))
+	for bic := range positions {
+		bics = append(bics, bic)
+	}
+	sort.Strings(bics)
+	for _, bic := range bics {
+		if net := positions[bic]; net < 0 && !s.covered(bic, -net) {
+			return nil, model.NewError(model.CODE_BALANCE_NOT_ENOUGH,
+				fmt.Sprintf("%s 央行清算账户头寸不足，无法完成轧差结算", participantName(bic)))
+		}
+	}
+
+	entries := make([]RTGSEntry, 0, len(bics))
+	post := func(debtor, creditor string, amount float64) {
+		entry := s.newEntry(RTGSInstruction{Reference: reference, Debtor: debtor, Creditor: creditor, Amount: amount, Contra: contra})
+		s.settle(entry)
+		entries = append(entries, *entry)
+	}
+	// 先收后付：净应付行划入清算所，再由清算所划付净应收行
+	for _, bic := range bics {
+		if net := positions[bic]; net < 0 {
+			post(bic, CLEARING_HOUSE_BIC, -net)
+		}
+	}
+	for _, bic := range bics {
+		if net := positions[bic]; net > 0 {
+			post(CLEARING_HOUSE_BIC, bic, net)
+		}
+	}
+	return entries, nil
+}
+
+// 模拟他行之间的跨行支付（经 RTGS 结算，可能排队）
+func (s *RTGSService) Transfer(req RTGSTransferRequest) (RTGSEntry, error) {
+	if err := model.Validate(req); err != nil {
+		return RTGSEntry{}, err
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+	_, fromOK := externalBanks[req.FromBank]
+	_, toOK := externalBanks[req.ToBank]
+	if !fromOK || !toOK {
+		return RTGSEntry{}, model.NewError(model.CODE_PARAM_ERROR, "付款行、收款行须为模拟他行")
+	}
+	if req.FromBank == req.ToBank {
+		return RTGSEntry{}, model.NewError(model.CODE_PARAM_ERROR, "付款行与收款行不能相同")
+	}
+	return s.Submit(RTGSInstruction{Debtor: req.FromBank, Creditor: req.ToBank, Amount: req.Amount}), nil
+}
+
+// 各参与行清算账户（本行在前）
+func (s *RTGSService) Accounts() []SettlementAccount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]SettlementAccount, 0, len(s.limits))
+	for bic, limit := range s.limits {
+		account := SettlementAccount{
+			BIC:           bic,
+			Name:          participantName(bic),
+			Own:           bic == model.BANK_BIC,
+			Balance:       s.balance(bic),
+			IntradayLimit: limit,
+		}
+		account.Available = model.RoundAmount(account.Balance + limit)
+		for _, entry := range s.queue {
+			if entry.Debtor == bic {
+				account.QueuedCount++
+				account.QueuedAmount += entry.Amount
+			}
+		}
+		account.QueuedAmount = model.RoundAmount(account.QueuedAmount)
+		result = append(result, account)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Own != result[j].Own {
+			return result[i].Own
+		}
+		return result[i].BIC < result[j].BIC
+	})
+	return result
+}
+
+// 调整参与行日间透支限额（立即生效，排队指令在下一次处理时重试）
+func (s *RTGSService) SetLimit(bic string, req RTGSLimitRequest) (SettlementAccount, error) {
+	if err := model.Validate(req); err != nil {
+		return SettlementAccount{}, err
+	}
+	s.mu.Lock()
+	if _, ok := s.limits[bic]; !ok {
+		s.mu.Unlock()
+		return SettlementAccount{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "参与行不存在")
+	}
+	s.limits[bic] = req.IntradayLimit
+	s.mu.Unlock()
+
+	for _, account := range s.Accounts() {
+		if account.BIC == bic {
+			return account, nil
+		}
+	}
+	return SettlementAccount{}, nil
+}
+
+// 排队中的结算指令（按提交顺序）
+func (s *RTGSService) Queue() []RTGSEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]RTGSEntry, 0, len(s.queue))
+	for _, entry := range s.queue {
+		result = append(result, *entry)
+	}
+	return result
+}
+
+// 已结算分录（最近的在前），date 为 YYYY-MM-DD，为空表示全部
+func (s *RTGSService) Entries(date string) []RTGSEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]RTGSEntry, 0)
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if date == "" || strings.HasPrefix(s.entries[i].SettledAt, date) {
+			result = append(result, s.entries[i])
+		}
+	}
+	return result
+}
+
+// 生成结算指令（调用方需持有 mu）
+func (s *RTGSService) newEntry(ins RTGSInstruction) *RTGSEntry {
+	now := s.clock.Now()
+	s.seq++
+	entry := &RTGSEntry{
+		EntryID:      fmt.Sprintf("RT%s%06d", now.Format("20060102"), s.seq),
+		Reference:    ins.Reference,
+		Debtor:       ins.Debtor,
+		DebtorName:   participantName(ins.Debtor),
+		Creditor:     ins.Creditor,
+		CreditorName: participantName(ins.Creditor),
+		Amount:       model.RoundAmount(ins.Amount),
+		Status:       RTGS_QUEUED,
+		SubmittedAt:  now.Format("2006-01-02 15:04:05"),
+		contra:       ins.Contra,
+	}
+	if entry.Reference == "" {
+		entry.Reference = entry.EntryID
+	}
+	return entry
+}
+
+// 结算一笔指令：借记付款行、贷记收款行。本行作为付款行时记账（借 Contra、贷存放中央银行款项），
+// 作为收款行时由受理方按入账结果记账（调用方需持有 mu）
+func (s *RTGSService) settle(entry *RTGSEntry) {
+	if entry.Debtor == model.BANK_BIC {
+		s.ledger.PostInternal(entry.contra, GL_CENTRAL_BANK, entry.Amount, model.BASE_CURRENCY, entry.Reference)
+	} else {
+		s.balances[entry.Debtor] = model.RoundAmount(s.balances[entry.Debtor] - entry.Amount)
+	}
+	if entry.Creditor != model.BANK_BIC {
+		s.balances[entry.Creditor] = model.RoundAmount(s.balances[entry.Creditor] + entry.Amount)
+	}
+	entry.Status = RTGS_SETTLED
+	entry.SettledAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	s.entries = append(s.entries, *entry)
+	log.Printf("[🏛️ RTGS 结算] %s → %s | %.2f 元 | 流水号: %s", entry.DebtorName, entry.CreditorName, entry.Amount, entry.Reference)
+}
+
+// 付款行可用头寸是否足以结算（调用方需持有 mu）
+func (s *RTGSService) covered(bic string, amount float64) bool {
+	return model.RoundAmount(s.available(bic)-amount) >= 0
+}
+
+// 可用头寸 = 余额 + 日间透支限额（调用方需持有 mu）
+func (s *RTGSService) available(bic string) float64 {
+	return model.RoundAmount(s.balance(bic) + s.limits[bic])
+}
+
+// 清算账户余额，本行取自存放中央银行款项科目（调用方需持有 mu）
+func (s *RTGSService) balance(bic string) float64 {
+	if bic != model.BANK_BIC {
+		return s.balances[bic]
+	}
+	code := s.ledger.Chart().Code(GL_CENTRAL_BANK)
+	for _, balance := range s.ledger.GLBalances() {
+		if balance.Code == code && balance.Currency == model.BASE_CURRENCY {
+			return balance.Balance
+		}
+	}
+	return 0
+}
+
+// 付款行是否已有排队指令（调用方需持有 mu）
+func (s *RTGSService) queuedFor(bic string) bool {
+	for _, entry := range s.queue {
+		if entry.Debtor == bic {
+			return true
+		}
+	}
+	return false
+}
+
+// 参与行名称
+func participantName(bic string) string {
+	if bic == model.BANK_BIC {
+		return "本行"
+	}
+	if bic == CLEARING_HOUSE_BIC {
+		return "模拟清算所"
+	}
+	if name, ok := externalBanks[bic]; ok {
+		return name
+	}
+	return bic
+}
+
+// 终端提示：RTGS 指令排队
+func logRTGSQueued(entry RTGSEntry, available float64) {
+	log.Println("\n[🏛️ RTGS 指令排队]")
+	log.Printf("指令编号: %s（流水号 %s）", entry.EntryID, entry.Reference)
+	log.Printf("%s → %s，金额 %.2f 元", entry.DebtorName, entry.CreditorName, entry.Amount)
+	log.Printf("付款行可用头寸: %.2f 元", available)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// RTGS 快照（随状态快照导出）
+type RTGSSnapshot struct {
+	Balances map[string]float64 `json:"balances"` // 他行清算账户余额
+	Limits   map[string]float64 `json:"limits"`
+	Queue    []RTGSQueuedEntry  `json:"queue"`
+	Entries  []RTGSEntry        `json:"entries"`
+	Seq      int                `json:"seq"`
+}
+
+// 排队中的结算指令及其记账科目
+type RTGSQueuedEntry struct {
+	RTGSEntry
+	Contra string `json:"contra,omitempty"`
+}
+
+// 导出清算账户余额、排队指令与已结算分录
+func (s *RTGSService) Snapshot() RTGSSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := RTGSSnapshot{
+		Balances: make(map[string]float64, len(s.balances)),
+		Limits:   make(map[string]float64, len(s.limits)),
+		Queue:    make([]RTGSQueuedEntry, 0, len(s.queue)),
+		Entries:  append([]RTGSEntry{}, s.entries...),
+		Seq:      s.seq,
+	}
+	for bic, balance := range s.balances {
+		snapshot.Balances[bic] = balance
+	}
+	for bic, limit := range s.limits {
+		snapshot.Limits[bic] = limit
+	}
+	for _, entry := range s.queue {
+		snapshot.Queue = append(snapshot.Queue, RTGSQueuedEntry{RTGSEntry: *entry, Contra: entry.contra})
+	}
+	return snapshot
+}
+
+// 以快照替换清算账户余额、排队指令与已结算分录
+func (s *RTGSService) Restore(snapshot RTGSSnapshot) {
+	queue := make([]*RTGSEntry, 0, len(snapshot.Queue))
+	for _, queued := range snapshot.Queue {
+		entry := queued.RTGSEntry
+		entry.contra = queued.Contra
+		queue = append(queue, &entry)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balances, s.limits, s.queue, s.seq = snapshot.Balances, snapshot.Limits, queue, snapshot.Seq
+	s.entries = append([]RTGSEntry{}, snapshot.Entries...)
+	if s.balances == nil {
+		s.balances = make(map[string]float64)
+	}
+	if s.limits == nil {
+		s.limits = make(map[string]float64)
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 事务编排（Saga）状态
+const (
+	SAGA_RUNNING     = "running"     // 执行中
+	SAGA_COMPLETED   = "completed"   // 全部步骤完成
+	SAGA_COMPENSATED = "compensated" // 步骤失败，已完成步骤均已补偿
+	SAGA_STUCK       = "stuck"       // 补偿失败，需人工重试（/api/admin/sagas/{id}/retry）
+)
+
+// 步骤状态
+const (
+	SAGA_STEP_PENDING             = "pending"             // 未执行
+	SAGA_STEP_DONE                = "done"                // 已完成
+	SAGA_STEP_FAILED              = "failed"              // 执行失败
+	SAGA_STEP_COMPENSATED         = "compensated"         // 已补偿
+	SAGA_STEP_COMPENSATION_FAILED = "compensation_failed" // 补偿失败
+)
+
+// 事务编排类型
+const (
+	SAGA_TYPE_TRANSFER    = "transfer"          // 行内转账（扣款 → 入账）
+	SAGA_TYPE_FX_TRANSFER = "fx_transfer"       // 跨币种转账（扣款 → 兑换入账）
+	SAGA_TYPE_LOAN        = "loan_disbursement" // 贷款放款（放款 → 收取手续费）
+)
+
+const (
+	SAGA_LIMIT                = 500 // 保留的编排记录条数（待人工处理的记录不淘汰）
+	SAGA_COMPENSATION_RETRIES = 3   // 补偿失败时的立即重试次数
+)
+
+// 编排步骤：Action 执行，Compensate 撤销已完成的 Action（为空表示无需补偿）
+type SagaStep struct {
+	Name       string
+	Action     func() error
+	Compensate func() error
+}
+
+// 步骤执行记录
+type SagaStepRecord struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	At     string `json:"at,omitempty"`
+}
+
+// 编排执行记录
+type Saga struct {
+	SagaID    string           `json:"sagaId"`
+	Type      string           `json:"type"`
+	Reference string           `json:"reference"` // 业务标识（如转出→收款账户、贷款编号）
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"` // 导致补偿的步骤错误
+	Steps     []SagaStepRecord `json:"steps"`
+	StartedAt string           `json:"startedAt"`
+	EndedAt   string           `json:"endedAt,omitempty"`
+}
+
+// 编排条目：补偿失败时保留步骤与锁，供人工重试
+type sagaEntry struct {
+	saga  *Saga
+	steps []SagaStep
+	lock  sync.Locker
+}
+
+// 事务编排服务：按顺序执行多步骤操作并记录每步完成情况，某步失败时按相反顺序补偿已完成的步骤，
+// 保证资金不丢失、不重复
+type SagaService struct {
+	clock Clock
+
+	mu      sync.Mutex
+	entries []*sagaEntry
+	index   map[string]*sagaEntry
+	seq     int64
+}
+
+func NewSagaService(clock Clock) *SagaService {
+	return &SagaService{clock: clock, index: make(map[string]*sagaEntry)}
+}
+
+// 执行编排。lock 为步骤所需的锁：调用方在 Run 期间已持有该锁，人工重试补偿时由编排服务获取（为空表示步骤自行加锁）。
+// 某步失败时补偿已完成的步骤并返回该步的错误
+func (s *SagaService) Run(sagaType, reference string, lock sync.Locker, steps ...SagaStep) (Saga, error) {
+	now := s.clock.Now()
+	saga := &Saga{Type: sagaType, Reference: reference, Status: SAGA_RUNNING, StartedAt: now.Format("2006-01-02 15:04:05")}
+	for _, step := range steps {
+		saga.Steps = append(saga.Steps, SagaStepRecord{Name: step.Name, Status: SAGA_STEP_PENDING})
+	}
+	entry := &sagaEntry{saga: saga, steps: steps, lock: lock}
+
+	s.mu.Lock()
+	s.seq++
+	saga.SagaID = fmt.Sprintf("SG%s%06d", now.Format("20060102"), s.seq)
+	s.mu.Unlock()
+
+	var stepErr error
+	for i, step := range steps {
+		if err := step.Action(); err != nil {
+			stepErr = err
+			s.setStep(saga, i, SAGA_STEP_FAILED, err)
+			break
+		}
+		s.setStep(saga, i, SAGA_STEP_DONE, nil)
+	}
+	if stepErr != nil {
+		s.mu.Lock()
+		saga.Error = stepErr.Error()
+		s.mu.Unlock()
+		s.compensate(entry)
+	} else {
+		s.finish(saga, SAGA_COMPLETED)
+	}
+	s.store(entry)
+
+	snapshot := s.copy(saga)
+	if stepErr != nil {
+		s.logSaga(snapshot)
+	}
+	return snapshot, stepErr
+}
+
+// 人工重试补偿失败的编排（仅 stuck 状态）
+func (s *SagaService) Retry(sagaID string) (Saga, error) {
+	s.mu.Lock()
+	entry, ok := s.index[sagaID]
+	if !ok {
+		s.mu.Unlock()
+		return Saga{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "编排记录不存在")
+	}
+	if entry.saga.Status != SAGA_STUCK {
+		saga := copySaga(entry.saga)
+		s.mu.Unlock()
+		return saga, model.NewError(model.CODE_PARAM_ERROR, "仅补偿失败的编排可重试，当前状态: "+saga.Status)
+	}
+	entry.saga.Status = SAGA_RUNNING // 防止并发重试重复补偿
+	s.mu.Unlock()
+
+	if entry.lock != nil {
+		entry.lock.Lock()
+	}
+	s.compensate(entry)
+	if entry.lock != nil {
+		entry.lock.Unlock()
+	}
+
+	s.mu.Lock()
+	if entry.saga.Status != SAGA_STUCK {
+		entry.steps, entry.lock = nil, nil
+	}
+	s.mu.Unlock()
+
+	saga := s.copy(entry.saga)
+	s.logSaga(saga)
+	return saga, nil
+}
+
+// 查询编排记录
+func (s *SagaService) Get(sagaID string) (Saga, error) {
+	s.mu.Lock()
+	entry, ok := s.index[sagaID]
+	s.mu.Unlock()
+	if !ok {
+		return Saga{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "编排记录不存在")
+	}
+	return s.copy(entry.saga), nil
+}
+
+// 编排记录列表（最新在前），status 为空时返回全部
+func (s *SagaService) List(status string) []Saga {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sagas := make([]Saga, 0)
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		saga := s.entries[i].saga
+		if status != "" && saga.Status != status {
+			continue
+		}
+		sagas = append(sagas, copySaga(saga))
+	}
+	return sagas
+}
+
+// 按相反顺序补偿已完成（或上次补偿失败）的步骤，每步失败时立即重试
+func (s *SagaService) compensate(entry *sagaEntry) {
+	stuck := false
+	for i := len(entry.steps) - 1; i >= 0; i-- {
+		s.mu.Lock()
+		status := entry.saga.Steps[i].Status
+		s.mu.Unlock()
+		if status != SAGA_STEP_DONE && status != SAGA_STEP_COMPENSATION_FAILED {
+			continue
+		}
+		if entry.steps[i].Compensate == nil {
+			s.setStep(entry.saga, i, SAGA_STEP_COMPENSATED, nil)
+			continue
+		}
+		var err error
+		for attempt := 0; attempt < SAGA_COMPENSATION_RETRIES; attempt++ {
+			if err = entry.steps[i].Compensate(); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			// 后续（更早的）步骤仍继续补偿，避免单步故障扩大影响
+			stuck = true
+			s.setStep(entry.saga, i, SAGA_STEP_COMPENSATION_FAILED, err)
+			continue
+		}
+		s.setStep(entry.saga, i, SAGA_STEP_COMPENSATED, nil)
+	}
+	if stuck {
+		s.mu.Lock()
+		entry.saga.Status = SAGA_STUCK
+		s.mu.Unlock()
+		return
+	}
+	s.finish(entry.saga, SAGA_COMPENSATED)
+}
+
+// 保存编排记录，超出保留条数时淘汰最早的已结束记录
+func (s *SagaService) store(entry *sagaEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.saga.Status != SAGA_STUCK {
+		entry.steps, entry.lock = nil, nil // 已结束的编排不再需要步骤与锁
+	}
+	s.entries = append(s.entries, entry)
+	s.index[entry.saga.SagaID] = entry
+	for i := 0; len(s.entries) > SAGA_LIMIT && i < len(s.entries); {
+		if s.entries[i].saga.Status == SAGA_STUCK {
+			i++
+			continue
+		}
+		delete(s.index, s.entries[i].saga.SagaID)
+		s.entries = append(s.entries[:i], s.entries[i+1:]...)
+	}
+}
+
+func (s *SagaService) setStep(saga *Saga, i int, status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.Steps[i].Status = status
+	saga.Steps[i].Error = ""
+	if err != nil {
+		saga.Steps[i].Error = err.Error()
+	}
+	saga.Steps[i].At = s.clock.Now().Format("2006-01-02 15:04:05")
+}
+
+func (s *SagaService) finish(saga *Saga, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saga.Status = status
+	saga.EndedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+}
+
+func (s *SagaService) copy(saga *Saga) Saga {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copySaga(saga)
+}
+
+// 终端提示：编排补偿结果
+func (s *SagaService) logSaga(saga Saga) {
+	if saga.Status == SAGA_STUCK {
+		log.Println("\n[🚨 事务编排 - 补偿失败，需人工处理]")
+	} else {
+		log.Println("\n[↩️ 事务编排 - 已补偿]")
+	}
+	log.Printf("编排编号: %s（%s）", saga.SagaID, saga.Type)
+	log.Printf("业务标识: %s", saga.Reference)
+	log.Printf("失败原因: %s", saga.Error)
+	for _, step := range saga.Steps {
+		line := fmt.Sprintf("步骤 %s: %s", step.Name, step.Status)
+		if step.Error != "" {
+			line += "（" + step.Error + "）"
+		}
+		log.Print(line)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+func copySaga(saga *Saga) Saga {
+	copied := *saga
+	copied.Steps = append([]SagaStepRecord(nil), saga.Steps...)
+	return copied
+}
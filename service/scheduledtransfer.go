@@ -0,0 +1,508 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 定时转账状态
+const (
+	SCHEDULED_ACTIVE    = "active"    // 等待执行
+	SCHEDULED_PAUSED    = "paused"    // 客户已暂停
+	SCHEDULED_EXECUTING = "executing" // 正在执行（不可修改）
+	SCHEDULED_COMPLETED = "completed" // 单次转账已执行成功
+	SCHEDULED_FAILED    = "failed"    // 单次转账执行失败
+	SCHEDULED_CANCELLED = "cancelled" // 客户已取消
+)
+
+// 单次定时转账的执行频率（周期性转账即约定转账，频率与周期性收支识别一致）
+const SCHEDULE_ONCE = "once"
+
+// 定时转账的审计操作，detail 含变更前后的金额、日期或执行结果
+const (
+	AUDIT_SCHEDULED_CREATED   = "scheduled_transfer.created"
+	AUDIT_SCHEDULED_PAUSED    = "scheduled_transfer.paused"
+	AUDIT_SCHEDULED_RESUMED   = "scheduled_transfer.resumed"
+	AUDIT_SCHEDULED_AMENDED   = "scheduled_transfer.amended"
+	AUDIT_SCHEDULED_CANCELLED = "scheduled_transfer.cancelled"
+	AUDIT_SCHEDULED_EXECUTED  = "scheduled_transfer.executed"
+	AUDIT_SCHEDULED_FAILED    = "scheduled_transfer.failed"
+)
+
+// 定时转账（单次或周期性约定转账）
+type ScheduledTransfer struct {
+	TransferID     string  `json:"transferId"`
+	AccountID      string  `json:"accountId"` // 转出账户
+	ToAccount      string  `json:"toAccount"`
+	PayeeName      string  `json:"payeeName"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`    // 转出账户币种
+	Frequency      string  `json:"frequency"`   // once/weekly/biweekly/monthly/quarterly/yearly
+	NextDate       string  `json:"nextDate"`    // 下次约定日期（YYYY-MM-DD）
+	ExecuteDate    string  `json:"executeDate"` // 实际执行日（约定日期遇非营业日顺延）
+	Status         string  `json:"status"`
+	Executions     int     `json:"executions"` // 成功执行次数
+	Failures       int     `json:"failures"`   // 失败次数
+	LastExecutedAt string  `json:"lastExecutedAt,omitempty"`
+	LastError      string  `json:"lastError,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+	model.Remittance
+}
+
+// 创建定时转账请求
+type ScheduledTransferRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	ToAccount string  `json:"toAccount" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"amount"`
+	Frequency string  `json:"frequency" validate:"omitempty,oneof=once weekly biweekly monthly quarterly yearly"` // 为空表示单次
+	Date      string  `json:"date" validate:"required"`                                                           // 首次（或唯一一次）约定日期 YYYY-MM-DD
+	model.Remittance
+}
+
+// 修改定时转账请求（字段为零值表示不变）
+type ScheduledTransferAmendRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"omitempty,amount"`
+	Date      string  `json:"date"` // 下次约定日期 YYYY-MM-DD
+}
+
+// 定时转账服务：客户预约单次或周期性行内转账，日终按营业日历执行；暂停、恢复、修改与取消均记入审计日志，
+// 正在执行的转账不可修改
+type ScheduledTransferService struct {
+	accounts  *repository.AccountRepository
+	transfers *AccountService
+	calendar  *CalendarService
+	audit     *AuditService
+	clock     Clock
+
+	mu        sync.Mutex // 叶子锁（执行转账前释放）
+	scheduled map[string]*ScheduledTransfer
+	seq       int64
+}
+
+func NewScheduledTransferService(accounts *repository.AccountRepository, transfers *AccountService, calendar *CalendarService, audit *AuditService, clock Clock) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		accounts:  accounts,
+		transfers: transfers,
+		calendar:  calendar,
+		audit:     audit,
+		clock:     clock,
+		scheduled: make(map[string]*ScheduledTransfer),
+	}
+}
+
+// 创建定时转账
+func (s *ScheduledTransferService) Create(req ScheduledTransferRequest) (ScheduledTransfer, error) {
+	if err := model.Validate(req); err != nil {
+		return ScheduledTransfer{}, err
+	}
+	if req.ToAccount == req.AccountID {
+		return ScheduledTransfer{}, model.NewError(model.CODE_PARAM_ERROR, "收款账户不能与转出账户相同")
+	}
+	if req.Frequency == "" {
+		req.Frequency = SCHEDULE_ONCE
+	}
+	date, err := s.parseDate(req.Date)
+	if err != nil {
+		return ScheduledTransfer{}, err
+	}
+	req.EndToEndID = "" // 端到端参考号每笔不同，不随预约保存
+	if err := validateRemittance(&req.Remittance); err != nil {
+		return ScheduledTransfer{}, err
+	}
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return ScheduledTransfer{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "转出账户不存在")
+	}
+	if account.Status != "normal" {
+		return ScheduledTransfer{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "转出账户已冻结，无法预约转账")
+	}
+	payee, exists := s.accounts.Get(req.ToAccount)
+	if !exists {
+		return ScheduledTransfer{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "收款账户不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	s.seq++
+	transfer := &ScheduledTransfer{
+		TransferID: fmt.Sprintf("ST%s%06d", now.Format("20060102"), s.seq),
+		AccountID:  account.AccountID,
+		ToAccount:  payee.AccountID,
+		PayeeName:  payee.UserName,
+		Amount:     req.Amount,
+		Currency:   account.Currency,
+		Frequency:  req.Frequency,
+		NextDate:   date.Format("2006-01-02"),
+		Status:     SCHEDULED_ACTIVE,
+		CreatedAt:  now.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  now.Format("2006-01-02 15:04:05"),
+		Remittance: req.Remittance,
+	}
+	s.scheduled[transfer.TransferID] = transfer
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, AUDIT_SCHEDULED_CREATED, transfer.AccountID, map[string]string{
+		"transferId": transfer.TransferID,
+		"toAccount":  transfer.ToAccount,
+		"amount":     fmt.Sprintf("%.2f", transfer.Amount),
+		"frequency":  transfer.Frequency,
+		"date":       transfer.NextDate,
+	})
+
+	result := s.view(transfer)
+
+	// 终端提示：定时转账预约
+	log.Println("\n[⏰ 定时转账预约]")
+	log.Printf("预约编号: %s", result.TransferID)
+	log.Printf("转出账户: %s → 收款账户: %s（%s）", result.AccountID, result.ToAccount, result.PayeeName)
+	log.Printf("金额: %.2f %s", result.Amount, result.Currency)
+	log.Printf("频率: %s，约定日期: %s（执行日 %s）", result.Frequency, result.NextDate, result.ExecuteDate)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 查询定时转账
+func (s *ScheduledTransferService) Get(transferID string) (ScheduledTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, ok := s.scheduled[transferID]
+	if !ok {
+		return ScheduledTransfer{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "定时转账不存在")
+	}
+	return s.view(transfer), nil
+}
+
+// 账户预约的定时转账（按预约编号倒序）
+func (s *ScheduledTransferService) List(accountID string) []ScheduledTransfer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []ScheduledTransfer{}
+	for _, transfer := range s.scheduled {
+		if transfer.AccountID == accountID {
+			result = append(result, s.view(transfer))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TransferID > result[j].TransferID })
+	return result
+}
+
+// 涉及账户的待执行定时转账（转出或转入，不含已暂停与已结束的），供收付款日历推算
+func (s *ScheduledTransferService) Pending(accountID string) []ScheduledTransfer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []ScheduledTransfer{}
+	for _, transfer := range s.scheduled {
+		if transfer.Status != SCHEDULED_ACTIVE && transfer.Status != SCHEDULED_EXECUTING {
+			continue
+		}
+		if transfer.AccountID == accountID || transfer.ToAccount == accountID {
+			result = append(result, s.view(transfer))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TransferID < result[j].TransferID })
+	return result
+}
+
+// 暂停定时转账：暂停期间到期的转账不执行
+func (s *ScheduledTransferService) Pause(transferID, accountID string) (ScheduledTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.modifiable(transferID, accountID)
+	if err != nil {
+		return ScheduledTransfer{}, err
+	}
+	if transfer.Status != SCHEDULED_ACTIVE {
+		return ScheduledTransfer{}, model.NewError(model.CODE_PARAM_ERROR, "定时转账已暂停")
+	}
+	transfer.Status = SCHEDULED_PAUSED
+	transfer.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, AUDIT_SCHEDULED_PAUSED, transfer.AccountID, map[string]string{
+		"transferId": transfer.TransferID,
+		"nextDate":   transfer.NextDate,
+	})
+	log.Printf("[⏰ 定时转账] %s 已暂停（账户 %s）", transfer.TransferID, transfer.AccountID)
+	return s.view(transfer), nil
+}
+
+// 恢复定时转账：周期性转账跳过暂停期间错过的期次，单次转账约定日期已过时于当日日终执行
+func (s *ScheduledTransferService) Resume(transferID, accountID string) (ScheduledTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.modifiable(transferID, accountID)
+	if err != nil {
+		return ScheduledTransfer{}, err
+	}
+	if transfer.Status != SCHEDULED_PAUSED {
+		return ScheduledTransfer{}, model.NewError(model.CODE_PARAM_ERROR, "定时转账未暂停")
+	}
+	now := s.clock.Now()
+	today := sim.StartOfDay(now)
+	previous := transfer.NextDate
+	next, _ := time.ParseInLocation("2006-01-02", transfer.NextDate, time.Local)
+	for next.Before(today) {
+		advanced, ok := nextRecurringDate(transfer.Frequency, next)
+		if !ok {
+			break
+		}
+		next = advanced
+	}
+	transfer.NextDate = next.Format("2006-01-02")
+	transfer.Status = SCHEDULED_ACTIVE
+	transfer.UpdatedAt = now.Format("2006-01-02 15:04:05")
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, AUDIT_SCHEDULED_RESUMED, transfer.AccountID, map[string]string{
+		"transferId": transfer.TransferID,
+		"from":       previous,
+		"nextDate":   transfer.NextDate,
+	})
+	log.Printf("[⏰ 定时转账] %s 已恢复，下次约定日期 %s", transfer.TransferID, transfer.NextDate)
+	return s.view(transfer), nil
+}
+
+// 修改定时转账的金额或下次约定日期
+func (s *ScheduledTransferService) Amend(transferID string, req ScheduledTransferAmendRequest) (ScheduledTransfer, error) {
+	if err := model.Validate(req); err != nil {
+		return ScheduledTransfer{}, err
+	}
+	if req.Amount == 0 && req.Date == "" {
+		return ScheduledTransfer{}, model.NewError(model.CODE_PARAM_ERROR, "请指定新的金额或日期")
+	}
+	var date time.Time
+	if req.Date != "" {
+		parsed, err := s.parseDate(req.Date)
+		if err != nil {
+			return ScheduledTransfer{}, err
+		}
+		date = parsed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.modifiable(transferID, req.AccountID)
+	if err != nil {
+		return ScheduledTransfer{}, err
+	}
+	detail := map[string]string{"transferId": transfer.TransferID}
+	if req.Amount != 0 {
+		detail["amount"] = fmt.Sprintf("%.2f → %.2f", transfer.Amount, req.Amount)
+		transfer.Amount = req.Amount
+	}
+	if req.Date != "" {
+		detail["date"] = transfer.NextDate + " → " + date.Format("2006-01-02")
+		transfer.NextDate = date.Format("2006-01-02")
+	}
+	transfer.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, AUDIT_SCHEDULED_AMENDED, transfer.AccountID, detail)
+
+	result := s.view(transfer)
+
+	// 终端提示：定时转账修改
+	log.Println("\n[⏰ 定时转账修改]")
+	log.Printf("预约编号: %s", result.TransferID)
+	log.Printf("金额: %.2f %s", result.Amount, result.Currency)
+	log.Printf("下次约定日期: %s（执行日 %s）", result.NextDate, result.ExecuteDate)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 取消定时转账
+func (s *ScheduledTransferService) Cancel(transferID, accountID string) (ScheduledTransfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.modifiable(transferID, accountID)
+	if err != nil {
+		return ScheduledTransfer{}, err
+	}
+	transfer.Status = SCHEDULED_CANCELLED
+	transfer.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	s.audit.Record(AUDIT_ACTOR_CUSTOMER, AUDIT_SCHEDULED_CANCELLED, transfer.AccountID, map[string]string{
+		"transferId": transfer.TransferID,
+		"nextDate":   transfer.NextDate,
+	})
+	log.Printf("[⏰ 定时转账] %s 已取消（账户 %s）", transfer.TransferID, transfer.AccountID)
+	return s.view(transfer), nil
+}
+
+// 日终执行到期的定时转账：执行日（约定日期按营业日历顺延）不晚于 day 的逐笔转账。
+// 执行期间状态为 executing，转账在释放服务锁后进行，其间的修改请求会被拒绝
+func (s *ScheduledTransferService) Run(day time.Time) {
+	s.mu.Lock()
+	due := make([]*ScheduledTransfer, 0)
+	for _, transfer := range s.scheduled {
+		if transfer.Status == SCHEDULED_ACTIVE && !s.executeDate(transfer).After(day) {
+			transfer.Status = SCHEDULED_EXECUTING
+			due = append(due, transfer)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].TransferID < due[j].TransferID })
+	requests := make([]TransferRequest, len(due))
+	for i, transfer := range due {
+		remittance := transfer.Remittance
+		remittance.EndToEndID = transfer.TransferID + "-" + transfer.NextDate
+		requests[i] = TransferRequest{
+			FromAccount: transfer.AccountID,
+			ToAccount:   transfer.ToAccount,
+			Amount:      transfer.Amount,
+			Remittance:  remittance,
+		}
+	}
+	s.mu.Unlock()
+
+	for i, transfer := range due {
+		_, err := s.transfers.Transfer(requests[i])
+
+		s.mu.Lock()
+		s.finish(transfer, day, err)
+		s.mu.Unlock()
+	}
+}
+
+// 记录执行结果：周期性转账推进至下一期，单次转账结束（调用方需持有 s.mu）
+func (s *ScheduledTransferService) finish(transfer *ScheduledTransfer, day time.Time, err error) {
+	now := s.clock.Now()
+	date := transfer.NextDate
+	detail := map[string]string{
+		"transferId": transfer.TransferID,
+		"date":       date,
+		"amount":     fmt.Sprintf("%.2f", transfer.Amount),
+	}
+	action := AUDIT_SCHEDULED_EXECUTED
+	if err != nil {
+		action = AUDIT_SCHEDULED_FAILED
+		transfer.Failures++
+		transfer.LastError = err.Error()
+		detail["error"] = err.Error()
+	} else {
+		transfer.Executions++
+		transfer.LastError = ""
+		transfer.LastExecutedAt = now.Format("2006-01-02 15:04:05")
+	}
+
+	current, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	if next, ok := nextRecurringDate(transfer.Frequency, current); ok {
+		transfer.NextDate = next.Format("2006-01-02")
+		transfer.Status = SCHEDULED_ACTIVE
+	} else if err != nil {
+		transfer.Status = SCHEDULED_FAILED
+	} else {
+		transfer.Status = SCHEDULED_COMPLETED
+	}
+	transfer.UpdatedAt = now.Format("2006-01-02 15:04:05")
+	s.audit.Record(AUDIT_ACTOR_SYSTEM, action, transfer.AccountID, detail)
+
+	// 终端提示：定时转账执行
+	if err != nil {
+		log.Println("\n[❌ 定时转账执行 - 失败]")
+	} else {
+		log.Println("\n[⏰ 定时转账执行]")
+	}
+	log.Printf("日终日期: %s", day.Format("2006-01-02"))
+	log.Printf("预约编号: %s（约定日期 %s）", transfer.TransferID, date)
+	log.Printf("转出账户: %s → 收款账户: %s（%s）", transfer.AccountID, transfer.ToAccount, transfer.PayeeName)
+	log.Printf("金额: %.2f %s", transfer.Amount, transfer.Currency)
+	if err != nil {
+		log.Printf("失败原因: %v", err)
+	}
+	if transfer.Status == SCHEDULED_ACTIVE {
+		log.Printf("下次约定日期: %s", transfer.NextDate)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 查找本账户可修改的定时转账：正在执行的拒绝修改，已结束的不可再变更（调用方需持有 s.mu）
+func (s *ScheduledTransferService) modifiable(transferID, accountID string) (*ScheduledTransfer, error) {
+	transfer, ok := s.scheduled[transferID]
+	if !ok || transfer.AccountID != accountID {
+		return nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "定时转账不存在")
+	}
+	switch transfer.Status {
+	case SCHEDULED_EXECUTING:
+		return nil, model.NewError(model.CODE_SERVER_BUSY, "定时转账正在执行，暂不能修改，请稍后再试")
+	case SCHEDULED_COMPLETED, SCHEDULED_FAILED, SCHEDULED_CANCELLED:
+		return nil, model.NewError(model.CODE_PARAM_ERROR, "定时转账已结束（"+transfer.Status+"），不能再修改")
+	}
+	return transfer, nil
+}
+
+// 解析约定日期：格式 YYYY-MM-DD，不早于今日
+func (s *ScheduledTransferService) parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, model.NewError(model.CODE_PARAM_ERROR, "日期格式错误，应为 YYYY-MM-DD")
+	}
+	if date.Before(sim.StartOfDay(s.clock.Now())) {
+		return time.Time{}, model.NewError(model.CODE_PARAM_ERROR, "约定日期不能早于今日")
+	}
+	return date, nil
+}
+
+// 实际执行日：约定日期遇非营业日顺延（节假日可随时调整，每次按当前日历计算）
+func (s *ScheduledTransferService) executeDate(transfer *ScheduledTransfer) time.Time {
+	date, _ := time.ParseInLocation("2006-01-02", transfer.NextDate, time.Local)
+	return s.calendar.Roll(date)
+}
+
+// 返回副本并填入实际执行日（调用方需持有 s.mu）
+func (s *ScheduledTransferService) view(transfer *ScheduledTransfer) ScheduledTransfer {
+	result := *transfer
+	result.ExecuteDate = s.executeDate(transfer).Format("2006-01-02")
+	return result
+}
+
+// 定时转账快照（随状态快照导出）
+type ScheduledTransferSnapshot struct {
+	Transfers []ScheduledTransfer `json:"transfers"`
+	Seq       int64               `json:"seq"`
+}
+
+// 导出定时转账
+func (s *ScheduledTransferService) Snapshot() ScheduledTransferSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := ScheduledTransferSnapshot{Transfers: make([]ScheduledTransfer, 0, len(s.scheduled)), Seq: s.seq}
+	for _, transfer := range s.scheduled {
+		snapshot.Transfers = append(snapshot.Transfers, *transfer)
+	}
+	sort.Slice(snapshot.Transfers, func(i, j int) bool {
+		return snapshot.Transfers[i].TransferID < snapshot.Transfers[j].TransferID
+	})
+	return snapshot
+}
+
+// 以快照替换定时转账。快照时正在执行的转账是否已入账无法确定，按执行失败处理（结果以账户流水为准），避免重复转账
+func (s *ScheduledTransferService) Restore(snapshot ScheduledTransferSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled := make(map[string]*ScheduledTransfer, len(snapshot.Transfers))
+	for _, transfer := range snapshot.Transfers {
+		transfer := transfer
+		scheduled[transfer.TransferID] = &transfer
+	}
+	s.scheduled, s.seq = scheduled, snapshot.Seq
+	for _, transfer := range s.scheduled {
+		if transfer.Status == SCHEDULED_EXECUTING {
+			day, _ := time.ParseInLocation("2006-01-02", transfer.NextDate, time.Local)
+			s.finish(transfer, day, model.NewError(model.CODE_UNKNOWN_ERROR, "快照时正在执行，结果以账户流水为准"))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 约定日期遇周末顺延至下一营业日执行，执行后推进至下一期；正在执行时拒绝修改与取消，每次变更与执行均记入审计日志
+func TestScheduledTransferLifecycle(t *testing.T) {
+	accounts, _ := newTransferFixture(t)
+	clock := accounts.ledger.clock // 2025-03-03（星期一）
+	audit := NewAuditService(clock)
+	scheduled := NewScheduledTransferService(accounts.accounts, accounts, NewCalendarService(CalendarConfig{}, clock), audit, clock)
+
+	transfer, err := scheduled.Create(ScheduledTransferRequest{
+		AccountID: "6200000001",
+		ToAccount: "6200000002",
+		Amount:    100,
+		Frequency: "monthly",
+		Date:      "2025-03-08", // 星期六
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transfer.ExecuteDate != "2025-03-10" {
+		t.Fatalf("执行日 = %s，应顺延至 2025-03-10", transfer.ExecuteDate)
+	}
+
+	scheduled.Run(time.Date(2025, 3, 8, 0, 0, 0, 0, time.Local))
+	if got, _ := scheduled.Get(transfer.TransferID); got.Executions != 0 {
+		t.Fatalf("非营业日不应执行，已执行 %d 次", got.Executions)
+	}
+	scheduled.Run(time.Date(2025, 3, 10, 0, 0, 0, 0, time.Local))
+	got, _ := scheduled.Get(transfer.TransferID)
+	if got.Executions != 1 || got.Status != SCHEDULED_ACTIVE || got.NextDate != "2025-04-08" {
+		t.Fatalf("执行后状态 = %+v，应执行 1 次并推进至 2025-04-08", got)
+	}
+	if account, _ := accounts.accounts.Get("6200000001"); account.Balance != 4900 {
+		t.Fatalf("转出账户余额 = %.2f，应为 4900", account.Balance)
+	}
+
+	scheduled.mu.Lock()
+	scheduled.scheduled[transfer.TransferID].Status = SCHEDULED_EXECUTING
+	scheduled.mu.Unlock()
+	if _, err := scheduled.Amend(transfer.TransferID, ScheduledTransferAmendRequest{AccountID: "6200000001", Amount: 200}); err == nil {
+		t.Fatal("正在执行的定时转账不应允许修改")
+	} else if code, _ := model.ErrorCode(err); code != model.CODE_SERVER_BUSY {
+		t.Fatalf("错误码 = %d，应为 %d", code, model.CODE_SERVER_BUSY)
+	}
+	if _, err := scheduled.Cancel(transfer.TransferID, "6200000001"); err == nil {
+		t.Fatal("正在执行的定时转账不应允许取消")
+	}
+	scheduled.mu.Lock()
+	scheduled.scheduled[transfer.TransferID].Status = SCHEDULED_ACTIVE
+	scheduled.mu.Unlock()
+
+	if _, err := scheduled.Pause(transfer.TransferID, "6200000001"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduled.Resume(transfer.TransferID, "6200000001"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduled.Amend(transfer.TransferID, ScheduledTransferAmendRequest{AccountID: "6200000001", Amount: 150, Date: "2025-04-15"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scheduled.Cancel(transfer.TransferID, "6200000002"); err == nil {
+		t.Fatal("非转出账户不应能取消定时转账")
+	}
+	if _, err := scheduled.Cancel(transfer.TransferID, "6200000001"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{AUDIT_SCHEDULED_CANCELLED, AUDIT_SCHEDULED_AMENDED, AUDIT_SCHEDULED_RESUMED, AUDIT_SCHEDULED_PAUSED, AUDIT_SCHEDULED_EXECUTED, AUDIT_SCHEDULED_CREATED}
+	entries := audit.List(AuditQuery{Target: "6200000001"})
+	if len(entries) != len(want) {
+		t.Fatalf("审计记录 %d 条，应为 %d 条", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Action != want[i] {
+			t.Errorf("第 %d 条审计记录为 %s，应为 %s", i+1, entry.Action, want[i])
+		}
+	}
+}
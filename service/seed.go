@@ -0,0 +1,288 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 余额分布
+const (
+	SEED_DISTRIBUTION_LOGNORMAL = "lognormal" // 对数正态分布（默认）：多数账户余额较小，少数账户余额较大
+	SEED_DISTRIBUTION_UNIFORM   = "uniform"   // 均匀分布
+)
+
+// 单次最多生成的账户数
+const SEED_MAX_ACCOUNTS = 10000
+
+// 模拟数据生成请求（零值字段使用默认值）
+type SeedRequest struct {
+	Count        int     `json:"count"`                         // 账户数
+	Currency     string  `json:"currency"`                      // 账户币种，为空默认人民币
+	Distribution string  `json:"distribution"`                  // 余额分布：lognormal/uniform
+	Median       float64 `json:"median" validate:"min=0"`       // 对数正态分布的中位数，默认 5000
+	Sigma        float64 `json:"sigma" validate:"min=0"`        // 对数正态分布的离散度，默认 1
+	Min          float64 `json:"min" validate:"min=0"`          // 均匀分布下限，默认 0
+	Max          float64 `json:"max" validate:"min=0"`          // 余额上限（均匀分布上限，默认 20000；对数正态分布超出时截断，0 表示不限）
+	Transactions int     `json:"transactions" validate:"min=0"` // 每个账户的历史交易笔数，0 表示只记开户余额
+	HistoryDays  int     `json:"historyDays" validate:"min=0"`  // 历史交易分布的天数，默认 90
+	RandSeed     int64   `json:"randSeed"`                      // 随机种子，相同种子生成相同数据，0 表示随机
+}
+
+// 生成的模拟账户
+type SeedAccount struct {
+	AccountID    string  `json:"accountId"`
+	UserName     string  `json:"userName"`
+	Balance      float64 `json:"balance"`
+	Currency     string  `json:"currency"`
+	ProductCode  string  `json:"productCode"`
+	Transactions int     `json:"transactions"` // 历史流水笔数（含开户余额）
+}
+
+// 模拟数据生成结果
+type SeedResult struct {
+	Count        int           `json:"count"`
+	Transactions int           `json:"transactions"`
+	TotalBalance float64       `json:"totalBalance"`
+	RandSeed     int64         `json:"randSeed"` // 实际使用的随机种子，可用于复现
+	Accounts     []SeedAccount `json:"accounts"`
+}
+
+// 模拟数据服务：批量生成随机账户及历史交易，供演示与压测使用
+type SeedService struct {
+	accounts  *repository.AccountRepository
+	customers *CustomerService
+	ledger    *LedgerService
+	fx        *FXService
+	products  *ProductService
+	clock     Clock
+}
+
+func NewSeedService(accounts *repository.AccountRepository, customers *CustomerService, ledger *LedgerService, fx *FXService, products *ProductService, clock Clock) *SeedService {
+	return &SeedService{accounts: accounts, customers: customers, ledger: ledger, fx: fx, products: products, clock: clock}
+}
+
+// 模拟姓名用字
+var (
+	seedSurnames   = []string{"王", "李", "张", "刘", "陈", "杨", "赵", "黄", "周", "吴", "徐", "孙", "胡", "朱", "高", "林", "何", "郭", "马", "罗"}
+	seedGivenNames = []string{"伟", "芳", "娜", "敏", "静", "丽", "强", "磊", "军", "洋", "勇", "艳", "杰", "娟", "涛", "明", "超", "秀英", "华", "慧", "建国", "子涵", "雨欣", "浩然", "思远"}
+)
+
+// 模拟历史交易：类型、方向与描述
+var seedActivities = []struct {
+	Type        string
+	Direction   string
+	Description string
+}{
+	{"deposit", "credit", "工资入账"},
+	{"deposit", "credit", "现金存款"},
+	{"card_purchase", "debit", "银行卡消费"},
+	{"card_purchase", "debit", "网上购物"},
+	{"teller_withdraw", "debit", "柜台取款"},
+}
+
+// 按请求批量生成账户：余额按指定分布抽取，历史交易倒推生成，保证流水与余额一致
+func (s *SeedService) Seed(req SeedRequest) (SeedResult, error) {
+	if err := s.normalize(&req); err != nil {
+		return SeedResult{}, err
+	}
+	now := s.clock.Now()
+	// 历史流水最早可能落在 HistoryDays 天前，不得进入已结账期间
+	if err := s.ledger.CheckOpenPeriod(now.Add(-time.Duration(req.HistoryDays)*24*time.Hour - time.Second)); err != nil {
+		return SeedResult{}, err
+	}
+	rng := rand.New(rand.NewSource(req.RandSeed))
+	productCodes := []string{PRODUCT_SAVINGS, PRODUCT_CHECKING}
+	products := make(map[string]Product, len(productCodes))
+	for _, code := range productCodes {
+		product, ok := s.products.Effective(code, now)
+		if !ok {
+			return SeedResult{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "产品不存在或尚未生效: "+code)
+		}
+		products[code] = product
+	}
+
+	result := SeedResult{RandSeed: req.RandSeed, Accounts: make([]SeedAccount, 0, req.Count)}
+
+	s.accounts.Lock()
+	next, _ := strconv.ParseInt(nextAccountID(s.accounts), 10, 64)
+	for i := 0; i < req.Count; i++ {
+		name := seedSurnames[rng.Intn(len(seedSurnames))] + seedGivenNames[rng.Intn(len(seedGivenNames))]
+		accountID := strconv.FormatInt(next+int64(i), 10)
+		phone := fmt.Sprintf("1%d%09d", []int{3, 5, 7, 8, 9}[rng.Intn(5)], rng.Intn(1000000000))
+		email := "seed" + accountID + "@example.com"
+		product := products[productCodes[rng.Intn(len(productCodes))]]
+		balance := s.drawBalance(rng, req)
+		history := s.history(rng, req, balance, now)
+
+		createAt := now
+		if len(history) > 0 {
+			createAt = history[0].Time
+		}
+		customer := s.customers.Create(CustomerRequest{Name: name, Phone: phone, Email: email})
+		account := s.accounts.Save(model.Account{
+			AccountID:   accountID,
+			CustomerID:  customer.CustomerID,
+			UserName:    name,
+			Balance:     balance,
+			Currency:    req.Currency,
+			Type:        product.AccountType,
+			ProductCode: product.Code,
+			Status:      "normal",
+			CreateAt:    createAt.Format("2006-01-02"),
+			Email:       email,
+			Phone:       phone,
+			Version:     1,
+		})
+		for _, tx := range history {
+			tx.AccountID = accountID
+			tx.Currency = req.Currency
+			s.ledger.RecordAt(tx, tx.Time)
+		}
+
+		result.Accounts = append(result.Accounts, SeedAccount{
+			AccountID:    account.AccountID,
+			UserName:     account.UserName,
+			Balance:      account.Balance,
+			Currency:     account.Currency,
+			ProductCode:  account.ProductCode,
+			Transactions: len(history),
+		})
+		result.Transactions += len(history)
+		result.TotalBalance += balance
+	}
+	s.accounts.Unlock()
+	result.Count = len(result.Accounts)
+	result.TotalBalance = model.RoundAmount(result.TotalBalance)
+
+	// 终端提示：生成模拟数据
+	log.Println("\n[🌱 生成模拟数据]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户数: %d（%s ~ %s）", result.Count, result.Accounts[0].AccountID, result.Accounts[result.Count-1].AccountID)
+	log.Printf("余额分布: %s，余额合计: %.2f %s", req.Distribution, result.TotalBalance, req.Currency)
+	log.Printf("历史流水: %d 笔（近 %d 天）", result.Transactions, req.HistoryDays)
+	log.Printf("随机种子: %d", req.RandSeed)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 校验请求并填充默认值
+func (s *SeedService) normalize(req *SeedRequest) error {
+	if req.Count <= 0 || req.Count > SEED_MAX_ACCOUNTS {
+		return model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("账户数应在 1~%d 之间", SEED_MAX_ACCOUNTS))
+	}
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		req.Currency = model.BASE_CURRENCY
+	}
+	if !s.fx.IsSupported(req.Currency) {
+		return model.NewError(model.CODE_PARAM_ERROR, "不支持的币种: "+req.Currency)
+	}
+	if err := model.Validate(req); err != nil {
+		return err
+	}
+
+	switch req.Distribution {
+	case "", SEED_DISTRIBUTION_LOGNORMAL:
+		req.Distribution = SEED_DISTRIBUTION_LOGNORMAL
+		if req.Median == 0 {
+			req.Median = 5000
+		}
+		if req.Sigma == 0 {
+			req.Sigma = 1
+		}
+	case SEED_DISTRIBUTION_UNIFORM:
+		if req.Max == 0 {
+			req.Max = 20000
+		}
+		if req.Min >= req.Max {
+			return model.NewError(model.CODE_PARAM_ERROR, "均匀分布的下限应小于上限")
+		}
+	default:
+		return model.NewError(model.CODE_PARAM_ERROR, "余额分布应为 lognormal 或 uniform")
+	}
+
+	if req.HistoryDays == 0 {
+		req.HistoryDays = 90
+	}
+	if req.RandSeed == 0 {
+		req.RandSeed = time.Now().UnixNano()
+	}
+	return nil
+}
+
+// 按分布抽取账户余额（保留两位小数）
+func (s *SeedService) drawBalance(rng *rand.Rand, req SeedRequest) float64 {
+	var balance float64
+	if req.Distribution == SEED_DISTRIBUTION_UNIFORM {
+		balance = req.Min + rng.Float64()*(req.Max-req.Min)
+	} else {
+		balance = req.Median * math.Exp(req.Sigma*rng.NormFloat64())
+		if req.Max > 0 && balance > req.Max {
+			balance = req.Max
+		}
+	}
+	return model.RoundAmount(balance)
+}
+
+// 生成账户的历史流水（按时间正序）：从当前余额倒推每笔交易前的余额，
+// 入账金额不超过入账后余额，保证任一时点余额非负，最早的余额记为开户余额
+func (s *SeedService) history(rng *rand.Rand, req SeedRequest, balance float64, now time.Time) []model.Transaction {
+	span := time.Duration(req.HistoryDays) * 24 * time.Hour
+	times := make([]time.Time, req.Transactions+1)
+	for i := range times {
+		times[i] = now.Add(-time.Duration(rng.Int63n(int64(span))) - time.Second)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	transactions := make([]model.Transaction, req.Transactions+1)
+	after := balance
+	for i := req.Transactions; i > 0; i-- {
+		activity := seedActivities[rng.Intn(len(seedActivities))]
+		var amount float64
+		if activity.Direction == "credit" {
+			amount = model.RoundAmount(after * (0.1 + 0.5*rng.Float64()))
+			if amount <= 0 {
+				// 入账后余额为 0 时无法倒推入账，改为消费
+				activity = seedActivities[2]
+			}
+		}
+		if activity.Direction == "debit" {
+			amount = model.RoundAmount(math.Max(after, 100) * (0.02 + 0.2*rng.Float64()))
+		}
+		transactions[i] = model.Transaction{
+			Type:         activity.Type,
+			Direction:    activity.Direction,
+			Amount:       amount,
+			BalanceAfter: after,
+			Description:  activity.Description,
+			Time:         times[i],
+		}
+		if activity.Direction == "credit" {
+			after = model.RoundAmount(after - amount)
+		} else {
+			after = model.RoundAmount(after + amount)
+		}
+	}
+	if after == 0 {
+		return transactions[1:]
+	}
+	transactions[0] = model.Transaction{
+		Type:         "opening",
+		Direction:    "credit",
+		Amount:       after,
+		BalanceAfter: after,
+		Description:  "开户余额",
+		Time:         times[0],
+	}
+	return transactions
+}
@@ -0,0 +1,26 @@
+// Package service 实现核心银行业务逻辑，不依赖 HTTP，依赖通过构造函数注入。
+package service
+
+import (
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 时钟（生产环境为模拟时钟，测试时可替换为固定时钟）
+type Clock interface {
+	Now() time.Time
+}
+
+// 消息推送（生产环境为 WebSocket hub，测试时可替换为空实现）
+type Notifier interface {
+	Send(msg ws.Message)                                // 推送给所有客户端，并打印推送日志
+	Publish(topic, msgType string, payload interface{}) // 推送给订阅该主题的客户端
+}
+
+// 邮件通知（生产环境为 EmailService，测试时可替换为空实现）
+type Mailer interface {
+	Notify(account model.Account, event string, vars map[string]interface{}) // 按事件模板发送邮件（异步投递）
+	LargeTransferThreshold() float64                                         // 大额转账提醒阈值
+}
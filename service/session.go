@@ -0,0 +1,444 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 会话状态
+const (
+	SESSION_ACTIVE          = "active"
+	SESSION_REAUTH_REQUIRED = "reauth_required" // 需重新认证
+	SESSION_REVOKED         = "revoked"         // 已失效（退出登录、强制下线、账户冻结）
+	SESSION_EXPIRED         = "expired"         // 空闲超时
+)
+
+// 管理员会话控制指令
+const (
+	SESSION_CMD_FORCE_LOGOUT   = "forceLogout"    // 强制退出登录
+	SESSION_CMD_REAUTH         = "reauthRequired" // 要求重新认证
+	SESSION_CMD_ACCOUNT_FROZEN = "accountFrozen"  // 冻结账户并终止会话
+)
+
+// 会话控制指令 → WebSocket 关闭原因
+var sessionCloseReasons = map[string]string{
+	SESSION_CMD_FORCE_LOGOUT:   "已被强制退出登录",
+	SESSION_CMD_REAUTH:         "需要重新认证",
+	SESSION_CMD_ACCOUNT_FROZEN: "账户已冻结",
+}
+
+// 会话配置
+type SessionConfig struct {
+	IdleTimeout time.Duration // 空闲超时（按模拟时钟），0 表示不超时
+}
+
+// 登录请求
+type LoginRequest struct {
+	AccountID  string `json:"accountId"`
+	Password   string `json:"password"`
+	RemoteAddr string `json:"-"`
+	UserAgent  string `json:"-"`
+	DeviceID   string `json:"-"` // 登录设备标识（X-Device-Id 请求头）
+}
+
+// 登录会话
+type Session struct {
+	SessionID  string `json:"sessionId"`
+	Token      string `json:"token,omitempty"` // 仅登录时返回
+	AccountID  string `json:"accountId"`
+	Status     string `json:"status"`
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+	UserAgent  string `json:"userAgent,omitempty"`
+	DeviceID   string `json:"deviceId,omitempty"` // 登录设备标识（X-Device-Id 请求头）
+	CreatedAt  string `json:"createdAt"`
+	LastSeenAt string `json:"lastSeenAt"`
+	EndedAt    string `json:"endedAt,omitempty"`
+	EndReason  string `json:"endReason,omitempty"`
+
+	lastSeen time.Time
+}
+
+// 会话控制请求（管理员）
+type SessionControlRequest struct {
+	AccountID string `json:"accountId"`
+	Command   string `json:"command"` // forceLogout/reauthRequired/accountFrozen
+	Reason    string `json:"reason"`
+}
+
+// 会话控制结果
+type SessionControlResult struct {
+	AccountID         string `json:"accountId"`
+	Command           string `json:"command"`
+	Sessions          int    `json:"sessions"`          // 受影响的会话数
+	ClosedConnections int    `json:"closedConnections"` // 断开的 WebSocket 连接数
+}
+
+// 账户的有效会话（含会话上的 WebSocket 连接）
+type ActiveSession struct {
+	Session
+	Current     bool                `json:"current"`     // 发起查询的会话
+	Connections []ws.ConnectionInfo `json:"connections"` // 以该会话令牌建立的连接
+}
+
+// 账户的在线会话与连接
+type AccountSessions struct {
+	AccountID   string              `json:"accountId"`
+	Sessions    []ActiveSession     `json:"sessions"`
+	Connections []ws.ConnectionInfo `json:"connections"` // 未关联登录会话的连接（未携带令牌建立）
+}
+
+// 撤销会话结果
+type SessionRevokeResult struct {
+	Session           Session `json:"session"`
+	ClosedConnections int     `json:"closedConnections"` // 断开的 WebSocket 连接数
+}
+
+// 会话连接控制（生产环境为 WebSocket hub）：向账户（或指定会话）的在线连接推送控制消息后断开
+type SessionTerminator interface {
+	Terminate(accountID, sessionID string, msg ws.Message, reason string) int
+}
+
+// 会话服务：模拟登录令牌的签发与校验，管理员可强制下线、要求重新认证或冻结账户，
+// 控制指令同时推送到该账户的 WebSocket 连接并使其后的接口调用被拒绝
+type SessionService struct {
+	cfg        SessionConfig
+	accounts   *repository.AccountRepository
+	accountSvc *AccountService
+	guard      *LoginGuardService
+	passwords  *PasswordService
+	terminator SessionTerminator
+	clock      Clock
+
+	mu       sync.Mutex
+	sessions map[string]*Session // 令牌 → 会话
+	seq      int64
+}
+
+func NewSessionService(cfg SessionConfig, accounts *repository.AccountRepository, accountSvc *AccountService, guard *LoginGuardService, passwords *PasswordService, terminator SessionTerminator, clock Clock) *SessionService {
+	return &SessionService{
+		cfg:        cfg,
+		accounts:   accounts,
+		accountSvc: accountSvc,
+		guard:      guard,
+		passwords:  passwords,
+		terminator: terminator,
+		clock:      clock,
+		sessions:   make(map[string]*Session),
+	}
+}
+
+// 登录：校验账户与登录密码，失败次数过多的账户或来源 IP 在锁定期内拒绝登录，成功后返回携带令牌的会话
+func (s *SessionService) Login(req LoginRequest) (Session, error) {
+	accountID, remoteAddr, userAgent, deviceID := req.AccountID, req.RemoteAddr, req.UserAgent, req.DeviceID
+	if err := s.guard.Check(accountID, remoteAddr); err != nil {
+		return Session{}, err
+	}
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		s.guard.Fail(accountID, remoteAddr, "账户不存在")
+		return Session{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if !s.passwords.Verify(accountID, req.Password) {
+		remaining := s.guard.Fail(accountID, remoteAddr, "密码错误")
+		if remaining == 0 {
+			return Session{}, model.NewError(model.CODE_LOGIN_LOCKED, "密码错误，登录失败次数过多，账户已锁定")
+		}
+		return Session{}, model.NewError(model.CODE_ACCOUNT_ERROR, fmt.Sprintf("密码错误，还可尝试 %d 次", remaining))
+	}
+	if account.Status == "frozen" {
+		return Session{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结，无法登录")
+	}
+	s.guard.Succeed(accountID)
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return Session{}, err
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	s.seq++
+	session := &Session{
+		SessionID:  fmt.Sprintf("SS%s%06d", now.Format("20060102"), s.seq),
+		Token:      hex.EncodeToString(raw),
+		AccountID:  accountID,
+		Status:     SESSION_ACTIVE,
+		RemoteAddr: remoteAddr,
+		UserAgent:  userAgent,
+		DeviceID:   deviceID,
+		CreatedAt:  now.Format("2006-01-02 15:04:05"),
+		LastSeenAt: now.Format("2006-01-02 15:04:05"),
+		lastSeen:   now,
+	}
+	s.sessions[session.Token] = session
+	s.mu.Unlock()
+
+	// 终端提示：登录
+	log.Println("\n[🔑 用户登录]")
+	log.Printf("登录时间: %s", session.CreatedAt)
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("会话编号: %s", session.SessionID)
+	log.Printf("客户端地址: %s", remoteAddr)
+	if deviceID != "" {
+		log.Printf("设备标识: %s", deviceID)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *session, nil
+}
+
+// 校验令牌：会话有效时刷新最近访问时间并返回会话（不含令牌）
+func (s *SessionService) Authenticate(token string) (Session, error) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	if ok && session.Status == SESSION_ACTIVE && s.cfg.IdleTimeout > 0 && now.Sub(session.lastSeen) > s.cfg.IdleTimeout {
+		s.end(session, SESSION_EXPIRED, "空闲超时", now)
+	}
+	if !ok {
+		s.mu.Unlock()
+		return Session{}, model.NewError(model.CODE_NOT_LOGIN, "会话不存在，请先登录")
+	}
+	switch session.Status {
+	case SESSION_REAUTH_REQUIRED:
+		s.mu.Unlock()
+		return Session{}, model.NewError(model.CODE_NOT_LOGIN, "需要重新认证，请重新登录")
+	case SESSION_REVOKED, SESSION_EXPIRED:
+		reason := session.EndReason
+		s.mu.Unlock()
+		return Session{}, model.NewError(model.CODE_NOT_LOGIN, "会话已失效（"+reason+"），请重新登录")
+	}
+	session.lastSeen = now
+	session.LastSeenAt = now.Format("2006-01-02 15:04:05")
+	result := *session
+	s.mu.Unlock()
+
+	// 账户冻结后已签发的会话立即失效
+	if account, exists := s.accounts.Get(result.AccountID); !exists || account.Status == "frozen" {
+		return Session{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "账户已冻结")
+	}
+	result.Token = ""
+	return result, nil
+}
+
+// 退出登录
+func (s *SessionService) Logout(token string) (Session, error) {
+	s.mu.Lock()
+	session, ok := s.sessions[token]
+	if !ok || session.Status != SESSION_ACTIVE {
+		s.mu.Unlock()
+		return Session{}, model.NewError(model.CODE_NOT_LOGIN, "会话不存在或已失效")
+	}
+	s.end(session, SESSION_REVOKED, "退出登录", s.clock.Now())
+	result := *session
+	s.mu.Unlock()
+
+	s.terminator.Terminate(result.AccountID, result.SessionID, ws.Message{
+		Type:      "sessionControl",
+		AccountID: result.AccountID,
+		Message:   "已退出登录",
+		Data:      map[string]string{"command": "logout", "sessionId": result.SessionID},
+	}, "已退出登录")
+	result.Token = ""
+	return result, nil
+}
+
+// 管理员会话控制：更新会话状态（冻结指令同时冻结账户），并向该账户的 WebSocket 连接推送指令后断开
+func (s *SessionService) Control(req SessionControlRequest) (SessionControlResult, error) {
+	if _, ok := sessionCloseReasons[req.Command]; !ok {
+		return SessionControlResult{}, model.NewError(model.CODE_PARAM_ERROR, "指令无效，可选 forceLogout/reauthRequired/accountFrozen")
+	}
+	if _, exists := s.accounts.Get(req.AccountID); !exists {
+		return SessionControlResult{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if req.Command == SESSION_CMD_ACCOUNT_FROZEN {
+		if _, err := s.accountSvc.SetStatus(AccountStatusRequest{AccountID: req.AccountID, Status: "frozen", Reason: req.Reason}); err != nil {
+			return SessionControlResult{}, err
+		}
+	}
+	return s.Terminate(req.AccountID, req.Command, req.Reason), nil
+}
+
+// 终止账户的全部有效会话并断开其 WebSocket 连接（账户冻结时由状态变更接口调用）
+func (s *SessionService) Terminate(accountID, command, reason string) SessionControlResult {
+	status, endReason := SESSION_REVOKED, sessionCloseReasons[command]
+	if command == SESSION_CMD_REAUTH {
+		status = SESSION_REAUTH_REQUIRED
+	}
+	if reason != "" {
+		endReason += "：" + reason
+	}
+
+	now := s.clock.Now()
+	result := SessionControlResult{AccountID: accountID, Command: command}
+	s.mu.Lock()
+	for _, session := range s.sessions {
+		if session.AccountID == accountID && session.Status == SESSION_ACTIVE {
+			s.end(session, status, endReason, now)
+			result.Sessions++
+		}
+	}
+	s.mu.Unlock()
+
+	result.ClosedConnections = s.terminator.Terminate(accountID, "", ws.Message{
+		Type:      "sessionControl",
+		AccountID: accountID,
+		Message:   endReason,
+		Data:      map[string]string{"command": command, "reason": reason},
+	}, sessionCloseReasons[command])
+
+	// 终端提示：会话控制
+	log.Println("\n[🛂 会话控制]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("控制指令: %s", command)
+	if reason != "" {
+		log.Printf("原因: %s", reason)
+	}
+	log.Printf("失效会话: %d，断开连接: %d", result.Sessions, result.ClosedConnections)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result
+}
+
+// 终止设备上的全部有效会话并断开对应的 WebSocket 连接（移除设备时调用），返回失效会话数
+func (s *SessionService) TerminateDevice(accountID, deviceID string) int {
+	now := s.clock.Now()
+	var ended []string
+	s.mu.Lock()
+	for _, session := range s.sessions {
+		if session.AccountID == accountID && session.DeviceID == deviceID && session.Status == SESSION_ACTIVE {
+			s.end(session, SESSION_REVOKED, "设备已移除", now)
+			ended = append(ended, session.SessionID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sessionID := range ended {
+		s.terminator.Terminate(accountID, sessionID, ws.Message{
+			Type:      "sessionControl",
+			AccountID: accountID,
+			Message:   "设备已移除",
+			Data:      map[string]string{"command": "deviceRevoked", "sessionId": sessionID, "deviceId": deviceID},
+		}, "设备已移除")
+	}
+	return len(ended)
+}
+
+// 账户的有效会话（按会话编号倒序），connections 为该账户的在线连接，按所属会话归入各会话，
+// currentSessionID 为发起查询的会话（未登录时为空）；空闲超时的会话在此一并失效
+func (s *SessionService) Active(accountID, currentSessionID string, connections []ws.ConnectionInfo) AccountSessions {
+	bySession := make(map[string][]ws.ConnectionInfo)
+	result := AccountSessions{AccountID: accountID, Sessions: []ActiveSession{}, Connections: []ws.ConnectionInfo{}}
+	for _, conn := range connections {
+		if conn.SessionID == "" {
+			result.Connections = append(result.Connections, conn)
+			continue
+		}
+		bySession[conn.SessionID] = append(bySession[conn.SessionID], conn)
+	}
+
+	now := s.clock.Now()
+	s.mu.Lock()
+	for _, session := range s.sessions {
+		if session.AccountID != accountID || session.Status != SESSION_ACTIVE {
+			continue
+		}
+		if s.cfg.IdleTimeout > 0 && now.Sub(session.lastSeen) > s.cfg.IdleTimeout {
+			s.end(session, SESSION_EXPIRED, "空闲超时", now)
+			continue
+		}
+		item := ActiveSession{Session: *session, Current: session.SessionID == currentSessionID, Connections: bySession[session.SessionID]}
+		item.Token = ""
+		if item.Connections == nil {
+			item.Connections = []ws.ConnectionInfo{}
+		}
+		result.Sessions = append(result.Sessions, item)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result.Sessions, func(i, j int) bool { return result.Sessions[i].SessionID > result.Sessions[j].SessionID })
+	return result
+}
+
+// 撤销账户的指定会话（如在其他设备上远程下线）：令牌立即失效，并推送控制消息后断开该会话的 WebSocket 连接
+func (s *SessionService) Revoke(accountID, sessionID string) (SessionRevokeResult, error) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	var session *Session
+	for _, item := range s.sessions {
+		if item.SessionID == sessionID && item.AccountID == accountID {
+			session = item
+			break
+		}
+	}
+	if session == nil {
+		s.mu.Unlock()
+		return SessionRevokeResult{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "会话不存在")
+	}
+	if session.Status != SESSION_ACTIVE {
+		s.mu.Unlock()
+		return SessionRevokeResult{}, model.NewError(model.CODE_PARAM_ERROR, "会话已失效")
+	}
+	s.end(session, SESSION_REVOKED, "已远程下线", now)
+	result := SessionRevokeResult{Session: *session}
+	s.mu.Unlock()
+
+	result.Session.Token = ""
+	result.ClosedConnections = s.terminator.Terminate(accountID, sessionID, ws.Message{
+		Type:      "sessionControl",
+		AccountID: accountID,
+		Message:   "会话已在其他设备上被下线",
+		Data:      map[string]string{"command": "sessionRevoked", "sessionId": sessionID},
+	}, "已远程下线")
+
+	// 终端提示：远程下线
+	log.Println("\n[🛂 远程下线]")
+	log.Printf("操作时间: %s", now.Format("2006-01-02 15:04:05"))
+	log.Printf("账户ID: %s", accountID)
+	log.Printf("会话编号: %s", sessionID)
+	if result.Session.DeviceID != "" {
+		log.Printf("设备标识: %s", result.Session.DeviceID)
+	}
+	log.Printf("登录IP: %s", result.Session.RemoteAddr)
+	log.Printf("断开连接: %d", result.ClosedConnections)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return result, nil
+}
+
+// 结束会话（调用方持有锁）
+func (s *SessionService) end(session *Session, status, reason string, now time.Time) {
+	session.Status = status
+	session.EndReason = reason
+	session.EndedAt = now.Format("2006-01-02 15:04:05")
+}
+
+// 会话列表（不含令牌，按会话编号倒序），accountID 为空时返回全部
+func (s *SessionService) List(accountID string) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Session{}
+	for _, session := range s.sessions {
+		if accountID != "" && session.AccountID != accountID {
+			continue
+		}
+		item := *session
+		item.Token = ""
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SessionID > result[j].SessionID })
+	return result
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 清算类别
+const (
+	SETTLEMENT_CARD         = "card"         // 银行卡消费/退货
+	SETTLEMENT_MERCHANT     = "merchant"     // 商户收单入账/退货/手续费
+	SETTLEMENT_INTERBANK    = "interbank"    // 跨行转出/退款
+	SETTLEMENT_DIRECT_DEBIT = "direct_debit" // 直接借记扣款/退回
+)
+
+// 清算文件格式
+const (
+	SETTLEMENT_FORMAT_CSV   = "csv"
+	SETTLEMENT_FORMAT_FIXED = "fixed" // 定长格式
+)
+
+// 纳入清算文件的交易类型 → 清算类别
+var settlementCategories = map[string]string{
+	"card_purchase":          SETTLEMENT_CARD,
+	"card_refund":            SETTLEMENT_CARD,
+	"pos_sale":               SETTLEMENT_MERCHANT,
+	"pos_refund":             SETTLEMENT_MERCHANT,
+	"interchange_fee":        SETTLEMENT_MERCHANT,
+	"interchange_fee_refund": SETTLEMENT_MERCHANT,
+	"interbank_out":          SETTLEMENT_INTERBANK,
+	"interbank_refund":       SETTLEMENT_INTERBANK,
+	"direct_debit":           SETTLEMENT_DIRECT_DEBIT,
+	"direct_debit_return":    SETTLEMENT_DIRECT_DEBIT,
+}
+
+// 清算明细
+type SettlementRecord struct {
+	SeqNo        int     `json:"seqNo"`
+	Category     string  `json:"category"`
+	Counterparty string  `json:"counterparty"` // 清算对手方（商户、结算账户、他行 BIC 或收款方）
+	TxID         string  `json:"txId"`
+	AccountID    string  `json:"accountId"`
+	Type         string  `json:"type"`
+	Direction    string  `json:"direction"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	Reference    string  `json:"reference,omitempty"`
+	Time         string  `json:"time"`
+}
+
+// 对手方净头寸（净额 = 入账 - 出账，按本行客户账户方向）
+type NetPosition struct {
+	Category     string  `json:"category"`
+	Counterparty string  `json:"counterparty"`
+	Currency     string  `json:"currency"`
+	Count        int     `json:"count"`
+	CreditTotal  float64 `json:"creditTotal"`
+	DebitTotal   float64 `json:"debitTotal"`
+	Net          float64 `json:"net"`
+}
+
+// 日终清算文件
+type SettlementFile struct {
+	FileID      string             `json:"fileId"`
+	Date        string             `json:"date"` // 清算日期 YYYY-MM-DD
+	RecordCount int                `json:"recordCount"`
+	Records     []SettlementRecord `json:"records,omitempty"` // 列表查询时不含明细
+	Positions   []NetPosition      `json:"positions"`
+	GeneratedAt string             `json:"generatedAt"`
+}
+
+// 清算文件服务：日终汇总卡、收单、跨行与直接借记交易，按对手方轧差并生成清算文件供下游清算系统使用
+type SettlementService struct {
+	journal   *repository.JournalRepository
+	interbank *InterbankService
+	clock     Clock
+
+	files map[string]SettlementFile // 清算日期 → 清算文件
+	mu    sync.RWMutex
+}
+
+func NewSettlementService(journal *repository.JournalRepository, interbank *InterbankService, clock Clock) *SettlementService {
+	return &SettlementService{
+		journal:   journal,
+		interbank: interbank,
+		clock:     clock,
+		files:     make(map[string]SettlementFile),
+	}
+}
+
+// 日终任务：生成 day 当日的清算文件（重复执行时覆盖）
+func (s *SettlementService) Generate(day time.Time) {
+	from, to := day, day.AddDate(0, 0, 1)
+
+	// 先在读锁内复制流水，再查询跨行支付，避免与记账锁交叉
+	var transactions []model.Transaction
+	s.journal.View(func(all []model.Transaction, _ []model.LedgerEntry) {
+		for _, tx := range all {
+			if _, ok := settlementCategories[tx.Type]; ok && !tx.Time.Before(from) && tx.Time.Before(to) {
+				transactions = append(transactions, tx)
+			}
+		}
+	})
+
+	file := SettlementFile{
+		FileID:      "SF" + day.Format("20060102"),
+		Date:        day.Format("2006-01-02"),
+		Records:     []SettlementRecord{},
+		Positions:   []NetPosition{},
+		GeneratedAt: s.clock.Now().Format("2006-01-02 15:04:05"),
+	}
+	positions := make(map[string]*NetPosition)
+	for _, tx := range transactions {
+		record := SettlementRecord{
+			SeqNo:        len(file.Records) + 1,
+			Category:     settlementCategories[tx.Type],
+			Counterparty: s.counterparty(tx),
+			TxID:         tx.TxID,
+			AccountID:    tx.AccountID,
+			Type:         tx.Type,
+			Direction:    tx.Direction,
+			Amount:       tx.Amount,
+			Currency:     tx.Currency,
+			Reference:    tx.Reference,
+			Time:         tx.Time.Format("2006-01-02 15:04:05"),
+		}
+		file.Records = append(file.Records, record)
+
+		key := record.Category + "|" + record.Counterparty + "|" + record.Currency
+		position := positions[key]
+		if position == nil {
+			position = &NetPosition{Category: record.Category, Counterparty: record.Counterparty, Currency: record.Currency}
+			positions[key] = position
+		}
+		position.Count++
+		if tx.Direction == "credit" {
+			position.CreditTotal += tx.Amount
+		} else {
+			position.DebitTotal += tx.Amount
+		}
+	}
+	for _, position := range positions {
+		position.CreditTotal = model.RoundAmount(position.CreditTotal)
+		position.DebitTotal = model.RoundAmount(position.DebitTotal)
+		position.Net = model.RoundAmount(position.CreditTotal - position.DebitTotal)
+		file.Positions = append(file.Positions, *position)
+	}
+	sort.Slice(file.Positions, func(i, j int) bool {
+		a, b := file.Positions[i], file.Positions[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Counterparty != b.Counterparty {
+			return a.Counterparty < b.Counterparty
+		}
+		return a.Currency < b.Currency
+	})
+	file.RecordCount = len(file.Records)
+
+	s.mu.Lock()
+	s.files[file.Date] = file
+	s.mu.Unlock()
+
+	// 终端提示：清算文件生成
+	log.Println("\n[📦 清算文件生成]")
+	log.Printf("文件编号: %s", file.FileID)
+	log.Printf("清算日期: %s", file.Date)
+	log.Printf("明细笔数: %d，对手方头寸: %d", file.RecordCount, len(file.Positions))
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
+
+// 清算对手方：跨行交易为收款行 BIC，收单交易为商户结算账户，其余为流水记录的对手方
+func (s *SettlementService) counterparty(tx model.Transaction) string {
+	switch settlementCategories[tx.Type] {
+	case SETTLEMENT_INTERBANK:
+		if payment, ok := s.interbank.Payment(tx.Reference); ok {
+			return payment.ToBank
+		}
+	case SETTLEMENT_MERCHANT:
+		return tx.AccountID
+	}
+	if tx.Counterparty == "" {
+		return "UNKNOWN"
+	}
+	return tx.Counterparty
+}
+
+// 查询指定日期的清算文件
+func (s *SettlementService) File(date string) (SettlementFile, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, ok := s.files[date]
+	return file, ok
+}
+
+// 已生成的清算文件（不含明细，按日期倒序）
+func (s *SettlementService) Files() []SettlementFile {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []SettlementFile{}
+	for _, file := range s.files {
+		file.Records = nil
+		result = append(result, file)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date > result[j].Date })
+	return result
+}
+
+// 导出清算文件（csv 或 fixed 定长格式）
+func (s *SettlementService) Export(file SettlementFile, format string) ([]byte, error) {
+	switch format {
+	case SETTLEMENT_FORMAT_CSV, "":
+		return settlementCSV(file)
+	case SETTLEMENT_FORMAT_FIXED:
+		return []byte(settlementFixedWidth(file)), nil
+	}
+	return nil, model.NewError(model.CODE_PARAM_ERROR, "不支持的文件格式，可选 csv/fixed")
+}
+
+// CSV 格式：记录类型 D 为明细，P 为对手方净头寸
+func settlementCSV(file SettlementFile) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"recordType", "seqNo", "category", "counterparty", "txId", "accountId", "type", "direction", "amount", "currency", "reference", "time"})
+	for _, r := range file.Records {
+		w.Write([]string{"D", fmt.Sprint(r.SeqNo), r.Category, r.Counterparty, r.TxID, r.AccountID, r.Type, r.Direction,
+			fmt.Sprintf("%.2f", r.Amount), r.Currency, r.Reference, r.Time})
+	}
+	w.Write([]string{"recordType", "category", "counterparty", "currency", "count", "creditTotal", "debitTotal", "net"})
+	for _, p := range file.Positions {
+		w.Write([]string{"P", p.Category, p.Counterparty, p.Currency, fmt.Sprint(p.Count),
+			fmt.Sprintf("%.2f", p.CreditTotal), fmt.Sprintf("%.2f", p.DebitTotal), fmt.Sprintf("%.2f", p.Net)})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// 定长格式（宽度按字符计）：
+//
+//	H 文件头  1 类型 | 12 文件编号 | 8 清算日期 | 11 本行 BIC | 14 生成时间
+//	D 明细    1 类型 | 6 序号 | 12 类别 | 24 对手方 | 24 流水号 | 12 账户 | 22 交易类型 | 1 借贷(D/C) | 15 金额 | 3 币种 | 24 参考号 | 14 时间
+//	P 净头寸  1 类型 | 12 类别 | 24 对手方 | 3 币种 | 6 笔数 | 15 入账合计 | 15 出账合计 | 16 净额（带符号）
+//	T 文件尾  1 类型 | 6 明细笔数 | 6 头寸笔数
+func settlementFixedWidth(file SettlementFile) string {
+	var b strings.Builder
+	generated, _ := time.ParseInLocation("2006-01-02 15:04:05", file.GeneratedAt, time.Local)
+	fmt.Fprintf(&b, "H%-12s%s%-11s%s\r\n", file.FileID, strings.ReplaceAll(file.Date, "-", ""), model.BANK_BIC, generated.Format("20060102150405"))
+
+	for _, r := range file.Records {
+		mark := "C"
+		if r.Direction == "debit" {
+			mark = "D"
+		}
+		at, _ := time.ParseInLocation("2006-01-02 15:04:05", r.Time, time.Local)
+		fmt.Fprintf(&b, "D%06d%-12.12s%-24.24s%-24.24s%-12.12s%-22.22s%s%015.2f%-3.3s%-24.24s%s\r\n",
+			r.SeqNo, r.Category, r.Counterparty, r.TxID, r.AccountID, r.Type, mark, r.Amount, r.Currency, r.Reference, at.Format("20060102150405"))
+	}
+	for _, p := range file.Positions {
+		fmt.Fprintf(&b, "P%-12.12s%-24.24s%-3.3s%06d%015.2f%015.2f%+016.2f\r\n",
+			p.Category, p.Counterparty, p.Currency, p.Count, p.CreditTotal, p.DebitTotal, p.Net)
+	}
+	fmt.Fprintf(&b, "T%06d%06d\r\n", len(file.Records), len(file.Positions))
+	return b.String()
+}
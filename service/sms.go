@@ -0,0 +1,381 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 短信类型
+const (
+	SMS_OTP               = "otp"              // 验证码
+	SMS_TRANSACTION_ALERT = "transactionAlert" // 交易提醒
+	SMS_PASSWORD_RESET    = "passwordReset"    // 密码重置令牌
+	SMS_SECURITY_ALERT    = "securityAlert"    // 安全提醒（如联系方式变更后通知原手机号）
+)
+
+const (
+	otpTTL         = 5 * time.Minute // 验证码有效期（模拟时钟）
+	otpMaxFailures = 5               // 验证码最多允许输错次数，超过后作废
+)
+
+// 模拟短信网关的失败原因
+var smsGatewayErrors = []string{
+	"网关响应超时",
+	"运营商拒绝下发",
+	"用户手机暂时无法接通",
+}
+
+// 短信配置
+type SMSConfig struct {
+	FailureRate   float64       // 单次下发失败概率（用于测试重试逻辑）
+	MaxAttempts   int           // 最多下发次数（含首次）
+	RetryInterval time.Duration // 首次重试间隔，之后按 2 倍递增
+}
+
+// 短信配置调整请求结构体（字段为空表示不变）
+type SMSConfigRequest struct {
+	FailureRate *float64 `json:"failureRate" validate:"omitempty,min=0,max=1"`
+	MaxAttempts *int     `json:"maxAttempts" validate:"omitempty,min=1,max=10"`
+}
+
+// 验证码发送请求结构体
+type OTPRequest struct {
+	AccountID string `json:"accountId" validate:"required,account"`
+	Purpose   string `json:"purpose" validate:"required,max=32"` // 业务场景，如 login/transfer/resetPassword
+}
+
+// 验证码校验请求结构体
+type OTPVerifyRequest struct {
+	AccountID string `json:"accountId" validate:"required,account"`
+	Purpose   string `json:"purpose" validate:"required,max=32"`
+	Code      string `json:"code" validate:"required"`
+}
+
+// 验证码发送结果（不含验证码本身，测试可通过短信发件箱读取）
+type OTPResult struct {
+	SMSID     string `json:"smsId"`
+	Phone     string `json:"phone"` // 脱敏手机号
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// 单次下发记录
+type SMSAttempt struct {
+	Attempt int    `json:"attempt"`
+	Time    string `json:"time"`
+	Result  string `json:"result"` // delivered/failed
+	Error   string `json:"error,omitempty"`
+}
+
+// 短信记录
+type SMS struct {
+	ID          string       `json:"id"`
+	AccountID   string       `json:"accountId"`
+	To          string       `json:"to"`
+	Type        string       `json:"type"`
+	Content     string       `json:"content"`
+	Status      string       `json:"status"` // queued/retrying/delivered/failed
+	Attempts    []SMSAttempt `json:"attempts"`
+	CreatedAt   string       `json:"createdAt"`
+	DeliveredAt string       `json:"deliveredAt,omitempty"`
+}
+
+// 待校验的验证码
+type otpEntry struct {
+	code      string
+	expiresAt time.Time
+	failures  int
+}
+
+// 短信服务：包装推送通道，交易提醒同时以短信下发；提供验证码收发，模拟网关的下发状态与失败重试。
+// 网关熔断期间短信暂停下发（按重试间隔等待恢复），交易提醒仍经推送通道送达，验证码直接拒绝获取
+type SMSService struct {
+	next      Notifier
+	accounts  *repository.AccountRepository
+	templates *TemplateRegistry
+	clock     Clock
+	gateway   *CircuitBreaker
+
+	cfg      SMSConfig
+	messages []*SMS
+	otps     map[string]*otpEntry // 账户ID + 业务场景 → 验证码
+	seq      int64
+	mu       sync.Mutex
+}
+
+func NewSMSService(cfg SMSConfig, accounts *repository.AccountRepository, templates *TemplateRegistry, next Notifier, clock Clock, gateway *CircuitBreaker) *SMSService {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	log.Printf("短信通道: 模拟网关，失败率: %.2f，最多下发: %d 次，重试间隔: %s",
+		cfg.FailureRate, cfg.MaxAttempts, cfg.RetryInterval)
+	return &SMSService{
+		next:      next,
+		accounts:  accounts,
+		templates: templates,
+		clock:     clock,
+		gateway:   gateway,
+		cfg:       cfg,
+		otps:      make(map[string]*otpEntry),
+	}
+}
+
+// 推送消息（交易提醒同时发送短信）
+func (s *SMSService) Send(msg ws.Message) {
+	if msg.Type == "transactionAlert" && msg.AccountID != "" && msg.Topic == "" {
+		// 调用方可能持有账户锁，查询手机号与下发均在后台进行
+		go s.alert(msg)
+	}
+	s.next.Send(msg)
+}
+
+// 推送主题消息（不发送短信）
+func (s *SMSService) Publish(topic, msgType string, payload interface{}) {
+	s.next.Publish(topic, msgType, payload)
+}
+
+// 发送交易提醒短信（账户未登记手机号时跳过），事件未定义短信模板时套用通用短信格式
+func (s *SMSService) alert(msg ws.Message) {
+	account, exists := s.accounts.Get(msg.AccountID)
+	if !exists || account.Phone == "" {
+		return
+	}
+	_, content, err := s.templates.Render(msg.Event, CHANNEL_SMS, account, msg.Vars)
+	if err != nil {
+		_, content, err = s.templates.Render(EVENT_SMS_ALERT, CHANNEL_SMS, account, map[string]interface{}{"Message": msg.Message})
+	}
+	if err != nil {
+		log.Printf("短信模板渲染失败: %v", err)
+		return
+	}
+	s.enqueue(account, SMS_TRANSACTION_ALERT, content)
+}
+
+// 发送验证码短信（同一场景重复获取时旧验证码作废）
+func (s *SMSService) SendOTP(req OTPRequest) (OTPResult, error) {
+	if err := model.Validate(req); err != nil {
+		return OTPResult{}, err
+	}
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return OTPResult{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+	if account.Phone == "" {
+		return OTPResult{}, model.NewError(model.CODE_ACCOUNT_ERROR, "账户未登记手机号")
+	}
+	if s.gateway.Open() {
+		return OTPResult{}, model.NewError(model.CODE_SERVER_BUSY, "短信网关暂不可用，请稍后重试或改用其他验证方式")
+	}
+
+	code := fmt.Sprintf("%06d", rand.Intn(1000000))
+	_, content, err := s.templates.Render(EVENT_OTP, CHANNEL_SMS, account, map[string]interface{}{
+		"Code":    code,
+		"Minutes": int(otpTTL.Minutes()),
+	})
+	if err != nil {
+		return OTPResult{}, err
+	}
+	expiresAt := s.clock.Now().Add(otpTTL)
+	s.mu.Lock()
+	s.otps[otpKey(req.AccountID, req.Purpose)] = &otpEntry{code: code, expiresAt: expiresAt}
+	s.mu.Unlock()
+
+	sms := s.enqueue(account, SMS_OTP, content)
+	return OTPResult{
+		SMSID:     sms.ID,
+		Phone:     maskPhone(account.Phone),
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// 按模板给账户发送业务短信（如密码重置令牌），账户未登记手机号时返回错误
+func (s *SMSService) Notify(account model.Account, smsType, event string, vars map[string]interface{}) (SMS, error) {
+	if account.Phone == "" {
+		return SMS{}, model.NewError(model.CODE_ACCOUNT_ERROR, "账户未登记手机号")
+	}
+	_, content, err := s.templates.Render(event, CHANNEL_SMS, account, vars)
+	if err != nil {
+		return SMS{}, err
+	}
+	return s.enqueue(account, smsType, content), nil
+}
+
+// 校验验证码（校验成功后作废，输错次数过多时作废）
+func (s *SMSService) VerifyOTP(req OTPVerifyRequest) error {
+	if err := model.Validate(req); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := otpKey(req.AccountID, req.Purpose)
+	entry, ok := s.otps[key]
+	if !ok || !s.clock.Now().Before(entry.expiresAt) {
+		delete(s.otps, key)
+		return model.NewError(model.CODE_PARAM_ERROR, "验证码已失效，请重新获取")
+	}
+	if entry.code != req.Code {
+		entry.failures++
+		if entry.failures >= otpMaxFailures {
+			delete(s.otps, key)
+			return model.NewError(model.CODE_RISK_CONTROL_REJECT, "验证码错误次数过多，请重新获取")
+		}
+		return model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("验证码错误，还可尝试 %d 次", otpMaxFailures-entry.failures))
+	}
+	delete(s.otps, key)
+	return nil
+}
+
+// 生成短信记录并在后台下发
+func (s *SMSService) enqueue(account model.Account, smsType, content string) SMS {
+	s.mu.Lock()
+	now := s.clock.Now()
+	s.seq++
+	sms := &SMS{
+		ID:        fmt.Sprintf("SM%s%06d", now.Format("20060102"), s.seq),
+		AccountID: account.AccountID,
+		To:        account.Phone,
+		Type:      smsType,
+		Content:   content,
+		Status:    "queued",
+		Attempts:  make([]SMSAttempt, 0),
+		CreatedAt: now.Format("2006-01-02 15:04:05"),
+	}
+	s.messages = append(s.messages, sms)
+	snapshot := *sms
+	s.mu.Unlock()
+
+	go s.deliver(sms)
+	return snapshot
+}
+
+// 模拟网关下发：按配置的失败率随机失败，失败后按指数退避重试，直至送达或用尽次数
+func (s *SMSService) deliver(sms *SMS) {
+	for attempt := 1; ; attempt++ {
+		s.mu.Lock()
+		cfg := s.cfg
+		s.mu.Unlock()
+
+		record := SMSAttempt{Attempt: attempt, Time: s.clock.Now().Format("2006-01-02 15:04:05"), Result: "delivered"}
+		if err := s.gateway.Allow(); err != nil {
+			record.Result = "failed"
+			record.Error = "短信网关熔断中，暂停下发"
+		} else {
+			var gatewayErr error
+			if rand.Float64() < cfg.FailureRate {
+				record.Result = "failed"
+				record.Error = smsGatewayErrors[rand.Intn(len(smsGatewayErrors))]
+				gatewayErr = errors.New(record.Error)
+			}
+			s.gateway.Record(gatewayErr)
+		}
+
+		s.mu.Lock()
+		sms.Attempts = append(sms.Attempts, record)
+		switch {
+		case record.Result == "delivered":
+			sms.Status = "delivered"
+			sms.DeliveredAt = record.Time
+		case attempt >= cfg.MaxAttempts:
+			sms.Status = "failed"
+		default:
+			sms.Status = "retrying"
+		}
+		snapshot := *sms
+		s.mu.Unlock()
+
+		if snapshot.Status == "retrying" {
+			log.Printf("短信下发失败（%s → %s，第 %d 次）: %s，稍后重试", snapshot.ID, maskPhone(snapshot.To), attempt, record.Error)
+			time.Sleep(cfg.RetryInterval << (attempt - 1))
+			continue
+		}
+
+		// 终端提示：短信下发结果
+		log.Println("\n[📱 短信下发]")
+		log.Printf("短信编号: %s", snapshot.ID)
+		log.Printf("接收号码: %s", maskPhone(snapshot.To))
+		log.Printf("类型: %s", snapshot.Type)
+		log.Printf("内容: %s", snapshot.Content)
+		if snapshot.Status == "delivered" {
+			log.Printf("状态: \033[1;32m已送达\033[0m（第 %d 次下发）", attempt)
+		} else {
+			log.Printf("状态: \033[1;31m下发失败\033[0m（已尝试 %d 次，最后错误: %s）", attempt, record.Error)
+		}
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+		return
+	}
+}
+
+// 查询短信发件箱（按时间倒序），accountID/smsType/status 为空表示不限
+func (s *SMSService) Messages(accountID, smsType, status string) []SMS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]SMS, 0)
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		sms := *s.messages[i]
+		if (accountID != "" && sms.AccountID != accountID) || (smsType != "" && sms.Type != smsType) ||
+			(status != "" && sms.Status != status) {
+			continue
+		}
+		sms.Attempts = append([]SMSAttempt(nil), sms.Attempts...)
+		result = append(result, sms)
+	}
+	return result
+}
+
+// 清空短信发件箱（测试用例之间重置）
+func (s *SMSService) ClearMessages() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := len(s.messages)
+	s.messages = nil
+	return count
+}
+
+// 查询短信网关配置
+func (s *SMSService) Config() SMSConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// 调整短信网关失败率与最多下发次数（对之后的下发生效）
+func (s *SMSService) SetConfig(req SMSConfigRequest) (SMSConfig, error) {
+	if err := model.Validate(req); err != nil {
+		return SMSConfig{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.FailureRate != nil {
+		s.cfg.FailureRate = *req.FailureRate
+	}
+	if req.MaxAttempts != nil {
+		s.cfg.MaxAttempts = *req.MaxAttempts
+	}
+	return s.cfg, nil
+}
+
+// 验证码存储键
+func otpKey(accountID, purpose string) string {
+	return accountID + "/" + purpose
+}
+
+// 手机号脱敏（保留前三位与后四位）
+func maskPhone(phone string) string {
+	if len(phone) < 7 {
+		return phone
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 快照文件格式版本（不兼容的格式调整时递增）
+const SNAPSHOT_VERSION = 4
+
+// 快照名称：字母、数字、下划线与连字符
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// 模拟系统状态快照：账户、冻结记录、客户、交易流水与内部分录、在途跨行清算（含清算所场次与 RTGS 排队）、
+// 贷款及还款计划、直接借记授权、定时转账、卡授权、积分与返现账本、计息状态、通知中心、模拟时钟及日终进度
+type Snapshot struct {
+	Version            int                        `json:"version"`
+	Name               string                     `json:"name"`
+	CreatedAt          string                     `json:"createdAt"`   // 创建时的真实时间
+	SimTime            time.Time                  `json:"simTime"`     // 创建时的模拟时间
+	LastEODDate        string                     `json:"lastEodDate"` // 最近一次已完成日终的日期
+	Accounts           []model.Account            `json:"accounts"`
+	Holds              HoldSnapshot               `json:"holds"` // 冻结记录（与账户冻结金额一致）
+	Customers          []model.Customer           `json:"customers"`
+	Journal            repository.JournalSnapshot `json:"journal"`
+	Interbank          InterbankSnapshot          `json:"interbank"`
+	ClearingHouse      ClearingHouseSnapshot      `json:"clearingHouse"`
+	RTGS               RTGSSnapshot               `json:"rtgs"`
+	Loans              LoanSnapshot               `json:"loans"`
+	DirectDebits       DirectDebitSnapshot        `json:"directDebits"`
+	ScheduledTransfers ScheduledTransferSnapshot  `json:"scheduledTransfers"`
+	CardAuthorizations []CardAuthorization        `json:"cardAuthorizations"`
+	Loyalty            LoyaltySnapshot            `json:"loyalty"`
+	Cashback           CashbackSnapshot           `json:"cashback"`
+	Products           ProductSnapshot            `json:"products"` // 计提利息与产品绑定时间（产品参数属于配置，不在快照范围内）
+	Notifications      NotificationSnapshot       `json:"notifications"`
+	Periods            []PeriodClose              `json:"periods,omitempty"` // 已结账期间（恢复后重新锁定）
+}
+
+// 快照概要
+type SnapshotInfo struct {
+	Name         string `json:"name"`
+	CreatedAt    string `json:"createdAt"`
+	SimTime      string `json:"simTime"`
+	LastEODDate  string `json:"lastEodDate"`
+	Accounts     int    `json:"accounts"`
+	Transactions int    `json:"transactions"`
+	Size         int64  `json:"size"` // 文件大小（字节）
+}
+
+// 快照创建请求
+type SnapshotRequest struct {
+	Name string `json:"name"` // 为空时按模拟时间命名
+}
+
+// 状态快照服务：将模拟系统状态导出为 JSON 文件，并可回滚到任一快照，便于测试环境设置检查点
+type SnapshotService struct {
+	dir           string
+	accounts      *repository.AccountRepository
+	customerRepo  *repository.CustomerRepository
+	journal       *repository.JournalRepository
+	holds         *HoldService
+	interbank     *InterbankService
+	house         *ClearingHouseService
+	rtgs          *RTGSService
+	loans         *LoanService
+	directDebits  *DirectDebitService
+	scheduled     *ScheduledTransferService
+	cards         *CardService
+	loyalty       *LoyaltyService
+	cashback      *CashbackService
+	products      *ProductService
+	customers     *CustomerService
+	notifications *NotificationService
+	periods       *PeriodCloseService
+	eod           *EODService
+	clock         *sim.Clock
+}
+
+func NewSnapshotService(dir string, accounts *repository.AccountRepository, customerRepo *repository.CustomerRepository, journal *repository.JournalRepository,
+	holds *HoldService, interbank *InterbankService, house *ClearingHouseService, rtgs *RTGSService, loans *LoanService, directDebits *DirectDebitService,
+	scheduled *ScheduledTransferService, cards *CardService, loyalty *LoyaltyService, cashback *CashbackService, products *ProductService,
+	customers *CustomerService, notifications *NotificationService, periods *PeriodCloseService, eod *EODService, clock *sim.Clock) *SnapshotService {
+	return &SnapshotService{
+		dir:           dir,
+		accounts:      accounts,
+		customerRepo:  customerRepo,
+		journal:       journal,
+		holds:         holds,
+		interbank:     interbank,
+		house:         house,
+		rtgs:          rtgs,
+		loans:         loans,
+		directDebits:  directDebits,
+		scheduled:     scheduled,
+		cards:         cards,
+		loyalty:       loyalty,
+		cashback:      cashback,
+		products:      products,
+		customers:     customers,
+		notifications: notifications,
+		periods:       periods,
+		eod:           eod,
+		clock:         clock,
+	}
+}
+
+// 创建快照并写入快照目录（同名快照将被覆盖）
+func (s *SnapshotService) Create(req SnapshotRequest) (SnapshotInfo, error) {
+	now := s.clock.Now()
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "snapshot-" + now.Format("20060102-150405")
+	}
+	if !snapshotNamePattern.MatchString(name) {
+		return SnapshotInfo{}, model.NewError(model.CODE_PARAM_ERROR, "快照名称只能包含字母、数字、下划线与连字符（不超过 64 个字符）")
+	}
+
+	// 账户与各业务模块状态在同一读锁内导出，保证余额、冻结金额、在途清算、贷款与流水一致：
+	// 须先于账户锁获取的模块锁（冻结、跨行清算、贷款、直接借记）依次持有后再取账户锁，
+	// 其余为叶子锁的模块在账户锁内逐个导出
+	s.lockModules()
+	s.accounts.RLock()
+	snapshot := Snapshot{
+		Version:       SNAPSHOT_VERSION,
+		Name:          name,
+		CreatedAt:     time.Now().Format("2006-01-02 15:04:05"),
+		SimTime:       now,
+		LastEODDate:   s.eod.LastDate().Format("2006-01-02"),
+		Accounts:      make([]model.Account, 0),
+		Holds:         s.holds.snapshot(),
+		Customers:     s.customerRepo.List(),
+		Journal:       s.journal.Snapshot(),
+		Interbank:     s.interbank.snapshot(),
+		Loans:         s.loans.snapshot(),
+		DirectDebits:  s.directDebits.snapshot(),
+		Notifications: s.notifications.Snapshot(),
+		Periods:       s.periods.Snapshot(),
+	}
+	for _, id := range s.accounts.IDs() {
+		account, _ := s.accounts.Find(id)
+		snapshot.Accounts = append(snapshot.Accounts, account)
+	}
+	s.house.mu.Lock() // 清算所锁先于 RTGS 锁获取，待结算场次与净额结算分录取自同一时刻
+	snapshot.ClearingHouse = s.house.snapshot()
+	snapshot.RTGS = s.rtgs.Snapshot()
+	s.house.mu.Unlock()
+	snapshot.ScheduledTransfers = s.scheduled.Snapshot()
+	snapshot.CardAuthorizations = s.cards.Snapshot()
+	snapshot.Loyalty = s.loyalty.Snapshot()
+	snapshot.Cashback = s.cashback.Snapshot()
+	snapshot.Products = s.products.Snapshot()
+	s.accounts.RUnlock()
+	s.unlockModules()
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return SnapshotInfo{}, err
+	}
+	if err := os.WriteFile(s.path(name), data, 0o644); err != nil {
+		return SnapshotInfo{}, err
+	}
+	info := snapshotInfo(snapshot, int64(len(data)))
+
+	// 终端提示：创建状态快照
+	log.Println("\n[💾 创建状态快照]")
+	log.Printf("快照名称: %s", info.Name)
+	log.Printf("模拟时间: %s", info.SimTime)
+	log.Printf("账户: %d 个，流水: %d 笔，文件大小: %d 字节", info.Accounts, info.Transactions, info.Size)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return info, nil
+}
+
+// 快照目录中的全部快照（按创建时间倒序）
+func (s *SnapshotService) List() ([]SnapshotInfo, error) {
+	list := make([]SnapshotInfo, 0)
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if entry.IsDir() || name == entry.Name() || !snapshotNamePattern.MatchString(name) {
+			continue
+		}
+		snapshot, size, err := s.load(name)
+		if err != nil {
+			log.Printf("快照文件 %s 无法解析，已跳过: %v", entry.Name(), err)
+			continue
+		}
+		list = append(list, snapshotInfo(snapshot, size))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt > list[j].CreatedAt })
+	return list, nil
+}
+
+// 读取快照文件原文（供下载）
+func (s *SnapshotService) Export(name string) ([]byte, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "快照不存在")
+	}
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "快照不存在")
+	}
+	return data, err
+}
+
+// 导入快照文件（如从其他环境下载的快照），校验格式后以指定名称保存
+func (s *SnapshotService) Import(name string, data []byte) (SnapshotInfo, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return SnapshotInfo{}, model.NewError(model.CODE_PARAM_ERROR, "快照名称只能包含字母、数字、下划线与连字符（不超过 64 个字符）")
+	}
+	snapshot, err := decodeSnapshot(data)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	snapshot.Name = name
+	if data, err = json.MarshalIndent(snapshot, "", "  "); err != nil {
+		return SnapshotInfo{}, err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return SnapshotInfo{}, err
+	}
+	if err := os.WriteFile(s.path(name), data, 0o644); err != nil {
+		return SnapshotInfo{}, err
+	}
+	return snapshotInfo(snapshot, int64(len(data))), nil
+}
+
+// 回滚到指定快照：替换快照范围内的全部状态，模拟时钟与日终进度回到快照时刻。
+// 账户版本号在恢复前后保持递增，恢复前签发的 ETag 不会再次匹配；
+// 营销活动、产品参数、功能开关等配置类状态不在快照范围内，保持不变
+func (s *SnapshotService) Restore(name string) (SnapshotInfo, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return SnapshotInfo{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "快照不存在")
+	}
+	snapshot, size, err := s.load(name)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	lastEOD, err := time.ParseInLocation("2006-01-02", snapshot.LastEODDate, time.Local)
+	if err != nil {
+		return SnapshotInfo{}, model.NewError(model.CODE_PARAM_ERROR, "快照文件的日终日期格式错误")
+	}
+	before := s.clock.Now()
+
+	// 先回拨时钟与日终进度，避免日终调度按恢复前的时间补跑
+	s.clock.Set(snapshot.SimTime)
+	s.eod.SetLastDate(lastEOD)
+
+	s.lockModules()
+	s.accounts.Lock()
+	s.accounts.Replace(snapshot.Accounts)
+	s.holds.restore(snapshot.Holds)
+	s.interbank.restore(snapshot.Interbank)
+	s.loans.restore(snapshot.Loans)
+	s.directDebits.restore(snapshot.DirectDebits)
+	s.journal.Restore(snapshot.Journal)
+	s.customerRepo.Replace(snapshot.Customers)
+	s.house.mu.Lock()
+	s.house.restore(snapshot.ClearingHouse)
+	s.rtgs.Restore(snapshot.RTGS)
+	s.house.mu.Unlock()
+	s.scheduled.Restore(snapshot.ScheduledTransfers)
+	s.cards.Restore(snapshot.CardAuthorizations)
+	s.loyalty.Restore(snapshot.Loyalty)
+	s.cashback.Restore(snapshot.Cashback)
+	s.products.Restore(snapshot.Products)
+	s.accounts.Unlock()
+	s.unlockModules()
+	s.customers.syncSequence()
+	s.notifications.Restore(snapshot.Notifications)
+	s.periods.Restore(snapshot.Periods)
+
+	info := snapshotInfo(snapshot, size)
+
+	// 终端提示：恢复状态快照
+	log.Println("\n[⏪ 恢复状态快照]")
+	log.Printf("快照名称: %s（创建于 %s）", info.Name, info.CreatedAt)
+	log.Printf("模拟时间: %s → %s", before.Format("2006-01-02 15:04:05"), info.SimTime)
+	log.Printf("账户: %d 个，流水: %d 笔，最近日终: %s", info.Accounts, info.Transactions, info.LastEODDate)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return info, nil
+}
+
+// 删除快照文件
+func (s *SnapshotService) Delete(name string) error {
+	if !snapshotNamePattern.MatchString(name) {
+		return model.NewError(model.CODE_RESOURCE_NOT_FOUND, "快照不存在")
+	}
+	err := os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return model.NewError(model.CODE_RESOURCE_NOT_FOUND, "快照不存在")
+	}
+	return err
+}
+
+// 依次获取须先于账户锁获取的模块锁（冻结、跨行清算、贷款、直接借记），使快照期间这些模块不再变更
+func (s *SnapshotService) lockModules() {
+	s.holds.mu.Lock()
+	s.interbank.mu.Lock()
+	s.loans.mu.Lock()
+	s.directDebits.mu.Lock()
+}
+
+// 按获取的相反顺序释放模块锁
+func (s *SnapshotService) unlockModules() {
+	s.directDebits.mu.Unlock()
+	s.loans.mu.Unlock()
+	s.interbank.mu.Unlock()
+	s.holds.mu.Unlock()
+}
+
+// 快照文件路径
+func (s *SnapshotService) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+// 读取并解析快照文件，返回快照与文件大小
+func (s *SnapshotService) load(name string) (Snapshot, int64, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, 0, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "快照不存在")
+	}
+	if err != nil {
+		return Snapshot{}, 0, err
+	}
+	snapshot, err := decodeSnapshot(data)
+	return snapshot, int64(len(data)), err
+}
+
+// 解析快照内容并校验格式版本
+func decodeSnapshot(data []byte) (Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, model.NewError(model.CODE_PARAM_ERROR, "快照文件格式错误: "+err.Error())
+	}
+	if snapshot.Version != SNAPSHOT_VERSION {
+		return Snapshot{}, model.NewError(model.CODE_PARAM_ERROR, "不支持的快照格式版本")
+	}
+	return snapshot, nil
+}
+
+// 快照概要
+func snapshotInfo(snapshot Snapshot, size int64) SnapshotInfo {
+	return SnapshotInfo{
+		Name:         snapshot.Name,
+		CreatedAt:    snapshot.CreatedAt,
+		SimTime:      snapshot.SimTime.Format("2006-01-02 15:04:05"),
+		LastEODDate:  snapshot.LastEODDate,
+		Accounts:     len(snapshot.Accounts),
+		Transactions: len(snapshot.Journal.Transactions),
+		Size:         size,
+	}
+}
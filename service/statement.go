@@ -0,0 +1,160 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 账户日终对账单
+type Statement struct {
+	StatementID    string              `json:"statementId"`
+	AccountID      string              `json:"accountId"`
+	UserName       string              `json:"userName"`
+	Currency       string              `json:"currency"`
+	Date           string              `json:"date"`             // 对账日期 YYYY-MM-DD（月度对账单为月末日期）
+	Period         string              `json:"period,omitempty"` // 月度对账单所属月份 YYYY-MM（日终对账单为空）
+	SeqNo          int                 `json:"seqNo"`
+	OpeningBalance float64             `json:"openingBalance"`
+	ClosingBalance float64             `json:"closingBalance"`
+	CreditCount    int                 `json:"creditCount"`
+	CreditTotal    float64             `json:"creditTotal"`
+	DebitCount     int                 `json:"debitCount"`
+	DebitTotal     float64             `json:"debitTotal"`
+	GrossInterest  float64             `json:"grossInterest,omitempty"`  // 税前结息
+	WithholdingTax float64             `json:"withholdingTax,omitempty"` // 代扣利息税
+	NetInterest    float64             `json:"netInterest,omitempty"`    // 税后利息
+	Entries        []model.Transaction `json:"entries"`
+	GeneratedAt    string              `json:"generatedAt"`
+}
+
+// 对账单服务：日终生成、存档与导出（camt.053/MT940），订阅客户按月自动生成月度对账单并邮件送达
+type StatementService struct {
+	accounts *repository.AccountRepository
+	ledger   *LedgerService
+	calendar *CalendarService
+	clock    Clock
+	mailer   Mailer
+
+	statements    map[string][]Statement            // 账户ID → 按日期排序的对账单
+	monthly       map[string][]Statement            // 账户ID → 按月份排序的月度对账单
+	subscriptions map[string]*StatementSubscription // 账户ID → 月度对账单订阅
+	mu            sync.RWMutex
+}
+
+func NewStatementService(accounts *repository.AccountRepository, ledger *LedgerService, calendar *CalendarService, clock Clock, mailer Mailer) *StatementService {
+	return &StatementService{
+		accounts:      accounts,
+		ledger:        ledger,
+		calendar:      calendar,
+		clock:         clock,
+		mailer:        mailer,
+		statements:    make(map[string][]Statement),
+		monthly:       make(map[string][]Statement),
+		subscriptions: make(map[string]*StatementSubscription),
+	}
+}
+
+// 日终任务：为每个账户生成当日对账单
+func (s *StatementService) Generate(day time.Time) {
+	from, to := day, day.AddDate(0, 0, 1)
+
+	for _, account := range s.accounts.List() {
+		statement, ok := s.build(account, "ST"+account.AccountID+day.Format("20060102"), from, to)
+		if !ok {
+			continue
+		}
+		statement.Date = day.Format("2006-01-02")
+		s.archive(statement)
+
+		s.mailer.Notify(account, EVENT_STATEMENT_READY, map[string]interface{}{
+			"Date":           statement.Date,
+			"ClosingBalance": statement.ClosingBalance,
+		})
+	}
+}
+
+// 按 [from, to) 区间的流水汇总对账单（账户在区间起点前尚未开立时返回 false）
+func (s *StatementService) build(account model.Account, statementID string, from, to time.Time) (Statement, bool) {
+	id := account.AccountID
+	opening, ok := s.ledger.BalanceAt(id, from)
+	if !ok {
+		return Statement{}, false
+	}
+	closing, _ := s.ledger.BalanceAt(id, to)
+
+	statement := Statement{
+		StatementID:    statementID,
+		AccountID:      id,
+		UserName:       account.UserName,
+		Currency:       account.Currency,
+		OpeningBalance: model.RoundAmount(opening),
+		ClosingBalance: model.RoundAmount(closing),
+		Entries:        s.ledger.Transactions(id, from, to),
+		GeneratedAt:    s.clock.Now().Format("2006-01-02 15:04:05"),
+	}
+	for _, tx := range statement.Entries {
+		if tx.Direction == "credit" {
+			statement.CreditCount++
+			statement.CreditTotal += tx.Amount
+		} else {
+			statement.DebitCount++
+			statement.DebitTotal += tx.Amount
+		}
+		switch tx.Type {
+		case "interest":
+			statement.GrossInterest += tx.Amount
+		case "withholding_tax":
+			statement.WithholdingTax += tx.Amount
+		}
+	}
+	statement.NetInterest = model.RoundAmount(statement.GrossInterest - statement.WithholdingTax)
+	return statement, true
+}
+
+// 存档对账单（同一日期重复生成时覆盖）
+func (s *StatementService) archive(statement Statement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.statements[statement.AccountID]
+	replaced := false
+	for i := range list {
+		if list[i].Date == statement.Date {
+			statement.SeqNo = list[i].SeqNo
+			list[i] = statement
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		statement.SeqNo = len(list) + 1
+		list = append(list, statement)
+		sort.Slice(list, func(i, j int) bool { return list[i].Date < list[j].Date })
+	}
+	s.statements[statement.AccountID] = list
+}
+
+// 查询指定账户、日期的对账单
+func (s *StatementService) Find(accountID, date string) (Statement, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, statement := range s.statements[accountID] {
+		if statement.Date == date {
+			return statement, true
+		}
+	}
+	return Statement{}, false
+}
+
+// 账户的全部对账单（按日期排序）
+func (s *StatementService) List(accountID string) []Statement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Statement{}, s.statements[accountID]...)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/sim"
+)
+
+// 排行榜默认条数
+const defaultTopAccounts = 5
+
+// 账户总数（按状态）
+type AccountTotals struct {
+	Total   int `json:"total"`
+	Normal  int `json:"normal"`
+	Frozen  int `json:"frozen"`
+	Dormant int `json:"dormant"`
+}
+
+// 单一交易类型的笔数与金额（金额按币种分列）
+type TxTypeStat struct {
+	Type    string             `json:"type"`
+	Count   int                `json:"count"`
+	Volumes map[string]float64 `json:"volumes"` // 币种 → 交易金额合计
+}
+
+// 统计区间内的交易汇总
+type PeriodStats struct {
+	From   string       `json:"from"`
+	Count  int          `json:"count"`
+	ByType []TxTypeStat `json:"byType"`
+}
+
+// 账户活跃度
+type AccountActivity struct {
+	AccountID string `json:"accountId"`
+	UserName  string `json:"userName"`
+	Count     int    `json:"count"`
+}
+
+// 运营统计
+type ActivityStats struct {
+	GeneratedAt string            `json:"generatedAt"`
+	Accounts    AccountTotals     `json:"accounts"`
+	Today       PeriodStats       `json:"today"`
+	ThisWeek    PeriodStats       `json:"thisWeek"`
+	TopAccounts []AccountActivity `json:"topAccounts"` // 本周交易笔数最多的账户
+}
+
+// 统计服务：按模拟时钟汇总账户与交易数据，供运营看板使用
+type StatsService struct {
+	accounts *repository.AccountRepository
+	journal  *repository.JournalRepository
+	clock    Clock
+}
+
+func NewStatsService(accounts *repository.AccountRepository, journal *repository.JournalRepository, clock Clock) *StatsService {
+	return &StatsService{accounts: accounts, journal: journal, clock: clock}
+}
+
+// 汇总当前统计数据（开户余额不计入交易统计）
+func (s *StatsService) Activity() ActivityStats {
+	now := s.clock.Now()
+	today := sim.StartOfDay(now)
+	// 本周从周一零点开始
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	accounts := s.accounts.List()
+	stats := ActivityStats{GeneratedAt: now.Format("2006-01-02 15:04:05")}
+	names := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		names[account.AccountID] = account.UserName
+		stats.Accounts.Total++
+		switch account.Status {
+		case "normal":
+			stats.Accounts.Normal++
+		case ACCOUNT_STATUS_DORMANT:
+			stats.Accounts.Dormant++
+		default:
+			stats.Accounts.Frozen++
+		}
+	}
+
+	todayTypes := make(map[string]*TxTypeStat)
+	weekTypes := make(map[string]*TxTypeStat)
+	activity := make(map[string]int)
+	s.journal.View(func(transactions []model.Transaction, _ []model.LedgerEntry) {
+		for _, tx := range transactions {
+			if tx.Type == "opening" || tx.Time.Before(weekStart) {
+				continue
+			}
+			addTxTypeStat(weekTypes, tx)
+			activity[tx.AccountID]++
+			if !tx.Time.Before(today) {
+				addTxTypeStat(todayTypes, tx)
+			}
+		}
+	})
+
+	stats.Today = buildPeriodStats(today, todayTypes)
+	stats.ThisWeek = buildPeriodStats(weekStart, weekTypes)
+
+	stats.TopAccounts = make([]AccountActivity, 0, len(activity))
+	for accountID, count := range activity {
+		stats.TopAccounts = append(stats.TopAccounts, AccountActivity{AccountID: accountID, UserName: names[accountID], Count: count})
+	}
+	sort.Slice(stats.TopAccounts, func(i, j int) bool {
+		if stats.TopAccounts[i].Count != stats.TopAccounts[j].Count {
+			return stats.TopAccounts[i].Count > stats.TopAccounts[j].Count
+		}
+		return stats.TopAccounts[i].AccountID < stats.TopAccounts[j].AccountID
+	})
+	if len(stats.TopAccounts) > defaultTopAccounts {
+		stats.TopAccounts = stats.TopAccounts[:defaultTopAccounts]
+	}
+	return stats
+}
+
+// 累加一笔交易到按类型统计
+func addTxTypeStat(types map[string]*TxTypeStat, tx model.Transaction) {
+	stat, ok := types[tx.Type]
+	if !ok {
+		stat = &TxTypeStat{Type: tx.Type, Volumes: make(map[string]float64)}
+		types[tx.Type] = stat
+	}
+	stat.Count++
+	stat.Volumes[tx.Currency] = model.RoundAmount(stat.Volumes[tx.Currency] + tx.Amount)
+}
+
+// 生成区间汇总（按交易类型排序）
+func buildPeriodStats(from time.Time, types map[string]*TxTypeStat) PeriodStats {
+	period := PeriodStats{From: from.Format("2006-01-02"), ByType: make([]TxTypeStat, 0, len(types))}
+	for _, stat := range types {
+		period.Count += stat.Count
+		period.ByType = append(period.ByType, *stat)
+	}
+	sort.Slice(period.ByType, func(i, j int) bool { return period.ByType[i].Type < period.ByType[j].Type })
+	return period
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 客服会话状态
+const (
+	SUPPORT_CONVERSATION_OPEN   = "open"   // 进行中
+	SUPPORT_CONVERSATION_CLOSED = "closed" // 已结束
+)
+
+// 客服消息发送方
+const (
+	SUPPORT_SENDER_CUSTOMER = "customer"
+	SUPPORT_SENDER_AGENT    = "agent"
+)
+
+// 客服会话推送事件（管理员主题 admin.support）
+const (
+	SUPPORT_EVENT_MESSAGE = "message" // 新消息（客户发送或客服回复）
+	SUPPORT_EVENT_CLOSED  = "closed"  // 会话结束
+)
+
+const (
+	supportMessageMaxLength = 1000   // 单条消息最大字符数
+	supportDefaultAgent     = "在线客服" // 未填写客服名称时的显示名
+)
+
+// 客服消息
+type ChatMessage struct {
+	MessageID      string `json:"messageId"`
+	ConversationID string `json:"conversationId"`
+	Sender         string `json:"sender"`          // customer/agent
+	Agent          string `json:"agent,omitempty"` // 客服名称（客服回复）
+	Text           string `json:"text"`
+	SentAt         string `json:"sentAt"`
+}
+
+// 客服会话（列表中不含消息明细）
+type Conversation struct {
+	ConversationID   string        `json:"conversationId"`
+	AccountID        string        `json:"accountId"`
+	UserName         string        `json:"userName"`
+	Status           string        `json:"status"`          // open/closed
+	Agent            string        `json:"agent,omitempty"` // 最近回复的客服
+	CreatedAt        string        `json:"createdAt"`
+	UpdatedAt        string        `json:"updatedAt"`
+	ClosedAt         string        `json:"closedAt,omitempty"`
+	LastMessage      string        `json:"lastMessage"`
+	UnreadByAgent    int           `json:"unreadByAgent"`    // 客服未读的客户消息数
+	UnreadByCustomer int           `json:"unreadByCustomer"` // 客户未读的客服回复数
+	Messages         []ChatMessage `json:"messages,omitempty"`
+}
+
+// 客户发送消息请求
+type SupportChatRequest struct {
+	AccountID string `json:"accountId"`
+	Text      string `json:"text"`
+}
+
+// 客服回复请求
+type SupportReplyRequest struct {
+	Agent string `json:"agent"` // 客服名称，为空时显示为“在线客服”
+	Text  string `json:"text"`
+}
+
+// 结束会话请求
+type SupportCloseRequest struct {
+	Agent string `json:"agent"`
+}
+
+// 客服会话推送事件（管理员主题消息负载）
+type SupportChatEvent struct {
+	Event        string       `json:"event"` // message/closed
+	Conversation Conversation `json:"conversation"`
+	Message      *ChatMessage `json:"message,omitempty"`
+}
+
+// 过滤字段：会话所属账户（不涉及金额）
+func (e SupportChatEvent) FilterFields() (string, float64) {
+	return e.Conversation.AccountID, 0
+}
+
+// 在线客服服务：客户经 WebSocket 或接口发送消息，同一账户同时只有一个进行中的会话；
+// 管理员订阅 admin.support 主题维护会话队列并回复，回复推送给客户并存入通知中心
+type SupportChatService struct {
+	accounts  *repository.AccountRepository
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	mu            sync.Mutex
+	conversations map[string]*Conversation // 会话编号 → 会话（含消息明细）
+	open          map[string]string        // 账户ID → 进行中的会话编号
+	seq           int64
+	messageSeq    int64
+}
+
+func NewSupportChatService(accounts *repository.AccountRepository, clock Clock, notifier Notifier, templates *TemplateRegistry) *SupportChatService {
+	return &SupportChatService{
+		accounts:      accounts,
+		clock:         clock,
+		notifier:      notifier,
+		templates:     templates,
+		conversations: make(map[string]*Conversation),
+		open:          make(map[string]string),
+	}
+}
+
+// 客户发送消息：没有进行中的会话时开启新会话，消息推送到管理员客服主题
+func (s *SupportChatService) Send(req SupportChatRequest) (ChatMessage, error) {
+	text, err := supportText(req.Text)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	account, exists := s.accounts.Get(req.AccountID)
+	if !exists {
+		return ChatMessage{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	now := s.clock.Now()
+	conversation, ok := s.conversations[s.open[req.AccountID]]
+	if !ok {
+		s.seq++
+		conversation = &Conversation{
+			ConversationID: fmt.Sprintf("SC%s%06d", now.Format("20060102"), s.seq),
+			AccountID:      req.AccountID,
+			UserName:       account.UserName,
+			Status:         SUPPORT_CONVERSATION_OPEN,
+			CreatedAt:      now.Format("2006-01-02 15:04:05"),
+		}
+		s.conversations[conversation.ConversationID] = conversation
+		s.open[req.AccountID] = conversation.ConversationID
+	}
+	message := s.addMessage(conversation, SUPPORT_SENDER_CUSTOMER, "", text)
+	conversation.UnreadByAgent++
+	summary := conversation.summary()
+	s.mu.Unlock()
+
+	// 终端提示：客户消息
+	log.Println("\n[💬 在线客服 - 客户消息]")
+	log.Printf("会话编号: %s", summary.ConversationID)
+	log.Printf("账户: %s（%s）", summary.AccountID, summary.UserName)
+	log.Printf("消息内容: %s", text)
+	log.Printf("客服未读: %d", summary.UnreadByAgent)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.notifier.Publish(ws.TOPIC_ADMIN_SUPPORT, "supportChat", SupportChatEvent{Event: SUPPORT_EVENT_MESSAGE, Conversation: summary, Message: &message})
+	return message, nil
+}
+
+// 客服回复（管理员）：回复推送给客户并存入通知中心，同时推送到管理员客服主题供其他客服同步
+func (s *SupportChatService) Reply(conversationID string, req SupportReplyRequest) (ChatMessage, error) {
+	text, err := supportText(req.Text)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	agent := supportAgent(req.Agent)
+
+	s.mu.Lock()
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		s.mu.Unlock()
+		return ChatMessage{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "客服会话不存在")
+	}
+	if conversation.Status != SUPPORT_CONVERSATION_OPEN {
+		s.mu.Unlock()
+		return ChatMessage{}, model.NewError(model.CODE_PARAM_ERROR, "客服会话已结束")
+	}
+	message := s.addMessage(conversation, SUPPORT_SENDER_AGENT, agent, text)
+	conversation.Agent = agent
+	conversation.UnreadByAgent = 0 // 回复视为已读客户消息
+	conversation.UnreadByCustomer++
+	summary := conversation.summary()
+	s.mu.Unlock()
+
+	// 终端提示：客服回复
+	log.Println("\n[💬 在线客服 - 客服回复]")
+	log.Printf("会话编号: %s", summary.ConversationID)
+	log.Printf("账户: %s（%s）", summary.AccountID, summary.UserName)
+	log.Printf("客服: %s", agent)
+	log.Printf("消息内容: %s", text)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.notifyCustomer(summary.AccountID, EVENT_SUPPORT_REPLY, map[string]interface{}{
+		"ConversationID": summary.ConversationID,
+		"Agent":          agent,
+		"Text":           text,
+	}, message)
+	s.notifier.Publish(ws.TOPIC_ADMIN_SUPPORT, "supportChat", SupportChatEvent{Event: SUPPORT_EVENT_MESSAGE, Conversation: summary, Message: &message})
+	return message, nil
+}
+
+// 结束会话（管理员）：客户再次发送消息时开启新会话
+func (s *SupportChatService) Close(conversationID string, req SupportCloseRequest) (Conversation, error) {
+	agent := supportAgent(req.Agent)
+
+	s.mu.Lock()
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		s.mu.Unlock()
+		return Conversation{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "客服会话不存在")
+	}
+	if conversation.Status != SUPPORT_CONVERSATION_OPEN {
+		s.mu.Unlock()
+		return Conversation{}, model.NewError(model.CODE_PARAM_ERROR, "客服会话已结束")
+	}
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+	conversation.Status = SUPPORT_CONVERSATION_CLOSED
+	conversation.Agent = agent
+	conversation.ClosedAt = now
+	conversation.UpdatedAt = now
+	delete(s.open, conversation.AccountID)
+	summary := conversation.summary()
+	s.mu.Unlock()
+
+	// 终端提示：会话结束
+	log.Println("\n[💬 在线客服 - 会话结束]")
+	log.Printf("会话编号: %s", summary.ConversationID)
+	log.Printf("账户: %s（%s）", summary.AccountID, summary.UserName)
+	log.Printf("结束客服: %s", agent)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	s.notifyCustomer(summary.AccountID, EVENT_SUPPORT_CLOSED, map[string]interface{}{
+		"ConversationID": summary.ConversationID,
+		"Agent":          agent,
+	}, summary)
+	s.notifier.Publish(ws.TOPIC_ADMIN_SUPPORT, "supportChat", SupportChatEvent{Event: SUPPORT_EVENT_CLOSED, Conversation: summary})
+	return summary, nil
+}
+
+// 客户的客服会话（含消息明细，按开启时间倒序），查询后客服回复视为已读
+func (s *SupportChatService) Conversations(accountID string) ([]Conversation, error) {
+	if _, exists := s.accounts.Get(accountID); !exists {
+		return nil, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "账户不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Conversation{}
+	for _, conversation := range s.conversations {
+		if conversation.AccountID != accountID {
+			continue
+		}
+		conversation.UnreadByCustomer = 0
+		result = append(result, conversation.detail())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ConversationID > result[j].ConversationID })
+	return result, nil
+}
+
+// 客服会话队列（管理员，不含消息明细）：进行中的排在前面，其中有未读客户消息的优先、等待最久的优先；
+// status 为空时返回全部
+func (s *SupportChatService) Queue(status string) []Conversation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := []Conversation{}
+	for _, conversation := range s.conversations {
+		if status != "" && conversation.Status != status {
+			continue
+		}
+		result = append(result, conversation.summary())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Status != b.Status {
+			return a.Status == SUPPORT_CONVERSATION_OPEN
+		}
+		if a.Status == SUPPORT_CONVERSATION_CLOSED {
+			return a.UpdatedAt > b.UpdatedAt
+		}
+		if (a.UnreadByAgent > 0) != (b.UnreadByAgent > 0) {
+			return a.UnreadByAgent > 0
+		}
+		if a.UpdatedAt != b.UpdatedAt {
+			return a.UpdatedAt < b.UpdatedAt
+		}
+		return a.ConversationID < b.ConversationID
+	})
+	return result
+}
+
+// 查询会话及消息明细（管理员），查询后客户消息视为已读
+func (s *SupportChatService) Conversation(conversationID string) (Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversation, ok := s.conversations[conversationID]
+	if !ok {
+		return Conversation{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "客服会话不存在")
+	}
+	conversation.UnreadByAgent = 0
+	return conversation.detail(), nil
+}
+
+// 追加一条消息并更新会话摘要（调用方需持有 s.mu）
+func (s *SupportChatService) addMessage(conversation *Conversation, sender, agent, text string) ChatMessage {
+	now := s.clock.Now()
+	s.messageSeq++
+	message := ChatMessage{
+		MessageID:      fmt.Sprintf("SM%s%08d", now.Format("20060102"), s.messageSeq),
+		ConversationID: conversation.ConversationID,
+		Sender:         sender,
+		Agent:          agent,
+		Text:           text,
+		SentAt:         now.Format("2006-01-02 15:04:05"),
+	}
+	conversation.Messages = append(conversation.Messages, message)
+	conversation.LastMessage = text
+	conversation.UpdatedAt = message.SentAt
+	return message
+}
+
+// 推送给客户并存入通知中心（supportChat 类型计入未读数）
+func (s *SupportChatService) notifyCustomer(accountID, event string, vars map[string]interface{}, data interface{}) {
+	account, exists := s.accounts.Get(accountID)
+	if !exists {
+		return
+	}
+	msg := s.templates.Alert("supportChat", account, event, vars)
+	msg.Data = data
+	s.notifier.Send(msg)
+}
+
+// 会话摘要（不含消息明细）
+func (c *Conversation) summary() Conversation {
+	summary := *c
+	summary.Messages = nil
+	return summary
+}
+
+// 会话副本（含消息明细）
+func (c *Conversation) detail() Conversation {
+	detail := *c
+	detail.Messages = append([]ChatMessage{}, c.Messages...)
+	return detail
+}
+
+// 校验消息内容：去除首尾空白后不能为空，且不超过 1000 字
+func supportText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", model.NewError(model.CODE_PARAM_ERROR, "消息内容不能为空")
+	}
+	if utf8.RuneCountInString(text) > supportMessageMaxLength {
+		return "", model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("消息内容不能超过 %d 字", supportMessageMaxLength))
+	}
+	return text, nil
+}
+
+// 客服显示名
+func supportAgent(agent string) string {
+	if agent = strings.TrimSpace(agent); agent == "" {
+		return supportDefaultAgent
+	}
+	return agent
+}
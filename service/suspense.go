@@ -0,0 +1,352 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 挂账状态
+const (
+	SUSPENSE_OPEN     = "open"     // 待处理
+	SUSPENSE_REPAIRED = "repaired" // 已查明收款人并入账
+	SUSPENSE_RETURNED = "returned" // 已退回汇出行
+)
+
+// 跨行汇入请求（模拟他行发来的贷记）
+type IncomingCreditRequest struct {
+	FromBank    string  `json:"fromBank"` // 汇出行 BIC
+	FromAccount string  `json:"fromAccount"`
+	FromName    string  `json:"fromName"`
+	ToAccount   string  `json:"toAccount" validate:"required"`
+	ToName      string  `json:"toName"` // 收款人户名（填写时与账户户名核对）
+	Amount      float64 `json:"amount" validate:"amount"`
+
+	model.Remittance
+}
+
+// 跨行汇入结果：入账或转入待处理挂账
+type IncomingCredit struct {
+	Reference    string        `json:"reference"`
+	Status       string        `json:"status"` // credited/suspense
+	AccountID    string        `json:"accountId,omitempty"`
+	Amount       float64       `json:"amount"`
+	BalanceAfter float64       `json:"balanceAfter,omitempty"`
+	Suspense     *SuspenseItem `json:"suspense,omitempty"`
+}
+
+// 待处理挂账：无法入账的汇入款项，待人工查明收款人后入账或退回汇出行
+type SuspenseItem struct {
+	ItemID       string  `json:"itemId"`
+	Reference    string  `json:"reference"` // 汇入流水号
+	FromBank     string  `json:"fromBank"`
+	FromBankName string  `json:"fromBankName"`
+	FromAccount  string  `json:"fromAccount"`
+	FromName     string  `json:"fromName"`
+	ToAccount    string  `json:"toAccount"` // 汇款人填写的收款账号
+	ToName       string  `json:"toName"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	ReasonCode   string  `json:"reasonCode"` // 无法入账原因码（ISO 20022）
+	Reason       string  `json:"reason"`
+	Status       string  `json:"status"` // open/repaired/returned
+	ReceivedAt   string  `json:"receivedAt"`
+	ResolvedAt   string  `json:"resolvedAt,omitempty"`
+	ResolvedBy   string  `json:"resolvedBy,omitempty"`
+	CreditedTo   string  `json:"creditedTo,omitempty"` // 人工入账的账户
+	Note         string  `json:"note,omitempty"`
+
+	model.Remittance
+}
+
+// 挂账入账请求
+type SuspenseRepairRequest struct {
+	AccountID string `json:"accountId"`
+	Note      string `json:"note"`
+	Operator  string `json:"operator"`
+}
+
+// 挂账退回请求
+type SuspenseReturnRequest struct {
+	Note     string `json:"note"`
+	Operator string `json:"operator"`
+}
+
+// 待处理挂账服务：受理他行汇入款项，收款账户无法入账时转入待处理挂账科目，
+// 由管理员在工作队列中查明收款人后入账，或退回汇出行
+type SuspenseService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	audit     *AuditService
+	clock     Clock
+	notifier  Notifier
+	templates *TemplateRegistry
+	rtgs      *RTGSService
+
+	mu    sync.Mutex // 需先于账户锁获取
+	items map[string]*SuspenseItem
+	seq   int64
+}
+
+func NewSuspenseService(accounts *repository.AccountRepository, ledger *LedgerService, audit *AuditService, clock Clock, notifier Notifier, templates *TemplateRegistry, rtgs *RTGSService) *SuspenseService {
+	return &SuspenseService{
+		accounts:  accounts,
+		ledger:    ledger,
+		audit:     audit,
+		clock:     clock,
+		notifier:  notifier,
+		templates: templates,
+		rtgs:      rtgs,
+		items:     make(map[string]*SuspenseItem),
+	}
+}
+
+// 受理一笔跨行汇入（人民币）：收款账户正常且户名相符时直接入账，否则转入待处理挂账
+func (s *SuspenseService) Receive(req IncomingCreditRequest) (IncomingCredit, error) {
+	if err := model.Validate(req); err != nil {
+		return IncomingCredit{}, err
+	}
+	req.Amount = model.RoundAmount(req.Amount)
+	bankName, ok := externalBanks[req.FromBank]
+	if !ok {
+		return IncomingCredit{}, model.NewError(model.CODE_PARAM_ERROR, "汇出行不存在")
+	}
+	if err := validateRemittance(&req.Remittance); err != nil {
+		return IncomingCredit{}, err
+	}
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	// 汇出行经央行 RTGS 划入本行备付金，汇出行头寸不足时不受理
+	reference := fmt.Sprintf("IN%s%06d", now.Format("20060102"), s.seq+1)
+	if _, err := s.rtgs.Settle(RTGSInstruction{Reference: reference, Debtor: req.FromBank, Creditor: model.BANK_BIC, Amount: req.Amount}, false); err != nil {
+		return IncomingCredit{}, err
+	}
+	s.seq++
+	account, exists := s.accounts.Find(req.ToAccount)
+	code, reason := "", ""
+	switch {
+	case !exists:
+		code, reason = "AC01", "收款账号不存在"
+	case account.Currency != model.BASE_CURRENCY:
+		code, reason = "AM03", "收款账户币种不符"
+	case account.Status == "frozen":
+		code, reason = "AC06", "收款账户已冻结"
+	case account.Status != "normal":
+		code, reason = "AC06", "收款账户状态异常，暂不能入账"
+	case req.ToName != "" && matchPayeeName(req.ToName, account.UserName) == PAYEE_NO_MATCH:
+		code, reason = "BE01", "收款人户名与账号不符"
+	}
+
+	if code != "" {
+		item := &SuspenseItem{
+			ItemID:       fmt.Sprintf("SP%s%06d", now.Format("20060102"), s.seq),
+			Reference:    reference,
+			FromBank:     req.FromBank,
+			FromBankName: bankName,
+			FromAccount:  req.FromAccount,
+			FromName:     req.FromName,
+			ToAccount:    req.ToAccount,
+			ToName:       req.ToName,
+			Amount:       req.Amount,
+			Currency:     model.BASE_CURRENCY,
+			ReasonCode:   code,
+			Reason:       reason,
+			Status:       SUSPENSE_OPEN,
+			ReceivedAt:   now.Format("2006-01-02 15:04:05"),
+			Remittance:   req.Remittance,
+		}
+		s.items[item.ItemID] = item
+		// 款项已到央行备付金，暂记待处理挂账
+		s.ledger.PostInternal(GL_CENTRAL_BANK, GL_SUSPENSE, item.Amount, item.Currency, reference)
+
+		// 终端提示：汇入款项转入挂账
+		log.Println("\n[📥 跨行汇入 - 转入挂账]")
+		log.Printf("流水号: %s，挂账编号: %s", reference, item.ItemID)
+		log.Printf("汇款人: %s（%s %s）", item.FromName, item.FromBankName, item.FromAccount)
+		log.Printf("收款账号: %s %s", item.ToAccount, item.ToName)
+		log.Printf("金额: %.2f 元，原因: \033[1;33m%s %s\033[0m", item.Amount, item.ReasonCode, item.Reason)
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+
+		copied := *item
+		return IncomingCredit{Reference: reference, Status: "suspense", Amount: item.Amount, Suspense: &copied}, nil
+	}
+
+	account = s.credit(account, req.Amount, "interbank_in", reference, req.FromAccount,
+		fmt.Sprintf("跨行汇入：%s（%s）", req.FromName, bankName), req.Remittance)
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_INTERBANK_RECEIVED, map[string]interface{}{
+		"Amount":    req.Amount,
+		"Balance":   account.Balance,
+		"Payer":     req.FromName,
+		"BankName":  bankName,
+		"Reference": reference,
+		"Memo":      req.Memo,
+	}))
+	log.Printf("[📥 跨行汇入] 流水号: %s | %s → %s | 金额: %.2f 元", reference, bankName, account.AccountID, req.Amount)
+
+	return IncomingCredit{Reference: reference, Status: "credited", AccountID: account.AccountID, Amount: req.Amount, BalanceAfter: account.Balance}, nil
+}
+
+// 挂账工作队列（status 为空表示全部，按接收时间排序）
+func (s *SuspenseService) List(status string) []SuspenseItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]SuspenseItem, 0)
+	for _, item := range s.items {
+		if status == "" || item.Status == status {
+			result = append(result, *item)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ItemID < result[j].ItemID })
+	return result
+}
+
+// 查明收款人后入账：从待处理挂账转入指定账户，记录审计日志
+func (s *SuspenseService) Repair(itemID string, req SuspenseRepairRequest) (SuspenseItem, error) {
+	if req.Operator == "" {
+		req.Operator = AUDIT_ACTOR_ADMIN
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	item, err := s.open(itemID)
+	if err != nil {
+		return SuspenseItem{}, err
+	}
+	account, exists := s.accounts.Find(req.AccountID)
+	if !exists {
+		return SuspenseItem{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "入账账户不存在")
+	}
+	if account.Currency != item.Currency {
+		return SuspenseItem{}, model.NewError(model.CODE_PARAM_ERROR, "入账账户币种与挂账款项不符")
+	}
+	if account.Status != "normal" {
+		return SuspenseItem{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "入账账户状态异常，不能入账")
+	}
+
+	account = s.credit(account, item.Amount, "suspense_release", item.Reference, item.FromAccount,
+		fmt.Sprintf("跨行汇入（挂账 %s 查明入账）：%s（%s）", item.ItemID, item.FromName, item.FromBankName), item.Remittance)
+	s.resolve(item, SUSPENSE_REPAIRED, req.Operator, req.Note)
+	item.CreditedTo = account.AccountID
+	s.audit.Record(req.Operator, "suspense.repaired", account.AccountID, map[string]string{
+		"itemId":    item.ItemID,
+		"reference": item.Reference,
+		"amount":    fmt.Sprintf("%.2f", item.Amount),
+		"toAccount": item.ToAccount,
+		"note":      item.Note,
+	})
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, EVENT_INTERBANK_RECEIVED, map[string]interface{}{
+		"Amount":    item.Amount,
+		"Balance":   account.Balance,
+		"Payer":     item.FromName,
+		"BankName":  item.FromBankName,
+		"Reference": item.Reference,
+		"Memo":      item.Memo,
+	}))
+	logSuspense("✅ 挂账查明入账", *item)
+	return *item, nil
+}
+
+// 无法查明收款人时退回汇出行，记录审计日志
+func (s *SuspenseService) Return(itemID string, req SuspenseReturnRequest) (SuspenseItem, error) {
+	if req.Operator == "" {
+		req.Operator = AUDIT_ACTOR_ADMIN
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.open(itemID)
+	if err != nil {
+		return SuspenseItem{}, err
+	}
+	// 挂账款项经央行 RTGS 退回汇出行（借待处理挂账、贷存放央行款项）
+	if _, err := s.rtgs.Settle(RTGSInstruction{
+		Reference: item.Reference,
+		Debtor:    model.BANK_BIC,
+		Creditor:  item.FromBank,
+		Amount:    item.Amount,
+		Contra:    GL_SUSPENSE,
+	}, false); err != nil {
+		return SuspenseItem{}, err
+	}
+	s.resolve(item, SUSPENSE_RETURNED, req.Operator, req.Note)
+	s.audit.Record(req.Operator, "suspense.returned", item.ToAccount, map[string]string{
+		"itemId":    item.ItemID,
+		"reference": item.Reference,
+		"amount":    fmt.Sprintf("%.2f", item.Amount),
+		"fromBank":  item.FromBank,
+		"note":      item.Note,
+	})
+	logSuspense("↩️ 挂账退回汇出行", *item)
+	return *item, nil
+}
+
+// 待处理的挂账（调用方需持有 s.mu）
+func (s *SuspenseService) open(itemID string) (*SuspenseItem, error) {
+	item, ok := s.items[itemID]
+	if !ok {
+		return nil, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "挂账记录不存在")
+	}
+	if item.Status != SUSPENSE_OPEN {
+		return nil, model.NewError(model.CODE_PARAM_ERROR, "挂账已处理")
+	}
+	return item, nil
+}
+
+// 标记挂账已处理（调用方需持有 s.mu）
+func (s *SuspenseService) resolve(item *SuspenseItem, status, operator, note string) {
+	item.Status = status
+	item.ResolvedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	item.ResolvedBy = operator
+	item.Note = strings.TrimSpace(note)
+}
+
+// 入账并推送余额（调用方需持有账户写锁）
+func (s *SuspenseService) credit(account model.Account, amount float64, txType, reference, counterparty, description string, remittance model.Remittance) model.Account {
+	account.Balance = model.RoundAmount(account.Balance + amount)
+	account = s.accounts.Save(account)
+	s.ledger.Record(model.Transaction{
+		AccountID:    account.AccountID,
+		Type:         txType,
+		Direction:    "credit",
+		Amount:       amount,
+		BalanceAfter: account.Balance,
+		Counterparty: counterparty,
+		Reference:    reference,
+		Description:  description,
+		Remittance:   remittance,
+	})
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+	return account
+}
+
+// 终端提示：挂账处理
+func logSuspense(title string, item SuspenseItem) {
+	log.Printf("\n[%s]", title)
+	log.Printf("挂账编号: %s（流水号 %s）", item.ItemID, item.Reference)
+	log.Printf("汇款人: %s（%s），金额: %.2f 元", item.FromName, item.FromBankName, item.Amount)
+	if item.CreditedTo != "" {
+		log.Printf("入账账户: %s", item.CreditedTo)
+	}
+	log.Printf("处理人: %s，备注: %s", item.ResolvedBy, item.Note)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 税务配置
+type TaxConfig struct {
+	WithholdingRate float64 // 利息代扣税税率（如 0.2 表示 20%），0 表示不代扣
+}
+
+// 代扣税汇总（按币种或按账户）
+type WithholdingTaxTotal struct {
+	AccountID     string  `json:"accountId,omitempty"`
+	UserName      string  `json:"userName,omitempty"`
+	Currency      string  `json:"currency"`
+	Count         int     `json:"count"` // 结息笔数
+	GrossInterest float64 `json:"grossInterest"`
+	Tax           float64 `json:"tax"`
+	NetInterest   float64 `json:"netInterest"`
+}
+
+// 利息代扣税报告
+type WithholdingTaxReport struct {
+	From     string                `json:"from,omitempty"`
+	To       string                `json:"to,omitempty"`
+	Rate     float64               `json:"rate"`
+	Totals   []WithholdingTaxTotal `json:"totals"`   // 按币种汇总
+	Accounts []WithholdingTaxTotal `json:"accounts"` // 按账户汇总
+}
+
+// 税务服务：结息时计算利息代扣税，并基于交易流水汇总累计代扣税额
+type TaxService struct {
+	cfg      TaxConfig
+	accounts *repository.AccountRepository
+	journal  *repository.JournalRepository
+}
+
+func NewTaxService(cfg TaxConfig, accounts *repository.AccountRepository, journal *repository.JournalRepository) *TaxService {
+	return &TaxService{
+		cfg:      cfg,
+		accounts: accounts,
+		journal:  journal,
+	}
+}
+
+// 代扣税税率
+func (s *TaxService) WithholdingRate() float64 {
+	return s.cfg.WithholdingRate
+}
+
+// 计算一笔利息应代扣的税额
+func (s *TaxService) Withhold(interest float64) float64 {
+	if s.cfg.WithholdingRate <= 0 || interest <= 0 {
+		return 0
+	}
+	return model.RoundAmount(interest * s.cfg.WithholdingRate)
+}
+
+// 汇总 [from, to) 区间的结息与代扣税（零值表示不限）
+func (s *TaxService) Report(from, to time.Time) WithholdingTaxReport {
+	report := WithholdingTaxReport{Rate: s.cfg.WithholdingRate}
+	if !from.IsZero() {
+		report.From = from.Format("2006-01-02")
+	}
+	if !to.IsZero() {
+		report.To = to.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	byCurrency := make(map[string]*WithholdingTaxTotal)
+	byAccount := make(map[string]*WithholdingTaxTotal)
+	s.journal.View(func(transactions []model.Transaction, _ []model.LedgerEntry) {
+		for _, tx := range transactions {
+			if tx.Type != "interest" && tx.Type != "withholding_tax" {
+				continue
+			}
+			if (!from.IsZero() && tx.Time.Before(from)) || (!to.IsZero() && !tx.Time.Before(to)) {
+				continue
+			}
+			if byCurrency[tx.Currency] == nil {
+				byCurrency[tx.Currency] = &WithholdingTaxTotal{Currency: tx.Currency}
+			}
+			if byAccount[tx.AccountID] == nil {
+				byAccount[tx.AccountID] = &WithholdingTaxTotal{AccountID: tx.AccountID, Currency: tx.Currency}
+			}
+			for _, total := range []*WithholdingTaxTotal{byCurrency[tx.Currency], byAccount[tx.AccountID]} {
+				if tx.Type == "interest" {
+					total.Count++
+					total.GrossInterest += tx.Amount
+				} else {
+					total.Tax += tx.Amount
+				}
+			}
+		}
+	})
+
+	report.Totals = withholdingTotals(byCurrency)
+	report.Accounts = withholdingTotals(byAccount)
+	for i := range report.Accounts {
+		if account, exists := s.accounts.Get(report.Accounts[i].AccountID); exists {
+			report.Accounts[i].UserName = account.UserName
+		}
+	}
+	return report
+}
+
+// 舍入并计算税后利息（按币种、账户ID排序）
+func withholdingTotals(totals map[string]*WithholdingTaxTotal) []WithholdingTaxTotal {
+	result := []WithholdingTaxTotal{}
+	for _, total := range totals {
+		total.GrossInterest = model.RoundAmount(total.GrossInterest)
+		total.Tax = model.RoundAmount(total.Tax)
+		total.NetInterest = model.RoundAmount(total.GrossInterest - total.Tax)
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Currency != result[j].Currency {
+			return result[i].Currency < result[j].Currency
+		}
+		return result[i].AccountID < result[j].AccountID
+	})
+	return result
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 网点信息结构体
+type Branch struct {
+	BranchID string `json:"branchId"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+}
+
+// 网点及其柜员
+type BranchDetail struct {
+	Branch
+	Tellers []Teller `json:"tellers"`
+}
+
+// 柜员信息结构体
+type Teller struct {
+	TellerID string `json:"tellerId"`
+	Name     string `json:"name"`
+	BranchID string `json:"branchId"`
+}
+
+// 钱箱流水（柜员代客存取现金）
+type DrawerOperation struct {
+	Type         string  `json:"type"` // deposit/withdraw
+	AccountID    string  `json:"accountId"`
+	Amount       float64 `json:"amount"`
+	DrawerAfter  float64 `json:"drawerAfter"`
+	AccountAfter float64 `json:"accountAfter"`
+	Time         string  `json:"time"`
+}
+
+// 柜员钱箱（一个班次）
+type CashDrawer struct {
+	TellerID    string            `json:"tellerId"`
+	BranchID    string            `json:"branchId"`
+	Status      string            `json:"status"` // open/closed
+	OpeningCash float64           `json:"openingCash"`
+	Balance     float64           `json:"balance"`
+	OpenedAt    string            `json:"openedAt"`
+	ClosedAt    string            `json:"closedAt,omitempty"`
+	Operations  []DrawerOperation `json:"operations"`
+}
+
+// 日终轧账报告
+type ShiftReport struct {
+	TellerID        string  `json:"tellerId"`
+	TellerName      string  `json:"tellerName"`
+	BranchID        string  `json:"branchId"`
+	BranchName      string  `json:"branchName"`
+	OpenedAt        string  `json:"openedAt"`
+	ClosedAt        string  `json:"closedAt,omitempty"`
+	OpeningCash     float64 `json:"openingCash"`
+	DepositCount    int     `json:"depositCount"`
+	DepositTotal    float64 `json:"depositTotal"`
+	WithdrawCount   int     `json:"withdrawCount"`
+	WithdrawTotal   float64 `json:"withdrawTotal"`
+	ExpectedCash    float64 `json:"expectedCash"`
+	CountedCash     float64 `json:"countedCash"`
+	Difference      float64 `json:"difference"`
+	ReconcileResult string  `json:"reconcileResult"` // balanced/over/short/pending
+}
+
+// 钱箱开箱请求结构体
+type DrawerOpenRequest struct {
+	TellerID    string  `json:"tellerId" validate:"required"`
+	OpeningCash float64 `json:"openingCash" validate:"min=0"`
+}
+
+// 钱箱封箱请求结构体
+type DrawerCloseRequest struct {
+	TellerID    string  `json:"tellerId" validate:"required"`
+	CountedCash float64 `json:"countedCash" validate:"min=0"` // 柜员实点现金
+}
+
+// 柜员代客存取款请求结构体
+type TellerCashRequest struct {
+	TellerID  string  `json:"tellerId" validate:"required"`
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"amount"`
+}
+
+// 模拟数据库 - 网点与柜员
+var (
+	branches = map[string]Branch{
+		"BR001": {BranchID: "BR001", Name: "总行营业部", Address: "金融街1号"},
+		"BR002": {BranchID: "BR002", Name: "城东支行", Address: "东大街88号"},
+	}
+	tellers = map[string]Teller{
+		"T001": {TellerID: "T001", Name: "王五", BranchID: "BR001"},
+		"T002": {TellerID: "T002", Name: "赵六", BranchID: "BR002"},
+	}
+)
+
+// 柜员服务：钱箱开封箱、代客现金存取款与轧账
+type TellerService struct {
+	accounts  *repository.AccountRepository
+	ledger    *LedgerService
+	promos    *PromoService
+	notifier  Notifier
+	templates *TemplateRegistry
+
+	drawers      map[string]*CashDrawer // 柜员当前（或最近一次）班次的钱箱
+	shiftReports map[string]ShiftReport // 最近一次封箱的轧账报告
+	mu           sync.Mutex             // 钱箱操作互斥锁（需先于账户锁获取）
+}
+
+func NewTellerService(accounts *repository.AccountRepository, ledger *LedgerService, promos *PromoService, notifier Notifier, templates *TemplateRegistry) *TellerService {
+	return &TellerService{
+		accounts:     accounts,
+		ledger:       ledger,
+		promos:       promos,
+		notifier:     notifier,
+		templates:    templates,
+		drawers:      make(map[string]*CashDrawer),
+		shiftReports: make(map[string]ShiftReport),
+	}
+}
+
+// 网点及柜员列表
+func (s *TellerService) Branches() []BranchDetail {
+	result := make([]BranchDetail, 0, len(branches))
+	for _, branch := range branches {
+		branchTellers := make([]Teller, 0)
+		for _, teller := range tellers {
+			if teller.BranchID == branch.BranchID {
+				branchTellers = append(branchTellers, teller)
+			}
+		}
+		result = append(result, BranchDetail{Branch: branch, Tellers: branchTellers})
+	}
+	return result
+}
+
+// 柜员开箱（开始班次）
+func (s *TellerService) OpenDrawer(req DrawerOpenRequest) (CashDrawer, error) {
+	if err := model.Validate(req); err != nil {
+		return CashDrawer{}, err
+	}
+
+	teller, exists := tellers[req.TellerID]
+	if !exists {
+		return CashDrawer{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "柜员不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if drawer, ok := s.drawers[req.TellerID]; ok && drawer.Status == "open" {
+		return CashDrawer{}, model.NewError(model.CODE_DRAWER_STATE_ERROR, "钱箱已处于开箱状态")
+	}
+
+	drawer := &CashDrawer{
+		TellerID:    teller.TellerID,
+		BranchID:    teller.BranchID,
+		Status:      "open",
+		OpeningCash: req.OpeningCash,
+		Balance:     req.OpeningCash,
+		OpenedAt:    time.Now().Format("2006-01-02 15:04:05"),
+		Operations:  make([]DrawerOperation, 0),
+	}
+	s.drawers[req.TellerID] = drawer
+
+	// 终端提示：柜员开箱
+	log.Println("\n[🏦 柜员开箱]")
+	log.Printf("开箱时间: %s", drawer.OpenedAt)
+	log.Printf("网点: %s", branches[teller.BranchID].Name)
+	log.Printf("柜员: %s (%s)", teller.Name, teller.TellerID)
+	log.Printf("备用金: %.2f 元", drawer.OpeningCash)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *drawer, nil
+}
+
+// 柜员封箱（结束班次并生成轧账报告）
+func (s *TellerService) CloseDrawer(req DrawerCloseRequest) (ShiftReport, error) {
+	if err := model.Validate(req); err != nil {
+		return ShiftReport{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drawer, ok := s.drawers[req.TellerID]
+	if !ok || drawer.Status != "open" {
+		return ShiftReport{}, model.NewError(model.CODE_DRAWER_STATE_ERROR, "钱箱未开箱")
+	}
+
+	drawer.Status = "closed"
+	drawer.ClosedAt = time.Now().Format("2006-01-02 15:04:05")
+	report := buildShiftReport(drawer, &req.CountedCash)
+	s.shiftReports[req.TellerID] = report
+
+	// 终端提示：柜员封箱轧账
+	log.Println("\n[🏦 柜员封箱轧账]")
+	log.Printf("封箱时间: %s", drawer.ClosedAt)
+	log.Printf("柜员: %s (%s)", report.TellerName, report.TellerID)
+	log.Printf("存款: %d 笔，共 %.2f 元", report.DepositCount, report.DepositTotal)
+	log.Printf("取款: %d 笔，共 %.2f 元", report.WithdrawCount, report.WithdrawTotal)
+	log.Printf("应有现金: %.2f 元 | 实点现金: %.2f 元", report.ExpectedCash, report.CountedCash)
+	if report.ReconcileResult == "balanced" {
+		log.Printf("轧账结果: \033[1;32m平账\033[0m") // 绿色高亮
+	} else {
+		log.Printf("轧账结果: \033[1;31m%s %.2f 元\033[0m", report.ReconcileResult, report.Difference) // 红色高亮
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return report, nil
+}
+
+// 柜员班次轧账报告（未封箱时返回实时试算）
+func (s *TellerService) ShiftReport(tellerID string) (ShiftReport, error) {
+	if tellerID == "" {
+		return ShiftReport{}, model.NewError(model.CODE_PARAM_ERROR, "柜员ID不能为空")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drawer, ok := s.drawers[tellerID]
+	if !ok {
+		return ShiftReport{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "该柜员暂无班次记录")
+	}
+
+	// 已封箱返回封箱时的轧账结果，否则按当前钱箱余额试算
+	if drawer.Status == "closed" {
+		return s.shiftReports[tellerID], nil
+	}
+	return buildShiftReport(drawer, nil), nil
+}
+
+// 柜员代客现金存款
+func (s *TellerService) Deposit(req TellerCashRequest) (DrawerOperation, error) {
+	op, err := s.cash(req, "deposit")
+	if err == nil {
+		// 存款匹配奖励（钱箱锁已释放）
+		s.promos.OnDeposit(op.AccountID, op.Amount)
+	}
+	return op, err
+}
+
+// 柜员代客现金取款
+func (s *TellerService) Withdraw(req TellerCashRequest) (DrawerOperation, error) {
+	return s.cash(req, "withdraw")
+}
+
+// 柜员现金业务公共处理
+func (s *TellerService) cash(req TellerCashRequest, opType string) (DrawerOperation, error) {
+	if err := model.Validate(req); err != nil {
+		return DrawerOperation{}, err
+	}
+
+	teller, exists := tellers[req.TellerID]
+	if !exists {
+		return DrawerOperation{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "柜员不存在")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	drawer, ok := s.drawers[req.TellerID]
+	if !ok || drawer.Status != "open" {
+		return DrawerOperation{}, model.NewError(model.CODE_DRAWER_STATE_ERROR, "钱箱未开箱，无法办理现金业务")
+	}
+
+	s.accounts.Lock()
+	defer s.accounts.Unlock()
+
+	account, exists := s.accounts.Find(req.AccountID)
+	if !exists {
+		return DrawerOperation{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "客户账户不存在")
+	}
+
+	if err := dormantError(account); err != nil {
+		return DrawerOperation{}, err
+	}
+	if account.Status != "normal" {
+		return DrawerOperation{}, model.NewError(model.CODE_ACCOUNT_FROZEN, "客户账户已冻结，无法办理现金业务")
+	}
+
+	// 钱箱为人民币现金
+	if account.Currency != model.BASE_CURRENCY {
+		return DrawerOperation{}, model.NewError(model.CODE_PARAM_ERROR, "柜面现金业务仅支持人民币账户")
+	}
+
+	var event, opName string
+	direction := "credit"
+	oldBalance := account.Balance
+	if opType == "deposit" {
+		account.Balance = model.RoundAmount(account.Balance + req.Amount)
+		drawer.Balance = model.RoundAmount(drawer.Balance + req.Amount)
+		opName = "现金存款"
+		event = EVENT_TELLER_DEPOSIT
+	} else {
+		if account.Available() < req.Amount {
+			return DrawerOperation{}, model.NewError(model.CODE_BALANCE_NOT_ENOUGH, "客户账户余额不足")
+		}
+		if drawer.Balance < req.Amount {
+			return DrawerOperation{}, model.NewError(model.CODE_DRAWER_CASH_NOT_ENOUGH, "钱箱现金不足，请先调拨现金")
+		}
+		account.Balance = model.RoundAmount(account.Balance - req.Amount)
+		drawer.Balance = model.RoundAmount(drawer.Balance - req.Amount)
+		direction = "debit"
+		opName = "现金取款"
+		event = EVENT_TELLER_WITHDRAW
+	}
+	account = s.accounts.Save(account)
+	s.ledger.Record(model.Transaction{
+		AccountID:    req.AccountID,
+		Type:         "teller_" + opType,
+		Direction:    direction,
+		Amount:       req.Amount,
+		BalanceAfter: account.Balance,
+		Reference:    teller.BranchID + "/" + teller.TellerID,
+		Description:  "柜面" + opName,
+	})
+
+	operation := DrawerOperation{
+		Type:         opType,
+		AccountID:    req.AccountID,
+		Amount:       req.Amount,
+		DrawerAfter:  drawer.Balance,
+		AccountAfter: account.Balance,
+		Time:         time.Now().Format("2006-01-02 15:04:05"),
+	}
+	drawer.Operations = append(drawer.Operations, operation)
+
+	// 发送 WebSocket 通知（实时更新余额）
+	s.notifier.Send(ws.Message{
+		Type:       "balanceUpdate",
+		AccountID:  account.AccountID,
+		NewBalance: account.Balance,
+	})
+
+	// 发送交易提醒
+	s.notifier.Send(s.templates.Alert("transactionAlert", account, event, map[string]interface{}{
+		"Amount":  req.Amount,
+		"Balance": account.Balance,
+	}))
+
+	// 终端提示：柜员现金业务详情
+	log.Printf("\n[🏦 柜员%s]", opName)
+	log.Printf("操作时间: %s", operation.Time)
+	log.Printf("网点: %s", branches[teller.BranchID].Name)
+	log.Printf("柜员: %s (%s)", teller.Name, teller.TellerID)
+	log.Printf("账户ID: %s", req.AccountID)
+	log.Printf("用户名: %s", account.UserName)
+	log.Printf("金额: \033[1;33m%.2f 元\033[0m", req.Amount) // 黄色高亮
+	log.Printf("账户余额: %.2f 元 → \033[1;36m%.2f 元\033[0m", oldBalance, account.Balance)
+	log.Printf("钱箱余额: %.2f 元", drawer.Balance)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return operation, nil
+}
+
+// -------------------------- 柜员工具函数 --------------------------
+
+// 根据钱箱流水生成轧账报告，counted 为 nil 表示尚未实点
+func buildShiftReport(drawer *CashDrawer, counted *float64) ShiftReport {
+	teller := tellers[drawer.TellerID]
+	report := ShiftReport{
+		TellerID:    drawer.TellerID,
+		TellerName:  teller.Name,
+		BranchID:    drawer.BranchID,
+		BranchName:  branches[drawer.BranchID].Name,
+		OpenedAt:    drawer.OpenedAt,
+		ClosedAt:    drawer.ClosedAt,
+		OpeningCash: drawer.OpeningCash,
+	}
+
+	for _, op := range drawer.Operations {
+		if op.Type == "deposit" {
+			report.DepositCount++
+			report.DepositTotal += op.Amount
+		} else {
+			report.WithdrawCount++
+			report.WithdrawTotal += op.Amount
+		}
+	}
+	report.ExpectedCash = report.OpeningCash + report.DepositTotal - report.WithdrawTotal
+
+	if counted == nil {
+		report.CountedCash = drawer.Balance
+		report.ReconcileResult = "pending"
+		return report
+	}
+
+	report.CountedCash = *counted
+	report.Difference = report.CountedCash - report.ExpectedCash
+	switch {
+	case report.Difference > 0.005:
+		report.ReconcileResult = "over" // 长款
+	case report.Difference < -0.005:
+		report.ReconcileResult = "short" // 短款
+	default:
+		report.Difference = 0
+		report.ReconcileResult = "balanced"
+	}
+
+	return report
+}
+
+// 打印测试柜员信息
+func (s *TellerService) PrintTestTellers() {
+	log.Println("\n[🏦 测试柜员信息]")
+	for _, teller := range tellers {
+		log.Printf("柜员ID: %s | 姓名: %s | 网点: %s",
+			teller.TellerID, teller.Name, branches[teller.BranchID].Name)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+}
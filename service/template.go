@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/template"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/ws"
+)
+
+// 消息通道
+const (
+	CHANNEL_WS    = "ws"    // WebSocket 推送（同时存入通知中心）
+	CHANNEL_EMAIL = "email" // 邮件
+	CHANNEL_SMS   = "sms"   // 短信
+)
+
+// 默认语言（账户未设置语言或模板缺少对应语言时使用）
+const DEFAULT_LOCALE = "zh-CN"
+
+// 消息事件类型
+const (
+	EVENT_DEPOSIT                = "deposit"              // 存款
+	EVENT_TRANSFER_OUT           = "transferOut"          // 行内转出
+	EVENT_INTERBANK_ACCEPTED     = "interbankAccepted"    // 跨行转账已受理
+	EVENT_INTERBANK_SETTLED      = "interbankSettled"     // 跨行转账已清算
+	EVENT_INTERBANK_REJECTED     = "interbankRejected"    // 跨行转账被拒绝
+	EVENT_INTERBANK_RETURNED     = "interbankReturned"    // 跨行转账被退汇
+	EVENT_INTERBANK_RECEIVED     = "interbankReceived"    // 跨行汇入
+	EVENT_CARD_PURCHASE          = "cardPurchase"         // 银行卡消费
+	EVENT_CARD_REFUND            = "cardRefund"           // 银行卡消费退货
+	EVENT_POS_SALE               = "posSale"              // 商户收单入账
+	EVENT_TELLER_DEPOSIT         = "tellerDeposit"        // 柜面现金存款
+	EVENT_TELLER_WITHDRAW        = "tellerWithdraw"       // 柜面现金取款
+	EVENT_ACCOUNT_FROZEN         = "accountFrozen"        // 账户冻结
+	EVENT_ACCOUNT_UNFROZEN       = "accountUnfrozen"      // 账户解冻
+	EVENT_ACCOUNT_DORMANT        = "accountDormant"       // 账户转为休眠
+	EVENT_ACCOUNT_REACTIVATED    = "accountReactivated"   // 休眠账户已激活
+	EVENT_HOLD_PLACED            = "holdPlaced"           // 资金冻结
+	EVENT_HOLD_RELEASED          = "holdReleased"         // 资金解冻（含到期解除）
+	EVENT_PROMO_BONUS            = "promoBonus"           // 营销奖励到账
+	EVENT_CASHBACK_POSTED        = "cashbackPosted"       // 月度消费返现入账
+	EVENT_POINTS_REDEEMED        = "pointsRedeemed"       // 积分兑换到账
+	EVENT_LOAN_DISBURSED         = "loanDisbursed"        // 贷款放款
+	EVENT_LOAN_REPAID            = "loanRepaid"           // 贷款按期扣款
+	EVENT_LOAN_OVERDUE           = "loanOverdue"          // 贷款扣款失败（逾期）
+	EVENT_LOAN_PREPAID           = "loanPrepaid"          // 贷款提前还款
+	EVENT_MANDATE_CREATED        = "mandateCreated"       // 直接借记授权登记
+	EVENT_DIRECT_DEBIT_COLLECTED = "directDebitCollected" // 直接借记扣款
+	EVENT_DIRECT_DEBIT_RETURNED  = "directDebitReturned"  // 直接借记退回
+	EVENT_PAYROLL_CREDITED       = "payrollCredited"      // 代发工资到账
+	EVENT_PAYROLL_DEBITED        = "payrollDebited"       // 代发工资批次扣款
+	EVENT_STATEMENT_READY        = "statementReady"       // 对账单已生成
+	EVENT_MONTHLY_STATEMENT      = "monthlyStatement"     // 月度对账单送达（订阅账户）
+	EVENT_LARGE_TRANSFER         = "largeTransfer"        // 大额转账提醒
+	EVENT_BALANCE_ALERT          = "balanceAlert"         // 用户自定义余额提醒规则触发
+	EVENT_BUDGET_ALERT           = "budgetAlert"          // 月度预算已用 80%/100%
+	EVENT_NEW_LOCATION           = "newLocation"          // 新地点登录、新地点交易（风控）
+	EVENT_NEW_DEVICE             = "newDevice"            // 新设备登录、新设备交易（风控）
+	EVENT_LOGIN_LOCKED           = "loginLocked"          // 登录失败次数过多，账户登录已锁定
+	EVENT_PASSWORD_RESET         = "passwordReset"        // 密码重置令牌（邮件、短信）
+	EVENT_PASSWORD_CHANGED       = "passwordChanged"      // 登录密码已重置
+	EVENT_CONTACT_VERIFY         = "contactVerify"        // 联系方式变更验证码（发往新的手机号或邮箱）
+	EVENT_CONTACT_CHANGED        = "contactChanged"       // 联系方式已变更（安全提醒，同时发往原联系方式）
+	EVENT_ALIAS_VERIFY           = "aliasVerify"          // 收款别名认领验证码（发往该手机号或邮箱）
+	EVENT_ALIAS_PORTED           = "aliasPorted"          // 收款别名已被其他账户认领（安全提醒，发往原绑定账户）
+	EVENT_SUPPORT_REPLY          = "supportReply"         // 在线客服回复
+	EVENT_SUPPORT_CLOSED         = "supportClosed"        // 在线客服会话已结束
+	EVENT_OTP                    = "otp"                  // 短信验证码
+	EVENT_SMS_ALERT              = "smsAlert"             // 通用短信提醒（事件未定义短信模板时套用，变量 Message 为推送内容）
+)
+
+// 消息模板（主题与正文均为 text/template，主题仅邮件使用）
+type MessageTemplate struct {
+	Event   string `json:"event"`
+	Locale  string `json:"locale"`
+	Channel string `json:"channel"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body"`
+}
+
+// 内置模板（模板文件中的同名模板会覆盖）
+var defaultMessageTemplates = []MessageTemplate{
+	// WebSocket 推送
+	{Event: EVENT_DEPOSIT, Channel: CHANNEL_WS, Body: "存款成功：+{{money .Amount}}元，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_TRANSFER_OUT, Channel: CHANNEL_WS, Body: "转账成功：-{{money .Amount}}元{{if .Fee}}，手续费：{{money .Fee}}元{{end}}，当前余额：{{money .Balance}}元{{if .Memo}}，附言：{{.Memo}}{{end}}"},
+	{Event: EVENT_INTERBANK_ACCEPTED, Channel: CHANNEL_WS, Body: "跨行转账已受理：-{{money .Amount}}元{{if .Fee}}，手续费：{{money .Fee}}元{{end}}，流水号：{{.Reference}}，当前余额：{{money .Balance}}元{{if .Memo}}，附言：{{.Memo}}{{end}}"},
+	{Event: EVENT_INTERBANK_SETTLED, Channel: CHANNEL_WS, Body: "跨行转账已清算：{{money .Amount}}元已汇入{{.BankName}}，流水号：{{.Reference}}"},
+	{Event: EVENT_INTERBANK_REJECTED, Channel: CHANNEL_WS, Body: "跨行转账被收款行拒绝：+{{money .Amount}}元已退回，原因：{{.Reason}}，流水号：{{.Reference}}"},
+	{Event: EVENT_INTERBANK_RETURNED, Channel: CHANNEL_WS, Body: "跨行转账被退汇：+{{money .Amount}}元已退回，原因：{{.Reason}}，流水号：{{.Reference}}"},
+	{Event: EVENT_INTERBANK_RECEIVED, Channel: CHANNEL_WS, Body: "跨行汇入：+{{money .Amount}}元，汇款人：{{.Payer}}（{{.BankName}}），流水号：{{.Reference}}，当前余额：{{money .Balance}}元{{if .Memo}}，附言：{{.Memo}}{{end}}"},
+	{Event: EVENT_CARD_PURCHASE, Channel: CHANNEL_WS, Body: "银行卡消费（尾号{{.CardSuffix}}）：-{{money .Amount}}元，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_CARD_REFUND, Channel: CHANNEL_WS, Body: "{{.Merchant}}消费退货：+{{money .Amount}}元，原交易：{{.Reference}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_POS_SALE, Channel: CHANNEL_WS, Body: "收单入账：+{{money .Amount}}元，手续费 {{money .Fee}}元，实收 {{money .Net}}元，交易编号：{{.Reference}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_TELLER_DEPOSIT, Channel: CHANNEL_WS, Body: "柜面现金存款：+{{money .Amount}}元，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_TELLER_WITHDRAW, Channel: CHANNEL_WS, Body: "柜面现金取款：-{{money .Amount}}元，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_ACCOUNT_FROZEN, Channel: CHANNEL_WS, Body: "您的账户已被冻结，原因：{{.Reason}}"},
+	{Event: EVENT_ACCOUNT_UNFROZEN, Channel: CHANNEL_WS, Body: "您的账户已解冻，可正常办理业务"},
+	{Event: EVENT_ACCOUNT_DORMANT, Channel: CHANNEL_WS, Body: "您的账户已连续 {{.Days}} 天无交易，已转为休眠账户，激活后方可办理业务"},
+	{Event: EVENT_ACCOUNT_REACTIVATED, Channel: CHANNEL_WS, Body: "您的休眠账户已激活，可正常办理业务"},
+	{Event: EVENT_HOLD_PLACED, Channel: CHANNEL_WS, Body: "资金冻结：{{money .Amount}}元，原因：{{.Reason}}，可用余额：{{money .Available}}元"},
+	{Event: EVENT_HOLD_RELEASED, Channel: CHANNEL_WS, Body: "资金解冻：{{money .Amount}}元，可用余额：{{money .Available}}元"},
+	{Event: EVENT_CASHBACK_POSTED, Channel: CHANNEL_WS, Body: "{{.Month}} 消费返现已到账：+{{money .Amount}}元，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_POINTS_REDEEMED, Channel: CHANNEL_WS, Body: "积分兑换成功：{{.Points}} 积分兑换 +{{money .Amount}}元，剩余积分 {{.Remaining}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_LOAN_DISBURSED, Channel: CHANNEL_WS, Body: "贷款已放款：+{{money .Amount}}元，贷款编号：{{.Reference}}，共 {{.Terms}} 期，月供 {{money .Installment}}元，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_LOAN_REPAID, Channel: CHANNEL_WS, Body: "贷款还款：-{{money .Amount}}元（第 {{.No}}/{{.Terms}} 期），贷款编号：{{.Reference}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_LOAN_OVERDUE, Channel: CHANNEL_WS, Body: "贷款第 {{.No}}/{{.Terms}} 期应还 {{money .Amount}}元扣款失败，已逾期，请尽快存入足额资金，贷款编号：{{.Reference}}"},
+	{Event: EVENT_LOAN_PREPAID, Channel: CHANNEL_WS, Body: "贷款提前还款：-{{money .Amount}}元（本金 {{money .Principal}}，利息 {{money .Interest}}，{{if .Penalty}}罚息 {{money .Penalty}}，{{end}}违约金 {{money .Fee}}），{{if .PaidOff}}贷款已结清{{else}}剩余本金 {{money .Outstanding}}元{{end}}，贷款编号：{{.Reference}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_MANDATE_CREATED, Channel: CHANNEL_WS, Body: "{{.Creditor}}已登记直接借记授权（{{.MandateID}}）{{if .MaxAmount}}，单笔上限 {{money .MaxAmount}}元{{end}}，如非本人授权请及时撤销"},
+	{Event: EVENT_DIRECT_DEBIT_COLLECTED, Channel: CHANNEL_WS, Body: "{{.Creditor}}直接借记扣款：-{{money .Amount}}元，扣款编号：{{.Reference}}，{{.RefundDeadline}} 前可申请退回，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_DIRECT_DEBIT_RETURNED, Channel: CHANNEL_WS, Body: "{{.Creditor}}直接借记扣款已退回：+{{money .Amount}}元，扣款编号：{{.Reference}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_PAYROLL_CREDITED, Channel: CHANNEL_WS, Body: "{{.Payer}}{{.Memo}}到账：+{{money .Amount}}元，批次号：{{.Reference}}，当前余额：{{money .Balance}}元"},
+	{Event: EVENT_PAYROLL_DEBITED, Channel: CHANNEL_WS, Body: "代发批次 {{.Reference}} 已扣款：-{{money .Amount}}元（成功 {{.Success}} 笔{{if .Failed}}，被拒 {{.Failed}} 笔{{end}}），当前余额：{{money .Balance}}元"},
+	{Event: EVENT_BALANCE_ALERT, Channel: CHANNEL_WS, Body: "{{if eq .RuleType \"balanceBelow\"}}余额提醒：当前余额 {{money .Balance}}元，已低于您设置的 {{money .Threshold}}元{{else if eq .RuleType \"balanceAbove\"}}余额提醒：当前余额 {{money .Balance}}元，已高于您设置的 {{money .Threshold}}元{{else if eq .RuleType \"debitOver\"}}大额支出提醒：-{{money .Amount}}元，超过您设置的 {{money .Threshold}}元，当前余额：{{money .Balance}}元{{else}}大额入账提醒：+{{money .Amount}}元，超过您设置的 {{money .Threshold}}元，当前余额：{{money .Balance}}元{{end}}"},
+	{Event: EVENT_BUDGET_ALERT, Channel: CHANNEL_WS, Body: "{{if .Exceeded}}预算超支提醒：{{.Month}} {{.Category}} 类支出 {{money .Spent}}元，已超出预算 {{money .Limit}}元{{else}}预算提醒：{{.Month}} {{.Category}} 类支出 {{money .Spent}}元，已用预算 {{money .Limit}}元的 {{.Percent}}%，剩余 {{money .Remaining}}元{{end}}"},
+	{Event: EVENT_NEW_LOCATION, Channel: CHANNEL_WS, Body: "安全提醒：您的账户在新的地点（{{.City}}，IP {{.IP}}）{{if .Amount}}发起了 {{money .Amount}}元转账{{else}}登录，该地点交易将按新地点风控处置{{end}}，如非本人操作请立即冻结账户并联系客服"},
+	{Event: EVENT_NEW_DEVICE, Channel: CHANNEL_WS, Body: "安全提醒：您的账户在未识别的设备（{{.DeviceID}}，IP {{.IP}}）上{{if .Amount}}验证后发起了 {{money .Amount}}元转账，该设备已加入信任设备{{else}}登录，该设备交易须短信验证码验证{{end}}，如非本人操作请立即移除设备并联系客服"},
+	{Event: EVENT_LOGIN_LOCKED, Channel: CHANNEL_WS, Body: "安全提醒：您的账户连续 {{.Failures}} 次登录失败（最近一次来自 IP {{.IP}}），登录已锁定至 {{.LockedUntil}}，如非本人操作请及时联系客服"},
+	{Event: EVENT_PASSWORD_CHANGED, Channel: CHANNEL_WS, Body: "安全提醒：您的登录密码已于 {{.Time}} 重置，所有设备上的登录已失效，如非本人操作请立即联系客服"},
+	{Event: EVENT_CONTACT_CHANGED, Channel: CHANNEL_WS, Body: "安全提醒：您的{{.FieldName}}已于 {{.Time}} 由 {{.OldValue}} 变更为 {{.NewValue}}，如非本人操作请立即联系客服"},
+	{Event: EVENT_ALIAS_PORTED, Channel: CHANNEL_WS, Body: "安全提醒：您绑定的收款{{.TypeName}} {{.Alias}} 已于 {{.Time}} 被其他账户验证认领，此后通过该{{.TypeName}}的转账将不再汇入本账户，如非本人操作请立即联系客服"},
+	{Event: EVENT_SUPPORT_REPLY, Channel: CHANNEL_WS, Body: "{{.Agent}}回复：{{.Text}}"},
+	{Event: EVENT_SUPPORT_CLOSED, Channel: CHANNEL_WS, Body: "您的在线客服会话（{{.ConversationID}}）已由{{.Agent}}结束，如有其他问题可随时发起新的咨询"},
+	{Event: EVENT_PROMO_BONUS, Channel: CHANNEL_WS, Body: "{{.Campaign}}奖励到账：+{{money .Amount}}元（{{.Reason}}），当前余额：{{money .Balance}}元"},
+
+	// 邮件
+	{
+		Event:   EVENT_STATEMENT_READY,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】您的 {{.Date}} 对账单已生成",
+		Body:    "尊敬的{{.UserName}}：\n\n您尾号 {{.AccountSuffix}} 的账户 {{.Date}} 对账单已生成，期末余额 {{money .ClosingBalance}} {{.Currency}}。\n请登录网银查看详情。",
+	},
+	{
+		Event:   EVENT_MONTHLY_STATEMENT,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】您的 {{.Period}} 月度对账单",
+		Body:    "尊敬的{{.UserName}}：\n\n您尾号 {{.AccountSuffix}} 的账户 {{.Period}} 月度对账单（{{.StatementID}}）如下：\n\n期初余额：{{money .OpeningBalance}} {{.Currency}}\n收入：{{.CreditCount}} 笔，合计 {{money .CreditTotal}} {{.Currency}}\n支出：{{.DebitCount}} 笔，合计 {{money .DebitTotal}} {{.Currency}}\n{{if .NetInterest}}税后利息：{{money .NetInterest}} {{.Currency}}\n{{end}}期末余额：{{money .ClosingBalance}} {{.Currency}}\n\n交易明细请登录网银在对账单存档中查看。如需停止接收，可在网银中关闭月度对账单。",
+	},
+	{
+		Event:   EVENT_LARGE_TRANSFER,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】大额转账提醒",
+		Body:    "尊敬的{{.UserName}}：\n\n您尾号 {{.AccountSuffix}} 的账户于 {{.Time}} 向 {{.Counterparty}} 转出 {{money .Amount}} {{.Currency}}，当前余额 {{money .Balance}} {{.Currency}}。\n如非本人操作，请立即联系客服。",
+	},
+	{
+		Event:   EVENT_ACCOUNT_FROZEN,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】账户冻结通知",
+		Body:    "尊敬的{{.UserName}}：\n\n您尾号 {{.AccountSuffix}} 的账户已于 {{.Time}} 被冻结，原因：{{.Reason}}。\n如有疑问，请携带有效证件前往网点办理。",
+	},
+	{
+		Event:   EVENT_ACCOUNT_DORMANT,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】账户休眠通知",
+		Body:    "尊敬的{{.UserName}}：\n\n您尾号 {{.AccountSuffix}} 的账户自 {{.LastActivity}} 起已连续 {{.Days}} 天无交易，已于 {{.Time}} 转为休眠账户。\n激活账户后方可继续办理存取款、转账等业务。",
+	},
+	{
+		Event:   EVENT_CONTACT_VERIFY,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】联系邮箱变更验证",
+		Body:    "尊敬的{{.UserName}}：\n\n您正在将账户{{.FieldName}}变更为本邮箱，验证码为 {{.Code}}，{{.Minutes}} 分钟内有效。\n如非本人操作，请忽略本邮件。",
+	},
+	{
+		Event:   EVENT_ALIAS_VERIFY,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】收款邮箱绑定验证",
+		Body:    "尊敬的{{.UserName}}：\n\n您正在将本邮箱绑定为尾号 {{.AccountSuffix}} 账户的收款别名，验证码为 {{.Code}}，{{.Minutes}} 分钟内有效。\n绑定后他人可通过本邮箱向该账户转账。如非本人操作，请忽略本邮件。",
+	},
+	{
+		Event:   EVENT_CONTACT_CHANGED,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】联系方式变更提醒",
+		Body:    "尊敬的{{.UserName}}：\n\n您尾号 {{.AccountSuffix}} 的账户{{.FieldName}}已于 {{.Time}} 由 {{.OldValue}} 变更为 {{.NewValue}}，本邮箱将不再接收账户通知。\n如非本人操作，请立即联系客服。",
+	},
+	{
+		Event:   EVENT_PASSWORD_RESET,
+		Channel: CHANNEL_EMAIL,
+		Subject: "【ZeroBank】登录密码重置",
+		Body:    "尊敬的{{.UserName}}：\n\n您正在重置尾号 {{.AccountSuffix}} 的账户登录密码，重置令牌为：\n\n{{.Token}}\n\n令牌 {{.Minutes}} 分钟内有效且仅可使用一次。如非本人操作，请忽略本邮件并及时联系客服。",
+	},
+
+	// 短信
+	{Event: EVENT_OTP, Channel: CHANNEL_SMS, Body: "【ZeroBank】您的验证码为 {{.Code}}，{{.Minutes}} 分钟内有效，请勿泄露给他人。"},
+	{Event: EVENT_PASSWORD_RESET, Channel: CHANNEL_SMS, Body: "【ZeroBank】您正在重置登录密码，重置令牌为 {{.Token}}，{{.Minutes}} 分钟内有效，请勿泄露给他人。"},
+	{Event: EVENT_CONTACT_VERIFY, Channel: CHANNEL_SMS, Body: "【ZeroBank】您正在将账户{{.FieldName}}变更为本号码，验证码为 {{.Code}}，{{.Minutes}} 分钟内有效，如非本人操作请忽略。"},
+	{Event: EVENT_ALIAS_VERIFY, Channel: CHANNEL_SMS, Body: "【ZeroBank】您正在将本号码绑定为尾号{{.AccountSuffix}}账户的收款手机号，验证码为 {{.Code}}，{{.Minutes}} 分钟内有效，如非本人操作请忽略。"},
+	{Event: EVENT_CONTACT_CHANGED, Channel: CHANNEL_SMS, Body: "【ZeroBank】您尾号{{.AccountSuffix}}的账户{{.FieldName}}已于 {{.Time}} 变更为 {{.NewValue}}，本号码将不再接收账户通知，如非本人操作请立即联系客服。"},
+	{Event: EVENT_SMS_ALERT, Channel: CHANNEL_SMS, Body: "【ZeroBank】您尾号{{.AccountSuffix}}的账户{{.Message}}"},
+}
+
+// 模板函数
+var templateFuncs = template.FuncMap{
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+}
+
+// 已解析的模板
+type parsedTemplate struct {
+	MessageTemplate
+	subject *template.Template
+	body    *template.Template
+}
+
+// 消息模板库：按事件类型、语言、通道管理消息文案，可从模板目录加载覆盖，修改文案无需改代码
+type TemplateRegistry struct {
+	dir string
+
+	templates map[string]*parsedTemplate // 事件/语言/通道 → 模板
+	mu        sync.RWMutex
+}
+
+// 创建模板库：加载内置模板，再加载模板目录下的 *.json 文件（每个文件为模板数组）
+func NewTemplateRegistry(dir string) *TemplateRegistry {
+	r := &TemplateRegistry{dir: dir}
+	if _, err := r.Reload(); err != nil {
+		log.Printf("消息模板加载失败: %v，使用内置模板", err)
+	}
+	return r
+}
+
+// 重新加载模板（内置模板 + 模板目录），返回生效的模板数；任一文件有误时保留原模板
+func (r *TemplateRegistry) Reload() (int, error) {
+	templates := make(map[string]*parsedTemplate)
+	for _, tmpl := range defaultMessageTemplates {
+		parsed, err := parseMessageTemplate(tmpl)
+		if err != nil {
+			return 0, err
+		}
+		templates[templateKey(parsed.Event, parsed.Locale, parsed.Channel)] = parsed
+	}
+
+	var files []string
+	if r.dir != "" {
+		files, _ = filepath.Glob(filepath.Join(r.dir, "*.json"))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return 0, fmt.Errorf("读取模板文件 %s 失败: %v", file, err)
+		}
+		var configured []MessageTemplate
+		if err := json.Unmarshal(data, &configured); err != nil {
+			return 0, fmt.Errorf("模板文件 %s 格式错误: %v", file, err)
+		}
+		for _, tmpl := range configured {
+			parsed, err := parseMessageTemplate(tmpl)
+			if err != nil {
+				return 0, fmt.Errorf("模板文件 %s: %v", file, err)
+			}
+			templates[templateKey(parsed.Event, parsed.Locale, parsed.Channel)] = parsed
+		}
+	}
+
+	r.mu.Lock()
+	r.templates = templates
+	r.mu.Unlock()
+
+	log.Printf("消息模板加载完成，共 %d 个模板（模板文件 %d 个）", len(templates), len(files))
+	return len(templates), nil
+}
+
+// 新增或覆盖一个模板（仅在内存中生效，重新加载后以文件为准）
+func (r *TemplateRegistry) Set(tmpl MessageTemplate) (MessageTemplate, error) {
+	parsed, err := parseMessageTemplate(tmpl)
+	if err != nil {
+		return MessageTemplate{}, model.NewError(model.CODE_PARAM_ERROR, err.Error())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[templateKey(parsed.Event, parsed.Locale, parsed.Channel)] = parsed
+	return parsed.MessageTemplate, nil
+}
+
+// 查询模板（按事件、语言、通道排序），条件为空表示不限
+func (r *TemplateRegistry) List(event, locale, channel string) []MessageTemplate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]MessageTemplate, 0, len(r.templates))
+	for _, tmpl := range r.templates {
+		if (event != "" && tmpl.Event != event) || (locale != "" && tmpl.Locale != locale) ||
+			(channel != "" && tmpl.Channel != channel) {
+			continue
+		}
+		result = append(result, tmpl.MessageTemplate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Event != b.Event {
+			return a.Event < b.Event
+		}
+		if a.Locale != b.Locale {
+			return a.Locale < b.Locale
+		}
+		return a.Channel < b.Channel
+	})
+	return result
+}
+
+// 按账户语言渲染事件在指定通道的消息，返回主题与正文；账户语言缺少模板时退回默认语言
+func (r *TemplateRegistry) Render(event, channel string, account model.Account, vars map[string]interface{}) (string, string, error) {
+	locale := account.Locale
+	if locale == "" {
+		locale = DEFAULT_LOCALE
+	}
+
+	r.mu.RLock()
+	tmpl, ok := r.templates[templateKey(event, locale, channel)]
+	if !ok {
+		tmpl, ok = r.templates[templateKey(event, DEFAULT_LOCALE, channel)]
+	}
+	r.mu.RUnlock()
+	if !ok {
+		return "", "", fmt.Errorf("消息模板不存在: %s/%s/%s", event, locale, channel)
+	}
+
+	data := map[string]interface{}{
+		"UserName":      account.UserName,
+		"AccountID":     account.AccountID,
+		"AccountSuffix": accountSuffix(account.AccountID),
+		"Currency":      account.Currency,
+	}
+	for key, value := range vars {
+		data[key] = value
+	}
+
+	var subject, body bytes.Buffer
+	if tmpl.subject != nil {
+		if err := tmpl.subject.Execute(&subject, data); err != nil {
+			return "", "", err
+		}
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+// 生成推送给账户的提醒消息（msgType 如 transactionAlert/securityAlert），携带事件与变量供短信等通道按各自模板渲染
+func (r *TemplateRegistry) Alert(msgType string, account model.Account, event string, vars map[string]interface{}) ws.Message {
+	_, message, err := r.Render(event, CHANNEL_WS, account, vars)
+	if err != nil {
+		log.Printf("消息模板渲染失败: %v", err)
+		message = event
+	}
+	return ws.Message{
+		Type:      msgType,
+		AccountID: account.AccountID,
+		Message:   message,
+		Event:     event,
+		Vars:      vars,
+	}
+}
+
+// 校验并解析模板（语言为空时视为默认语言）
+func parseMessageTemplate(tmpl MessageTemplate) (*parsedTemplate, error) {
+	if tmpl.Event == "" || tmpl.Body == "" {
+		return nil, fmt.Errorf("模板事件类型与正文不能为空")
+	}
+	switch tmpl.Channel {
+	case CHANNEL_WS, CHANNEL_EMAIL, CHANNEL_SMS:
+	default:
+		return nil, fmt.Errorf("模板 %s 的通道应为 ws/email/sms", tmpl.Event)
+	}
+	if tmpl.Locale == "" {
+		tmpl.Locale = DEFAULT_LOCALE
+	}
+
+	parsed := &parsedTemplate{MessageTemplate: tmpl}
+	var err error
+	if parsed.body, err = template.New(tmpl.Event).Funcs(templateFuncs).Option("missingkey=zero").Parse(tmpl.Body); err != nil {
+		return nil, fmt.Errorf("模板 %s 正文解析失败: %v", tmpl.Event, err)
+	}
+	if tmpl.Subject != "" {
+		if parsed.subject, err = template.New(tmpl.Event).Funcs(templateFuncs).Option("missingkey=zero").Parse(tmpl.Subject); err != nil {
+			return nil, fmt.Errorf("模板 %s 主题解析失败: %v", tmpl.Event, err)
+		}
+	}
+	return parsed, nil
+}
+
+// 模板索引键
+func templateKey(event, locale, channel string) string {
+	return event + "/" + locale + "/" + channel
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 全文检索结果中的一条流水：highlights 为命中字段的原文（已做 HTML 转义），命中词以 <em></em> 标出
+type TransactionSearchHit struct {
+	Transaction model.Transaction `json:"transaction"`
+	Highlights  map[string]string `json:"highlights"`
+}
+
+// 交易流水全文检索结果（默认按时间倒序）
+type TransactionSearchResult struct {
+	AccountID string                 `json:"accountId"`
+	Query     string                 `json:"query"`
+	Total     int                    `json:"total"`
+	Items     []TransactionSearchHit `json:"items"`
+}
+
+// 全文检索账户交易流水：在摘要（含收付款方户名）、对方、业务参考号、附言与端到端参考号中匹配关键词，
+// 多个关键词须全部命中（英文按词匹配、不区分大小写，中文按连续字串匹配）。filter 可进一步限定金额、时间等条件
+func (s *LedgerService) SearchText(accountID, text string, filter TransactionFilter, limit int) (TransactionSearchResult, error) {
+	keywords := repository.TextSegments(text)
+	if len(keywords) == 0 {
+		return TransactionSearchResult{}, model.NewError(model.CODE_PARAM_ERROR, "搜索关键词不能为空")
+	}
+	query, err := filter.query()
+	if err != nil {
+		return TransactionSearchResult{}, err
+	}
+	limit = cursorLimit(limit)
+
+	// 倒排索引按单字定位候选流水，再逐条校验中文关键词是否连续出现并生成高亮
+	result := TransactionSearchResult{AccountID: accountID, Query: text, Items: make([]TransactionSearchHit, 0)}
+	for _, tx := range s.journal.SearchText(accountID, repository.TextTerms(text), query) {
+		highlights, ok := highlightTransaction(tx, keywords)
+		if !ok {
+			continue
+		}
+		result.Total++
+		if len(result.Items) < limit {
+			result.Items = append(result.Items, TransactionSearchHit{Transaction: tx, Highlights: highlights})
+		}
+	}
+	return result, nil
+}
+
+// 标出流水各检索字段中的关键词，全部关键词均有命中时返回 true
+func highlightTransaction(tx model.Transaction, keywords []repository.TextSegment) (map[string]string, bool) {
+	found := make([]bool, len(keywords))
+	highlights := make(map[string]string)
+	for _, field := range repository.TransactionTextFields(tx) {
+		spans := make([][2]int, 0)
+		for _, segment := range repository.TextSegments(field.Text) {
+			for k, keyword := range keywords {
+				if keyword.Han != segment.Han {
+					continue
+				}
+				if !keyword.Han {
+					if strings.EqualFold(segment.Text, keyword.Text) {
+						found[k] = true
+						spans = append(spans, [2]int{segment.Start, segment.End})
+					}
+					continue
+				}
+				for offset := 0; ; {
+					i := strings.Index(segment.Text[offset:], keyword.Text)
+					if i < 0 {
+						break
+					}
+					found[k] = true
+					start := segment.Start + offset + i
+					spans = append(spans, [2]int{start, start + len(keyword.Text)})
+					offset += i + len(keyword.Text)
+				}
+			}
+		}
+		if len(spans) > 0 {
+			highlights[field.Name] = markSpans(field.Text, spans)
+		}
+	}
+	for _, ok := range found {
+		if !ok {
+			return nil, false
+		}
+	}
+	return highlights, true
+}
+
+// 合并重叠区间后以 <em></em> 标出，其余文本做 HTML 转义
+func markSpans(text string, spans [][2]int) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(spans); {
+		start, end := spans[i][0], spans[i][1]
+		for i++; i < len(spans) && spans[i][0] <= end; i++ {
+			if spans[i][1] > end {
+				end = spans[i][1]
+			}
+		}
+		b.WriteString(html.EscapeString(text[pos:start]))
+		b.WriteString("<em>" + html.EscapeString(text[start:end]) + "</em>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(text[pos:]))
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 账户动态类型
+const (
+	TIMELINE_TRANSACTION  = "transaction"  // 交易流水
+	TIMELINE_LOGIN        = "login"        // 登录
+	TIMELINE_SECURITY     = "security"     // 风控安全事件
+	TIMELINE_STATUS       = "statusChange" // 账户状态变更
+	TIMELINE_NOTIFICATION = "notification" // 通知（含全员广播）
+)
+
+var timelineTypes = []string{TIMELINE_TRANSACTION, TIMELINE_LOGIN, TIMELINE_SECURITY, TIMELINE_STATUS, TIMELINE_NOTIFICATION}
+
+// 账户动态条目
+type TimelineItem struct {
+	Type  string      `json:"type"`  // 见 TIMELINE_*
+	Time  string      `json:"time"`  // 发生时间
+	Title string      `json:"title"` // 摘要，供列表直接展示
+	RefID string      `json:"refId"` // 原记录编号（流水号、登录记录编号、风控事件编号、审计编号、通知编号）
+	Data  interface{} `json:"data"`  // 原记录
+
+	at time.Time
+}
+
+// 排序键：时间相同时按类型与原记录编号排列，保证分页顺序稳定
+func (item TimelineItem) key() string {
+	return item.Type + ":" + item.RefID
+}
+
+// 一页账户动态（游标分页，按时间倒序）
+type TimelinePage struct {
+	AccountID  string         `json:"accountId"`
+	Limit      int            `json:"limit"`
+	Items      []TimelineItem `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"` // 下一页游标，为空表示没有更多
+	HasMore    bool           `json:"hasMore"`
+}
+
+// 账户动态服务：将交易流水、登录记录、风控事件、状态变更与通知按时间合并为一条动态流（只读，不单独存储）
+type TimelineService struct {
+	ledger        *LedgerService
+	risk          *RiskService
+	audit         *AuditService
+	notifications *NotificationService
+}
+
+func NewTimelineService(ledger *LedgerService, risk *RiskService, audit *AuditService, notifications *NotificationService) *TimelineService {
+	return &TimelineService{ledger: ledger, risk: risk, audit: audit, notifications: notifications}
+}
+
+// 游标分页查询账户动态，types 为空表示全部类型；cursor 为上一页返回的 nextCursor，为空表示第一页。
+// 游标记录上一页最后一条的时间与排序键，之后产生的新动态不影响向前翻页
+func (s *TimelineService) Page(accountID string, types []string, cursor string, limit int) (TimelinePage, error) {
+	after, err := parseCursor(cursor)
+	if err != nil {
+		return TimelinePage{}, err
+	}
+	limit = cursorLimit(limit)
+
+	include := make(map[string]bool, len(timelineTypes))
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !containsString(timelineTypes, t) {
+			return TimelinePage{}, model.NewError(model.CODE_PARAM_ERROR, "不支持的动态类型: "+t+"，应为 "+strings.Join(timelineTypes, "/"))
+		}
+		include[t] = true
+	}
+	if len(include) == 0 {
+		for _, t := range timelineTypes {
+			include[t] = true
+		}
+	}
+
+	items := s.collect(accountID, include)
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].at.Equal(items[j].at) {
+			return items[i].at.After(items[j].at)
+		}
+		return items[i].key() > items[j].key()
+	})
+
+	start := 0
+	if after.Pos >= 0 {
+		at := time.Unix(int64(after.Pos), 0)
+		start = sort.Search(len(items), func(i int) bool {
+			return items[i].at.Before(at) || (items[i].at.Equal(at) && items[i].key() < after.ID)
+		})
+	}
+
+	page := TimelinePage{AccountID: accountID, Limit: limit, Items: make([]TimelineItem, 0, limit)}
+	for _, item := range items[start:] {
+		if len(page.Items) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Items = append(page.Items, item)
+	}
+	if page.HasMore {
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = pageCursor{Pos: int(last.at.Unix()), ID: last.key()}.String()
+	}
+	return page, nil
+}
+
+// 汇总各类动态（时间统一精确到秒）
+func (s *TimelineService) collect(accountID string, include map[string]bool) []TimelineItem {
+	var items []TimelineItem
+	add := func(t, refID, title string, at time.Time, data interface{}) {
+		at = at.Truncate(time.Second)
+		items = append(items, TimelineItem{Type: t, Time: at.Format("2006-01-02 15:04:05"), Title: title, RefID: refID, Data: data, at: at})
+	}
+
+	if include[TIMELINE_TRANSACTION] {
+		for _, tx := range s.ledger.Transactions(accountID, time.Time{}, time.Time{}) {
+			sign := "+"
+			if tx.Direction == "debit" {
+				sign = "-"
+			}
+			add(TIMELINE_TRANSACTION, tx.TxID, fmt.Sprintf("%s %s%.2f %s", tx.Description, sign, tx.Amount, tx.Currency), tx.Time, tx)
+		}
+	}
+	if include[TIMELINE_LOGIN] {
+		for _, attempt := range s.risk.LoginAttempts(LoginAttemptQuery{AccountID: accountID}) {
+			title := "登录成功"
+			if attempt.Result != LOGIN_RESULT_SUCCESS {
+				title = "登录失败"
+			}
+			title += "（IP " + attempt.IP
+			if attempt.DeviceID != "" {
+				title += "，设备 " + attempt.DeviceID
+			}
+			title += "）"
+			add(TIMELINE_LOGIN, attempt.AttemptID, title, parseLocalTime(attempt.Time), attempt)
+		}
+	}
+	if include[TIMELINE_SECURITY] {
+		for _, event := range s.risk.Events(accountID) {
+			title := fmt.Sprintf("风控事件：%s（%s）", strings.Join(event.Rules, "、"), event.Outcome)
+			add(TIMELINE_SECURITY, event.EventID, title, parseLocalTime(event.Time), event)
+		}
+	}
+	if include[TIMELINE_STATUS] {
+		for _, entry := range s.audit.List(AuditQuery{Target: accountID, Action: AUDIT_ACCOUNT_STATUS_CHANGED}) {
+			title := "账户状态变更：" + entry.Detail["from"] + " → " + entry.Detail["to"]
+			if reason := entry.Detail["reason"]; reason != "" {
+				title += "（" + reason + "）"
+			}
+			add(TIMELINE_STATUS, entry.AuditID, title, parseLocalTime(entry.Time), entry)
+		}
+	}
+	if include[TIMELINE_NOTIFICATION] {
+		for _, notification := range s.notifications.List(accountID, false).Items {
+			add(TIMELINE_NOTIFICATION, notification.ID, notification.Message, parseLocalTime(notification.CreatedAt), notification)
+		}
+	}
+	return items
+}
+
+// 解析 2006-01-02 15:04:05 格式的本地时间（记录中的时间字段），格式错误时返回零值
+func parseLocalTime(value string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+	return t
+}
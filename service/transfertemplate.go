@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/repository"
+)
+
+// 单账户转账模板数上限
+const MAX_TEMPLATES_PER_ACCOUNT = 50
+
+// 转账模板（常用收款人）：保存收款账户、金额与附言，执行时按模板发起行内转账
+type TransferTemplate struct {
+	TemplateID string  `json:"templateId"`
+	AccountID  string  `json:"accountId"` // 转出账户
+	Name       string  `json:"name"`      // 模板名称，默认为收款人户名
+	ToAccount  string  `json:"toAccount"`
+	PayeeName  string  `json:"payeeName"` // 收款人户名（创建时取自收款账户）
+	Amount     float64 `json:"amount"`    // 0 表示执行时指定金额
+	UseCount   int     `json:"useCount"`  // 成功执行次数
+	LastUsedAt string  `json:"lastUsedAt,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+	model.Remittance
+}
+
+// 创建转账模板请求
+type TransferTemplateRequest struct {
+	AccountID string  `json:"accountId" validate:"required,account"`
+	Name      string  `json:"name"`
+	ToAccount string  `json:"toAccount" validate:"required,account"`
+	Amount    float64 `json:"amount" validate:"min=0"`
+	model.Remittance
+}
+
+// 修改转账模板请求（字段为空表示不变）
+type TransferTemplateUpdateRequest struct {
+	Name        *string  `json:"name" validate:"omitempty,required"`
+	Amount      *float64 `json:"amount" validate:"omitempty,min=0"`
+	Memo        *string  `json:"memo"`
+	PurposeCode *string  `json:"purposeCode"`
+}
+
+// 执行转账模板请求：金额为 0 时使用模板金额
+type TemplateExecuteRequest struct {
+	Amount     float64 `json:"amount"`
+	EndToEndID string  `json:"endToEndId"`
+	OTPCode    string  `json:"otpCode"`
+}
+
+// 转账模板服务：用户保存常用转账，一次请求即可按模板转账，并按使用次数排序
+type TransferTemplateService struct {
+	accounts *repository.AccountRepository
+	clock    Clock
+
+	mu        sync.Mutex // 叶子锁
+	templates map[string]*TransferTemplate
+	seq       int64
+}
+
+func NewTransferTemplateService(accounts *repository.AccountRepository, clock Clock) *TransferTemplateService {
+	return &TransferTemplateService{
+		accounts:  accounts,
+		clock:     clock,
+		templates: make(map[string]*TransferTemplate),
+	}
+}
+
+// 创建转账模板
+func (s *TransferTemplateService) Create(req TransferTemplateRequest) (TransferTemplate, error) {
+	if err := model.Validate(req); err != nil {
+		return TransferTemplate{}, err
+	}
+	if _, exists := s.accounts.Get(req.AccountID); !exists {
+		return TransferTemplate{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "转出账户不存在")
+	}
+	payee, exists := s.accounts.Get(req.ToAccount)
+	if !exists {
+		return TransferTemplate{}, model.NewError(model.CODE_ACCOUNT_NOT_EXIST, "收款账户不存在")
+	}
+	if req.ToAccount == req.AccountID {
+		return TransferTemplate{}, model.NewError(model.CODE_PARAM_ERROR, "收款账户不能与转出账户相同")
+	}
+	req.EndToEndID = "" // 端到端参考号每笔不同，执行时指定
+	if err := validateRemittance(&req.Remittance); err != nil {
+		return TransferTemplate{}, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = payee.UserName
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, template := range s.templates {
+		if template.AccountID == req.AccountID {
+			count++
+		}
+	}
+	if count >= MAX_TEMPLATES_PER_ACCOUNT {
+		return TransferTemplate{}, model.NewError(model.CODE_PARAM_ERROR, fmt.Sprintf("每个账户最多保存 %d 个转账模板", MAX_TEMPLATES_PER_ACCOUNT))
+	}
+	now := s.clock.Now().Format("2006-01-02 15:04:05")
+	s.seq++
+	template := &TransferTemplate{
+		TemplateID: fmt.Sprintf("TT%08d", s.seq),
+		AccountID:  req.AccountID,
+		Name:       name,
+		ToAccount:  req.ToAccount,
+		PayeeName:  payee.UserName,
+		Amount:     model.RoundAmount(req.Amount),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Remittance: req.Remittance,
+	}
+	s.templates[template.TemplateID] = template
+
+	// 终端提示：转账模板创建
+	log.Println("\n[⭐ 转账模板创建]")
+	log.Printf("模板ID: %s（%s）", template.TemplateID, template.Name)
+	log.Printf("转出账户: %s → 收款账户: %s（%s）", template.AccountID, template.ToAccount, template.PayeeName)
+	log.Printf("金额: %.2f", template.Amount)
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	return *template, nil
+}
+
+// 账户的转账模板：按使用次数倒序，次数相同时最近使用的在前
+func (s *TransferTemplateService) List(accountID string) []TransferTemplate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]TransferTemplate, 0)
+	for _, template := range s.templates {
+		if template.AccountID == accountID {
+			result = append(result, *template)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].UseCount != result[j].UseCount {
+			return result[i].UseCount > result[j].UseCount
+		}
+		if result[i].LastUsedAt != result[j].LastUsedAt {
+			return result[i].LastUsedAt > result[j].LastUsedAt
+		}
+		return result[i].TemplateID < result[j].TemplateID
+	})
+	return result
+}
+
+// 查询转账模板
+func (s *TransferTemplateService) Get(templateID string) (TransferTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.templates[templateID]
+	if !ok {
+		return TransferTemplate{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "转账模板不存在")
+	}
+	return *template, nil
+}
+
+// 修改模板名称、金额或附言
+func (s *TransferTemplateService) Update(templateID string, req TransferTemplateUpdateRequest) (TransferTemplate, error) {
+	if err := model.Validate(req); err != nil {
+		return TransferTemplate{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.templates[templateID]
+	if !ok {
+		return TransferTemplate{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "转账模板不存在")
+	}
+	updated := *template
+	if req.Name != nil {
+		updated.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Amount != nil {
+		updated.Amount = model.RoundAmount(*req.Amount)
+	}
+	if req.Memo != nil {
+		updated.Memo = *req.Memo
+	}
+	if req.PurposeCode != nil {
+		updated.PurposeCode = *req.PurposeCode
+	}
+	if err := validateRemittance(&updated.Remittance); err != nil {
+		return TransferTemplate{}, err
+	}
+	updated.UpdatedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	*template = updated
+	return updated, nil
+}
+
+// 删除转账模板
+func (s *TransferTemplateService) Delete(templateID string) (TransferTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.templates[templateID]
+	if !ok {
+		return TransferTemplate{}, model.NewError(model.CODE_RESOURCE_NOT_FOUND, "转账模板不存在")
+	}
+	delete(s.templates, templateID)
+	return *template, nil
+}
+
+// 按模板生成转账请求（执行金额为 0 时使用模板金额）
+func (s *TransferTemplateService) TransferRequest(templateID string, req TemplateExecuteRequest) (TransferRequest, error) {
+	template, err := s.Get(templateID)
+	if err != nil {
+		return TransferRequest{}, err
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = template.Amount
+	}
+	if amount <= 0 {
+		return TransferRequest{}, model.NewError(model.CODE_PARAM_ERROR, "转账金额必须大于0（模板未设置金额时须在执行时指定）")
+	}
+	remittance := template.Remittance
+	remittance.EndToEndID = req.EndToEndID
+	return TransferRequest{
+		FromAccount: template.AccountID,
+		ToAccount:   template.ToAccount,
+		Amount:      amount,
+		OTPCode:     req.OTPCode,
+		Remittance:  remittance,
+	}, nil
+}
+
+// 记录一次成功执行（模板已删除时忽略）
+func (s *TransferTemplateService) RecordUse(templateID string) (TransferTemplate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	template, ok := s.templates[templateID]
+	if !ok {
+		return TransferTemplate{}, false
+	}
+	template.UseCount++
+	template.LastUsedAt = s.clock.Now().Format("2006-01-02 15:04:05")
+	return *template, true
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/Taworshine/DigitalBankCoreBusinessSimulationSystem/model"
+)
+
+// 资金头寸配置
+type TreasuryConfig struct {
+	ReserveRatio   float64 // 准备金率：流动性资产（库存现金 + 存放央行）不得低于客户存款的该比例
+	QueueThreshold float64 // 大额清算门槛：达到该金额的跨行转出须通过流动性检查，否则排队等待
+}
+
+// 调整资金头寸配置请求结构体（字段为空表示不修改）
+type TreasuryConfigRequest struct {
+	ReserveRatio   *float64 `json:"reserveRatio" validate:"omitempty,min=0,max=1"`
+	QueueThreshold *float64 `json:"queueThreshold" validate:"omitempty,min=0"`
+}
+
+// 人民币流动性头寸
+type LiquidityPosition struct {
+	Currency         string  `json:"currency"`
+	Cash             float64 `json:"cash"`             // 库存现金
+	CentralBank      float64 `json:"centralBank"`      // 存放中央银行款项（清算备付金）
+	LiquidAssets     float64 `json:"liquidAssets"`     // 流动性资产 = 库存现金 + 存放央行
+	CustomerDeposits float64 `json:"customerDeposits"` // 客户存款（负债）
+	PendingClearing  float64 `json:"pendingClearing"`  // 已扣款待清算的跨行转出
+	ReserveRatio     float64 `json:"reserveRatio"`
+	RequiredReserve  float64 `json:"requiredReserve"` // 应缴准备金 = 客户存款 × 准备金率
+	ExcessReserve    float64 `json:"excessReserve"`   // 超额准备金 = 流动性资产 - 应缴准备金，可用于大额清算
+	LiquidityRatio   float64 `json:"liquidityRatio"`  // 流动性资产 / 客户存款
+}
+
+// 资金头寸看板
+type TreasuryDashboard struct {
+	GeneratedAt    string             `json:"generatedAt"`
+	QueueThreshold float64            `json:"queueThreshold"`
+	Position       LiquidityPosition  `json:"position"`
+	Queued         []InterbankPayment `json:"queued"` // 因流动性不足排队的跨行清算（本行准备金约束或央行 RTGS 头寸不足）
+	QueuedCount    int                `json:"queuedCount"`
+	QueuedAmount   float64            `json:"queuedAmount"`
+	Shortfall      float64            `json:"shortfall"` // 全部放行尚缺的流动性（排队金额 - 超额准备金，不足 0 记 0）
+}
+
+// 资金头寸服务：按总账跟踪全行流动性资产与客户存款，执行准备金率约束，
+// 流动性不足时大额跨行清算排队等待（由清算系统逐笔重试）
+type TreasuryService struct {
+	ledger *LedgerService
+	clock  Clock
+
+	mu  sync.Mutex
+	cfg TreasuryConfig
+}
+
+func NewTreasuryService(cfg TreasuryConfig, ledger *LedgerService, clock Clock) *TreasuryService {
+	return &TreasuryService{cfg: cfg, ledger: ledger, clock: clock}
+}
+
+// 当前配置
+func (s *TreasuryService) Config() TreasuryConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// 调整准备金率与大额清算门槛（立即生效，排队中的清算在下一次清算处理时按新配置重试）
+func (s *TreasuryService) SetConfig(req TreasuryConfigRequest) (TreasuryConfig, error) {
+	if err := model.Validate(req); err != nil {
+		return TreasuryConfig{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.ReserveRatio != nil {
+		s.cfg.ReserveRatio = *req.ReserveRatio
+	}
+	if req.QueueThreshold != nil {
+		s.cfg.QueueThreshold = *req.QueueThreshold
+	}
+	return s.cfg, nil
+}
+
+// 当前人民币流动性头寸（取自总账科目余额）
+func (s *TreasuryService) Position() LiquidityPosition {
+	cfg := s.Config()
+	position := LiquidityPosition{Currency: model.BASE_CURRENCY, ReserveRatio: cfg.ReserveRatio}
+	chart := s.ledger.Chart()
+	cash, centralBank := chart.Code(GL_CASH), chart.Code(GL_CENTRAL_BANK)
+	deposits, clearing := chart.Code(GL_CUSTOMER_DEPOSITS), chart.Code(GL_CLEARING)
+	for _, balance := range s.ledger.GLBalances() {
+		if balance.Currency != model.BASE_CURRENCY {
+			continue
+		}
+		switch balance.Code {
+		case cash:
+			position.Cash = balance.Balance
+		case centralBank:
+			position.CentralBank = balance.Balance
+		case deposits:
+			position.CustomerDeposits = balance.Balance
+		case clearing:
+			position.PendingClearing = balance.Balance
+		}
+	}
+	position.LiquidAssets = model.RoundAmount(position.Cash + position.CentralBank)
+	position.RequiredReserve = model.RoundAmount(position.CustomerDeposits * cfg.ReserveRatio)
+	position.ExcessReserve = model.RoundAmount(position.LiquidAssets - position.RequiredReserve)
+	if position.CustomerDeposits > 0 {
+		position.LiquidityRatio = math.Round(position.LiquidAssets/position.CustomerDeposits*10000) / 10000
+	}
+	return position
+}
+
+// 大额清算流动性检查：未达门槛直接放行；达到门槛时，清算后流动性资产仍须不低于应缴准备金
+func (s *TreasuryService) Admit(amount float64) (bool, LiquidityPosition) {
+	if amount < s.Config().QueueThreshold {
+		return true, LiquidityPosition{}
+	}
+	position := s.Position()
+	return model.RoundAmount(position.LiquidAssets-amount) >= position.RequiredReserve, position
+}
+
+// 资金头寸看板：流动性头寸与排队中的大额清算
+func (s *TreasuryService) Dashboard(queued []InterbankPayment) TreasuryDashboard {
+	sort.Slice(queued, func(i, j int) bool { return queued[i].Reference < queued[j].Reference })
+	dashboard := TreasuryDashboard{
+		GeneratedAt:    s.clock.Now().Format("2006-01-02 15:04:05"),
+		QueueThreshold: s.Config().QueueThreshold,
+		Position:       s.Position(),
+		Queued:         queued,
+		QueuedCount:    len(queued),
+	}
+	for _, payment := range queued {
+		dashboard.QueuedAmount += payment.Amount
+	}
+	dashboard.QueuedAmount = model.RoundAmount(dashboard.QueuedAmount)
+	if shortfall := model.RoundAmount(dashboard.QueuedAmount - dashboard.Position.ExcessReserve); shortfall > 0 && dashboard.QueuedCount > 0 {
+		dashboard.Shortfall = shortfall
+	}
+	return dashboard
+}
@@ -0,0 +1,61 @@
+package sim
+
+import (
+	"sync"
+	"time"
+)
+
+// 模拟时钟：以可配置倍速推进，并支持手动快进，驱动日终等定时业务
+type Clock struct {
+	mu        sync.RWMutex
+	realStart time.Time     // 时钟启动的真实时间
+	simStart  time.Time     // 时钟启动时的模拟时间
+	speed     float64       // 倍速（1 表示与真实时间同步）
+	offset    time.Duration // 手动快进的累计时长
+}
+
+// 创建从当前时间开始、按 speed 倍速推进的模拟时钟
+func NewClock(speed float64) *Clock {
+	now := time.Now()
+	return &Clock{realStart: now, simStart: now, speed: speed}
+}
+
+// 当前模拟时间
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	elapsed := time.Duration(float64(time.Since(c.realStart)) * c.speed)
+	return c.simStart.Add(elapsed + c.offset)
+}
+
+// 时钟倍速
+func (c *Clock) Speed() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.speed
+}
+
+// 快进模拟时钟，返回快进前后的模拟时间
+func (c *Clock) Advance(d time.Duration) (before, after time.Time) {
+	before = c.Now()
+	c.mu.Lock()
+	c.offset += d
+	c.mu.Unlock()
+	return before, c.Now()
+}
+
+// 将模拟时钟调整到指定时间（可回拨，如恢复状态快照），此后继续按倍速推进
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := time.Duration(float64(time.Since(c.realStart)) * c.speed)
+	c.offset = t.Sub(c.simStart.Add(elapsed))
+}
+
+// 模拟日期的零点
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,124 @@
+// Package ws 实现 WebSocket 实时推送（余额变动、交易提醒、行情等主题消息）。
+//
+// # 消息格式版本
+//
+// 客户端可在连接时通过查询参数 ?version=N 指定消息格式版本，
+// 也可在订阅指令中携带 version 字段随时切换（topic 可为空，仅切换格式）：
+//
+//	{"action": "subscribe", "topic": "rates", "version": 2}
+//
+// 未协商时使用 v1，已有客户端无需任何改动。
+//
+// v1（旧版扁平消息）：
+//
+//	{
+//	  "type": "balanceUpdate",     // balanceUpdate/transactionAlert/securityAlert/balanceAlert/broadcast/supportChat/rateUpdate 等
+//	  "topic": "rates",            // 可选，主题推送时为主题名
+//	  "accountId": "8001234567",   // 可选，消息所属账户
+//	  "notificationId": "N...",    // 可选，对应通知中心记录
+//	  "newBalance": 1000.00,       // 可选
+//	  "message": "...",            // 可选
+//	  "data": {...}                // 可选，消息类型相关的结构化数据
+//	}
+//
+// v2（版本化信封）：
+//
+//	{
+//	  "version": 2,
+//	  "type": "balanceUpdate",
+//	  "id": "42",                              // 消息唯一编号（进程内单调递增）
+//	  "timestamp": "2026-10-15T09:30:00.000+08:00",
+//	  "topic": "rates",                        // 可选
+//	  "accountId": "8001234567",               // 可选
+//	  "payload": {
+//	    "notificationId": "N...",              // 可选
+//	    "newBalance": 1000.00,                 // 可选
+//	    "message": "...",                      // 可选
+//	    "data": {...}                          // 可选
+//	  }
+//	}
+//
+// # 编码方式
+//
+// 默认使用 JSON 文本帧。高频推送场景可在连接时通过 ?encoding=msgpack 或在订阅指令中携带
+// encoding 字段切换为 MessagePack 二进制帧，与版本协商可同时进行：
+//
+//	{"action": "subscribe", "topic": "rates", "version": 2, "encoding": "msgpack"}
+//
+// MessagePack 消息的字段名与结构与对应版本的 JSON 消息完全一致（对象键按字典序输出），
+// 整数按最短格式编码，小数编码为 float64。
+//
+// # 断线重连补发
+//
+// 连接时携带登录令牌 ?token= 即为账户连接（账户取自登录会话，会话终止时连接随之断开）：
+// 只接收本账户消息、全员广播及已订阅主题消息。未配置令牌校验的独立推送中心可改用 ?accountId=X 绑定账户。
+// 未绑定账户的连接（匿名连接、管理员连接）不接收任何账户消息，只接收全员广播及已订阅主题消息。
+// 账户消息（非主题消息）带有账户内单调递增的事件序号 seq（v1 为顶层 seq 字段，v2 为信封 seq 字段），
+// 服务端为每个账户保留最近 WS_REPLAY_BUFFER 条事件。客户端断线后携带最后收到的序号重连：
+//
+//	/ws?token=<登录令牌>&lastEventId=42
+//
+// 服务端先补发序号 42 之后的事件，再继续实时推送，补发与实时推送之间不重复、不遗漏。
+// 若所需事件已超出缓冲区（或服务端重启导致序号重置），先推送一条 resyncRequired 消息：
+//
+//	{"type": "resyncRequired", "accountId": "8001234567",
+//	 "data": {"lastEventId": 42, "oldestEventId": 300, "latestEventId": 555}}
+//
+// 客户端应据此通过通知中心与交易查询接口全量同步。主题消息与全员广播不分配序号，不参与补发。
+//
+// # 长轮询
+//
+// 网络环境拦截 WebSocket 时，可改用 GET /api/events/poll?since=N 长轮询当前登录账户的事件。
+// 服务端返回缓冲区中序号 N 之后的事件，暂无新事件时最多等待 30 秒（?wait= 可缩短），期间有新事件立即返回：
+//
+//	{"accountId": "8001234567", "events": [{"type": "balanceUpdate", "seq": 43, ...}],
+//	 "latestEventId": 43, "oldestEventId": 1}
+//
+// 事件格式与 WebSocket 推送相同（?version= 指定版本），latestEventId 作为下一次请求的 since；
+// 未携带 since 时从当前最新事件开始等待。所需事件已超出缓冲区时 resyncRequired 为 true，处理方式同上。
+//
+// # 账户连接数限制
+//
+// 同一账户的并发连接数上限为 WS_MAX_CONNECTIONS_PER_ACCOUNT（0 表示不限）。超限时按
+// WS_CONNECTION_LIMIT_POLICY 处理：reject 拒绝新连接，evict_oldest（默认）断开该账户最早的连接。
+// 被拒绝或被断开的连接会收到关闭码 1008（Policy Violation）及关闭原因说明。
+//
+// # 会话控制
+//
+// 登录后以 ?token=<登录令牌> 建立的连接绑定到登录会话（账户取自会话）。退出登录、管理员强制下线、
+// 要求重新认证或冻结账户时，服务端先推送控制消息，再以关闭码 1008 断开连接：
+//
+//	{"type": "sessionControl", "accountId": "8001234567", "message": "已被强制退出登录",
+//	 "data": {"command": "forceLogout", "reason": "..."}}
+//
+// command 取值 logout/forceLogout/reauthRequired/accountFrozen，收到后客户端应清除令牌并引导用户重新登录。
+//
+// # 管理员交易流水
+//
+// 以 ?adminKey=<WS_ADMIN_KEY> 建立的连接为管理员连接（不可同时指定 accountId 或登录令牌，密钥错误返回 403），
+// 仅管理员连接可订阅 admin. 前缀主题。主题 admin.transactions 实时推送全行每一笔交易流水，
+// 订阅时可携带过滤条件（按账户、按折合人民币金额阈值），再次订阅同一主题时替换原条件：
+//
+//	{"action": "subscribe", "topic": "admin.transactions", "filter": {"accountId": "8001234567", "minAmount": 10000}}
+//
+// 消息类型为 transaction，data 为交易流水字段（txId/accountId/type/direction/amount/currency/balanceAfter 等）
+// 外加 amountBase（折合人民币金额）。
+//
+// # 在线客服
+//
+// 账户连接可发送 chat 指令联系在线客服，同一账户未结束的会话只有一个，首条消息自动开启会话：
+//
+//	{"action": "chat", "text": "转账一直显示处理中"}
+//
+// 管理员连接订阅 admin.support 主题维护会话队列，消息类型为 supportChat，
+// data 为 {"event": "message|closed", "conversation": {...}, "message": {...}}；
+// 在管理员连接上发送 chat 指令即回复指定会话：
+//
+//	{"action": "chat", "conversationId": "SC20261015000001", "agent": "客服小王", "text": "请提供交易流水号"}
+//
+// 客服回复以 supportChat 类型推送给客户并存入通知中心（计入未读数），data 为该条消息。
+// chat 指令处理失败不回送错误，客户端可通过 /api/support/chat 查询会话确认送达。
+//
+// 兼容性约定：同一版本内只新增可选字段，不删除、不改名、不改变字段类型；
+// 破坏性变更发布为新版本，旧版本继续按原格式推送。
+package ws
@@ -0,0 +1,88 @@
+package ws
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// 消息格式版本
+const (
+	SCHEMA_V1      = 1         // 旧版扁平消息（Message 直接序列化），未协商时的默认版本
+	SCHEMA_V2      = 2         // 版本化信封（Envelope）
+	SCHEMA_DEFAULT = SCHEMA_V1 // 默认版本，保证未升级的客户端不受影响
+	SCHEMA_LATEST  = SCHEMA_V2 // 当前最新版本
+)
+
+// 消息编码方式
+const (
+	ENCODING_JSON    = "json"    // JSON 文本帧（默认）
+	ENCODING_MSGPACK = "msgpack" // MessagePack 二进制帧，结构与 JSON 一致，适合高频推送
+)
+
+// 客户端协商的消息格式：版本 + 编码方式
+type format struct {
+	version  int
+	encoding string
+}
+
+// v2 消息信封：所有推送统一包装为 version/type/id/timestamp/payload，
+// 新增字段只追加到 payload，破坏性变更通过新版本号发布
+type Envelope struct {
+	Version   int     `json:"version"`
+	Type      string  `json:"type"`                // 同 Message.Type
+	ID        string  `json:"id"`                  // 消息唯一编号（进程内单调递增）
+	Timestamp string  `json:"timestamp"`           // 服务器推送时间，RFC 3339（毫秒）
+	Topic     string  `json:"topic,omitempty"`     // 主题推送时为主题名
+	AccountID string  `json:"accountId,omitempty"` // 消息所属账户，为空表示面向全部用户
+	Seq       uint64  `json:"seq,omitempty"`       // 账户事件序号（同 Message.Seq）
+	Payload   Payload `json:"payload"`
+}
+
+// v2 消息负载
+type Payload struct {
+	NotificationID string      `json:"notificationId,omitempty"`
+	NewBalance     float64     `json:"newBalance,omitempty"`
+	Message        string      `json:"message,omitempty"`
+	Data           interface{} `json:"data,omitempty"`
+}
+
+// 是否为服务端支持的消息版本
+func SupportedVersion(version int) bool {
+	return version >= SCHEMA_V1 && version <= SCHEMA_LATEST
+}
+
+// 是否为服务端支持的编码方式
+func SupportedEncoding(encoding string) bool {
+	return encoding == ENCODING_JSON || encoding == ENCODING_MSGPACK
+}
+
+// 按各支持版本分别序列化同一条消息的 JSON 格式（每条广播只编码一次，按客户端协商格式分发；二进制格式由 hub 按需转码）
+func encodeVersions(msg Message, id uint64, now time.Time) (map[format][]byte, error) {
+	v1, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := json.Marshal(Envelope{
+		Version:   SCHEMA_V2,
+		Type:      msg.Type,
+		ID:        strconv.FormatUint(id, 10),
+		Timestamp: now.Format("2006-01-02T15:04:05.000Z07:00"),
+		Topic:     msg.Topic,
+		AccountID: msg.AccountID,
+		Seq:       msg.Seq,
+		Payload: Payload{
+			NotificationID: msg.NotificationID,
+			NewBalance:     msg.NewBalance,
+			Message:        msg.Message,
+			Data:           msg.Data,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[format][]byte{
+		{SCHEMA_V1, ENCODING_JSON}: v1,
+		{SCHEMA_V2, ENCODING_JSON}: v2,
+	}, nil
+}
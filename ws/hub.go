@@ -0,0 +1,1035 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 消息结构体
+type Message struct {
+	Type           string      `json:"type"`                     // balanceUpdate/transactionAlert/securityAlert/balanceAlert/broadcast/supportChat/rateUpdate
+	Topic          string      `json:"topic,omitempty"`          // 为空表示推送给所有客户端，否则仅推送给订阅者
+	AccountID      string      `json:"accountId,omitempty"`      // 消息所属账户，为空表示面向全部用户
+	NotificationID string      `json:"notificationId,omitempty"` // 对应的通知中心记录（可据此标记已读）
+	NewBalance     float64     `json:"newBalance,omitempty"`
+	Message        string      `json:"message,omitempty"`
+	Data           interface{} `json:"data,omitempty"`
+	Seq            uint64      `json:"seq,omitempty"` // 账户事件序号（同一账户内单调递增，断线重连时作为 lastEventId）
+
+	Event string                 `json:"-"` // 消息事件类型（由消息模板生成时携带，供短信等通道按各自模板渲染）
+	Vars  map[string]interface{} `json:"-"` // 模板变量
+}
+
+// WebSocket 客户端指令（订阅/退订主题，订阅时可协商消息格式版本与编码方式；chat 发送在线客服消息）
+type ClientMessage struct {
+	Action   string  `json:"action"`             // subscribe/unsubscribe/chat
+	Topic    string  `json:"topic"`              // 如 rates
+	Version  int     `json:"version,omitempty"`  // 消息格式版本（见 SCHEMA_*），0 表示不变
+	Encoding string  `json:"encoding,omitempty"` // 消息编码方式 json/msgpack，为空表示不变
+	Filter   *Filter `json:"filter,omitempty"`   // 主题过滤条件，订阅时为空表示不过滤
+
+	ConversationID string `json:"conversationId,omitempty"` // chat：管理员回复的会话编号
+	Agent          string `json:"agent,omitempty"`          // chat：管理员回复时的客服名称
+	Text           string `json:"text,omitempty"`           // chat：消息内容
+}
+
+// 主题订阅过滤条件（仅对负载实现 Filterable 的主题消息生效，如管理员交易流水主题）
+type Filter struct {
+	AccountID string  `json:"accountId,omitempty"` // 只接收该账户的消息
+	MinAmount float64 `json:"minAmount,omitempty"` // 只接收金额不低于该值的消息（按负载给出的折算金额比较）
+}
+
+// 可按 Filter 过滤的主题消息负载
+type Filterable interface {
+	FilterFields() (accountID string, amount float64)
+}
+
+// 管理员主题前缀：仅管理员连接（?adminKey=）可订阅
+const ADMIN_TOPIC_PREFIX = "admin."
+
+// 管理员交易流水主题：实时推送全行每一笔交易流水
+const TOPIC_ADMIN_TRANSACTIONS = ADMIN_TOPIC_PREFIX + "transactions"
+
+// 管理员在线客服主题：推送客户新消息、客服回复与会话结束，供客服工作台维护会话队列
+const TOPIC_ADMIN_SUPPORT = ADMIN_TOPIC_PREFIX + "support"
+
+// 账户连接数超限策略
+const (
+	LIMIT_REJECT       = "reject"       // 拒绝新连接
+	LIMIT_EVICT_OLDEST = "evict_oldest" // 断开该账户最早建立的连接
+)
+
+// 连接关闭原因（WebSocket 关闭帧 1008 Policy Violation 携带的说明）
+const (
+	CLOSE_REASON_LIMIT   = "账户连接数已达上限"
+	CLOSE_REASON_EVICTED = "已被同账户新连接替换"
+)
+
+// 连接断开原因
+const (
+	DISCONNECT_CLIENT_CLOSED = "client_closed" // 客户端主动关闭
+	DISCONNECT_READ_ERROR    = "read_error"    // 读取异常（网络中断等）
+	DISCONNECT_WRITE_ERROR   = "write_error"   // 写出失败
+	DISCONNECT_SLOW_CONSUMER = "slow_consumer" // 发送队列写满
+	DISCONNECT_EVICTED       = "evicted"       // 账户连接数超限被新连接替换
+	DISCONNECT_REJECTED      = "rejected"      // 账户连接数超限被拒绝
+	DISCONNECT_TERMINATED    = "terminated"    // 会话被终止（退出登录、强制下线、账户冻结等）
+	DISCONNECT_CHAOS         = "chaos"         // 故障注入断开（模拟断线风暴）
+)
+
+// WebSocket 推送配置
+type Config struct {
+	SendBuffer               int           // 单客户端发送队列长度
+	WriteTimeout             time.Duration // 单条消息写超时
+	ReplayBuffer             int           // 单账户断线重连补发缓冲区长度（0 表示不补发）
+	MaxConnectionsPerAccount int           // 单账户最大并发连接数（0 表示不限）
+	ConnectionLimitPolicy    string        // 超限策略 reject/evict_oldest
+	AdminKey                 string        // 管理员连接密钥（?adminKey=），为空表示不允许管理员连接
+	Compression              bool          // 启用 permessage-deflate 扩展（客户端同时支持时生效）
+	CompressionLevel         int           // 压缩级别 1~9，0 表示默认级别
+}
+
+// WebSocket 推送统计
+type Metrics struct {
+	Clients           int64 `json:"clients"`           // 当前在线客户端数
+	Connections       int64 `json:"connections"`       // 累计连接数
+	Broadcasts        int64 `json:"broadcasts"`        // 累计广播次数
+	MessagesQueued    int64 `json:"messagesQueued"`    // 累计入队消息数
+	MessagesSent      int64 `json:"messagesSent"`      // 累计写出消息数
+	SlowDisconnects   int64 `json:"slowDisconnects"`   // 因发送队列写满被断开的客户端数
+	WriteErrors       int64 `json:"writeErrors"`       // 写出失败次数
+	MessagesReplayed  int64 `json:"messagesReplayed"`  // 断线重连补发的消息数
+	ResyncRequired    int64 `json:"resyncRequired"`    // 补发缓冲区已覆盖所需事件、要求客户端全量同步的次数
+	LimitRejected     int64 `json:"limitRejected"`     // 因账户连接数超限被拒绝的连接数
+	LimitEvicted      int64 `json:"limitEvicted"`      // 因账户连接数超限被断开的旧连接数
+	MessagesDropped   int64 `json:"messagesDropped"`   // 未能送达而丢弃的消息数（队列写满、转码失败、断开时队列中剩余）
+	SendBufferSize    int   `json:"sendBufferSize"`    // 单客户端发送队列长度
+	ReplayBufferSize  int   `json:"replayBufferSize"`  // 单账户补发缓冲区长度
+	WriteTimeoutMilli int64 `json:"writeTimeoutMilli"` // 单条消息写超时（毫秒）
+	Compression       bool  `json:"compression"`       // 是否启用 permessage-deflate
+
+	MaxConnectionsPerAccount int            `json:"maxConnectionsPerAccount"` // 0 表示不限
+	ConnectionLimitPolicy    string         `json:"connectionLimitPolicy"`
+	AccountConnections       map[string]int `json:"accountConnections"` // 账户ID → 当前连接数
+
+	Disconnects map[string]int64 `json:"disconnects"` // 断开原因（见 DISCONNECT_*）→ 累计次数
+}
+
+// 在线连接详情
+type ConnectionInfo struct {
+	ID             string   `json:"id"`
+	AccountID      string   `json:"accountId,omitempty"`
+	SessionID      string   `json:"sessionId,omitempty"` // 以登录令牌建立的连接所属会话
+	Admin          bool     `json:"admin,omitempty"`     // 管理员连接
+	RemoteAddr     string   `json:"remoteAddr"`
+	ConnectedAt    string   `json:"connectedAt"`
+	Version        int      `json:"version"`
+	Encoding       string   `json:"encoding"`
+	Compressed     bool     `json:"compressed,omitempty"` // 已协商 permessage-deflate 压缩
+	Topics         []string `json:"topics"`
+	MessagesQueued int64    `json:"messagesQueued"`
+	MessagesSent   int64    `json:"messagesSent"`
+	Lag            int      `json:"lag"` // 发送队列中尚未写出的消息数
+	LastSentAt     string   `json:"lastSentAt,omitempty"`
+}
+
+// WebSocket 客户端（每个连接一个发送队列与写协程）
+type client struct {
+	id      string
+	conn    *websocket.Conn
+	send    chan frame        // 有界发送队列，写满视为慢客户端
+	topics  map[string]bool   // 已订阅主题（仅由 hub 协程访问）
+	filters map[string]Filter // 主题 → 过滤条件（仅由 hub 协程访问）
+	format  format            // 协商的消息格式（仅由 hub 协程访问）
+	admin   bool              // 管理员连接：可订阅 admin. 前缀主题
+
+	accountID   string // 账户连接：只接收本账户及全员广播消息，为空表示接收全部消息
+	sessionID   string // 以登录令牌建立的连接所属会话
+	lastEventID uint64 // 重连时客户端已收到的最后事件序号
+	resume      bool   // 是否携带 lastEventId 重连
+	compressed  bool   // 已协商 permessage-deflate 压缩
+	replayedTo  uint64 // 已补发的最大事件序号（之后广播中序号不超过该值的事件跳过，避免重复）
+
+	connectedAt time.Time
+	queued      int64 // 累计入队消息数（仅由 hub 协程访问）
+	sent        int64 // 累计写出消息数（原子读写）
+	lastSentAt  int64 // 最近写出时间（UnixNano，原子读写）
+}
+
+// 注销指令
+type disconnect struct {
+	client *client
+	reason string
+}
+
+// 会话终止指令：向账户（或指定会话）的连接推送控制消息后断开
+type termination struct {
+	accountID string
+	sessionID string // 为空表示该账户的全部连接
+	msg       broadcast
+	reason    string   // 关闭原因
+	reply     chan int // 返回断开的连接数
+}
+
+// 断线指令：按比例随机断开连接
+type dropRequest struct {
+	ratio float64
+	reply chan int // 返回断开的连接数
+}
+
+// 登录令牌校验：返回令牌所属账户与会话编号
+type Authenticator func(token string) (accountID, sessionID string, err error)
+
+// 在线客服消息处理：账户连接发送的消息 admin 为 false（accountID 为所属账户），管理员连接发送的为客服回复
+type ChatHandler func(accountID string, admin bool, cmd ClientMessage) error
+
+// 待写出的 WebSocket 帧
+type frame struct {
+	binary bool
+	close  bool // 关闭帧：写出后关闭连接
+	data   []byte
+}
+
+// 广播消息（topic 为空表示推送给所有客户端）
+type broadcast struct {
+	topic     string
+	accountID string
+	seq       uint64            // 账户事件序号（0 表示不参与补发）
+	frames    map[format][]byte // 消息格式 → 序列化结果（二进制格式在 hub 协程内按需转码并缓存）
+	versions  map[int][]byte    // 账户事件各版本的 JSON 序列化结果（只读副本，供长轮询在 hub 协程外读取）
+
+	filterable    bool // 负载实现 Filterable，按订阅者的过滤条件分发
+	filterAccount string
+	filterAmount  float64
+}
+
+// 订阅/退订指令（topic 为空时仅切换消息格式）
+type subscription struct {
+	client    *client
+	topic     string
+	subscribe bool
+	version   int
+	encoding  string
+	filter    *Filter
+}
+
+// WebSocket 中心：由单个协程维护客户端集合，负责注册、注销、订阅与广播分发
+type Hub struct {
+	clients     map[*client]bool
+	register    chan *client
+	unregister  chan disconnect
+	subscribe   chan subscription
+	broadcast   chan broadcast
+	inspect     chan chan []ConnectionInfo
+	terminate   chan termination
+	drop        chan dropRequest
+	auth        Authenticator // 为空表示不支持令牌连接
+	chat        ChatHandler   // 为空表示不支持在线客服消息
+	clientCount int64         // 原子读写，供日志与统计使用
+	nextID      uint64        // 消息编号（原子递增）
+	nextConnID  uint64        // 连接编号（原子递增）
+
+	cfg      Config
+	upgrader websocket.Upgrader
+	accounts map[string][]*client // 账户ID → 该账户的连接（按建立顺序，仅由 hub 协程访问）
+
+	countMu       sync.Mutex
+	accountCounts map[string]int // 账户连接数快照，供统计接口读取
+
+	historyMu sync.Mutex
+	history   map[string]*history // 账户ID → 最近事件
+
+	disconnectMu sync.Mutex
+	disconnects  map[string]int64 // 断开原因 → 累计次数
+
+	connections     int64
+	broadcasts      int64
+	messagesQueued  int64
+	messagesSent    int64
+	slowDisconnects int64
+	writeErrors     int64
+	replayed        int64
+	resyncs         int64
+	limitRejected   int64
+	limitEvicted    int64
+	dropped         int64
+}
+
+// 账户事件补发缓冲区（有界，超出后丢弃最早的事件）
+type history struct {
+	seq    uint64
+	events []broadcast
+	wake   chan struct{} // 有新事件时关闭并替换，唤醒等待中的长轮询请求
+}
+
+func NewHub(cfg Config) *Hub {
+	if cfg.ConnectionLimitPolicy != LIMIT_REJECT && cfg.ConnectionLimitPolicy != LIMIT_EVICT_OLDEST {
+		log.Printf("WebSocket 连接超限策略 %q 无效，使用 %s", cfg.ConnectionLimitPolicy, LIMIT_EVICT_OLDEST)
+		cfg.ConnectionLimitPolicy = LIMIT_EVICT_OLDEST
+	}
+	return &Hub{
+		clients:       make(map[*client]bool),
+		register:      make(chan *client),
+		unregister:    make(chan disconnect),
+		subscribe:     make(chan subscription),
+		broadcast:     make(chan broadcast, 256),
+		inspect:       make(chan chan []ConnectionInfo),
+		terminate:     make(chan termination),
+		drop:          make(chan dropRequest),
+		cfg:           cfg,
+		accounts:      make(map[string][]*client),
+		accountCounts: make(map[string]int),
+		history:       make(map[string]*history),
+		disconnects:   make(map[string]int64),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return true // 允许跨域（开发环境）
+			},
+			EnableCompression: cfg.Compression,
+		},
+	}
+}
+
+// hub 主循环（所有对 clients 的读写都在此协程内完成）
+func (h *Hub) Run() {
+	for {
+		select {
+		case c := <-h.register:
+			if !h.admit(c) {
+				continue
+			}
+			h.clients[c] = true
+			if c.accountID != "" {
+				h.accounts[c.accountID] = append(h.accounts[c.accountID], c)
+				h.updateAccountCount(c.accountID)
+			}
+			atomic.AddInt64(&h.connections, 1)
+			atomic.StoreInt64(&h.clientCount, int64(len(h.clients)))
+			if c.resume {
+				h.replay(c)
+			}
+
+		case d := <-h.unregister:
+			h.remove(d.client, d.reason)
+
+		case reply := <-h.inspect:
+			reply <- h.snapshot()
+
+		case t := <-h.terminate:
+			t.reply <- h.terminateClients(t)
+
+		case d := <-h.drop:
+			d.reply <- h.dropClients(d.ratio)
+
+		case sub := <-h.subscribe:
+			if !h.clients[sub.client] {
+				continue
+			}
+			if sub.version != 0 {
+				sub.client.format.version = sub.version
+			}
+			if sub.encoding != "" {
+				sub.client.format.encoding = sub.encoding
+			}
+			if sub.topic == "" {
+				continue
+			}
+			if sub.subscribe {
+				sub.client.topics[sub.topic] = true
+				if sub.filter != nil {
+					sub.client.filters[sub.topic] = *sub.filter
+				} else {
+					delete(sub.client.filters, sub.topic)
+				}
+			} else {
+				delete(sub.client.topics, sub.topic)
+				delete(sub.client.filters, sub.topic)
+			}
+
+		case msg := <-h.broadcast:
+			atomic.AddInt64(&h.broadcasts, 1)
+			for c := range h.clients {
+				if msg.topic != "" && !c.topics[msg.topic] {
+					continue
+				}
+				// 账户消息只发给该账户的连接；未绑定账户的连接（匿名、管理员）只接收全员广播与已订阅主题
+				if msg.accountID != "" && msg.accountID != c.accountID {
+					continue
+				}
+				if msg.seq != 0 && msg.seq <= c.replayedTo {
+					continue // 已在重连补发中送达
+				}
+				if filter, ok := c.filters[msg.topic]; ok && msg.filterable && !filter.match(msg.filterAccount, msg.filterAmount) {
+					continue
+				}
+				h.deliver(c, msg)
+			}
+		}
+	}
+}
+
+// 按客户端协商的格式将消息放入发送队列（仅在 hub 协程内调用）
+func (h *Hub) deliver(c *client, msg broadcast) bool {
+	data, err := msg.frame(c.format)
+	if err != nil {
+		log.Printf("WebSocket 消息转码失败（%s）: %v", c.format.encoding, err)
+		atomic.AddInt64(&h.dropped, 1)
+		return true
+	}
+	select {
+	case c.send <- frame{binary: c.format.encoding != ENCODING_JSON, data: data}:
+		atomic.AddInt64(&h.messagesQueued, 1)
+		c.queued++
+		return true
+	default:
+		// 发送队列已满：断开慢客户端，避免拖慢其他客户端
+		log.Printf("WebSocket 客户端 %s 发送队列已满，断开连接", c.conn.RemoteAddr())
+		atomic.AddInt64(&h.slowDisconnects, 1)
+		atomic.AddInt64(&h.dropped, 1)
+		h.remove(c, DISCONNECT_SLOW_CONSUMER)
+		return false
+	}
+}
+
+// 重连补发：推送账户 lastEventId 之后的事件；所需事件已被缓冲区覆盖时先推送 resyncRequired，
+// 提示客户端通过通知中心/交易查询接口全量同步（仅在 hub 协程内调用）
+func (h *Hub) replay(c *client) {
+	h.historyMu.Lock()
+	var events []broadcast
+	var latest, oldest uint64
+	if hist := h.history[c.accountID]; hist != nil {
+		latest = hist.seq
+		if len(hist.events) > 0 {
+			oldest = hist.events[0].seq
+		}
+		for _, event := range hist.events {
+			if event.seq > c.lastEventID {
+				events = append(events, event)
+			}
+		}
+	}
+	h.historyMu.Unlock()
+
+	c.replayedTo = latest
+	// 客户端序号超前（服务端重启后序号重置）或所需事件已被覆盖
+	if c.lastEventID > latest || c.lastEventID+1 < oldest {
+		atomic.AddInt64(&h.resyncs, 1)
+		frames, err := h.encode(Message{Type: "resyncRequired", AccountID: c.accountID, Data: map[string]uint64{
+			"lastEventId":   c.lastEventID,
+			"oldestEventId": oldest,
+			"latestEventId": latest,
+		}})
+		if err != nil || !h.deliver(c, broadcast{accountID: c.accountID, frames: frames}) {
+			return
+		}
+	}
+	for _, event := range events {
+		if !h.deliver(c, event) {
+			return
+		}
+		atomic.AddInt64(&h.replayed, 1)
+	}
+	if len(events) > 0 {
+		log.Printf("WebSocket 客户端 %s 重连补发账户 %s 事件 %d 条（序号 %d 之后）", c.conn.RemoteAddr(), c.accountID, len(events), c.lastEventID)
+	}
+}
+
+// 账户连接数检查：未超限时放行；超限时按策略拒绝新连接或断开最早的连接（仅在 hub 协程内调用）
+func (h *Hub) admit(c *client) bool {
+	limit := h.cfg.MaxConnectionsPerAccount
+	if c.accountID == "" || limit <= 0 || len(h.accounts[c.accountID]) < limit {
+		return true
+	}
+	if h.cfg.ConnectionLimitPolicy == LIMIT_REJECT {
+		log.Printf("WebSocket 账户 %s 连接数已达上限 %d，拒绝新连接 %s", c.accountID, limit, c.conn.RemoteAddr())
+		atomic.AddInt64(&h.limitRejected, 1)
+		h.countDisconnect(DISCONNECT_REJECTED)
+		select {
+		case c.send <- closeFrame(CLOSE_REASON_LIMIT):
+		default:
+		}
+		close(c.send)
+		return false
+	}
+	for len(h.accounts[c.accountID]) >= limit {
+		oldest := h.accounts[c.accountID][0]
+		log.Printf("WebSocket 账户 %s 连接数已达上限 %d，断开最早的连接 %s", c.accountID, limit, oldest.conn.RemoteAddr())
+		atomic.AddInt64(&h.limitEvicted, 1)
+		select {
+		case oldest.send <- closeFrame(CLOSE_REASON_EVICTED):
+		default:
+		}
+		h.remove(oldest, DISCONNECT_EVICTED)
+	}
+	return true
+}
+
+// 消息是否满足过滤条件
+func (f Filter) match(accountID string, amount float64) bool {
+	if f.AccountID != "" && f.AccountID != accountID {
+		return false
+	}
+	return amount >= f.MinAmount
+}
+
+// 关闭帧（1008 Policy Violation）
+func closeFrame(reason string) frame {
+	return frame{close: true, data: websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)}
+}
+
+// 移除客户端并关闭其发送队列（写协程随之退出并关闭连接）
+func (h *Hub) remove(c *client, reason string) {
+	if !h.clients[c] {
+		return
+	}
+	delete(h.clients, c)
+	h.countDisconnect(reason)
+	close(c.send)
+	atomic.StoreInt64(&h.clientCount, int64(len(h.clients)))
+
+	if c.accountID != "" {
+		conns := h.accounts[c.accountID]
+		for i, conn := range conns {
+			if conn == c {
+				h.accounts[c.accountID] = append(conns[:i:i], conns[i+1:]...)
+				break
+			}
+		}
+		if len(h.accounts[c.accountID]) == 0 {
+			delete(h.accounts, c.accountID)
+		}
+		h.updateAccountCount(c.accountID)
+	}
+}
+
+// 推送控制消息并断开匹配的连接（仅在 hub 协程内调用）
+func (h *Hub) terminateClients(t termination) int {
+	var targets []*client
+	for _, c := range h.accounts[t.accountID] {
+		if t.sessionID == "" || c.sessionID == t.sessionID {
+			targets = append(targets, c)
+		}
+	}
+	for _, c := range targets {
+		if !h.deliver(c, t.msg) {
+			continue
+		}
+		select {
+		case c.send <- closeFrame(t.reason):
+		default:
+		}
+		h.remove(c, DISCONNECT_TERMINATED)
+	}
+	return len(targets)
+}
+
+// 终止账户（sessionID 非空时仅该会话）的 WebSocket 连接：先推送控制消息，再以 1008 关闭码断开，返回断开的连接数
+func (h *Hub) Terminate(accountID, sessionID string, msg Message, reason string) int {
+	frames, err := h.encode(msg)
+	if err != nil {
+		log.Printf("WebSocket 消息序列化失败: %v", err)
+		return 0
+	}
+	reply := make(chan int, 1)
+	h.terminate <- termination{
+		accountID: accountID,
+		sessionID: sessionID,
+		msg:       broadcast{accountID: accountID, frames: frames},
+		reason:    reason,
+		reply:     reply,
+	}
+	return <-reply
+}
+
+// 按比例随机断开连接（仅在 hub 协程内调用），以 1012 Service Restart 关闭码通知客户端重连
+func (h *Hub) dropClients(ratio float64) int {
+	dropped := 0
+	for c := range h.clients {
+		if rand.Float64() >= ratio {
+			continue
+		}
+		select {
+		case c.send <- frame{close: true, data: websocket.FormatCloseMessage(websocket.CloseServiceRestart, DISCONNECT_CHAOS)}:
+		default:
+		}
+		h.remove(c, DISCONNECT_CHAOS)
+		dropped++
+	}
+	return dropped
+}
+
+// 按比例（0~1）随机断开当前连接，模拟断线风暴，返回断开的连接数
+func (h *Hub) Drop(ratio float64) int {
+	reply := make(chan int, 1)
+	h.drop <- dropRequest{ratio: ratio, reply: reply}
+	return <-reply
+}
+
+// 设置登录令牌校验（?token= 建立的连接绑定到令牌所属账户与会话），须在 Run 之前调用
+func (h *Hub) SetAuthenticator(auth Authenticator) {
+	h.auth = auth
+}
+
+// 设置在线客服消息处理（客户端 chat 指令），须在 Run 之前调用
+func (h *Hub) SetChatHandler(chat ChatHandler) {
+	h.chat = chat
+}
+
+// 处理 chat 指令：仅账户连接与管理员连接可发送，处理失败只记录日志（客户端可通过 REST 接口查询会话确认送达）
+func (h *Hub) handleChat(c *client, cmd ClientMessage) {
+	if h.chat == nil || (c.accountID == "" && !c.admin) {
+		log.Printf("WebSocket 客户端 %s 无法发送客服消息（需账户连接或管理员连接）", c.conn.RemoteAddr())
+		return
+	}
+	if err := h.chat(c.accountID, c.admin, cmd); err != nil {
+		log.Printf("WebSocket 客户端 %s 客服消息处理失败: %v", c.conn.RemoteAddr(), err)
+	}
+}
+
+// 累计断开原因
+func (h *Hub) countDisconnect(reason string) {
+	h.disconnectMu.Lock()
+	defer h.disconnectMu.Unlock()
+	h.disconnects[reason]++
+}
+
+// 在线连接详情快照（仅在 hub 协程内调用）
+func (h *Hub) snapshot() []ConnectionInfo {
+	result := make([]ConnectionInfo, 0, len(h.clients))
+	for c := range h.clients {
+		info := ConnectionInfo{
+			ID:             c.id,
+			AccountID:      c.accountID,
+			SessionID:      c.sessionID,
+			Admin:          c.admin,
+			RemoteAddr:     c.conn.RemoteAddr().String(),
+			ConnectedAt:    c.connectedAt.Format("2006-01-02 15:04:05"),
+			Version:        c.format.version,
+			Encoding:       c.format.encoding,
+			Compressed:     c.compressed,
+			Topics:         []string{},
+			MessagesQueued: c.queued,
+			MessagesSent:   atomic.LoadInt64(&c.sent),
+			Lag:            len(c.send),
+		}
+		for topic := range c.topics {
+			info.Topics = append(info.Topics, topic)
+		}
+		sort.Strings(info.Topics)
+		if at := atomic.LoadInt64(&c.lastSentAt); at != 0 {
+			info.LastSentAt = time.Unix(0, at).Format("2006-01-02 15:04:05")
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// 在线连接列表（按连接编号排序），accountID 非空时只返回该账户的连接
+func (h *Hub) Connections(accountID string) []ConnectionInfo {
+	reply := make(chan []ConnectionInfo, 1)
+	h.inspect <- reply
+	all := <-reply
+	if accountID == "" {
+		return all
+	}
+	result := []ConnectionInfo{}
+	for _, info := range all {
+		if info.AccountID == accountID {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
+// 同步账户连接数快照
+func (h *Hub) updateAccountCount(accountID string) {
+	h.countMu.Lock()
+	defer h.countMu.Unlock()
+
+	if n := len(h.accounts[accountID]); n > 0 {
+		h.accountCounts[accountID] = n
+	} else {
+		delete(h.accountCounts, accountID)
+	}
+}
+
+// 断开原因累计次数
+func (h *Hub) disconnectCounts() map[string]int64 {
+	h.disconnectMu.Lock()
+	defer h.disconnectMu.Unlock()
+
+	counts := make(map[string]int64, len(h.disconnects))
+	for reason, n := range h.disconnects {
+		counts[reason] = n
+	}
+	return counts
+}
+
+// 各账户当前连接数
+func (h *Hub) AccountCounts() map[string]int {
+	h.countMu.Lock()
+	defer h.countMu.Unlock()
+
+	counts := make(map[string]int, len(h.accountCounts))
+	for accountID, n := range h.accountCounts {
+		counts[accountID] = n
+	}
+	return counts
+}
+
+// 推送主题消息给订阅该主题的客户端（高频行情类消息，不逐条打印日志）；
+// 负载实现 Filterable 时按订阅者的过滤条件分发
+func (h *Hub) Publish(topic, msgType string, payload interface{}) {
+	frames, err := h.encode(Message{Type: msgType, Topic: topic, Data: payload})
+	if err != nil {
+		log.Printf("WebSocket 消息序列化失败: %v", err)
+		return
+	}
+
+	b := broadcast{topic: topic, frames: frames}
+	if f, ok := payload.(Filterable); ok {
+		b.filterable = true
+		b.filterAccount, b.filterAmount = f.FilterFields()
+	}
+	h.broadcast <- b
+}
+
+// 发送 WebSocket 消息给所有在线客户端
+func (h *Hub) Send(msg Message) {
+	// 账户事件分配序号并存入补发缓冲区，再按各消息格式版本序列化
+	b, err := h.record(msg)
+	if err != nil {
+		log.Printf("WebSocket 消息序列化失败: %v", err)
+		return
+	}
+
+	// 终端提示：WebSocket 消息推送
+	log.Println("\n[📤 WebSocket 消息推送]")
+	log.Printf("推送时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("消息类型: %s", msg.Type)
+	if msg.Type == "balanceUpdate" {
+		log.Printf("更新余额: %.2f 元", msg.NewBalance)
+	} else {
+		log.Printf("消息内容: %s", msg.Message)
+	}
+	log.Printf("在线客户端数: %d", h.Count())
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	// 交由 hub 异步分发给所有客户端
+	h.broadcast <- b
+}
+
+// 账户事件（非主题消息）分配账户内序号并存入补发缓冲区，其余消息直接序列化
+func (h *Hub) record(msg Message) (broadcast, error) {
+	b := broadcast{topic: msg.Topic, accountID: msg.AccountID}
+	if msg.AccountID == "" || msg.Topic != "" || h.cfg.ReplayBuffer <= 0 {
+		frames, err := h.encode(msg)
+		b.frames = frames
+		return b, err
+	}
+
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	hist := h.history[msg.AccountID]
+	if hist == nil {
+		hist = &history{}
+		h.history[msg.AccountID] = hist
+	}
+	msg.Seq = hist.seq + 1
+	frames, err := h.encode(msg)
+	if err != nil {
+		return b, err
+	}
+	hist.seq = msg.Seq
+	b.seq, b.frames = msg.Seq, frames
+	b.versions = make(map[int][]byte, len(frames))
+	for f, data := range frames {
+		b.versions[f.version] = data
+	}
+	hist.events = append(hist.events, b)
+	if len(hist.events) > h.cfg.ReplayBuffer {
+		hist.events = hist.events[len(hist.events)-h.cfg.ReplayBuffer:]
+	}
+	if hist.wake != nil {
+		close(hist.wake)
+		hist.wake = nil
+	}
+	return b, nil
+}
+
+// 分配消息编号并按各版本序列化
+func (h *Hub) encode(msg Message) (map[format][]byte, error) {
+	return encodeVersions(msg, atomic.AddUint64(&h.nextID, 1), time.Now())
+}
+
+// 当前在线客户端数
+func (h *Hub) Count() int64 {
+	return atomic.LoadInt64(&h.clientCount)
+}
+
+// 推送统计快照
+func (h *Hub) Metrics() Metrics {
+	return Metrics{
+		Clients:           atomic.LoadInt64(&h.clientCount),
+		Connections:       atomic.LoadInt64(&h.connections),
+		Broadcasts:        atomic.LoadInt64(&h.broadcasts),
+		MessagesQueued:    atomic.LoadInt64(&h.messagesQueued),
+		MessagesSent:      atomic.LoadInt64(&h.messagesSent),
+		SlowDisconnects:   atomic.LoadInt64(&h.slowDisconnects),
+		WriteErrors:       atomic.LoadInt64(&h.writeErrors),
+		MessagesReplayed:  atomic.LoadInt64(&h.replayed),
+		ResyncRequired:    atomic.LoadInt64(&h.resyncs),
+		LimitRejected:     atomic.LoadInt64(&h.limitRejected),
+		LimitEvicted:      atomic.LoadInt64(&h.limitEvicted),
+		MessagesDropped:   atomic.LoadInt64(&h.dropped),
+		SendBufferSize:    h.cfg.SendBuffer,
+		ReplayBufferSize:  h.cfg.ReplayBuffer,
+		WriteTimeoutMilli: h.cfg.WriteTimeout.Milliseconds(),
+		Compression:       h.cfg.Compression,
+
+		MaxConnectionsPerAccount: h.cfg.MaxConnectionsPerAccount,
+		ConnectionLimitPolicy:    h.cfg.ConnectionLimitPolicy,
+		AccountConnections:       h.AccountCounts(),
+
+		Disconnects: h.disconnectCounts(),
+	}
+}
+
+// 按客户端协商的格式取出序列化结果（二进制格式由同版本 JSON 转码，仅在 hub 协程内调用）
+func (b broadcast) frame(f format) ([]byte, error) {
+	if data, ok := b.frames[f]; ok {
+		return data, nil
+	}
+	data, err := jsonToMsgpack(b.frames[format{f.version, ENCODING_JSON}])
+	if err != nil {
+		return nil, err
+	}
+	b.frames[f] = data
+	return data, nil
+}
+
+// 处理 WebSocket 连接
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// 连接时可通过 ?version=N&encoding=json|msgpack 指定消息格式
+	query := r.URL.Query()
+	f := format{version: SCHEMA_DEFAULT, encoding: ENCODING_JSON}
+	if v := query.Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || !SupportedVersion(n) {
+			http.Error(w, "不支持的消息格式版本", http.StatusBadRequest)
+			return
+		}
+		f.version = n
+	}
+	if encoding := query.Get("encoding"); encoding != "" {
+		if !SupportedEncoding(encoding) {
+			http.Error(w, "不支持的消息编码方式", http.StatusBadRequest)
+			return
+		}
+		f.encoding = encoding
+	}
+	// 账户连接：只接收本账户及全员广播消息，重连时携带 lastEventId=N 补发之后的事件。
+	// 已配置令牌校验时账户连接必须携带登录令牌 ?token=，账户只取自会话（?accountId= 仅用于核对），
+	// 会话被终止时连接随之断开；未配置令牌校验（独立运行的推送中心）时按 ?accountId=X 绑定账户
+	accountID, sessionID := query.Get("accountId"), ""
+	token := query.Get("token")
+	if h.auth != nil && token == "" && accountID != "" {
+		http.Error(w, "账户连接须携带登录令牌 token", http.StatusUnauthorized)
+		return
+	}
+	if token != "" && h.auth != nil {
+		tokenAccountID, tokenSessionID, err := h.auth(token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if accountID != "" && accountID != tokenAccountID {
+			http.Error(w, "accountId 与登录令牌不符", http.StatusForbidden)
+			return
+		}
+		accountID, sessionID = tokenAccountID, tokenSessionID
+	}
+	// 管理员连接：?adminKey= 与配置的管理员密钥一致，可订阅 admin. 前缀主题（不可同时为账户连接）
+	admin := query.Get("adminKey") != ""
+	if admin {
+		if h.cfg.AdminKey == "" || query.Get("adminKey") != h.cfg.AdminKey {
+			http.Error(w, "管理员密钥无效", http.StatusForbidden)
+			return
+		}
+		if accountID != "" {
+			http.Error(w, "管理员连接不可指定 accountId 或登录令牌", http.StatusBadRequest)
+			return
+		}
+	}
+	var lastEventID uint64
+	resume := query.Get("lastEventId") != ""
+	if resume {
+		n, err := strconv.ParseUint(query.Get("lastEventId"), 10, 64)
+		if err != nil || accountID == "" {
+			http.Error(w, "lastEventId 须为非负整数且需为账户连接", http.StatusBadRequest)
+			return
+		}
+		lastEventID = n
+	}
+
+	// 升级 HTTP 连接为 WebSocket 连接
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket 升级失败: %v", err)
+		return
+	}
+	// 客户端在握手中提出 permessage-deflate 且服务端已启用时按该扩展压缩
+	compressed := h.cfg.Compression && strings.Contains(r.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+	if compressed && h.cfg.CompressionLevel != 0 {
+		if err := conn.SetCompressionLevel(h.cfg.CompressionLevel); err != nil {
+			log.Printf("WebSocket 压缩级别设置失败: %v", err)
+		}
+	}
+	// 终端提示：WebSocket 连接状态
+	log.Println("\n[📡 WebSocket 连接]")
+	log.Printf("连接时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+	log.Printf("客户端地址: %s", conn.RemoteAddr())
+	log.Printf("连接状态: 成功建立")
+	log.Printf("消息格式: v%d/%s", f.version, f.encoding)
+	if compressed {
+		log.Printf("消息压缩: permessage-deflate")
+	}
+	if accountID != "" {
+		log.Printf("所属账户: %s", accountID)
+	}
+	if sessionID != "" {
+		log.Printf("登录会话: %s", sessionID)
+	}
+	if admin {
+		log.Printf("连接角色: 管理员")
+	}
+	if resume {
+		log.Printf("断线重连: lastEventId=%d", lastEventID)
+	}
+	log.Println("-" + strings.Repeat("-", 50) + "-")
+
+	// 添加客户端到 hub，由独立写协程负责发送
+	c := &client{id: fmt.Sprintf("WS%06d", atomic.AddUint64(&h.nextConnID, 1)), conn: conn, connectedAt: time.Now(), send: make(chan frame, h.cfg.SendBuffer), topics: make(map[string]bool), filters: make(map[string]Filter), format: f,
+		admin: admin, accountID: accountID, sessionID: sessionID, lastEventID: lastEventID, resume: resume, compressed: compressed}
+	h.register <- c
+	go c.writePump(h)
+
+	// 延迟注销客户端（hub 关闭发送队列后写协程关闭连接）
+	reason := DISCONNECT_CLIENT_CLOSED
+	defer func() {
+		h.unregister <- disconnect{client: c, reason: reason}
+		// 终端提示：WebSocket 断开连接
+		log.Println("\n[📡 WebSocket 连接]")
+		log.Printf("断开时间: %s", time.Now().Format("2006-01-02 15:04:05"))
+		log.Printf("客户端地址: %s", conn.RemoteAddr())
+		log.Printf("连接状态: 已断开")
+		log.Println("-" + strings.Repeat("-", 50) + "-")
+	}()
+
+	// 循环读取客户端消息（保持连接，处理主题订阅与在线客服指令）
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WebSocket 读取错误: %v", err)
+			}
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				reason = DISCONNECT_READ_ERROR
+			}
+			break
+		}
+
+		var cmd ClientMessage
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			continue
+		}
+		if cmd.Action == "chat" {
+			h.handleChat(c, cmd)
+			continue
+		}
+		if cmd.Action != "subscribe" && cmd.Action != "unsubscribe" {
+			continue
+		}
+		if cmd.Version != 0 && !SupportedVersion(cmd.Version) {
+			log.Printf("WebSocket 客户端 %s 请求不支持的消息格式版本: %d", conn.RemoteAddr(), cmd.Version)
+			cmd.Version = 0
+		}
+		if cmd.Encoding != "" && !SupportedEncoding(cmd.Encoding) {
+			log.Printf("WebSocket 客户端 %s 请求不支持的消息编码方式: %s", conn.RemoteAddr(), cmd.Encoding)
+			cmd.Encoding = ""
+		}
+		if strings.HasPrefix(cmd.Topic, ADMIN_TOPIC_PREFIX) && !admin {
+			log.Printf("WebSocket 客户端 %s 无权订阅管理员主题: %s", conn.RemoteAddr(), cmd.Topic)
+			cmd.Topic = ""
+		}
+		if cmd.Topic == "" && cmd.Version == 0 && cmd.Encoding == "" {
+			continue
+		}
+		h.subscribe <- subscription{client: c, topic: cmd.Topic, subscribe: cmd.Action == "subscribe", version: cmd.Version, encoding: cmd.Encoding, filter: cmd.Filter}
+		if cmd.Topic != "" {
+			log.Printf("WebSocket 客户端 %s %s 主题: %s", conn.RemoteAddr(), cmd.Action, cmd.Topic)
+		}
+		if cmd.Topic != "" && cmd.Filter != nil && cmd.Action == "subscribe" {
+			log.Printf("WebSocket 客户端 %s 主题 %s 过滤条件: 账户=%q 最低金额=%.2f", conn.RemoteAddr(), cmd.Topic, cmd.Filter.AccountID, cmd.Filter.MinAmount)
+		}
+		if cmd.Version != 0 {
+			log.Printf("WebSocket 客户端 %s 切换消息格式版本: v%d", conn.RemoteAddr(), cmd.Version)
+		}
+		if cmd.Encoding != "" {
+			log.Printf("WebSocket 客户端 %s 切换消息编码方式: %s", conn.RemoteAddr(), cmd.Encoding)
+		}
+	}
+}
+
+// 客户端写协程：逐条写出发送队列中的消息，队列关闭或写失败时关闭连接
+func (c *client) writePump(h *Hub) {
+	defer c.conn.Close()
+
+	for f := range c.send {
+		if f.close {
+			// 服务端主动关闭：写出关闭原因后断开，剩余消息随队列关闭丢弃
+			c.conn.WriteControl(websocket.CloseMessage, f.data, time.Now().Add(h.cfg.WriteTimeout))
+			c.drain(h)
+			return
+		}
+		messageType := websocket.TextMessage
+		if f.binary {
+			messageType = websocket.BinaryMessage
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(h.cfg.WriteTimeout))
+		if err := c.conn.WriteMessage(messageType, f.data); err != nil {
+			log.Printf("WebSocket 消息发送失败（客户端: %s）: %v", c.conn.RemoteAddr(), err)
+			atomic.AddInt64(&h.writeErrors, 1)
+			h.unregister <- disconnect{client: c, reason: DISCONNECT_WRITE_ERROR}
+			// 继续排空队列，直到 hub 关闭发送队列
+			c.drain(h)
+			return
+		}
+		atomic.AddInt64(&h.messagesSent, 1)
+		atomic.AddInt64(&c.sent, 1)
+		atomic.StoreInt64(&c.lastSentAt, time.Now().UnixNano())
+	}
+	c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+}
+
+// 排空发送队列直至 hub 关闭队列，剩余消息计为丢弃
+func (c *client) drain(h *Hub) {
+	for f := range c.send {
+		if !f.close {
+			atomic.AddInt64(&h.dropped, 1)
+		}
+	}
+}
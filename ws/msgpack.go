@@ -0,0 +1,99 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// 将 JSON 消息转码为 MessagePack（字段名、结构与 JSON 格式一致，对象键按字典序输出）
+func jsonToMsgpack(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return appendMsgpack(make([]byte, 0, len(data)), value), nil
+}
+
+// 按 MessagePack 规范编码 JSON 解码得到的值（nil/bool/json.Number/string/[]interface{}/map[string]interface{}）
+func appendMsgpack(b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return appendMsgpackInt(b, n)
+		}
+		f, _ := v.Float64()
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+	case string:
+		b = appendMsgpackHeader(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		return append(b, v...)
+	case []interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			b = appendMsgpack(b, item)
+		}
+		return b
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b = appendMsgpackHeader(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			b = appendMsgpack(b, key)
+			b = appendMsgpack(b, v[key])
+		}
+		return b
+	}
+	return append(b, 0xc0)
+}
+
+// 整数按最短编码输出
+func appendMsgpackInt(b []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= 0x7f:
+		return append(b, byte(n))
+	case n < 0 && n >= -32:
+		return append(b, byte(n))
+	case n >= 0 && n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n >= 0 && n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	case n >= 0:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), uint64(n))
+	case n >= math.MinInt8:
+		return append(b, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(n))
+}
+
+// 字符串/数组/映射的长度头：fix 格式（fixCode|n），其余依次为 8/16/32 位长度（code8 为 0 表示无 8 位格式）
+func appendMsgpackHeader(b []byte, n int, fixCode byte, fixMax int, code8, code16, code32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(b, fixCode|byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		return append(b, code8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, code32), uint32(n))
+}
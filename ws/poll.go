@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 未启用事件补发缓冲区（WS_REPLAY_BUFFER 为 0）时不支持长轮询
+var ErrPollUnavailable = errors.New("未启用账户事件缓冲区，不支持长轮询")
+
+// 长轮询结果
+type PollResult struct {
+	AccountID      string            `json:"accountId"`
+	Events         []json.RawMessage `json:"events"`                   // 序号 since 之后的账户事件，格式与 WebSocket 推送一致（含 seq）
+	LatestEventID  uint64            `json:"latestEventId"`            // 当前最新事件序号，作为下一次请求的 since
+	OldestEventID  uint64            `json:"oldestEventId,omitempty"`  // 缓冲区中最早的事件序号
+	ResyncRequired bool              `json:"resyncRequired,omitempty"` // 所需事件已超出缓冲区或序号超前，客户端应全量同步
+}
+
+// 账户当前最新的事件序号（尚无事件时为 0）
+func (h *Hub) LatestEventID(accountID string) uint64 {
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	if hist := h.history[accountID]; hist != nil {
+		return hist.seq
+	}
+	return 0
+}
+
+// 长轮询账户事件（供无法使用 WebSocket 的环境）：返回序号 since 之后的事件（按 version 指定的消息格式），
+// 暂无新事件时最多等待 timeout，期间有新事件立即返回；ctx 结束（客户端断开）时提前返回
+func (h *Hub) Poll(ctx context.Context, accountID string, since uint64, version int, timeout time.Duration) (PollResult, error) {
+	if h.cfg.ReplayBuffer <= 0 {
+		return PollResult{}, ErrPollUnavailable
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		result, wake := h.pollOnce(accountID, since, version)
+		if len(result.Events) > 0 || result.ResyncRequired {
+			return result, nil
+		}
+		select {
+		case <-wake:
+		case <-timer.C:
+			return result, nil
+		case <-ctx.Done():
+			return result, nil
+		}
+	}
+}
+
+// 读取一次缓冲区：返回 since 之后的事件；没有新事件时同时返回用于等待下一条事件的通道
+func (h *Hub) pollOnce(accountID string, since uint64, version int) (PollResult, <-chan struct{}) {
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	hist := h.history[accountID]
+	if hist == nil {
+		hist = &history{}
+		h.history[accountID] = hist
+	}
+	result := PollResult{AccountID: accountID, Events: []json.RawMessage{}, LatestEventID: hist.seq}
+	if len(hist.events) > 0 {
+		result.OldestEventID = hist.events[0].seq
+	}
+	// 客户端序号超前（服务端重启后序号重置）或所需事件已被覆盖
+	result.ResyncRequired = since > hist.seq || since+1 < result.OldestEventID
+	for _, event := range hist.events {
+		if event.seq > since {
+			result.Events = append(result.Events, json.RawMessage(event.versions[version]))
+		}
+	}
+	if hist.wake == nil {
+		hist.wake = make(chan struct{})
+	}
+	return result, hist.wake
+}